@@ -32,6 +32,7 @@ type ExecutionConsumer interface {
 // Every consensus plugin needs to implement this interface
 type Consenter interface {
 	RecvMsg(msg *pb.Message, senderHandle *pb.PeerID) error // Called serially with incoming messages from gRPC
+	GetStatus() (*pb.ConsensusStatus, error)                // Returns a read-only snapshot of the state of the plugin
 	ExecutionConsumer
 }
 
@@ -103,6 +104,22 @@ type StatePersistor interface {
 	DelState(key string)
 }
 
+// NetworkConfigReader is implemented by stacks which can read the network-wide
+// parameters held on chain by the configuration system chaincode
+type NetworkConfigReader interface {
+	// ReadNetworkConfig returns the value of a parameter for the next block,
+	// according to the committed state. found is false if the parameter is
+	// not set on chain.
+	ReadNetworkConfig(name string) (value string, found bool, err error)
+}
+
+// MembershipManager is implemented by consenters whose set of validators can
+// change while the network is running. The changes are ordered by the
+// consenter, so they apply at the same point on every validator.
+type MembershipManager interface {
+	ReconfigureValidators(req *pb.ValidatorReconfiguration) error // Requests that a validator joins or leaves the set of validators, as signed by administrators
+}
+
 // Stack is the set of stack-facing methods available to the consensus plugin
 type Stack interface {
 	NetworkStack
@@ -17,31 +17,34 @@ limitations under the License.
 package controller
 
 import (
-	"strings"
-
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric/consensus"
-	"github.com/hyperledger/fabric/consensus/noops"
-	"github.com/hyperledger/fabric/consensus/pbft"
+
+	// The plugins shipped with fabric, they register themselves by name
+	_ "github.com/hyperledger/fabric/consensus/noops"
+	_ "github.com/hyperledger/fabric/consensus/orderer"
+	_ "github.com/hyperledger/fabric/consensus/pbft"
+	_ "github.com/hyperledger/fabric/consensus/raft"
 )
 
+// defaultPlugin is used when peer.validator.consensus.plugin is not set
+const defaultPlugin = "noops"
+
 var logger *logging.Logger // package-level logger
-var consenter consensus.Consenter
 
 func init() {
 	logger = logging.MustGetLogger("consensus/controller")
 }
 
-// NewConsenter constructs a Consenter object if not already present
-func NewConsenter(stack consensus.Stack) consensus.Consenter {
-	plugin := strings.ToLower(viper.GetString("peer.validator.consensus.plugin"))
-	if plugin == "pbft" {
-		logger.Infof("Creating consensus plugin %s", plugin)
-		return pbft.GetPlugin(stack)
+// NewConsenter constructs the Consenter of the plugin named by
+// peer.validator.consensus.plugin, among the registered plugins
+func NewConsenter(stack consensus.Stack) (consensus.Consenter, error) {
+	plugin := viper.GetString("peer.validator.consensus.plugin")
+	if plugin == "" {
+		plugin = defaultPlugin
 	}
-	logger.Info("Creating default consensus plugin (noops)")
-	return noops.GetNoops(stack)
-
+	logger.Infof("Creating consensus plugin %s", plugin)
+	return consensus.NewConsenter(plugin, stack)
 }
@@ -21,21 +21,24 @@ import (
 	"github.com/hyperledger/fabric/core/peer"
 
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric/consensus/controller"
 	"github.com/hyperledger/fabric/consensus/util"
 	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/db"
 	pb "github.com/hyperledger/fabric/protos"
 	"golang.org/x/net/context"
 )
 
 // EngineImpl implements a struct to hold consensus.Consenter, PeerEndpoint and MessageFan
 type EngineImpl struct {
-	consenter    consensus.Consenter
-	helper       *Helper
-	peerEndpoint *pb.PeerEndpoint
-	consensusFan *util.MessageFan
+	consenter     consensus.Consenter
+	helper        *Helper
+	peerEndpoint  *pb.PeerEndpoint
+	consensusChan chan *util.Message // The consensus messages of the chain of the engine
 }
 
 // GetHandlerFactory returns new NewConsensusHandler
@@ -48,7 +51,7 @@ func (eng *EngineImpl) GetHandlerFactory() peer.HandlerFactory {
 func (eng *EngineImpl) ProcessTransactionMsg(msg *pb.Message, tx *pb.Transaction) (response *pb.Response) {
 	//TODO: Do we always verify security, or can we supply a flag on the invoke ot this functions so to bypass check for locally generated transactions?
 	if tx.Type == pb.Transaction_CHAINCODE_QUERY {
-		if !engine.helper.valid {
+		if !eng.helper.valid {
 			logger.Warning("Rejecting query because state is currently not valid")
 			return &pb.Response{Status: pb.Response_FAILURE,
 				Msg: []byte("Error: state may be inconsistent, cannot query")}
@@ -80,6 +83,9 @@ func (eng *EngineImpl) ProcessTransactionMsg(msg *pb.Message, tx *pb.Transaction
 		if eng.consenter == nil {
 			return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte("Engine not initialized")}
 		}
+		if eng.helper.isDraining() {
+			return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte("Peer is shutting down")}
+		}
 		// TODO, do we want to put these requests into a queue? This will block until
 		// the consenter gets around to handling the message, but it also provides some
 		// natural feedback to the REST API to determine how long it takes to queue messages
@@ -91,6 +97,26 @@ func (eng *EngineImpl) ProcessTransactionMsg(msg *pb.Message, tx *pb.Transaction
 	return response
 }
 
+// GetConsensusStatus returns a snapshot of the state of the consensus plugin
+func (eng *EngineImpl) GetConsensusStatus() (*pb.ConsensusStatus, error) {
+	if eng.consenter == nil {
+		return nil, fmt.Errorf("Engine not initialized")
+	}
+	return eng.consenter.GetStatus()
+}
+
+// ReconfigureValidators orders a change of the set of validators
+func (eng *EngineImpl) ReconfigureValidators(req *pb.ValidatorReconfiguration) error {
+	if eng.consenter == nil {
+		return fmt.Errorf("Engine not initialized")
+	}
+	manager, ok := eng.consenter.(consensus.MembershipManager)
+	if !ok {
+		return fmt.Errorf("The consensus plugin does not support changing the set of validators")
+	}
+	return manager.ReconfigureValidators(req)
+}
+
 func (eng *EngineImpl) setConsenter(consenter consensus.Consenter) *EngineImpl {
 	eng.consenter = consenter
 	return eng
@@ -101,33 +127,124 @@ func (eng *EngineImpl) setPeerEndpoint(peerEndpoint *pb.PeerEndpoint) *EngineImp
 	return eng
 }
 
-var engineOnce sync.Once
+var (
+	enginesLock  sync.Mutex
+	engines      = make(map[string]*EngineImpl) // The engines of the chains hosted by the peer
+	consensusFan *util.MessageFan
+)
 
-var engine *EngineImpl
+// getConsensusFan returns the fan which the consensus messages of all the
+// handlers are fanned into, starting the dispatch of the messages to the
+// engines of their chains on first use
+func getConsensusFan() *util.MessageFan {
+	enginesLock.Lock()
+	defer enginesLock.Unlock()
+	if consensusFan == nil {
+		consensusFan = util.NewMessageFan()
+		go dispatchConsensusMessages(consensusFan)
+	}
+	return consensusFan
+}
 
-func getEngineImpl() *EngineImpl {
-	return engine
+// dispatchConsensusMessages passes each consensus message to the engine of its
+// chain. A message is dropped when the queue of its chain is full, so that a
+// chain whose consenter is slow does not hold up the other chains.
+func dispatchConsensusMessages(fan *util.MessageFan) {
+	logger.Debug("Starting up message thread for consenters")
+
+	// The channel never closes, so this should never break
+	for msg := range fan.GetOutChannel() {
+		chainID := db.NormalizeChainID(msg.Msg.ChainID)
+		enginesLock.Lock()
+		eng, ok := engines[chainID]
+		enginesLock.Unlock()
+		if !ok {
+			logger.Warningf("Dropping consensus message from %v for chain %s which is not hosted by this peer", msg.Sender, chainID)
+			continue
+		}
+		select {
+		case eng.consensusChan <- msg:
+		default:
+			logger.Warningf("Consensus message queue of chain %s full, dropping message from %v", chainID, msg.Sender)
+		}
+	}
 }
 
-// GetEngine returns initialized peer.Engine
+// DrainEngines stops the engines of the chains hosted by the peer from
+// accepting transactions and waits until the transaction batches they are
+// executing are committed or rolled back, or the deadline passes
+func DrainEngines(deadline time.Time) error {
+	enginesLock.Lock()
+	drained := make([]*EngineImpl, 0, len(engines))
+	for _, eng := range engines {
+		drained = append(drained, eng)
+	}
+	enginesLock.Unlock()
+
+	var errs []string
+	for _, eng := range drained {
+		if err := eng.helper.drain(deadline); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// closer is implemented by the consenters which release their resources,
+// and stop processing events, when closed
+type closer interface {
+	Close()
+}
+
+// StopEngines stops the consenters and executors of the engines of the chains
+// hosted by the peer, so that they no longer use the ledgers, which are closed
+// next. The engines are drained first.
+func StopEngines() {
+	enginesLock.Lock()
+	defer enginesLock.Unlock()
+	for _, eng := range engines {
+		eng.helper.stop()
+	}
+}
+
+// GetEngine returns initialized peer.Engine for the chain of the coordinator
 func GetEngine(coord peer.MessageHandlerCoordinator) (peer.Engine, error) {
+	chainID := db.DefaultChainID
+	if scoped, ok := coord.(peer.ChainScoped); ok {
+		chainID = db.NormalizeChainID(scoped.ChainID())
+	}
+
+	enginesLock.Lock()
+	defer enginesLock.Unlock()
+	if eng, ok := engines[chainID]; ok {
+		return eng, nil
+	}
+
 	var err error
-	engineOnce.Do(func() {
-		engine = new(EngineImpl)
-		engine.helper = NewHelper(coord)
-		engine.consenter = controller.NewConsenter(engine.helper)
-		engine.helper.setConsenter(engine.consenter)
-		engine.peerEndpoint, err = coord.GetPeerEndpoint()
-		engine.consensusFan = util.NewMessageFan()
-
-		go func() {
-			logger.Debug("Starting up message thread for consenter")
-
-			// The channel never closes, so this should never break
-			for msg := range engine.consensusFan.GetOutChannel() {
-				engine.consenter.RecvMsg(msg.Msg, msg.Sender)
-			}
-		}()
-	})
-	return engine, err
+	eng := new(EngineImpl)
+	eng.helper = NewHelper(coord)
+	eng.consenter, err = controller.NewConsenter(eng.helper)
+	if err != nil {
+		return nil, err
+	}
+	eng.helper.setConsenter(eng.consenter)
+	eng.peerEndpoint, err = coord.GetPeerEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	eng.consensusChan = make(chan *util.Message, consensusQueueSize())
+	engines[chainID] = eng
+
+	go func() {
+		logger.Debugf("Starting up message thread for consenter of chain %s", chainID)
+
+		// The channel never closes, so this should never break
+		for msg := range eng.consensusChan {
+			eng.consenter.RecvMsg(msg.Msg, msg.Sender)
+		}
+	}()
+	return eng, nil
 }
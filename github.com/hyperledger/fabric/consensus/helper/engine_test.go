@@ -16,8 +16,50 @@ limitations under the License.
 
 package helper
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/consensus/util"
+	pb "github.com/hyperledger/fabric/protos"
+)
 
 func TestEngine(t *testing.T) {
 	t.Skip("Engine functions already tested in other consensus components")
 }
+
+func TestDispatchIsolatesChains(t *testing.T) {
+	stuck := &EngineImpl{consensusChan: make(chan *util.Message, 1)} // Never read from
+	live := &EngineImpl{consensusChan: make(chan *util.Message, 1)}
+	enginesLock.Lock()
+	saved := engines
+	engines = map[string]*EngineImpl{"stuck": stuck, "live": live}
+	enginesLock.Unlock()
+	defer func() {
+		enginesLock.Lock()
+		engines = saved
+		enginesLock.Unlock()
+	}()
+
+	in := make(chan *util.Message, 10)
+	fan := util.NewMessageFan()
+	fan.AddFaninChannel(in)
+	go dispatchConsensusMessages(fan)
+
+	for i := 0; i < 3; i++ {
+		in <- &util.Message{Msg: &pb.Message{Type: pb.Message_CONSENSUS, ChainID: "stuck"}, Sender: &pb.PeerID{Name: "vp1"}}
+	}
+	in <- &util.Message{Msg: &pb.Message{Type: pb.Message_CONSENSUS, ChainID: "live"}, Sender: &pb.PeerID{Name: "vp1"}}
+
+	select {
+	case msg := <-live.consensusChan:
+		if msg.Msg.ChainID != "live" {
+			t.Errorf("Expected a message of chain live, got one of chain %s", msg.Msg.ChainID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected a chain whose consenter does not read its messages not to hold up the other chains")
+	}
+	if len(stuck.consensusChan) != 1 {
+		t.Errorf("Expected the messages beyond the queue of the chain to be dropped, %d queued", len(stuck.consensusChan))
+	}
+}
@@ -62,22 +62,26 @@ func NewConsensusHandler(coord peer.MessageHandlerCoordinator,
 		coordinator:    coord,
 	}
 
-	consensusQueueSize := viper.GetInt("peer.validator.consensus.buffersize")
-
-	if consensusQueueSize <= 0 {
-		logger.Errorf("peer.validator.consensus.buffersize is set to %d, but this must be a positive integer, defaulting to %d", consensusQueueSize, DefaultConsensusQueueSize)
-		consensusQueueSize = DefaultConsensusQueueSize
-	}
-
-	handler.consenterChan = make(chan *util.Message, consensusQueueSize)
-	getEngineImpl().consensusFan.AddFaninChannel(handler.consenterChan)
+	handler.consenterChan = make(chan *util.Message, consensusQueueSize())
+	getConsensusFan().AddFaninChannel(handler.consenterChan)
 
 	return handler, nil
 }
 
+// consensusQueueSize returns the number of consensus messages buffered per
+// connection, and per chain, before delivery is rejected
+func consensusQueueSize() int {
+	size := viper.GetInt("peer.validator.consensus.buffersize")
+	if size <= 0 {
+		logger.Errorf("peer.validator.consensus.buffersize is set to %d, but this must be a positive integer, defaulting to %d", size, DefaultConsensusQueueSize)
+		size = DefaultConsensusQueueSize
+	}
+	return size
+}
+
 // HandleMessage handles the incoming Fabric messages for the Peer
 // HandleMessage函数consenterChan 这个channel比较重要，
-// 该写入操作会触发engine.consensusFan的消息循环
+// 该写入操作会触发consensusFan的消息循环
 func (handler *ConsensusHandler) HandleMessage(msg *pb.Message) error {
 	if msg.Type == pb.Message_CONSENSUS {
 		senderPE, _ := handler.To()
@@ -88,7 +92,7 @@ func (handler *ConsensusHandler) HandleMessage(msg *pb.Message) error {
 		}:
 			return nil
 		default:
-			err := fmt.Errorf("Message channel for %v full, rejecting", senderPE.ID)
+			err := peer.LocalError{Err: fmt.Errorf("Message channel for %v full, rejecting", senderPE.ID)}
 			logger.Errorf("Failed to queue consensus message because: %v", err)
 			return err
 		}
@@ -99,3 +103,28 @@ func (handler *ConsensusHandler) HandleMessage(msg *pb.Message) error {
 	}
 	return handler.MessageHandler.HandleMessage(msg)
 }
+
+// GetChainRemoteLedger returns the RemoteLedger of a chain of the other PeerEndpoint
+func (handler *ConsensusHandler) GetChainRemoteLedger(chainID string) peer.RemoteLedger {
+	if remoteLedgers, ok := handler.MessageHandler.(peer.ChainRemoteLedgers); ok {
+		return remoteLedgers.GetChainRemoteLedger(chainID)
+	}
+	return handler.MessageHandler
+}
+
+// Closed returns a channel closed when the Chat with the other PeerEndpoint should end
+func (handler *ConsensusHandler) Closed() <-chan struct{} {
+	if closer, ok := handler.MessageHandler.(peer.ChatCloser); ok {
+		return closer.Closed()
+	}
+	return nil
+}
+
+// Traffic returns the traffic to the other PeerEndpoint
+func (handler *ConsensusHandler) Traffic() *pb.PeerTraffic {
+	if reporter, ok := handler.MessageHandler.(peer.TrafficReporter); ok {
+		return reporter.Traffic()
+	}
+	to, _ := handler.To()
+	return &pb.PeerTraffic{PeerEndpoint: &to}
+}
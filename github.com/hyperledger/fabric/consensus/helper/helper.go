@@ -18,6 +18,8 @@ package helper
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/spf13/viper"
@@ -28,8 +30,11 @@ import (
 	"github.com/hyperledger/fabric/consensus/helper/persist"
 	"github.com/hyperledger/fabric/core/chaincode"
 	crypto "github.com/hyperledger/fabric/core/crypto"
+	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/system_chaincode/configscc"
+	"github.com/hyperledger/fabric/events/producer"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -37,14 +42,21 @@ import (
 type Helper struct {
 	consenter    consensus.Consenter
 	coordinator  peer.MessageHandlerCoordinator
+	chainID      string // The chain whose ledger the consenter orders
 	secOn        bool
 	valid        bool // Whether we believe the state is up to date
 	secHelper    crypto.Peer
 	curBatch     []*pb.Transaction       // TODO, remove after issue 579
 	curBatchErrs []*pb.TransactionResult // TODO, remove after issue 579
+	curBatchTxs  []*pb.Transaction       // All the transactions executed in the batch, to execute it again
 	persist.Helper
 
 	executor consensus.Executor
+
+	batchLock sync.Mutex
+	batchDone chan struct{} // Closed when the batch in progress ends, nil if there is none
+	draining  bool          // Whether the peer is shutting down, no batch is started then
+	refused   bool          // Whether a batch was refused while draining, the executor callbacks no longer reach the consenter then
 }
 
 // NewHelper constructs the consensus helper object
@@ -56,6 +68,16 @@ func NewHelper(mhc peer.MessageHandlerCoordinator) *Helper {
 		secHelper:   mhc.GetSecHelper(),
 		valid:       true, // Assume our state is consistent until we are told otherwise, actual consensus (pbft) will invalidate this immediately, but noops will not
 	}
+	if scoped, ok := mhc.(peer.ChainScoped); ok {
+		h.chainID = scoped.ChainID()
+	}
+	if db.NormalizeChainID(h.chainID) != db.DefaultChainID {
+		openchainDB, err := db.GetChainDBHandle(h.chainID)
+		if err != nil {
+			panic(fmt.Errorf("Error opening the database of chain %s: %s", h.chainID, err))
+		}
+		h.Helper.OpenchainDB = openchainDB
+	}
 
 	h.executor = executor.NewImpl(h, h, mhc)
 	return h
@@ -66,6 +88,11 @@ func (h *Helper) setConsenter(c consensus.Consenter) {
 	h.executor.Start() // The consenter may be expecting a callback from the executor because of state transfer completing, it will miss this if we start the executor too early
 }
 
+// ChainID returns the ID of the chain whose ledger the consenter orders
+func (h *Helper) ChainID() string {
+	return db.NormalizeChainID(h.chainID)
+}
+
 // GetNetworkInfo returns the PeerEndpoints of the current validator and the entire validating network
 func (h *Helper) GetNetworkInfo() (self *pb.PeerEndpoint, network []*pb.PeerEndpoint, err error) {
 	ep, err := h.coordinator.GetPeerEndpoint()
@@ -153,24 +180,109 @@ func (h *Helper) Verify(replicaID *pb.PeerID, signature []byte, message []byte)
 		logger.Debugf("Endpoint name: %v", endpoint.ID.Name)
 		if *replicaID == *endpoint.ID {
 			cryptoID := endpoint.PkiID
-			return h.secHelper.Verify(cryptoID, signature, message)
+			err := h.secHelper.Verify(cryptoID, signature, message)
+			if err != nil {
+				h.ReportMisbehavior(replicaID, peer.OffenseInvalidData, fmt.Sprintf("invalid signature on consensus message: %s", err))
+			}
+			return err
 		}
 	}
 	return fmt.Errorf("Could not verify message from %s (unknown peer)", replicaID.Name)
 }
 
+// ReportMisbehavior scores an offense of a remote peer, detected by
+// consensus or state transfer, against it on the peer
+func (h *Helper) ReportMisbehavior(peerID *pb.PeerID, offense peer.Offense, reason string) {
+	if reporter, ok := h.coordinator.(peer.MisbehaviorReporter); ok {
+		reporter.ReportMisbehavior(peerID, offense, reason)
+	}
+}
+
+// startBatch records the start of a batch, unless the peer is shutting down
+func (h *Helper) startBatch() error {
+	h.batchLock.Lock()
+	defer h.batchLock.Unlock()
+	if h.draining {
+		h.refused = true
+		return fmt.Errorf("Peer is shutting down, not starting a transaction batch")
+	}
+	if h.batchDone == nil {
+		h.batchDone = make(chan struct{})
+	}
+	return nil
+}
+
+// endBatch records the end of the batch in progress
+func (h *Helper) endBatch() {
+	h.batchLock.Lock()
+	defer h.batchLock.Unlock()
+	if h.batchDone != nil {
+		close(h.batchDone)
+		h.batchDone = nil
+	}
+}
+
+// inBatch returns whether a batch is in progress
+func (h *Helper) inBatch() bool {
+	h.batchLock.Lock()
+	defer h.batchLock.Unlock()
+	return h.batchDone != nil
+}
+
+// isDraining returns whether the peer is shutting down
+func (h *Helper) isDraining() bool {
+	h.batchLock.Lock()
+	defer h.batchLock.Unlock()
+	return h.draining
+}
+
+// reporting returns whether the executor callbacks are passed to the
+// consenter. The executor ignores the errors of a refused batch, so it
+// reports the batch as executed and committed, which it was not.
+func (h *Helper) reporting() bool {
+	h.batchLock.Lock()
+	defer h.batchLock.Unlock()
+	return !h.refused
+}
+
+// drain keeps new batches from starting and waits until the batch in
+// progress, if any, is committed or rolled back, or the deadline passes
+func (h *Helper) drain(deadline time.Time) error {
+	h.batchLock.Lock()
+	h.draining = true
+	done := h.batchDone
+	h.batchLock.Unlock()
+
+	if done == nil {
+		return nil
+	}
+	logger.Infof("Waiting for the transaction batch of chain %s to end", db.NormalizeChainID(h.chainID))
+	select {
+	case <-done:
+		return nil
+	case <-time.After(deadline.Sub(time.Now())):
+		return fmt.Errorf("Transaction batch of chain %s still in progress", db.NormalizeChainID(h.chainID))
+	}
+}
+
 // BeginTxBatch gets invoked when the next round
 // of transaction-batch execution begins
 func (h *Helper) BeginTxBatch(id interface{}) error {
-	ledger, err := ledger.GetLedger()
+	if err := h.startBatch(); err != nil {
+		return err
+	}
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
+		h.endBatch()
 		return fmt.Errorf("Failed to get the ledger: %v", err)
 	}
 	if err := ledger.BeginTxBatch(id); err != nil {
+		h.endBatch()
 		return fmt.Errorf("Failed to begin transaction with the ledger: %v", err)
 	}
 	h.curBatch = nil     // TODO, remove after issue 579
 	h.curBatchErrs = nil // TODO, remove after issue 579
+	h.curBatchTxs = nil
 	return nil
 }
 
@@ -181,13 +293,38 @@ func (h *Helper) BeginTxBatch(id interface{}) error {
 func (h *Helper) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error) {
 	// TODO id is currently ignored, fix once the underlying implementation accepts id
 
+	// The batch was refused because the peer is shutting down
+	if !h.inBatch() && h.isDraining() {
+		return nil, fmt.Errorf("Peer is shutting down, not executing transactions")
+	}
+
+	return h.execTxs(txs)
+}
+
+func (h *Helper) execTxs(txs []*pb.Transaction) ([]byte, error) {
 	// The secHelper is set during creat ChaincodeSupport, so we don't need this step
 	// cxt := context.WithValue(context.Background(), "security", h.coordinator.GetSecHelper())
 	// TODO return directly once underlying implementation no longer returns []error
 
-	succeededTxs, res, ccevents, txerrs, err := chaincode.ExecuteTransactions(context.Background(), chaincode.DefaultChain, txs)
+	l, err := ledger.GetChainLedger(h.chainID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the ledger: %v", err)
+	}
+	txs = uniqueTxs(txs, h.curBatchTxs, func(txID string) bool {
+		_, err := l.GetTransactionBlockNumber(txID)
+		return err == nil
+	})
+
+	succeededTxs, res, ccevents, txerrs, err := chaincode.ExecuteTransactions(context.Background(), chaincode.DefaultChain, h.chainID, txs)
+	h.addResults(txs, succeededTxs, ccevents, txerrs)
+	return res, err
+}
 
+// addResults adds the transactions executed and the results of their
+// execution to the current batch
+func (h *Helper) addResults(txs []*pb.Transaction, succeededTxs []*pb.Transaction, ccevents []*pb.ChaincodeEvent, txerrs []error) {
 	h.curBatch = append(h.curBatch, succeededTxs...) // TODO, remove after issue 579
+	h.curBatchTxs = append(h.curBatchTxs, txs...)
 
 	//copy errs to result
 	txresults := make([]*pb.TransactionResult, len(txerrs))
@@ -202,8 +339,116 @@ func (h *Helper) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error)
 		}
 	}
 	h.curBatchErrs = append(h.curBatchErrs, txresults...) // TODO, remove after issue 579
+}
 
-	return res, err
+// uniqueTxs returns the transactions which neither committed nor were executed
+// in the batch already. A transaction forwarded to several validators may be
+// ordered several times, it is executed the first time only.
+func uniqueTxs(txs []*pb.Transaction, executed []*pb.Transaction, committed func(txID string) bool) []*pb.Transaction {
+	seen := make(map[string]bool, len(executed)+len(txs))
+	for _, tx := range executed {
+		seen[tx.Txid] = true
+	}
+	unique := make([]*pb.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		if seen[tx.Txid] || committed(tx.Txid) {
+			logger.Warningf("Not executing transaction %s again", tx.Txid)
+			continue
+		}
+		seen[tx.Txid] = true
+		unique = append(unique, tx)
+	}
+	return unique
+}
+
+// validateBatch runs the batch validation plugins on the transactions which
+// executed successfully and their results, right before the batch is
+// committed. The batch is executed again without the transactions the
+// plugins reject, until they accept all of the remaining ones.
+func (h *Helper) validateBatch(id interface{}, l *ledger.Ledger) error {
+	return h.validateBatchWith(func(txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+		return chaincode.ValidateBatch(chaincode.DefaultChain, txs, results)
+	}, func(txs []*pb.Transaction) error {
+		if err := l.RollbackTxBatch(id); err != nil {
+			return fmt.Errorf("Failed to rollback transaction with the ledger: %v", err)
+		}
+		if err := l.BeginTxBatch(id); err != nil {
+			return fmt.Errorf("Failed to begin transaction with the ledger: %v", err)
+		}
+		_, err := h.execTxs(txs)
+		return err
+	})
+}
+
+// validateBatchWith validates the batch with validate, and executes it again
+// with reexecute, from an empty batch, without the transactions rejected
+func (h *Helper) validateBatchWith(validate func(txs []*pb.Transaction, results []*pb.TransactionResult) []error, reexecute func(txs []*pb.Transaction) error) error {
+	txs := h.curBatchTxs
+	rejected := make(map[string]error)
+	for {
+		results := make(map[string]*pb.TransactionResult, len(h.curBatchErrs))
+		for _, result := range h.curBatchErrs {
+			results[result.Txid] = result
+		}
+		succeeded := make([]*pb.TransactionResult, len(h.curBatch))
+		for i, tx := range h.curBatch {
+			succeeded[i] = results[tx.Txid]
+		}
+
+		newlyRejected := 0
+		for i, err := range validate(h.curBatch, succeeded) {
+			if err != nil {
+				rejected[h.curBatch[i].Txid] = err
+				newlyRejected++
+			}
+		}
+		if newlyRejected == 0 {
+			return nil
+		}
+		logger.Warningf("Validation plugins rejected %d transactions of the batch, executing it again without them", newlyRejected)
+
+		var accepted []*pb.Transaction
+		for _, tx := range txs {
+			if _, ok := rejected[tx.Txid]; !ok {
+				accepted = append(accepted, tx)
+			}
+		}
+		h.curBatch, h.curBatchErrs, h.curBatchTxs = nil, nil, nil
+		if err := reexecute(accepted); err != nil {
+			return err
+		}
+
+		// The rejected transactions keep their place in the results
+		for _, result := range h.curBatchErrs {
+			results[result.Txid] = result
+		}
+		h.curBatchErrs = make([]*pb.TransactionResult, len(txs))
+		for i, tx := range txs {
+			if err, ok := rejected[tx.Txid]; ok {
+				h.curBatchErrs[i] = &pb.TransactionResult{Txid: tx.Txid, Error: err.Error(), ErrorCode: 1}
+			} else {
+				h.curBatchErrs[i] = results[tx.Txid]
+			}
+		}
+		h.curBatchTxs = txs
+	}
+}
+
+// rejectionEvents returns a Rejection event for each transaction of the batch
+// which failed or was rejected. They are sent once the batch is validated, so
+// that a transaction executed again is reported once.
+func (h *Helper) rejectionEvents() []*pb.Event {
+	txs := make(map[string]*pb.Transaction, len(h.curBatchTxs))
+	for _, tx := range h.curBatchTxs {
+		txs[tx.Txid] = tx
+	}
+	var events []*pb.Event
+	for _, result := range h.curBatchErrs {
+		if result.ErrorCode != 0 && txs[result.Txid] != nil {
+			events = append(events, producer.CreateRejectionEvent(txs[result.Txid], result.Error))
+		}
+	}
+	return events
 }
 
 // CommitTxBatch gets invoked when the current transaction-batch needs
@@ -212,10 +457,20 @@ func (h *Helper) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error)
 // during execution of this transaction-batch) have been committed to
 // permanent storage.
 func (h *Helper) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, error) {
-	ledger, err := ledger.GetLedger()
+	if !h.inBatch() && h.isDraining() {
+		return nil, fmt.Errorf("Peer is shutting down, not committing transactions")
+	}
+	defer h.endBatch()
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get the ledger: %v", err)
 	}
+	if err := h.validateBatch(id, ledger); err != nil {
+		return nil, err
+	}
+	for _, event := range h.rejectionEvents() {
+		producer.Send(event)
+	}
 	// TODO fix this one the ledger has been fixed to implement
 	if err := ledger.CommitTxBatch(id, h.curBatch, h.curBatchErrs, metadata); err != nil {
 		return nil, fmt.Errorf("Failed to commit transaction to the ledger: %v", err)
@@ -225,6 +480,7 @@ func (h *Helper) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, erro
 	defer func() {
 		h.curBatch = nil     // TODO, remove after issue 579
 		h.curBatchErrs = nil // TODO, remove after issue 579
+		h.curBatchTxs = nil
 	}()
 
 	block, err := ledger.GetBlockByNumber(size - 1)
@@ -240,7 +496,11 @@ func (h *Helper) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, erro
 // RollbackTxBatch discards all the state changes that may have taken
 // place during the execution of current transaction-batch
 func (h *Helper) RollbackTxBatch(id interface{}) error {
-	ledger, err := ledger.GetLedger()
+	if !h.inBatch() && h.isDraining() {
+		return fmt.Errorf("Peer is shutting down, no transaction batch to rollback")
+	}
+	defer h.endBatch()
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
 		return fmt.Errorf("Failed to get the ledger: %v", err)
 	}
@@ -249,6 +509,7 @@ func (h *Helper) RollbackTxBatch(id interface{}) error {
 	}
 	h.curBatch = nil     // TODO, remove after issue 579
 	h.curBatchErrs = nil // TODO, remove after issue 579
+	h.curBatchTxs = nil
 	return nil
 }
 
@@ -257,7 +518,7 @@ func (h *Helper) RollbackTxBatch(id interface{}) error {
 // blockchain if CommitTxBatch were invoked.  The blockinfo will
 // change if additional ExecTXs calls are invoked.
 func (h *Helper) PreviewCommitTxBatch(id interface{}, metadata []byte) ([]byte, error) {
-	ledger, err := ledger.GetLedger()
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get the ledger: %v", err)
 	}
@@ -273,7 +534,7 @@ func (h *Helper) PreviewCommitTxBatch(id interface{}, metadata []byte) ([]byte,
 // GetBlock returns a block from the chain
 // 从链上返回一个块
 func (h *Helper) GetBlock(blockNumber uint64) (block *pb.Block, err error) {
-	ledger, err := ledger.GetLedger()
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get the ledger :%v", err)
 	}
@@ -282,7 +543,7 @@ func (h *Helper) GetBlock(blockNumber uint64) (block *pb.Block, err error) {
 
 // GetCurrentStateHash returns the current/temporary state hash
 func (h *Helper) GetCurrentStateHash() (stateHash []byte, err error) {
-	ledger, err := ledger.GetLedger()
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get the ledger :%v", err)
 	}
@@ -296,14 +557,14 @@ func (h *Helper) GetBlockchainSize() uint64 {
 
 // GetBlockchainInfo gets the ledger's BlockchainInfo
 func (h *Helper) GetBlockchainInfo() *pb.BlockchainInfo {
-	ledger, _ := ledger.GetLedger()
+	ledger, _ := ledger.GetChainLedger(h.chainID)
 	info, _ := ledger.GetBlockchainInfo()
 	return info
 }
 
 // GetBlockchainInfoBlob marshals a ledger's BlockchainInfo into a protobuf
 func (h *Helper) GetBlockchainInfoBlob() []byte {
-	ledger, _ := ledger.GetLedger()
+	ledger, _ := ledger.GetChainLedger(h.chainID)
 	info, _ := ledger.GetBlockchainInfo()
 	rawInfo, _ := proto.Marshal(info)
 	return rawInfo
@@ -311,7 +572,7 @@ func (h *Helper) GetBlockchainInfoBlob() []byte {
 
 // GetBlockHeadMetadata returns metadata from block at the head of the blockchain
 func (h *Helper) GetBlockHeadMetadata() ([]byte, error) {
-	ledger, err := ledger.GetLedger()
+	ledger, err := ledger.GetChainLedger(h.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -323,6 +584,16 @@ func (h *Helper) GetBlockHeadMetadata() ([]byte, error) {
 	return block.ConsensusMetadata, nil
 }
 
+// ReadNetworkConfig returns the value a parameter held by the configuration
+// system chaincode has for the next block
+func (h *Helper) ReadNetworkConfig(name string) (string, bool, error) {
+	ledger, err := ledger.GetChainLedger(h.chainID)
+	if err != nil {
+		return "", false, err
+	}
+	return configscc.Read(ledger, name, ledger.GetBlockchainSize())
+}
+
 // InvalidateState is invoked to tell us that consensus realizes the ledger is out of sync
 func (h *Helper) InvalidateState() {
 	logger.Debug("Invalidating the current state")
@@ -361,21 +632,21 @@ func (h *Helper) UpdateState(tag interface{}, target *pb.BlockchainInfo, peers [
 
 // Executed is called whenever Execute completes
 func (h *Helper) Executed(tag interface{}) {
-	if h.consenter != nil {
+	if h.consenter != nil && h.reporting() {
 		h.consenter.Executed(tag)
 	}
 }
 
 // Committed is called whenever Commit completes
 func (h *Helper) Committed(tag interface{}, target *pb.BlockchainInfo) {
-	if h.consenter != nil {
+	if h.consenter != nil && h.reporting() {
 		h.consenter.Committed(tag, target)
 	}
 }
 
 // RolledBack is called whenever a Rollback completes
 func (h *Helper) RolledBack(tag interface{}) {
-	if h.consenter != nil {
+	if h.consenter != nil && h.reporting() {
 		h.consenter.RolledBack(tag)
 	}
 }
@@ -387,6 +658,15 @@ func (h *Helper) StateUpdated(tag interface{}, target *pb.BlockchainInfo) {
 	}
 }
 
+// stop halts the consenter and the executor, so that they no longer use the
+// ledger once the peer is drained
+func (h *Helper) stop() {
+	if c, ok := h.consenter.(closer); ok {
+		c.Close()
+	}
+	h.executor.Halt()
+}
+
 // Start his is a byproduct of the consensus API needing some cleaning, for now it's a no-op
 func (h *Helper) Start() {}
 
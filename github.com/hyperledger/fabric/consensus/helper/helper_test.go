@@ -16,8 +16,169 @@ limitations under the License.
 
 package helper
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
 
 func TestHelper(t *testing.T) {
 	t.Skip("Helper functions already tested in other consensus components")
 }
+
+func TestDrainWaitsForBatch(t *testing.T) {
+	h := &Helper{}
+	if err := h.startBatch(); err != nil {
+		t.Fatalf("Error starting batch: %s", err)
+	}
+
+	drained := make(chan error)
+	go func() {
+		drained <- h.drain(time.Now().Add(time.Minute))
+	}()
+	select {
+	case <-drained:
+		t.Fatalf("Expected draining to wait for the batch in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	h.endBatch()
+	if err := <-drained; err != nil {
+		t.Fatalf("Error draining: %s", err)
+	}
+	if err := h.startBatch(); err == nil {
+		t.Errorf("Expected no batch to start once draining")
+	}
+}
+
+func TestDrainDeadline(t *testing.T) {
+	h := &Helper{}
+	if err := h.startBatch(); err != nil {
+		t.Fatalf("Error starting batch: %s", err)
+	}
+	if err := h.drain(time.Now().Add(10 * time.Millisecond)); err == nil {
+		t.Errorf("Expected an error when the batch does not end before the deadline")
+	}
+}
+
+// drainConsenter records the executor callbacks it receives
+type drainConsenter struct {
+	executed, committed, rolledBack int
+}
+
+func (c *drainConsenter) RecvMsg(msg *pb.Message, senderHandle *pb.PeerID) error  { return nil }
+func (c *drainConsenter) GetStatus() (*pb.ConsensusStatus, error)                 { return nil, nil }
+func (c *drainConsenter) Executed(tag interface{})                                { c.executed++ }
+func (c *drainConsenter) Committed(tag interface{}, target *pb.BlockchainInfo)    { c.committed++ }
+func (c *drainConsenter) RolledBack(tag interface{})                              { c.rolledBack++ }
+func (c *drainConsenter) StateUpdated(tag interface{}, target *pb.BlockchainInfo) {}
+
+func TestCommitDuringDrain(t *testing.T) {
+	c := &drainConsenter{}
+	h := &Helper{consenter: c}
+	if err := h.startBatch(); err != nil {
+		t.Fatalf("Error starting batch: %s", err)
+	}
+	drained := make(chan error)
+	go func() {
+		drained <- h.drain(time.Now().Add(time.Minute))
+	}()
+
+	// The batch in progress commits
+	h.endBatch()
+	h.Committed(1, &pb.BlockchainInfo{Height: 2})
+	if err := <-drained; err != nil {
+		t.Fatalf("Error draining: %s", err)
+	}
+	if c.committed != 1 {
+		t.Fatalf("Expected the batch in progress to be reported committed")
+	}
+
+	// The executor ignores the errors of the next batch, which is refused,
+	// and calls back as if it was executed and committed
+	if err := h.BeginTxBatch(2); err == nil {
+		t.Fatalf("Expected the batch to be refused once draining")
+	}
+	if _, err := h.ExecTxs(2, []*pb.Transaction{{Txid: "tx1"}}); err == nil {
+		t.Fatalf("Expected the transactions not to execute once draining")
+	}
+	h.Executed(2)
+	if _, err := h.CommitTxBatch(2, nil); err == nil {
+		t.Fatalf("Expected the batch not to commit once draining")
+	}
+	h.Committed(2, &pb.BlockchainInfo{Height: 3})
+	if err := h.RollbackTxBatch(2); err == nil {
+		t.Fatalf("Expected no batch to roll back once draining")
+	}
+	h.RolledBack(2)
+	if c.executed != 0 || c.committed != 1 || c.rolledBack != 0 {
+		t.Errorf("Expected the refused batch not to be reported to the consenter, got %d executed, %d committed and %d rolled back", c.executed, c.committed, c.rolledBack)
+	}
+}
+
+func TestRejectionEventsOfReexecutedBatch(t *testing.T) {
+	h := &Helper{}
+	// a fails to execute, every time it is executed
+	execute := func(txs []*pb.Transaction) error {
+		var succeeded []*pb.Transaction
+		errs := make([]error, len(txs))
+		for i, tx := range txs {
+			if tx.Txid == "a" {
+				errs[i] = fmt.Errorf("execution failed")
+			} else {
+				succeeded = append(succeeded, tx)
+			}
+		}
+		h.addResults(txs, succeeded, make([]*pb.ChaincodeEvent, len(txs)), errs)
+		return nil
+	}
+	execute([]*pb.Transaction{{Txid: "a"}, {Txid: "b"}, {Txid: "c"}, {Txid: "d"}})
+
+	// b is rejected first, then c once the batch is executed without b
+	round := 0
+	validate := func(txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+		round++
+		errs := make([]error, len(txs))
+		for i, tx := range txs {
+			if tx.Txid == "b" && round == 1 || tx.Txid == "c" && round == 2 {
+				errs[i] = fmt.Errorf("rejected by plugin")
+			}
+		}
+		return errs
+	}
+	executions := 0
+	reexecute := func(txs []*pb.Transaction) error {
+		executions++
+		return execute(txs)
+	}
+	if err := h.validateBatchWith(validate, reexecute); err != nil {
+		t.Fatalf("Error validating batch: %s", err)
+	}
+	if executions != 2 {
+		t.Fatalf("Expected the batch to be executed again twice, got %d", executions)
+	}
+	if len(h.curBatch) != 1 || h.curBatch[0].Txid != "d" || len(h.curBatchErrs) != 4 {
+		t.Fatalf("Expected d to commit and the results of all the transactions to be kept, got %v and %v", h.curBatch, h.curBatchErrs)
+	}
+
+	rejections := make(map[string]int)
+	for _, event := range h.rejectionEvents() {
+		rejections[event.GetRejection().Tx.Txid]++
+	}
+	if len(rejections) != 3 || rejections["a"] != 1 || rejections["b"] != 1 || rejections["c"] != 1 {
+		t.Errorf("Expected one Rejection event for each of a, b and c, got %v", rejections)
+	}
+}
+
+func TestUniqueTxs(t *testing.T) {
+	txs := []*pb.Transaction{{Txid: "a"}, {Txid: "b"}, {Txid: "c"}, {Txid: "b"}, {Txid: "d"}}
+	executed := []*pb.Transaction{{Txid: "c"}}
+	committed := func(txID string) bool { return txID == "a" }
+
+	unique := uniqueTxs(txs, executed, committed)
+	if len(unique) != 2 || unique[0].Txid != "b" || unique[1].Txid != "d" {
+		t.Fatalf("Expected the transactions which did not commit nor execute to execute once, got %v", unique)
+	}
+}
@@ -21,30 +21,40 @@ import (
 )
 
 // Helper provides an abstraction to access the Persist column family
-// in the database.
-type Helper struct{}
+// in the database. The database of the default chain is used unless
+// OpenchainDB is set.
+type Helper struct {
+	OpenchainDB *db.OpenchainDB
+}
+
+func (h *Helper) getDB() *db.OpenchainDB {
+	if h.OpenchainDB != nil {
+		return h.OpenchainDB
+	}
+	return db.GetDBHandle()
+}
 
 // StoreState stores a key,value pair
 func (h *Helper) StoreState(key string, value []byte) error {
-	db := db.GetDBHandle()
+	db := h.getDB()
 	return db.Put(db.PersistCF, []byte("consensus."+key), value)
 }
 
 // DelState removes a key,value pair
 func (h *Helper) DelState(key string) {
-	db := db.GetDBHandle()
+	db := h.getDB()
 	db.Delete(db.PersistCF, []byte("consensus."+key))
 }
 
 // ReadState retrieves a value to a key
 func (h *Helper) ReadState(key string) ([]byte, error) {
-	db := db.GetDBHandle()
+	db := h.getDB()
 	return db.Get(db.PersistCF, []byte("consensus."+key))
 }
 
 // ReadStateSet retrieves all key,value pairs where the key starts with prefix
 func (h *Helper) ReadStateSet(prefix string) (map[string][]byte, error) {
-	db := db.GetDBHandle()
+	db := h.getDB()
 	prefixRaw := []byte("consensus." + prefix)
 
 	ret := make(map[string][]byte)
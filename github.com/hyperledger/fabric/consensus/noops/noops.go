@@ -23,6 +23,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/op/go-logging"
+	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric/consensus"
 	"github.com/hyperledger/fabric/core/ledger"
@@ -33,8 +34,16 @@ import (
 
 var logger *logging.Logger // package-level logger
 
+// plugin registers NOOPS under the name "noops"
+var plugin = &consensus.Plugin{
+	Name:       "noops",
+	LoadConfig: loadConfig,
+}
+
 func init() {
 	logger = logging.MustGetLogger("consensus/noops")
+	plugin.New = newNoops
+	consensus.RegisterPlugin(plugin)
 }
 
 // Noops is a plugin object implementing the consensus.Consenter interface.
@@ -46,26 +55,19 @@ type Noops struct {
 	channel  chan *pb.Transaction
 }
 
-// Setting up a singleton NOOPS consenter
-var iNoops consensus.Consenter
-
 // GetNoops returns a singleton of NOOPS
 func GetNoops(c consensus.Stack) consensus.Consenter {
-	if iNoops == nil {
-		iNoops = newNoops(c)
-	}
-	return iNoops
+	return plugin.Singleton(c)
 }
 
 // newNoops is a constructor returning a consensus.Consenter object.
-func newNoops(c consensus.Stack) consensus.Consenter {
+func newNoops(c consensus.Stack, config *viper.Viper) consensus.Consenter {
 	var err error
 	if logger.IsEnabledFor(logging.DEBUG) {
 		logger.Debug("Creating a NOOPS object")
 	}
 	i := &Noops{}
 	i.stack = c
-	config := loadConfig()
 	blockSize := config.GetInt("block.size")
 	blockWait := config.GetString("block.wait")
 	if _, err = strconv.Atoi(blockWait); err == nil {
@@ -313,3 +315,8 @@ func (i *Noops) RolledBack(tag interface{}) {
 func (i *Noops) StateUpdated(tag interface{}, target *pb.BlockchainInfo) {
 	// Never called
 }
+
+// GetStatus returns the name of the plugin, noops keeps no state worth reporting
+func (i *Noops) GetStatus() (*pb.ConsensusStatus, error) {
+	return &pb.ConsensusStatus{Plugin: "noops"}, nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orderer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric/consensus"
+	"github.com/hyperledger/fabric/consensus/util/events"
+	"github.com/hyperledger/fabric/core/comm"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// Event types
+
+// batchEvent is sent when the ordering service delivers a batch
+type batchEvent struct {
+	batch *pb.OrderedBatch
+}
+
+// executedEvent is sent when a requested execution completes
+type executedEvent struct {
+	tag interface{}
+}
+
+// committedEvent is sent when a requested commit completes
+type committedEvent struct {
+	tag interface{}
+}
+
+// obcOrderer leaves the ordering of the transactions to an external
+// ordering service: it broadcasts the transactions it receives to the
+// service, and executes the batches the service delivers, in order. As every
+// peer executes the same log of batches, the peers need not communicate.
+type obcOrderer struct {
+	stack   consensus.Stack
+	manager events.Manager
+	conn    *grpc.ClientConn
+	client  pb.AtomicBroadcastClient
+	done    chan struct{}
+
+	address          string
+	broadcastTimeout time.Duration
+	reconnectTimeout time.Duration
+
+	lastExec  uint64             // sequence number of the last batch committed to the ledger
+	executing *pb.OrderedBatch   // batch being executed and committed
+	delivered []*pb.OrderedBatch // batches waiting for execution
+}
+
+func newObcOrderer(config *viper.Viper, stack consensus.Stack) *obcOrderer {
+	var err error
+	op := &obcOrderer{
+		stack:   stack,
+		address: config.GetString("general.address"),
+		done:    make(chan struct{}),
+	}
+	op.broadcastTimeout, err = time.ParseDuration(config.GetString("general.timeout.broadcast"))
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse broadcast timeout: %s", err))
+	}
+	op.reconnectTimeout, err = time.ParseDuration(config.GetString("general.timeout.reconnect"))
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse reconnect timeout: %s", err))
+	}
+	op.conn, err = comm.NewClientConnectionWithAddress(op.address, false, false, nil)
+	if err != nil {
+		panic(fmt.Errorf("Cannot connect to the ordering service at %s: %s", op.address, err))
+	}
+	op.client = pb.NewAtomicBroadcastClient(op.conn)
+
+	if raw, err := stack.GetBlockHeadMetadata(); err == nil && raw != nil {
+		meta := &Metadata{}
+		if err = proto.Unmarshal(raw, meta); err != nil {
+			logger.Warningf("Could not unmarshal the metadata of the last block: %s", err)
+		} else {
+			op.lastExec = meta.SequenceNumber
+		}
+	}
+
+	logger.Infof("Ordering service at %s", op.address)
+	logger.Infof("Resuming after batch %d", op.lastExec)
+
+	op.manager = events.NewManagerImpl()
+	op.manager.SetReceiver(op)
+	op.manager.Start()
+	go op.deliver(op.lastExec + 1)
+
+	return op
+}
+
+// Close tells us to release resources we are holding
+func (op *obcOrderer) Close() {
+	close(op.done)
+	op.manager.Halt()
+	op.conn.Close()
+}
+
+// RecvMsg is called by the stack when a new message is received, it
+// broadcasts the transactions submitted to this peer to the ordering
+// service, and returns once the ordering service accepted them
+func (op *obcOrderer) RecvMsg(ocMsg *pb.Message, senderHandle *pb.PeerID) error {
+	if ocMsg.Type != pb.Message_CHAIN_TRANSACTION {
+		logger.Warningf("Ignoring message of type %s from %v, the peers do not exchange messages", ocMsg.Type, senderHandle)
+		return nil
+	}
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(ocMsg.Payload, tx); err != nil {
+		return fmt.Errorf("Could not unmarshal transaction: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), op.broadcastTimeout)
+	defer cancel()
+	resp, err := op.client.Broadcast(ctx, tx, grpc.FailFast(false))
+	if err != nil {
+		return fmt.Errorf("Could not broadcast transaction %s to the ordering service: %s", tx.Txid, err)
+	}
+	if resp.Status != pb.BroadcastResponse_SUCCESS {
+		return fmt.Errorf("Ordering service did not accept transaction %s: %s %s", tx.Txid, resp.Status, resp.Message)
+	}
+	logger.Debugf("Broadcast transaction %s", tx.Txid)
+	return nil
+}
+
+// deliver receives the batches from the ordering service, starting at next,
+// and connects again whenever the delivery is interrupted
+func (op *obcOrderer) deliver(next uint64) {
+	for {
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, err := op.client.Deliver(ctx, &pb.DeliverRequest{Start: next}, grpc.FailFast(false))
+		for err == nil {
+			var batch *pb.OrderedBatch
+			if batch, err = stream.Recv(); err != nil {
+				break
+			}
+			if batch.SequenceNumber != next {
+				err = fmt.Errorf("received batch %d, expected %d", batch.SequenceNumber, next)
+				break
+			}
+			next++
+			select {
+			case op.manager.Queue() <- batchEvent{batch}:
+			case <-op.done:
+			}
+		}
+		cancel()
+
+		select {
+		case <-op.done:
+			return
+		default:
+		}
+		logger.Warningf("Delivery from the ordering service at %s interrupted, connecting again from batch %d: %s", op.address, next, err)
+		select {
+		case <-op.done:
+			return
+		case <-time.After(op.reconnectTimeout):
+		}
+	}
+}
+
+// ProcessEvent executes the batches in the order they are delivered, all
+// the state is only accessed from here
+func (op *obcOrderer) ProcessEvent(event events.Event) events.Event {
+	switch et := event.(type) {
+	case batchEvent:
+		logger.Debugf("Received batch %d with %d transactions", et.batch.SequenceNumber, len(et.batch.Transactions))
+		op.delivered = append(op.delivered, et.batch)
+		op.executeNext()
+	case executedEvent:
+		meta, err := proto.Marshal(&Metadata{SequenceNumber: op.executing.SequenceNumber})
+		if err != nil {
+			panic(fmt.Errorf("Could not marshal metadata: %s", err))
+		}
+		op.stack.Commit(et.tag, meta)
+	case committedEvent:
+		op.lastExec = op.executing.SequenceNumber
+		op.executing = nil
+		logger.Debugf("Committed batch %d", op.lastExec)
+		op.executeNext()
+	default:
+		logger.Errorf("Unexpected event %T", event)
+	}
+	return nil
+}
+
+func (op *obcOrderer) executeNext() {
+	if op.executing != nil || len(op.delivered) == 0 {
+		return
+	}
+	op.executing, op.delivered = op.delivered[0], op.delivered[1:]
+	op.stack.Execute(op.executing.SequenceNumber, op.executing.Transactions)
+}
+
+// Executed is called whenever Execute completes
+func (op *obcOrderer) Executed(tag interface{}) {
+	op.manager.Queue() <- executedEvent{tag}
+}
+
+// Committed is called whenever Commit completes
+func (op *obcOrderer) Committed(tag interface{}, target *pb.BlockchainInfo) {
+	op.manager.Queue() <- committedEvent{tag}
+}
+
+// RolledBack is called whenever a Rollback completes, the orderer never
+// rolls back
+func (op *obcOrderer) RolledBack(tag interface{}) {
+	logger.Warningf("Unexpected rollback")
+}
+
+// StateUpdated is called when state transfer completes, the orderer does not
+// need state transfer as it can replay the whole log of the ordering service
+func (op *obcOrderer) StateUpdated(tag interface{}, target *pb.BlockchainInfo) {
+	logger.Warningf("Unexpected state update")
+}
+
+// GetStatus returns the name of the plugin
+func (op *obcOrderer) GetStatus() (*pb.ConsensusStatus, error) {
+	return &pb.ConsensusStatus{Plugin: "orderer"}, nil
+}
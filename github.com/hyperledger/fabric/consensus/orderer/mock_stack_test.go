@@ -0,0 +1,215 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orderer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// testPeer is the consensus.Stack of a peer, with an in memory ledger. The
+// peer can be stopped and started again on the same ledger.
+type testPeer struct {
+	config   *viper.Viper
+	starting sync.WaitGroup // the completions wait for the consenter to be set
+
+	mutex   sync.Mutex
+	op      *obcOrderer
+	blocks  []*pb.Block
+	pending []*pb.Transaction
+}
+
+func newTestPeer(config *viper.Viper) *testPeer {
+	return &testPeer{config: config, blocks: []*pb.Block{{}}}
+}
+
+func (p *testPeer) start() {
+	p.starting.Add(1)
+	op := newObcOrderer(p.config, p)
+	p.mutex.Lock()
+	p.op = op
+	p.mutex.Unlock()
+	p.starting.Done()
+}
+
+func (p *testPeer) stop() {
+	p.mutex.Lock()
+	op := p.op
+	p.op = nil
+	p.mutex.Unlock()
+	if op != nil {
+		op.Close()
+	}
+}
+
+func (p *testPeer) consenter() *obcOrderer {
+	p.starting.Wait()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.op
+}
+
+func (p *testPeer) submit(txID string) error {
+	raw, _ := proto.Marshal(&pb.Transaction{Txid: txID})
+	return p.consenter().RecvMsg(&pb.Message{Type: pb.Message_CHAIN_TRANSACTION, Payload: raw}, &pb.PeerID{Name: "vp0"})
+}
+
+// transactions lists the IDs of the transactions in the ledger, in order
+func (p *testPeer) transactions() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var ids []string
+	for _, block := range p.blocks {
+		for _, tx := range block.Transactions {
+			ids = append(ids, tx.Txid)
+		}
+	}
+	return ids
+}
+
+// Executor
+
+func (p *testPeer) Start() {}
+
+func (p *testPeer) Halt() {}
+
+func (p *testPeer) Execute(tag interface{}, txs []*pb.Transaction) {
+	p.mutex.Lock()
+	p.pending = append(p.pending, txs...)
+	p.mutex.Unlock()
+	go func() {
+		if op := p.consenter(); op != nil {
+			op.Executed(tag)
+		}
+	}()
+}
+
+func (p *testPeer) Commit(tag interface{}, metadata []byte) {
+	p.mutex.Lock()
+	p.blocks = append(p.blocks, &pb.Block{Transactions: p.pending, ConsensusMetadata: metadata})
+	p.pending = nil
+	p.mutex.Unlock()
+	go func() {
+		if op := p.consenter(); op != nil {
+			op.Committed(tag, nil)
+		}
+	}()
+}
+
+func (p *testPeer) Rollback(tag interface{}) {}
+
+func (p *testPeer) UpdateState(tag interface{}, target *pb.BlockchainInfo, peers []*pb.PeerID) {}
+
+// ReadOnlyLedger
+
+func (p *testPeer) GetBlock(id uint64) (*pb.Block, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if id >= uint64(len(p.blocks)) {
+		return nil, fmt.Errorf("Block not found")
+	}
+	return p.blocks[id], nil
+}
+
+func (p *testPeer) GetBlockchainSize() uint64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return uint64(len(p.blocks))
+}
+
+func (p *testPeer) GetBlockchainInfo() *pb.BlockchainInfo {
+	return &pb.BlockchainInfo{Height: p.GetBlockchainSize()}
+}
+
+func (p *testPeer) GetBlockchainInfoBlob() []byte {
+	return nil
+}
+
+func (p *testPeer) GetBlockHeadMetadata() ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.blocks[len(p.blocks)-1].ConsensusMetadata, nil
+}
+
+// The rest of the stack is not used by the orderer plugin
+
+func (p *testPeer) GetNetworkInfo() (self *pb.PeerEndpoint, network []*pb.PeerEndpoint, err error) {
+	return nil, nil, nil
+}
+
+func (p *testPeer) GetNetworkHandles() (self *pb.PeerID, network []*pb.PeerID, err error) {
+	return nil, nil, nil
+}
+
+func (p *testPeer) Broadcast(msg *pb.Message, peerType pb.PeerEndpoint_Type) error {
+	return nil
+}
+
+func (p *testPeer) Unicast(msg *pb.Message, receiverHandle *pb.PeerID) error {
+	return nil
+}
+
+func (p *testPeer) Sign(msg []byte) ([]byte, error) {
+	return msg, nil
+}
+
+func (p *testPeer) Verify(peerID *pb.PeerID, signature []byte, message []byte) error {
+	return nil
+}
+
+func (p *testPeer) BeginTxBatch(id interface{}) error {
+	return nil
+}
+
+func (p *testPeer) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error) {
+	return nil, nil
+}
+
+func (p *testPeer) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, error) {
+	return nil, nil
+}
+
+func (p *testPeer) RollbackTxBatch(id interface{}) error {
+	return nil
+}
+
+func (p *testPeer) PreviewCommitTxBatch(id interface{}, metadata []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (p *testPeer) InvalidateState() {}
+
+func (p *testPeer) ValidateState() {}
+
+func (p *testPeer) StoreState(key string, value []byte) error {
+	return nil
+}
+
+func (p *testPeer) ReadState(key string) ([]byte, error) {
+	return nil, fmt.Errorf("Not found")
+}
+
+func (p *testPeer) ReadStateSet(prefix string) (map[string][]byte, error) {
+	return nil, fmt.Errorf("Not found")
+}
+
+func (p *testPeer) DelState(key string) {}
@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orderer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/consensus"
+
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+const configPrefix = "CORE_ORDERER"
+
+var logger *logging.Logger // package-level logger
+
+// plugin registers the orderer under the name "orderer"
+var plugin = &consensus.Plugin{
+	Name:       "orderer",
+	LoadConfig: loadConfig,
+	Defaults: map[string]interface{}{
+		"general.address":           "localhost:7050",
+		"general.timeout.broadcast": "3s",
+		"general.timeout.reconnect": "1s",
+	},
+}
+
+func init() {
+	logger = logging.MustGetLogger("consensus/orderer")
+	plugin.New = func(stack consensus.Stack, config *viper.Viper) consensus.Consenter {
+		return newObcOrderer(config, stack)
+	}
+	consensus.RegisterPlugin(plugin)
+}
+
+// GetPlugin returns the handle to the Consenter singleton
+func GetPlugin(c consensus.Stack) consensus.Consenter {
+	return plugin.Singleton(c)
+}
+
+// New creates a Consenter which orders transactions through the ordering
+// service set in the configuration
+func New(stack consensus.Stack) consensus.Consenter {
+	return newObcOrderer(plugin.Config(), stack)
+}
+
+func loadConfig() (config *viper.Viper) {
+	config = viper.New()
+
+	// for environment variables
+	config.SetEnvPrefix(configPrefix)
+	config.AutomaticEnv()
+	replacer := strings.NewReplacer(".", "_")
+	config.SetEnvKeyReplacer(replacer)
+
+	config.SetConfigName("config")
+	config.AddConfigPath("./")
+	config.AddConfigPath("../consensus/orderer/")
+	config.AddConfigPath("../../consensus/orderer")
+	// Path to look for the config file in based on GOPATH
+	gopath := os.Getenv("GOPATH")
+	for _, p := range filepath.SplitList(gopath) {
+		ordererpath := filepath.Join(p, "src/github.com/hyperledger/fabric/consensus/orderer")
+		config.AddConfigPath(ordererpath)
+	}
+
+	err := config.ReadInConfig()
+	if err != nil {
+		panic(fmt.Errorf("Error reading %s plugin config: %s", configPrefix, err))
+	}
+	return
+}
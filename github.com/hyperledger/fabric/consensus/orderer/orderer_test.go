@@ -0,0 +1,173 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orderer
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric/orderer/solo"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// testOrderer runs the reference orderer in process, on a log which
+// survives its restarts
+type testOrderer struct {
+	dir     string
+	address string
+	server  *grpc.Server
+	solo    *solo.Server
+}
+
+func startOrderer(t *testing.T, dir, address string) *testOrderer {
+	s, err := solo.New(dir, 3, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Could not create orderer: %s", err)
+	}
+	sock, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatalf("Could not listen on %s: %s", address, err)
+	}
+	o := &testOrderer{dir: dir, address: sock.Addr().String(), server: grpc.NewServer(), solo: s}
+	pb.RegisterAtomicBroadcastServer(o.server, s)
+	go o.server.Serve(sock)
+	return o
+}
+
+func (o *testOrderer) stop() {
+	o.server.Stop()
+	o.solo.Close()
+}
+
+// startNetwork runs an orderer and n peers ordering through it
+func startNetwork(t *testing.T, n int) (*testOrderer, []*testPeer) {
+	dir, err := ioutil.TempDir("", "orderer")
+	if err != nil {
+		t.Fatalf("Could not create the directory of the log: %s", err)
+	}
+	o := startOrderer(t, dir, "127.0.0.1:0")
+	config := plugin.Config()
+	config.Set("general.address", o.address)
+	config.Set("general.timeout.broadcast", "500ms")
+	config.Set("general.timeout.reconnect", "50ms")
+
+	var peers []*testPeer
+	for i := 0; i < n; i++ {
+		p := newTestPeer(config)
+		p.start()
+		peers = append(peers, p)
+	}
+	return o, peers
+}
+
+func stopNetwork(o *testOrderer, peers []*testPeer) {
+	for _, p := range peers {
+		p.stop()
+	}
+	o.stop()
+	os.RemoveAll(o.dir)
+}
+
+// submit sends transactions first to first+count-1, each to the next peer
+func submit(t *testing.T, peers []*testPeer, first, count int) {
+	for i := first; i < first+count; i++ {
+		if err := peers[i%len(peers)].submit(fmt.Sprintf("tx%d", i)); err != nil {
+			t.Fatalf("Could not submit tx%d: %s", i, err)
+		}
+	}
+}
+
+// waitLedgers waits until every peer committed count transactions, and
+// checks they committed them in the same order
+func waitLedgers(t *testing.T, peers []*testPeer, count int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for i, p := range peers {
+		for len(p.transactions()) < count && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if txs := p.transactions(); len(txs) != count {
+			t.Fatalf("Peer %d committed %d transactions, expected %d", i, len(txs), count)
+		}
+	}
+	for i, p := range peers[1:] {
+		if !reflect.DeepEqual(p.transactions(), peers[0].transactions()) {
+			t.Errorf("Peers 0 and %d committed different logs:\n%v\n%v", i+1, peers[0].transactions(), p.transactions())
+		}
+	}
+}
+
+func TestPeersCommitSameLog(t *testing.T) {
+	o, peers := startNetwork(t, 4)
+	defer stopNetwork(o, peers)
+
+	submit(t, peers, 0, 20)
+	waitLedgers(t, peers, 20)
+
+	if err := peers[0].submit("tx3"); err == nil {
+		t.Errorf("A transaction should not be ordered twice")
+	}
+}
+
+func TestPeerRestart(t *testing.T) {
+	o, peers := startNetwork(t, 3)
+	defer stopNetwork(o, peers)
+
+	submit(t, peers, 0, 6)
+	waitLedgers(t, peers, 6)
+
+	// The others carry on while a peer is down, it resumes after the last
+	// batch it committed
+	peers[2].stop()
+	submit(t, peers[:2], 6, 6)
+	waitLedgers(t, peers[:2], 12)
+
+	peers[2].start()
+	submit(t, peers, 12, 3)
+	waitLedgers(t, peers, 15)
+}
+
+func TestOrdererRestart(t *testing.T) {
+	o, peers := startNetwork(t, 3)
+	defer func() { stopNetwork(o, peers) }()
+
+	submit(t, peers, 0, 6)
+	waitLedgers(t, peers, 6)
+
+	o.stop()
+	if err := peers[0].submit("tx6"); err == nil {
+		t.Errorf("Transactions should be refused while the ordering service is down")
+	}
+
+	// The restarted orderer restores its log, the peers connect again and
+	// receive the new batches
+	o = startOrderer(t, o.dir, o.address)
+	deadline := time.Now().Add(5 * time.Second)
+	for peers[0].submit("tx6") != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Peer did not connect to the restarted orderer")
+		}
+	}
+	submit(t, peers, 7, 5)
+	waitLedgers(t, peers, 12)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+// maxIdleBuckets is the number of token buckets kept before the ones of the
+// clients which did not submit for long enough to refill them are dropped
+const maxIdleBuckets = 1024
+
+// admissionControl bounds the requests clients submit to this replica, so
+// that a single client can not exhaust the request store. It is applied
+// before the requests are broadcast, the requests received from other
+// replicas are not limited: a replica may not reject what the primary
+// orders, or its timers would start a view change. Each client is limited by
+// a token bucket, and by a maximum number of outstanding requests, and the
+// outstanding requests of all the replicas are bounded by the queue size.
+// A limit of 0 disables the corresponding check.
+type admissionControl struct {
+	rate        float64 // requests per second each client may sustain
+	burst       float64 // requests each client may submit at once
+	outstanding int     // maximum outstanding requests of each client
+	queue       int     // maximum outstanding requests overall
+
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newAdmissionControl(config *viper.Viper) *admissionControl {
+	ac := &admissionControl{
+		rate:        config.GetFloat64("general.admission.rate"),
+		burst:       float64(config.GetInt("general.admission.burst")),
+		outstanding: config.GetInt("general.admission.outstanding"),
+		queue:       config.GetInt("general.admission.queue"),
+		buckets:     make(map[string]*tokenBucket),
+		now:         time.Now,
+	}
+	if ac.rate > 0 && ac.burst < 1 {
+		ac.burst = 1
+	}
+	return ac
+}
+
+// admit checks whether a new request of client may be broadcast, given the
+// number of requests outstanding from this client, and overall. It returns
+// the reason of the rejection if not.
+func (ac *admissionControl) admit(client string, outstanding, total int) error {
+	if ac.queue > 0 && total >= ac.queue {
+		return fmt.Errorf("request queue is full, %d requests outstanding", total)
+	}
+	if ac.outstanding > 0 && outstanding >= ac.outstanding {
+		return fmt.Errorf("client %s has %d requests outstanding, the maximum", client, outstanding)
+	}
+	if ac.rate <= 0 {
+		return nil
+	}
+
+	now := ac.now()
+	bucket, ok := ac.buckets[client]
+	if !ok {
+		ac.pruneBuckets(now)
+		bucket = &tokenBucket{tokens: ac.burst, last: now}
+		ac.buckets[client] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * ac.rate
+	if bucket.tokens > ac.burst {
+		bucket.tokens = ac.burst
+	}
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return fmt.Errorf("client %s exceeds its rate of %g requests per second", client, ac.rate)
+	}
+	bucket.tokens--
+	return nil
+}
+
+// pruneBuckets drops the buckets which are full again, a new bucket is full
+// as well, once there are too many of them
+func (ac *admissionControl) pruneBuckets(now time.Time) {
+	if len(ac.buckets) < maxIdleBuckets {
+		return
+	}
+	for client, bucket := range ac.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*ac.rate >= ac.burst {
+			delete(ac.buckets, client)
+		}
+	}
+}
+
+// clientOf returns the client a request is accounted to: the replica it was
+// submitted to, and the certificate of its transaction. The transactions
+// without certificate submitted to a replica are accounted together, and as
+// transaction certificates are unlinkable, each transaction signed with one
+// is accounted on its own.
+func clientOf(req *Request) string {
+	tx := &pb.Transaction{}
+	if req.Payload == nil || proto.Unmarshal(req.Payload, tx) != nil || len(tx.Cert) == 0 {
+		return fmt.Sprintf("%d", req.ReplicaId)
+	}
+	digest := sha256.Sum256(tx.Cert)
+	return fmt.Sprintf("%d/%x", req.ReplicaId, digest[:8])
+}
+
+// fairBatch takes up to n requests from reqs, alternating between their
+// clients in a round robin starting at the first client after start, and
+// preserving the order of the requests of each client. It returns the
+// batch, the requests left over, and the client to start the next round
+// robin after.
+func fairBatch(reqs []*Request, n int, start string) (batch, rest []*Request, next string) {
+	if len(reqs) <= n {
+		return reqs, nil, start
+	}
+
+	queues := make(map[string][]*Request)
+	var clients []string
+	for _, req := range reqs {
+		client := clientOf(req)
+		if _, ok := queues[client]; !ok {
+			clients = append(clients, client)
+		}
+		queues[client] = append(queues[client], req)
+	}
+	sort.Strings(clients)
+	first := sort.Search(len(clients), func(i int) bool { return clients[i] > start }) % len(clients)
+
+	taken := make(map[*Request]bool)
+	for len(batch) < n {
+		for i := range clients {
+			client := clients[(first+i)%len(clients)]
+			if len(queues[client]) == 0 || len(batch) == n {
+				continue
+			}
+			batch = append(batch, queues[client][0])
+			taken[queues[client][0]] = true
+			queues[client] = queues[client][1:]
+		}
+	}
+	for _, req := range reqs {
+		if !taken[req] {
+			rest = append(rest, req)
+		}
+	}
+	return batch, rest, clients[first]
+}
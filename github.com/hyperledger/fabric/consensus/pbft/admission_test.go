@@ -0,0 +1,239 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/consensus/util/events"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func TestAdmissionRateLimit(t *testing.T) {
+	config := loadConfig()
+	config.Set("general.admission.rate", 2)
+	config.Set("general.admission.burst", 3)
+	ac := newAdmissionControl(config)
+	now := time.Unix(1000, 0)
+	ac.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if err := ac.admit("1", 0, 0); err != nil {
+			t.Fatalf("Request %d of the burst should be admitted: %s", i, err)
+		}
+	}
+	if err := ac.admit("1", 0, 0); err == nil {
+		t.Errorf("Request beyond the burst should be rejected")
+	}
+	if err := ac.admit("2", 0, 0); err != nil {
+		t.Errorf("Another client should not be limited: %s", err)
+	}
+
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if err := ac.admit("1", 0, 0); err != nil {
+			t.Fatalf("Request %d should be admitted after a second: %s", i, err)
+		}
+	}
+	if err := ac.admit("1", 0, 0); err == nil {
+		t.Errorf("Request beyond the rate should be rejected")
+	}
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if err := ac.admit("1", 0, 0); err != nil {
+			t.Fatalf("Request %d should be admitted after an idle period: %s", i, err)
+		}
+	}
+	if err := ac.admit("1", 0, 0); err == nil {
+		t.Errorf("The tokens accumulated while idle should not exceed the burst")
+	}
+}
+
+func TestAdmissionBounds(t *testing.T) {
+	config := loadConfig()
+	config.Set("general.admission.outstanding", 2)
+	config.Set("general.admission.queue", 5)
+	ac := newAdmissionControl(config)
+
+	if err := ac.admit("1", 1, 4); err != nil {
+		t.Errorf("Request within the bounds should be admitted: %s", err)
+	}
+	if err := ac.admit("1", 2, 4); err == nil {
+		t.Errorf("Request beyond the outstanding requests of its client should be rejected")
+	}
+	if err := ac.admit("1", 0, 5); err == nil {
+		t.Errorf("Request beyond the queue size should be rejected")
+	}
+}
+
+func TestFairBatch(t *testing.T) {
+	var reqs []*Request
+	for i := 0; i < 6; i++ {
+		reqs = append(reqs, createPbftReq(int64(i), 1))
+	}
+	reqs = append(reqs, createPbftReq(6, 3), createPbftReq(7, 2), createPbftReq(8, 3))
+
+	submitters := func(batch []*Request) (ids []uint64) {
+		for _, req := range batch {
+			ids = append(ids, req.ReplicaId)
+		}
+		return
+	}
+	check := func(batch []*Request, expected ...uint64) {
+		ids := submitters(batch)
+		if len(ids) != len(expected) {
+			t.Fatalf("Expected batch of submitters %v, got %v", expected, ids)
+		}
+		for i := range ids {
+			if ids[i] != expected[i] {
+				t.Fatalf("Expected batch of submitters %v, got %v", expected, ids)
+			}
+		}
+	}
+
+	batch, rest, next := fairBatch(reqs, 4, "")
+	check(batch, 1, 2, 3, 1)
+	if batch[0] != reqs[0] || batch[3] != reqs[1] {
+		t.Errorf("The requests of a client should be batched in order")
+	}
+
+	batch, rest, next = fairBatch(rest, 4, next)
+	check(batch, 3, 1, 1, 1)
+	if len(rest) != 1 {
+		t.Fatalf("Expected 1 request left, got %d", len(rest))
+	}
+
+	batch, rest, _ = fairBatch(rest, 4, next)
+	check(batch, 1)
+	if rest != nil {
+		t.Errorf("Expected no requests left, got %d", len(rest))
+	}
+}
+
+func TestClientOf(t *testing.T) {
+	signed := func(cert string, replica uint64) *Request {
+		tx := createTx(1)
+		tx.Cert = []byte(cert)
+		return &Request{ReplicaId: replica, Payload: marshalTx(tx)}
+	}
+
+	if clientOf(createPbftReq(1, 2)) != clientOf(createPbftReq(2, 2)) {
+		t.Errorf("The requests without certificate of a replica should be accounted together")
+	}
+	if clientOf(signed("a", 1)) == clientOf(signed("b", 1)) {
+		t.Errorf("The requests of different certificates should be accounted apart")
+	}
+	if clientOf(signed("a", 1)) == clientOf(signed("a", 2)) {
+		t.Errorf("The requests submitted to different replicas should be accounted apart")
+	}
+}
+
+func TestBatchAdmission(t *testing.T) {
+	config := loadConfig()
+	config.Set("general.admission.outstanding", 2)
+	omni := *inertState
+	omni.BroadcastImpl = func(msg *pb.Message, peerType pb.PeerEndpoint_Type) error { return nil }
+	omni.UnicastImpl = func(ocMsg *pb.Message, peer *pb.PeerID) error { return nil }
+	b := newObcBatch(1, config, &omni)
+	defer b.Close()
+
+	b.StateUpdated(&checkpointMessage{seqNo: 0, id: inertState.GetBlockchainInfoBlobImpl()}, inertState.GetBlockchainInfoImpl())
+	b.manager.Queue() <- nil
+
+	submit := func(tag int64, cert string) {
+		tx := createTx(tag)
+		tx.Cert = []byte(cert)
+		events.SendEvent(b, batchMessageEvent{msg: &pb.Message{Type: pb.Message_CHAIN_TRANSACTION, Payload: marshalTx(tx)}})
+	}
+	recv := func(req *Request) {
+		payload, _ := proto.Marshal(&BatchMessage{Payload: &BatchMessage_Request{Request: req}})
+		events.SendEvent(b, batchMessageEvent{msg: &pb.Message{Type: pb.Message_CONSENSUS, Payload: payload}, sender: &pb.PeerID{Name: "vp2"}})
+	}
+	count := func(tag int64, cert string) int {
+		tx := createTx(tag)
+		tx.Cert = []byte(cert)
+		return b.reqStore.outstandingRequests.countFrom(clientOf(&Request{ReplicaId: 1, Payload: marshalTx(tx)}))
+	}
+
+	// A local client flooding the replica is limited
+	for i := int64(1); i <= 3; i++ {
+		submit(i, "flooder")
+	}
+	submit(4, "other")
+	b.manager.Queue() <- nil
+	if c := count(0, "flooder"); c != 2 {
+		t.Errorf("Expected 2 requests of the flooding client to be admitted, got %d", c)
+	}
+	if c := count(0, "other"); c != 1 {
+		t.Errorf("The request of another client should be admitted")
+	}
+
+	// The requests broadcast by other replicas are stored regardless, as
+	// the primary may order them
+	for i := int64(5); i <= 7; i++ {
+		recv(createPbftReq(i, 2))
+	}
+	b.manager.Queue() <- nil
+	if c := b.reqStore.outstandingRequests.countFrom(clientOf(createPbftReq(0, 2))); c != 3 {
+		t.Errorf("Expected the 3 requests of replica 2 to be stored, got %d", c)
+	}
+
+	// Once a request is executed, its client may submit again
+	events.SendEvent(b, workEvent(func() {
+		b.reqStore.remove(b.reqStore.outstandingRequests.order.Front().Value.(requestContainer).req)
+	}))
+	submit(8, "flooder")
+	b.manager.Queue() <- nil
+	if c := count(0, "flooder"); c != 2 {
+		t.Errorf("The flooding client should be admitted once it is below its limit, got %d requests", c)
+	}
+}
+
+func TestFairResubmission(t *testing.T) {
+	config := loadConfig()
+	config.Set("general.batchsize", 2)
+	omni := *inertState
+	omni.UnicastImpl = func(ocMsg *pb.Message, dest *pb.PeerID) error { return nil }
+	b := newObcBatch(0, config, &omni)
+	defer b.Close()
+
+	b.StateUpdated(&checkpointMessage{seqNo: 0, id: inertState.GetBlockchainInfoBlobImpl()}, inertState.GetBlockchainInfoImpl())
+	b.manager.Queue() <- nil
+
+	// Replica 1 floods the primary, before replica 2 submits a request
+	for i := 0; i < 4; i++ {
+		b.reqStore.storeOutstanding(createPbftReq(int64(i), 1))
+	}
+	b.reqStore.storeOutstanding(createPbftReq(4, 2))
+
+	events.SendEvent(b, viewChangedEvent{})
+
+	cert, ok := b.pbft.certStore[msgID{v: 0, n: 1}]
+	if !ok || cert.prePrepare == nil {
+		t.Fatalf("Primary should have sent a pre-prepare for the first batch")
+	}
+	batch := cert.prePrepare.RequestBatch.GetBatch()
+	if len(batch) != 2 || batch[0].ReplicaId != 1 || batch[1].ReplicaId != 2 {
+		t.Errorf("The first batch should alternate between replicas 1 and 2: %v", batch)
+	}
+	if len(b.batchStore) != 1 || !b.batchTimerActive {
+		t.Errorf("The last request should wait for the batch timer")
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/hyperledger/fabric/core/util"
+)
+
+// Messages are authenticated in one of three modes, set by general.authentication.
+// With none, only view changes are signed, the other messages are authenticated
+// by the connection between the replicas. With signatures, pre-prepares,
+// prepares, commits and checkpoints are signed through the stack as well. With
+// MACs, pre-prepares, prepares and commits carry an authenticator instead: a
+// vector of HMACs, one for each receiving replica, under keys the replicas
+// agree on when they connect. Checkpoints and session keys remain signed. View
+// changes are signed in every mode, as their signature must be verifiable by
+// any replica which receives them in a new view.
+const (
+	authNone       = "none"
+	authSignatures = "signatures"
+	authMACs       = "macs"
+)
+
+// sessionKeyRetry is the minimum delay between two session keys sent to the same replica
+const sessionKeyRetry = time.Second
+
+// sessionKeys holds the ephemeral ECDH key of a replica, and the HMAC key it
+// shares with each of the other replicas. A new ephemeral key is generated
+// each time the replica starts, the shared keys are never persisted.
+type sessionKeys struct {
+	private []byte
+	public  []byte
+	shared  map[uint64][]byte    // HMAC key shared with each replica
+	sent    map[uint64]time.Time // when we last sent our session key to each replica
+}
+
+func newSessionKeys() (*sessionKeys, error) {
+	private, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionKeys{
+		private: private,
+		public:  elliptic.Marshal(elliptic.P256(), x, y),
+		shared:  make(map[uint64][]byte),
+		sent:    make(map[uint64]time.Time),
+	}, nil
+}
+
+// derive computes the HMAC key shared between replicas self and peer, from the public key of peer
+func (sk *sessionKeys) derive(self, peer uint64, public []byte) error {
+	curve := elliptic.P256()
+	x, y := elliptic.Unmarshal(curve, public)
+	if x == nil {
+		return fmt.Errorf("invalid session key")
+	}
+	secret, _ := curve.ScalarMult(x, y, sk.private)
+
+	low, high := self, peer
+	if low > high {
+		low, high = high, low
+	}
+	info := make([]byte, 16)
+	binary.BigEndian.PutUint64(info, low)
+	binary.BigEndian.PutUint64(info[8:], high)
+
+	key := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret.Bytes(), nil, info), key); err != nil {
+		return err
+	}
+	sk.shared[peer] = key
+	return nil
+}
+
+// mac computes the HMAC of raw under the key shared with peer, it returns false if there is no such key
+func (sk *sessionKeys) mac(peer uint64, raw []byte) ([]byte, bool) {
+	key, ok := sk.shared[peer]
+	if !ok {
+		return nil, false
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(raw)
+	return h.Sum(nil), true
+}
+
+// isNormalCase returns whether a message is authenticated by MACs in MAC mode
+func isNormalCase(msg *Message) bool {
+	return msg.GetPrePrepare() != nil || msg.GetPrepare() != nil || msg.GetCommit() != nil
+}
+
+// isAuthenticated returns whether a message is signed, or carries an
+// authenticator, in the authentication mode of the replica. The others are
+// either self-certifying, harmless or carry their own signature.
+func (instance *pbftCore) isAuthenticated(msg *Message) bool {
+	if instance.authentication == authNone {
+		return false
+	}
+	return isNormalCase(msg) || msg.GetCheckpoint() != nil || msg.GetSessionKey() != nil
+}
+
+// authenticate signs a message we send, or adds its authenticator in MAC mode
+func (instance *pbftCore) authenticate(msg *Message) error {
+	msg.Signature = nil
+	msg.Authenticator = nil
+	if !instance.isAuthenticated(msg) {
+		return nil
+	}
+	raw, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	if instance.sessionKeys == nil || !isNormalCase(msg) {
+		msg.Signature, err = instance.consumer.sign(util.ComputeCryptoHash(raw))
+		return err
+	}
+
+	msg.Authenticator = make(map[uint64][]byte)
+	for _, id := range instance.replicas {
+		if id == instance.id {
+			continue
+		}
+		if mac, ok := instance.sessionKeys.mac(id, raw); ok {
+			msg.Authenticator[id] = mac
+		} else {
+			logger.Debugf("Replica %d has no session key for replica %d", instance.id, id)
+			instance.sendSessionKey(id, false)
+		}
+	}
+	return nil
+}
+
+// checkAuthentication verifies the signature, or in MAC mode the MAC, of a message received from a replica
+func (instance *pbftCore) checkAuthentication(msg *Message, senderID uint64) error {
+	if !instance.isAuthenticated(msg) {
+		return nil
+	}
+	signature, authenticator := msg.Signature, msg.Authenticator
+	msg.Signature, msg.Authenticator = nil, nil
+	raw, err := proto.Marshal(msg)
+	msg.Signature, msg.Authenticator = signature, authenticator
+	if err != nil {
+		return err
+	}
+
+	if instance.sessionKeys == nil || !isNormalCase(msg) {
+		return instance.consumer.verify(senderID, signature, util.ComputeCryptoHash(raw))
+	}
+
+	expected, ok := instance.sessionKeys.mac(senderID, raw)
+	if !ok || !hmac.Equal(expected, authenticator[instance.id]) {
+		// Either of us may have restarted since the keys were exchanged
+		instance.sendSessionKey(senderID, false)
+		return fmt.Errorf("Replica %d could not authenticate message from replica %d", instance.id, senderID)
+	}
+	return nil
+}
+
+// announceSessionKey sends our session key to all the replicas, in MAC mode
+func (instance *pbftCore) announceSessionKey() {
+	if instance.sessionKeys == nil {
+		return
+	}
+	for _, id := range instance.replicas {
+		if id != instance.id {
+			instance.sendSessionKey(id, false)
+		}
+	}
+}
+
+// sendSessionKey sends our session key to a replica, a reply is expected
+// unless this is a reply. Session keys to the same replica are rate limited.
+func (instance *pbftCore) sendSessionKey(receiverID uint64, reply bool) {
+	if last, ok := instance.sessionKeys.sent[receiverID]; ok && time.Since(last) < sessionKeyRetry {
+		logger.Debugf("Replica %d sent its session key to replica %d recently, not sending it again", instance.id, receiverID)
+		return
+	}
+	instance.sessionKeys.sent[receiverID] = time.Now()
+
+	msg := &Message{Payload: &Message_SessionKey{SessionKey: &SessionKey{
+		ReplicaId: instance.id,
+		PublicKey: instance.sessionKeys.public,
+		Reply:     reply,
+	}}}
+	if err := instance.authenticate(msg); err != nil {
+		logger.Errorf("Replica %d could not sign its session key: %s", instance.id, err)
+		return
+	}
+	msgRaw, err := proto.Marshal(msg)
+	if err != nil {
+		logger.Errorf("Replica %d could not marshal its session key: %s", instance.id, err)
+		return
+	}
+	logger.Debugf("Replica %d sending its session key to replica %d", instance.id, receiverID)
+	instance.consumer.unicast(msgRaw, receiverID)
+}
+
+func (instance *pbftCore) recvSessionKey(sk *SessionKey) error {
+	if instance.sessionKeys == nil {
+		logger.Debugf("Replica %d authenticates messages with signatures, ignoring session key from replica %d", instance.id, sk.ReplicaId)
+		return nil
+	}
+	if err := instance.sessionKeys.derive(instance.id, sk.ReplicaId, sk.PublicKey); err != nil {
+		return fmt.Errorf("Replica %d received a bad session key from replica %d: %s", instance.id, sk.ReplicaId, err)
+	}
+	logger.Debugf("Replica %d derived session key with replica %d", instance.id, sk.ReplicaId)
+	if !sk.Reply {
+		delete(instance.sessionKeys.sent, sk.ReplicaId) // The replica restarted, or lost our key
+		instance.sendSessionKey(sk.ReplicaId, true)
+	}
+	return nil
+}
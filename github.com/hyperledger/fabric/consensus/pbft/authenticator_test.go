@@ -0,0 +1,260 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+)
+
+func makeMACNetwork(t *testing.T, validatorCount int) *pbftNetwork {
+	config := loadConfig()
+	config.Set("general.authentication", authMACs)
+	net := makePBFTNetwork(validatorCount, config)
+	for _, pe := range net.pbftEndpoints {
+		pe := pe
+		pe.manager.Queue() <- workEvent(func() { pe.pbft.announceSessionKey() })
+	}
+	if err := net.process(); err != nil {
+		t.Fatalf("Processing failed: %s", err)
+	}
+	return net
+}
+
+// checkSessionKeys verifies that every pair of replicas agrees on its session key
+func checkSessionKeys(t *testing.T, net *pbftNetwork) {
+	for _, a := range net.pbftEndpoints {
+		for _, b := range net.pbftEndpoints {
+			if a.id == b.id {
+				continue
+			}
+			key := a.pbft.sessionKeys.shared[b.id]
+			if key == nil || !bytes.Equal(key, b.pbft.sessionKeys.shared[a.id]) {
+				t.Errorf("Replicas %d and %d do not share a session key", a.id, b.id)
+			}
+		}
+	}
+}
+
+func TestMACNetwork(t *testing.T) {
+	validatorCount := 4
+	net := makeMACNetwork(t, validatorCount)
+	defer net.stop()
+	checkSessionKeys(t, net)
+
+	reqBatch := createPbftReqBatch(1, uint64(generateBroadcaster(validatorCount)))
+	net.pbftEndpoints[0].manager.Queue() <- reqBatch
+	if err := net.process(); err != nil {
+		t.Fatalf("Processing failed: %s", err)
+	}
+
+	for _, pep := range net.pbftEndpoints {
+		if pep.sc.executions != 1 {
+			t.Errorf("Instance %d executed %d transactions, expected 1", pep.id, pep.sc.executions)
+		}
+	}
+}
+
+func TestMACSessionKeyRenewal(t *testing.T) {
+	validatorCount := 4
+	net := makeMACNetwork(t, validatorCount)
+	defer net.stop()
+
+	// Replica 2 restarts with a new session key, which it did not announce
+	restarted := net.pbftEndpoints[2]
+	restarted.manager.Queue() <- workEvent(func() {
+		restarted.pbft.sessionKeys, _ = newSessionKeys()
+	})
+
+	// The others still order without replica 2, and exchange keys with it
+	net.pbftEndpoints[0].manager.Queue() <- createPbftReqBatch(1, uint64(generateBroadcaster(validatorCount)))
+	if err := net.process(); err != nil {
+		t.Fatalf("Processing failed: %s", err)
+	}
+	for _, pep := range net.pbftEndpoints {
+		if pep.id != restarted.id && pep.sc.executions != 1 {
+			t.Errorf("Instance %d executed %d transactions, expected 1", pep.id, pep.sc.executions)
+		}
+	}
+	checkSessionKeys(t, net)
+}
+
+func TestMACAuthentication(t *testing.T) {
+	net := makeMACNetwork(t, 4)
+	defer net.stop()
+	sender, receiver := net.pbftEndpoints[1].pbft, net.pbftEndpoints[0].pbft
+
+	newCommit := func() *Message {
+		msg := &Message{Payload: &Message_Commit{Commit: &Commit{View: 0, SequenceNumber: 1, BatchDigest: "foo", ReplicaId: 1}}}
+		if err := sender.authenticate(msg); err != nil {
+			t.Fatalf("Could not authenticate commit: %s", err)
+		}
+		return msg
+	}
+
+	msg := newCommit()
+	if len(msg.Authenticator) != 3 || msg.Signature != nil {
+		t.Errorf("Commit should carry a MAC for each other replica, and no signature: %v", msg)
+	}
+	if err := receiver.checkAuthentication(msg, 1); err != nil {
+		t.Errorf("Commit should authenticate: %s", err)
+	}
+	if err := receiver.checkAuthentication(msg, 3); err == nil {
+		t.Errorf("Commit should not authenticate as sent by replica 3")
+	}
+
+	msg.GetCommit().BatchDigest = "bar"
+	if err := receiver.checkAuthentication(msg, 1); err == nil {
+		t.Errorf("Modified commit should not authenticate")
+	}
+
+	msg = newCommit()
+	msg.Authenticator[0] = msg.Authenticator[2]
+	if err := receiver.checkAuthentication(msg, 1); err == nil {
+		t.Errorf("Commit should not authenticate with the MAC of another replica")
+	}
+
+	chkpt := &Message{Payload: &Message_Checkpoint{Checkpoint: &Checkpoint{SequenceNumber: 10, ReplicaId: 1, Id: "foo"}}}
+	if err := sender.authenticate(chkpt); err != nil {
+		t.Fatalf("Could not authenticate checkpoint: %s", err)
+	}
+	if chkpt.Authenticator != nil || chkpt.Signature == nil {
+		t.Errorf("Checkpoint should be signed: %v", chkpt)
+	}
+}
+
+func TestAuthenticationModes(t *testing.T) {
+	signed := 0
+	mock := &omniProto{
+		signImpl: func(msg []byte) ([]byte, error) {
+			signed++
+			return []byte("signature"), nil
+		},
+	}
+	newMessages := func() []*Message {
+		return []*Message{
+			{Payload: &Message_Commit{Commit: &Commit{View: 0, SequenceNumber: 1, BatchDigest: "foo", ReplicaId: 1}}},
+			{Payload: &Message_Checkpoint{Checkpoint: &Checkpoint{SequenceNumber: 10, ReplicaId: 1, Id: "foo"}}},
+		}
+	}
+
+	instance := newPbftCore(1, loadConfig(), mock, &inertTimerFactory{})
+	defer instance.close()
+	if instance.authentication != authNone {
+		t.Fatalf("Expected only view changes to be signed by default, authentication is %s", instance.authentication)
+	}
+	for _, msg := range newMessages() {
+		if err := instance.authenticate(msg); err != nil || msg.Signature != nil {
+			t.Errorf("Expected %v not to be signed by default: %v", msg, err)
+		}
+		if err := instance.checkAuthentication(msg, 1); err != nil {
+			t.Errorf("Expected %v to be accepted without signature by default: %s", msg, err)
+		}
+	}
+	if signed != 0 {
+		t.Errorf("Expected nothing to be signed by default, %d messages signed", signed)
+	}
+
+	config := loadConfig()
+	config.Set("general.authentication", authSignatures)
+	instance = newPbftCore(1, config, mock, &inertTimerFactory{})
+	defer instance.close()
+	for _, msg := range newMessages() {
+		if err := instance.authenticate(msg); err != nil || msg.Signature == nil {
+			t.Errorf("Expected %v to be signed in signature mode: %v", msg, err)
+		}
+	}
+}
+
+// ecdsaConsumer signs and verifies with ECDSA, as the stack does with security enabled
+type ecdsaConsumer struct {
+	simpleConsumer
+	key  *ecdsa.PrivateKey
+	keys map[uint64]*ecdsa.PrivateKey
+}
+
+func (ec *ecdsaConsumer) sign(msg []byte) ([]byte, error) {
+	return primitives.ECDSASign(ec.key, msg)
+}
+
+func (ec *ecdsaConsumer) verify(senderID uint64, signature []byte, message []byte) error {
+	ok, err := primitives.ECDSAVerify(&ec.keys[senderID].PublicKey, message, signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// benchmarkAuthentication measures authenticating a commit, and verifying it at every other replica
+func benchmarkAuthentication(b *testing.B, mode string) {
+	validatorCount := 4
+	if err := primitives.InitSecurityLevel("SHA3", 256); err != nil {
+		b.Fatal(err)
+	}
+	config := loadConfig()
+	config.Set("general.authentication", mode)
+	config.Set("general.N", validatorCount)
+	config.Set("general.f", (validatorCount-1)/3)
+
+	keys := make(map[uint64]*ecdsa.PrivateKey)
+	var replicas []*pbftCore
+	for id := uint64(0); id < uint64(validatorCount); id++ {
+		key, err := primitives.NewECDSAKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		keys[id] = key
+		replicas = append(replicas, newPbftCore(id, config, &ecdsaConsumer{key: key, keys: keys}, &inertTimerFactory{}))
+	}
+	if mode == authMACs {
+		for _, a := range replicas {
+			for _, b := range replicas {
+				if a != b {
+					a.sessionKeys.derive(a.id, b.id, b.sessionKeys.public)
+				}
+			}
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		msg := &Message{Payload: &Message_Commit{Commit: &Commit{View: 0, SequenceNumber: uint64(i), BatchDigest: "foo", ReplicaId: 0}}}
+		if err := replicas[0].authenticate(msg); err != nil {
+			b.Fatal(err)
+		}
+		for _, r := range replicas[1:] {
+			if err := r.checkAuthentication(msg, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSignatureAuthentication(b *testing.B) {
+	benchmarkAuthentication(b, authSignatures)
+}
+
+func BenchmarkMACAuthentication(b *testing.B) {
+	benchmarkAuthentication(b, authMACs)
+}
@@ -18,10 +18,12 @@ package pbft
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/hyperledger/fabric/consensus"
 	"github.com/hyperledger/fabric/consensus/util/events"
+	"github.com/hyperledger/fabric/events/producer"
 	pb "github.com/hyperledger/fabric/protos"
 
 	"github.com/golang/protobuf/proto"
@@ -51,6 +53,9 @@ type obcBatch struct {
 
 	deduplicator *deduplicator
 
+	admission  *admissionControl // Bounds the requests clients submit to this replica
+	lastClient string            // Client the last batch started with
+
 	persistForward
 }
 
@@ -67,6 +72,9 @@ type batchMessageEvent batchMessage
 // batchTimerEvent is sent when the batch timer expires
 type batchTimerEvent struct{}
 
+// reconfigurationEvent is sent when a change of the set of replicas is requested
+type reconfigurationEvent Reconfiguration
+
 func newObcBatch(id uint64, config *viper.Viper, stack consensus.Stack) *obcBatch {
 	var err error
 
@@ -82,11 +90,16 @@ func newObcBatch(id uint64, config *viper.Viper, stack consensus.Stack) *obcBatc
 	op.manager.SetReceiver(op)
 	etf := events.NewTimerFactoryImpl(op.manager)
 	op.pbft = newPbftCore(id, config, op, etf)
+	if scoped, ok := stack.(chainScoped); ok {
+		op.pbft.chainID = scoped.ChainID()
+	}
 	op.manager.Start()
 	blockchainInfoBlob := stack.GetBlockchainInfoBlob()
 	op.externalEventReceiver.manager = op.manager
 	op.broadcaster = newBroadcaster(id, op.pbft.N, op.pbft.f, op.pbft.broadcastTimeout, stack)
 	op.manager.Queue() <- workEvent(func() {
+		op.broadcaster.setReplicas(op.pbft.replicas, op.pbft.f)
+		op.pbft.announceSessionKey()
 		op.pbft.stateTransfer(&stateUpdateTarget{
 			checkpointMessage: checkpointMessage{
 				seqNo: op.pbft.lastExec,
@@ -101,18 +114,11 @@ func newObcBatch(id uint64, config *viper.Viper, stack consensus.Stack) *obcBatc
 	if err != nil {
 		panic(fmt.Errorf("Cannot parse batch timeout: %s", err))
 	}
+	op.refreshNetworkConfig()
 	logger.Infof("PBFT Batch size = %d", op.batchSize)
 	logger.Infof("PBFT Batch timeout = %v", op.batchTimeout)
 
-	if op.batchTimeout >= op.pbft.requestTimeout {
-		op.pbft.requestTimeout = 3 * op.batchTimeout / 2
-		logger.Warningf("Configured request timeout must be greater than batch timeout, setting to %v", op.pbft.requestTimeout)
-	}
-
-	if op.pbft.requestTimeout >= op.pbft.nullRequestTimeout && op.pbft.nullRequestTimeout != 0 {
-		op.pbft.nullRequestTimeout = 3 * op.pbft.requestTimeout / 2
-		logger.Warningf("Configured null request timeout must be greater than request timeout, setting to %v", op.pbft.nullRequestTimeout)
-	}
+	op.adjustTimeouts()
 
 	op.incomingChan = make(chan *batchMessage)
 
@@ -120,7 +126,8 @@ func newObcBatch(id uint64, config *viper.Viper, stack consensus.Stack) *obcBatc
 
 	op.reqStore = newRequestStore()
 
-	op.deduplicator = newDeduplicator()
+	op.deduplicator = newDeduplicator(config.GetInt("general.replaywindow"), stack)
+	op.admission = newAdmissionControl(config)
 
 	op.idleChan = make(chan struct{})
 	close(op.idleChan) // TODO remove eventually
@@ -128,13 +135,65 @@ func newObcBatch(id uint64, config *viper.Viper, stack consensus.Stack) *obcBatc
 	return op
 }
 
+// adjustTimeouts makes sure the request timeout exceeds the batch timeout,
+// and the null request timeout the request timeout
+func (op *obcBatch) adjustTimeouts() {
+	if op.batchTimeout >= op.pbft.requestTimeout {
+		op.pbft.requestTimeout = 3 * op.batchTimeout / 2
+		logger.Warningf("Configured request timeout must be greater than batch timeout, setting to %v", op.pbft.requestTimeout)
+	}
+
+	if op.pbft.requestTimeout >= op.pbft.nullRequestTimeout && op.pbft.nullRequestTimeout != 0 {
+		op.pbft.nullRequestTimeout = 3 * op.pbft.requestTimeout / 2
+		logger.Warningf("Configured null request timeout must be greater than request timeout, setting to %v", op.pbft.nullRequestTimeout)
+	}
+}
+
+// refreshNetworkConfig applies the batch size and batch timeout held on
+// chain, if the stack can read them. It runs whenever the ledger moves to a
+// new block so that all replicas switch to new values at the same block.
+// Values which are not set on chain keep the ones of the local configuration.
+func (op *obcBatch) refreshNetworkConfig() {
+	reader, ok := op.stack.(consensus.NetworkConfigReader)
+	if !ok {
+		return
+	}
+
+	if value, found, err := reader.ReadNetworkConfig("pbft.general.batchsize"); err != nil {
+		logger.Warningf("Replica %d could not read batch size from network configuration: %s", op.pbft.id, err)
+	} else if found {
+		if size, err := strconv.Atoi(value); err != nil || size <= 0 {
+			logger.Warningf("Replica %d ignoring invalid batch size %q in network configuration", op.pbft.id, value)
+		} else if size != op.batchSize {
+			logger.Infof("Replica %d changing batch size from %d to %d", op.pbft.id, op.batchSize, size)
+			op.batchSize = size
+		}
+	}
+
+	if value, found, err := reader.ReadNetworkConfig("pbft.general.timeout.batch"); err != nil {
+		logger.Warningf("Replica %d could not read batch timeout from network configuration: %s", op.pbft.id, err)
+	} else if found {
+		if timeout, err := time.ParseDuration(value); err != nil || timeout <= 0 {
+			logger.Warningf("Replica %d ignoring invalid batch timeout %q in network configuration", op.pbft.id, value)
+		} else if timeout != op.batchTimeout {
+			logger.Infof("Replica %d changing batch timeout from %v to %v", op.pbft.id, op.batchTimeout, timeout)
+			op.batchTimeout = timeout
+			op.adjustTimeouts()
+		}
+	}
+}
+
 // Close tells us to release resources we are holding
 func (op *obcBatch) Close() {
 	op.batchTimer.Halt()
 	op.pbft.close()
+	op.manager.Halt()
 }
 
 func (op *obcBatch) submitToLeader(req *Request) events.Event {
+	if !op.admit(req) {
+		return nil
+	}
 	// Broadcast the request to the network, in case we're in the wrong view
 	op.broadcastMsg(&BatchMessage{Payload: &BatchMessage_Request{Request: req}})
 	op.logAddTxFromRequest(req)
@@ -196,6 +255,13 @@ func (op *obcBatch) verify(senderID uint64, signature []byte, message []byte) er
 func (op *obcBatch) execute(seqNo uint64, reqBatch *RequestBatch) {
 	var txs []*pb.Transaction
 	for _, req := range reqBatch.GetBatch() {
+		if reconfig := req.GetReconfiguration(); reconfig != nil {
+			// The reconfiguration was recorded by pbft-core, it is not a transaction
+			logger.Debugf("Batch replica %d executing %s of replica %d, seqNo=%d", op.pbft.id, reconfig.Action, reconfig.ReplicaId, seqNo)
+			op.reqStore.remove(req)
+			op.deduplicator.Execute(req, "")
+			continue
+		}
 		tx := &pb.Transaction{}
 		if err := proto.Unmarshal(req.Payload, tx); err != nil {
 			logger.Warningf("Batch replica %d could not unmarshal transaction %s", op.pbft.id, err)
@@ -206,13 +272,45 @@ func (op *obcBatch) execute(seqNo uint64, reqBatch *RequestBatch) {
 			logger.Debugf("Batch replica %d missing transaction %s outstanding=%v, pending=%v", op.pbft.id, tx.Txid, outstanding, pending)
 		}
 		txs = append(txs, tx)
-		op.deduplicator.Execute(req)
+		op.deduplicator.Execute(req, tx.Txid)
 	}
-	meta, _ := proto.Marshal(&Metadata{seqNo})
+	meta, _ := proto.Marshal(&Metadata{SeqNo: seqNo, Membership: op.pbft.membership()})
 	logger.Debugf("Batch replica %d received exec for seqNo %d containing %d transactions", op.pbft.id, seqNo, len(txs))
 	op.stack.Execute(meta, txs) // This executes in the background, we will receive an executedEvent once it completes
 }
 
+// membershipChanged is called when pbft-core changes the set of replicas
+func (op *obcBatch) membershipChanged(replicas []uint64, f int) {
+	if op.broadcaster == nil {
+		// Still being constructed, the broadcaster picks up the restored membership once created
+		return
+	}
+	op.broadcaster.setReplicas(replicas, f)
+}
+
+// =============================================================================
+// MembershipManager interface
+// =============================================================================
+
+// ReconfigureValidators orders the addition or removal of a validator,
+// which takes effect at the checkpoint following its execution. Every
+// replica checks the signatures of the administrators when executing it, a
+// request without enough of them is refused here already.
+func (op *obcBatch) ReconfigureValidators(req *pb.ValidatorReconfiguration) error {
+	if err := op.pbft.verifyChain(req); err != nil {
+		return err
+	}
+	reconfig, err := newReconfiguration(req)
+	if err != nil {
+		return err
+	}
+	if err = op.pbft.verifyAdminSignatures(reconfig.Request, reconfig.Signatures); err != nil {
+		return fmt.Errorf("Refusing %s of replica %d: %s", reconfig.Action, reconfig.ReplicaId, err)
+	}
+	op.manager.Queue() <- reconfigurationEvent(*reconfig)
+	return nil
+}
+
 // =============================================================================
 // functions specific to batch mode
 // =============================================================================
@@ -242,9 +340,12 @@ func (op *obcBatch) sendBatch() events.Event {
 		return nil
 	}
 
-	reqBatch := &RequestBatch{Batch: op.batchStore}
-	op.batchStore = nil
+	reqBatch := &RequestBatch{}
+	reqBatch.Batch, op.batchStore, op.lastClient = fairBatch(op.batchStore, op.batchSize, op.lastClient)
 	logger.Infof("Creating batch with %d requests", len(reqBatch.Batch))
+	if len(op.batchStore) > 0 {
+		op.startBatchTimer()
+	}
 	return reqBatch
 }
 
@@ -262,6 +363,12 @@ func (op *obcBatch) txToReq(tx []byte) *Request {
 	return req
 }
 
+func (op *obcBatch) reconfigToReq(reconfig *Reconfiguration) *Request {
+	req := op.txToReq(nil)
+	req.Reconfiguration = reconfig
+	return req
+}
+
 func (op *obcBatch) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) events.Event {
 	if ocMsg.Type == pb.Message_CHAIN_TRANSACTION {
 		req := op.txToReq(ocMsg.Payload)
@@ -281,8 +388,7 @@ func (op *obcBatch) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) e
 	}
 
 	if req := batchMsg.GetRequest(); req != nil {
-		if !op.deduplicator.IsNew(req) {
-			logger.Warningf("Replica %d ignoring request as it is too old", op.pbft.id)
+		if !op.isNew(req) {
 			return nil
 		}
 
@@ -315,6 +421,48 @@ func (op *obcBatch) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) e
 	return nil
 }
 
+// isNew returns whether a request may enter the request store: it must be
+// newer than the last executed request of its replica, and its transaction
+// must not have been executed recently
+func (op *obcBatch) isNew(req *Request) bool {
+	if !op.deduplicator.IsNew(req) {
+		logger.Warningf("Replica %d ignoring request as it is too old", op.pbft.id)
+		return false
+	}
+	if req.Payload == nil {
+		return true // Reconfigurations carry no transaction
+	}
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(req.Payload, tx); err == nil && tx.Txid != "" && op.deduplicator.IsReplay(tx.Txid) {
+		logger.Warningf("Replica %d ignoring request as transaction %s was already executed", op.pbft.id, tx.Txid)
+		return false
+	}
+	return true
+}
+
+// admit returns whether a request submitted to this replica may be
+// broadcast: it must be new, and its client must be within the admission
+// limits. The rejection of a transaction is sent back to the client as a
+// Rejection event.
+func (op *obcBatch) admit(req *Request) bool {
+	if !op.isNew(req) {
+		return false
+	}
+	if req.Payload == nil {
+		return true // Reconfigurations are not limited
+	}
+	client := clientOf(req)
+	err := op.admission.admit(client, op.reqStore.outstandingRequests.countFrom(client), op.reqStore.outstandingRequests.Len())
+	if err == nil {
+		return true
+	}
+	tx := &pb.Transaction{}
+	proto.Unmarshal(req.Payload, tx)
+	logger.Warningf("Replica %d rejecting transaction %s: %s", op.pbft.id, tx.Txid, err)
+	producer.Send(producer.CreateRejectionEvent(tx, err.Error()))
+	return false
+}
+
 func (op *obcBatch) logAddTxFromRequest(req *Request) {
 	if logger.IsEnabledFor(logging.DEBUG) {
 		// This is potentially a very large expensive debug statement, guard
@@ -335,17 +483,17 @@ func (op *obcBatch) resubmitOutstandingReqs() events.Event {
 	// we run out of requests, or a new batch message is triggered (this path will re-enter after execution)
 	// Do not enter while an execution is in progress to prevent duplicating a request
 	if op.pbft.primary(op.pbft.view) == op.pbft.id && op.pbft.activeView && op.pbft.currentExec == nil {
-		needed := op.batchSize - len(op.batchStore)
-
-		for op.reqStore.hasNonPending() {
-			outstanding := op.reqStore.getNextNonPending(needed)
-
-			// If we have enough outstanding requests, this will trigger a batch
-			for _, nreq := range outstanding {
-				if msg := op.leaderProcReq(nreq); msg != nil {
-					op.manager.Inject(msg)
-				}
-			}
+		// Queue them all before assembling the batches, so that the batches
+		// alternate fairly between the clients
+		for _, nreq := range op.reqStore.getNextNonPending(op.reqStore.outstandingRequests.Len()) {
+			op.batchStore = append(op.batchStore, nreq)
+			op.reqStore.storePending(nreq)
+		}
+		for len(op.batchStore) >= op.batchSize {
+			op.manager.Inject(op.sendBatch())
+		}
+		if len(op.batchStore) > 0 && !op.batchTimerActive {
+			op.startBatchTimer()
 		}
 	}
 	return nil
@@ -362,6 +510,7 @@ func (op *obcBatch) ProcessEvent(event events.Event) events.Event {
 		op.stack.Commit(nil, et.tag.([]byte))
 	case committedEvent:
 		logger.Debugf("Replica %d received committedEvent", op.pbft.id)
+		op.refreshNetworkConfig()
 		return execDoneEvent{}
 	case execDoneEvent:
 		if res := op.pbft.ProcessEvent(event); res != nil {
@@ -369,6 +518,10 @@ func (op *obcBatch) ProcessEvent(event events.Event) events.Event {
 			return res
 		}
 		return op.resubmitOutstandingReqs()
+	case reconfigurationEvent:
+		reconfig := Reconfiguration(et)
+		logger.Infof("Replica %d submitting %s of replica %d", op.pbft.id, reconfig.Action, reconfig.ReplicaId)
+		return op.submitToLeader(op.reconfigToReq(&reconfig))
 	case batchTimerEvent:
 		logger.Infof("Replica %d batch timer expired", op.pbft.id)
 		if op.pbft.activeView && (len(op.batchStore) > 0) {
@@ -424,6 +577,7 @@ func (op *obcBatch) ProcessEvent(event events.Event) events.Event {
 	case stateUpdatedEvent:
 		// When the state is updated, clear any outstanding requests, they may have been processed while we were gone
 		op.reqStore = newRequestStore()
+		op.refreshNetworkConfig()
 		return op.pbft.ProcessEvent(event)
 	default:
 		return op.pbft.ProcessEvent(event)
@@ -393,3 +393,43 @@ func TestClearBatchStoreOnViewChange(t *testing.T) {
 		t.Fatalf("Should have cleared the batch store on view change")
 	}
 }
+
+// configStack is a stack whose network configuration is held in a map
+type configStack struct {
+	*omniProto
+	config map[string]string
+}
+
+func (cs *configStack) ReadNetworkConfig(name string) (string, bool, error) {
+	value, ok := cs.config[name]
+	return value, ok, nil
+}
+
+func TestNetworkConfigAppliedOnCommit(t *testing.T) {
+	omni := *inertState
+	omni.UnicastImpl = func(ocMsg *pb.Message, peer *pb.PeerID) error { return nil }
+	stack := &configStack{omniProto: &omni, config: map[string]string{"pbft.general.batchsize": "7"}}
+	b := newObcBatch(0, loadConfig(), stack)
+	defer b.Close()
+
+	if b.batchSize != 7 {
+		t.Fatalf("Expected the batch size held on chain to override the configuration, got %d", b.batchSize)
+	}
+	configuredTimeout := b.batchTimeout
+
+	// Values which do not parse are ignored
+	stack.config["pbft.general.batchsize"] = "many"
+	stack.config["pbft.general.timeout.batch"] = "2h"
+	b.manager.Queue() <- committedEvent{}
+	b.manager.Queue() <- nil
+
+	if b.batchSize != 7 {
+		t.Errorf("Expected the invalid batch size to be ignored, got %d", b.batchSize)
+	}
+	if b.batchTimeout == configuredTimeout || b.batchTimeout != 2*time.Hour {
+		t.Errorf("Expected batch timeout of 2h after commit, got %v", b.batchTimeout)
+	}
+	if b.pbft.requestTimeout <= b.batchTimeout {
+		t.Errorf("Expected the request timeout %v to be raised above the batch timeout", b.pbft.requestTimeout)
+	}
+}
@@ -33,9 +33,11 @@ type communicator interface {
 type broadcaster struct {
 	comm communicator
 
+	self             uint64
 	f                int
 	broadcastTimeout time.Duration
 	msgChans         map[uint64]chan *sendRequest
+	stopChans        map[uint64]chan struct{}
 	closed           sync.WaitGroup
 	closedCh         chan struct{}
 }
@@ -45,33 +47,54 @@ type sendRequest struct {
 	done chan bool
 }
 
-func newBroadcaster(self uint64, N int, f int, broadcastTimeout time.Duration, c communicator) *broadcaster {
-	queueSize := 10 // XXX increase after testing
+const broadcastQueueSize = 10 // XXX increase after testing
 
-	chans := make(map[uint64]chan *sendRequest)
+func newBroadcaster(self uint64, N int, f int, broadcastTimeout time.Duration, c communicator) *broadcaster {
 	b := &broadcaster{
 		comm:             c,
+		self:             self,
 		f:                f,
 		broadcastTimeout: broadcastTimeout,
-		msgChans:         chans,
+		msgChans:         make(map[uint64]chan *sendRequest),
+		stopChans:        make(map[uint64]chan struct{}),
 		closedCh:         make(chan struct{}),
 	}
 	for i := 0; i < N; i++ {
-		if uint64(i) == self {
-			continue
-		}
-		chans[uint64(i)] = make(chan *sendRequest, queueSize)
+		b.addDest(uint64(i))
 	}
 
-	// We do not start the go routines in the above loop to avoid concurrent map read/writes
-	for i := 0; i < N; i++ {
-		if uint64(i) == self {
-			continue
+	return b
+}
+
+// setReplicas changes the set of replicas messages are sent to, it must
+// not be called concurrently with Broadcast or Unicast
+func (b *broadcaster) setReplicas(replicas []uint64, f int) {
+	b.f = f
+
+	members := make(map[uint64]bool)
+	for _, id := range replicas {
+		members[id] = true
+		b.addDest(id)
+	}
+	for id, stop := range b.stopChans {
+		if !members[id] {
+			close(stop)
+			delete(b.msgChans, id)
+			delete(b.stopChans, id)
 		}
-		go b.drainer(uint64(i))
 	}
+}
 
-	return b
+func (b *broadcaster) addDest(dest uint64) {
+	if _, ok := b.msgChans[dest]; ok || dest == b.self {
+		return
+	}
+	destChan := make(chan *sendRequest, broadcastQueueSize)
+	stop := make(chan struct{})
+	b.msgChans[dest] = destChan
+	b.stopChans[dest] = stop
+	// The drainer is given its channels, so that it does not read the map concurrently with changes
+	go b.drainer(dest, destChan, stop)
 }
 
 func (b *broadcaster) Close() {
@@ -111,29 +134,33 @@ func (b *broadcaster) drainerSend(dest uint64, send *sendRequest, successLastTim
 
 }
 
-func (b *broadcaster) drainer(dest uint64) {
+func (b *broadcaster) drainer(dest uint64, destChan chan *sendRequest, stop chan struct{}) {
 	successLastTime := false
-	destChan, exsit := b.msgChans[dest] // Avoid doing the map lookup every send
-	if !exsit {
-		logger.Warningf("could not get message channel for replica %d", dest)
-		return
-	}
 
 	for {
 		select {
 		case send := <-destChan:
 			successLastTime = b.drainerSend(dest, send, successLastTime)
+		case <-stop:
+			logger.Debugf("replica %d left the network, no longer sending to it", dest)
+			b.drain(destChan)
+			return
 		case <-b.closedCh:
-			for {
-				// Drain the message channel to free calling waiters before we shut down
-				select {
-				case send := <-destChan:
-					send.done <- false
-					b.closed.Done()
-				default:
-					return
-				}
-			}
+			b.drain(destChan)
+			return
+		}
+	}
+}
+
+// drain empties the message channel to free calling waiters before the drainer shuts down
+func (b *broadcaster) drain(destChan chan *sendRequest) {
+	for {
+		select {
+		case send := <-destChan:
+			send.done <- false
+			b.closed.Done()
+		default:
+			return
 		}
 	}
 }
@@ -307,3 +307,38 @@ func TestBroadcastIndefinitelyStuck(t *testing.T) {
 	close(m.done)
 	b.Close()
 }
+
+func TestBroadcastSetReplicas(t *testing.T) {
+	m := &mockComm{
+		self:  1,
+		n:     4,
+		msgCh: make(chan mockMsg, 4),
+	}
+	b := newBroadcaster(1, 4, 1, time.Second, m)
+	defer b.Close()
+
+	// Replica 0 leaves and replica 7 joins
+	b.setReplicas([]uint64{1, 2, 3, 7}, 1)
+
+	b.Broadcast(&pb.Message{Payload: []byte("hi")})
+	sent := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-m.msgCh:
+			sent[msg.dest.Name] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Broadcast was sent to %v only", sent)
+		}
+	}
+
+	for _, name := range []string{"vp2", "vp3", "vp7"} {
+		if !sent[name] {
+			t.Errorf("Broadcast was not sent to %s: %v", name, sent)
+		}
+	}
+	select {
+	case msg := <-m.msgCh:
+		t.Errorf("Broadcast was also sent to %s", msg.dest.Name)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
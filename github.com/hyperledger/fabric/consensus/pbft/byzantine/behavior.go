@@ -0,0 +1,239 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package byzantine runs a network of PBFT replicas in memory, and lets
+// tests script the faults of each replica. A replica runs the unmodified
+// PBFT plugin, its faults are applied to the messages it sends: the
+// behavior of a replica may rewrite, drop, duplicate or delay each of them.
+// Replicas can also be crashed and recovered, keeping their ledger and
+// persisted state.
+//
+// All the random choices of the network and of the behaviors are taken from
+// a single generator, seeded by the test, so that a failing scenario can be
+// run again with the same faults. The interleaving of the replicas still
+// depends on the Go scheduler.
+//
+// The replicas are configured like the plugin, through the pbft config.yaml
+// and the CORE_PBFT environment variables.
+package byzantine
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+
+	"github.com/hyperledger/fabric/consensus/pbft"
+	"github.com/hyperledger/fabric/core/util"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// Message is a consensus message sent from one replica to another
+type Message struct {
+	Src   uint64
+	Dst   uint64
+	Batch *pbft.BatchMessage // the message as sent by the plugin
+	Pbft  *pbft.Message      // the decoded protocol message, nil for requests
+	Delay time.Duration      // how long the network holds the message before delivery
+}
+
+// copy returns a copy of the message which can be modified without
+// affecting the original
+func (msg *Message) copy() *Message {
+	c := *msg
+	if msg.Pbft != nil {
+		c.Pbft = proto.Clone(msg.Pbft).(*pbft.Message)
+	}
+	return &c
+}
+
+// Behavior scripts the messages sent by a replica. Send is called for each
+// message the replica sends, and returns the messages which are actually
+// delivered.
+type Behavior interface {
+	Send(msg *Message, rng *rand.Rand) []*Message
+}
+
+// timingFault is implemented by behaviors which only delay messages, a
+// replica with such a behavior is still correct
+type timingFault interface {
+	timingOnly() bool
+}
+
+// isByzantine returns whether a behavior deviates from the protocol
+func isByzantine(b Behavior) bool {
+	if b == nil {
+		return false
+	}
+	if t, ok := b.(timingFault); ok {
+		return !t.timingOnly()
+	}
+	return true
+}
+
+type composed []Behavior
+
+// Compose applies several behaviors in order, each to the messages returned
+// by the previous one
+func Compose(behaviors ...Behavior) Behavior {
+	return composed(behaviors)
+}
+
+func (c composed) Send(msg *Message, rng *rand.Rand) []*Message {
+	msgs := []*Message{msg}
+	for _, b := range c {
+		var next []*Message
+		for _, m := range msgs {
+			next = append(next, b.Send(m, rng)...)
+		}
+		msgs = next
+	}
+	return msgs
+}
+
+func (c composed) timingOnly() bool {
+	for _, b := range c {
+		if isByzantine(b) {
+			return false
+		}
+	}
+	return true
+}
+
+type delay time.Duration
+
+// Delay holds every message sent by the replica for d, as a slow replica
+// or link would
+func Delay(d time.Duration) Behavior {
+	return delay(d)
+}
+
+func (d delay) Send(msg *Message, rng *rand.Rand) []*Message {
+	msg.Delay += time.Duration(d)
+	return []*Message{msg}
+}
+
+func (d delay) timingOnly() bool {
+	return true
+}
+
+type reorder time.Duration
+
+// Reorder holds every message sent by the replica for a random duration up
+// to max, so that they are not delivered in the order they were sent
+func Reorder(max time.Duration) Behavior {
+	return reorder(max)
+}
+
+func (r reorder) Send(msg *Message, rng *rand.Rand) []*Message {
+	msg.Delay += time.Duration(rng.Int63n(int64(r)))
+	return []*Message{msg}
+}
+
+func (r reorder) timingOnly() bool {
+	return true
+}
+
+type equivocate map[uint64]bool
+
+// EquivocatePrePrepares makes a primary send the victims a pre-prepare for
+// a different request batch than the one the other replicas receive, for
+// each sequence number
+func EquivocatePrePrepares(victims ...uint64) Behavior {
+	e := make(equivocate)
+	for _, id := range victims {
+		e[id] = true
+	}
+	return e
+}
+
+func (e equivocate) Send(msg *Message, rng *rand.Rand) []*Message {
+	preprep := msg.Pbft.GetPrePrepare()
+	if preprep == nil || !e[msg.Dst] {
+		return []*Message{msg}
+	}
+
+	forged := msg.copy()
+	preprep = forged.Pbft.GetPrePrepare()
+	preprep.RequestBatch = forgeRequestBatch(preprep.SequenceNumber, preprep.ReplicaId)
+	preprep.BatchDigest = hash(preprep.RequestBatch)
+	return []*Message{forged}
+}
+
+// forgeRequestBatch returns a batch with a single transaction, which the
+// primary made up for the sequence number
+func forgeRequestBatch(seqNo uint64, replicaID uint64) *pbft.RequestBatch {
+	tx := &pb.Transaction{Txid: fmt.Sprintf("forged-%d-%d", replicaID, seqNo)}
+	raw, _ := proto.Marshal(tx)
+	now := time.Now()
+	return &pbft.RequestBatch{Batch: []*pbft.Request{{
+		Timestamp: &timestamp.Timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())},
+		Payload:   raw,
+		ReplicaId: replicaID,
+	}}}
+}
+
+// hash computes the digest of a request batch the way the plugin does
+func hash(reqBatch *pbft.RequestBatch) string {
+	raw, _ := proto.Marshal(reqBatch)
+	return base64.StdEncoding.EncodeToString(util.ComputeCryptoHash(raw))
+}
+
+type withholdCommits struct{}
+
+// WithholdCommits makes a replica prepare request batches like the others,
+// but never send its commits
+func WithholdCommits() Behavior {
+	return withholdCommits{}
+}
+
+func (withholdCommits) Send(msg *Message, rng *rand.Rand) []*Message {
+	if msg.Pbft.GetCommit() != nil {
+		return nil
+	}
+	return []*Message{msg}
+}
+
+type forgeCheckpoints uint64
+
+// ForgeCheckpoints makes a replica send a checkpoint with a made up state
+// for each checkpoint it takes. The forged checkpoint claims a sequence
+// number ahead of the real one, which may lie beyond the high watermark of
+// the other replicas.
+func ForgeCheckpoints(ahead uint64) Behavior {
+	return forgeCheckpoints(ahead)
+}
+
+func (f forgeCheckpoints) Send(msg *Message, rng *rand.Rand) []*Message {
+	if msg.Pbft.GetCheckpoint() == nil {
+		return []*Message{msg}
+	}
+
+	forged := msg.copy()
+	chkpt := forged.Pbft.GetCheckpoint()
+	chkpt.SequenceNumber += uint64(f)
+	info := &pb.BlockchainInfo{
+		Height:           chkpt.SequenceNumber + 1,
+		CurrentBlockHash: make([]byte, 32),
+	}
+	rng.Read(info.CurrentBlockHash)
+	raw, _ := proto.Marshal(info)
+	chkpt.Id = base64.StdEncoding.EncodeToString(raw)
+	return []*Message{forged}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package byzantine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// ledger is an in memory chain of blocks, whose state is the hash of all
+// the transactions it executed. It survives the crashes of its replica.
+type ledger struct {
+	mutex   sync.Mutex
+	blocks  []*pb.Block
+	pending []*pb.Transaction
+	valid   bool
+}
+
+func newLedger() *ledger {
+	return &ledger{
+		blocks: []*pb.Block{{StateHash: []byte("genesis")}},
+		valid:  true,
+	}
+}
+
+func (l *ledger) execute(txs []*pb.Transaction) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.pending = append(l.pending, txs...)
+}
+
+func (l *ledger) commit(meta []byte) *pb.BlockchainInfo {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	prev := l.blocks[len(l.blocks)-1]
+	prevHash, _ := prev.GetHash()
+	state := prev.StateHash
+	for _, tx := range l.pending {
+		raw, _ := proto.Marshal(tx)
+		h := sha256.Sum256(append(append([]byte(nil), state...), raw...))
+		state = h[:]
+	}
+	l.blocks = append(l.blocks, &pb.Block{
+		PreviousBlockHash: prevHash,
+		Transactions:      l.pending,
+		StateHash:         state,
+		ConsensusMetadata: meta,
+	})
+	l.pending = nil
+	return l.info()
+}
+
+func (l *ledger) rollback() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.pending = nil
+}
+
+// chain returns a copy of the blocks up to target, nil if the ledger does
+// not contain it
+func (l *ledger) chain(target *pb.BlockchainInfo) []*pb.Block {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if target.Height == 0 || uint64(len(l.blocks)) < target.Height {
+		return nil
+	}
+	if hash, _ := l.blocks[target.Height-1].GetHash(); !bytes.Equal(hash, target.CurrentBlockHash) {
+		return nil
+	}
+	return append([]*pb.Block(nil), l.blocks[:target.Height]...)
+}
+
+func (l *ledger) replace(blocks []*pb.Block) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.blocks = blocks
+	l.pending = nil
+}
+
+func (l *ledger) setValid(valid bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.valid = valid
+}
+
+func (l *ledger) info() *pb.BlockchainInfo {
+	info := &pb.BlockchainInfo{Height: uint64(len(l.blocks))}
+	info.CurrentBlockHash, _ = l.blocks[len(l.blocks)-1].GetHash()
+	if len(l.blocks) > 1 {
+		info.PreviousBlockHash, _ = l.blocks[len(l.blocks)-2].GetHash()
+	}
+	return info
+}
+
+func (l *ledger) getBlock(id uint64) (*pb.Block, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if id >= uint64(len(l.blocks)) {
+		return nil, fmt.Errorf("Block %d not found", id)
+	}
+	return l.blocks[id], nil
+}
+
+func (l *ledger) size() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return uint64(len(l.blocks))
+}
+
+func (l *ledger) blockchainInfo() *pb.BlockchainInfo {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.info()
+}
+
+func (l *ledger) headMetadata() []byte {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.blocks[len(l.blocks)-1].ConsensusMetadata
+}
+
+// hashes returns the hash of every block
+func (l *ledger) hashes() [][]byte {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	hashes := make([][]byte, len(l.blocks))
+	for i, block := range l.blocks {
+		hashes[i], _ = block.GetHash()
+	}
+	return hashes
+}
+
+// transactions lists the IDs of the transactions in the ledger, in order
+func (l *ledger) transactions() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	var ids []string
+	for _, block := range l.blocks {
+		for _, tx := range block.Transactions {
+			ids = append(ids, tx.Txid)
+		}
+	}
+	return ids
+}
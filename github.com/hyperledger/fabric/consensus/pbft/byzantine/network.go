@@ -0,0 +1,665 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package byzantine
+
+import (
+	"bytes"
+	"container/heap"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/op/go-logging"
+
+	"github.com/hyperledger/fabric/consensus"
+	"github.com/hyperledger/fabric/consensus/pbft"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+var logger *logging.Logger // package-level logger
+
+func init() {
+	logger = logging.MustGetLogger("consensus/pbft/byzantine")
+}
+
+// Network is a set of PBFT replicas connected by an in memory network,
+// whose faults are scripted by the test
+type Network struct {
+	replicas []*replica
+
+	rngLock sync.Mutex
+	rng     *rand.Rand
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewNetwork starts n replicas, numbered from 0 to n-1. The number of
+// replicas must match general.N of the PBFT configuration.
+func NewNetwork(n int, seed int64) *Network {
+	net := &Network{
+		rng:    rand.New(rand.NewSource(seed)),
+		closed: make(chan struct{}),
+	}
+	for id := 0; id < n; id++ {
+		r := &replica{
+			id:     uint64(id),
+			net:    net,
+			ledger: newLedger(),
+			state:  make(map[string][]byte),
+			inbox:  newInbox(),
+		}
+		net.replicas = append(net.replicas, r)
+	}
+	for _, r := range net.replicas {
+		net.wg.Add(1)
+		go r.deliverLoop()
+		r.start()
+	}
+	return net
+}
+
+// Stop crashes every replica and shuts the network down
+func (net *Network) Stop() {
+	for _, r := range net.replicas {
+		r.crash()
+	}
+	close(net.closed)
+	net.wg.Wait()
+}
+
+// SetBehavior scripts the messages a replica sends from now on, nil makes
+// it follow the protocol again. A replica which was given a Byzantine
+// behavior is not considered correct anymore by the invariants.
+func (net *Network) SetBehavior(id uint64, behavior Behavior) {
+	r := net.replicas[id]
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.behavior = behavior
+	if isByzantine(behavior) {
+		r.faulty = true
+	}
+}
+
+// Crash stops a replica, its ledger and persisted state are kept
+func (net *Network) Crash(id uint64) {
+	net.replicas[id].crash()
+}
+
+// Recover restarts a crashed replica from its ledger and persisted state
+func (net *Network) Recover(id uint64) {
+	net.replicas[id].start()
+}
+
+// Submit sends a transaction to a replica, as a client would
+func (net *Network) Submit(id uint64, tx *pb.Transaction) error {
+	inc := net.replicas[id].running()
+	if inc == nil {
+		return fmt.Errorf("Replica %d is crashed", id)
+	}
+	raw, err := proto.Marshal(tx)
+	if err != nil {
+		return err
+	}
+	return inc.getConsenter().RecvMsg(&pb.Message{Type: pb.Message_CHAIN_TRANSACTION, Payload: raw}, handle(id))
+}
+
+// Status returns a snapshot of the state of a running replica
+func (net *Network) Status(id uint64) (*pb.PbftStatus, error) {
+	inc := net.replicas[id].running()
+	if inc == nil {
+		return nil, fmt.Errorf("Replica %d is crashed", id)
+	}
+	status, err := inc.getConsenter().GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	return status.Pbft, nil
+}
+
+// Transactions lists the IDs of the transactions in the ledger of a
+// replica, in the order they were executed
+func (net *Network) Transactions(id uint64) []string {
+	return net.replicas[id].ledger.transactions()
+}
+
+// CheckSafety verifies that the correct replicas agree: of any two chains,
+// one is a prefix of the other, and no transaction was executed twice
+func (net *Network) CheckSafety() error {
+	var longest [][]byte
+	var owner uint64
+	for _, r := range net.correct() {
+		hashes := r.ledger.hashes()
+		common := hashes
+		if len(longest) < len(hashes) {
+			common = hashes[:len(longest)]
+		}
+		for i := range common {
+			if !bytes.Equal(common[i], longest[i]) {
+				return fmt.Errorf("Replicas %d and %d disagree on block %d", owner, r.id, i)
+			}
+		}
+		if len(hashes) > len(longest) {
+			longest, owner = hashes, r.id
+		}
+
+		executed := make(map[string]bool)
+		for _, txID := range r.ledger.transactions() {
+			if executed[txID] {
+				return fmt.Errorf("Replica %d executed transaction %s twice", r.id, txID)
+			}
+			executed[txID] = true
+		}
+	}
+	return nil
+}
+
+// WaitExecuted waits until every correct replica which is running executed
+// all the transactions, it returns an error if they did not within timeout
+func (net *Network) WaitExecuted(txIDs []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var missing []string
+		for _, r := range net.correct() {
+			if r.running() == nil {
+				continue
+			}
+			executed := make(map[string]bool)
+			for _, txID := range r.ledger.transactions() {
+				executed[txID] = true
+			}
+			for _, txID := range txIDs {
+				if !executed[txID] {
+					missing = append(missing, fmt.Sprintf("replica %d misses %s", r.id, txID))
+				}
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Transactions not executed after %v: %s", timeout, strings.Join(missing, ", "))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// correct returns the replicas which never had a Byzantine behavior
+func (net *Network) correct() []*replica {
+	var correct []*replica
+	for _, r := range net.replicas {
+		r.mutex.Lock()
+		if !r.faulty {
+			correct = append(correct, r)
+		}
+		r.mutex.Unlock()
+	}
+	return correct
+}
+
+// random runs fn with the random generator of the network
+func (net *Network) random(fn func(rng *rand.Rand)) {
+	net.rngLock.Lock()
+	defer net.rngLock.Unlock()
+	fn(net.rng)
+}
+
+func handle(id uint64) *pb.PeerID {
+	return &pb.PeerID{Name: "vp" + strconv.FormatUint(id, 10)}
+}
+
+func replicaID(handle *pb.PeerID) (uint64, error) {
+	if !strings.HasPrefix(handle.Name, "vp") {
+		return 0, fmt.Errorf("Unexpected peer %s", handle.Name)
+	}
+	return strconv.ParseUint(handle.Name[2:], 10, 64)
+}
+
+// replica is what survives a crash: the ledger and the persisted state
+type replica struct {
+	id     uint64
+	net    *Network
+	ledger *ledger
+	inbox  *inbox
+
+	mutex    sync.Mutex
+	state    map[string][]byte
+	behavior Behavior
+	faulty   bool
+	current  *incarnation // nil while crashed
+}
+
+func (r *replica) running() *incarnation {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.current
+}
+
+func (r *replica) start() {
+	r.mutex.Lock()
+	if r.current != nil {
+		r.mutex.Unlock()
+		return
+	}
+	inc := &incarnation{replica: r, ready: make(chan struct{})}
+	r.current = inc
+	r.mutex.Unlock()
+
+	r.ledger.rollback() // Whatever was executing when the replica crashed is lost
+	inc.consenter = pbft.New(inc)
+	close(inc.ready)
+}
+
+func (r *replica) crash() {
+	r.mutex.Lock()
+	inc := r.current
+	if inc == nil {
+		r.mutex.Unlock()
+		return
+	}
+	r.current = nil
+	inc.dead = true
+	r.mutex.Unlock()
+
+	if closer, ok := inc.getConsenter().(interface {
+		Close()
+	}); ok {
+		closer.Close()
+	}
+	logger.Infof("Replica %d crashed", r.id)
+}
+
+// send applies the behavior of the replica to a message it sends, and
+// queues the resulting messages for delivery
+func (r *replica) send(msg *Message, behavior Behavior) {
+	msgs := []*Message{msg}
+	if behavior != nil {
+		r.net.random(func(rng *rand.Rand) {
+			msgs = behavior.Send(msg, rng)
+		})
+	}
+	for _, m := range msgs {
+		if m.Dst >= uint64(len(r.net.replicas)) {
+			continue
+		}
+		r.net.replicas[m.Dst].inbox.push(m)
+	}
+}
+
+func (r *replica) deliverLoop() {
+	defer r.net.wg.Done()
+	for {
+		msg, wait := r.inbox.next()
+		if msg != nil {
+			r.deliver(msg)
+			continue
+		}
+		select {
+		case <-wait:
+		case <-r.inbox.wakeup:
+		case <-r.net.closed:
+			return
+		}
+	}
+}
+
+func (r *replica) deliver(msg *Message) {
+	inc := r.running()
+	if inc == nil {
+		return // Lost, the replica is crashed
+	}
+	batchMsg := msg.Batch
+	if msg.Pbft != nil {
+		raw, err := proto.Marshal(msg.Pbft)
+		if err != nil {
+			logger.Errorf("Could not marshal message for replica %d: %s", r.id, err)
+			return
+		}
+		batchMsg = &pbft.BatchMessage{Payload: &pbft.BatchMessage_PbftMessage{PbftMessage: raw}}
+	}
+	raw, err := proto.Marshal(batchMsg)
+	if err != nil {
+		logger.Errorf("Could not marshal message for replica %d: %s", r.id, err)
+		return
+	}
+	inc.getConsenter().RecvMsg(&pb.Message{Type: pb.Message_CONSENSUS, Payload: raw}, handle(msg.Src))
+}
+
+// inbox holds the messages sent to a replica until their delay elapsed,
+// they are delivered in the order of their delivery time
+type inbox struct {
+	mutex  sync.Mutex
+	queue  deliveryQueue
+	seq    uint64
+	wakeup chan struct{}
+}
+
+type delivery struct {
+	at  time.Time
+	seq uint64
+	msg *Message
+}
+
+type deliveryQueue []*delivery
+
+func (q deliveryQueue) Len() int { return len(q) }
+func (q deliveryQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+func (q deliveryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *deliveryQueue) Push(x interface{}) { *q = append(*q, x.(*delivery)) }
+func (q *deliveryQueue) Pop() interface{} {
+	old := *q
+	d := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return d
+}
+
+func newInbox() *inbox {
+	return &inbox{wakeup: make(chan struct{}, 1)}
+}
+
+func (in *inbox) push(msg *Message) {
+	in.mutex.Lock()
+	in.seq++
+	heap.Push(&in.queue, &delivery{at: time.Now().Add(msg.Delay), seq: in.seq, msg: msg})
+	in.mutex.Unlock()
+
+	select {
+	case in.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next message which is due, or a channel which fires
+// when the next message is due
+func (in *inbox) next() (*Message, <-chan time.Time) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	if len(in.queue) == 0 {
+		return nil, nil
+	}
+	if wait := in.queue[0].at.Sub(time.Now()); wait > 0 {
+		return nil, time.After(wait)
+	}
+	return heap.Pop(&in.queue).(*delivery).msg, nil
+}
+
+// incarnation is a replica between a start and a crash, it is the stack of
+// the plugin. Once crashed, it does not send messages, execute or persist
+// anything anymore.
+type incarnation struct {
+	*replica
+	dead      bool // protected by the mutex of the replica
+	ready     chan struct{}
+	consenter consensus.Consenter
+}
+
+func (inc *incarnation) getConsenter() consensus.Consenter {
+	<-inc.ready
+	return inc.consenter
+}
+
+// do runs fn unless the incarnation crashed, atomically with respect to the crash
+func (inc *incarnation) do(fn func()) bool {
+	inc.mutex.Lock()
+	defer inc.mutex.Unlock()
+	if inc.dead {
+		return false
+	}
+	fn()
+	return true
+}
+
+// callback delivers the completion of an asynchronous call to the plugin
+func (inc *incarnation) callback(fn func(c consensus.Consenter)) {
+	go func() {
+		c := inc.getConsenter()
+		inc.mutex.Lock()
+		dead := inc.dead
+		inc.mutex.Unlock()
+		if !dead {
+			fn(c)
+		}
+	}()
+}
+
+// Inquirer
+
+func (inc *incarnation) GetNetworkInfo() (self *pb.PeerEndpoint, network []*pb.PeerEndpoint, err error) {
+	for _, r := range inc.net.replicas {
+		ep := &pb.PeerEndpoint{ID: handle(r.id), Type: pb.PeerEndpoint_VALIDATOR}
+		if r.id == inc.id {
+			self = ep
+			continue
+		}
+		network = append(network, ep)
+	}
+	return
+}
+
+func (inc *incarnation) GetNetworkHandles() (self *pb.PeerID, network []*pb.PeerID, err error) {
+	for _, r := range inc.net.replicas {
+		if r.id == inc.id {
+			self = handle(r.id)
+			continue
+		}
+		network = append(network, handle(r.id))
+	}
+	return
+}
+
+// Communicator
+
+func (inc *incarnation) Broadcast(msg *pb.Message, peerType pb.PeerEndpoint_Type) error {
+	for _, r := range inc.net.replicas {
+		if r.id != inc.id {
+			inc.Unicast(msg, handle(r.id))
+		}
+	}
+	return nil
+}
+
+func (inc *incarnation) Unicast(msg *pb.Message, receiverHandle *pb.PeerID) error {
+	dst, err := replicaID(receiverHandle)
+	if err != nil {
+		return err
+	}
+	batchMsg := &pbft.BatchMessage{}
+	if err = proto.Unmarshal(msg.Payload, batchMsg); err != nil {
+		return fmt.Errorf("Replica %d sent an unexpected message: %s", inc.id, err)
+	}
+	m := &Message{Src: inc.id, Dst: dst, Batch: batchMsg}
+	if raw := batchMsg.GetPbftMessage(); raw != nil {
+		m.Pbft = &pbft.Message{}
+		if err = proto.Unmarshal(raw, m.Pbft); err != nil {
+			return fmt.Errorf("Replica %d sent an unexpected message: %s", inc.id, err)
+		}
+	}
+
+	var behavior Behavior
+	if inc.do(func() { behavior = inc.behavior }) {
+		inc.send(m, behavior)
+	}
+	return nil
+}
+
+// SecurityUtils, messages are not signed
+
+func (inc *incarnation) Sign(msg []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (inc *incarnation) Verify(peerID *pb.PeerID, signature []byte, message []byte) error {
+	return nil
+}
+
+// Executor
+
+func (inc *incarnation) Start() {}
+
+func (inc *incarnation) Halt() {}
+
+func (inc *incarnation) Execute(tag interface{}, txs []*pb.Transaction) {
+	if inc.do(func() { inc.ledger.execute(txs) }) {
+		inc.callback(func(c consensus.Consenter) { c.Executed(tag) })
+	}
+}
+
+func (inc *incarnation) Commit(tag interface{}, metadata []byte) {
+	var info *pb.BlockchainInfo
+	if inc.do(func() { info = inc.ledger.commit(metadata) }) {
+		inc.callback(func(c consensus.Consenter) { c.Committed(tag, info) })
+	}
+}
+
+func (inc *incarnation) Rollback(tag interface{}) {
+	if inc.do(func() { inc.ledger.rollback() }) {
+		inc.callback(func(c consensus.Consenter) { c.RolledBack(tag) })
+	}
+}
+
+// UpdateState copies the chain of the first of the peers which has the
+// target, any peer when none is given. A replica whose ledger already
+// contains the target keeps it.
+func (inc *incarnation) UpdateState(tag interface{}, target *pb.BlockchainInfo, peers []*pb.PeerID) {
+	if len(peers) == 0 {
+		_, peers, _ = inc.GetNetworkHandles()
+	}
+	peers = append([]*pb.PeerID{handle(inc.id)}, peers...)
+	go func() {
+		for _, peer := range peers {
+			id, err := replicaID(peer)
+			if err != nil || id >= uint64(len(inc.net.replicas)) {
+				continue
+			}
+			blocks := inc.net.replicas[id].ledger.chain(target)
+			if blocks == nil {
+				continue
+			}
+			if inc.do(func() { inc.ledger.replace(blocks) }) {
+				inc.callback(func(c consensus.Consenter) { c.StateUpdated(tag, target) })
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond) // Do not spin when no peer can serve the target yet
+		inc.callback(func(c consensus.Consenter) { c.StateUpdated(tag, nil) })
+	}()
+}
+
+// LegacyExecutor
+
+func (inc *incarnation) BeginTxBatch(id interface{}) error {
+	return nil
+}
+
+func (inc *incarnation) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error) {
+	inc.do(func() { inc.ledger.execute(txs) })
+	return nil, nil
+}
+
+func (inc *incarnation) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, error) {
+	var block *pb.Block
+	var err error
+	inc.do(func() {
+		info := inc.ledger.commit(metadata)
+		block, err = inc.ledger.getBlock(info.Height - 1)
+	})
+	return block, err
+}
+
+func (inc *incarnation) RollbackTxBatch(id interface{}) error {
+	inc.do(func() { inc.ledger.rollback() })
+	return nil
+}
+
+func (inc *incarnation) PreviewCommitTxBatch(id interface{}, metadata []byte) ([]byte, error) {
+	return nil, fmt.Errorf("Not implemented")
+}
+
+// LedgerManager
+
+func (inc *incarnation) InvalidateState() {
+	inc.do(func() { inc.ledger.setValid(false) })
+}
+
+func (inc *incarnation) ValidateState() {
+	inc.do(func() { inc.ledger.setValid(true) })
+}
+
+// ReadOnlyLedger
+
+func (inc *incarnation) GetBlock(id uint64) (*pb.Block, error) {
+	return inc.ledger.getBlock(id)
+}
+
+func (inc *incarnation) GetBlockchainSize() uint64 {
+	return inc.ledger.size()
+}
+
+func (inc *incarnation) GetBlockchainInfo() *pb.BlockchainInfo {
+	return inc.ledger.blockchainInfo()
+}
+
+func (inc *incarnation) GetBlockchainInfoBlob() []byte {
+	raw, _ := proto.Marshal(inc.ledger.blockchainInfo())
+	return raw
+}
+
+func (inc *incarnation) GetBlockHeadMetadata() ([]byte, error) {
+	return inc.ledger.headMetadata(), nil
+}
+
+// StatePersistor
+
+func (inc *incarnation) StoreState(key string, value []byte) error {
+	inc.do(func() { inc.state[key] = append([]byte(nil), value...) })
+	return nil
+}
+
+func (inc *incarnation) ReadState(key string) ([]byte, error) {
+	var value []byte
+	var found bool
+	inc.do(func() { value, found = inc.state[key] })
+	if !found {
+		return nil, fmt.Errorf("Key %s not found", key)
+	}
+	return value, nil
+}
+
+func (inc *incarnation) ReadStateSet(prefix string) (map[string][]byte, error) {
+	set := make(map[string][]byte)
+	inc.do(func() {
+		for key, value := range inc.state {
+			if strings.HasPrefix(key, prefix) {
+				set[key] = value
+			}
+		}
+	})
+	return set, nil
+}
+
+func (inc *incarnation) DelState(key string) {
+	inc.do(func() { delete(inc.state, key) })
+}
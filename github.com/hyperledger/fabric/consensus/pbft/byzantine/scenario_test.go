@@ -0,0 +1,193 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package byzantine
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+var seed = flag.Int64("seed", 0, "Seed of the faults, overrides the seed of every scenario")
+
+const executionTimeout = 30 * time.Second
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	logging.SetLevel(logging.WARNING, "consensus/pbft")
+	logging.SetLevel(logging.WARNING, "consensus/util/events")
+
+	// Small batches, checkpoints and timeouts, so that the scenarios cover
+	// several checkpoints and view changes in a few seconds
+	for key, value := range map[string]string{
+		"CORE_PBFT_GENERAL_N":                        "4",
+		"CORE_PBFT_GENERAL_F":                        "1",
+		"CORE_PBFT_GENERAL_K":                        "2",
+		"CORE_PBFT_GENERAL_LOGMULTIPLIER":            "2",
+		"CORE_PBFT_GENERAL_BATCHSIZE":                "1",
+		"CORE_PBFT_GENERAL_TIMEOUT_BATCH":            "100ms",
+		"CORE_PBFT_GENERAL_TIMEOUT_REQUEST":          "1s",
+		"CORE_PBFT_GENERAL_TIMEOUT_VIEWCHANGE":       "1s",
+		"CORE_PBFT_GENERAL_TIMEOUT_RESENDVIEWCHANGE": "500ms",
+	} {
+		os.Setenv(key, value)
+	}
+	os.Exit(m.Run())
+}
+
+func newScenario(t *testing.T, defaultSeed int64) *Network {
+	s := defaultSeed
+	if *seed != 0 {
+		s = *seed
+	}
+	t.Logf("Running with seed %d, rerun with -seed=%d to reproduce the faults", s, s)
+	return NewNetwork(4, s)
+}
+
+// submit sends transactions first to first+count-1 to a replica
+func submit(t *testing.T, net *Network, id uint64, first, count int) []string {
+	var txIDs []string
+	for i := first; i < first+count; i++ {
+		txID := fmt.Sprintf("tx%d", i)
+		if err := net.Submit(id, &pb.Transaction{Txid: txID}); err != nil {
+			t.Fatalf("Could not submit %s: %s", txID, err)
+		}
+		txIDs = append(txIDs, txID)
+	}
+	return txIDs
+}
+
+// checkInvariants asserts the liveness and safety of the correct replicas
+func checkInvariants(t *testing.T, net *Network, txIDs []string) {
+	if err := net.WaitExecuted(txIDs, executionTimeout); err != nil {
+		t.Error(err)
+	}
+	if err := net.CheckSafety(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDelayedAndReorderedDelivery(t *testing.T) {
+	net := newScenario(t, 1)
+	defer net.Stop()
+
+	for id := uint64(0); id < 3; id++ {
+		net.SetBehavior(id, Reorder(20*time.Millisecond))
+	}
+	net.SetBehavior(3, Compose(Delay(50*time.Millisecond), Reorder(20*time.Millisecond)))
+
+	checkInvariants(t, net, submit(t, net, 1, 1, 10))
+}
+
+func TestEquivocatingPrimary(t *testing.T) {
+	net := newScenario(t, 2)
+	defer net.Stop()
+
+	// Neither batch can gather a quorum, the correct replicas must replace the primary
+	net.SetBehavior(0, EquivocatePrePrepares(1, 2))
+
+	checkInvariants(t, net, submit(t, net, 3, 1, 4))
+
+	for id := uint64(1); id < 4; id++ {
+		status, err := net.Status(id)
+		if err != nil {
+			t.Fatalf("Replica %d did not report its status: %s", id, err)
+		}
+		if status.View == 0 {
+			t.Errorf("Replica %d is still in the view of the equivocating primary", id)
+		}
+	}
+}
+
+func TestWithheldCommits(t *testing.T) {
+	net := newScenario(t, 3)
+	defer net.Stop()
+
+	net.SetBehavior(2, Compose(WithholdCommits(), Reorder(10*time.Millisecond)))
+
+	checkInvariants(t, net, submit(t, net, 1, 1, 8))
+}
+
+func TestForgedCheckpoints(t *testing.T) {
+	net := newScenario(t, 4)
+	defer net.Stop()
+
+	// The forged checkpoints are beyond the high watermark, a single replica
+	// claiming them must not trigger state transfer
+	net.SetBehavior(3, ForgeCheckpoints(8))
+
+	checkInvariants(t, net, submit(t, net, 1, 1, 10))
+
+	for id := uint64(0); id < 3; id++ {
+		status, err := net.Status(id)
+		if err != nil {
+			t.Fatalf("Replica %d did not report its status: %s", id, err)
+		}
+		if status.StateTransferInProgress || status.LowWatermark < 8 {
+			t.Errorf("Replica %d should have moved its watermarks on its own, low watermark %d, state transfer %v",
+				id, status.LowWatermark, status.StateTransferInProgress)
+		}
+	}
+}
+
+func TestCrashRecover(t *testing.T) {
+	net := newScenario(t, 5)
+	defer net.Stop()
+
+	net.SetBehavior(3, Reorder(10*time.Millisecond))
+
+	txIDs := submit(t, net, 1, 1, 4)
+	checkInvariants(t, net, txIDs)
+
+	// The others carry on while a backup is down, and it catches up once restarted
+	net.Crash(2)
+	txIDs = append(txIDs, submit(t, net, 1, 5, 6)...)
+	checkInvariants(t, net, txIDs)
+
+	net.Recover(2)
+	txIDs = append(txIDs, submit(t, net, 1, 11, 6)...)
+	checkInvariants(t, net, txIDs)
+
+	// Crash the primary, the others replace it
+	net.Crash(0)
+	txIDs = append(txIDs, submit(t, net, 3, 17, 4)...)
+	checkInvariants(t, net, txIDs)
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	net := newScenario(t, 6)
+	defer net.Stop()
+
+	txIDs := submit(t, net, 1, 1, 4)
+	checkInvariants(t, net, txIDs)
+
+	// The restarted replicas still know which requests they executed,
+	// the replayed transactions must not be executed twice
+	for _, id := range []uint64{1, 2} {
+		net.Crash(id)
+		net.Recover(id)
+		submit(t, net, id, 1, 4)
+	}
+	txIDs = append(txIDs, submit(t, net, 3, 5, 2)...)
+	checkInvariants(t, net, txIDs)
+}
@@ -17,23 +17,46 @@ limitations under the License.
 package pbft
 
 import (
+	"encoding/binary"
+	"fmt"
 	"time"
+
+	"github.com/hyperledger/fabric/consensus"
 )
 
 // deduplicator maintains the most recent Request timestamp for each
 // replica.  Two timestamps are maintained per replica.  One timestamp
 // tracks the most recent Request received from a replica, the other
 // timeout tracks the most recent executed Request.
+//
+// It also remembers the transaction IDs of the most recently executed
+// requests, up to the size of its replay window.  The executed timestamps
+// and the replay window are persisted, so that they survive a restart.
 type deduplicator struct {
 	reqTimestamps  map[uint64]time.Time
 	execTimestamps map[uint64]time.Time
+
+	window    uint64            // number of transaction IDs remembered, 0 disables the replay window
+	txIDs     map[string]uint64 // position in the execution order of each remembered transaction ID
+	positions map[uint64]string // transaction ID at each remembered position
+	next      uint64            // position of the next executed transaction
+
+	persistor consensus.StatePersistor
 }
 
-// newDeduplicator creates a new deduplicator.
-func newDeduplicator() *deduplicator {
+// newDeduplicator creates a new deduplicator, restoring the state it
+// persisted before a restart.
+func newDeduplicator(window int, persistor consensus.StatePersistor) *deduplicator {
 	d := &deduplicator{}
 	d.reqTimestamps = make(map[uint64]time.Time)
 	d.execTimestamps = make(map[uint64]time.Time)
+	if window > 0 {
+		d.window = uint64(window)
+	}
+	d.txIDs = make(map[string]uint64)
+	d.positions = make(map[uint64]string)
+	d.persistor = persistor
+	d.restore()
 	return d
 }
 
@@ -52,15 +75,21 @@ func (d *deduplicator) Request(req *Request) bool {
 }
 
 // Execute updates the executed request timestamp for the submitting
-// replica.  If the request is older than any previously executed
+// replica, and adds the transaction ID of the request, if any, to the
+// replay window.  If the request is older than any previously executed
 // request from the same replica, Execute() will return false,
 // indicating a stale request.
-func (d *deduplicator) Execute(req *Request) bool {
+func (d *deduplicator) Execute(req *Request, txID string) bool {
+	d.executeTxID(txID)
+
 	reqTime := time.Unix(req.Timestamp.Seconds, int64(req.Timestamp.Nanos))
 	if !reqTime.After(d.execTimestamps[req.ReplicaId]) {
 		return false
 	}
 	d.execTimestamps[req.ReplicaId] = reqTime
+	raw := make([]byte, 8)
+	binary.BigEndian.PutUint64(raw, uint64(reqTime.UnixNano()))
+	d.persistor.StoreState(fmt.Sprintf("dedup.exec.%d", req.ReplicaId), raw)
 	return true
 }
 
@@ -70,3 +99,74 @@ func (d *deduplicator) IsNew(req *Request) bool {
 	reqTime := time.Unix(req.Timestamp.Seconds, int64(req.Timestamp.Nanos))
 	return reqTime.After(d.execTimestamps[req.ReplicaId])
 }
+
+// IsReplay returns true if a transaction with this ID is in the replay
+// window, that is it was recently executed.
+func (d *deduplicator) IsReplay(txID string) bool {
+	_, ok := d.txIDs[txID]
+	return ok
+}
+
+func (d *deduplicator) executeTxID(txID string) {
+	if d.window == 0 || txID == "" {
+		return
+	}
+	if old, ok := d.txIDs[txID]; ok {
+		// Executed again nevertheless, remember the latest execution
+		delete(d.positions, old)
+		d.persistor.DelState(fmt.Sprintf("dedup.tx.%d", old))
+	}
+	d.txIDs[txID] = d.next
+	d.positions[d.next] = txID
+	d.persistor.StoreState(fmt.Sprintf("dedup.tx.%d", d.next), []byte(txID))
+	d.next++
+
+	if d.next <= d.window {
+		return
+	}
+	oldest := d.next - d.window - 1
+	if expired, ok := d.positions[oldest]; ok {
+		delete(d.txIDs, expired)
+		delete(d.positions, oldest)
+		d.persistor.DelState(fmt.Sprintf("dedup.tx.%d", oldest))
+	}
+}
+
+func (d *deduplicator) restore() {
+	if execs, err := d.persistor.ReadStateSet("dedup.exec."); err == nil {
+		for key, raw := range execs {
+			var replicaID uint64
+			if _, err = fmt.Sscanf(key, "dedup.exec.%d", &replicaID); err != nil || len(raw) != 8 {
+				logger.Warningf("Could not restore executed request timestamp %s", key)
+				continue
+			}
+			d.execTimestamps[replicaID] = time.Unix(0, int64(binary.BigEndian.Uint64(raw)))
+		}
+	}
+
+	txs, err := d.persistor.ReadStateSet("dedup.tx.")
+	if err != nil {
+		return
+	}
+	for key, txID := range txs {
+		var position uint64
+		if _, err = fmt.Sscanf(key, "dedup.tx.%d", &position); err != nil {
+			logger.Warningf("Could not restore executed transaction ID %s", key)
+			continue
+		}
+		if position >= d.next {
+			d.next = position + 1
+		}
+		d.txIDs[string(txID)] = position
+		d.positions[position] = string(txID)
+	}
+	// The window may have shrunk since the positions were persisted
+	for position, txID := range d.positions {
+		if d.window == 0 || position+d.window < d.next {
+			delete(d.txIDs, txID)
+			delete(d.positions, position)
+			d.persistor.DelState(fmt.Sprintf("dedup.tx.%d", position))
+		}
+	}
+	logger.Debugf("Restored %d executed request timestamps and %d transaction IDs", len(d.execTimestamps), len(d.txIDs))
+}
@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDeduplicatorRestore(t *testing.T) {
+	persist := &mockPersist{}
+	d := newDeduplicator(10, persist)
+
+	req := createPbftReq(5, 1)
+	if !d.IsNew(req) || !d.Execute(req, "tx1") {
+		t.Fatalf("Request should be new")
+	}
+	if d.Execute(createPbftReq(4, 1), "tx0") {
+		t.Errorf("Older request should be stale")
+	}
+
+	// Restart
+	d = newDeduplicator(10, persist)
+	if d.IsNew(req) {
+		t.Errorf("Executed request should not be new after a restart")
+	}
+	if !d.IsNew(createPbftReq(6, 1)) || !d.IsNew(createPbftReq(1, 2)) {
+		t.Errorf("Requests newer than the executed ones should be new after a restart")
+	}
+	if !d.IsReplay("tx1") || !d.IsReplay("tx0") || d.IsReplay("tx2") {
+		t.Errorf("Executed transactions should be remembered after a restart")
+	}
+}
+
+func TestDeduplicatorReplayWindow(t *testing.T) {
+	persist := &mockPersist{}
+	d := newDeduplicator(2, persist)
+	for i := int64(1); i <= 3; i++ {
+		d.Execute(createPbftReq(i, 1), fmt.Sprintf("tx%d", i))
+	}
+	if d.IsReplay("tx1") || !d.IsReplay("tx2") || !d.IsReplay("tx3") {
+		t.Errorf("Only the last two transactions should be in the window")
+	}
+	if txs, _ := persist.ReadStateSet("dedup.tx."); len(txs) != 2 {
+		t.Errorf("Expected two persisted transaction IDs, got %v", txs)
+	}
+
+	// Restart with a smaller window
+	d = newDeduplicator(1, persist)
+	if d.IsReplay("tx2") || !d.IsReplay("tx3") {
+		t.Errorf("Only the last transaction should be in the window after a restart")
+	}
+	d.Execute(createPbftReq(4, 1), "tx4")
+	if d.IsReplay("tx3") || !d.IsReplay("tx4") {
+		t.Errorf("Only the last transaction should be in the window")
+	}
+	if txs, _ := persist.ReadStateSet("dedup.tx."); len(txs) != 1 {
+		t.Errorf("Expected one persisted transaction ID, got %v", txs)
+	}
+
+	// Disabled window
+	d = newDeduplicator(0, persist)
+	d.Execute(createPbftReq(5, 1), "tx5")
+	if d.IsReplay("tx4") || d.IsReplay("tx5") {
+		t.Errorf("No transaction should be remembered without a window")
+	}
+}
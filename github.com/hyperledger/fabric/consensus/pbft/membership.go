@@ -0,0 +1,388 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/db"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+// The set of replicas is changed by reconfiguration requests, which are
+// ordered by PBFT like any other request. A reconfiguration executed at
+// sequence number n is recorded as pending, and takes effect once the
+// checkpoint following n becomes stable. Until then the primary does not
+// assign sequence numbers beyond that checkpoint, so that every request
+// after it is agreed upon by the new set of replicas. The membership is
+// written with the sequence number into the metadata of each block, which
+// is how a replica which state transfers, or restarts, learns it.
+//
+// A reconfiguration is only recorded when it is signed by enough of the
+// administrators of general.reconfiguration, and its sequence follows the
+// one of the last reconfiguration recorded, so that a replica cannot change
+// the membership on its own and a signed request cannot be replayed.
+
+// isMember returns whether the replica is part of the current membership
+func (instance *pbftCore) isMember(id uint64) bool {
+	for _, replica := range instance.replicas {
+		if replica == id {
+			return true
+		}
+	}
+	return false
+}
+
+// membership returns a snapshot of the current membership, including the
+// reconfigurations which are not applied yet
+func (instance *pbftCore) membership() *Membership {
+	return &Membership{
+		Replicas:   append([]uint64(nil), instance.replicas...),
+		F:          uint64(instance.f),
+		Pending:    append([]*Reconfiguration(nil), instance.pending...),
+		Activation: instance.activation,
+		Sequence:   instance.reconfigSeq,
+	}
+}
+
+// setReplicas replaces the set of replicas, the quorum sizes follow from N and f
+func (instance *pbftCore) setReplicas(replicas []uint64, f int) {
+	instance.replicas = replicas
+	instance.N = len(replicas)
+	instance.replicaCount = instance.N
+	instance.f = f
+
+	for idx := range instance.checkpointStore {
+		if !instance.isMember(idx.ReplicaId) {
+			delete(instance.checkpointStore, idx)
+		}
+	}
+	for idx := range instance.viewChangeStore {
+		if !instance.isMember(idx.id) {
+			delete(instance.viewChangeStore, idx)
+		}
+	}
+	for id := range instance.hChkpts {
+		if !instance.isMember(id) {
+			delete(instance.hChkpts, id)
+		}
+	}
+
+	logger.Infof("Replica %d now has membership %v, N=%d, f=%d (member: %v)",
+		instance.id, instance.replicas, instance.N, instance.f, instance.isMember(instance.id))
+	instance.consumer.membershipChanged(append([]uint64(nil), replicas...), f)
+}
+
+// adoptMembership replaces the membership by one learned from the ledger
+func (instance *pbftCore) adoptMembership(m *Membership) {
+	if m == nil || len(m.Replicas) == 0 {
+		return
+	}
+	if int(m.F)*3+1 > len(m.Replicas) {
+		logger.Warningf("Replica %d ignoring membership %v, it cannot tolerate %d faults", instance.id, m.Replicas, m.F)
+		return
+	}
+
+	instance.pending = append([]*Reconfiguration(nil), m.Pending...)
+	instance.activation = m.Activation
+	instance.reconfigSeq = m.Sequence
+	if !equalReplicas(instance.replicas, m.Replicas) || instance.f != int(m.F) {
+		instance.setReplicas(append([]uint64(nil), m.Replicas...), int(m.F))
+	}
+}
+
+// restoreMembership adopts the membership recorded in the last block
+func (instance *pbftCore) restoreMembership() {
+	m, err := instance.consumer.getLastMembership()
+	if err != nil {
+		logger.Warningf("Replica %d could not restore membership: %s", instance.id, err)
+		return
+	}
+	instance.adoptMembership(m)
+}
+
+// recordReconfigurations adds the reconfigurations of a request batch
+// executed at seqNo to the pending ones
+func (instance *pbftCore) recordReconfigurations(seqNo uint64, reqBatch *RequestBatch) {
+	for _, req := range reqBatch.GetBatch() {
+		reconfig := req.GetReconfiguration()
+		if reconfig == nil {
+			continue
+		}
+		sequence, err := instance.verifyReconfiguration(reconfig)
+		if err != nil {
+			logger.Warningf("Replica %d ignoring %s of replica %d: %s", instance.id, reconfig.Action, reconfig.ReplicaId, err)
+			continue
+		}
+
+		replicas := instance.pendingReplicas()
+		member := false
+		for _, id := range replicas {
+			if id == reconfig.ReplicaId {
+				member = true
+			}
+		}
+		switch {
+		case reconfig.Action == Reconfiguration_ADD && member:
+			logger.Warningf("Replica %d ignoring addition of replica %d, it is already a member", instance.id, reconfig.ReplicaId)
+			continue
+		case reconfig.Action == Reconfiguration_REMOVE && !member:
+			logger.Warningf("Replica %d ignoring removal of replica %d, it is not a member", instance.id, reconfig.ReplicaId)
+			continue
+		case reconfig.Action == Reconfiguration_REMOVE && len(replicas) == 1:
+			logger.Warningf("Replica %d ignoring removal of replica %d, it is the last member", instance.id, reconfig.ReplicaId)
+			continue
+		}
+
+		if len(instance.pending) == 0 {
+			instance.activation = (seqNo + instance.K - 1) / instance.K * instance.K
+		}
+		instance.pending = append(instance.pending, reconfig)
+		instance.reconfigSeq = sequence
+		logger.Infof("Replica %d ordered %s of replica %d at seqNo %d, effective at checkpoint %d",
+			instance.id, reconfig.Action, reconfig.ReplicaId, seqNo, instance.activation)
+	}
+}
+
+// newReconfiguration returns the reconfiguration ordered for a request of
+// the administrators
+func newReconfiguration(req *pb.ValidatorReconfiguration) (*Reconfiguration, error) {
+	id, err := getValidatorID(req.Validator)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := req.SignedBytes()
+	if err != nil {
+		return nil, err
+	}
+	action := Reconfiguration_ADD
+	if req.Action == pb.ValidatorReconfiguration_REMOVE {
+		action = Reconfiguration_REMOVE
+	}
+	return &Reconfiguration{Action: action, ReplicaId: id, Request: raw, Signatures: req.Signatures}, nil
+}
+
+// verifyReconfiguration checks that a reconfiguration is the one its
+// request asks for, that the request follows the last reconfiguration
+// recorded and that it is signed by enough administrators. It returns the
+// sequence of the request.
+func (instance *pbftCore) verifyReconfiguration(reconfig *Reconfiguration) (uint64, error) {
+	req := &pb.ValidatorReconfiguration{}
+	if err := proto.Unmarshal(reconfig.Request, req); err != nil {
+		return 0, fmt.Errorf("invalid request: %s", err)
+	}
+	if err := instance.verifyChain(req); err != nil {
+		return 0, err
+	}
+	expected, err := newReconfiguration(req)
+	if err != nil {
+		return 0, err
+	}
+	if expected.Action != reconfig.Action || expected.ReplicaId != reconfig.ReplicaId {
+		return 0, fmt.Errorf("the request is for the %s of replica %d", expected.Action, expected.ReplicaId)
+	}
+	if req.Sequence != instance.reconfigSeq+1 {
+		return 0, fmt.Errorf("request sequence %d, expected %d", req.Sequence, instance.reconfigSeq+1)
+	}
+	if err = instance.verifyAdminSignatures(expected.Request, reconfig.Signatures); err != nil {
+		return 0, err
+	}
+	return req.Sequence, nil
+}
+
+// chainScoped is implemented by stacks which order a chain hosted by the
+// peer
+type chainScoped interface {
+	ChainID() string
+}
+
+// verifyChain checks that a request changes the validators of the chain of
+// the replica, administrators sign the chain with the request so that it is
+// not ordered on another chain
+func (instance *pbftCore) verifyChain(req *pb.ValidatorReconfiguration) error {
+	if db.NormalizeChainID(req.ChainID) != db.NormalizeChainID(instance.chainID) {
+		return fmt.Errorf("the request is for chain %s", db.NormalizeChainID(req.ChainID))
+	}
+	return nil
+}
+
+// verifyAdminSignatures checks that raw is signed by enough distinct
+// administrators
+func (instance *pbftCore) verifyAdminSignatures(raw []byte, signatures [][]byte) error {
+	if len(instance.admins) == 0 {
+		return fmt.Errorf("no reconfiguration administrators configured")
+	}
+	digest := sha256.Sum256(raw)
+	signed := make(map[int]bool)
+	for _, encoded := range signatures {
+		sig := &primitives.ECDSASignature{}
+		if _, err := asn1.Unmarshal(encoded, sig); err != nil {
+			continue
+		}
+		for i, admin := range instance.admins {
+			if !signed[i] && ecdsa.Verify(admin, digest[:], sig.R, sig.S) {
+				signed[i] = true
+				break
+			}
+		}
+	}
+	if len(signed) < instance.adminThreshold {
+		return fmt.Errorf("signed by %d administrators, %d required", len(signed), instance.adminThreshold)
+	}
+	return nil
+}
+
+// loadAdmins reads the administrators whose signatures reconfigurations
+// need, and how many of them must sign, from general.reconfiguration
+func loadAdmins(config *viper.Viper) ([]*ecdsa.PublicKey, int, error) {
+	var admins []*ecdsa.PublicKey
+	for _, file := range config.GetStringSlice("general.reconfiguration.admins") {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error reading administrator certificate %s: %s", file, err)
+		}
+		cert, err := primitives.PEMtoCertificate(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error parsing administrator certificate %s: %s", file, err)
+		}
+		key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, 0, fmt.Errorf("Administrator certificate %s does not hold an ECDSA key", file)
+		}
+		admins = append(admins, key)
+	}
+	threshold := config.GetInt("general.reconfiguration.threshold")
+	if threshold <= 0 {
+		threshold = len(admins)
+	}
+	if threshold > len(admins) {
+		return nil, 0, fmt.Errorf("Invalid configuration, %d signatures required of %d administrators", threshold, len(admins))
+	}
+	return admins, threshold, nil
+}
+
+// pendingReplicas returns the set of replicas once the pending
+// reconfigurations are applied
+func (instance *pbftCore) pendingReplicas() []uint64 {
+	replicas := append([]uint64(nil), instance.replicas...)
+	for _, reconfig := range instance.pending {
+		if reconfig.Action == Reconfiguration_ADD {
+			replicas = append(replicas, reconfig.ReplicaId)
+			continue
+		}
+		for i, id := range replicas {
+			if id == reconfig.ReplicaId {
+				replicas = append(replicas[:i], replicas[i+1:]...)
+				break
+			}
+		}
+	}
+	return replicas
+}
+
+// applyReconfigurations switches to the new membership once the low
+// watermark reached the activation checkpoint, and replays the messages
+// which were held back until then
+func (instance *pbftCore) applyReconfigurations() {
+	if len(instance.pending) == 0 || instance.h < instance.activation {
+		return
+	}
+
+	replicas := instance.pendingReplicas()
+	instance.pending = nil
+	instance.setReplicas(replicas, (len(replicas)-1)/3)
+
+	// Nothing was assigned after the activation checkpoint, a new primary starts from there
+	if instance.seqNo < instance.h {
+		instance.seqNo = instance.h
+	}
+
+	deferred := instance.deferred
+	instance.deferred = nil
+	instance.deferredIDs = nil
+	for _, msg := range deferred {
+		instance.ProcessEvent(msg)
+	}
+}
+
+// deferredID identifies a message held back until the pending
+// reconfigurations are applied
+type deferredID struct {
+	kind    string
+	replica uint64
+	v       uint64
+	n       uint64
+}
+
+func newDeferredID(msg interface{}) deferredID {
+	switch msg := msg.(type) {
+	case *PrePrepare:
+		return deferredID{"pre-prepare", msg.ReplicaId, msg.View, msg.SequenceNumber}
+	case *Prepare:
+		return deferredID{"prepare", msg.ReplicaId, msg.View, msg.SequenceNumber}
+	case *Commit:
+		return deferredID{"commit", msg.ReplicaId, msg.View, msg.SequenceNumber}
+	case *Checkpoint:
+		return deferredID{"checkpoint", msg.ReplicaId, 0, msg.SequenceNumber}
+	}
+	panic(fmt.Sprintf("cannot defer %T", msg))
+}
+
+// deferBeyondActivation holds back a message for a sequence number after a
+// pending reconfiguration, the membership it must be checked against is not
+// known before the reconfiguration is applied. A replica sends at most one
+// message of each type per view and sequence number, its duplicates are
+// dropped, which bounds what is held back by the watermarks. It returns true
+// if the message was held back or dropped.
+func (instance *pbftCore) deferBeyondActivation(n uint64, msg interface{}) bool {
+	if len(instance.pending) == 0 || n <= instance.activation || n > instance.h+instance.L {
+		return false
+	}
+	id := newDeferredID(msg)
+	if instance.deferredIDs[id] {
+		logger.Warningf("Replica %d dropping duplicate %s from replica %d for view=%d/seqNo=%d held back until checkpoint %d",
+			instance.id, id.kind, id.replica, id.v, id.n, instance.activation)
+		return true
+	}
+	if instance.deferredIDs == nil {
+		instance.deferredIDs = make(map[deferredID]bool)
+	}
+	instance.deferredIDs[id] = true
+	logger.Debugf("Replica %d deferring %T for seqNo %d until the reconfiguration at checkpoint %d is applied",
+		instance.id, msg, n, instance.activation)
+	instance.deferred = append(instance.deferred, msg)
+	return true
+}
+
+func equalReplicas(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,325 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+// reconfigurationAdmin signs the reconfigurations of the tests, its
+// certificate is written to certFile the first time a configuration needs it
+var reconfigurationAdmin struct {
+	sync.Once
+	key      *ecdsa.PrivateKey
+	certFile string
+}
+
+func init() {
+	var err error
+	if reconfigurationAdmin.key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		panic(err)
+	}
+}
+
+func reconfigurationAdminCert() string {
+	reconfigurationAdmin.Do(func() {
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "admin"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &reconfigurationAdmin.key.PublicKey, reconfigurationAdmin.key)
+		if err != nil {
+			panic(err)
+		}
+		file, err := ioutil.TempFile("", "pbft-admin")
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		if err = pem.Encode(file, &pem.Block{Type: "CERTIFICATE", Bytes: raw}); err != nil {
+			panic(err)
+		}
+		reconfigurationAdmin.certFile = file.Name()
+	})
+	return reconfigurationAdmin.certFile
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if reconfigurationAdmin.certFile != "" {
+		os.Remove(reconfigurationAdmin.certFile)
+	}
+	os.Exit(code)
+}
+
+func reconfigurationConfig(N int) *viper.Viper {
+	config := loadConfig()
+	config.Set("general.reconfiguration.admins", []string{reconfigurationAdminCert()})
+	config.Set("general.N", N)
+	config.Set("general.f", (N-1)/3)
+	config.Set("general.K", 2)
+	config.Set("general.logmultiplier", 2)
+	return config
+}
+
+// submit hands a request batch to the primary of the view replica 0 is in
+func (net *pbftNetwork) submit(t *testing.T, reqBatch *RequestBatch) {
+	pbft := net.pbftEndpoints[0].pbft
+	net.pbftEndpoints[pbft.primary(pbft.view)].manager.Queue() <- reqBatch
+	if err := net.process(); err != nil {
+		t.Fatalf("Processing failed: %s", err)
+	}
+}
+
+func TestReconfigurationIgnoresInvalidChanges(t *testing.T) {
+	net := makePBFTNetwork(4, reconfigurationConfig(4))
+	defer net.stop()
+
+	instance := net.pbftEndpoints[0].pbft
+	done := make(chan struct{})
+	net.pbftEndpoints[0].manager.Queue() <- workEvent(func() {
+		defer close(done)
+		instance.recordReconfigurations(3, createPbftReconfigurationBatch(1, 0, Reconfiguration_ADD, 2, 1))
+		instance.recordReconfigurations(3, createPbftReconfigurationBatch(2, 0, Reconfiguration_REMOVE, 9, 1))
+		if len(instance.pending) != 0 {
+			t.Errorf("Expected the addition of a member and the removal of a non member to be ignored, pending %v", instance.pending)
+		}
+
+		instance.recordReconfigurations(3, createPbftReconfigurationBatch(3, 0, Reconfiguration_ADD, 9, 1))
+		instance.recordReconfigurations(4, createPbftReconfigurationBatch(4, 0, Reconfiguration_ADD, 9, 2))
+		if len(instance.pending) != 1 || instance.activation != 4 || instance.reconfigSeq != 1 {
+			t.Errorf("Expected one reconfiguration effective at checkpoint 4, got %v effective at %d, sequence %d", instance.pending, instance.activation, instance.reconfigSeq)
+		}
+	})
+	<-done
+}
+
+func TestDeferredMessagesDeduplicated(t *testing.T) {
+	net := makePBFTNetwork(4, reconfigurationConfig(4))
+	defer net.stop()
+
+	instance := net.pbftEndpoints[0].pbft
+	done := make(chan struct{})
+	net.pbftEndpoints[0].manager.Queue() <- workEvent(func() {
+		defer close(done)
+		instance.recordReconfigurations(1, createPbftReconfigurationBatch(1, 0, Reconfiguration_ADD, 9, 1))
+		if instance.activation != 2 {
+			t.Fatalf("Expected the reconfiguration to be effective at checkpoint 2, got %d", instance.activation)
+		}
+
+		for i := 0; i < 10; i++ {
+			for replica := uint64(1); replica <= 2; replica++ {
+				if !instance.deferBeyondActivation(3, &Prepare{View: 0, SequenceNumber: 3, BatchDigest: fmt.Sprintf("%d", i), ReplicaId: replica}) {
+					t.Fatalf("Expected the prepare of replica %d for seqNo 3 to be held back", replica)
+				}
+				instance.deferBeyondActivation(3, &Checkpoint{SequenceNumber: 3, ReplicaId: replica})
+			}
+		}
+		instance.deferBeyondActivation(3, &Commit{View: 0, SequenceNumber: 3, ReplicaId: 1})
+		if len(instance.deferred) != 5 {
+			t.Fatalf("Expected one prepare and one checkpoint per replica and the commit to be held back, got %d messages", len(instance.deferred))
+		}
+		if prep, ok := instance.deferred[0].(*Prepare); !ok || prep.BatchDigest != "0" {
+			t.Errorf("Expected the first prepare to be kept, got %v", instance.deferred[0])
+		}
+	})
+	<-done
+}
+
+func TestReconfigurationAddReplica(t *testing.T) {
+	validatorCount := 4
+	net := makePBFTNetworkWithEndpoints(validatorCount+1, reconfigurationConfig(validatorCount))
+	defer net.stop()
+
+	joining := net.pbftEndpoints[validatorCount]
+	if !joining.pbft.skipInProgress {
+		t.Fatalf("Replica %d is not a member, it should be waiting to catch up", joining.id)
+	}
+
+	net.submit(t, createPbftReconfigurationBatch(1, 0, Reconfiguration_ADD, uint64(validatorCount), 1))
+
+	for _, pep := range net.pbftEndpoints[:validatorCount] {
+		if pep.pbft.N != validatorCount || len(pep.pbft.pending) != 1 || pep.pbft.activation != 2 {
+			t.Fatalf("Replica %d should have ordered the addition for checkpoint 2 without applying it, N=%d, pending %v, activation %d",
+				pep.id, pep.pbft.N, pep.pbft.pending, pep.pbft.activation)
+		}
+	}
+
+	net.submit(t, createPbftReqBatch(2, 0))
+
+	expected := []uint64{0, 1, 2, 3, 4}
+	for _, pep := range net.pbftEndpoints {
+		if pep.pbft.N != 5 || pep.pbft.f != 1 || len(pep.pbft.pending) != 0 {
+			t.Errorf("Replica %d should have applied the addition at checkpoint 2, N=%d, f=%d, pending %v", pep.id, pep.pbft.N, pep.pbft.f, pep.pbft.pending)
+		}
+		if !reflect.DeepEqual(pep.sc.members, expected) {
+			t.Errorf("Replica %d reported membership %v, expected %v", pep.id, pep.sc.members, expected)
+		}
+	}
+	if !joining.sc.skipOccurred || joining.pbft.skipInProgress || joining.pbft.lastExec != 2 {
+		t.Fatalf("Replica %d should have caught up to seqNo 2 through state transfer, lastExec is %d", joining.id, joining.pbft.lastExec)
+	}
+
+	// The new replica takes part in the following requests, which now need 4 replicas to commit
+	net.filterFn = func(src, dst int, msg []byte) []byte {
+		if src == 3 || dst == 3 {
+			return nil
+		}
+		return msg
+	}
+	reqBatch := createPbftReqBatch(3, 0)
+	net.submit(t, reqBatch)
+	net.submit(t, createPbftReqBatch(4, 0))
+
+	for _, pep := range net.pbftEndpoints {
+		if pep.id == 3 {
+			continue
+		}
+		if pep.pbft.lastExec != 4 {
+			t.Errorf("Replica %d should have executed up to seqNo 4, got %d", pep.id, pep.pbft.lastExec)
+		}
+	}
+	if joining.sc.executions != 4 || joining.sc.lastExecution != hash(createPbftReqBatch(4, 0).GetBatch()[0]) {
+		t.Errorf("Replica %d did not execute the requests after joining, executions %d", joining.id, joining.sc.executions)
+	}
+	if net.pbftEndpoints[0].pbft.h != 4 {
+		t.Errorf("Expected a stable checkpoint at seqNo 4 with the new replica, low watermark is %d", net.pbftEndpoints[0].pbft.h)
+	}
+}
+
+func TestReconfigurationRemovePrimary(t *testing.T) {
+	validatorCount := 4
+	net := makePBFTNetwork(validatorCount, reconfigurationConfig(validatorCount))
+	defer net.stop()
+
+	net.submit(t, createPbftReconfigurationBatch(1, 0, Reconfiguration_REMOVE, 0, 1))
+	net.submit(t, createPbftReqBatch(2, 0))
+
+	expected := []uint64{1, 2, 3}
+	for _, pep := range net.pbftEndpoints {
+		if pep.pbft.N != 3 || pep.pbft.f != 0 {
+			t.Errorf("Replica %d should have N=3 and f=0 after the removal, got N=%d, f=%d", pep.id, pep.pbft.N, pep.pbft.f)
+		}
+		if !reflect.DeepEqual(pep.sc.members, expected) {
+			t.Errorf("Replica %d reported membership %v, expected %v", pep.id, pep.sc.members, expected)
+		}
+		if primary := pep.pbft.primary(pep.pbft.view); primary != 1 {
+			t.Errorf("Replica %d expects primary %d, the removed primary should be replaced by replica 1", pep.id, primary)
+		}
+	}
+
+	// The removed replica does not take part anymore
+	var lock sync.Mutex
+	fromRemoved := 0
+	net.filterFn = func(src, dst int, msg []byte) []byte {
+		if src == 0 {
+			lock.Lock()
+			fromRemoved++
+			lock.Unlock()
+		}
+		return msg
+	}
+	net.submit(t, createPbftReqBatch(3, 1))
+	net.submit(t, createPbftReqBatch(4, 1))
+
+	for _, pep := range net.pbftEndpoints[1:] {
+		if pep.pbft.lastExec != 4 || pep.pbft.h != 4 {
+			t.Errorf("Replica %d should have executed and checkpointed seqNo 4, lastExec %d, low watermark %d", pep.id, pep.pbft.lastExec, pep.pbft.h)
+		}
+	}
+	if fromRemoved != 0 {
+		t.Errorf("The removed replica sent %d messages", fromRemoved)
+	}
+}
+
+func TestReconfigurationRequiresAdministrators(t *testing.T) {
+	net := makePBFTNetwork(4, reconfigurationConfig(4))
+	defer net.stop()
+
+	instance := net.pbftEndpoints[0].pbft
+	done := make(chan struct{})
+	net.pbftEndpoints[0].manager.Queue() <- workEvent(func() {
+		defer close(done)
+
+		// Signed by a replica instead of an administrator
+		replicaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		signed := &pb.ValidatorReconfiguration{Action: pb.ValidatorReconfiguration_ADD, Validator: &pb.PeerID{Name: "vp9"}, Sequence: 1}
+		signed.Sign(replicaKey)
+		reconfig, _ := newReconfiguration(signed)
+		instance.recordReconfigurations(3, &RequestBatch{Batch: []*Request{{ReplicaId: 1, Reconfiguration: reconfig}}})
+		if len(instance.pending) != 0 {
+			t.Errorf("Expected a reconfiguration not signed by an administrator to be ignored")
+		}
+
+		// Signed for another chain
+		otherChain := &pb.ValidatorReconfiguration{Action: pb.ValidatorReconfiguration_ADD, Validator: &pb.PeerID{Name: "vp9"}, Sequence: 1, ChainID: "chain1"}
+		otherChain.Sign(reconfigurationAdmin.key)
+		reconfig, _ = newReconfiguration(otherChain)
+		instance.recordReconfigurations(3, &RequestBatch{Batch: []*Request{{ReplicaId: 1, Reconfiguration: reconfig}}})
+		if len(instance.pending) != 0 {
+			t.Errorf("Expected a reconfiguration of another chain to be ignored")
+		}
+
+		// A signed request for another replica
+		reconfig = createPbftReconfigurationBatch(1, 0, Reconfiguration_ADD, 9, 1).Batch[0].Reconfiguration
+		reconfig.ReplicaId = 8
+		instance.recordReconfigurations(3, &RequestBatch{Batch: []*Request{{ReplicaId: 1, Reconfiguration: reconfig}}})
+		if len(instance.pending) != 0 {
+			t.Errorf("Expected a reconfiguration which is not the one signed to be ignored")
+		}
+
+		// A replayed request
+		instance.recordReconfigurations(3, createPbftReconfigurationBatch(2, 0, Reconfiguration_REMOVE, 3, 1))
+		instance.recordReconfigurations(3, createPbftReconfigurationBatch(3, 0, Reconfiguration_REMOVE, 3, 1))
+		instance.recordReconfigurations(3, createPbftReconfigurationBatch(4, 0, Reconfiguration_ADD, 9, 1))
+		if len(instance.pending) != 1 || instance.reconfigSeq != 1 {
+			t.Errorf("Expected only the first request of sequence 1 to be recorded, pending %v", instance.pending)
+		}
+	})
+	<-done
+
+	// Refused before being ordered
+	op := &obcBatch{pbft: instance}
+	err := op.ReconfigureValidators(&pb.ValidatorReconfiguration{Action: pb.ValidatorReconfiguration_ADD, Validator: &pb.PeerID{Name: "vp9"}, Sequence: 2})
+	if err == nil {
+		t.Errorf("Expected an unsigned reconfiguration to be refused")
+	}
+	otherChain := &pb.ValidatorReconfiguration{Action: pb.ValidatorReconfiguration_ADD, Validator: &pb.PeerID{Name: "vp9"}, Sequence: 2, ChainID: "chain1"}
+	otherChain.Sign(reconfigurationAdmin.key)
+	if err = op.ReconfigureValidators(otherChain); err == nil || !strings.Contains(err.Error(), "chain1") {
+		t.Errorf("Expected a reconfiguration of another chain to be refused, got %v", err)
+	}
+}
@@ -20,8 +20,11 @@ It has these top-level messages:
 	PQset
 	NewView
 	FetchRequestBatch
+	SessionKey
 	RequestBatch
 	BatchMessage
+	Reconfiguration
+	Membership
 	Metadata
 */
 package pbft
@@ -42,6 +45,27 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type ReconfigurationAction int32
+
+const (
+	Reconfiguration_ADD    ReconfigurationAction = 0
+	Reconfiguration_REMOVE ReconfigurationAction = 1
+)
+
+var ReconfigurationAction_name = map[int32]string{
+	0: "ADD",
+	1: "REMOVE",
+}
+var ReconfigurationAction_value = map[string]int32{
+	"ADD":    0,
+	"REMOVE": 1,
+}
+
+func (x ReconfigurationAction) String() string {
+	return proto.EnumName(ReconfigurationAction_name, int32(x))
+}
+func (ReconfigurationAction) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{14, 0} }
+
 type Message struct {
 	// Types that are valid to be assigned to Payload:
 	//	*Message_RequestBatch
@@ -53,7 +77,10 @@ type Message struct {
 	//	*Message_NewView
 	//	*Message_FetchRequestBatch
 	//	*Message_ReturnRequestBatch
-	Payload isMessage_Payload `protobuf_oneof:"payload"`
+	//	*Message_SessionKey
+	Payload       isMessage_Payload `protobuf_oneof:"payload"`
+	Signature     []byte            `protobuf:"bytes,11,opt,name=signature,proto3" json:"signature,omitempty"`
+	Authenticator map[uint64][]byte `protobuf:"bytes,12,rep,name=authenticator" json:"authenticator,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
@@ -92,6 +119,9 @@ type Message_FetchRequestBatch struct {
 type Message_ReturnRequestBatch struct {
 	ReturnRequestBatch *RequestBatch `protobuf:"bytes,9,opt,name=return_request_batch,json=returnRequestBatch,oneof"`
 }
+type Message_SessionKey struct {
+	SessionKey *SessionKey `protobuf:"bytes,10,opt,name=session_key,json=sessionKey,oneof"`
+}
 
 func (*Message_RequestBatch) isMessage_Payload()       {}
 func (*Message_PrePrepare) isMessage_Payload()         {}
@@ -102,6 +132,7 @@ func (*Message_ViewChange) isMessage_Payload()         {}
 func (*Message_NewView) isMessage_Payload()            {}
 func (*Message_FetchRequestBatch) isMessage_Payload()  {}
 func (*Message_ReturnRequestBatch) isMessage_Payload() {}
+func (*Message_SessionKey) isMessage_Payload()         {}
 
 func (m *Message) GetPayload() isMessage_Payload {
 	if m != nil {
@@ -173,6 +204,20 @@ func (m *Message) GetReturnRequestBatch() *RequestBatch {
 	return nil
 }
 
+func (m *Message) GetSessionKey() *SessionKey {
+	if x, ok := m.GetPayload().(*Message_SessionKey); ok {
+		return x.SessionKey
+	}
+	return nil
+}
+
+func (m *Message) GetAuthenticator() map[uint64][]byte {
+	if m != nil {
+		return m.Authenticator
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Message_OneofMarshaler, _Message_OneofUnmarshaler, _Message_OneofSizer, []interface{}{
@@ -185,6 +230,7 @@ func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error
 		(*Message_NewView)(nil),
 		(*Message_FetchRequestBatch)(nil),
 		(*Message_ReturnRequestBatch)(nil),
+		(*Message_SessionKey)(nil),
 	}
 }
 
@@ -237,6 +283,11 @@ func _Message_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.ReturnRequestBatch); err != nil {
 			return err
 		}
+	case *Message_SessionKey:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SessionKey); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Message.Payload has unexpected type %T", x)
@@ -319,6 +370,14 @@ func _Message_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer
 		err := b.DecodeMessage(msg)
 		m.Payload = &Message_ReturnRequestBatch{msg}
 		return true, err
+	case 10: // payload.session_key
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SessionKey)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_SessionKey{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -373,6 +432,11 @@ func _Message_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(9<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Message_SessionKey:
+		s := proto.Size(x.SessionKey)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -381,10 +445,11 @@ func _Message_OneofSizer(msg proto.Message) (n int) {
 }
 
 type Request struct {
-	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
-	Payload   []byte                     `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
-	ReplicaId uint64                     `protobuf:"varint,3,opt,name=replica_id,json=replicaId" json:"replica_id,omitempty"`
-	Signature []byte                     `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Timestamp       *google_protobuf.Timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	Payload         []byte                     `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	ReplicaId       uint64                     `protobuf:"varint,3,opt,name=replica_id,json=replicaId" json:"replica_id,omitempty"`
+	Signature       []byte                     `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Reconfiguration *Reconfiguration           `protobuf:"bytes,5,opt,name=reconfiguration" json:"reconfiguration,omitempty"`
 }
 
 func (m *Request) Reset()                    { *m = Request{} }
@@ -399,6 +464,13 @@ func (m *Request) GetTimestamp() *google_protobuf.Timestamp {
 	return nil
 }
 
+func (m *Request) GetReconfiguration() *Reconfiguration {
+	if m != nil {
+		return m.Reconfiguration
+	}
+	return nil
+}
+
 type PrePrepare struct {
 	View           uint64        `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
 	SequenceNumber uint64        `protobuf:"varint,2,opt,name=sequence_number,json=sequenceNumber" json:"sequence_number,omitempty"`
@@ -574,6 +646,19 @@ func (m *FetchRequestBatch) String() string            { return proto.CompactTex
 func (*FetchRequestBatch) ProtoMessage()               {}
 func (*FetchRequestBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
+// session_key carries the ephemeral ECDH key of a replica, from which each
+// pair of replicas derives the key of their message authentication codes
+type SessionKey struct {
+	ReplicaId uint64 `protobuf:"varint,1,opt,name=replica_id,json=replicaId" json:"replica_id,omitempty"`
+	PublicKey []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Reply     bool   `protobuf:"varint,3,opt,name=reply" json:"reply,omitempty"`
+}
+
+func (m *SessionKey) Reset()                    { *m = SessionKey{} }
+func (m *SessionKey) String() string            { return proto.CompactTextString(m) }
+func (*SessionKey) ProtoMessage()               {}
+func (*SessionKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
 type RequestBatch struct {
 	Batch []*Request `protobuf:"bytes,1,rep,name=batch" json:"batch,omitempty"`
 }
@@ -581,7 +666,7 @@ type RequestBatch struct {
 func (m *RequestBatch) Reset()                    { *m = RequestBatch{} }
 func (m *RequestBatch) String() string            { return proto.CompactTextString(m) }
 func (*RequestBatch) ProtoMessage()               {}
-func (*RequestBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*RequestBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 func (m *RequestBatch) GetBatch() []*Request {
 	if m != nil {
@@ -602,7 +687,7 @@ type BatchMessage struct {
 func (m *BatchMessage) Reset()                    { *m = BatchMessage{} }
 func (m *BatchMessage) String() string            { return proto.CompactTextString(m) }
 func (*BatchMessage) ProtoMessage()               {}
-func (*BatchMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*BatchMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 type isBatchMessage_Payload interface {
 	isBatchMessage_Payload()
@@ -769,14 +854,54 @@ func _BatchMessage_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
+type Reconfiguration struct {
+	Action     ReconfigurationAction `protobuf:"varint,1,opt,name=action,enum=pbft.ReconfigurationAction" json:"action,omitempty"`
+	ReplicaId  uint64                `protobuf:"varint,2,opt,name=replica_id,json=replicaId" json:"replica_id,omitempty"`
+	Request    []byte                `protobuf:"bytes,3,opt,name=request,proto3" json:"request,omitempty"`
+	Signatures [][]byte              `protobuf:"bytes,4,rep,name=signatures,proto3" json:"signatures,omitempty"`
+}
+
+func (m *Reconfiguration) Reset()                    { *m = Reconfiguration{} }
+func (m *Reconfiguration) String() string            { return proto.CompactTextString(m) }
+func (*Reconfiguration) ProtoMessage()               {}
+func (*Reconfiguration) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+type Membership struct {
+	Replicas   []uint64           `protobuf:"varint,1,rep,packed,name=replicas" json:"replicas,omitempty"`
+	F          uint64             `protobuf:"varint,2,opt,name=f" json:"f,omitempty"`
+	Pending    []*Reconfiguration `protobuf:"bytes,3,rep,name=pending" json:"pending,omitempty"`
+	Activation uint64             `protobuf:"varint,4,opt,name=activation" json:"activation,omitempty"`
+	Sequence   uint64             `protobuf:"varint,5,opt,name=sequence" json:"sequence,omitempty"`
+}
+
+func (m *Membership) Reset()                    { *m = Membership{} }
+func (m *Membership) String() string            { return proto.CompactTextString(m) }
+func (*Membership) ProtoMessage()               {}
+func (*Membership) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *Membership) GetPending() []*Reconfiguration {
+	if m != nil {
+		return m.Pending
+	}
+	return nil
+}
+
 type Metadata struct {
-	SeqNo uint64 `protobuf:"varint,1,opt,name=seqNo" json:"seqNo,omitempty"`
+	SeqNo      uint64      `protobuf:"varint,1,opt,name=seqNo" json:"seqNo,omitempty"`
+	Membership *Membership `protobuf:"bytes,2,opt,name=membership" json:"membership,omitempty"`
 }
 
 func (m *Metadata) Reset()                    { *m = Metadata{} }
 func (m *Metadata) String() string            { return proto.CompactTextString(m) }
 func (*Metadata) ProtoMessage()               {}
-func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *Metadata) GetMembership() *Membership {
+	if m != nil {
+		return m.Membership
+	}
+	return nil
+}
 
 func init() {
 	proto.RegisterType((*Message)(nil), "pbft.message")
@@ -792,66 +917,88 @@ func init() {
 	proto.RegisterType((*PQset)(nil), "pbft.PQset")
 	proto.RegisterType((*NewView)(nil), "pbft.new_view")
 	proto.RegisterType((*FetchRequestBatch)(nil), "pbft.fetch_request_batch")
+	proto.RegisterType((*SessionKey)(nil), "pbft.session_key")
 	proto.RegisterType((*RequestBatch)(nil), "pbft.request_batch")
 	proto.RegisterType((*BatchMessage)(nil), "pbft.batch_message")
+	proto.RegisterType((*Reconfiguration)(nil), "pbft.reconfiguration")
+	proto.RegisterType((*Membership)(nil), "pbft.membership")
 	proto.RegisterType((*Metadata)(nil), "pbft.metadata")
+	proto.RegisterEnum("pbft.ReconfigurationAction", ReconfigurationAction_name, ReconfigurationAction_value)
 }
 
 func init() { proto.RegisterFile("messages.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 848 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xc4, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
-	0x10, 0xee, 0x52, 0x94, 0x64, 0x8e, 0x68, 0xd5, 0x5e, 0xfb, 0xc0, 0x0a, 0x35, 0xea, 0xd2, 0xa8,
-	0x6b, 0xa3, 0x2d, 0x0d, 0xb8, 0x06, 0x6a, 0x18, 0x3d, 0xd9, 0x2d, 0xea, 0xa2, 0xa8, 0x21, 0x13,
-	0x41, 0x92, 0x1b, 0x41, 0x51, 0x2b, 0x89, 0xb0, 0x44, 0xd2, 0x14, 0xe5, 0xc4, 0x2f, 0x90, 0xe4,
-	0x92, 0x17, 0xc8, 0x03, 0xe5, 0x92, 0x73, 0xde, 0x27, 0xbb, 0xc3, 0xa5, 0xf8, 0x23, 0x45, 0xf6,
-	0x25, 0xc8, 0x81, 0xc0, 0xee, 0xcc, 0xf7, 0x0d, 0x67, 0xbf, 0x9d, 0xdd, 0x59, 0x68, 0x4f, 0xd8,
-	0x74, 0xea, 0x0e, 0xd9, 0xd4, 0x8a, 0xe2, 0x30, 0x09, 0xa9, 0x1a, 0xf5, 0x06, 0x49, 0xe7, 0x87,
-	0x61, 0x18, 0x0e, 0xc7, 0xec, 0x08, 0x6d, 0xbd, 0xd9, 0xe0, 0x28, 0xf1, 0x39, 0x2e, 0x71, 0x27,
-	0x51, 0x0a, 0x33, 0x5f, 0xa9, 0xd0, 0x94, 0x4c, 0x7a, 0x06, 0xeb, 0x31, 0xbb, 0x9d, 0x71, 0xbf,
-	0xd3, 0x73, 0x13, 0x6f, 0x64, 0x90, 0x5d, 0x72, 0xd0, 0x3a, 0xde, 0xb2, 0x44, 0x28, 0xab, 0xe4,
-	0xba, 0xfc, 0xc6, 0xd6, 0xa5, 0xe1, 0x5c, 0xcc, 0xe9, 0x09, 0xb4, 0xa2, 0x98, 0x39, 0xfc, 0x8b,
-	0xdc, 0x98, 0x19, 0x0a, 0x32, 0x37, 0x53, 0x66, 0xc1, 0xc1, 0x79, 0xc0, 0x87, 0xdd, 0x74, 0x46,
-	0x0f, 0xa1, 0x99, 0x31, 0x6a, 0xc8, 0x58, 0x9f, 0x33, 0x24, 0x3a, 0xf3, 0xd3, 0x7d, 0x68, 0x78,
-	0xe1, 0x64, 0xe2, 0x27, 0x86, 0x8a, 0x48, 0x3d, 0x45, 0xa6, 0x36, 0x0e, 0x94, 0x5e, 0x7a, 0x0c,
-	0xe0, 0x8d, 0x98, 0x77, 0x13, 0x85, 0x7e, 0x90, 0x18, 0x75, 0xc4, 0x6e, 0x48, 0xec, 0xdc, 0x2e,
-	0xd2, 0xc8, 0x67, 0x22, 0xf9, 0x3b, 0x9f, 0xbd, 0x70, 0xbc, 0x91, 0x1b, 0x0c, 0x99, 0xd1, 0x28,
-	0x26, 0x5f, 0x70, 0x08, 0x96, 0x98, 0x5e, 0xe0, 0x8c, 0xfe, 0x02, 0x6b, 0x01, 0xf7, 0x09, 0x8b,
-	0xd1, 0x44, 0x4a, 0x3b, 0xa5, 0x64, 0x56, 0x91, 0x3e, 0x1f, 0x3f, 0xe5, 0x43, 0xfa, 0x1f, 0x6c,
-	0x0d, 0x18, 0x17, 0xca, 0x29, 0x2b, 0xbc, 0x86, 0xbc, 0xef, 0x52, 0xde, 0x12, 0x00, 0x0f, 0xb1,
-	0x89, 0x66, 0xbb, 0x28, 0xf6, 0x3f, 0xb0, 0x1d, 0xb3, 0x64, 0x16, 0x07, 0x95, 0x68, 0xda, 0xaa,
-	0xfd, 0xa2, 0x29, 0xa5, 0x18, 0xe8, 0x5c, 0xe3, 0xfa, 0xbb, 0xf7, 0xe3, 0xd0, 0xed, 0x9b, 0xef,
-	0x08, 0x34, 0x25, 0x85, 0x9e, 0x82, 0x36, 0xaf, 0x13, 0x59, 0x04, 0x1d, 0x2b, 0xad, 0x24, 0x2b,
-	0xab, 0x24, 0xeb, 0x49, 0x86, 0xb0, 0x73, 0x30, 0x35, 0xe6, 0x01, 0xb1, 0x04, 0x74, 0x3b, 0x9b,
-	0xd2, 0x1d, 0x00, 0xbe, 0x93, 0x63, 0xdf, 0x73, 0x1d, 0xbf, 0x8f, 0xbb, 0xad, 0xda, 0x9a, 0xb4,
-	0xfc, 0xdb, 0xa7, 0xdf, 0x83, 0x36, 0xf5, 0x87, 0x81, 0xcb, 0x53, 0x64, 0xb8, 0xc3, 0xba, 0x9d,
-	0x1b, 0xcc, 0xf7, 0xa4, 0x54, 0x5e, 0x94, 0x82, 0x8a, 0xb2, 0x13, 0x0c, 0x83, 0x63, 0xfa, 0x33,
-	0x7c, 0x3b, 0x15, 0xf9, 0x07, 0x1e, 0x73, 0x82, 0xd9, 0xa4, 0xc7, 0x62, 0x4c, 0x41, 0xb5, 0xdb,
-	0x99, 0xf9, 0x0a, 0xad, 0xf4, 0x47, 0xd0, 0x51, 0x13, 0xa7, 0xef, 0xf3, 0xe3, 0x92, 0x60, 0x2e,
-	0x9a, 0xdd, 0x42, 0xdb, 0x5f, 0x68, 0xe2, 0x02, 0x54, 0x4e, 0x82, 0xfa, 0x59, 0x65, 0x2b, 0xe7,
-	0xa0, 0xbc, 0xcc, 0x7a, 0x65, 0x99, 0xe6, 0x1b, 0x32, 0xaf, 0xf8, 0x2f, 0xbe, 0x88, 0x72, 0x2a,
-	0x6a, 0x35, 0x95, 0xd7, 0x24, 0x3b, 0x51, 0x5f, 0x3b, 0x93, 0x2b, 0x80, 0xde, 0x38, 0xf4, 0x6e,
-	0x1c, 0x3f, 0x18, 0x84, 0x18, 0x0f, 0x67, 0xf2, 0xaf, 0x69, 0x52, 0x2d, 0xb4, 0xc9, 0x5f, 0xee,
-	0x64, 0x84, 0x91, 0x3b, 0x1d, 0xc9, 0x42, 0xd3, 0xd0, 0x72, 0xc9, 0x0d, 0x66, 0xbf, 0x78, 0x05,
-	0x2c, 0x5b, 0x08, 0x59, 0xba, 0x90, 0x72, 0x96, 0x4a, 0xb5, 0x42, 0xdb, 0xa0, 0xc8, 0xc2, 0xd5,
-	0x6c, 0x3e, 0x32, 0xdf, 0xd6, 0x4a, 0xb7, 0xc6, 0x52, 0x11, 0x75, 0x20, 0x23, 0x19, 0x89, 0x8c,
-	0x78, 0x26, 0xaa, 0x37, 0x65, 0x42, 0xa1, 0x5a, 0x5e, 0x4c, 0x85, 0x10, 0xd6, 0x85, 0x8d, 0x00,
-	0x7a, 0x00, 0x6a, 0x24, 0x80, 0x2a, 0x02, 0xb7, 0x17, 0x81, 0xdd, 0x6b, 0x1b, 0x11, 0x02, 0x79,
-	0x2b, 0x90, 0xf5, 0x55, 0x48, 0x81, 0xa8, 0xac, 0xae, 0xb1, 0xf2, 0xfc, 0x35, 0x2b, 0xe7, 0xaf,
-	0xf3, 0x27, 0x90, 0x8b, 0xc7, 0x0b, 0x59, 0x51, 0xaa, 0xd3, 0x07, 0xa5, 0x7b, 0xfd, 0x78, 0x7a,
-	0xb5, 0xa0, 0x94, 0xc5, 0x82, 0xca, 0xb4, 0xae, 0xe5, 0x5a, 0x9b, 0x47, 0x50, 0xef, 0x5e, 0x8b,
-	0x95, 0xee, 0x43, 0x4d, 0x48, 0x42, 0x56, 0x48, 0x22, 0x00, 0xe6, 0x07, 0x92, 0x5f, 0xe0, 0x4b,
-	0x77, 0xef, 0x27, 0x6e, 0x13, 0x91, 0x14, 0x8c, 0xb4, 0xd8, 0x0f, 0x6c, 0x74, 0xd3, 0x5f, 0x41,
-	0x7d, 0x99, 0x6f, 0xab, 0x51, 0xee, 0x01, 0xd6, 0x73, 0xee, 0xfa, 0x3b, 0x48, 0xe2, 0x7b, 0x1b,
-	0x51, 0x0f, 0x9c, 0x85, 0xce, 0x1f, 0xa0, 0xcd, 0x19, 0x74, 0x03, 0x6a, 0x37, 0xec, 0x5e, 0xe6,
-	0x24, 0x86, 0x74, 0x1b, 0xea, 0x77, 0xee, 0x78, 0xc6, 0xa4, 0x28, 0xe9, 0xe4, 0x4c, 0x39, 0x25,
-	0xe6, 0xb3, 0xa5, 0x0d, 0x66, 0x41, 0x4c, 0xf2, 0xd0, 0xe9, 0xac, 0xd6, 0xbd, 0x79, 0x52, 0xb9,
-	0x0b, 0xe9, 0x1e, 0xd4, 0xb3, 0xe7, 0x41, 0x2d, 0x6f, 0xd9, 0x12, 0x63, 0xa7, 0x3e, 0xf3, 0x23,
-	0x81, 0xf5, 0xf4, 0xc7, 0xd9, 0xeb, 0xe2, 0x70, 0xde, 0x5f, 0x64, 0x4b, 0x29, 0x13, 0x45, 0xb3,
-	0xcc, 0xfa, 0xcf, 0xc2, 0x43, 0x44, 0x79, 0xfc, 0x43, 0x64, 0x0f, 0x74, 0x81, 0xca, 0x7e, 0x8b,
-	0x25, 0xa2, 0x73, 0x54, 0x4b, 0x58, 0xff, 0x97, 0xb9, 0xfc, 0x06, 0x1a, 0xbf, 0xfa, 0xa2, 0xb1,
-	0x2b, 0xde, 0x08, 0xea, 0xf2, 0x6c, 0x72, 0x44, 0xb1, 0x4d, 0xee, 0xc2, 0xda, 0x84, 0x25, 0x6e,
-	0xdf, 0x4d, 0x5c, 0xb1, 0x19, 0xbc, 0x74, 0xaf, 0x42, 0xb9, 0x41, 0xe9, 0xa4, 0xd7, 0xc0, 0x0e,
-	0xf9, 0xfb, 0xa7, 0x00, 0x00, 0x00, 0xff, 0xff, 0x18, 0x21, 0x64, 0x8c, 0x91, 0x09, 0x00, 0x00,
+	// 1130 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0xdd, 0x6e, 0xe3, 0xc4,
+	0x17, 0xcf, 0xc4, 0x4e, 0x52, 0x9f, 0xa4, 0xdd, 0xee, 0x6c, 0xff, 0x92, 0xff, 0xd1, 0x16, 0x8a,
+	0x57, 0xec, 0x76, 0x05, 0xa4, 0xa8, 0x54, 0xa2, 0x5a, 0x21, 0xc1, 0x6e, 0x5b, 0x08, 0xaa, 0xb6,
+	0xb4, 0x23, 0xb4, 0x70, 0x67, 0x4d, 0x9c, 0x49, 0x6c, 0x35, 0xb1, 0x5d, 0x7b, 0xd2, 0x6e, 0x9e,
+	0x00, 0x6e, 0x78, 0x0a, 0x1e, 0x84, 0x3b, 0x6e, 0xb8, 0xe6, 0x8a, 0x5b, 0x1e, 0x04, 0xcd, 0x87,
+	0xe3, 0x8f, 0x64, 0xdb, 0x5e, 0xc1, 0x9d, 0xcf, 0x6f, 0x7e, 0x67, 0x7c, 0xce, 0x99, 0xf3, 0x05,
+	0x1b, 0x53, 0x96, 0xa6, 0x74, 0xcc, 0xd2, 0x5e, 0x9c, 0x44, 0x3c, 0xc2, 0x66, 0x3c, 0x18, 0xf1,
+	0xee, 0xfb, 0xe3, 0x28, 0x1a, 0x4f, 0xd8, 0x9e, 0xc4, 0x06, 0xb3, 0xd1, 0x1e, 0x0f, 0xa6, 0x2c,
+	0xe5, 0x74, 0x1a, 0x2b, 0x9a, 0xf3, 0x77, 0x03, 0x5a, 0x5a, 0x13, 0xbf, 0x80, 0xf5, 0x84, 0x5d,
+	0xcd, 0x58, 0xca, 0xdd, 0x01, 0xe5, 0x9e, 0x6f, 0xa3, 0x1d, 0xb4, 0xdb, 0xde, 0x7f, 0xd4, 0x13,
+	0x57, 0xf5, 0x4a, 0x47, 0xfd, 0x1a, 0xe9, 0x68, 0xe0, 0x95, 0x90, 0xf1, 0x01, 0xb4, 0xe3, 0x84,
+	0xb9, 0x71, 0xc2, 0x62, 0x9a, 0x30, 0xbb, 0x2e, 0x35, 0x1f, 0x2a, 0xcd, 0xc2, 0x41, 0xbf, 0x46,
+	0x20, 0x4e, 0xd8, 0xb9, 0x92, 0xf0, 0x73, 0x68, 0x65, 0x1a, 0x86, 0xd4, 0x58, 0x5f, 0x68, 0x68,
+	0x76, 0x76, 0x8e, 0x9f, 0x42, 0xd3, 0x8b, 0xa6, 0xd3, 0x80, 0xdb, 0xa6, 0x64, 0x76, 0x14, 0x53,
+	0x61, 0xfd, 0x1a, 0xd1, 0xa7, 0x78, 0x1f, 0xc0, 0xf3, 0x99, 0x77, 0x19, 0x47, 0x41, 0xc8, 0xed,
+	0x86, 0xe4, 0x6e, 0x6a, 0xee, 0x02, 0x17, 0x66, 0xe4, 0x92, 0x30, 0xfe, 0x3a, 0x60, 0x37, 0xae,
+	0xe7, 0xd3, 0x70, 0xcc, 0xec, 0x66, 0xd1, 0xf8, 0xc2, 0x81, 0xd0, 0x12, 0xe2, 0x91, 0x94, 0xf0,
+	0x47, 0xb0, 0x16, 0xb2, 0x1b, 0x57, 0x20, 0x76, 0x4b, 0xaa, 0x6c, 0x28, 0x95, 0x0c, 0x15, 0xe6,
+	0x87, 0xec, 0xe6, 0x4d, 0xc0, 0x6e, 0xf0, 0x29, 0x3c, 0x1a, 0x31, 0xee, 0xf9, 0x6e, 0x39, 0xc2,
+	0x6b, 0x52, 0xef, 0xff, 0x4a, 0x6f, 0x05, 0xa1, 0x5f, 0x23, 0x0f, 0x25, 0x4c, 0x8a, 0xc1, 0xfe,
+	0x06, 0xb6, 0x12, 0xc6, 0x67, 0x49, 0x58, 0xb9, 0xcd, 0xba, 0xed, 0xbd, 0xb0, 0x52, 0x21, 0x95,
+	0x57, 0x4b, 0x59, 0x9a, 0x06, 0x51, 0xe8, 0x5e, 0xb2, 0xb9, 0x0d, 0x45, 0xc7, 0x0b, 0x07, 0xc2,
+	0x71, 0x2d, 0x9e, 0xb2, 0x39, 0x7e, 0x0c, 0x56, 0x1a, 0x8c, 0x43, 0xca, 0x67, 0x09, 0xb3, 0xdb,
+	0x3b, 0x68, 0xb7, 0x43, 0x72, 0x00, 0x7f, 0x0d, 0xeb, 0x74, 0xc6, 0x7d, 0x16, 0xf2, 0xc0, 0xa3,
+	0x3c, 0x4a, 0xec, 0xce, 0x8e, 0xb1, 0xdb, 0xde, 0xdf, 0x51, 0xb7, 0xea, 0x5c, 0xeb, 0xbd, 0x2c,
+	0x52, 0x4e, 0x42, 0x9e, 0xcc, 0x49, 0x59, 0xad, 0xfb, 0x15, 0xe0, 0x65, 0x12, 0xde, 0x04, 0x43,
+	0x58, 0x2a, 0x32, 0xd3, 0x24, 0xe2, 0x13, 0x6f, 0x41, 0xe3, 0x9a, 0x4e, 0x66, 0x2a, 0xe7, 0x3a,
+	0x44, 0x09, 0x2f, 0xea, 0x87, 0xe8, 0x95, 0x05, 0xad, 0x98, 0xce, 0x27, 0x11, 0x1d, 0x3a, 0x7f,
+	0x21, 0x68, 0xe9, 0x80, 0xe0, 0x43, 0xb0, 0x16, 0x55, 0xa0, 0x53, 0xbc, 0xdb, 0x53, 0x75, 0xd2,
+	0xcb, 0xea, 0xa4, 0xf7, 0x7d, 0xc6, 0x20, 0x39, 0x19, 0xdb, 0x8b, 0x0b, 0xf5, 0xcf, 0x32, 0x11,
+	0x6f, 0x03, 0x24, 0x2c, 0x9e, 0x04, 0x1e, 0x75, 0x83, 0xa1, 0xcc, 0x65, 0x93, 0x58, 0x1a, 0xf9,
+	0x76, 0x58, 0x8e, 0x98, 0x59, 0x8d, 0xd8, 0x97, 0xf0, 0x20, 0x61, 0x5e, 0x14, 0x8e, 0x82, 0xf1,
+	0x2c, 0xa1, 0x3c, 0x88, 0x42, 0x9d, 0xb7, 0xff, 0xcb, 0x5e, 0xb2, 0x74, 0x48, 0xaa, 0x6c, 0xe7,
+	0x77, 0x54, 0xaa, 0x3e, 0x8c, 0xc1, 0x94, 0x59, 0xa9, 0xa2, 0x24, 0xbf, 0xf1, 0x33, 0x78, 0x90,
+	0x8a, 0x00, 0x84, 0x1e, 0x73, 0xc3, 0xd9, 0x74, 0xc0, 0x12, 0xe9, 0x83, 0x49, 0x36, 0x32, 0xf8,
+	0x4c, 0xa2, 0xf8, 0x03, 0xe8, 0xc8, 0x94, 0x71, 0x87, 0xc1, 0x98, 0xa5, 0x5c, 0x3a, 0x63, 0x91,
+	0xb6, 0xc4, 0x8e, 0x25, 0x84, 0x0f, 0xab, 0x8d, 0xc2, 0x7c, 0x67, 0xe2, 0x55, 0xda, 0x44, 0x39,
+	0x4e, 0x8d, 0x4a, 0x9c, 0x9c, 0x9f, 0x11, 0xb4, 0xfe, 0x2d, 0x27, 0xca, 0xa6, 0x98, 0x55, 0x53,
+	0x7e, 0x42, 0x59, 0xc3, 0xf9, 0xaf, 0x2d, 0x39, 0x03, 0x18, 0x4c, 0x22, 0xef, 0xd2, 0x0d, 0xc2,
+	0x51, 0x24, 0xef, 0x93, 0x92, 0xfe, 0xab, 0x32, 0xaa, 0x2d, 0x31, 0xfd, 0xcb, 0xed, 0x4c, 0xc1,
+	0xa7, 0xa9, 0xaf, 0x33, 0xd5, 0x92, 0x48, 0x9f, 0xa6, 0xbe, 0x33, 0x2c, 0x76, 0xc8, 0x55, 0x8e,
+	0xa0, 0x95, 0x8e, 0x94, 0xad, 0xac, 0x57, 0x53, 0x7c, 0x03, 0xea, 0x3a, 0xf3, 0x2d, 0x52, 0x0f,
+	0x86, 0xce, 0x2f, 0x46, 0xa9, 0xa9, 0xae, 0x0c, 0x62, 0x07, 0x90, 0xaf, 0x6f, 0x42, 0x3e, 0x7e,
+	0x06, 0xa6, 0x97, 0x32, 0x11, 0x21, 0x23, 0x4f, 0xa6, 0xc2, 0x15, 0xbd, 0x23, 0x22, 0x09, 0x78,
+	0x17, 0xcc, 0x58, 0x10, 0x4d, 0x49, 0xdc, 0x5a, 0x26, 0x9e, 0x5f, 0x10, 0x33, 0xd6, 0xcc, 0x2b,
+	0xc1, 0x6c, 0xdc, 0xc6, 0x14, 0x8c, 0x8a, 0x77, 0xcd, 0x5b, 0x0b, 0xb8, 0x55, 0x29, 0xe0, 0xee,
+	0x17, 0x80, 0x8e, 0xee, 0x1f, 0xc8, 0x4a, 0xa4, 0xba, 0x43, 0xa8, 0x9f, 0x5f, 0xdc, 0x5f, 0xbd,
+	0x9a, 0x50, 0xf5, 0xe5, 0x84, 0xca, 0x62, 0x6d, 0xe4, 0xb1, 0x76, 0xf6, 0xa0, 0x71, 0x7e, 0x21,
+	0x3c, 0x7d, 0x0a, 0x86, 0x08, 0x09, 0xba, 0x25, 0x24, 0x82, 0xe0, 0xfc, 0x81, 0xf2, 0xf9, 0xb6,
+	0xf2, 0xf5, 0x3e, 0x04, 0xf3, 0x5a, 0xdc, 0x54, 0xdf, 0x31, 0xf2, 0xa9, 0x51, 0xb8, 0x89, 0xc8,
+	0x63, 0xfc, 0x31, 0x98, 0x6f, 0xf3, 0x67, 0xb5, 0xcb, 0x23, 0xb2, 0xf7, 0x63, 0xca, 0xb8, 0x6a,
+	0xff, 0xe6, 0xdb, 0xe5, 0x77, 0xa8, 0xd6, 0x42, 0xf7, 0x73, 0xb0, 0x16, 0x1a, 0x77, 0xcd, 0x02,
+	0xab, 0x30, 0x0b, 0x9c, 0x1f, 0x56, 0xce, 0xdf, 0xa5, 0x60, 0xa2, 0xbb, 0xaa, 0xb3, 0x9a, 0xf7,
+	0x0e, 0x2d, 0x8d, 0xd0, 0x0a, 0x1b, 0x55, 0xf3, 0x68, 0x1b, 0x20, 0x9e, 0x0d, 0x26, 0x81, 0x27,
+	0xc8, 0x59, 0x69, 0x2a, 0xe4, 0x54, 0xd9, 0x2f, 0xb8, 0x73, 0xf9, 0x72, 0x6b, 0x44, 0x09, 0xce,
+	0x41, 0xa5, 0xdd, 0xe2, 0x27, 0xd0, 0xc8, 0x16, 0x34, 0x23, 0x5f, 0x9a, 0x34, 0x87, 0xa8, 0x33,
+	0xe7, 0x4f, 0x04, 0xeb, 0xca, 0xb7, 0x6c, 0xbf, 0x7b, 0xbe, 0x98, 0x81, 0x7a, 0xec, 0x95, 0x15,
+	0xc5, 0xba, 0xa2, 0x3f, 0x97, 0x57, 0xc1, 0xfa, 0xfd, 0x57, 0xc1, 0x27, 0xd0, 0x11, 0xac, 0xec,
+	0xb7, 0xd2, 0x97, 0x4e, 0xbf, 0x46, 0xda, 0x02, 0x7d, 0xad, 0x6d, 0xf9, 0x04, 0x2c, 0x2f, 0x9a,
+	0xc6, 0x13, 0x2a, 0xb6, 0x34, 0x73, 0xb5, 0x35, 0x39, 0xa3, 0x38, 0xca, 0x7f, 0x43, 0x4b, 0xe3,
+	0x12, 0x1f, 0x40, 0x93, 0x7a, 0xe2, 0x4b, 0x3a, 0xb6, 0xb1, 0xff, 0x78, 0xe5, 0xe0, 0xec, 0x29,
+	0x0e, 0xd1, 0xdc, 0xbb, 0x3a, 0x9a, 0x9d, 0x87, 0xcb, 0x50, 0xd3, 0x5e, 0x8b, 0xf8, 0x3d, 0x80,
+	0x45, 0xf1, 0xa7, 0xb2, 0x0d, 0x75, 0x48, 0x01, 0x71, 0xb6, 0x33, 0x73, 0x70, 0x0b, 0x8c, 0x97,
+	0xc7, 0xc7, 0x9b, 0x35, 0x0c, 0xd0, 0x24, 0x27, 0xaf, 0xbf, 0x7b, 0x73, 0xb2, 0x89, 0x9c, 0x5f,
+	0x11, 0xc0, 0x94, 0x89, 0x62, 0x4e, 0xfd, 0x20, 0xc6, 0x5d, 0x58, 0xd3, 0x3f, 0x4d, 0xe5, 0x83,
+	0x9a, 0x64, 0x21, 0x8b, 0x0e, 0x39, 0xca, 0x3a, 0xe4, 0x08, 0xef, 0x41, 0x2b, 0x66, 0xe1, 0x30,
+	0x08, 0xc7, 0xba, 0x9a, 0xde, 0xb1, 0x20, 0x64, 0x2c, 0x61, 0xa8, 0x30, 0xe4, 0x5a, 0xc2, 0xba,
+	0x9a, 0x0a, 0x88, 0xf8, 0x75, 0xd6, 0x5d, 0xf4, 0x30, 0x5e, 0xc8, 0x0e, 0x81, 0xb5, 0x29, 0xe3,
+	0x74, 0x48, 0x39, 0x15, 0x79, 0x99, 0xb2, 0xab, 0xb3, 0x48, 0x27, 0xb4, 0x12, 0xf0, 0xa7, 0x45,
+	0x37, 0xec, 0x7a, 0x71, 0xd5, 0xce, 0x71, 0x52, 0xe0, 0x0c, 0x9a, 0x72, 0xbf, 0xfa, 0xec, 0x9f,
+	0x01, 0x00, 0xde, 0x27, 0x0e, 0x60, 0xad, 0x0c, 0x00, 0x00,
 }
@@ -198,22 +198,25 @@ type omniProto struct {
 	InvalidateStateImpl        func()
 
 	// Inner Stack methods
-	broadcastImpl       func(msgPayload []byte)
-	unicastImpl         func(msgPayload []byte, receiverID uint64) (err error)
-	executeImpl         func(seqNo uint64, reqBatch *RequestBatch)
-	getStateImpl        func() []byte
-	skipToImpl          func(seqNo uint64, snapshotID []byte, peers []uint64)
-	viewChangeImpl      func(curView uint64)
-	signImpl            func(msg []byte) ([]byte, error)
-	verifyImpl          func(senderID uint64, signature []byte, message []byte) error
-	getLastSeqNoImpl    func() (uint64, error)
-	validateStateImpl   func()
-	invalidateStateImpl func()
+	broadcastImpl         func(msgPayload []byte)
+	unicastImpl           func(msgPayload []byte, receiverID uint64) (err error)
+	executeImpl           func(seqNo uint64, reqBatch *RequestBatch)
+	getStateImpl          func() []byte
+	skipToImpl            func(seqNo uint64, snapshotID []byte, peers []uint64)
+	viewChangeImpl        func(curView uint64)
+	signImpl              func(msg []byte) ([]byte, error)
+	verifyImpl            func(senderID uint64, signature []byte, message []byte) error
+	getLastSeqNoImpl      func() (uint64, error)
+	getLastMembershipImpl func() (*Membership, error)
+	membershipChangedImpl func(replicas []uint64, f int)
+	validateStateImpl     func()
+	invalidateStateImpl   func()
 
 	// Closable Consenter methods
-	RecvMsgImpl func(ocMsg *pb.Message, senderHandle *pb.PeerID) error
-	CloseImpl   func()
-	deliverImpl func([]byte, *pb.PeerID)
+	RecvMsgImpl   func(ocMsg *pb.Message, senderHandle *pb.PeerID) error
+	GetStatusImpl func() (*pb.ConsensusStatus, error)
+	CloseImpl     func()
+	deliverImpl   func([]byte, *pb.PeerID)
 
 	// Orderer methods
 	ValidateImpl func(seqNo uint64, id []byte) (commit bool, correctedID []byte, peerIDs []*pb.PeerID)
@@ -472,6 +475,14 @@ func (op *omniProto) RecvMsg(ocMsg *pb.Message, senderHandle *pb.PeerID) error {
 	panic("Unimplemented")
 }
 
+func (op *omniProto) GetStatus() (*pb.ConsensusStatus, error) {
+	if nil != op.GetStatusImpl {
+		return op.GetStatusImpl()
+	}
+
+	panic("Unimplemented")
+}
+
 func (op *omniProto) getLastSeqNo() (uint64, error) {
 	if op.getLastSeqNoImpl != nil {
 		return op.getLastSeqNoImpl()
@@ -480,6 +491,20 @@ func (op *omniProto) getLastSeqNo() (uint64, error) {
 	return 0, fmt.Errorf("getLastSeqNo is not implemented")
 }
 
+func (op *omniProto) getLastMembership() (*Membership, error) {
+	if op.getLastMembershipImpl != nil {
+		return op.getLastMembershipImpl()
+	}
+
+	return nil, fmt.Errorf("getLastMembership is not implemented")
+}
+
+func (op *omniProto) membershipChanged(replicas []uint64, f int) {
+	if nil != op.membershipChangedImpl {
+		op.membershipChangedImpl(replicas, f)
+	}
+}
+
 func (op *omniProto) Close() {
 	if nil != op.CloseImpl {
 		op.CloseImpl()
@@ -613,3 +638,24 @@ func (op *omniProto) Execute(tag interface{}, txs []*pb.Transaction) {
 // These methods are a temporary hack until the consensus API can be cleaned a little
 func (op *omniProto) Start() {}
 func (op *omniProto) Halt()  {}
+
+func createPbftReconfigurationBatch(tag int64, replica uint64, action ReconfigurationAction, id uint64, sequence uint64) *RequestBatch {
+	signed := &pb.ValidatorReconfiguration{
+		Action:    pb.ValidatorReconfiguration_Action(action),
+		Validator: &pb.PeerID{Name: fmt.Sprintf("vp%d", id)},
+		Sequence:  sequence,
+	}
+	if err := signed.Sign(reconfigurationAdmin.key); err != nil {
+		panic(fmt.Sprintf("Failed to sign reconfiguration: %s", err))
+	}
+	reconfig, err := newReconfiguration(signed)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create reconfiguration: %s", err))
+	}
+	req := &Request{
+		Timestamp:       &timestamp.Timestamp{Seconds: tag, Nanos: 0},
+		ReplicaId:       replica,
+		Reconfiguration: reconfig,
+	}
+	return &RequestBatch{Batch: []*Request{req}}
+}
@@ -17,10 +17,12 @@ limitations under the License.
 package pbft
 
 import (
+	"crypto/ecdsa"
 	"encoding/base64"
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -86,7 +88,9 @@ type innerStack interface {
 	execute(seqNo uint64, reqBatch *RequestBatch) // This is invoked on a separate thread
 	getState() []byte
 	getLastSeqNo() (uint64, error)
+	getLastMembership() (*Membership, error)
 	skipTo(seqNo uint64, snapshotID []byte, peers []uint64)
+	membershipChanged(replicas []uint64, f int)
 
 	sign(msg []byte) ([]byte, error)
 	verify(senderID uint64, signature []byte, message []byte) error
@@ -123,6 +127,9 @@ type pbftCore struct {
 
 	consumer innerStack
 
+	authentication string       // how messages other than view changes are authenticated
+	sessionKeys    *sessionKeys // keys of the message authentication codes, nil unless authenticating with MACs
+
 	// PBFT data
 	activeView    bool              // view change happening
 	byzantine     bool              // whether this node is intentionally acting as Byzantine; useful for debugging on the testnet
@@ -135,6 +142,7 @@ type pbftCore struct {
 	L             uint64            // log size
 	lastExec      uint64            // last request we executed
 	replicaCount  int               // number of replicas; PBFT `|R|`
+	replicas      []uint64          // IDs of the replicas, in the order in which they become primary
 	seqNo         uint64            // PBFT "n", strictly monotonic increasing sequence number
 	view          uint64            // current view
 	chkpts        map[uint64]string // state checkpoints; map lastExec to global hash
@@ -146,6 +154,16 @@ type pbftCore struct {
 	highStateTarget   *stateUpdateTarget // Set to the highest weak checkpoint cert we have observed
 	hChkpts           map[uint64]uint64  // highest checkpoint sequence number observed for each replica
 
+	pending     []*Reconfiguration  // reconfigurations which were executed but are not applied yet
+	activation  uint64              // checkpoint at which the pending reconfigurations are applied
+	deferred    []interface{}       // messages for sequence numbers after activation, replayed once applied
+	deferredIDs map[deferredID]bool // messages held back, a replica's duplicates are dropped
+	reconfigSeq uint64              // sequence of the last reconfiguration recorded
+	chainID     string              // chain whose validators the reconfigurations change
+
+	admins         []*ecdsa.PublicKey // administrators who sign reconfigurations
+	adminThreshold int                // number of administrators who must sign a reconfiguration
+
 	currentExec           *uint64                  // currently executing request
 	timerActive           bool                     // is the timer running?
 	vcResendTimer         events.Timer             // timer triggering resend of a view change
@@ -261,13 +279,34 @@ func newPbftCore(id uint64, config *viper.Viper, consumer innerStack, etf events
 		panic(fmt.Errorf("Cannot parse new broadcast timeout: %s", err))
 	}
 
+	instance.admins, instance.adminThreshold, err = loadAdmins(config)
+	if err != nil {
+		panic(fmt.Errorf("Cannot load reconfiguration administrators: %s", err))
+	}
+
+	switch instance.authentication = strings.ToLower(config.GetString("general.authentication")); instance.authentication {
+	case "":
+		instance.authentication = authNone
+	case authNone, authSignatures:
+	case authMACs:
+		if instance.sessionKeys, err = newSessionKeys(); err != nil {
+			panic(fmt.Errorf("Cannot generate session key: %s", err))
+		}
+	default:
+		panic(fmt.Errorf("Unknown authentication mode %s", instance.authentication))
+	}
+
 	instance.activeView = true
 	instance.replicaCount = instance.N
+	for i := 0; i < instance.N; i++ {
+		instance.replicas = append(instance.replicas, uint64(i))
+	}
 
 	logger.Infof("PBFT type = %T", instance.consumer)
 	logger.Infof("PBFT Max number of validating peers (N) = %v", instance.N)
 	logger.Infof("PBFT Max number of failing peers (f) = %v", instance.f)
 	logger.Infof("PBFT byzantine flag = %v", instance.byzantine)
+	logger.Infof("PBFT authentication = %s", instance.authentication)
 	logger.Infof("PBFT request timeout = %v", instance.requestTimeout)
 	logger.Infof("PBFT view change timeout = %v", instance.newViewTimeout)
 	logger.Infof("PBFT Checkpoint period (K) = %v", instance.K)
@@ -306,6 +345,11 @@ func newPbftCore(id uint64, config *viper.Viper, consumer innerStack, etf events
 
 	instance.restoreState()
 
+	if !instance.isMember(instance.id) {
+		logger.Infof("Replica %d is not a member of %v, it will catch up with the network through state transfer", instance.id, instance.replicas)
+		instance.stateTransfer(nil)
+	}
+
 	instance.viewChangeSeqNo = ^uint64(0) // infinity
 	instance.updateViewChangeSeqNo()
 
@@ -336,6 +380,10 @@ func (instance *pbftCore) ProcessEvent(e events.Event) events.Event {
 		if err != nil {
 			break
 		}
+		if err = instance.checkAuthentication(msg.msg, msg.sender); err != nil {
+			logger.Warningf("Replica %d received a message from replica %d which does not authenticate: %s", instance.id, msg.sender, err)
+			break
+		}
 		return next
 	case *RequestBatch:
 		err = instance.recvRequestBatch(et)
@@ -353,6 +401,8 @@ func (instance *pbftCore) ProcessEvent(e events.Event) events.Event {
 		return instance.recvNewView(et)
 	case *FetchRequestBatch:
 		err = instance.recvFetchRequestBatch(et)
+	case *SessionKey:
+		err = instance.recvSessionKey(et)
 	case returnRequestBatchEvent:
 		return instance.recvReturnRequestBatch(et)
 	case stateUpdatedEvent:
@@ -377,6 +427,8 @@ func (instance *pbftCore) ProcessEvent(e events.Event) events.Event {
 		}
 		logger.Infof("Replica %d application caught up via state transfer, lastExec now %d", instance.id, update.seqNo)
 		instance.lastExec = update.seqNo
+		// Adopt the membership of the ledger, any pending reconfiguration is applied when moving the watermarks
+		instance.restoreMembership()
 		instance.moveWatermarks(instance.lastExec) // The watermark movement handles moving this to a checkpoint boundary
 		instance.skipInProgress = false
 		instance.consumer.validateState()
@@ -426,7 +478,7 @@ func (instance *pbftCore) ProcessEvent(e events.Event) events.Event {
 
 // Given a certain view n, what is the expected primary?
 func (instance *pbftCore) primary(n uint64) uint64 {
-	return n % uint64(instance.replicaCount)
+	return instance.replicas[n%uint64(instance.replicaCount)]
 }
 
 // Is the sequence number between watermarks?
@@ -547,7 +599,7 @@ func (instance *pbftCore) committed(digest string, v uint64, n uint64) bool {
 // =============================================================================
 
 func (instance *pbftCore) nullRequestHandler() {
-	if !instance.activeView {
+	if !instance.activeView || !instance.isMember(instance.id) {
 		return
 	}
 
@@ -604,6 +656,11 @@ func (instance *pbftCore) recvMsg(msg *Message, senderID uint64) (interface{}, e
 	} else if reqBatch := msg.GetReturnRequestBatch(); reqBatch != nil {
 		// it's ok for sender ID and replica ID to differ; we're sending the original request message
 		return returnRequestBatchEvent(reqBatch), nil
+	} else if sk := msg.GetSessionKey(); sk != nil {
+		if senderID != sk.ReplicaId {
+			return nil, fmt.Errorf("Sender ID included in session-key message (%v) doesn't match ID corresponding to the receiving stream (%v)", sk.ReplicaId, senderID)
+		}
+		return sk, nil
 	}
 	return nil, fmt.Errorf("Invalid message: %v", msg)
 }
@@ -651,6 +708,11 @@ func (instance *pbftCore) sendPrePrepare(reqBatch *RequestBatch, digest string)
 		return
 	}
 
+	if len(instance.pending) > 0 && n > instance.activation {
+		logger.Infof("Primary %d waiting for the reconfiguration at checkpoint %d, not sending pre-prepare with seqNo=%d", instance.id, instance.activation, n)
+		return
+	}
+
 	logger.Debugf("Primary %d broadcasting pre-prepare for view=%d/seqNo=%d and digest %s", instance.id, instance.view, n, digest)
 	instance.seqNo = n
 	preprep := &PrePrepare{
@@ -707,6 +769,10 @@ func (instance *pbftCore) recvPrePrepare(preprep *PrePrepare) error {
 		return nil
 	}
 
+	if instance.deferBeyondActivation(preprep.SequenceNumber, preprep) {
+		return nil
+	}
+
 	if instance.primary(instance.view) != preprep.ReplicaId {
 		logger.Warningf("Pre-prepare from other than primary: got %d, should be %d", preprep.ReplicaId, instance.primary(instance.view))
 		return nil
@@ -776,6 +842,15 @@ func (instance *pbftCore) recvPrepare(prep *Prepare) error {
 	logger.Debugf("Replica %d received prepare from replica %d for view=%d/seqNo=%d",
 		instance.id, prep.ReplicaId, prep.View, prep.SequenceNumber)
 
+	if instance.deferBeyondActivation(prep.SequenceNumber, prep) {
+		return nil
+	}
+
+	if !instance.isMember(prep.ReplicaId) {
+		logger.Warningf("Replica %d received prepare from replica %d which is not a member, ignoring", instance.id, prep.ReplicaId)
+		return nil
+	}
+
 	if instance.primary(prep.View) == prep.ReplicaId {
 		logger.Warningf("Replica %d received prepare from primary, ignoring", instance.id)
 		return nil
@@ -819,7 +894,7 @@ func (instance *pbftCore) maybeSendCommit(digest string, v uint64, n uint64) err
 		}
 		cert.sentCommit = true
 		instance.recvCommit(commit)
-		return instance.innerBroadcast(&Message{&Message_Commit{commit}})
+		return instance.innerBroadcast(&Message{Payload: &Message_Commit{Commit: commit}})
 	}
 	return nil
 }
@@ -828,6 +903,15 @@ func (instance *pbftCore) recvCommit(commit *Commit) error {
 	logger.Debugf("Replica %d received commit from replica %d for view=%d/seqNo=%d",
 		instance.id, commit.ReplicaId, commit.View, commit.SequenceNumber)
 
+	if instance.deferBeyondActivation(commit.SequenceNumber, commit) {
+		return nil
+	}
+
+	if !instance.isMember(commit.ReplicaId) {
+		logger.Warningf("Replica %d received commit from replica %d which is not a member, ignoring", instance.id, commit.ReplicaId)
+		return nil
+	}
+
 	if !instance.inWV(commit.View, commit.SequenceNumber) {
 		if commit.SequenceNumber != instance.h && !instance.skipInProgress {
 			logger.Warningf("Replica %d ignoring commit for view=%d/seqNo=%d: not in-wv, in view %d, high water mark %d", instance.id, commit.View, commit.SequenceNumber, instance.view, instance.h)
@@ -960,6 +1044,7 @@ func (instance *pbftCore) executeOne(idx msgID) bool {
 	} else {
 		logger.Infof("Replica %d executing/committing request batch for view=%d/seqNo=%d and digest %s",
 			instance.id, idx.v, idx.n, digest)
+		instance.recordReconfigurations(idx.n, reqBatch)
 		// synchronously execute, it is the other side's responsibility to execute in the background if needed
 		instance.consumer.execute(idx.n, reqBatch)
 	}
@@ -1053,6 +1138,8 @@ func (instance *pbftCore) moveWatermarks(n uint64) {
 	logger.Debugf("Replica %d updated low watermark to %d",
 		instance.id, instance.h)
 
+	instance.applyReconfigurations()
+
 	instance.resubmitRequestBatches()
 }
 
@@ -1144,6 +1231,15 @@ func (instance *pbftCore) recvCheckpoint(chkpt *Checkpoint) events.Event {
 	logger.Debugf("Replica %d received checkpoint from replica %d, seqNo %d, digest %s",
 		instance.id, chkpt.ReplicaId, chkpt.SequenceNumber, chkpt.Id)
 
+	if instance.deferBeyondActivation(chkpt.SequenceNumber, chkpt) {
+		return nil
+	}
+
+	if !instance.isMember(chkpt.ReplicaId) {
+		logger.Warningf("Replica %d received checkpoint from replica %d which is not a member, ignoring", instance.id, chkpt.ReplicaId)
+		return nil
+	}
+
 	if instance.weakCheckpointSetOutOfRange(chkpt) {
 		return nil
 	}
@@ -1284,6 +1380,14 @@ func (instance *pbftCore) recvReturnRequestBatch(reqBatch *RequestBatch) events.
 // Marshals a Message and hands it to the Stack. If toSelf is true,
 // the message is also dispatched to the local instance's RecvMsgSync.
 func (instance *pbftCore) innerBroadcast(msg *Message) error {
+	if !instance.isMember(instance.id) {
+		logger.Debugf("Replica %d is not a member, not broadcasting", instance.id)
+		return nil
+	}
+
+	if err := instance.authenticate(msg); err != nil {
+		return fmt.Errorf("Cannot authenticate message %s", err)
+	}
 	msgRaw, err := proto.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("Cannot marshal message %s", err)
@@ -1302,11 +1406,11 @@ func (instance *pbftCore) innerBroadcast(msg *Message) error {
 	if doByzantine {
 		rand2 := rand.New(rand.NewSource(time.Now().UnixNano()))
 		ignoreidx := rand2.Intn(instance.N)
-		for i := 0; i < instance.N; i++ {
-			if i != ignoreidx && uint64(i) != instance.id { //Pick a random replica and do not send message
-				instance.consumer.unicast(msgRaw, uint64(i))
+		for i, id := range instance.replicas {
+			if i != ignoreidx && id != instance.id { //Pick a random replica and do not send message
+				instance.consumer.unicast(msgRaw, id)
 			} else {
-				logger.Debugf("PBFT byzantine: not broadcasting to replica %v", i)
+				logger.Debugf("PBFT byzantine: not broadcasting to replica %v", id)
 			}
 		}
 	} else {
@@ -1350,6 +1454,10 @@ func (instance *pbftCore) startTimerIfOutstandingRequests() {
 }
 
 func (instance *pbftCore) softStartTimer(timeout time.Duration, reason string) {
+	if !instance.isMember(instance.id) {
+		// Replicas which are not members follow the network, but may not suspect the primary
+		return
+	}
 	logger.Debugf("Replica %d soft starting new view timer for %s: %s", instance.id, timeout, reason)
 	instance.newViewTimerReason = reason
 	instance.timerActive = true
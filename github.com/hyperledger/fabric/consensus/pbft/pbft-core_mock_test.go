@@ -18,6 +18,7 @@ package pbft
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/spf13/viper"
@@ -70,15 +71,39 @@ func (pe *pbftEndpoint) isBusy() bool {
 type pbftNetwork struct {
 	*testnet
 	pbftEndpoints []*pbftEndpoint
+
+	mutex       sync.Mutex
+	memberships map[uint64]*Membership // membership written along with each executed seqNo, as in the block metadata
+}
+
+func (net *pbftNetwork) recordMembership(seqNo uint64, m *Membership) {
+	net.mutex.Lock()
+	defer net.mutex.Unlock()
+	net.memberships[seqNo] = m
+}
+
+// membershipAt returns the membership a ledger at seqNo holds in its last block
+func (net *pbftNetwork) membershipAt(seqNo uint64) (m *Membership) {
+	net.mutex.Lock()
+	defer net.mutex.Unlock()
+	last := uint64(0)
+	for n, recorded := range net.memberships {
+		if n <= seqNo && n >= last {
+			m, last = recorded, n
+		}
+	}
+	return
 }
 
 type simpleConsumer struct {
-	pe            *pbftEndpoint
-	pbftNet       *pbftNetwork
-	executions    uint64
-	lastSeqNo     uint64
-	skipOccurred  bool
-	lastExecution string
+	pe             *pbftEndpoint
+	pbftNet        *pbftNetwork
+	executions     uint64
+	lastSeqNo      uint64
+	skipOccurred   bool
+	lastExecution  string
+	lastMembership *Membership
+	members        []uint64 // set when pbft changes the membership
 	mockPersist
 }
 
@@ -112,9 +137,14 @@ func (sc *simpleConsumer) viewChange(curView uint64) {
 func (sc *simpleConsumer) invalidateState() {}
 func (sc *simpleConsumer) validateState()   {}
 
+func (sc *simpleConsumer) membershipChanged(replicas []uint64, f int) {
+	sc.members = replicas
+}
+
 func (sc *simpleConsumer) skipTo(seqNo uint64, id []byte, replicas []uint64) {
 	sc.skipOccurred = true
 	sc.executions = seqNo
+	sc.lastMembership = sc.pbftNet.membershipAt(seqNo)
 	go func() {
 		sc.pe.manager.Queue() <- stateUpdatedEvent{
 			chkpt: &checkpointMessage{
@@ -128,6 +158,8 @@ func (sc *simpleConsumer) skipTo(seqNo uint64, id []byte, replicas []uint64) {
 }
 
 func (sc *simpleConsumer) execute(seqNo uint64, reqBatch *RequestBatch) {
+	sc.lastMembership = sc.pe.pbft.membership()
+	sc.pbftNet.recordMembership(seqNo, sc.lastMembership)
 	for _, req := range reqBatch.GetBatch() {
 		sc.pbftNet.debugMsg("TEST: executing request\n")
 		sc.lastExecution = hash(req)
@@ -148,6 +180,10 @@ func (sc *simpleConsumer) getLastSeqNo() (uint64, error) {
 	return sc.lastSeqNo, nil
 }
 
+func (sc *simpleConsumer) getLastMembership() (*Membership, error) {
+	return sc.lastMembership, nil
+}
+
 func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 	if config == nil {
 		config = loadConfig()
@@ -155,6 +191,13 @@ func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 
 	config.Set("general.N", N)
 	config.Set("general.f", (N-1)/3)
+	return makePBFTNetworkWithEndpoints(N, config)
+}
+
+// makePBFTNetworkWithEndpoints creates a network of endpoints replicas, the
+// ones with an ID of N or more are not part of the configured membership
+func makePBFTNetworkWithEndpoints(endpoints int, config *viper.Viper) *pbftNetwork {
+	pn := &pbftNetwork{memberships: make(map[uint64]*Membership)}
 	endpointFunc := func(id uint64, net *testnet) endpoint {
 		tep := makeTestEndpoint(id, net)
 		pe := &pbftEndpoint{
@@ -163,7 +206,8 @@ func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 		}
 
 		pe.sc = &simpleConsumer{
-			pe: pe,
+			pe:      pe,
+			pbftNet: pn,
 		}
 
 		pe.pbft = newPbftCore(id, config, pe.sc, events.NewTimerFactoryImpl(pe.manager))
@@ -175,11 +219,10 @@ func makePBFTNetwork(N int, config *viper.Viper) *pbftNetwork {
 
 	}
 
-	pn := &pbftNetwork{testnet: makeTestnet(N, endpointFunc)}
+	pn.testnet = makeTestnet(endpoints, endpointFunc)
 	pn.pbftEndpoints = make([]*pbftEndpoint, len(pn.endpoints))
 	for i, ep := range pn.endpoints {
 		pn.pbftEndpoints[i] = ep.(*pbftEndpoint)
-		pn.pbftEndpoints[i].sc.pbftNet = pn
 	}
 	return pn
 }
@@ -774,6 +774,7 @@ func TestNewViewTimeout(t *testing.T) {
 func TestViewChangeUpdateSeqNo(t *testing.T) {
 	millisUntilTimeout := 400 * time.Millisecond
 	validatorCount := 4
+	config := loadConfig()
 	config.Set("general.timeout.request", "400ms")
 	config.Set("general.timeout.viewchange", "400ms")
 	net := makePBFTNetwork(validatorCount, config)
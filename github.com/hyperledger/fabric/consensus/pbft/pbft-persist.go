@@ -149,6 +149,7 @@ func (instance *pbftCore) restoreState() {
 	}
 
 	instance.restoreLastSeqNo()
+	instance.restoreMembership()
 
 	chkpts, err := instance.consumer.ReadStateSet("chkpt.")
 	if err == nil {
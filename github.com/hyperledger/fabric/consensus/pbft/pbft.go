@@ -32,24 +32,39 @@ import (
 
 const configPrefix = "CORE_PBFT"
 
-var pluginInstance consensus.Consenter // singleton service
-var config *viper.Viper
+// plugin registers PBFT under the name "pbft". Its defaults are the settings
+// added to config.yaml since its first release, so that the configurations
+// written for earlier releases remain valid.
+var plugin = &consensus.Plugin{
+	Name:       "pbft",
+	LoadConfig: loadConfig,
+	Defaults: map[string]interface{}{
+		"general.authentication":        authNone,
+		"general.replaywindow":          10000,
+		"general.admission.rate":        0,
+		"general.admission.burst":       1000,
+		"general.admission.outstanding": 0,
+		"general.admission.queue":       100000,
+	},
+}
 
 func init() {
-	config = loadConfig()
+	plugin.New = newConsenter
+	consensus.RegisterPlugin(plugin)
 }
 
 // GetPlugin returns the handle to the Consenter singleton
 func GetPlugin(c consensus.Stack) consensus.Consenter {
-	if pluginInstance == nil {
-		pluginInstance = New(c)
-	}
-	return pluginInstance
+	return plugin.Singleton(c)
 }
 
 // New creates a new Obc* instance that provides the Consenter interface.
 // Internally, it uses an opaque pbft-core instance.
 func New(stack consensus.Stack) consensus.Consenter {
+	return newConsenter(stack, plugin.Config())
+}
+
+func newConsenter(stack consensus.Stack, config *viper.Viper) consensus.Consenter {
 	handle, _, _ := stack.GetNetworkHandles()
 	id, _ := getValidatorID(handle)
 
@@ -100,8 +115,8 @@ func getValidatorID(handle *pb.PeerID) (id uint64, err error) {
 	}
 
 	err = fmt.Errorf(`For MVP, set the VP's peer.id to vpX,
-		where X is a unique integer between 0 and N-1
-		(N being the maximum number of VPs in the network`)
+		where X is a unique integer, the VPs of the genesis
+		membership being numbered from 0 to N-1`)
 	return
 }
 
@@ -157,3 +172,13 @@ func (op *obcGeneric) getLastSeqNo() (uint64, error) {
 	proto.Unmarshal(raw, meta)
 	return meta.SeqNo, nil
 }
+
+func (op *obcGeneric) getLastMembership() (*Membership, error) {
+	raw, err := op.stack.GetBlockHeadMetadata()
+	if err != nil {
+		return nil, err
+	}
+	meta := &Metadata{}
+	proto.Unmarshal(raw, meta)
+	return meta.Membership, nil
+}
@@ -19,13 +19,15 @@ package pbft
 import "container/list"
 
 type requestContainer struct {
-	key string
-	req *Request
+	key    string
+	client string
+	req    *Request
 }
 
 type orderedRequests struct {
 	order    list.List
 	presence map[string]*list.Element
+	clients  map[string]int // number of requests of each client
 }
 
 func (a *orderedRequests) Len() int {
@@ -47,8 +49,10 @@ func (a *orderedRequests) has(key string) bool {
 func (a *orderedRequests) add(request *Request) {
 	rc := a.wrapRequest(request)
 	if !a.has(rc.key) {
+		rc.client = clientOf(request)
 		e := a.order.PushBack(rc)
 		a.presence[rc.key] = e
+		a.clients[rc.client]++
 	}
 }
 
@@ -66,6 +70,10 @@ func (a *orderedRequests) remove(request *Request) bool {
 	}
 	a.order.Remove(e)
 	delete(a.presence, rc.key)
+	client := e.Value.(requestContainer).client
+	if a.clients[client]--; a.clients[client] == 0 {
+		delete(a.clients, client)
+	}
 	return true
 }
 
@@ -83,6 +91,12 @@ func (a *orderedRequests) removes(requests []*Request) bool {
 func (a *orderedRequests) empty() {
 	a.order.Init()
 	a.presence = make(map[string]*list.Element)
+	a.clients = make(map[string]int)
+}
+
+// countFrom returns the number of requests of a client
+func (a *orderedRequests) countFrom(client string) int {
+	return a.clients[client]
 }
 
 type requestStore struct {
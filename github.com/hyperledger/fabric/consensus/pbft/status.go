@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// statusTimeout bounds how long a status request waits for the main thread
+const statusTimeout = 5 * time.Second
+
+// status returns a snapshot of the state of the replica, it must be called
+// from the main thread
+func (instance *pbftCore) status() *pb.PbftStatus {
+	status := &pb.PbftStatus{
+		ReplicaId:                 instance.id,
+		View:                      instance.view,
+		Primary:                   instance.primary(instance.view),
+		ActiveView:                instance.activeView,
+		N:                         uint32(instance.N),
+		F:                         uint32(instance.f),
+		Replicas:                  append([]uint64(nil), instance.replicas...),
+		LowWatermark:              instance.h,
+		HighWatermark:             instance.h + instance.L,
+		LastExec:                  instance.lastExec,
+		SeqNo:                     instance.seqNo,
+		StateTransferInProgress:   instance.skipInProgress || instance.stateTransferring,
+		Executing:                 instance.currentExec != nil,
+		OutstandingRequestBatches: uint32(len(instance.outstandingReqBatches)),
+		PendingReconfigurations:   uint32(len(instance.pending)),
+		ReconfigurationSequence:   instance.reconfigSeq,
+	}
+	if len(instance.pending) > 0 {
+		status.ReconfigurationCheckpoint = instance.activation
+	}
+
+	for n := range instance.chkpts {
+		status.Checkpoints = append(status.Checkpoints, n)
+	}
+	sort.Sort(sortableUint64Slice(status.Checkpoints))
+
+	votes := make(map[uint64][]uint64)
+	for idx := range instance.viewChangeStore {
+		votes[idx.v] = append(votes[idx.v], idx.id)
+	}
+	var views []uint64
+	for v := range votes {
+		views = append(views, v)
+	}
+	sort.Sort(sortableUint64Slice(views))
+	for _, v := range views {
+		sort.Sort(sortableUint64Slice(votes[v]))
+		status.ViewChanges = append(status.ViewChanges, &pb.PbftStatus_ViewChangeVotes{View: v, Replicas: votes[v]})
+	}
+
+	return status
+}
+
+// status adds the requests held by the batcher to the snapshot of pbftCore,
+// it must be called from the main thread
+func (op *obcBatch) status() *pb.PbftStatus {
+	status := op.pbft.status()
+	status.OutstandingRequests = uint32(op.reqStore.outstandingRequests.Len())
+	status.PendingRequests = uint32(op.reqStore.pendingRequests.Len())
+	status.BatchedRequests = uint32(len(op.batchStore))
+	return status
+}
+
+// GetStatus returns a snapshot of the state of the replica. The snapshot is
+// taken by the main thread, between two events, so that it is consistent.
+func (op *obcBatch) GetStatus() (*pb.ConsensusStatus, error) {
+	result := make(chan *pb.PbftStatus, 1)
+	timeout := time.NewTimer(statusTimeout)
+	defer timeout.Stop()
+
+	select {
+	case op.manager.Queue() <- workEvent(func() { result <- op.status() }):
+	case <-timeout.C:
+		return nil, fmt.Errorf("Timed out queueing the status request for replica %d", op.pbft.id)
+	}
+
+	select {
+	case status := <-result:
+		return &pb.ConsensusStatus{Plugin: "pbft", Pbft: status}, nil
+	case <-timeout.C:
+		return nil, fmt.Errorf("Timed out waiting for replica %d to report its status", op.pbft.id)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pbft
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func getPbftStatus(t *testing.T, op *obcBatch) *pb.PbftStatus {
+	status, err := op.GetStatus()
+	if err != nil {
+		t.Fatalf("Replica %d did not report its status: %s", op.pbft.id, err)
+	}
+	if status.Plugin != "pbft" || status.Pbft == nil {
+		t.Fatalf("Replica %d reported an unexpected status %v", op.pbft.id, status)
+	}
+	return status.Pbft
+}
+
+func TestStatus(t *testing.T) {
+	validatorCount := 4
+	net := makeConsumerNetwork(validatorCount, obcBatchHelper, func(ce *consumerEndpoint) {
+		ce.consumer.(*obcBatch).batchSize = 2
+	})
+	defer net.stop()
+
+	primary := net.endpoints[0].(*consumerEndpoint).consumer.(*obcBatch)
+	broadcaster := net.endpoints[generateBroadcaster(validatorCount)].getHandle()
+
+	// The request waits in the batch of the primary until a second one arrives
+	primary.RecvMsg(createTxMsg(1), broadcaster)
+	status := getPbftStatus(t, primary)
+	if status.OutstandingRequests != 1 || status.BatchedRequests != 1 || status.PendingRequests != 1 {
+		t.Errorf("Primary should have one outstanding request in its batch, got %v", status)
+	}
+
+	primary.RecvMsg(createTxMsg(2), broadcaster)
+	net.process()
+
+	for _, ep := range net.endpoints {
+		op := ep.(*consumerEndpoint).consumer.(*obcBatch)
+		status := getPbftStatus(t, op)
+		expected := &pb.PbftStatus{
+			ReplicaId:     op.pbft.id,
+			ActiveView:    true,
+			N:             4,
+			F:             1,
+			Replicas:      []uint64{0, 1, 2, 3},
+			HighWatermark: op.pbft.L,
+			LastExec:      1,
+			Checkpoints:   []uint64{0},
+		}
+		if op == primary {
+			expected.SeqNo = 1 // Only the primary assigns sequence numbers
+		}
+		if !reflect.DeepEqual(status, expected) {
+			t.Errorf("Replica %d reported status %v, expected %v", op.pbft.id, status, expected)
+		}
+	}
+
+	// A single replica asking for a view change does not move the others
+	backup := net.endpoints[1].(*consumerEndpoint).consumer.(*obcBatch)
+	backup.manager.Queue() <- workEvent(func() {
+		backup.pbft.sendViewChange()
+	})
+	net.process()
+
+	status = getPbftStatus(t, backup)
+	if status.ActiveView || status.View != 1 || status.Primary != 1 {
+		t.Errorf("Replica 1 should be changing to view 1 with primary 1, got %v", status)
+	}
+	status = getPbftStatus(t, net.endpoints[2].(*consumerEndpoint).consumer.(*obcBatch))
+	votes := []*pb.PbftStatus_ViewChangeVotes{{View: 1, Replicas: []uint64{1}}}
+	if !status.ActiveView || status.View != 0 || !reflect.DeepEqual(status.ViewChanges, votes) {
+		t.Errorf("Replica 2 should be in view 0 with a pending view change from replica 1, got %v", status)
+	}
+}
@@ -197,6 +197,11 @@ func (instance *pbftCore) recvViewChange(vc *ViewChange) events.Event {
 		return nil
 	}
 
+	if !instance.isMember(vc.ReplicaId) {
+		logger.Warningf("Replica %d found view-change message from replica %d which is not a member", instance.id, vc.ReplicaId)
+		return nil
+	}
+
 	if !instance.correctViewChange(vc) {
 		logger.Warningf("Replica %d found view-change message incorrect", instance.id)
 		return nil
@@ -0,0 +1,201 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/consensus"
+)
+
+const (
+	entryPrefix    = "raft.entry."
+	snapshotKey    = "raft.snapshot"
+	hardStateKey   = "raft.hardState"
+	entryKeyFormat = entryPrefix + "%020d"
+)
+
+// raftLog holds the entries which follow the last snapshot, and persists
+// every change through the StatePersistor so that it survives a crash
+type raftLog struct {
+	persistor consensus.StatePersistor
+	snapshot  *Snapshot
+	entries   []*Entry // entries[i].Index == snapshot.Index + 1 + i
+}
+
+// newRaftLog restores the snapshot and the entries which follow it
+func newRaftLog(persistor consensus.StatePersistor) *raftLog {
+	l := &raftLog{persistor: persistor, snapshot: &Snapshot{}}
+
+	if raw, err := persistor.ReadState(snapshotKey); err == nil && raw != nil {
+		snap := &Snapshot{}
+		if err = proto.Unmarshal(raw, snap); err != nil {
+			logger.Errorf("Could not unmarshal snapshot - local state is damaged: %s", err)
+		} else {
+			l.snapshot = snap
+		}
+	}
+
+	raws, err := persistor.ReadStateSet(entryPrefix)
+	if err != nil {
+		logger.Warningf("Could not restore log entries: %s", err)
+		return l
+	}
+	var indexes []uint64
+	restored := make(map[uint64]*Entry)
+	for key, raw := range raws {
+		index, err := strconv.ParseUint(strings.TrimPrefix(key, entryPrefix), 10, 64)
+		if err != nil {
+			logger.Warningf("Ignoring unexpected log key %s", key)
+			continue
+		}
+		entry := &Entry{}
+		if err = proto.Unmarshal(raw, entry); err != nil || entry.Index != index {
+			logger.Errorf("Could not unmarshal log entry %d - local state is damaged", index)
+			continue
+		}
+		indexes = append(indexes, index)
+		restored[index] = entry
+	}
+	sort.Sort(uint64Slice(indexes))
+
+	for _, index := range indexes {
+		if index == l.lastIndex()+1 {
+			l.entries = append(l.entries, restored[index])
+		} else {
+			// Compacted, or left behind by a crash in the middle of a
+			// truncation, these are not part of the log
+			persistor.DelState(fmt.Sprintf(entryKeyFormat, index))
+		}
+	}
+	return l
+}
+
+// firstIndex is the index of the first entry which is not in the snapshot
+func (l *raftLog) firstIndex() uint64 {
+	return l.snapshot.Index + 1
+}
+
+func (l *raftLog) lastIndex() uint64 {
+	return l.snapshot.Index + uint64(len(l.entries))
+}
+
+func (l *raftLog) lastTerm() uint64 {
+	if len(l.entries) == 0 {
+		return l.snapshot.Term
+	}
+	return l.entries[len(l.entries)-1].Term
+}
+
+// term returns the term of the entry at index, ok is false if the entry was
+// compacted or does not exist yet
+func (l *raftLog) term(index uint64) (term uint64, ok bool) {
+	if index == l.snapshot.Index {
+		return l.snapshot.Term, true
+	}
+	if entry := l.entry(index); entry != nil {
+		return entry.Term, true
+	}
+	return 0, false
+}
+
+// entry returns the entry at index, nil if it was compacted or does not exist yet
+func (l *raftLog) entry(index uint64) *Entry {
+	if index < l.firstIndex() || index > l.lastIndex() {
+		return nil
+	}
+	return l.entries[index-l.firstIndex()]
+}
+
+// slice returns at most max entries starting at index from
+func (l *raftLog) slice(from uint64, max int) []*Entry {
+	if from < l.firstIndex() || from > l.lastIndex() {
+		return nil
+	}
+	entries := l.entries[from-l.firstIndex():]
+	if len(entries) > max {
+		entries = entries[:max]
+	}
+	return entries
+}
+
+// append adds entries at the end of the log, the first one must follow the
+// last entry of the log
+func (l *raftLog) append(entries ...*Entry) {
+	for _, entry := range entries {
+		if entry.Index != l.lastIndex()+1 {
+			panic(fmt.Sprintf("Appending entry %d to a log ending at %d", entry.Index, l.lastIndex()))
+		}
+		raw, err := proto.Marshal(entry)
+		if err != nil {
+			panic(fmt.Sprintf("Could not marshal log entry %d: %s", entry.Index, err))
+		}
+		if err = l.persistor.StoreState(fmt.Sprintf(entryKeyFormat, entry.Index), raw); err != nil {
+			logger.Errorf("Could not persist log entry %d: %s", entry.Index, err)
+		}
+		l.entries = append(l.entries, entry)
+	}
+}
+
+// truncate removes the entries from index on
+func (l *raftLog) truncate(from uint64) {
+	if from < l.firstIndex() {
+		panic(fmt.Sprintf("Truncating the log at %d, before its first index %d", from, l.firstIndex()))
+	}
+	for index := l.lastIndex(); index >= from; index-- {
+		l.persistor.DelState(fmt.Sprintf(entryKeyFormat, index))
+	}
+	if from <= l.lastIndex() {
+		l.entries = l.entries[:from-l.firstIndex()]
+	}
+}
+
+// compact makes snap the base of the log. The entries which follow the
+// snapshot are kept if the log agrees with the snapshot, otherwise the log
+// is emptied.
+func (l *raftLog) compact(snap *Snapshot) {
+	if snap.Index < l.snapshot.Index {
+		panic(fmt.Sprintf("Compacting the log to %d, before its snapshot %d", snap.Index, l.snapshot.Index))
+	}
+	raw, err := proto.Marshal(snap)
+	if err != nil {
+		panic(fmt.Sprintf("Could not marshal snapshot %d: %s", snap.Index, err))
+	}
+	if err = l.persistor.StoreState(snapshotKey, raw); err != nil {
+		logger.Errorf("Could not persist snapshot %d: %s", snap.Index, err)
+	}
+
+	var kept []*Entry
+	if term, ok := l.term(snap.Index); ok && term == snap.Term {
+		kept = l.entries[snap.Index-l.snapshot.Index:]
+	}
+	for _, entry := range l.entries[:len(l.entries)-len(kept)] {
+		l.persistor.DelState(fmt.Sprintf(entryKeyFormat, entry.Index))
+	}
+	l.snapshot = snap
+	l.entries = append([]*Entry(nil), kept...)
+}
+
+type uint64Slice []uint64
+
+func (a uint64Slice) Len() int           { return len(a) }
+func (a uint64Slice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a uint64Slice) Less(i, j int) bool { return a[i] < a[j] }
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func makeEntries(from, to, term uint64) (entries []*Entry) {
+	for index := from; index <= to; index++ {
+		entries = append(entries, &Entry{Term: term, Index: index, Transactions: [][]byte{[]byte(fmt.Sprintf("tx%d", index))}})
+	}
+	return
+}
+
+func TestLogAppendTruncate(t *testing.T) {
+	l := newRaftLog(newMockPersist())
+	if l.firstIndex() != 1 || l.lastIndex() != 0 || l.lastTerm() != 0 {
+		t.Fatalf("Empty log should be [1, 0] in term 0, got [%d, %d] in term %d", l.firstIndex(), l.lastIndex(), l.lastTerm())
+	}
+
+	l.append(makeEntries(1, 5, 1)...)
+	if l.lastIndex() != 5 || l.lastTerm() != 1 {
+		t.Fatalf("Expected log to end at 5 in term 1, got %d in term %d", l.lastIndex(), l.lastTerm())
+	}
+	if entries := l.slice(2, 2); len(entries) != 2 || entries[0].Index != 2 || entries[1].Index != 3 {
+		t.Errorf("Unexpected slice %v", entries)
+	}
+	if entries := l.slice(6, 2); entries != nil {
+		t.Errorf("Slice after the end of the log should be empty, got %v", entries)
+	}
+
+	l.truncate(4)
+	if l.lastIndex() != 3 {
+		t.Fatalf("Expected log to end at 3 after truncation, got %d", l.lastIndex())
+	}
+	if _, ok := l.term(4); ok {
+		t.Errorf("Truncated entry 4 still has a term")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Appending a gap should panic")
+			}
+		}()
+		l.append(makeEntries(5, 5, 2)...)
+	}()
+}
+
+func TestLogCompact(t *testing.T) {
+	l := newRaftLog(newMockPersist())
+	l.append(makeEntries(1, 3, 1)...)
+	l.append(makeEntries(4, 6, 2)...)
+
+	// The log agrees with the snapshot, the entries which follow are kept
+	l.compact(&Snapshot{Index: 4, Term: 2})
+	if l.firstIndex() != 5 || l.lastIndex() != 6 {
+		t.Fatalf("Expected log [5, 6] after compaction, got [%d, %d]", l.firstIndex(), l.lastIndex())
+	}
+	if term, ok := l.term(4); !ok || term != 2 {
+		t.Errorf("Expected the snapshot to hold the term of entry 4")
+	}
+	if l.entry(4) != nil {
+		t.Errorf("Compacted entry 4 is still in the log")
+	}
+
+	// The log conflicts with the snapshot, it is emptied
+	l.compact(&Snapshot{Index: 6, Term: 3})
+	if l.firstIndex() != 7 || l.lastIndex() != 6 || l.lastTerm() != 3 {
+		t.Fatalf("Expected empty log after snapshot 6 in term 3, got [%d, %d] in term %d", l.firstIndex(), l.lastIndex(), l.lastTerm())
+	}
+}
+
+func TestLogRestore(t *testing.T) {
+	persist := newMockPersist()
+	l := newRaftLog(persist)
+	l.append(makeEntries(1, 6, 1)...)
+	l.compact(&Snapshot{Index: 2, Term: 1})
+	l.truncate(5)
+
+	// A stale entry left behind by a crash is not part of the log
+	persist.StoreState(fmt.Sprintf(entryKeyFormat, 9), mustMarshal(makeEntries(9, 9, 1)[0]))
+
+	restored := newRaftLog(persist)
+	if restored.snapshot.Index != 2 || restored.firstIndex() != 3 || restored.lastIndex() != 4 {
+		t.Fatalf("Expected restored log [3, 4] after snapshot 2, got [%d, %d] after snapshot %d",
+			restored.firstIndex(), restored.lastIndex(), restored.snapshot.Index)
+	}
+	if _, err := persist.ReadState(fmt.Sprintf(entryKeyFormat, 9)); err == nil {
+		t.Errorf("Stale entry 9 was not deleted")
+	}
+	if set, _ := persist.ReadStateSet(entryPrefix); len(set) != 2 {
+		t.Errorf("Expected 2 persisted entries, found %d", len(set))
+	}
+}
+
+func mustMarshal(entry *Entry) []byte {
+	raw, err := proto.Marshal(entry)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
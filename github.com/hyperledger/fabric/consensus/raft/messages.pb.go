@@ -0,0 +1,468 @@
+// Code generated by protoc-gen-go.
+// source: messages.proto
+// DO NOT EDIT!
+
+/*
+Package raft is a generated protocol buffer package.
+
+It is generated from these files:
+	messages.proto
+
+It has these top-level messages:
+	Message
+	Entry
+	RequestVote
+	Vote
+	AppendEntries
+	AppendResult
+	Snapshot
+	InstallSnapshot
+	Forward
+	HardState
+	Metadata
+*/
+package raft
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Message struct {
+	// Types that are valid to be assigned to Payload:
+	//	*Message_RequestVote
+	//	*Message_Vote
+	//	*Message_AppendEntries
+	//	*Message_AppendResult
+	//	*Message_InstallSnapshot
+	//	*Message_Forward
+	Payload isMessage_Payload `protobuf_oneof:"payload"`
+}
+
+func (m *Message) Reset()                    { *m = Message{} }
+func (m *Message) String() string            { return proto.CompactTextString(m) }
+func (*Message) ProtoMessage()               {}
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+type isMessage_Payload interface {
+	isMessage_Payload()
+}
+
+type Message_RequestVote struct {
+	RequestVote *RequestVote `protobuf:"bytes,1,opt,name=request_vote,json=requestVote,oneof"`
+}
+type Message_Vote struct {
+	Vote *Vote `protobuf:"bytes,2,opt,name=vote,oneof"`
+}
+type Message_AppendEntries struct {
+	AppendEntries *AppendEntries `protobuf:"bytes,3,opt,name=append_entries,json=appendEntries,oneof"`
+}
+type Message_AppendResult struct {
+	AppendResult *AppendResult `protobuf:"bytes,4,opt,name=append_result,json=appendResult,oneof"`
+}
+type Message_InstallSnapshot struct {
+	InstallSnapshot *InstallSnapshot `protobuf:"bytes,5,opt,name=install_snapshot,json=installSnapshot,oneof"`
+}
+type Message_Forward struct {
+	Forward *Forward `protobuf:"bytes,6,opt,name=forward,oneof"`
+}
+
+func (*Message_RequestVote) isMessage_Payload()     {}
+func (*Message_Vote) isMessage_Payload()            {}
+func (*Message_AppendEntries) isMessage_Payload()   {}
+func (*Message_AppendResult) isMessage_Payload()    {}
+func (*Message_InstallSnapshot) isMessage_Payload() {}
+func (*Message_Forward) isMessage_Payload()         {}
+
+func (m *Message) GetPayload() isMessage_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *Message) GetRequestVote() *RequestVote {
+	if x, ok := m.GetPayload().(*Message_RequestVote); ok {
+		return x.RequestVote
+	}
+	return nil
+}
+
+func (m *Message) GetVote() *Vote {
+	if x, ok := m.GetPayload().(*Message_Vote); ok {
+		return x.Vote
+	}
+	return nil
+}
+
+func (m *Message) GetAppendEntries() *AppendEntries {
+	if x, ok := m.GetPayload().(*Message_AppendEntries); ok {
+		return x.AppendEntries
+	}
+	return nil
+}
+
+func (m *Message) GetAppendResult() *AppendResult {
+	if x, ok := m.GetPayload().(*Message_AppendResult); ok {
+		return x.AppendResult
+	}
+	return nil
+}
+
+func (m *Message) GetInstallSnapshot() *InstallSnapshot {
+	if x, ok := m.GetPayload().(*Message_InstallSnapshot); ok {
+		return x.InstallSnapshot
+	}
+	return nil
+}
+
+func (m *Message) GetForward() *Forward {
+	if x, ok := m.GetPayload().(*Message_Forward); ok {
+		return x.Forward
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Message) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Message_OneofMarshaler, _Message_OneofUnmarshaler, _Message_OneofSizer, []interface{}{
+		(*Message_RequestVote)(nil),
+		(*Message_Vote)(nil),
+		(*Message_AppendEntries)(nil),
+		(*Message_AppendResult)(nil),
+		(*Message_InstallSnapshot)(nil),
+		(*Message_Forward)(nil),
+	}
+}
+
+func _Message_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Message)
+	// payload
+	switch x := m.Payload.(type) {
+	case *Message_RequestVote:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.RequestVote); err != nil {
+			return err
+		}
+	case *Message_Vote:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Vote); err != nil {
+			return err
+		}
+	case *Message_AppendEntries:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AppendEntries); err != nil {
+			return err
+		}
+	case *Message_AppendResult:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.AppendResult); err != nil {
+			return err
+		}
+	case *Message_InstallSnapshot:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.InstallSnapshot); err != nil {
+			return err
+		}
+	case *Message_Forward:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Forward); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Message.Payload has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Message_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Message)
+	switch tag {
+	case 1: // payload.request_vote
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RequestVote)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_RequestVote{msg}
+		return true, err
+	case 2: // payload.vote
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Vote)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_Vote{msg}
+		return true, err
+	case 3: // payload.append_entries
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AppendEntries)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_AppendEntries{msg}
+		return true, err
+	case 4: // payload.append_result
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(AppendResult)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_AppendResult{msg}
+		return true, err
+	case 5: // payload.install_snapshot
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(InstallSnapshot)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_InstallSnapshot{msg}
+		return true, err
+	case 6: // payload.forward
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Forward)
+		err := b.DecodeMessage(msg)
+		m.Payload = &Message_Forward{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Message_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Message)
+	// payload
+	switch x := m.Payload.(type) {
+	case *Message_RequestVote:
+		s := proto.Size(x.RequestVote)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Vote:
+		s := proto.Size(x.Vote)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AppendEntries:
+		s := proto.Size(x.AppendEntries)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_AppendResult:
+		s := proto.Size(x.AppendResult)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_InstallSnapshot:
+		s := proto.Size(x.InstallSnapshot)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Message_Forward:
+		s := proto.Size(x.Forward)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// A log entry holds a batch of marshaled transactions, an entry without
+// transactions is the no-op a new leader appends to commit its term
+type Entry struct {
+	Term         uint64   `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Index        uint64   `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+	Transactions [][]byte `protobuf:"bytes,3,rep,name=transactions,proto3" json:"transactions,omitempty"`
+}
+
+func (m *Entry) Reset()                    { *m = Entry{} }
+func (m *Entry) String() string            { return proto.CompactTextString(m) }
+func (*Entry) ProtoMessage()               {}
+func (*Entry) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+type RequestVote struct {
+	Term         uint64 `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	LastLogIndex uint64 `protobuf:"varint,2,opt,name=last_log_index,json=lastLogIndex" json:"last_log_index,omitempty"`
+	LastLogTerm  uint64 `protobuf:"varint,3,opt,name=last_log_term,json=lastLogTerm" json:"last_log_term,omitempty"`
+}
+
+func (m *RequestVote) Reset()                    { *m = RequestVote{} }
+func (m *RequestVote) String() string            { return proto.CompactTextString(m) }
+func (*RequestVote) ProtoMessage()               {}
+func (*RequestVote) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type Vote struct {
+	Term    uint64 `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Granted bool   `protobuf:"varint,2,opt,name=granted" json:"granted,omitempty"`
+}
+
+func (m *Vote) Reset()                    { *m = Vote{} }
+func (m *Vote) String() string            { return proto.CompactTextString(m) }
+func (*Vote) ProtoMessage()               {}
+func (*Vote) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+type AppendEntries struct {
+	Term         uint64   `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	PrevLogIndex uint64   `protobuf:"varint,2,opt,name=prev_log_index,json=prevLogIndex" json:"prev_log_index,omitempty"`
+	PrevLogTerm  uint64   `protobuf:"varint,3,opt,name=prev_log_term,json=prevLogTerm" json:"prev_log_term,omitempty"`
+	Entries      []*Entry `protobuf:"bytes,4,rep,name=entries" json:"entries,omitempty"`
+	LeaderCommit uint64   `protobuf:"varint,5,opt,name=leader_commit,json=leaderCommit" json:"leader_commit,omitempty"`
+}
+
+func (m *AppendEntries) Reset()                    { *m = AppendEntries{} }
+func (m *AppendEntries) String() string            { return proto.CompactTextString(m) }
+func (*AppendEntries) ProtoMessage()               {}
+func (*AppendEntries) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *AppendEntries) GetEntries() []*Entry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+type AppendResult struct {
+	Term    uint64 `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Success bool   `protobuf:"varint,2,opt,name=success" json:"success,omitempty"`
+	// The last index known to match the log of the leader on success, the
+	// last index of the log of the follower otherwise
+	MatchIndex uint64 `protobuf:"varint,3,opt,name=match_index,json=matchIndex" json:"match_index,omitempty"`
+}
+
+func (m *AppendResult) Reset()                    { *m = AppendResult{} }
+func (m *AppendResult) String() string            { return proto.CompactTextString(m) }
+func (*AppendResult) ProtoMessage()               {}
+func (*AppendResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+// A snapshot identifies the state of the ledger after applying the entry at
+// index, entries up to index are discarded from the log
+type Snapshot struct {
+	Index          uint64 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Term           uint64 `protobuf:"varint,2,opt,name=term" json:"term,omitempty"`
+	BlockchainInfo []byte `protobuf:"bytes,3,opt,name=blockchain_info,json=blockchainInfo,proto3" json:"blockchain_info,omitempty"`
+}
+
+func (m *Snapshot) Reset()                    { *m = Snapshot{} }
+func (m *Snapshot) String() string            { return proto.CompactTextString(m) }
+func (*Snapshot) ProtoMessage()               {}
+func (*Snapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+type InstallSnapshot struct {
+	Term     uint64    `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Snapshot *Snapshot `protobuf:"bytes,2,opt,name=snapshot" json:"snapshot,omitempty"`
+}
+
+func (m *InstallSnapshot) Reset()                    { *m = InstallSnapshot{} }
+func (m *InstallSnapshot) String() string            { return proto.CompactTextString(m) }
+func (*InstallSnapshot) ProtoMessage()               {}
+func (*InstallSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *InstallSnapshot) GetSnapshot() *Snapshot {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+// Transactions received by a follower are forwarded to the leader
+type Forward struct {
+	Transactions [][]byte `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+}
+
+func (m *Forward) Reset()                    { *m = Forward{} }
+func (m *Forward) String() string            { return proto.CompactTextString(m) }
+func (*Forward) ProtoMessage()               {}
+func (*Forward) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+type HardState struct {
+	Term     uint64 `protobuf:"varint,1,opt,name=term" json:"term,omitempty"`
+	Voted    bool   `protobuf:"varint,2,opt,name=voted" json:"voted,omitempty"`
+	VotedFor uint64 `protobuf:"varint,3,opt,name=voted_for,json=votedFor" json:"voted_for,omitempty"`
+}
+
+func (m *HardState) Reset()                    { *m = HardState{} }
+func (m *HardState) String() string            { return proto.CompactTextString(m) }
+func (*HardState) ProtoMessage()               {}
+func (*HardState) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+type Metadata struct {
+	Index uint64 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	Term  uint64 `protobuf:"varint,2,opt,name=term" json:"term,omitempty"`
+}
+
+func (m *Metadata) Reset()                    { *m = Metadata{} }
+func (m *Metadata) String() string            { return proto.CompactTextString(m) }
+func (*Metadata) ProtoMessage()               {}
+func (*Metadata) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func init() {
+	proto.RegisterType((*Message)(nil), "raft.message")
+	proto.RegisterType((*Entry)(nil), "raft.entry")
+	proto.RegisterType((*RequestVote)(nil), "raft.request_vote")
+	proto.RegisterType((*Vote)(nil), "raft.vote")
+	proto.RegisterType((*AppendEntries)(nil), "raft.append_entries")
+	proto.RegisterType((*AppendResult)(nil), "raft.append_result")
+	proto.RegisterType((*Snapshot)(nil), "raft.snapshot")
+	proto.RegisterType((*InstallSnapshot)(nil), "raft.install_snapshot")
+	proto.RegisterType((*Forward)(nil), "raft.forward")
+	proto.RegisterType((*HardState)(nil), "raft.hard_state")
+	proto.RegisterType((*Metadata)(nil), "raft.metadata")
+}
+
+func init() { proto.RegisterFile("messages.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 561 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x8e, 0xd3, 0x3c,
+	0x14, 0x6d, 0xa7, 0xe9, 0xb4, 0x73, 0x93, 0x76, 0x3e, 0xf9, 0x1b, 0xa1, 0x48, 0x2c, 0xa8, 0x02,
+	0x88, 0x82, 0x44, 0x17, 0xc3, 0x48, 0x48, 0x48, 0x6c, 0x18, 0x81, 0x3a, 0x12, 0x2b, 0x0f, 0xb0,
+	0x83, 0xc8, 0x93, 0xb8, 0x6d, 0x44, 0x62, 0x07, 0xdb, 0x2d, 0xcc, 0xab, 0xf1, 0x28, 0x3c, 0x0d,
+	0xca, 0xb5, 0xd3, 0xa6, 0x7f, 0x12, 0xbb, 0xdc, 0x73, 0xcf, 0xf1, 0xfd, 0xf1, 0x71, 0x60, 0x58,
+	0x70, 0xad, 0xd9, 0x9c, 0xeb, 0x49, 0xa9, 0xa4, 0x91, 0xc4, 0x53, 0x6c, 0x66, 0xa2, 0x3f, 0x27,
+	0xd0, 0x73, 0x09, 0xf2, 0x1a, 0x02, 0xc5, 0x7f, 0x2c, 0xb9, 0x36, 0xf1, 0x4a, 0x1a, 0x1e, 0xb6,
+	0x47, 0xed, 0xb1, 0x7f, 0x49, 0x26, 0x15, 0x71, 0xd2, 0xcc, 0x4c, 0x5b, 0xd4, 0x77, 0xf1, 0x17,
+	0x69, 0x38, 0x19, 0x81, 0x87, 0x82, 0x13, 0x14, 0x80, 0x15, 0x38, 0x22, 0x66, 0xc8, 0x5b, 0x18,
+	0xb2, 0xb2, 0xe4, 0x22, 0x8d, 0xb9, 0x30, 0x2a, 0xe3, 0x3a, 0xec, 0x20, 0xf7, 0xc2, 0x72, 0xb7,
+	0x73, 0xd3, 0x16, 0x1d, 0x58, 0xe4, 0xbd, 0x05, 0xc8, 0x1b, 0x70, 0x40, 0xac, 0xb8, 0x5e, 0xe6,
+	0x26, 0xf4, 0x50, 0xfd, 0xff, 0x96, 0xda, 0xa6, 0xa6, 0x2d, 0x1a, 0x58, 0x80, 0x62, 0x4c, 0xae,
+	0xe1, 0xbf, 0x4c, 0x68, 0xc3, 0xf2, 0x3c, 0xd6, 0x82, 0x95, 0x7a, 0x21, 0x4d, 0xd8, 0x45, 0xf9,
+	0x03, 0x2b, 0xdf, 0xcd, 0x4e, 0x5b, 0xf4, 0xdc, 0x61, 0xb7, 0x0e, 0x22, 0xcf, 0xa1, 0x37, 0x93,
+	0xea, 0x27, 0x53, 0x69, 0x78, 0x8a, 0xda, 0x81, 0xd5, 0x3a, 0x70, 0xda, 0xa2, 0x75, 0xfe, 0xdd,
+	0x19, 0xf4, 0x4a, 0x76, 0x9f, 0x4b, 0x96, 0x46, 0x9f, 0xa1, 0x5b, 0x8d, 0x74, 0x4f, 0x08, 0x78,
+	0x86, 0xab, 0x02, 0x37, 0xea, 0x51, 0xfc, 0x26, 0x17, 0xd0, 0xcd, 0x44, 0xca, 0x7f, 0xe1, 0xd6,
+	0x3c, 0x6a, 0x03, 0x12, 0x41, 0x60, 0x14, 0x13, 0x9a, 0x25, 0x26, 0x93, 0xa2, 0x5a, 0x53, 0x67,
+	0x1c, 0xd0, 0x2d, 0x2c, 0xca, 0xb7, 0xef, 0xe9, 0xe0, 0xe9, 0x4f, 0x60, 0x98, 0x33, 0x6d, 0xe2,
+	0x5c, 0xce, 0xe3, 0x66, 0x99, 0xa0, 0x42, 0x3f, 0xca, 0xf9, 0x8d, 0xab, 0x36, 0x58, 0xb3, 0xf0,
+	0x88, 0x0e, 0x92, 0x7c, 0x47, 0xfa, 0xc4, 0x55, 0x11, 0x5d, 0x81, 0x77, 0xb4, 0x4a, 0x08, 0xbd,
+	0xb9, 0x62, 0xc2, 0xf0, 0x14, 0x8f, 0xef, 0xd3, 0x3a, 0x8c, 0x7e, 0xb7, 0x77, 0x6f, 0xfc, 0x58,
+	0x9b, 0xa5, 0xe2, 0xab, 0xfd, 0x36, 0x2b, 0xb4, 0xd9, 0xe6, 0x9a, 0xd5, 0x6c, 0xd3, 0x91, 0xaa,
+	0x36, 0xc9, 0x53, 0xe8, 0xd5, 0xd6, 0xf2, 0x46, 0x9d, 0xb1, 0x7f, 0xe9, 0xdb, 0x1b, 0xc2, 0x0b,
+	0xa0, 0x75, 0x8e, 0x3c, 0x86, 0x41, 0xce, 0x59, 0xca, 0x55, 0x9c, 0xc8, 0xa2, 0xc8, 0xac, 0x15,
+	0xaa, 0xb5, 0x20, 0x78, 0x8d, 0x58, 0xf4, 0x6d, 0xc7, 0x6e, 0xc7, 0x66, 0xd7, 0xcb, 0x24, 0xe1,
+	0x5a, 0xd7, 0xb3, 0xbb, 0x90, 0x3c, 0x02, 0xbf, 0x60, 0x26, 0x59, 0xb8, 0x89, 0x6c, 0xb3, 0x80,
+	0x10, 0xce, 0x13, 0x7d, 0x85, 0x7e, 0x6d, 0xb6, 0x8d, 0x0d, 0xda, 0x4d, 0x1b, 0xd4, 0x05, 0x4f,
+	0x1a, 0x05, 0x9f, 0xc1, 0xf9, 0x5d, 0x2e, 0x93, 0xef, 0xc9, 0x82, 0x65, 0x22, 0xce, 0xc4, 0x4c,
+	0xe2, 0xd1, 0x01, 0x1d, 0x6e, 0xe0, 0x1b, 0x31, 0x93, 0x11, 0xdd, 0x77, 0xfc, 0xc1, 0x09, 0x5e,
+	0x6c, 0xda, 0x70, 0x4f, 0x77, 0x68, 0x77, 0x56, 0xa3, 0x74, 0x9d, 0x8f, 0x5e, 0xae, 0x1f, 0xc0,
+	0x9e, 0x45, 0xdb, 0x07, 0x2c, 0x7a, 0x0b, 0xb0, 0x60, 0x2a, 0x8d, 0xb5, 0x61, 0x47, 0xac, 0x73,
+	0x01, 0xdd, 0x95, 0xdc, 0x18, 0xc7, 0x06, 0xe4, 0x21, 0x9c, 0xe1, 0x47, 0x3c, 0x93, 0xca, 0x2d,
+	0xae, 0x8f, 0xc0, 0x07, 0xa9, 0xa2, 0x2b, 0xe8, 0x17, 0xdc, 0xb0, 0x94, 0x19, 0xf6, 0xef, 0x6b,
+	0xbb, 0x3b, 0xc5, 0xdf, 0xdd, 0xab, 0xbf, 0x03, 0x00, 0xa7, 0x79, 0xb9, 0xc1, 0x00, 0x05, 0x00,
+	0x00,
+}
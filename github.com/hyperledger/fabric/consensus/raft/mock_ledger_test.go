@@ -0,0 +1,253 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// ledgerOwner receives the completions of the asynchronous calls of the
+// mock ledger, and finds the ledgers of the other replicas for state transfer
+type ledgerOwner interface {
+	executed(tag interface{})
+	committed(tag interface{}, target *pb.BlockchainInfo)
+	stateUpdated(tag interface{}, target *pb.BlockchainInfo)
+	remoteLedger(handle *pb.PeerID) *mockLedger
+}
+
+// mockLedger is an in memory ledger, whose state is the hash of all the
+// transactions it executed
+type mockLedger struct {
+	owner ledgerOwner
+
+	mutex      sync.Mutex
+	blocks     []*pb.Block
+	pending    []*pb.Transaction
+	valid      bool
+	executions int // number of Execute calls, including the ones of replicas restarted on this ledger
+}
+
+func newMockLedger(owner ledgerOwner) *mockLedger {
+	return &mockLedger{
+		owner:  owner,
+		blocks: []*pb.Block{{StateHash: []byte("genesis")}},
+		valid:  true,
+	}
+}
+
+// Executor
+
+func (mock *mockLedger) Start() {}
+
+func (mock *mockLedger) Halt() {}
+
+func (mock *mockLedger) Execute(tag interface{}, txs []*pb.Transaction) {
+	mock.mutex.Lock()
+	mock.pending = append(mock.pending, txs...)
+	mock.executions++
+	mock.mutex.Unlock()
+	go mock.owner.executed(tag)
+}
+
+func (mock *mockLedger) Commit(tag interface{}, meta []byte) {
+	mock.mutex.Lock()
+	mock.commitBlock(meta)
+	info := mock.info()
+	mock.mutex.Unlock()
+	go mock.owner.committed(tag, info)
+}
+
+func (mock *mockLedger) Rollback(tag interface{}) {
+	mock.mutex.Lock()
+	mock.pending = nil
+	mock.mutex.Unlock()
+}
+
+// UpdateState copies the blocks of the first peer whose chain contains the target
+func (mock *mockLedger) UpdateState(tag interface{}, target *pb.BlockchainInfo, peers []*pb.PeerID) {
+	go func() {
+		for _, peer := range peers {
+			remote := mock.owner.remoteLedger(peer)
+			if remote == nil {
+				continue
+			}
+			blocks := remote.chain(target)
+			if blocks == nil {
+				continue
+			}
+			mock.mutex.Lock()
+			mock.blocks = blocks
+			mock.pending = nil
+			mock.mutex.Unlock()
+			mock.owner.stateUpdated(tag, target)
+			return
+		}
+		time.Sleep(10 * time.Millisecond) // Do not spin when no peer can serve the target yet
+		mock.owner.stateUpdated(tag, nil)
+	}()
+}
+
+// chain returns a copy of the blocks up to target, nil if the ledger does
+// not contain it
+func (mock *mockLedger) chain(target *pb.BlockchainInfo) []*pb.Block {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	if uint64(len(mock.blocks)) < target.Height {
+		return nil
+	}
+	if hash, _ := mock.blocks[target.Height-1].GetHash(); !bytes.Equal(hash, target.CurrentBlockHash) {
+		return nil
+	}
+	return append([]*pb.Block(nil), mock.blocks[:target.Height]...)
+}
+
+func (mock *mockLedger) commitBlock(meta []byte) *pb.Block {
+	prev := mock.blocks[len(mock.blocks)-1]
+	prevHash, _ := prev.GetHash()
+	state := prev.StateHash
+	for _, tx := range mock.pending {
+		raw, _ := proto.Marshal(tx)
+		h := sha256.Sum256(append(append([]byte(nil), state...), raw...))
+		state = h[:]
+	}
+	block := &pb.Block{
+		PreviousBlockHash: prevHash,
+		Transactions:      mock.pending,
+		StateHash:         state,
+		ConsensusMetadata: meta,
+	}
+	mock.blocks = append(mock.blocks, block)
+	mock.pending = nil
+	return block
+}
+
+func (mock *mockLedger) info() *pb.BlockchainInfo {
+	info := &pb.BlockchainInfo{Height: uint64(len(mock.blocks))}
+	info.CurrentBlockHash, _ = mock.blocks[len(mock.blocks)-1].GetHash()
+	return info
+}
+
+// LegacyExecutor
+
+func (mock *mockLedger) BeginTxBatch(id interface{}) error {
+	return nil
+}
+
+func (mock *mockLedger) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	mock.pending = append(mock.pending, txs...)
+	return nil, nil
+}
+
+func (mock *mockLedger) CommitTxBatch(id interface{}, metadata []byte) (*pb.Block, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return mock.commitBlock(metadata), nil
+}
+
+func (mock *mockLedger) RollbackTxBatch(id interface{}) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	mock.pending = nil
+	return nil
+}
+
+func (mock *mockLedger) PreviewCommitTxBatch(id interface{}, metadata []byte) ([]byte, error) {
+	return nil, fmt.Errorf("Not implemented")
+}
+
+// LedgerManager
+
+func (mock *mockLedger) InvalidateState() {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	mock.valid = false
+}
+
+func (mock *mockLedger) ValidateState() {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	mock.valid = true
+}
+
+// ReadOnlyLedger
+
+func (mock *mockLedger) GetBlock(id uint64) (*pb.Block, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	if id >= uint64(len(mock.blocks)) {
+		return nil, fmt.Errorf("Block not found")
+	}
+	return mock.blocks[id], nil
+}
+
+func (mock *mockLedger) GetBlockchainSize() uint64 {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return uint64(len(mock.blocks))
+}
+
+func (mock *mockLedger) GetBlockchainInfo() *pb.BlockchainInfo {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return mock.info()
+}
+
+func (mock *mockLedger) GetBlockchainInfoBlob() []byte {
+	raw, _ := proto.Marshal(mock.GetBlockchainInfo())
+	return raw
+}
+
+func (mock *mockLedger) GetBlockHeadMetadata() ([]byte, error) {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return mock.blocks[len(mock.blocks)-1].ConsensusMetadata, nil
+}
+
+// transactions lists the IDs of the transactions in the ledger, in order
+func (mock *mockLedger) transactions() []string {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	var ids []string
+	for _, block := range mock.blocks {
+		for _, tx := range block.Transactions {
+			ids = append(ids, tx.Txid)
+		}
+	}
+	return ids
+}
+
+func (mock *mockLedger) executionCount() int {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return mock.executions
+}
+
+func (mock *mockLedger) isValid() bool {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return mock.valid
+}
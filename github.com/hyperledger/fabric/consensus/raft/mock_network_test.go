@@ -0,0 +1,352 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// testnet connects replicas running in the same process. Replicas can be
+// crashed and restarted on the ledger and persisted state they had, and
+// messages between them can be dropped by a filter.
+type testnet struct {
+	mutex    sync.Mutex
+	config   *viper.Viper
+	replicas []*testReplica
+	filterFn func(src, dst uint64, msg *Message) bool // returns false to drop the message
+}
+
+// testReplica is the consensus.Stack of one replica
+type testReplica struct {
+	*mockLedger
+	*mockPersist
+	id  uint64
+	net *testnet
+
+	mutex   sync.Mutex
+	op      *obcRaft
+	stopped chan struct{}
+}
+
+func makeTestnet(N int, configure func(config *viper.Viper)) *testnet {
+	config := loadConfig()
+	config.Set("general.N", N)
+	config.Set("general.K", 10)
+	config.Set("general.batchsize", 2)
+	config.Set("general.timeout.batch", "20ms")
+	config.Set("general.timeout.heartbeat", "20ms")
+	config.Set("general.timeout.election", "150ms")
+	if configure != nil {
+		configure(config)
+	}
+
+	net := &testnet{config: config}
+	for i := 0; i < N; i++ {
+		r := &testReplica{id: uint64(i), net: net, mockPersist: newMockPersist()}
+		r.mockLedger = newMockLedger(r)
+		net.replicas = append(net.replicas, r)
+	}
+	for _, r := range net.replicas {
+		r.start()
+	}
+	return net
+}
+
+func (net *testnet) stop() {
+	for _, r := range net.replicas {
+		r.crash()
+	}
+}
+
+func (net *testnet) setFilter(filterFn func(src, dst uint64, msg *Message) bool) {
+	net.mutex.Lock()
+	defer net.mutex.Unlock()
+	net.filterFn = filterFn
+}
+
+// isolate drops all the messages to and from the given replicas
+func (net *testnet) isolate(ids ...uint64) {
+	isolated := make(map[uint64]bool)
+	for _, id := range ids {
+		isolated[id] = true
+	}
+	net.setFilter(func(src, dst uint64, msg *Message) bool {
+		return !isolated[src] && !isolated[dst]
+	})
+}
+
+func (net *testnet) heal() {
+	net.setFilter(nil)
+}
+
+func (net *testnet) deliver(src uint64, dst *testReplica, ocMsg *pb.Message) {
+	msg := &Message{}
+	if err := proto.Unmarshal(ocMsg.Payload, msg); err != nil {
+		panic(fmt.Sprintf("Replica %d sent a message which does not unmarshal: %s", src, err))
+	}
+	net.mutex.Lock()
+	filterFn := net.filterFn
+	net.mutex.Unlock()
+	if filterFn != nil && !filterFn(src, dst.id, msg) {
+		return
+	}
+	dst.recv(ocMsg, getValidatorHandle(src))
+}
+
+// start runs a new replica on the ledger and persisted state of the replica
+func (r *testReplica) start() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stopped = make(chan struct{})
+	r.op = newObcRaft(r.id, r.net.config, r)
+}
+
+// crash stops the replica, it keeps its ledger and persisted state
+func (r *testReplica) crash() {
+	r.mutex.Lock()
+	op := r.op
+	r.op = nil
+	if op != nil {
+		close(r.stopped)
+	}
+	r.mutex.Unlock()
+	if op != nil {
+		op.Close()
+	}
+}
+
+func (r *testReplica) consenter() (*obcRaft, chan struct{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.op, r.stopped
+}
+
+// recv queues an event for the replica, unless it crashed
+func (r *testReplica) recv(ocMsg *pb.Message, sender *pb.PeerID) {
+	r.queue(raftMessageEvent{msg: ocMsg, sender: sender})
+}
+
+func (r *testReplica) queue(event interface{}) bool {
+	op, stopped := r.consenter()
+	if op == nil {
+		return false
+	}
+	select {
+	case op.manager.Queue() <- event:
+		return true
+	case <-stopped:
+		return false
+	}
+}
+
+// inspect runs fn on the main thread of the replica, so that it can safely
+// read its state. It returns false if the replica is not running.
+func (r *testReplica) inspect(fn func(op *obcRaft)) bool {
+	done := make(chan struct{})
+	op, stopped := r.consenter()
+	if op == nil || !r.queue(workEvent(func() { fn(op); close(done) })) {
+		return false
+	}
+	select {
+	case <-done:
+		return true
+	case <-stopped:
+		return false
+	}
+}
+
+func (r *testReplica) submit(tx *pb.Transaction) {
+	raw, _ := proto.Marshal(tx)
+	r.recv(&pb.Message{Type: pb.Message_CHAIN_TRANSACTION, Payload: raw}, r.handle())
+}
+
+func (r *testReplica) handle() *pb.PeerID {
+	return getValidatorHandle(r.id)
+}
+
+// NetworkStack
+
+func (r *testReplica) GetNetworkInfo() (self *pb.PeerEndpoint, network []*pb.PeerEndpoint, err error) {
+	for _, o := range r.net.replicas {
+		network = append(network, &pb.PeerEndpoint{ID: o.handle(), Type: pb.PeerEndpoint_VALIDATOR})
+	}
+	return network[r.id], network, nil
+}
+
+func (r *testReplica) GetNetworkHandles() (self *pb.PeerID, network []*pb.PeerID, err error) {
+	for _, o := range r.net.replicas {
+		network = append(network, o.handle())
+	}
+	return r.handle(), network, nil
+}
+
+func (r *testReplica) Broadcast(msg *pb.Message, peerType pb.PeerEndpoint_Type) error {
+	for _, o := range r.net.replicas {
+		if o != r {
+			r.net.deliver(r.id, o, msg)
+		}
+	}
+	return nil
+}
+
+func (r *testReplica) Unicast(msg *pb.Message, receiverHandle *pb.PeerID) error {
+	id, err := getValidatorID(receiverHandle)
+	if err != nil || id >= uint64(len(r.net.replicas)) {
+		return fmt.Errorf("Couldn't unicast message to %s", receiverHandle.Name)
+	}
+	r.net.deliver(r.id, r.net.replicas[id], msg)
+	return nil
+}
+
+// SecurityUtils
+
+func (r *testReplica) Sign(msg []byte) ([]byte, error) {
+	return msg, nil
+}
+
+func (r *testReplica) Verify(peerID *pb.PeerID, signature []byte, message []byte) error {
+	return nil
+}
+
+// Consumer callbacks of the mock ledger
+
+func (r *testReplica) executed(tag interface{}) {
+	r.queue(executedEvent{tag})
+}
+
+func (r *testReplica) committed(tag interface{}, target *pb.BlockchainInfo) {
+	r.queue(committedEvent{tag, target})
+}
+
+func (r *testReplica) stateUpdated(tag interface{}, target *pb.BlockchainInfo) {
+	r.queue(stateUpdatedEvent{snapshot: tag.(*Snapshot), target: target})
+}
+
+func (r *testReplica) remoteLedger(handle *pb.PeerID) *mockLedger {
+	id, err := getValidatorID(handle)
+	if err != nil || id >= uint64(len(r.net.replicas)) {
+		return nil
+	}
+	return r.net.replicas[id].mockLedger
+}
+
+// mockPersist is an in memory StatePersistor
+type mockPersist struct {
+	mutex sync.Mutex
+	store map[string][]byte
+}
+
+func newMockPersist() *mockPersist {
+	return &mockPersist{store: make(map[string][]byte)}
+}
+
+func (p *mockPersist) ReadState(key string) ([]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if val, ok := p.store[key]; ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("cannot find key %s", key)
+}
+
+func (p *mockPersist) ReadStateSet(prefix string) (map[string][]byte, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ret := make(map[string][]byte)
+	for key, val := range p.store {
+		if strings.HasPrefix(key, prefix) {
+			ret[key] = val
+		}
+	}
+	return ret, nil
+}
+
+func (p *mockPersist) StoreState(key string, value []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.store[key] = value
+	return nil
+}
+
+func (p *mockPersist) DelState(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.store, key)
+}
+
+// waitFor polls cond until it holds, or fails the test after timeout
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// leaderOf returns the replica which is leader of the highest term, among
+// the running replicas
+func (net *testnet) leaderOf() (l *testReplica, term uint64) {
+	for _, r := range net.replicas {
+		r.inspect(func(op *obcRaft) {
+			if op.role == leader && op.term >= term {
+				l, term = r, op.term
+			}
+		})
+	}
+	return
+}
+
+// waitLeader waits until the running replicas agree on a leader
+func (net *testnet) waitLeader(t *testing.T) *testReplica {
+	var l *testReplica
+	waitFor(t, 10*time.Second, "a leader", func() bool {
+		var term uint64
+		l, term = net.leaderOf()
+		if l == nil {
+			return false
+		}
+		agreed := true
+		for _, r := range net.replicas {
+			r.inspect(func(op *obcRaft) {
+				if op.term != term || !op.leaderKnown || op.leader != l.id {
+					agreed = false
+				}
+			})
+		}
+		return agreed
+	})
+	return l
+}
+
+func createTx(tag int) *pb.Transaction {
+	return &pb.Transaction{
+		Type:    pb.Transaction_CHAINCODE_INVOKE,
+		Txid:    fmt.Sprintf("tx%d", tag),
+		Payload: []byte(fmt.Sprintf("payload%d", tag)),
+	}
+}
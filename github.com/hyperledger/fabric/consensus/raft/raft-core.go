@@ -0,0 +1,865 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/consensus"
+	"github.com/hyperledger/fabric/consensus/util/events"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+type role int
+
+const (
+	follower role = iota
+	candidate
+	leader
+)
+
+func (r role) String() string {
+	switch r {
+	case follower:
+		return "follower"
+	case candidate:
+		return "candidate"
+	case leader:
+		return "leader"
+	}
+	return fmt.Sprintf("role(%d)", int(r))
+}
+
+// Event types
+
+// electionTimerEvent is sent when a follower or candidate did not hear from
+// a leader in time
+type electionTimerEvent struct{}
+
+// heartbeatTimerEvent is sent when the leader must replicate its log
+type heartbeatTimerEvent struct{}
+
+// batchTimerEvent is sent when the batch timer expires
+type batchTimerEvent struct{}
+
+// workEvent is a temporary type, to inject work
+type workEvent func()
+
+// obcRaft is a replica of the Raft protocol, it orders transactions in a log
+// replicated by a leader, and executes them through the consensus.Stack once
+// a majority of the replicas have them
+type obcRaft struct {
+	externalEventReceiver
+	stack     consensus.Stack
+	transport *transport
+
+	id         uint64
+	N          int
+	K          uint64 // snapshot period
+	batchSize  int
+	maxEntries int
+
+	batchTimeout     time.Duration
+	heartbeatTimeout time.Duration
+	electionTimeout  time.Duration
+	random           *rand.Rand
+
+	electionTimer    events.Timer
+	heartbeatTimer   events.Timer
+	batchTimer       events.Timer
+	batchTimerActive bool
+
+	// persistent state
+	term     uint64
+	voted    bool
+	votedFor uint64
+	log      *raftLog
+
+	// volatile state
+	role        role
+	leader      uint64
+	leaderKnown bool
+	votes       map[uint64]bool
+	commitIndex uint64
+	lastApplied uint64
+	executing   *Entry    // the entry being executed and committed
+	transfer    *Snapshot // the snapshot state transfer is moving to
+	deferred    *Snapshot // a snapshot to move to once the current execution completes
+
+	// leader state
+	nextIndex  map[uint64]uint64
+	matchIndex map[uint64]uint64
+	batchStore [][]byte
+
+	txIDs       map[string]bool   // transactions of the log and the batch store
+	compacted   map[string]bool   // transactions of the entries discarded by the last snapshot
+	outstanding map[string][]byte // transactions submitted through this replica and not applied yet
+}
+
+func newObcRaft(id uint64, config *viper.Viper, stack consensus.Stack) *obcRaft {
+	var err error
+	op := &obcRaft{
+		stack:       stack,
+		id:          id,
+		N:           config.GetInt("general.N"),
+		K:           uint64(config.GetInt("general.K")),
+		batchSize:   config.GetInt("general.batchsize"),
+		maxEntries:  config.GetInt("general.maxentries"),
+		random:      rand.New(rand.NewSource(time.Now().UnixNano() + int64(id))),
+		txIDs:       make(map[string]bool),
+		compacted:   make(map[string]bool),
+		outstanding: make(map[string][]byte),
+	}
+	if op.N < 1 || id >= uint64(op.N) {
+		panic(fmt.Errorf("Replica id %d is not within the %d replicas of the network", id, op.N))
+	}
+	if op.K == 0 || op.batchSize <= 0 || op.maxEntries <= 0 {
+		panic(fmt.Errorf("Raft snapshot period, batch size and max entries must be positive"))
+	}
+	op.batchTimeout, err = time.ParseDuration(config.GetString("general.timeout.batch"))
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse batch timeout: %s", err))
+	}
+	op.heartbeatTimeout, err = time.ParseDuration(config.GetString("general.timeout.heartbeat"))
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse heartbeat timeout: %s", err))
+	}
+	op.electionTimeout, err = time.ParseDuration(config.GetString("general.timeout.election"))
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse election timeout: %s", err))
+	}
+	if op.electionTimeout <= op.heartbeatTimeout {
+		op.electionTimeout = 2 * op.heartbeatTimeout
+		logger.Warningf("Configured election timeout must be greater than heartbeat timeout, setting to %v", op.electionTimeout)
+	}
+
+	logger.Infof("Raft replica %d of %d", id, op.N)
+	logger.Infof("Raft snapshot period K = %d", op.K)
+	logger.Infof("Raft batch size = %d", op.batchSize)
+	logger.Infof("Raft batch timeout = %v", op.batchTimeout)
+	logger.Infof("Raft heartbeat timeout = %v", op.heartbeatTimeout)
+	logger.Infof("Raft election timeout = %v", op.electionTimeout)
+
+	op.restoreState()
+
+	op.transport = newTransport(id, op.N, stack)
+	op.manager = events.NewManagerImpl()
+	op.manager.SetReceiver(op)
+	etf := events.NewTimerFactoryImpl(op.manager)
+	op.electionTimer = etf.CreateTimer()
+	op.heartbeatTimer = etf.CreateTimer()
+	op.batchTimer = etf.CreateTimer()
+	op.manager.Start()
+
+	op.manager.Queue() <- workEvent(func() {
+		if op.lastApplied < op.log.snapshot.Index {
+			// Crashed while moving to the snapshot
+			op.startTransfer(op.log.snapshot)
+		}
+		op.resetElectionTimer()
+		if op.N == 1 {
+			op.becomeCandidate()
+		}
+	})
+
+	return op
+}
+
+// restoreState reads the state persisted before a crash, and what was
+// applied from the ledger
+func (op *obcRaft) restoreState() {
+	if raw, err := op.stack.ReadState(hardStateKey); err == nil && raw != nil {
+		hs := &HardState{}
+		if err = proto.Unmarshal(raw, hs); err != nil {
+			logger.Errorf("Replica %d could not unmarshal hard state - local state is damaged: %s", op.id, err)
+		} else {
+			op.term, op.voted, op.votedFor = hs.Term, hs.Voted, hs.VotedFor
+		}
+	}
+	op.log = newRaftLog(op.stack)
+
+	if raw, err := op.stack.GetBlockHeadMetadata(); err == nil && raw != nil {
+		meta := &Metadata{}
+		if err = proto.Unmarshal(raw, meta); err != nil {
+			logger.Warningf("Replica %d could not unmarshal the metadata of the last block: %s", op.id, err)
+		} else {
+			op.lastApplied = meta.Index
+			if op.lastApplied > op.log.lastIndex() {
+				// The ledger is ahead of the log, which was lost
+				op.log.compact(&Snapshot{Index: meta.Index, Term: meta.Term, BlockchainInfo: op.stack.GetBlockchainInfoBlob()})
+			}
+		}
+	}
+	op.commitIndex = op.lastApplied
+	if op.log.snapshot.Index > op.commitIndex {
+		op.commitIndex = op.log.snapshot.Index
+	}
+	for index := op.log.firstIndex(); index <= op.log.lastIndex(); index++ {
+		op.addTxIDs(op.log.entry(index))
+	}
+
+	logger.Infof("Replica %d restored term %d, log [%d, %d], applied %d", op.id, op.term, op.log.firstIndex(), op.log.lastIndex(), op.lastApplied)
+}
+
+// Close tells us to release resources we are holding
+func (op *obcRaft) Close() {
+	op.manager.Halt()
+	op.electionTimer.Halt()
+	op.heartbeatTimer.Halt()
+	op.batchTimer.Halt()
+	op.transport.close()
+}
+
+func (op *obcRaft) quorum() int {
+	return op.N/2 + 1
+}
+
+func (op *obcRaft) persistHardState() {
+	raw, err := proto.Marshal(&HardState{Term: op.term, Voted: op.voted, VotedFor: op.votedFor})
+	if err != nil {
+		logger.Errorf("Replica %d could not marshal hard state: %s", op.id, err)
+		return
+	}
+	if err = op.stack.StoreState(hardStateKey, raw); err != nil {
+		logger.Errorf("Replica %d could not persist hard state: %s", op.id, err)
+	}
+}
+
+// GetStatus returns the name of the plugin, the state of raft is not
+// described by ConsensusStatus yet
+func (op *obcRaft) GetStatus() (*pb.ConsensusStatus, error) {
+	return &pb.ConsensusStatus{Plugin: "raft"}, nil
+}
+
+// ProcessEvent is the main thread of the replica, all the state is only
+// accessed from here
+func (op *obcRaft) ProcessEvent(event events.Event) events.Event {
+	switch et := event.(type) {
+	case workEvent:
+		et()
+	case raftMessageEvent:
+		op.processMessage(et.msg, et.sender)
+	case electionTimerEvent:
+		if op.role != leader {
+			logger.Infof("Replica %d election timer expired in term %d", op.id, op.term)
+			op.becomeCandidate()
+		}
+	case heartbeatTimerEvent:
+		if op.role == leader {
+			op.sendAppends()
+			op.heartbeatTimer.Reset(op.heartbeatTimeout, heartbeatTimerEvent{})
+		}
+	case batchTimerEvent:
+		op.batchTimerActive = false
+		if op.role == leader && len(op.batchStore) > 0 {
+			op.appendBatch()
+		}
+	case executedEvent:
+		entry := et.tag.(*Entry)
+		meta, _ := proto.Marshal(&Metadata{Index: entry.Index, Term: entry.Term})
+		op.stack.Commit(entry, meta)
+	case committedEvent:
+		if op.executing == nil {
+			logger.Warningf("Replica %d received a commit while not executing", op.id)
+			return nil
+		}
+		logger.Debugf("Replica %d committed entry %d", op.id, op.executing.Index)
+		op.lastApplied = op.executing.Index
+		op.executing = nil
+		op.maybeSnapshot()
+		if snap := op.deferred; snap != nil {
+			op.deferred = nil
+			if snap.Index > op.lastApplied {
+				op.startTransfer(snap)
+			}
+		}
+		op.apply()
+	case rolledBackEvent:
+	case stateUpdatedEvent:
+		op.stateUpdated(et.snapshot, et.target)
+	default:
+		logger.Errorf("Replica %d received an unknown event type %T", op.id, et)
+	}
+	return nil
+}
+
+func (op *obcRaft) processMessage(ocMsg *pb.Message, senderHandle *pb.PeerID) {
+	if ocMsg.Type == pb.Message_CHAIN_TRANSACTION {
+		op.submit(ocMsg.Payload)
+		return
+	}
+	if ocMsg.Type != pb.Message_CONSENSUS {
+		logger.Errorf("Unexpected message type: %s", ocMsg.Type)
+		return
+	}
+
+	sender, err := getValidatorID(senderHandle)
+	if err != nil || sender >= uint64(op.N) || sender == op.id {
+		logger.Warningf("Replica %d ignoring message from unexpected sender %v", op.id, senderHandle)
+		return
+	}
+	msg := &Message{}
+	if err = proto.Unmarshal(ocMsg.Payload, msg); err != nil {
+		logger.Errorf("Error unmarshaling message: %s", err)
+		return
+	}
+
+	switch {
+	case msg.GetRequestVote() != nil:
+		op.handleRequestVote(sender, msg.GetRequestVote())
+	case msg.GetVote() != nil:
+		op.handleVote(sender, msg.GetVote())
+	case msg.GetAppendEntries() != nil:
+		op.handleAppendEntries(sender, msg.GetAppendEntries())
+	case msg.GetAppendResult() != nil:
+		op.handleAppendResult(sender, msg.GetAppendResult())
+	case msg.GetInstallSnapshot() != nil:
+		op.handleInstallSnapshot(sender, msg.GetInstallSnapshot())
+	case msg.GetForward() != nil:
+		for _, tx := range msg.GetForward().Transactions {
+			op.submit(tx)
+		}
+	default:
+		logger.Warningf("Replica %d received an empty message from replica %d", op.id, sender)
+	}
+}
+
+// observeTerm steps down to follower if a message carries a newer term
+func (op *obcRaft) observeTerm(term uint64) {
+	if term > op.term {
+		logger.Infof("Replica %d moving from term %d to term %d", op.id, op.term, term)
+		op.term = term
+		op.voted = false
+		op.persistHardState()
+		op.becomeFollower()
+	}
+}
+
+// =============================================================================
+// leader election
+// =============================================================================
+
+func (op *obcRaft) resetElectionTimer() {
+	timeout := op.electionTimeout + time.Duration(op.random.Int63n(int64(op.electionTimeout)))
+	op.electionTimer.Reset(timeout, electionTimerEvent{})
+}
+
+func (op *obcRaft) becomeFollower() {
+	if op.role == leader {
+		op.heartbeatTimer.Stop()
+		op.stopBatchTimer()
+		// The batch store was never appended, hand it to the next leader
+		for _, tx := range op.batchStore {
+			delete(op.txIDs, txID(tx))
+		}
+		op.batchStore = nil
+	}
+	op.role = follower
+	op.leaderKnown = false
+	op.resetElectionTimer()
+}
+
+func (op *obcRaft) becomeCandidate() {
+	op.role = candidate
+	op.leaderKnown = false
+	op.term++
+	op.voted = true
+	op.votedFor = op.id
+	op.persistHardState()
+	op.votes = map[uint64]bool{op.id: true}
+	op.resetElectionTimer()
+
+	logger.Infof("Replica %d starting election for term %d", op.id, op.term)
+	op.transport.broadcast(&Message{Payload: &Message_RequestVote{RequestVote: &RequestVote{
+		Term:         op.term,
+		LastLogIndex: op.log.lastIndex(),
+		LastLogTerm:  op.log.lastTerm(),
+	}}})
+	if len(op.votes) >= op.quorum() {
+		op.becomeLeader()
+	}
+}
+
+func (op *obcRaft) handleRequestVote(sender uint64, rv *RequestVote) {
+	op.observeTerm(rv.Term)
+
+	granted := false
+	if rv.Term == op.term && (!op.voted || op.votedFor == sender) {
+		upToDate := rv.LastLogTerm > op.log.lastTerm() ||
+			(rv.LastLogTerm == op.log.lastTerm() && rv.LastLogIndex >= op.log.lastIndex())
+		if upToDate {
+			granted = true
+			op.voted = true
+			op.votedFor = sender
+			op.persistHardState()
+			op.resetElectionTimer()
+		}
+	}
+	logger.Debugf("Replica %d vote for replica %d in term %d: %v", op.id, sender, op.term, granted)
+	op.transport.send(sender, &Message{Payload: &Message_Vote{Vote: &Vote{Term: op.term, Granted: granted}}})
+}
+
+func (op *obcRaft) handleVote(sender uint64, v *Vote) {
+	op.observeTerm(v.Term)
+	if op.role != candidate || v.Term != op.term || !v.Granted {
+		return
+	}
+	op.votes[sender] = true
+	if len(op.votes) >= op.quorum() {
+		op.becomeLeader()
+	}
+}
+
+func (op *obcRaft) becomeLeader() {
+	logger.Infof("Replica %d is the leader of term %d", op.id, op.term)
+	op.role = leader
+	op.leader = op.id
+	op.leaderKnown = true
+	op.electionTimer.Stop()
+
+	op.nextIndex = make(map[uint64]uint64)
+	op.matchIndex = make(map[uint64]uint64)
+	for i := uint64(0); i < uint64(op.N); i++ {
+		op.nextIndex[i] = op.log.lastIndex() + 1
+		op.matchIndex[i] = 0
+	}
+
+	// Entries of previous terms are only committed along with an entry
+	// of the current term
+	op.appendEntry(nil)
+	for _, tx := range op.outstanding {
+		op.leaderAppendTx(tx)
+	}
+	op.heartbeatTimer.Reset(op.heartbeatTimeout, heartbeatTimerEvent{})
+}
+
+// learnLeader records the leader of the current term, and hands it the
+// transactions which are still outstanding
+func (op *obcRaft) learnLeader(id uint64) {
+	if op.leaderKnown && op.leader == id {
+		return
+	}
+	logger.Infof("Replica %d following leader %d in term %d", op.id, id, op.term)
+	op.role = follower
+	op.leader = id
+	op.leaderKnown = true
+	op.forwardOutstanding()
+}
+
+// =============================================================================
+// transactions
+// =============================================================================
+
+func txID(raw []byte) string {
+	tx := &pb.Transaction{}
+	if err := proto.Unmarshal(raw, tx); err != nil {
+		return ""
+	}
+	return tx.Txid
+}
+
+func (op *obcRaft) addTxIDs(entry *Entry) {
+	for _, tx := range entry.Transactions {
+		op.txIDs[txID(tx)] = true
+	}
+}
+
+// submit orders a transaction, it stays outstanding until it is applied and
+// is handed to every new leader until then
+func (op *obcRaft) submit(tx []byte) {
+	id := txID(tx)
+	if id == "" {
+		logger.Warningf("Replica %d dropping a transaction which did not unmarshal", op.id)
+		return
+	}
+	if _, ok := op.outstanding[id]; !ok {
+		op.outstanding[id] = tx
+	}
+	if op.role == leader {
+		op.leaderAppendTx(tx)
+	} else if op.leaderKnown {
+		op.transport.send(op.leader, &Message{Payload: &Message_Forward{Forward: &Forward{Transactions: [][]byte{tx}}}})
+	} else {
+		logger.Debugf("Replica %d holding transaction %s until a leader is known", op.id, id)
+	}
+}
+
+func (op *obcRaft) forwardOutstanding() {
+	if len(op.outstanding) == 0 || op.leader == op.id {
+		return
+	}
+	forward := &Forward{}
+	for _, tx := range op.outstanding {
+		forward.Transactions = append(forward.Transactions, tx)
+	}
+	logger.Debugf("Replica %d forwarding %d outstanding transactions to leader %d", op.id, len(forward.Transactions), op.leader)
+	op.transport.send(op.leader, &Message{Payload: &Message_Forward{Forward: forward}})
+}
+
+func (op *obcRaft) leaderAppendTx(tx []byte) {
+	id := txID(tx)
+	if op.txIDs[id] || op.compacted[id] {
+		logger.Debugf("Leader %d ignoring transaction %s which is already ordered", op.id, id)
+		return
+	}
+	op.txIDs[id] = true
+	op.batchStore = append(op.batchStore, tx)
+	if len(op.batchStore) >= op.batchSize {
+		op.appendBatch()
+	} else if !op.batchTimerActive {
+		op.batchTimer.Reset(op.batchTimeout, batchTimerEvent{})
+		op.batchTimerActive = true
+	}
+}
+
+func (op *obcRaft) stopBatchTimer() {
+	op.batchTimer.Stop()
+	op.batchTimerActive = false
+}
+
+func (op *obcRaft) appendBatch() {
+	op.stopBatchTimer()
+	batch := op.batchStore
+	op.batchStore = nil
+	logger.Infof("Leader %d creating entry with %d transactions", op.id, len(batch))
+	op.appendEntry(batch)
+}
+
+// appendEntry appends an entry to the log of the leader and replicates it
+func (op *obcRaft) appendEntry(txs [][]byte) {
+	entry := &Entry{Term: op.term, Index: op.log.lastIndex() + 1, Transactions: txs}
+	op.log.append(entry)
+	op.matchIndex[op.id] = entry.Index
+	op.maybeCommit()
+	op.sendAppends()
+}
+
+// =============================================================================
+// log replication
+// =============================================================================
+
+func (op *obcRaft) sendAppends() {
+	for i := uint64(0); i < uint64(op.N); i++ {
+		if i != op.id {
+			op.sendAppend(i)
+		}
+	}
+}
+
+func (op *obcRaft) sendAppend(id uint64) {
+	next := op.nextIndex[id]
+	if next < op.log.firstIndex() {
+		logger.Debugf("Leader %d sending snapshot %d to replica %d", op.id, op.log.snapshot.Index, id)
+		op.transport.send(id, &Message{Payload: &Message_InstallSnapshot{InstallSnapshot: &InstallSnapshot{
+			Term:     op.term,
+			Snapshot: op.log.snapshot,
+		}}})
+		return
+	}
+	prevTerm, _ := op.log.term(next - 1)
+	op.transport.send(id, &Message{Payload: &Message_AppendEntries{AppendEntries: &AppendEntries{
+		Term:         op.term,
+		PrevLogIndex: next - 1,
+		PrevLogTerm:  prevTerm,
+		Entries:      op.log.slice(next, op.maxEntries),
+		LeaderCommit: op.commitIndex,
+	}}})
+}
+
+func (op *obcRaft) handleAppendEntries(sender uint64, ae *AppendEntries) {
+	op.observeTerm(ae.Term)
+	if ae.Term < op.term {
+		op.transport.send(sender, &Message{Payload: &Message_AppendResult{AppendResult: &AppendResult{
+			Term:       op.term,
+			MatchIndex: op.log.lastIndex(),
+		}}})
+		return
+	}
+	op.learnLeader(sender)
+	op.resetElectionTimer()
+
+	prevIndex, prevTerm, entries := ae.PrevLogIndex, ae.PrevLogTerm, ae.Entries
+	// Entries up to our snapshot are committed and match the leader
+	for prevIndex < op.log.snapshot.Index && len(entries) > 0 {
+		prevIndex, prevTerm, entries = entries[0].Index, entries[0].Term, entries[1:]
+	}
+	if prevIndex < op.log.snapshot.Index {
+		prevIndex, prevTerm = op.log.snapshot.Index, op.log.snapshot.Term
+	}
+
+	if term, ok := op.log.term(prevIndex); !ok || term != prevTerm {
+		hint := op.log.lastIndex()
+		if prevIndex <= hint {
+			hint = prevIndex - 1
+		}
+		logger.Debugf("Replica %d log does not match leader at %d, hinting %d", op.id, prevIndex, hint)
+		op.transport.send(sender, &Message{Payload: &Message_AppendResult{AppendResult: &AppendResult{
+			Term:       op.term,
+			MatchIndex: hint,
+		}}})
+		return
+	}
+
+	for _, entry := range entries {
+		if term, ok := op.log.term(entry.Index); ok {
+			if term == entry.Term {
+				continue
+			}
+			logger.Infof("Replica %d truncating its log at %d", op.id, entry.Index)
+			for index := entry.Index; index <= op.log.lastIndex(); index++ {
+				for _, tx := range op.log.entry(index).Transactions {
+					delete(op.txIDs, txID(tx))
+				}
+			}
+			op.log.truncate(entry.Index)
+		}
+		op.log.append(entry)
+		op.addTxIDs(entry)
+	}
+
+	lastNew := prevIndex + uint64(len(entries))
+	if ae.LeaderCommit > op.commitIndex {
+		op.commitIndex = ae.LeaderCommit
+		if lastNew < op.commitIndex {
+			op.commitIndex = lastNew
+		}
+		op.apply()
+	}
+	op.transport.send(sender, &Message{Payload: &Message_AppendResult{AppendResult: &AppendResult{
+		Term:       op.term,
+		Success:    true,
+		MatchIndex: lastNew,
+	}}})
+}
+
+func (op *obcRaft) handleAppendResult(sender uint64, ar *AppendResult) {
+	op.observeTerm(ar.Term)
+	if op.role != leader || ar.Term != op.term {
+		return
+	}
+	if ar.Success {
+		if ar.MatchIndex > op.matchIndex[sender] {
+			op.matchIndex[sender] = ar.MatchIndex
+		}
+		op.nextIndex[sender] = op.matchIndex[sender] + 1
+		op.maybeCommit()
+		if op.nextIndex[sender] <= op.log.lastIndex() {
+			op.sendAppend(sender)
+		}
+		return
+	}
+
+	next := op.nextIndex[sender] - 1
+	if ar.MatchIndex+1 < next {
+		next = ar.MatchIndex + 1
+	}
+	if next < 1 {
+		next = 1
+	}
+	op.nextIndex[sender] = next
+	op.sendAppend(sender)
+}
+
+// maybeCommit advances the commit index to the last entry of the current
+// term which a majority of the replicas have
+func (op *obcRaft) maybeCommit() {
+	for index := op.log.lastIndex(); index > op.commitIndex; index-- {
+		if term, _ := op.log.term(index); term != op.term {
+			break
+		}
+		count := 0
+		for _, match := range op.matchIndex {
+			if match >= index {
+				count++
+			}
+		}
+		if count >= op.quorum() {
+			logger.Debugf("Leader %d committing up to entry %d", op.id, index)
+			op.commitIndex = index
+			op.apply()
+			return
+		}
+	}
+}
+
+// =============================================================================
+// execution
+// =============================================================================
+
+// apply executes the next committed entry, unless an execution or a state
+// transfer is in progress
+func (op *obcRaft) apply() {
+	for op.executing == nil && op.transfer == nil && op.lastApplied < op.commitIndex {
+		entry := op.log.entry(op.lastApplied + 1)
+		if entry == nil {
+			logger.Errorf("Replica %d is missing committed entry %d", op.id, op.lastApplied+1)
+			return
+		}
+
+		var txs []*pb.Transaction
+		for _, raw := range entry.Transactions {
+			tx := &pb.Transaction{}
+			if err := proto.Unmarshal(raw, tx); err != nil {
+				logger.Warningf("Replica %d could not unmarshal transaction %s", op.id, err)
+				continue
+			}
+			delete(op.outstanding, tx.Txid)
+			txs = append(txs, tx)
+		}
+
+		if len(entry.Transactions) == 0 {
+			// No block for the no-op of a new leader
+			op.lastApplied = entry.Index
+			continue
+		}
+
+		logger.Debugf("Replica %d executing entry %d with %d transactions", op.id, entry.Index, len(txs))
+		op.executing = entry
+		op.stack.Execute(entry, txs) // This executes in the background, we will receive an executedEvent once it completes
+	}
+}
+
+// maybeSnapshot takes a snapshot of the ledger and compacts the log every K
+// applied entries
+func (op *obcRaft) maybeSnapshot() {
+	if op.lastApplied < op.log.snapshot.Index+op.K {
+		return
+	}
+	term, ok := op.log.term(op.lastApplied)
+	if !ok {
+		return
+	}
+	snap := &Snapshot{Index: op.lastApplied, Term: term, BlockchainInfo: op.stack.GetBlockchainInfoBlob()}
+	logger.Infof("Replica %d taking snapshot at entry %d", op.id, snap.Index)
+	// Outstanding transactions of slow replicas may still be forwarded
+	// after their entry is discarded, so remember them for another period
+	op.compacted = make(map[string]bool)
+	for index := op.log.firstIndex(); index <= snap.Index; index++ {
+		for _, tx := range op.log.entry(index).Transactions {
+			id := txID(tx)
+			delete(op.txIDs, id)
+			op.compacted[id] = true
+		}
+	}
+	op.log.compact(snap)
+}
+
+// =============================================================================
+// snapshots and state transfer
+// =============================================================================
+
+func (op *obcRaft) handleInstallSnapshot(sender uint64, is *InstallSnapshot) {
+	op.observeTerm(is.Term)
+	if is.Term < op.term {
+		op.transport.send(sender, &Message{Payload: &Message_AppendResult{AppendResult: &AppendResult{
+			Term:       op.term,
+			MatchIndex: op.log.lastIndex(),
+		}}})
+		return
+	}
+	op.learnLeader(sender)
+	op.resetElectionTimer()
+
+	snap := is.Snapshot
+	if snap == nil {
+		return
+	}
+	if snap.Index <= op.commitIndex {
+		// Nothing we do not have already
+		op.transport.send(sender, &Message{Payload: &Message_AppendResult{AppendResult: &AppendResult{
+			Term:       op.term,
+			Success:    true,
+			MatchIndex: snap.Index,
+		}}})
+		return
+	}
+	if op.transfer != nil || op.deferred != nil {
+		logger.Debugf("Replica %d already moving to a snapshot, ignoring snapshot %d", op.id, snap.Index)
+		return
+	}
+
+	logger.Infof("Replica %d installing snapshot %d from leader %d", op.id, snap.Index, sender)
+	op.compacted = op.txIDs
+	op.txIDs = make(map[string]bool)
+	op.log.compact(snap)
+	for index := op.log.firstIndex(); index <= op.log.lastIndex(); index++ {
+		op.addTxIDs(op.log.entry(index))
+	}
+	op.commitIndex = snap.Index
+	if op.executing != nil {
+		op.deferred = snap
+		return
+	}
+	op.startTransfer(snap)
+}
+
+// startTransfer moves the ledger to the state of a snapshot
+func (op *obcRaft) startTransfer(snap *Snapshot) {
+	info := &pb.BlockchainInfo{}
+	if err := proto.Unmarshal(snap.BlockchainInfo, info); err != nil {
+		logger.Errorf("Replica %d could not unmarshal the blockchain info of snapshot %d: %s", op.id, snap.Index, err)
+		return
+	}
+	op.transfer = snap
+	op.stack.InvalidateState()
+	op.stack.UpdateState(snap, info, op.transferPeers())
+}
+
+// transferPeers lists the replicas to transfer state from, the leader first
+func (op *obcRaft) transferPeers() []*pb.PeerID {
+	var peers []*pb.PeerID
+	if op.leaderKnown && op.leader != op.id {
+		peers = append(peers, getValidatorHandle(op.leader))
+	}
+	for i := uint64(0); i < uint64(op.N); i++ {
+		if i != op.id && !(op.leaderKnown && i == op.leader) {
+			peers = append(peers, getValidatorHandle(i))
+		}
+	}
+	return peers
+}
+
+func (op *obcRaft) stateUpdated(snap *Snapshot, target *pb.BlockchainInfo) {
+	if target == nil {
+		logger.Warningf("Replica %d state transfer to snapshot %d failed, retrying", op.id, snap.Index)
+		info := &pb.BlockchainInfo{}
+		proto.Unmarshal(snap.BlockchainInfo, info)
+		op.stack.UpdateState(snap, info, op.transferPeers())
+		return
+	}
+
+	logger.Infof("Replica %d moved to snapshot %d", op.id, snap.Index)
+	op.transfer = nil
+	// Outstanding transactions may have been applied while we were gone
+	op.outstanding = make(map[string][]byte)
+	if snap.Index > op.lastApplied {
+		op.lastApplied = snap.Index
+	}
+	op.stack.ValidateState()
+	if op.leaderKnown && op.leader != op.id {
+		op.transport.send(op.leader, &Message{Payload: &Message_AppendResult{AppendResult: &AppendResult{
+			Term:       op.term,
+			Success:    true,
+			MatchIndex: snap.Index,
+		}}})
+	}
+	op.apply()
+}
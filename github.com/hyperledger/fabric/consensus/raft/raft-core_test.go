@@ -0,0 +1,310 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	logging.SetLevel(logging.WARNING, "consensus/raft")
+}
+
+func TestEnvOverride(t *testing.T) {
+	config := loadConfig()
+
+	key := "general.batchsize"
+	envName := "CORE_RAFT_GENERAL_BATCHSIZE"
+
+	// Make sure the key exists in the config file
+	if !config.IsSet(key) {
+		t.Fatalf("The key %s is not set in config.yaml", key)
+	}
+
+	os.Setenv(envName, "7")
+	defer os.Unsetenv(envName)
+
+	if config.GetInt(key) != 7 {
+		t.Errorf("Env override of %s was %d, not 7", key, config.GetInt(key))
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected a replica id outside of N to be rejected")
+		}
+	}()
+	config := loadConfig()
+	config.Set("general.N", 3)
+	newObcRaft(3, config, nil)
+}
+
+// waitReplicated waits until the running replicas have the same chain,
+// containing exactly the given transactions
+func (net *testnet) waitReplicated(t *testing.T, txIDs []string) {
+	waitFor(t, 10*time.Second, fmt.Sprintf("transactions %v on all the replicas", txIDs), func() bool {
+		var head []byte
+		for _, r := range net.replicas {
+			if op, _ := r.consenter(); op == nil {
+				continue
+			}
+			if !reflect.DeepEqual(r.transactions(), txIDs) {
+				return false
+			}
+			info := r.GetBlockchainInfo()
+			if head != nil && !bytes.Equal(head, info.CurrentBlockHash) {
+				return false
+			}
+			head = info.CurrentBlockHash
+		}
+		return true
+	})
+}
+
+func TestLeaderElection(t *testing.T) {
+	net := makeTestnet(3, nil)
+	defer net.stop()
+
+	l := net.waitLeader(t)
+
+	leaders := 0
+	for _, r := range net.replicas {
+		r.inspect(func(op *obcRaft) {
+			if op.role == leader {
+				leaders++
+			}
+			if op.log.lastIndex() == 0 {
+				t.Errorf("Replica %d did not get the no-op entry of leader %d", r.id, l.id)
+			}
+		})
+	}
+	if leaders != 1 {
+		t.Errorf("Expected exactly one leader, got %d", leaders)
+	}
+}
+
+func TestSingleReplica(t *testing.T) {
+	net := makeTestnet(1, nil)
+	defer net.stop()
+
+	net.waitLeader(t)
+	net.replicas[0].submit(createTx(1))
+	net.waitReplicated(t, []string{"tx1"})
+}
+
+func TestReplication(t *testing.T) {
+	net := makeTestnet(3, nil)
+	defer net.stop()
+
+	l := net.waitLeader(t)
+	var expected []string
+	for i := 1; i <= 5; i++ {
+		// Alternate between the replicas, followers forward to the leader
+		net.replicas[(int(l.id)+i)%3].submit(createTx(i))
+		expected = append(expected, fmt.Sprintf("tx%d", i))
+		net.waitReplicated(t, expected)
+	}
+
+	// A duplicate is not ordered again
+	net.replicas[(int(l.id)+1)%3].submit(createTx(1))
+	net.replicas[(int(l.id)+2)%3].submit(createTx(6))
+	expected = append(expected, "tx6")
+	net.waitReplicated(t, expected)
+}
+
+func TestBatching(t *testing.T) {
+	net := makeTestnet(3, func(config *viper.Viper) {
+		config.Set("general.batchsize", 3)
+		config.Set("general.timeout.batch", "10s")
+	})
+	defer net.stop()
+
+	l := net.waitLeader(t)
+	for i := 1; i <= 3; i++ {
+		l.submit(createTx(i))
+	}
+	net.waitReplicated(t, []string{"tx1", "tx2", "tx3"})
+
+	for _, r := range net.replicas {
+		if height := r.GetBlockchainSize(); height != 2 {
+			t.Errorf("Replica %d should have made one block of the batch, its height is %d", r.id, height)
+		}
+	}
+}
+
+func TestLeaderCrash(t *testing.T) {
+	net := makeTestnet(3, nil)
+	defer net.stop()
+
+	old := net.waitLeader(t)
+	old.submit(createTx(1))
+	net.waitReplicated(t, []string{"tx1"})
+
+	var oldTerm uint64
+	old.inspect(func(op *obcRaft) { oldTerm = op.term })
+	old.crash()
+
+	l := net.waitLeader(t)
+	if l == old {
+		t.Fatalf("Crashed replica %d still leader", old.id)
+	}
+	l.inspect(func(op *obcRaft) {
+		if op.term <= oldTerm {
+			t.Errorf("New leader %d is in term %d, not after the term %d of the crashed leader", l.id, op.term, oldTerm)
+		}
+	})
+
+	net.replicas[3-l.id-old.id].submit(createTx(2))
+	net.waitReplicated(t, []string{"tx1", "tx2"})
+
+	old.start()
+	net.waitReplicated(t, []string{"tx1", "tx2"})
+	if l != net.waitLeader(t) {
+		t.Errorf("Restarted replica %d should not have disrupted leader %d", old.id, l.id)
+	}
+}
+
+func TestPartitionedLeader(t *testing.T) {
+	net := makeTestnet(3, nil)
+	defer net.stop()
+
+	old := net.waitLeader(t)
+	net.isolate(old.id)
+
+	// The isolated leader appends this, but cannot commit it
+	old.submit(createTx(1))
+
+	var l *testReplica
+	waitFor(t, 10*time.Second, "a new leader", func() bool {
+		for _, r := range net.replicas {
+			if r == old {
+				continue
+			}
+			r.inspect(func(op *obcRaft) {
+				if op.role == leader {
+					l = r
+				}
+			})
+		}
+		return l != nil
+	})
+	l.submit(createTx(2))
+	waitFor(t, 10*time.Second, "tx2 on the majority", func() bool {
+		return reflect.DeepEqual(net.replicas[3-l.id-old.id].transactions(), []string{"tx2"})
+	})
+	if txs := old.transactions(); len(txs) != 0 {
+		t.Fatalf("Isolated leader executed %v", txs)
+	}
+
+	// The old leader steps down, drops its uncommitted entry and forwards
+	// the transaction to the new leader
+	net.heal()
+	net.waitReplicated(t, []string{"tx2", "tx1"})
+}
+
+func TestSnapshotCatchUp(t *testing.T) {
+	net := makeTestnet(3, func(config *viper.Viper) {
+		config.Set("general.K", 2)
+		config.Set("general.batchsize", 1)
+	})
+	defer net.stop()
+
+	l := net.waitLeader(t)
+	lagging := net.replicas[(l.id+1)%3]
+	lagging.crash()
+
+	var expected []string
+	for i := 1; i <= 6; i++ {
+		l.submit(createTx(i))
+		expected = append(expected, fmt.Sprintf("tx%d", i))
+		net.waitReplicated(t, expected)
+	}
+	l.inspect(func(op *obcRaft) {
+		if op.log.snapshot.Index < 4 {
+			t.Fatalf("Leader should have compacted its log, its snapshot is at %d", op.log.snapshot.Index)
+		}
+	})
+
+	lagging.start()
+	net.waitReplicated(t, expected)
+	waitFor(t, 10*time.Second, "the lagging replica to resume", func() bool {
+		resumed := false
+		lagging.inspect(func(op *obcRaft) {
+			resumed = op.transfer == nil && op.log.snapshot.Index > 0 && op.lastApplied == op.commitIndex
+		})
+		return resumed && lagging.isValid()
+	})
+
+	// The caught up replica takes part in ordering again
+	l.submit(createTx(7))
+	net.waitReplicated(t, append(expected, "tx7"))
+}
+
+func TestRestart(t *testing.T) {
+	net := makeTestnet(1, func(config *viper.Viper) {
+		config.Set("general.K", 3)
+		config.Set("general.batchsize", 1)
+	})
+	defer net.stop()
+
+	r := net.replicas[0]
+	net.waitLeader(t)
+	var expected []string
+	for i := 1; i <= 4; i++ {
+		r.submit(createTx(i))
+		expected = append(expected, fmt.Sprintf("tx%d", i))
+		net.waitReplicated(t, expected)
+	}
+
+	var term, first, last uint64
+	r.inspect(func(op *obcRaft) {
+		term, first, last = op.term, op.log.firstIndex(), op.log.lastIndex()
+	})
+	executions := r.executionCount()
+	r.crash()
+
+	op := &obcRaft{id: r.id, stack: r, txIDs: make(map[string]bool)}
+	op.restoreState()
+	if op.term != term {
+		t.Errorf("Restored term %d, expected %d", op.term, term)
+	}
+	if op.log.firstIndex() != first || op.log.lastIndex() != last {
+		t.Errorf("Restored log [%d, %d], expected [%d, %d]", op.log.firstIndex(), op.log.lastIndex(), first, last)
+	}
+	if op.lastApplied != last || op.commitIndex != last {
+		t.Errorf("Restored last applied %d and commit index %d, expected %d", op.lastApplied, op.commitIndex, last)
+	}
+
+	r.start()
+	net.waitLeader(t)
+	r.submit(createTx(3)) // already ordered before the crash
+	r.submit(createTx(5))
+	expected = append(expected, "tx5")
+	net.waitReplicated(t, expected)
+	if n := r.executionCount() - executions; n != 1 {
+		t.Errorf("Expected only the new transaction to be executed after restart, got %d executions", n)
+	}
+}
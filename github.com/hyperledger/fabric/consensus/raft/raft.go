@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/consensus"
+	pb "github.com/hyperledger/fabric/protos"
+
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+const configPrefix = "CORE_RAFT"
+
+var logger *logging.Logger // package-level logger
+
+// plugin registers Raft under the name "raft"
+var plugin = &consensus.Plugin{
+	Name:       "raft",
+	LoadConfig: loadConfig,
+}
+
+func init() {
+	logger = logging.MustGetLogger("consensus/raft")
+	plugin.New = newConsenter
+	consensus.RegisterPlugin(plugin)
+}
+
+// GetPlugin returns the handle to the Consenter singleton
+func GetPlugin(c consensus.Stack) consensus.Consenter {
+	return plugin.Singleton(c)
+}
+
+// New creates a new Raft replica that provides the Consenter interface
+func New(stack consensus.Stack) consensus.Consenter {
+	return newConsenter(stack, plugin.Config())
+}
+
+func newConsenter(stack consensus.Stack, config *viper.Viper) consensus.Consenter {
+	handle, _, _ := stack.GetNetworkHandles()
+	id, err := getValidatorID(handle)
+	if err != nil {
+		panic(err)
+	}
+	return newObcRaft(id, config, stack)
+}
+
+func loadConfig() (config *viper.Viper) {
+	config = viper.New()
+
+	// for environment variables
+	config.SetEnvPrefix(configPrefix)
+	config.AutomaticEnv()
+	replacer := strings.NewReplacer(".", "_")
+	config.SetEnvKeyReplacer(replacer)
+
+	config.SetConfigName("config")
+	config.AddConfigPath("./")
+	config.AddConfigPath("../consensus/raft/")
+	config.AddConfigPath("../../consensus/raft")
+	// Path to look for the config file in based on GOPATH
+	gopath := os.Getenv("GOPATH")
+	for _, p := range filepath.SplitList(gopath) {
+		raftpath := filepath.Join(p, "src/github.com/hyperledger/fabric/consensus/raft")
+		config.AddConfigPath(raftpath)
+	}
+
+	err := config.ReadInConfig()
+	if err != nil {
+		panic(fmt.Errorf("Error reading %s plugin config: %s", configPrefix, err))
+	}
+	return
+}
+
+// Returns the uint64 ID corresponding to a peer handle, validators are
+// named vpX as for PBFT
+func getValidatorID(handle *pb.PeerID) (id uint64, err error) {
+	if startsWith := strings.HasPrefix(handle.Name, "vp"); startsWith {
+		id, err = strconv.ParseUint(handle.Name[2:], 10, 64)
+		if err != nil {
+			return id, fmt.Errorf("Error extracting ID from \"%s\" handle: %v", handle.Name, err)
+		}
+		return
+	}
+
+	err = fmt.Errorf(`Set the VP's peer.id to vpX,
+		where X is a unique integer between 0 and N-1
+		(N being the number of VPs in the network)`)
+	return
+}
+
+// Returns the peer handle that corresponds to a validator ID
+func getValidatorHandle(id uint64) *pb.PeerID {
+	return &pb.PeerID{Name: "vp" + strconv.FormatUint(id, 10)}
+}
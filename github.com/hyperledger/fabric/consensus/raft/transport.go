@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package raft
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/consensus"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// transport sends messages to the other replicas without blocking the main
+// thread. Each replica has its own queue, when it is full messages to that
+// replica are dropped: Raft retries whatever is not acknowledged, and a slow
+// replica must not stall the others.
+type transport struct {
+	comm   consensus.Communicator
+	queues map[uint64]chan *pb.Message
+	closed chan struct{}
+	done   sync.WaitGroup
+}
+
+func newTransport(self uint64, N int, comm consensus.Communicator) *transport {
+	queueSize := 64
+
+	t := &transport{
+		comm:   comm,
+		queues: make(map[uint64]chan *pb.Message),
+		closed: make(chan struct{}),
+	}
+	for i := uint64(0); i < uint64(N); i++ {
+		if i != self {
+			t.queues[i] = make(chan *pb.Message, queueSize)
+		}
+	}
+	// Not started in the above loop to avoid concurrent map read/writes
+	for i, queue := range t.queues {
+		t.done.Add(1)
+		go t.drain(i, queue)
+	}
+	return t
+}
+
+func (t *transport) drain(id uint64, queue chan *pb.Message) {
+	defer t.done.Done()
+	handle := getValidatorHandle(id)
+	for {
+		select {
+		case msg := <-queue:
+			if err := t.comm.Unicast(msg, handle); err != nil {
+				logger.Debugf("Could not send message to replica %d: %s", id, err)
+			}
+		case <-t.closed:
+			return
+		}
+	}
+}
+
+// send queues msg for replica id
+func (t *transport) send(id uint64, msg *Message) {
+	queue, ok := t.queues[id]
+	if !ok {
+		logger.Errorf("Asked to send a message to unknown replica %d", id)
+		return
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		logger.Errorf("Could not marshal message: %s", err)
+		return
+	}
+	select {
+	case queue <- &pb.Message{Type: pb.Message_CONSENSUS, Payload: payload}:
+	default:
+		logger.Debugf("Queue of replica %d is full, dropping message", id)
+	}
+}
+
+// broadcast queues msg for all the other replicas
+func (t *transport) broadcast(msg *Message) {
+	for id := range t.queues {
+		t.send(id, msg)
+	}
+}
+
+func (t *transport) close() {
+	close(t.closed)
+	t.done.Wait()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consensus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Plugin describes a consensus plugin, the plugins register themselves by
+// name when their package is initialized
+type Plugin struct {
+	// Name the plugin is selected by, in peer.validator.consensus.plugin
+	Name string
+
+	// New creates the Consenter of the plugin on the stack, with the
+	// configuration returned by Config
+	New func(stack Stack, config *viper.Viper) Consenter
+
+	// LoadConfig reads the configuration of the plugin, it is nil for
+	// plugins which take no configuration
+	LoadConfig func() *viper.Viper
+
+	// Defaults are used for the settings the configuration does not set
+	Defaults map[string]interface{}
+
+	singletonLock sync.Mutex
+	singleton     Consenter // the first Consenter created by the plugin
+}
+
+var (
+	pluginsLock sync.RWMutex
+	plugins     = make(map[string]*Plugin)
+)
+
+// RegisterPlugin makes a plugin available by its name, which is case
+// insensitive. It panics if the name is empty or already registered.
+func RegisterPlugin(plugin *Plugin) {
+	name := strings.ToLower(plugin.Name)
+	if name == "" || plugin.New == nil {
+		panic("Consensus plugin must have a name and a constructor")
+	}
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+	if _, ok := plugins[name]; ok {
+		panic(fmt.Sprintf("Consensus plugin %s registered twice", name))
+	}
+	plugins[name] = plugin
+}
+
+// Plugins returns the names of the registered plugins, sorted
+func Plugins() []string {
+	pluginsLock.RLock()
+	defer pluginsLock.RUnlock()
+	var names []string
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetPlugin returns the plugin registered with a name
+func GetPlugin(name string) (*Plugin, error) {
+	pluginsLock.RLock()
+	plugin, ok := plugins[strings.ToLower(name)]
+	pluginsLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown consensus plugin \"%s\", available plugins: %s", name, strings.Join(Plugins(), ", "))
+	}
+	return plugin, nil
+}
+
+// Config loads the configuration of the plugin, completed with its defaults
+func (plugin *Plugin) Config() *viper.Viper {
+	var config *viper.Viper
+	if plugin.LoadConfig != nil {
+		config = plugin.LoadConfig()
+	} else {
+		config = viper.New()
+	}
+	for key, value := range plugin.Defaults {
+		config.SetDefault(key, value)
+	}
+	return config
+}
+
+// newConsenter creates a Consenter of the plugin on the stack. Each chain
+// hosted by the peer gets its own consenter, the first one is the singleton.
+func (plugin *Plugin) newConsenter(stack Stack) Consenter {
+	consenter := plugin.New(stack, plugin.Config())
+	plugin.singletonLock.Lock()
+	defer plugin.singletonLock.Unlock()
+	if plugin.singleton == nil {
+		plugin.singleton = consenter
+	}
+	return consenter
+}
+
+// Singleton returns the first Consenter created by the plugin, it is
+// created on the stack if the plugin created none yet
+func (plugin *Plugin) Singleton(stack Stack) Consenter {
+	plugin.singletonLock.Lock()
+	singleton := plugin.singleton
+	plugin.singletonLock.Unlock()
+	if singleton != nil {
+		return singleton
+	}
+	return plugin.newConsenter(stack)
+}
+
+// NewConsenter creates the Consenter of the plugin registered with a name
+func NewConsenter(name string, stack Stack) (Consenter, error) {
+	plugin, err := GetPlugin(name)
+	if err != nil {
+		return nil, err
+	}
+	return plugin.newConsenter(stack), nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consensus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+type testConsenter struct {
+	config *viper.Viper
+}
+
+func (tc *testConsenter) RecvMsg(msg *pb.Message, senderHandle *pb.PeerID) error  { return nil }
+func (tc *testConsenter) GetStatus() (*pb.ConsensusStatus, error)                 { return nil, nil }
+func (tc *testConsenter) Executed(tag interface{})                                {}
+func (tc *testConsenter) Committed(tag interface{}, target *pb.BlockchainInfo)    {}
+func (tc *testConsenter) RolledBack(tag interface{})                              {}
+func (tc *testConsenter) StateUpdated(tag interface{}, target *pb.BlockchainInfo) {}
+
+func TestPluginRegistry(t *testing.T) {
+	RegisterPlugin(&Plugin{
+		Name: "TestPlugin",
+		New: func(stack Stack, config *viper.Viper) Consenter {
+			return &testConsenter{config: config}
+		},
+		LoadConfig: func() *viper.Viper {
+			config := viper.New()
+			config.Set("general.batchsize", 10)
+			return config
+		},
+		Defaults: map[string]interface{}{
+			"general.batchsize": 500,
+			"general.timeout":   "2s",
+		},
+	})
+
+	consenter, err := NewConsenter("testplugin", nil)
+	if err != nil {
+		t.Fatalf("Plugin names should be case insensitive: %s", err)
+	}
+	config := consenter.(*testConsenter).config
+	if config.GetInt("general.batchsize") != 10 {
+		t.Errorf("The configuration should override the defaults of the plugin")
+	}
+	if config.GetString("general.timeout") != "2s" {
+		t.Errorf("The defaults should complete the configuration")
+	}
+
+	_, err = NewConsenter("unknown", nil)
+	if err == nil || !strings.Contains(err.Error(), "testplugin") {
+		t.Errorf("Unknown plugin should fail with the list of plugins, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Registering a plugin twice should panic")
+		}
+	}()
+	RegisterPlugin(&Plugin{Name: "testplugin", New: func(Stack, *viper.Viper) Consenter { return nil }})
+}
+
+func TestPluginSingleton(t *testing.T) {
+	plugin := &Plugin{
+		Name: "TestSingleton",
+		New: func(stack Stack, config *viper.Viper) Consenter {
+			return &testConsenter{config: config}
+		},
+	}
+	RegisterPlugin(plugin)
+
+	first, _ := NewConsenter("testsingleton", nil)
+	second, _ := NewConsenter("testsingleton", nil)
+	if first == second {
+		t.Fatalf("Each chain should get its own consenter")
+	}
+	if plugin.Singleton(nil) != first {
+		t.Errorf("The first consenter created should be the singleton")
+	}
+
+	other := &Plugin{Name: "TestOtherSingleton", New: plugin.New}
+	singleton := other.Singleton(nil)
+	if singleton == nil || other.Singleton(nil) != singleton {
+		t.Errorf("The singleton should be created once when no consenter was created")
+	}
+}
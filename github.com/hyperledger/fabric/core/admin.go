@@ -17,6 +17,7 @@ limitations under the License.
 package core
 
 import (
+	"fmt"
 	"os"
 	"runtime"
 
@@ -25,19 +26,33 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/config"
+	"github.com/hyperledger/fabric/core/peer"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
 var log = logging.MustGetLogger("server")
 
+// Stopper shuts the peer down in order
+type Stopper interface {
+	// Stop stops the peer from accepting requests and drains it. The peer
+	// process exits once the subsystems are stopped.
+	Stop() error
+}
+
 // NewAdminServer creates and returns a Admin service instance.
-func NewAdminServer() *ServerAdmin {
-	s := new(ServerAdmin)
+func NewAdminServer(consensus peer.ConsensusStatusReporter, bans peer.BanListReporter, traffic peer.PeersTrafficReporter, validators peer.ValidatorReconfigurer, stopper Stopper) *ServerAdmin {
+	s := &ServerAdmin{consensus: consensus, bans: bans, traffic: traffic, validators: validators, stopper: stopper}
 	return s
 }
 
 // ServerAdmin implementation of the Admin service for the Peer
 type ServerAdmin struct {
+	consensus  peer.ConsensusStatusReporter
+	bans       peer.BanListReporter
+	traffic    peer.PeersTrafficReporter
+	validators peer.ValidatorReconfigurer
+	stopper    Stopper
 }
 
 func worker(id int, die chan struct{}) {
@@ -67,9 +82,89 @@ func (*ServerAdmin) StartServer(context.Context, *empty.Empty) (*pb.ServerStatus
 	return status, nil
 }
 
-// StopServer stops the server
-func (*ServerAdmin) StopServer(context.Context, *empty.Empty) (*pb.ServerStatus, error) {
+// GetConsensusStatus reports a snapshot of the state of the consensus plugin
+// of the chain of the request
+func (s *ServerAdmin) GetConsensusStatus(ctx context.Context, req *pb.ConsensusStatusRequest) (*pb.ConsensusStatus, error) {
+	if s.consensus == nil {
+		return nil, fmt.Errorf("Consensus status is not available on this peer")
+	}
+	status, err := peer.GetConsensusStatusOf(s.consensus, req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("returning consensus status: %s", status)
+	return status, nil
+}
+
+// GetBannedPeers reports the remote peers banned for misbehaving
+func (s *ServerAdmin) GetBannedPeers(context.Context, *empty.Empty) (*pb.BannedPeers, error) {
+	if s.bans == nil {
+		return nil, fmt.Errorf("Banned peers are not available on this peer")
+	}
+	bans, err := s.bans.GetBannedPeers()
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("returning banned peers: %s", bans)
+	return bans, nil
+}
+
+// GetPeersTraffic reports the traffic to the connected peers
+func (s *ServerAdmin) GetPeersTraffic(context.Context, *empty.Empty) (*pb.PeersTraffic, error) {
+	if s.traffic == nil {
+		return nil, fmt.Errorf("Peers traffic is not available on this peer")
+	}
+	traffic, err := s.traffic.GetPeersTraffic()
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("returning peers traffic: %s", traffic)
+	return traffic, nil
+}
+
+// ReloadConfig reads the configuration file again and applies the changes
+// which do not require a restart of the peer
+func (*ServerAdmin) ReloadConfig(context.Context, *empty.Empty) (*pb.ConfigReload, error) {
+	result, err := config.Reload()
+	if err != nil {
+		return nil, err
+	}
+	reload := &pb.ConfigReload{Applied: result.Applied, RestartRequired: result.RestartRequired}
+	log.Debugf("returning config reload: %s", reload)
+	return reload, nil
+}
+
+// GetHealth runs the health and readiness checks of the peer
+func (*ServerAdmin) GetHealth(context.Context, *empty.Empty) (*pb.Health, error) {
+	health := CheckHealth()
+	log.Debugf("returning health: %s", health)
+	return health, nil
+}
+
+// ReconfigureValidators orders the addition or removal of a validator, as
+// signed by administrators
+func (s *ServerAdmin) ReconfigureValidators(ctx context.Context, req *pb.ValidatorReconfiguration) (*empty.Empty, error) {
+	if s.validators == nil {
+		return nil, fmt.Errorf("Changing the set of validators is not available on this peer")
+	}
+	if err := s.validators.ReconfigureValidators(req); err != nil {
+		return nil, err
+	}
+	log.Infof("Ordered %s of validator %s", req.Action, req.Validator)
+	return &empty.Empty{}, nil
+}
+
+// StopServer stops the server. With a Stopper the peer is drained before
+// the status is returned, otherwise the process exits right away.
+func (s *ServerAdmin) StopServer(context.Context, *empty.Empty) (*pb.ServerStatus, error) {
 	status := &pb.ServerStatus{Status: pb.ServerStatus_STOPPED}
+	if s.stopper != nil {
+		if err := s.stopper.Stop(); err != nil {
+			return nil, fmt.Errorf("Peer stopped without draining: %s", err)
+		}
+		log.Debugf("returning status: %s", status)
+		return status, nil
+	}
 	log.Debugf("returning status: %s", status)
 
 	pidFile := viper.GetString("peer.fileSystemPath") + "/peer.pid"
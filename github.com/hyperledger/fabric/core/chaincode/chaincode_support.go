@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -30,9 +31,13 @@ import (
 
 	"strings"
 
+	"github.com/hyperledger/fabric/core/chaincode/policy"
+	"github.com/hyperledger/fabric/core/chaincode/replay"
+	"github.com/hyperledger/fabric/core/chaincode/validation"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/crypto"
+	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger"
 	pb "github.com/hyperledger/fabric/protos"
 )
@@ -46,6 +51,7 @@ const (
 	// DevModeUserRunsChaincode property allows user to run chaincode in development environment
 	DevModeUserRunsChaincode       string = "dev"
 	chaincodeStartupTimeoutDefault int    = 5000
+	chaincodeExecuteTimeoutDefault int    = 30000
 	chaincodeInstallPathDefault    string = "/opt/gopath/bin/"
 	peerAddressDefault             string = "0.0.0.0:7051"
 )
@@ -64,11 +70,54 @@ type chaincodeRTEnv struct {
 	handler *Handler
 }
 
-// runningChaincodes contains maps of chaincodeIDs to their chaincodeRTEs
+// chaincodeKey identifies a chaincode deployed on a chain. A chaincode deployed
+// on several chains runs in a container per chain, initialized by the deploy
+// transaction of that chain.
+type chaincodeKey struct {
+	chainID string // Normalized, see db.NormalizeChainID
+	name    string
+}
+
+func newChaincodeKey(chainID, name string) chaincodeKey {
+	return chaincodeKey{chainID: db.NormalizeChainID(chainID), name: name}
+}
+
+// String returns the name the chaincode container registers with: the name of
+// the chaincode on the default chain, unless it contains '@', and name@chainID
+// otherwise. In development mode, this is the name the chaincode is started with.
+func (k chaincodeKey) String() string {
+	if k.chainID == db.DefaultChainID && !strings.Contains(k.name, "@") {
+		return k.name
+	}
+	return k.name + "@" + k.chainID
+}
+
+// parseChaincodeKey returns the key of the chaincode a container registered as
+func parseChaincodeKey(registered string) chaincodeKey {
+	if i := strings.LastIndex(registered, "@"); i >= 0 && db.ValidateChainID(registered[i+1:]) == nil {
+		return newChaincodeKey(registered[i+1:], registered[:i])
+	}
+	return newChaincodeKey("", registered)
+}
+
+// vmChainID returns the chain which names the container of a chaincode, the
+// containers of the default chain keep their name
+func (k chaincodeKey) vmChainID() string {
+	if k.chainID == db.DefaultChainID {
+		return ""
+	}
+	return k.chainID
+}
+
+// runningChaincodes contains maps of chaincodes deployed on a chain to their chaincodeRTEs
 type runningChaincodes struct {
 	sync.RWMutex
 	// chaincode environment for each chaincode
-	chaincodeMap map[string]*chaincodeRTEnv
+	chaincodeMap map[chaincodeKey]*chaincodeRTEnv
+	// execution timeout requested by the deploy transaction of each chaincode
+	deployedTimeouts map[chaincodeKey]time.Duration
+	// deployment spec of each chaincode container launched by the peer
+	launched map[chaincodeKey]*pb.ChaincodeDeploymentSpec
 }
 
 // GetChain returns the chaincode support for a given chain
@@ -77,27 +126,39 @@ func GetChain(name ChainName) *ChaincodeSupport {
 }
 
 //call this under lock
-func (chaincodeSupport *ChaincodeSupport) preLaunchSetup(chaincode string) chan bool {
+func (chaincodeSupport *ChaincodeSupport) preLaunchSetup(key chaincodeKey) chan bool {
 	//register placeholder Handler. This will be transferred in registerHandler
 	//NOTE: from this point, existence of handler for this chaincode means the chaincode
 	//is in the process of getting started (or has been started)
 	notfy := make(chan bool, 1)
-	chaincodeSupport.runningChaincodes.chaincodeMap[chaincode] = &chaincodeRTEnv{handler: &Handler{readyNotify: notfy}}
+	chaincodeSupport.runningChaincodes.chaincodeMap[key] = &chaincodeRTEnv{handler: &Handler{readyNotify: notfy}}
 	return notfy
 }
 
 //call this under lock
-func (chaincodeSupport *ChaincodeSupport) chaincodeHasBeenLaunched(chaincode string) (*chaincodeRTEnv, bool) {
-	chrte, hasbeenlaunched := chaincodeSupport.runningChaincodes.chaincodeMap[chaincode]
+func (chaincodeSupport *ChaincodeSupport) chaincodeHasBeenLaunched(key chaincodeKey) (*chaincodeRTEnv, bool) {
+	chrte, hasbeenlaunched := chaincodeSupport.runningChaincodes.chaincodeMap[key]
 	return chrte, hasbeenlaunched
 }
 
+// keyOf returns the key of the chaincode a transaction on a chain runs on.
+// System chaincodes run in the peer process once, for all the chains.
+func (chaincodeSupport *ChaincodeSupport) keyOf(chainID, chaincode string) chaincodeKey {
+	chaincodeSupport.runningChaincodes.RLock()
+	defer chaincodeSupport.runningChaincodes.RUnlock()
+	key := newChaincodeKey(chainID, chaincode)
+	if cds, ok := chaincodeSupport.runningChaincodes.launched[newChaincodeKey("", chaincode)]; ok && cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM {
+		return newChaincodeKey("", chaincode)
+	}
+	return key
+}
+
 // NewChaincodeSupport creates a new ChaincodeSupport instance
 func NewChaincodeSupport(chainname ChainName, getPeerEndpoint func() (*pb.PeerEndpoint, error), userrunsCC bool, ccstartuptimeout time.Duration, secHelper crypto.Peer) *ChaincodeSupport {
 	pnid := viper.GetString("peer.networkId")
 	pid := viper.GetString("peer.id")
 
-	s := &ChaincodeSupport{name: chainname, runningChaincodes: &runningChaincodes{chaincodeMap: make(map[string]*chaincodeRTEnv)}, secHelper: secHelper, peerNetworkID: pnid, peerID: pid}
+	s := &ChaincodeSupport{name: chainname, runningChaincodes: &runningChaincodes{chaincodeMap: make(map[chaincodeKey]*chaincodeRTEnv), deployedTimeouts: make(map[chaincodeKey]time.Duration), launched: make(map[chaincodeKey]*pb.ChaincodeDeploymentSpec)}, secHelper: secHelper, peerNetworkID: pnid, peerID: pid}
 
 	//initialize global chain
 	chains[chainname] = s
@@ -147,9 +208,92 @@ func NewChaincodeSupport(chainname ChainName, getPeerEndpoint func() (*pb.PeerEn
 		s.keepalive = time.Duration(t) * time.Second
 	}
 
+	s.executeTimeout, s.maxExecuteTimeout = getExecuteTimeoutsConfig()
+
+	if s.recorder, err = replay.NewRecorderFromConfig(); err != nil {
+		chaincodeLogger.Errorf("Chaincode message recording disabled: %s", err)
+	} else if s.recorder != nil {
+		chaincodeLogger.Infof("Recording chaincode messages to %s", viper.GetString("chaincode.recorder.file"))
+	}
+
+	if s.policy, err = policy.LoadFromConfig(); err != nil {
+		// Do not let a broken policy open the peer to everyone
+		chaincodeLogger.Errorf("Denying all chaincode transactions: %s", err)
+		s.policy = policy.DenyAll()
+	} else if s.policy != nil {
+		chaincodeLogger.Infof("Enforcing chaincode policy with %d rules", len(s.policy.Rules))
+	}
+
+	if s.validators, err = validation.NewManagerFromConfig(); err != nil {
+		// Every validator must apply the same checks, reject rather than
+		// accept what the others may refuse
+		chaincodeLogger.Errorf("Rejecting all chaincode transactions: %s", err)
+		s.validationErr = fmt.Errorf("Validation plugins not available: %s", err)
+	}
+
 	return s
 }
 
+// getExecuteTimeoutsConfig reads chaincode.executetimeout and
+// chaincode.maxexecutetimeout from the configuration
+func getExecuteTimeoutsConfig() (executeTimeout, maxExecuteTimeout time.Duration) {
+	executeTimeout = getTimeoutConfig("chaincode.executetimeout", chaincodeExecuteTimeoutDefault)
+	maxExecuteTimeout = getTimeoutConfig("chaincode.maxexecutetimeout", 0)
+	if maxExecuteTimeout > 0 && executeTimeout > maxExecuteTimeout {
+		chaincodeLogger.Warningf("Execute timeout %s exceeds maximum execute timeout %s, using the maximum", executeTimeout, maxExecuteTimeout)
+		executeTimeout = maxExecuteTimeout
+	}
+	return
+}
+
+// ValidateTimeouts checks the chaincode timeouts of a new configuration
+func ValidateTimeouts(v *viper.Viper) error {
+	for _, key := range []string{"chaincode.startuptimeout", "chaincode.executetimeout", "chaincode.maxexecutetimeout"} {
+		if to := v.GetString(key); to != "" {
+			if t, err := strconv.Atoi(to); err != nil || t < 0 {
+				return fmt.Errorf("Invalid %s value %s, must be a number of milliseconds", key, to)
+			}
+		}
+	}
+	return nil
+}
+
+// ReloadTimeouts applies the chaincode timeouts of the configuration to
+// the chaincode support of every chain. They apply to the chaincodes
+// started and the transactions executed from now on.
+func ReloadTimeouts() {
+	startupTimeout := getTimeoutConfig("chaincode.startuptimeout", chaincodeStartupTimeoutDefault)
+	executeTimeout, maxExecuteTimeout := getExecuteTimeoutsConfig()
+	for _, chaincodeSupport := range chains {
+		chaincodeSupport.timeoutsLock.Lock()
+		chaincodeSupport.ccStartupTimeout = startupTimeout
+		chaincodeSupport.executeTimeout = executeTimeout
+		chaincodeSupport.maxExecuteTimeout = maxExecuteTimeout
+		chaincodeSupport.timeoutsLock.Unlock()
+	}
+	chaincodeLogger.Infof("Chaincode startup timeout %s, execute timeout %s, maximum execute timeout %s", startupTimeout, executeTimeout, maxExecuteTimeout)
+}
+
+// getStartupTimeout returns how long a chaincode may take to start
+func (chaincodeSupport *ChaincodeSupport) getStartupTimeout() time.Duration {
+	chaincodeSupport.timeoutsLock.RLock()
+	defer chaincodeSupport.timeoutsLock.RUnlock()
+	return chaincodeSupport.ccStartupTimeout
+}
+
+// getTimeoutConfig reads a timeout in milliseconds from the configuration
+func getTimeoutConfig(key string, def int) time.Duration {
+	t := def
+	if to := viper.GetString(key); to != "" {
+		var err error
+		if t, err = strconv.Atoi(to); err != nil || t < 0 {
+			chaincodeLogger.Errorf("Invalid %s value %s defaulting to %d", key, to, def)
+			t = def
+		}
+	}
+	return time.Duration(t) * time.Millisecond
+}
+
 // // ChaincodeStream standard stream for ChaincodeMessage type.
 // type ChaincodeStream interface {
 // 	Send(*pb.ChaincodeMessage) error
@@ -172,6 +316,13 @@ type ChaincodeSupport struct {
 	peerTLSKeyFile       string
 	peerTLSSvrHostOrd    string
 	keepalive            time.Duration
+	timeoutsLock         sync.RWMutex // Guards the timeouts, which are reloaded with the configuration
+	executeTimeout       time.Duration
+	maxExecuteTimeout    time.Duration
+	recorder             *replay.Recorder
+	policy               *policy.Policy
+	validators           *validation.Manager
+	validationErr        error
 }
 
 // DuplicateChaincodeHandlerError returned if attempt to register same chaincodeID while a stream already exists.
@@ -188,7 +339,10 @@ func newDuplicateChaincodeHandlerError(chaincodeHandler *Handler) error {
 }
 
 func (chaincodeSupport *ChaincodeSupport) registerHandler(chaincodehandler *Handler) error {
-	key := chaincodehandler.ChaincodeID.Name
+	// The container registers with the chain it serves, the handler works with the chaincode name
+	key := parseChaincodeKey(chaincodehandler.ChaincodeID.Name)
+	chaincodehandler.ChaincodeID = &pb.ChaincodeID{Path: chaincodehandler.ChaincodeID.Path, Name: key.name}
+	chaincodehandler.chainID = key.chainID
 
 	chaincodeSupport.runningChaincodes.Lock()
 	defer chaincodeSupport.runningChaincodes.Unlock()
@@ -229,7 +383,7 @@ func (chaincodeSupport *ChaincodeSupport) deregisterHandler(chaincodehandler *Ha
 		}
 	}
 
-	key := chaincodehandler.ChaincodeID.Name
+	key := newChaincodeKey(chaincodehandler.chainID, chaincodehandler.ChaincodeID.Name)
 	chaincodeLogger.Debugf("Deregister handler: %s", key)
 	chaincodeSupport.runningChaincodes.Lock()
 	defer chaincodeSupport.runningChaincodes.Unlock()
@@ -243,15 +397,15 @@ func (chaincodeSupport *ChaincodeSupport) deregisterHandler(chaincodehandler *Ha
 }
 
 // Based on state of chaincode send either init or ready to move to ready state
-func (chaincodeSupport *ChaincodeSupport) sendInitOrReady(context context.Context, txid string, chaincode string, initArgs [][]byte, timeout time.Duration, tx *pb.Transaction, depTx *pb.Transaction) error {
+func (chaincodeSupport *ChaincodeSupport) sendInitOrReady(context context.Context, txid string, key chaincodeKey, initArgs [][]byte, timeout time.Duration, tx *pb.Transaction, depTx *pb.Transaction) error {
 	chaincodeSupport.runningChaincodes.Lock()
 	//if its in the map, there must be a connected stream...nothing to do
 	var chrte *chaincodeRTEnv
 	var ok bool
-	if chrte, ok = chaincodeSupport.chaincodeHasBeenLaunched(chaincode); !ok {
+	if chrte, ok = chaincodeSupport.chaincodeHasBeenLaunched(key); !ok {
 		chaincodeSupport.runningChaincodes.Unlock()
-		chaincodeLogger.Debugf("handler not found for chaincode %s", chaincode)
-		return fmt.Errorf("handler not found for chaincode %s", chaincode)
+		chaincodeLogger.Debugf("handler not found for chaincode %s", key)
+		return fmt.Errorf("handler not found for chaincode %s", key)
 	}
 	chaincodeSupport.runningChaincodes.Unlock()
 
@@ -264,7 +418,7 @@ func (chaincodeSupport *ChaincodeSupport) sendInitOrReady(context context.Contex
 		select {
 		case ccMsg := <-notfy:
 			if ccMsg.Type == pb.ChaincodeMessage_ERROR {
-				err = fmt.Errorf("Error initializing container %s: %s", chaincode, string(ccMsg.Payload))
+				err = fmt.Errorf("Error initializing container %s: %s", key, string(ccMsg.Payload))
 			}
 		case <-time.After(timeout):
 			err = fmt.Errorf("Timeout expired while executing send init message")
@@ -277,9 +431,9 @@ func (chaincodeSupport *ChaincodeSupport) sendInitOrReady(context context.Contex
 	return err
 }
 
-//get args and env given chaincodeID
-func (chaincodeSupport *ChaincodeSupport) getArgsAndEnv(cID *pb.ChaincodeID, cLang pb.ChaincodeSpec_Type) (args []string, envs []string, err error) {
-	envs = []string{"CORE_CHAINCODE_ID_NAME=" + cID.Name}
+//get args and env given chaincodeID and the chain the chaincode serves
+func (chaincodeSupport *ChaincodeSupport) getArgsAndEnv(key chaincodeKey, cID *pb.ChaincodeID, cLang pb.ChaincodeSpec_Type) (args []string, envs []string, err error) {
+	envs = []string{"CORE_CHAINCODE_ID_NAME=" + key.String()}
 	//if TLS is enabled, pass TLS material to chaincode
 	if chaincodeSupport.peerTLS {
 		envs = append(envs, "CORE_PEER_TLS_ENABLED=true")
@@ -299,7 +453,7 @@ func (chaincodeSupport *ChaincodeSupport) getArgsAndEnv(cID *pb.ChaincodeID, cLa
 		//TODO add security args
 		args = strings.Split(
 			fmt.Sprintf("java -jar chaincode.jar -a %s -i %s",
-				chaincodeSupport.peerAddress, cID.Name),
+				chaincodeSupport.peerAddress, key),
 			" ")
 		if chaincodeSupport.peerTLS {
 			args = append(args, " -s")
@@ -312,28 +466,28 @@ func (chaincodeSupport *ChaincodeSupport) getArgsAndEnv(cID *pb.ChaincodeID, cLa
 }
 
 // launchAndWaitForRegister will launch container if not already running. Use the targz to create the image if not found
-func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.Context, cds *pb.ChaincodeDeploymentSpec, cID *pb.ChaincodeID, txid string, cLang pb.ChaincodeSpec_Type, targz io.Reader) (bool, error) {
-	chaincode := cID.Name
-	if chaincode == "" {
+func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.Context, key chaincodeKey, cds *pb.ChaincodeDeploymentSpec, cID *pb.ChaincodeID, txid string, cLang pb.ChaincodeSpec_Type, targz io.Reader) (bool, error) {
+	chaincode := key.String()
+	if cID.Name == "" {
 		return false, fmt.Errorf("chaincode name not set")
 	}
 
 	chaincodeSupport.runningChaincodes.Lock()
 	var ok bool
 	//if its in the map, there must be a connected stream...nothing to do
-	if _, ok = chaincodeSupport.chaincodeHasBeenLaunched(chaincode); ok {
+	if _, ok = chaincodeSupport.chaincodeHasBeenLaunched(key); ok {
 		chaincodeLogger.Debugf("chaincode is running and ready: %s", chaincode)
 		chaincodeSupport.runningChaincodes.Unlock()
 		return true, nil
 	}
 	alreadyRunning := false
 
-	notfy := chaincodeSupport.preLaunchSetup(chaincode)
+	notfy := chaincodeSupport.preLaunchSetup(key)
 	chaincodeSupport.runningChaincodes.Unlock()
 
 	//launch the chaincode
 
-	args, env, err := chaincodeSupport.getArgsAndEnv(cID, cLang)
+	args, env, err := chaincodeSupport.getArgsAndEnv(key, cID, cLang)
 	if err != nil {
 		return alreadyRunning, err
 	}
@@ -342,7 +496,7 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 
 	vmtype, _ := chaincodeSupport.getVMType(cds)
 
-	sir := container.StartImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID}, Reader: targz, Args: args, Env: env}
+	sir := container.StartImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, ChainID: key.vmChainID()}, Reader: targz, Args: args, Env: env}
 
 	ipcCtxt := context.WithValue(ctxt, ccintf.GetCCHandlerKey(), chaincodeSupport)
 
@@ -353,7 +507,7 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 		}
 		err = fmt.Errorf("Error starting container: %s", err)
 		chaincodeSupport.runningChaincodes.Lock()
-		delete(chaincodeSupport.runningChaincodes.chaincodeMap, chaincode)
+		delete(chaincodeSupport.runningChaincodes.chaincodeMap, key)
 		chaincodeSupport.runningChaincodes.Unlock()
 		return alreadyRunning, err
 	}
@@ -364,12 +518,12 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 		if !ok {
 			err = fmt.Errorf("registration failed for %s(networkid:%s,peerid:%s,tx:%s)", chaincode, chaincodeSupport.peerNetworkID, chaincodeSupport.peerID, txid)
 		}
-	case <-time.After(chaincodeSupport.ccStartupTimeout):
+	case <-time.After(chaincodeSupport.getStartupTimeout()):
 		err = fmt.Errorf("Timeout expired while starting chaincode %s(networkid:%s,peerid:%s,tx:%s)", chaincode, chaincodeSupport.peerNetworkID, chaincodeSupport.peerID, txid)
 	}
 	if err != nil {
 		chaincodeLogger.Debugf("stopping due to error while launching %s", err)
-		errIgnore := chaincodeSupport.Stop(ctxt, cds)
+		errIgnore := chaincodeSupport.stop(ctxt, key, cds)
 		if errIgnore != nil {
 			chaincodeLogger.Debugf("error on stop %s(%s)", errIgnore, err)
 		}
@@ -377,15 +531,18 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 	return alreadyRunning, err
 }
 
-//Stop stops a chaincode if running
+//Stop stops a chaincode on the chain of its spec if running
 func (chaincodeSupport *ChaincodeSupport) Stop(context context.Context, cds *pb.ChaincodeDeploymentSpec) error {
-	chaincode := cds.ChaincodeSpec.ChaincodeID.Name
-	if chaincode == "" {
+	return chaincodeSupport.stop(context, newChaincodeKey(cds.ChaincodeSpec.ChainID, cds.ChaincodeSpec.ChaincodeID.Name), cds)
+}
+
+func (chaincodeSupport *ChaincodeSupport) stop(context context.Context, key chaincodeKey, cds *pb.ChaincodeDeploymentSpec) error {
+	if key.name == "" {
 		return fmt.Errorf("chaincode name not set")
 	}
 
 	//stop the chaincode
-	sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID}, Timeout: 0}
+	sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, ChainID: key.vmChainID()}, Timeout: 0}
 
 	vmtype, _ := chaincodeSupport.getVMType(cds)
 
@@ -396,19 +553,88 @@ func (chaincodeSupport *ChaincodeSupport) Stop(context context.Context, cds *pb.
 	}
 
 	chaincodeSupport.runningChaincodes.Lock()
-	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched(chaincode); !ok {
+	delete(chaincodeSupport.runningChaincodes.launched, key)
+	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched(key); !ok {
 		//nothing to do
 		chaincodeSupport.runningChaincodes.Unlock()
 		return nil
 	}
 
-	delete(chaincodeSupport.runningChaincodes.chaincodeMap, chaincode)
+	delete(chaincodeSupport.runningChaincodes.chaincodeMap, key)
 
 	chaincodeSupport.runningChaincodes.Unlock()
 
 	return err
 }
 
+// StopAll stops the chaincode containers launched by the peer. System
+// chaincodes run in the peer process and are left running.
+func (chaincodeSupport *ChaincodeSupport) StopAll(context context.Context) error {
+	chaincodeSupport.runningChaincodes.Lock()
+	launched := make(map[chaincodeKey]*pb.ChaincodeDeploymentSpec, len(chaincodeSupport.runningChaincodes.launched))
+	for key, cds := range chaincodeSupport.runningChaincodes.launched {
+		if cds.ExecEnv != pb.ChaincodeDeploymentSpec_SYSTEM {
+			launched[key] = cds
+		}
+		delete(chaincodeSupport.runningChaincodes.launched, key)
+	}
+	chaincodeSupport.runningChaincodes.Unlock()
+
+	var errs []string
+	for key, cds := range launched {
+		chaincodeLogger.Infof("Stopping chaincode %s", key)
+		if err := chaincodeSupport.stop(context, key, cds); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", key, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Error stopping chaincodes %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// StopChaincodes stops the chaincode containers launched by the peer for
+// all the chains
+func StopChaincodes(context context.Context) error {
+	var errs []string
+	for _, chain := range chains {
+		if err := chain.StopAll(context); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// NotRegistered returns the names of the chaincodes launched by the peer
+// whose container is not registered with it, because the container exited
+// or lost its stream. They are launched again on their next transaction.
+// The chaincodes of chains other than the default one are named name@chainID.
+func (chaincodeSupport *ChaincodeSupport) NotRegistered() []string {
+	chaincodeSupport.runningChaincodes.Lock()
+	defer chaincodeSupport.runningChaincodes.Unlock()
+	var names []string
+	for key := range chaincodeSupport.runningChaincodes.launched {
+		if chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(key); !ok || chrte.handler == nil {
+			names = append(names, key.String())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ChaincodesNotRegistered returns the names of the chaincodes launched by
+// the peer whose container is not registered with it, for all the chains
+func ChaincodesNotRegistered() []string {
+	var names []string
+	for _, chain := range chains {
+		names = append(names, chain.NotRegistered()...)
+	}
+	return names
+}
+
 // Launch will launch the chaincode if not running (if running return nil) and will wait for handler of the chaincode to get into FSM ready state.
 func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.Transaction) (*pb.ChaincodeID, *pb.ChaincodeInput, error) {
 	//build the chaincode
@@ -440,20 +666,25 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.
 		return nil, nil, fmt.Errorf("invalid transaction type: %d", t.Type)
 	}
 	chaincode := cID.Name
+	// The chaincode is launched and initialized for the chain of the transaction
+	key := chaincodeSupport.keyOf(t.ChainID, chaincode)
+	if t.Type == pb.Transaction_CHAINCODE_DEPLOY {
+		chaincodeSupport.setDeployedTimeout(key, cds.ChaincodeSpec.Timeout)
+	}
 	chaincodeSupport.runningChaincodes.Lock()
 	var chrte *chaincodeRTEnv
 	var ok bool
 	var err error
 	//if its in the map, there must be a connected stream...nothing to do
-	if chrte, ok = chaincodeSupport.chaincodeHasBeenLaunched(chaincode); ok {
+	if chrte, ok = chaincodeSupport.chaincodeHasBeenLaunched(key); ok {
 		if !chrte.handler.registered {
 			chaincodeSupport.runningChaincodes.Unlock()
-			chaincodeLogger.Debugf("premature execution - chaincode (%s) is being launched", chaincode)
-			err = fmt.Errorf("premature execution - chaincode (%s) is being launched", chaincode)
+			chaincodeLogger.Debugf("premature execution - chaincode (%s) is being launched", key)
+			err = fmt.Errorf("premature execution - chaincode (%s) is being launched", key)
 			return cID, cMsg, err
 		}
 		if chrte.handler.isRunning() {
-			chaincodeLogger.Debugf("chaincode is running(no need to launch) : %s", chaincode)
+			chaincodeLogger.Debugf("chaincode is running(no need to launch) : %s", key)
 			chaincodeSupport.runningChaincodes.Unlock()
 			return cID, cMsg, nil
 		}
@@ -476,7 +707,7 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.
 	// See issue #710
 
 	if t.Type != pb.Transaction_CHAINCODE_DEPLOY {
-		ledger, ledgerErr := ledger.GetLedger()
+		ledger, ledgerErr := ledger.GetChainLedger(key.chainID)
 
 		if chaincodeSupport.userRunsCC {
 			chaincodeLogger.Error("You are attempting to perform an action other than Deploy on Chaincode that is not ready and you are in developer mode. Did you forget to Deploy your chaincode?")
@@ -508,6 +739,7 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.
 			return cID, cMsg, fmt.Errorf("failed to unmarshal deployment transactions for %s - %s", chaincode, err)
 		}
 		cLang = cds.ChaincodeSpec.Type
+		chaincodeSupport.setDeployedTimeout(key, cds.ChaincodeSpec.Timeout)
 	}
 
 	//from here on : if we launch the container and get an error, we need to stop the container
@@ -515,20 +747,30 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.
 	//launch container if it is a System container or not in dev mode
 	if (!chaincodeSupport.userRunsCC || cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM) && (chrte == nil || chrte.handler == nil) {
 		var targz io.Reader = bytes.NewBuffer(cds.CodePackage)
-		_, err = chaincodeSupport.launchAndWaitForRegister(context, cds, cID, t.Txid, cLang, targz)
+		_, err = chaincodeSupport.launchAndWaitForRegister(context, key, cds, cID, t.Txid, cLang, targz)
 		if err != nil {
 			chaincodeLogger.Errorf("launchAndWaitForRegister failed %s", err)
 			return cID, cMsg, err
 		}
+		chaincodeSupport.runningChaincodes.Lock()
+		chaincodeSupport.runningChaincodes.launched[key] = cds
+		chaincodeSupport.runningChaincodes.Unlock()
 	}
 
 	if err == nil {
+		//the deploy transaction may ask for more (or less) time to run Init
+		//than the startup timeout
+		timeout := chaincodeSupport.getStartupTimeout()
+		if initargs != nil && cds.ChaincodeSpec.Timeout > 0 {
+			timeout = chaincodeSupport.GetExecuteTimeout(key.chainID, chaincode, cds.ChaincodeSpec.Timeout)
+		}
+
 		//send init (if (args)) and wait for ready state
-		err = chaincodeSupport.sendInitOrReady(context, t.Txid, chaincode, initargs, chaincodeSupport.ccStartupTimeout, t, depTx)
+		err = chaincodeSupport.sendInitOrReady(context, t.Txid, key, initargs, timeout, t, depTx)
 		if err != nil {
 			chaincodeLogger.Errorf("sending init failed(%s)", err)
 			err = fmt.Errorf("Failed to init chaincode(%s)", err)
-			errIgnore := chaincodeSupport.Stop(context, cds)
+			errIgnore := chaincodeSupport.stop(context, key, cds)
 			if errIgnore != nil {
 				chaincodeLogger.Errorf("stop failed %s(%s)", errIgnore, err)
 			}
@@ -541,6 +783,184 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, t *pb.
 	return cID, cMsg, err
 }
 
+// setDeployedTimeout remembers the execution timeout, in milliseconds, set in the
+// ChaincodeSpec of the deploy transaction of a chaincode on a chain
+func (chaincodeSupport *ChaincodeSupport) setDeployedTimeout(key chaincodeKey, timeout int32) {
+	chaincodeSupport.runningChaincodes.Lock()
+	defer chaincodeSupport.runningChaincodes.Unlock()
+	if timeout > 0 {
+		chaincodeSupport.runningChaincodes.deployedTimeouts[key] = time.Duration(timeout) * time.Millisecond
+	} else {
+		delete(chaincodeSupport.runningChaincodes.deployedTimeouts, key)
+	}
+}
+
+// GetExecuteTimeout returns how long a transaction or query may run on
+// chaincode on the chain chainID. The timeout requested, in milliseconds, in
+// the ChaincodeSpec of the invocation takes precedence over the one recorded
+// at deploy on the chain, which takes precedence over chaincode.executetimeout.
+// The result never exceeds chaincode.maxexecutetimeout.
+func (chaincodeSupport *ChaincodeSupport) GetExecuteTimeout(chainID, chaincode string, requested int32) time.Duration {
+	var timeout time.Duration
+	if requested > 0 {
+		timeout = time.Duration(requested) * time.Millisecond
+	} else {
+		key := chaincodeSupport.keyOf(chainID, chaincode)
+		chaincodeSupport.runningChaincodes.RLock()
+		timeout = chaincodeSupport.runningChaincodes.deployedTimeouts[key]
+		chaincodeSupport.runningChaincodes.RUnlock()
+	}
+	return chaincodeSupport.capTimeout(timeout)
+}
+
+// capTimeout applies the peer policy to a timeout: it defaults to
+// chaincode.executetimeout and may not exceed chaincode.maxexecutetimeout
+func (chaincodeSupport *ChaincodeSupport) capTimeout(timeout time.Duration) time.Duration {
+	chaincodeSupport.timeoutsLock.RLock()
+	defer chaincodeSupport.timeoutsLock.RUnlock()
+	if timeout <= 0 {
+		timeout = chaincodeSupport.executeTimeout
+	}
+	if chaincodeSupport.maxExecuteTimeout > 0 && timeout > chaincodeSupport.maxExecuteTimeout {
+		chaincodeLogger.Debugf("Capping timeout %s to %s", timeout, chaincodeSupport.maxExecuteTimeout)
+		timeout = chaincodeSupport.maxExecuteTimeout
+	}
+	return timeout
+}
+
+// Authorize checks the decrypted transaction t against the chaincode policy
+// and returns an error if its creator may not perform it. All transactions
+// are authorized when no policy is configured.
+func (chaincodeSupport *ChaincodeSupport) Authorize(t *pb.Transaction) error {
+	if chaincodeSupport.policy == nil {
+		return nil
+	}
+	op, err := policy.OperationOf(t.Type)
+	if err != nil {
+		return err
+	}
+
+	cID, system, err := chaincodeSupport.getChaincodeID(t)
+	if err != nil {
+		return err
+	}
+	if system {
+		return nil
+	}
+
+	var id *policy.Identity
+	if len(t.Cert) > 0 {
+		if id, err = policy.NewIdentity(t.Cert); err != nil {
+			return err
+		}
+	}
+	if err = chaincodeSupport.policy.Check(op, cID, id); err != nil {
+		chaincodeLogger.Warningf("[%s]Transaction rejected: %s", shorttxid(t.Txid), err)
+		return err
+	}
+	return nil
+}
+
+// ValidateTransaction runs the validation plugins bound to the chaincode of
+// the decrypted deploy or invoke transaction t
+func (chaincodeSupport *ChaincodeSupport) ValidateTransaction(t *pb.Transaction) error {
+	if chaincodeSupport.validationErr != nil {
+		return chaincodeSupport.validationErr
+	}
+	if chaincodeSupport.validators == nil {
+		return nil
+	}
+	cID, system, err := chaincodeSupport.getChaincodeID(t)
+	if err != nil || system {
+		return err
+	}
+	return chaincodeSupport.validators.ValidateTransaction(cID, t)
+}
+
+// ValidateBatch runs the validation plugins on the transactions of a batch
+// which executed successfully and the results of their execution, before the
+// batch is committed. It returns one error per transaction, nil for the
+// transactions accepted.
+func (chaincodeSupport *ChaincodeSupport) ValidateBatch(txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+	errs := make([]error, len(txs))
+	if chaincodeSupport.validationErr != nil {
+		for i := range errs {
+			errs[i] = chaincodeSupport.validationErr
+		}
+		return errs
+	}
+	if chaincodeSupport.validators == nil {
+		return errs
+	}
+
+	cIDs := make([]*pb.ChaincodeID, len(txs))
+	decrypted := make([]*pb.Transaction, len(txs))
+	for i, t := range txs {
+		var err error
+		if secHelper := chaincodeSupport.getSecHelper(); nil != secHelper {
+			// t is now a decrypted deep clone of the transaction in the batch
+			if t, err = secHelper.TransactionPreExecution(t); err != nil {
+				errs[i] = err
+				continue
+			}
+		}
+		cID, system, err := chaincodeSupport.getChaincodeID(t)
+		if err != nil || system {
+			continue
+		}
+		cIDs[i], decrypted[i] = cID, t
+	}
+	for i, err := range chaincodeSupport.validators.ValidateBatch(cIDs, decrypted, results) {
+		if errs[i] == nil {
+			errs[i] = err
+		}
+	}
+	return errs
+}
+
+// getChaincodeID returns the chaincode of a decrypted transaction, and
+// whether it is the deployment of a system chaincode, which the peer deploys
+// itself
+func (chaincodeSupport *ChaincodeSupport) getChaincodeID(t *pb.Transaction) (*pb.ChaincodeID, bool, error) {
+	if t.Type == pb.Transaction_CHAINCODE_DEPLOY {
+		cds := &pb.ChaincodeDeploymentSpec{}
+		if err := proto.Unmarshal(t.Payload, cds); err != nil || cds.ChaincodeSpec == nil || cds.ChaincodeSpec.ChaincodeID == nil {
+			return nil, false, fmt.Errorf("Failed to read chaincode of deployment transaction %s", t.Txid)
+		}
+		return cds.ChaincodeSpec.ChaincodeID, cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM, nil
+	}
+	ci := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(t.Payload, ci); err != nil || ci.ChaincodeSpec == nil || ci.ChaincodeSpec.ChaincodeID == nil {
+		return nil, false, fmt.Errorf("Failed to read chaincode of transaction %s", t.Txid)
+	}
+	name := ci.ChaincodeSpec.ChaincodeID.Name
+	return &pb.ChaincodeID{Name: name, Path: chaincodeSupport.getDeployedPath(t.ChainID, name)}, false, nil
+}
+
+// getDeployedPath returns the path of a chaincode deployed on a chain, so that
+// policy rules can refer to chaincodes by path. It returns an empty path if the
+// deployment transaction cannot be read.
+func (chaincodeSupport *ChaincodeSupport) getDeployedPath(chainID, chaincode string) string {
+	ledger, err := ledger.GetChainLedger(chainID)
+	if err != nil {
+		return ""
+	}
+	depTx, err := ledger.GetTransactionByID(chaincode)
+	if err != nil || depTx == nil {
+		return ""
+	}
+	if nil != chaincodeSupport.secHelper {
+		if depTx, err = chaincodeSupport.secHelper.TransactionPreExecution(depTx); err != nil {
+			return ""
+		}
+	}
+	cds := &pb.ChaincodeDeploymentSpec{}
+	if err = proto.Unmarshal(depTx.Payload, cds); err != nil || cds.ChaincodeSpec == nil || cds.ChaincodeSpec.ChaincodeID == nil {
+		return ""
+	}
+	return cds.ChaincodeSpec.ChaincodeID.Path
+}
+
 // getSecHelper returns the security help set from NewChaincodeSupport
 func (chaincodeSupport *ChaincodeSupport) getSecHelper() crypto.Peer {
 	return chaincodeSupport.secHelper
@@ -575,22 +995,23 @@ func (chaincodeSupport *ChaincodeSupport) Deploy(context context.Context, t *pb.
 		return nil, nil
 	}
 
+	key := newChaincodeKey(t.ChainID, chaincode)
 	chaincodeSupport.runningChaincodes.Lock()
 	//if its in the map, there must be a connected stream...and we are trying to build the code ?!
-	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched(chaincode); ok {
-		chaincodeLogger.Debugf("deploy ?!! there's a chaincode with that name running: %s", chaincode)
+	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched(key); ok {
+		chaincodeLogger.Debugf("deploy ?!! there's a chaincode with that name running: %s", key)
 		chaincodeSupport.runningChaincodes.Unlock()
-		return cds, fmt.Errorf("deploy attempted but a chaincode with same name running %s", chaincode)
+		return cds, fmt.Errorf("deploy attempted but a chaincode with same name running %s", key)
 	}
 	chaincodeSupport.runningChaincodes.Unlock()
 
-	args, envs, err := chaincodeSupport.getArgsAndEnv(cID, cLang)
+	args, envs, err := chaincodeSupport.getArgsAndEnv(key, cID, cLang)
 	if err != nil {
 		return cds, fmt.Errorf("error getting args for chaincode %s", err)
 	}
 
 	var targz io.Reader = bytes.NewBuffer(cds.CodePackage)
-	cir := &container.CreateImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID}, Args: args, Reader: targz, Env: envs}
+	cir := &container.CreateImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, ChainID: key.vmChainID()}, Args: args, Reader: targz, Env: envs}
 
 	vmtype, _ := chaincodeSupport.getVMType(cds)
 
@@ -634,13 +1055,16 @@ func createQueryMessage(txid string, cMsg *pb.ChaincodeInput) (*pb.ChaincodeMess
 	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY, Payload: payload, Txid: txid}, nil
 }
 
-// Execute executes a transaction and waits for it to complete until a timeout value.
+// Execute executes a transaction on the chaincode deployed on the chain of the
+// transaction and waits for it to complete until a timeout value.
+// The timeout is subject to the peer policy, see GetExecuteTimeout.
 // 该函数在ChainCodeSupport文件中，首先检测ChainCode是否建立成功、能否正常运行。
 // 其中chrte.handler的得来是比较复杂的
 func (chaincodeSupport *ChaincodeSupport) Execute(ctxt context.Context, chaincode string, msg *pb.ChaincodeMessage, timeout time.Duration, tx *pb.Transaction) (*pb.ChaincodeMessage, error) {
+	key := chaincodeSupport.keyOf(tx.ChainID, chaincode)
 	chaincodeSupport.runningChaincodes.Lock()
 	//we expect the chaincode to be running... sanity check
-	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(chaincode)
+	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(key)
 	if !ok {
 		chaincodeSupport.runningChaincodes.Unlock()
 		chaincodeLogger.Debugf("cannot execute-chaincode is not running: %s", chaincode)
@@ -653,13 +1077,14 @@ func (chaincodeSupport *ChaincodeSupport) Execute(ctxt context.Context, chaincod
 	if notfy, err = chrte.handler.sendExecuteMessage(msg, tx); err != nil {
 		return nil, fmt.Errorf("Error sending %s: %s", msg.Type.String(), err)
 	}
+	timeout = chaincodeSupport.capTimeout(timeout)
 	var ccresp *pb.ChaincodeMessage
 	select {
 	case ccresp = <-notfy:
 		//response is sent to user or calling chaincode. ChaincodeMessage_ERROR and ChaincodeMessage_QUERY_ERROR
 		//are typically treated as error
 	case <-time.After(timeout):
-		err = fmt.Errorf("Timeout expired while executing transaction")
+		err = fmt.Errorf("Timeout expired while executing transaction (%s)", timeout)
 	}
 
 	//our responsibility to delete transaction context if sendExecuteMessage succeeded
@@ -17,18 +17,33 @@ limitations under the License.
 package chaincode
 
 import (
-	"errors"
 	"fmt"
-	"time"
 
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 
+	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger"
-	"github.com/hyperledger/fabric/events/producer"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
+// systemDeployKey marks the context of a system chaincode deployment
+type systemDeployKey struct{}
+
+// SystemDeployContext returns the context the peer deploys a system chaincode
+// with. The deploy transaction is built by the peer itself rather than signed
+// by a client, so it does not go through the security layer.
+func SystemDeployContext(ctxt context.Context) context.Context {
+	return context.WithValue(ctxt, systemDeployKey{}, true)
+}
+
+// isSystemDeploy returns whether the transaction is the public deploy
+// transaction of a system chaincode, executed with SystemDeployContext
+func isSystemDeploy(ctxt context.Context, t *pb.Transaction) bool {
+	internal, _ := ctxt.Value(systemDeployKey{}).(bool)
+	return internal && t.Type == pb.Transaction_CHAINCODE_DEPLOY && t.ConfidentialityLevel == pb.ConfidentialityLevel_PUBLIC
+}
+
 //Execute执行交易或者查询
 // 该函数在core/chaincode 中处理，将命令封装成ChainCode识别的格式。
 // 其中的chain对象则是访问ChainCode对应的ChainCodeSupport，
@@ -36,13 +51,13 @@ import (
 func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) ([]byte, *pb.ChaincodeEvent, error) {
 	var err error
 
-	// get a handle to ledger to mark the begin/finish of a tx
-	ledger, ledgerErr := ledger.GetLedger()
+	// get a handle to the ledger of the chain of the tx to mark the begin/finish of the tx
+	ledger, ledgerErr := ledger.GetChainLedger(t.ChainID)
 	if ledgerErr != nil {
 		return nil, nil, fmt.Errorf("Failed to get handle to ledger (%s)", ledgerErr)
 	}
 
-	if secHelper := chain.getSecHelper(); nil != secHelper {
+	if secHelper := chain.getSecHelper(); nil != secHelper && !isSystemDeploy(ctxt, t) {
 		var err error
 		t, err = secHelper.TransactionPreExecution(t)
 		// Note that t is now decrypted and is a deep clone of the original input t
@@ -51,6 +66,17 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) (
 		}
 	}
 
+	// Rejected here, before anything is executed, so that ExecuteTransactions
+	// reports the transaction with a Rejection event
+	if err = chain.Authorize(t); err != nil {
+		return nil, nil, err
+	}
+	if t.Type != pb.Transaction_CHAINCODE_QUERY {
+		if err = chain.ValidateTransaction(t); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if t.Type == pb.Transaction_CHAINCODE_DEPLOY {
 		_, err := chain.Deploy(ctxt, t)
 		if err != nil {
@@ -79,13 +105,7 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) (
 			return nil, nil, fmt.Errorf("Failed to stablish stream to container %s", chaincode)
 		}
 
-		// TODO: Need to comment next line and uncomment call to getTimeout, when transaction blocks are being created
-		timeout := time.Duration(30000) * time.Millisecond
-		//timeout, err := getTimeout(cID)
-
-		if err != nil {
-			return nil, nil, fmt.Errorf("Failed to retrieve chaincode spec(%s)", err)
-		}
+		timeout := chain.GetExecuteTimeout(t.ChainID, chaincode, getRequestedTimeout(t))
 
 		var ccMsg *pb.ChaincodeMessage
 		if t.Type == pb.Transaction_CHAINCODE_INVOKE {
@@ -137,11 +157,12 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) (
 
 //ExecuteTransactions - will execute transactions on the array one by one
 //will return an array of errors one for each transaction. If the execution
-//succeeded, array element will be nil. returns []byte of state hash or
-//error
+//succeeded, array element will be nil. returns []byte of state hash of the
+//chain chainID or error. Transactions of other chains are rejected. The
+//caller reports the failed transactions with Rejection events.
 // ExecuteTransactions将会按数组一个一个地执行交易，每个交易将返回一个错误数组；如果执行成功
 // 数组元素将为空。返回状态哈希字符数组或者错误
-func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Transaction) (succeededTXs []*pb.Transaction, stateHash []byte, ccevents []*pb.ChaincodeEvent, txerrs []error, err error) {
+func ExecuteTransactions(ctxt context.Context, cname ChainName, chainID string, xacts []*pb.Transaction) (succeededTXs []*pb.Transaction, stateHash []byte, ccevents []*pb.ChaincodeEvent, txerrs []error, err error) {
 	var chain = GetChain(cname)
 	if chain == nil {
 		// TODO: We should never get here, but otherwise a good reminder to better handle
@@ -152,16 +173,18 @@ func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Tran
 	ccevents = make([]*pb.ChaincodeEvent, len(xacts))
 	var succeededTxs = make([]*pb.Transaction, 0)
 	for i, t := range xacts {
-		_, ccevents[i], txerrs[i] = Execute(ctxt, chain, t)
+		if db.NormalizeChainID(t.ChainID) != db.NormalizeChainID(chainID) {
+			txerrs[i] = fmt.Errorf("Transaction %s is for chain %s, not chain %s", t.Txid, db.NormalizeChainID(t.ChainID), db.NormalizeChainID(chainID))
+		} else {
+			_, ccevents[i], txerrs[i] = Execute(ctxt, chain, t)
+		}
 		if txerrs[i] == nil {
 			succeededTxs = append(succeededTxs, t)
-		} else {
-			sendTxRejectedEvent(xacts[i], txerrs[i].Error())
 		}
 	}
 
 	var lgr *ledger.Ledger
-	lgr, err = ledger.GetLedger()
+	lgr, err = ledger.GetChainLedger(chainID)
 	if err == nil {
 		stateHash, err = lgr.GetTempStateHash()
 	}
@@ -169,6 +192,19 @@ func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Tran
 	return succeededTxs, stateHash, ccevents, txerrs, err
 }
 
+// ValidateBatch runs the validation plugins of chain cname on the
+// transactions of a batch which executed successfully and the results of
+// their execution, right before the batch is committed. The caller reports
+// the rejected transactions with Rejection events, and executes the batch
+// again without them.
+func ValidateBatch(cname ChainName, txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+	var chain = GetChain(cname)
+	if chain == nil {
+		panic(fmt.Sprintf("[ValidateBatch]Chain %s not found\n", cname))
+	}
+	return chain.ValidateBatch(txs, results)
+}
+
 // GetSecureContext returns the security context from the context object or error
 // Security context is nil if security is off from core.yaml file
 // func GetSecureContext(ctxt context.Context) (crypto.Peer, error) {
@@ -183,26 +219,14 @@ func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Tran
 // 	return nil, err
 // }
 
-var errFailedToGetChainCodeSpecForTransaction = errors.New("Failed to get ChainCodeSpec from Transaction")
-
-func getTimeout(cID *pb.ChaincodeID) (time.Duration, error) {
-	ledger, err := ledger.GetLedger()
-	if err == nil {
-		chaincodeID := cID.Name
-		txID, err := ledger.GetState(chaincodeID, "github.com_openblockchain_obc-peer_chaincode_id", true)
-		if err == nil {
-			tx, err := ledger.GetTransactionByID(string(txID))
-			if err == nil {
-				chaincodeDeploymentSpec := &pb.ChaincodeDeploymentSpec{}
-				proto.Unmarshal(tx.Payload, chaincodeDeploymentSpec)
-				chaincodeSpec := chaincodeDeploymentSpec.GetChaincodeSpec()
-				timeout := time.Duration(time.Duration(chaincodeSpec.Timeout) * time.Millisecond)
-				return timeout, nil
-			}
-		}
+// getRequestedTimeout returns the timeout, in milliseconds, requested in the
+// ChaincodeSpec of an invoke or query transaction, 0 if none
+func getRequestedTimeout(t *pb.Transaction) int32 {
+	ci := &pb.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(t.Payload, ci); err != nil || ci.ChaincodeSpec == nil {
+		return 0
 	}
-
-	return -1, errFailedToGetChainCodeSpecForTransaction
+	return ci.ChaincodeSpec.Timeout
 }
 
 func markTxBegin(ledger *ledger.Ledger, t *pb.Transaction) {
@@ -218,7 +242,3 @@ func markTxFinish(ledger *ledger.Ledger, t *pb.Transaction, successful bool) {
 	}
 	ledger.TxFinished(t.Txid, successful)
 }
-
-func sendTxRejectedEvent(tx *pb.Transaction, errorMsg string) {
-	producer.Send(producer.CreateRejectionEvent(tx, errorMsg))
-}
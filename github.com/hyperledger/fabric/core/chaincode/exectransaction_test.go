@@ -18,6 +18,7 @@ package chaincode
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"strconv"
@@ -28,6 +29,7 @@ import (
 
 	"path/filepath"
 
+	"github.com/hyperledger/fabric/core/chaincode/replay"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/crypto"
@@ -1559,6 +1561,136 @@ func TestGetRows(t *testing.T) {
 	closeListenerAndSleep(lis)
 }
 
+func TestGetExecuteTimeout(t *testing.T) {
+	chaincodeSupport := &ChaincodeSupport{
+		runningChaincodes: &runningChaincodes{chaincodeMap: make(map[chaincodeKey]*chaincodeRTEnv), deployedTimeouts: make(map[chaincodeKey]time.Duration), launched: make(map[chaincodeKey]*pb.ChaincodeDeploymentSpec)},
+		executeTimeout:    30 * time.Second,
+		maxExecuteTimeout: time.Minute,
+	}
+
+	if timeout := chaincodeSupport.GetExecuteTimeout("", "cc", 0); timeout != 30*time.Second {
+		t.Fatalf("Expected peer default timeout, got %s", timeout)
+	}
+
+	chaincodeSupport.setDeployedTimeout(newChaincodeKey("", "cc"), 45000)
+	if timeout := chaincodeSupport.GetExecuteTimeout("", "cc", 0); timeout != 45*time.Second {
+		t.Fatalf("Expected timeout recorded at deploy, got %s", timeout)
+	}
+	if timeout := chaincodeSupport.GetExecuteTimeout("other", "cc", 0); timeout != 30*time.Second {
+		t.Fatalf("Expected the timeout recorded at deploy on another chain not to apply, got %s", timeout)
+	}
+
+	if timeout := chaincodeSupport.GetExecuteTimeout("", "cc", 500); timeout != 500*time.Millisecond {
+		t.Fatalf("Expected timeout requested by the invocation, got %s", timeout)
+	}
+
+	if timeout := chaincodeSupport.GetExecuteTimeout("", "cc", 120000); timeout != time.Minute {
+		t.Fatalf("Expected timeout capped by the peer, got %s", timeout)
+	}
+
+	chaincodeSupport.setDeployedTimeout(newChaincodeKey("", "cc"), 0)
+	if timeout := chaincodeSupport.GetExecuteTimeout("", "cc", 0); timeout != 30*time.Second {
+		t.Fatalf("Expected peer default timeout after redeploy without timeout, got %s", timeout)
+	}
+}
+
+func TestChaincodeKeys(t *testing.T) {
+	for _, key := range []chaincodeKey{newChaincodeKey("", "cc"), newChaincodeKey("chain_1", "cc"), newChaincodeKey("", "dev@cc")} {
+		if parsed := parseChaincodeKey(key.String()); parsed != key {
+			t.Errorf("Expected %s to register as %+v, got %+v", key, key, parsed)
+		}
+	}
+	if key := newChaincodeKey("", "cc"); key != newChaincodeKey("default", "cc") || key.String() != "cc" || key.vmChainID() != "" {
+		t.Errorf("Expected the chaincodes of the default chain to keep their name, got %+v", key)
+	}
+	if key := newChaincodeKey("chain_1", "cc"); key.String() != "cc@chain_1" || key.vmChainID() != "chain_1" {
+		t.Errorf("Expected the chaincodes of other chains to be named after their chain, got %+v", key)
+	}
+
+	chaincodeSupport := &ChaincodeSupport{
+		runningChaincodes: &runningChaincodes{chaincodeMap: make(map[chaincodeKey]*chaincodeRTEnv), deployedTimeouts: make(map[chaincodeKey]time.Duration), launched: make(map[chaincodeKey]*pb.ChaincodeDeploymentSpec)},
+	}
+	chaincodeSupport.runningChaincodes.launched[newChaincodeKey("", "cc")] = &pb.ChaincodeDeploymentSpec{}
+	chaincodeSupport.runningChaincodes.launched[newChaincodeKey("", "syscc")] = &pb.ChaincodeDeploymentSpec{ExecEnv: pb.ChaincodeDeploymentSpec_SYSTEM}
+	if key := chaincodeSupport.keyOf("chain_1", "cc"); key != newChaincodeKey("chain_1", "cc") {
+		t.Errorf("Expected a chaincode running on the default chain not to be reused for another chain, got %+v", key)
+	}
+	if key := chaincodeSupport.keyOf("chain_1", "syscc"); key != newChaincodeKey("", "syscc") {
+		t.Errorf("Expected system chaincodes to run once for all the chains, got %+v", key)
+	}
+}
+
+func TestSystemDeployContext(t *testing.T) {
+	deploy := &pb.Transaction{Type: pb.Transaction_CHAINCODE_DEPLOY}
+	if isSystemDeploy(context.Background(), deploy) {
+		t.Errorf("Expected a deploy transaction of a client to go through the security layer")
+	}
+	ctxt := SystemDeployContext(context.Background())
+	if !isSystemDeploy(ctxt, deploy) {
+		t.Errorf("Expected the deploy transaction of a system chaincode to bypass the security layer")
+	}
+	if isSystemDeploy(ctxt, &pb.Transaction{Type: pb.Transaction_CHAINCODE_INVOKE}) {
+		t.Errorf("Expected only deploy transactions to bypass the security layer")
+	}
+	if isSystemDeploy(ctxt, &pb.Transaction{Type: pb.Transaction_CHAINCODE_DEPLOY, ConfidentialityLevel: pb.ConfidentialityLevel_CONFIDENTIAL}) {
+		t.Errorf("Expected confidential transactions to go through the security layer")
+	}
+}
+
+// replyingStream records the reply of the chaincode to each message while
+// the message is being sent, as a fast chaincode would
+type replyingStream struct {
+	handler *Handler
+}
+
+func (s *replyingStream) Send(msg *pb.ChaincodeMessage) error {
+	s.handler.record(replay.ChaincodeToPeer, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: msg.Txid})
+	return nil
+}
+
+func (s *replyingStream) Recv() (*pb.ChaincodeMessage, error) {
+	return nil, io.EOF
+}
+
+func TestRecordReplyAfterTrigger(t *testing.T) {
+	path := filepath.Join(os.TempDir(), "hyperledger", "handler_test.rec")
+	os.Remove(path)
+	defer os.Remove(path)
+	recorder, err := replay.NewRecorder(path, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %s", err)
+	}
+
+	stream := &replyingStream{}
+	handler := &Handler{ChatStream: stream, ChaincodeID: &pb.ChaincodeID{Name: "cc"}, chaincodeSupport: &ChaincodeSupport{recorder: recorder}}
+	stream.handler = handler
+	for _, txid := range []string{"tx1", "tx2"} {
+		if err = handler.serialSend(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: txid}); err != nil {
+			t.Fatalf("Error sending message: %s", err)
+		}
+	}
+	if err = recorder.Close(); err != nil {
+		t.Fatalf("Failed to close recorder: %s", err)
+	}
+
+	entries, err := replay.LoadRecording(path)
+	if err != nil {
+		t.Fatalf("Failed to load recording: %s", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 recorded messages, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		expected := replay.PeerToChaincode
+		if i%2 == 1 {
+			expected = replay.ChaincodeToPeer
+		}
+		if entry.Direction != expected {
+			t.Errorf("Expected message %d to be recorded as %s, got %s", i, expected, entry.Direction)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	SetupTestConfig()
 	os.Exit(m.Run())
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/replay"
 	ccintf "github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/crypto"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
@@ -76,6 +77,8 @@ type Handler struct {
 	ChatStream  ccintf.ChaincodeStream
 	FSM         *fsm.FSM
 	ChaincodeID *pb.ChaincodeID
+	// The chain the chaincode was deployed on, and initialized for
+	chainID string
 
 	// A copy of decrypted deploy tx this handler manages, no code
 	deployTXSecContext *pb.Transaction
@@ -106,6 +109,9 @@ func shorttxid(txid string) string {
 func (handler *Handler) serialSend(msg *pb.ChaincodeMessage) error {
 	handler.serialLock.Lock()
 	defer handler.serialLock.Unlock()
+	// Recorded before it is sent, the reply of the chaincode may be received
+	// and recorded before Send returns
+	handler.record(replay.PeerToChaincode, msg)
 	if err := handler.ChatStream.Send(msg); err != nil {
 		chaincodeLogger.Errorf("Error sending %s: %s", msg.Type.String(), err)
 		return fmt.Errorf("Error sending %s: %s", msg.Type.String(), err)
@@ -113,6 +119,18 @@ func (handler *Handler) serialSend(msg *pb.ChaincodeMessage) error {
 	return nil
 }
 
+// record writes msg to the chaincode message recording, if one is enabled
+func (handler *Handler) record(direction replay.Direction, msg *pb.ChaincodeMessage) {
+	if handler.chaincodeSupport == nil || handler.chaincodeSupport.recorder == nil {
+		return
+	}
+	var chaincodeID string
+	if handler.ChaincodeID != nil {
+		chaincodeID = handler.ChaincodeID.Name
+	}
+	handler.chaincodeSupport.recorder.Record(chaincodeID, direction, msg)
+}
+
 func (handler *Handler) createTxContext(txid string, tx *pb.Transaction) (*transactionContext, error) {
 	if handler.txCtxs == nil {
 		return nil, fmt.Errorf("cannot create notifier for txid:%s", txid)
@@ -134,6 +152,21 @@ func (handler *Handler) getTxContext(txid string) *transactionContext {
 	return handler.txCtxs[txid]
 }
 
+// getChainID returns the chain a transaction is executed on, a chaincode
+// invoked or queried by the chaincode is executed on the same chain
+func (handler *Handler) getChainID(txid string) string {
+	if txctx := handler.getTxContext(txid); txctx != nil && txctx.transactionSecContext != nil {
+		return txctx.transactionSecContext.ChainID
+	}
+	return ""
+}
+
+// getLedger returns the ledger of the chain of a transaction, which the state
+// the chaincode reads and writes during the transaction belongs to
+func (handler *Handler) getLedger(txid string) (*ledger.Ledger, error) {
+	return ledger.GetChainLedger(handler.getChainID(txid))
+}
+
 func (handler *Handler) deleteTxContext(txid string) {
 	handler.Lock()
 	defer handler.Unlock()
@@ -311,6 +344,7 @@ func (handler *Handler) processStream() error {
 				return err
 			}
 			chaincodeLogger.Debugf("[%s]Received message %s from shim", shorttxid(in.Txid), in.Type.String())
+			handler.record(replay.ChaincodeToPeer, in)
 			if in.Type.String() == pb.ChaincodeMessage_ERROR.String() {
 				chaincodeLogger.Errorf("Got error: %s", string(in.Payload))
 			}
@@ -616,7 +650,7 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 		}()
 
 		key := string(msg.Payload)
-		ledgerObj, ledgerErr := ledger.GetLedger()
+		ledgerObj, ledgerErr := handler.getLedger(msg.Txid)
 		if ledgerErr != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(ledgerErr.Error())
@@ -707,7 +741,7 @@ func (handler *Handler) handleRangeQueryState(msg *pb.ChaincodeMessage) {
 
 		hasNext := true
 
-		ledger, ledgerErr := ledger.GetLedger()
+		ledger, ledgerErr := handler.getLedger(msg.Txid)
 		if ledgerErr != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(ledgerErr.Error())
@@ -1017,7 +1051,7 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			handler.triggerNextState(triggerNextStateMsg, true)
 		}()
 
-		ledgerObj, ledgerErr := ledger.GetLedger()
+		ledgerObj, ledgerErr := handler.getLedger(msg.Txid)
 		if ledgerErr != nil {
 			// Send error msg back to chaincode and trigger event
 			payload := []byte(ledgerErr.Error())
@@ -1070,6 +1104,7 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			// Create the transaction object
 			chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: chaincodeSpec}
 			transaction, _ := pb.NewChaincodeExecute(chaincodeInvocationSpec, msg.Txid, pb.Transaction_CHAINCODE_INVOKE)
+			transaction.ChainID = handler.getChainID(msg.Txid)
 
 			// Launch the new chaincode if not already running
 			_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(context.Background(), transaction)
@@ -1080,8 +1115,7 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 				return
 			}
 
-			// TODO: Need to handle timeout correctly
-			timeout := time.Duration(30000) * time.Millisecond
+			timeout := handler.chaincodeSupport.GetExecuteTimeout(transaction.ChainID, newChaincodeID, chaincodeSpec.Timeout)
 
 			ccMsg, _ := createTransactionMessage(transaction.Txid, chaincodeInput)
 
@@ -1342,6 +1376,7 @@ func (handler *Handler) handleQueryChaincode(msg *pb.ChaincodeMessage) {
 		// Create the transaction object
 		chaincodeInvocationSpec := &pb.ChaincodeInvocationSpec{ChaincodeSpec: chaincodeSpec}
 		transaction, _ := pb.NewChaincodeExecute(chaincodeInvocationSpec, msg.Txid, pb.Transaction_CHAINCODE_QUERY)
+		transaction.ChainID = handler.getChainID(msg.Txid)
 
 		// Launch the new chaincode if not already running
 		_, chaincodeInput, launchErr := handler.chaincodeSupport.Launch(context.Background(), transaction)
@@ -1352,8 +1387,7 @@ func (handler *Handler) handleQueryChaincode(msg *pb.ChaincodeMessage) {
 			return
 		}
 
-		// TODO: Need to handle timeout correctly
-		timeout := time.Duration(30000) * time.Millisecond
+		timeout := handler.chaincodeSupport.GetExecuteTimeout(transaction.ChainID, newChaincodeID, chaincodeSpec.Timeout)
 
 		ccMsg, _ := createQueryMessage(transaction.Txid, chaincodeInput)
 
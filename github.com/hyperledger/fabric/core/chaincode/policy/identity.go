@@ -0,0 +1,114 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/crypto"
+	"github.com/hyperledger/fabric/core/crypto/attributes"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	membersrvc "github.com/hyperledger/fabric/membersrvc/protos"
+)
+
+const (
+	// RoleAttribute is the attribute of a transaction certificate holding
+	// the role of its holder, as issued by the ACA
+	RoleAttribute = "role"
+	// AffiliationAttribute is the attribute of a transaction certificate
+	// holding the affiliation of its holder, as issued by the ACA
+	AffiliationAttribute = "affiliation"
+)
+
+// Identity is what the policy knows about the creator of a transaction.
+// Enrollment certificates carry the enrollment ID, affiliation and role of
+// their subject. Transaction certificates hide the enrollment ID, they carry
+// the attributes of their holder, of which the role and affiliation.
+type Identity struct {
+	EnrollmentID string
+	Affiliation  string
+	Role         string
+	Attributes   map[string]string
+}
+
+// NewIdentity extracts the identity from the DER encoded certificate of a
+// transaction
+func NewIdentity(certRaw []byte) (*Identity, error) {
+	cert, err := primitives.DERToX509Certificate(certRaw)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing transaction certificate: %s", err)
+	}
+
+	id := &Identity{Attributes: make(map[string]string)}
+	if roleRaw, err := primitives.GetCriticalExtension(cert, crypto.ECertSubjectRole); err == nil {
+		role, err := strconv.ParseInt(string(roleRaw), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing role in enrollment certificate: %s", err)
+		}
+		id.Role = membersrvc.Role(role).String()
+		// The ECA issues the certificates of affiliated members to
+		// <id>\<affiliation>
+		id.EnrollmentID = cert.Subject.CommonName
+		if sep := strings.LastIndex(id.EnrollmentID, "\\"); sep >= 0 {
+			id.Affiliation = id.EnrollmentID[sep+1:]
+			id.EnrollmentID = id.EnrollmentID[:sep]
+		}
+		return id, nil
+	}
+
+	// The ACA certifies the role and affiliation of the holder of a
+	// transaction certificate through the attributes it requested
+	readAttributes(cert, id.Attributes)
+	id.Role = id.Attributes[RoleAttribute]
+	id.Affiliation = id.Attributes[AffiliationAttribute]
+	return id, nil
+}
+
+// readAttributes reads the attributes of a transaction certificate. The
+// attributes of a certificate with an encrypted header cannot be read by the
+// validators, they never match a rule.
+func readAttributes(cert *x509.Certificate, values map[string]string) {
+	header, _, err := attributes.ReadAttributeHeader(cert, nil)
+	if err != nil {
+		logger.Debugf("No attributes read from certificate: %s", err)
+		return
+	}
+	for name, position := range header {
+		value, err := attributes.ReadTCertAttributeByPosition(cert, position)
+		if err != nil {
+			logger.Warningf("Error reading attribute %s of certificate: %s", name, err)
+			continue
+		}
+		values[name] = string(value)
+	}
+}
+
+func (id *Identity) String() string {
+	if id == nil {
+		return "anonymous creator"
+	}
+	if id.EnrollmentID != "" {
+		return fmt.Sprintf("%s (%s)", id.EnrollmentID, id.Role)
+	}
+	if id.Role != "" {
+		return fmt.Sprintf("transaction certificate holder (%s)", id.Role)
+	}
+	return "transaction certificate holder"
+}
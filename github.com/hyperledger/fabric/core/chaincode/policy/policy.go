@@ -0,0 +1,236 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	membersrvc "github.com/hyperledger/fabric/membersrvc/protos"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+var logger = logging.MustGetLogger("chaincode/policy")
+
+// Operation is an operation on a chaincode the policy decides upon
+type Operation string
+
+const (
+	// Deploy is the deployment of a chaincode
+	Deploy Operation = "deploy"
+	// Invoke is the invocation of a chaincode
+	Invoke Operation = "invoke"
+	// Query is a query of a chaincode
+	Query Operation = "query"
+)
+
+const (
+	// Allow is the action permitting an operation
+	Allow = "allow"
+	// Deny is the action refusing an operation
+	Deny = "deny"
+)
+
+// OperationOf returns the operation performed by a transaction of type t
+func OperationOf(t pb.Transaction_Type) (Operation, error) {
+	switch t {
+	case pb.Transaction_CHAINCODE_DEPLOY:
+		return Deploy, nil
+	case pb.Transaction_CHAINCODE_INVOKE:
+		return Invoke, nil
+	case pb.Transaction_CHAINCODE_QUERY:
+		return Query, nil
+	}
+	return "", fmt.Errorf("Transaction type %s is not subject to the policy", t)
+}
+
+// Rule grants operations on a set of chaincodes to the identities matching
+// all of its requirements. An empty list matches everything.
+type Rule struct {
+	// Operations are the operations granted: deploy, invoke or query
+	Operations []string `mapstructure:"operations"`
+	// Chaincodes are patterns, as in path.Match, matched against the name
+	// and the path of the chaincode
+	Chaincodes []string `mapstructure:"chaincodes"`
+	// Roles are the member roles (client, peer, validator, auditor) of
+	// which the identity must have one
+	Roles []string `mapstructure:"roles"`
+	// Affiliations are the affiliation groups of which the identity must
+	// belong to one
+	Affiliations []string `mapstructure:"affiliations"`
+	// Attributes are the certificate attributes the identity must have,
+	// with the given values
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+// Policy decides who may deploy, invoke and query which chaincodes. The
+// rules applying to an operation on a chaincode are those granting the
+// operation on it. If there are none the default action is taken, otherwise
+// the operation is permitted only to the identities matched by one of them.
+type Policy struct {
+	Default string  `mapstructure:"default"`
+	Rules   []*Rule `mapstructure:"rules"`
+}
+
+// DenyAll returns a policy refusing every operation
+func DenyAll() *Policy {
+	return &Policy{Default: Deny}
+}
+
+// LoadFromConfig reads the policy from the chaincode.policy section of the
+// configuration, it returns nil if the policy is disabled
+func LoadFromConfig() (*Policy, error) {
+	if !viper.GetBool("chaincode.policy.enabled") {
+		return nil, nil
+	}
+	p := &Policy{}
+	if err := viper.UnmarshalKey("chaincode.policy", p); err != nil {
+		return nil, fmt.Errorf("Error reading chaincode policy: %s", err)
+	}
+	if p.Default == "" {
+		p.Default = Allow
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks that the policy is well formed
+func (p *Policy) Validate() error {
+	if p.Default != Allow && p.Default != Deny {
+		return fmt.Errorf("Invalid default action '%s' in chaincode policy, must be %s or %s", p.Default, Allow, Deny)
+	}
+	for i, rule := range p.Rules {
+		if rule == nil {
+			return fmt.Errorf("Rule %d of chaincode policy is empty", i)
+		}
+		for _, op := range rule.Operations {
+			switch Operation(strings.ToLower(op)) {
+			case Deploy, Invoke, Query:
+			default:
+				return fmt.Errorf("Invalid operation '%s' in rule %d of chaincode policy", op, i)
+			}
+		}
+		for _, pattern := range rule.Chaincodes {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("Invalid chaincode pattern '%s' in rule %d of chaincode policy: %s", pattern, i, err)
+			}
+		}
+		for _, role := range rule.Roles {
+			if _, ok := membersrvc.Role_value[strings.ToUpper(role)]; !ok {
+				return fmt.Errorf("Invalid role '%s' in rule %d of chaincode policy", role, i)
+			}
+		}
+	}
+	return nil
+}
+
+// Check returns an error if the policy does not permit the identity to
+// perform op on the chaincode. The identity is nil for transactions without
+// a certificate, they only match rules without identity requirements.
+func (p *Policy) Check(op Operation, chaincodeID *pb.ChaincodeID, id *Identity) error {
+	applicable := false
+	for _, rule := range p.Rules {
+		if !rule.grants(op, chaincodeID) {
+			continue
+		}
+		applicable = true
+		if rule.matches(id) {
+			return nil
+		}
+	}
+	if !applicable && p.Default == Allow {
+		return nil
+	}
+	return fmt.Errorf("Policy does not permit %s to %s chaincode %s", id, op, describe(chaincodeID))
+}
+
+func describe(chaincodeID *pb.ChaincodeID) string {
+	if chaincodeID.Path == "" {
+		return chaincodeID.Name
+	}
+	if chaincodeID.Name == "" {
+		return chaincodeID.Path
+	}
+	return fmt.Sprintf("%s (%s)", chaincodeID.Name, chaincodeID.Path)
+}
+
+func (r *Rule) grants(op Operation, chaincodeID *pb.ChaincodeID) bool {
+	if len(r.Operations) > 0 && !containsFold(r.Operations, string(op)) {
+		return false
+	}
+	if len(r.Chaincodes) == 0 {
+		return true
+	}
+	for _, pattern := range r.Chaincodes {
+		if match(pattern, chaincodeID.Name) || match(pattern, chaincodeID.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, name string) bool {
+	if name == "" {
+		return false
+	}
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+func (r *Rule) matches(id *Identity) bool {
+	if id == nil {
+		return len(r.Roles) == 0 && len(r.Affiliations) == 0 && len(r.Attributes) == 0
+	}
+	if len(r.Roles) > 0 && !containsFold(r.Roles, id.Role) {
+		return false
+	}
+	if len(r.Affiliations) > 0 && !contains(r.Affiliations, id.Affiliation) {
+		return false
+	}
+	for name, value := range r.Attributes {
+		if actual, ok := id.Attributes[name]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
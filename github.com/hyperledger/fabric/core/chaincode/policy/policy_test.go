@@ -0,0 +1,218 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/crypto"
+	"github.com/hyperledger/fabric/core/crypto/attributes"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+var examplePath = "github.com/hyperledger/fabric/examples/chaincode/go/chaincode_example02"
+
+func testPolicy() *Policy {
+	return &Policy{
+		Default: Allow,
+		Rules: []*Rule{
+			{Operations: []string{"deploy"}, Roles: []string{"client"}, Affiliations: []string{"bank_a"}},
+			{Operations: []string{"Invoke"}, Chaincodes: []string{"github.com/hyperledger/fabric/examples/*/*/*"}, Attributes: map[string]string{"position": "Software Engineer"}},
+			{Operations: []string{"invoke"}, Chaincodes: []string{"mycc"}, Roles: []string{"auditor"}},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := testPolicy()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Unexpected invalid policy: %s", err)
+	}
+
+	alice := &Identity{EnrollmentID: "alice", Affiliation: "bank_a", Role: "CLIENT"}
+	bob := &Identity{EnrollmentID: "bob", Affiliation: "bank_b", Role: "CLIENT"}
+	engineer := &Identity{Attributes: map[string]string{"position": "Software Engineer"}}
+	auditor := &Identity{EnrollmentID: "eve", Role: "AUDITOR"}
+	example := &pb.ChaincodeID{Name: "0123", Path: examplePath}
+	mycc := &pb.ChaincodeID{Name: "mycc"}
+
+	cases := []struct {
+		op        Operation
+		chaincode *pb.ChaincodeID
+		id        *Identity
+		permitted bool
+	}{
+		{Deploy, example, alice, true},
+		{Deploy, example, bob, false},
+		{Deploy, example, engineer, false},
+		{Deploy, example, nil, false},
+		{Invoke, example, engineer, true},
+		{Invoke, example, alice, false},
+		{Invoke, mycc, auditor, true},
+		{Invoke, mycc, engineer, false},
+		// no rule grants queries, the default applies
+		{Query, example, nil, true},
+		{Query, mycc, bob, true},
+	}
+	for _, c := range cases {
+		err := p.Check(c.op, c.chaincode, c.id)
+		if c.permitted && err != nil {
+			t.Errorf("Expected %s to be permitted to %s %s: %s", c.id, c.op, describe(c.chaincode), err)
+		} else if !c.permitted && err == nil {
+			t.Errorf("Expected %s not to be permitted to %s %s", c.id, c.op, describe(c.chaincode))
+		}
+	}
+
+	p.Default = Deny
+	if err := p.Check(Query, mycc, auditor); err == nil {
+		t.Error("Expected the default action to deny the query")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalid := []*Policy{
+		{Default: "maybe"},
+		{Default: Allow, Rules: []*Rule{{Operations: []string{"upgrade"}}}},
+		{Default: Allow, Rules: []*Rule{{Chaincodes: []string{"["}}}},
+		{Default: Allow, Rules: []*Rule{{Roles: []string{"admin"}}}},
+		{Default: Allow, Rules: []*Rule{nil}},
+	}
+	for i, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Expected policy %d to be invalid", i)
+		}
+	}
+}
+
+func TestLoadFromConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.SetConfigType("yaml")
+	config := []byte(`
+chaincode:
+    policy:
+        enabled: true
+        rules:
+            - operations: [deploy]
+              roles: [client]
+              attributes:
+                  company: ACompany
+`)
+	if err := viper.ReadConfig(bytes.NewBuffer(config)); err != nil {
+		t.Fatalf("Failed to read config: %s", err)
+	}
+
+	p, err := LoadFromConfig()
+	if err != nil {
+		t.Fatalf("Failed to load policy: %s", err)
+	}
+	if p == nil || p.Default != Allow || len(p.Rules) != 1 {
+		t.Fatalf("Unexpected policy %+v", p)
+	}
+	if p.Rules[0].Attributes["company"] != "ACompany" || p.Rules[0].Roles[0] != "client" {
+		t.Fatalf("Unexpected rule %+v", p.Rules[0])
+	}
+
+	viper.Set("chaincode.policy.enabled", false)
+	if p, err = LoadFromConfig(); p != nil || err != nil {
+		t.Fatalf("Expected no policy when disabled, got %+v, %v", p, err)
+	}
+}
+
+func TestNewIdentityFromEnrollmentCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "alice\\bank_a"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{
+			{Id: crypto.ECertSubjectRole, Critical: true, Value: []byte("1")},
+		},
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+
+	id, err := NewIdentity(raw)
+	if err != nil {
+		t.Fatalf("Failed to read identity: %s", err)
+	}
+	if id.EnrollmentID != "alice" || id.Affiliation != "bank_a" || id.Role != "CLIENT" {
+		t.Fatalf("Unexpected identity %+v", id)
+	}
+
+	if _, err = NewIdentity([]byte("not a certificate")); err == nil {
+		t.Fatal("Expected an error reading an invalid certificate")
+	}
+}
+
+func TestNewIdentityFromTransactionCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	header, err := attributes.BuildAttributesHeader(map[string]int{RoleAttribute: 1, AffiliationAttribute: 2, "position": 3})
+	if err != nil {
+		t.Fatalf("Failed to build attributes header: %s", err)
+	}
+	attribute := func(position int) asn1.ObjectIdentifier {
+		return asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 9 + position}
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Transaction Certificate"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{
+			{Id: primitives.TCertAttributesHeaders, Value: header},
+			{Id: attribute(1), Value: []byte("client")},
+			{Id: attribute(2), Value: []byte("bank_a")},
+			{Id: attribute(3), Value: []byte("Software Engineer")},
+		},
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+
+	id, err := NewIdentity(raw)
+	if err != nil {
+		t.Fatalf("Failed to read identity: %s", err)
+	}
+	if id.EnrollmentID != "" || id.Role != "client" || id.Affiliation != "bank_a" || id.Attributes["position"] != "Software Engineer" {
+		t.Fatalf("Unexpected identity %+v", id)
+	}
+	// The holder is granted deploy by the role and affiliation rule
+	if err = testPolicy().Check(Deploy, &pb.ChaincodeID{Path: examplePath}, id); err != nil {
+		t.Fatalf("Expected the transaction certificate holder to deploy: %s", err)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+var logger = logging.MustGetLogger("chaincode/replay")
+
+// Direction tells which side of the chaincode stream sent a recorded message
+type Direction string
+
+const (
+	// PeerToChaincode marks messages sent by the peer to the chaincode
+	PeerToChaincode Direction = "peer->chaincode"
+	// ChaincodeToPeer marks messages sent by the chaincode to the peer
+	ChaincodeToPeer Direction = "chaincode->peer"
+)
+
+// Entry is a single recorded ChaincodeMessage. State read results are
+// recorded as the RESPONSE messages the peer sends back to GET_STATE and
+// RANGE_QUERY_STATE* requests.
+type Entry struct {
+	Seq         uint64               `json:"seq"`
+	Timestamp   time.Time            `json:"timestamp"`
+	ChaincodeID string               `json:"chaincodeID"`
+	Direction   Direction            `json:"direction"`
+	Message     *pb.ChaincodeMessage `json:"message"`
+}
+
+// Recorder appends the messages of selected transactions to a recording file,
+// one JSON encoded Entry per line
+type Recorder struct {
+	sync.Mutex
+	file       *os.File
+	writer     *bufio.Writer
+	encoder    *json.Encoder
+	seq        uint64
+	chaincodes map[string]bool
+	txids      map[string]bool
+}
+
+// NewRecorder opens (or creates) the recording file at path. Only messages
+// of the given chaincodes and transactions are recorded; an empty list
+// selects all of them.
+func NewRecorder(path string, chaincodes []string, txids []string) (*Recorder, error) {
+	if path == "" {
+		return nil, fmt.Errorf("No recording file specified")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("Error creating directory for recording file %s: %s", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening recording file %s: %s", path, err)
+	}
+	r := &Recorder{
+		file:       file,
+		writer:     bufio.NewWriter(file),
+		chaincodes: toSet(chaincodes),
+		txids:      toSet(txids),
+	}
+	r.encoder = json.NewEncoder(r.writer)
+	return r, nil
+}
+
+// NewRecorderFromConfig creates a recorder using the chaincode.recorder
+// section of the configuration, it returns nil if recording is disabled
+func NewRecorderFromConfig() (*Recorder, error) {
+	if !viper.GetBool("chaincode.recorder.enabled") {
+		return nil, nil
+	}
+	return NewRecorder(viper.GetString("chaincode.recorder.file"),
+		viper.GetStringSlice("chaincode.recorder.chaincodes"),
+		viper.GetStringSlice("chaincode.recorder.txids"))
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range values {
+		if v != "" {
+			set[v] = true
+		}
+	}
+	return set
+}
+
+// Selected returns true if messages of the given chaincode and transaction
+// are recorded
+func (r *Recorder) Selected(chaincodeID string, txid string) bool {
+	if len(r.chaincodes) > 0 && !r.chaincodes[chaincodeID] {
+		return false
+	}
+	if len(r.txids) > 0 && !r.txids[txid] {
+		return false
+	}
+	return true
+}
+
+// Record writes msg to the recording if it belongs to a selected transaction.
+// Errors are logged rather than returned so that recording never interferes
+// with the execution of the chaincode.
+func (r *Recorder) Record(chaincodeID string, direction Direction, msg *pb.ChaincodeMessage) {
+	if r == nil || msg == nil || msg.Type == pb.ChaincodeMessage_KEEPALIVE {
+		return
+	}
+	if !r.Selected(chaincodeID, msg.Txid) {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if r.file == nil {
+		return
+	}
+	r.seq++
+	entry := &Entry{Seq: r.seq, Timestamp: time.Now().UTC(), ChaincodeID: chaincodeID, Direction: direction, Message: msg}
+	if err := r.encoder.Encode(entry); err != nil {
+		logger.Errorf("Error recording message %s for txid %s: %s", msg.Type, msg.Txid, err)
+		return
+	}
+	// Flush every entry, a recording is most useful right after a crash
+	if err := r.writer.Flush(); err != nil {
+		logger.Errorf("Error flushing recording file: %s", err)
+	}
+}
+
+// Close flushes and closes the recording file
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.writer.Flush()
+	if cerr := r.file.Close(); err == nil {
+		err = cerr
+	}
+	r.file = nil
+	return err
+}
+
+// ReadRecording decodes all the entries of a recording
+func ReadRecording(in io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	decoder := json.NewDecoder(in)
+	for {
+		entry := &Entry{}
+		err := decoder.Decode(entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Error decoding entry %d of recording: %s", len(entries)+1, err)
+		}
+		if entry.Message == nil {
+			return nil, fmt.Errorf("Entry %d of recording has no message", len(entries)+1)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// LoadRecording reads the recording file at path
+func LoadRecording(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening recording file %s: %s", path, err)
+	}
+	defer file.Close()
+	return ReadRecording(file)
+}
+
+// Filter returns the entries of the given chaincode and transactions. An
+// empty chaincodeID or txids list matches everything.
+func Filter(entries []*Entry, chaincodeID string, txids []string) []*Entry {
+	txidSet := toSet(txids)
+	var filtered []*Entry
+	for _, entry := range entries {
+		if chaincodeID != "" && entry.ChaincodeID != chaincodeID {
+			continue
+		}
+		if len(txidSet) > 0 && !txidSet[entry.Message.Txid] {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
@@ -0,0 +1,261 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// Divergence describes the first point at which the replayed chaincode
+// behaved differently from the recording
+type Divergence struct {
+	// Entry is the recorded entry that was expected, nil if the chaincode
+	// sent a message that the recording does not contain
+	Entry *Entry
+	// Actual is the message sent by the chaincode, nil if none was received
+	Actual *pb.ChaincodeMessage
+	Reason string
+}
+
+func (d *Divergence) String() string {
+	var expected, actual string
+	if d.Entry != nil {
+		expected = fmt.Sprintf("entry %d %s [%s]", d.Entry.Seq, d.Entry.Message.Type, shorttxid(d.Entry.Message.Txid))
+	} else {
+		expected = "no message"
+	}
+	if d.Actual != nil {
+		actual = fmt.Sprintf("%s [%s]", d.Actual.Type, shorttxid(d.Actual.Txid))
+	} else {
+		actual = "no message"
+	}
+	return fmt.Sprintf("expected %s, got %s: %s", expected, actual, d.Reason)
+}
+
+// Result is the outcome of a replay
+type Result struct {
+	// Replayed is the number of recorded entries replayed before the end of
+	// the recording or the divergence
+	Replayed   int
+	Divergence *Divergence
+}
+
+// Replayer plays a recording back to a chaincode in place of the peer. The
+// messages recorded as sent by the peer are sent to the chaincode, and the
+// messages recorded as sent by the chaincode are compared with what it
+// actually sends.
+type Replayer struct {
+	entries []*Entry
+	timeout time.Duration
+}
+
+// drainQuiet is how long the chaincode must stay silent after the end of the
+// recording for the replay to be over
+const drainQuiet = 500 * time.Millisecond
+
+type received struct {
+	msg *pb.ChaincodeMessage
+	err error
+}
+
+// NewReplayer creates a replayer for the given entries. timeout bounds the
+// wait for every message expected from the chaincode.
+func NewReplayer(entries []*Entry, timeout time.Duration) *Replayer {
+	return &Replayer{entries: entries, timeout: timeout}
+}
+
+func shorttxid(txid string) string {
+	if len(txid) < 8 {
+		return txid
+	}
+	return txid[0:8]
+}
+
+// Replay performs the registration handshake with the chaincode on stream and
+// then replays the recording. An error is returned only if the stream itself
+// fails, a behavior mismatch is reported in the Result.
+func (r *Replayer) Replay(stream ccintf.ChaincodeStream) (*Result, error) {
+	in := make(chan *received, 16)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			msg, err := stream.Recv()
+			select {
+			case in <- &received{msg, err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// messages received from the chaincode, by txid, not yet matched
+	pending := make(map[string][]*pb.ChaincodeMessage)
+	result := &Result{}
+
+	register, err := r.await(in, pending, "")
+	if err != nil {
+		return nil, err
+	}
+	if register == nil || register.Type != pb.ChaincodeMessage_REGISTER {
+		result.Divergence = &Divergence{Actual: register, Reason: fmt.Sprintf("chaincode did not %s", pb.ChaincodeMessage_REGISTER)}
+		return result, nil
+	}
+	if err = stream.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTERED}); err != nil {
+		return nil, fmt.Errorf("Error sending %s: %s", pb.ChaincodeMessage_REGISTERED, err)
+	}
+
+	var script []*Entry
+	for _, entry := range r.entries {
+		// the registration handshake is always performed by the replayer
+		if entry.Message.Type == pb.ChaincodeMessage_REGISTER || entry.Message.Type == pb.ChaincodeMessage_REGISTERED {
+			continue
+		}
+		script = append(script, entry)
+	}
+	if len(script) > 0 && !startsChaincode(script[0]) {
+		// The recording was restricted to transactions run after the chaincode
+		// was started, bring it to the ready state ourselves
+		ready := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_READY, Txid: script[0].Message.Txid}
+		if err = stream.Send(ready); err != nil {
+			return nil, fmt.Errorf("Error sending %s: %s", pb.ChaincodeMessage_READY, err)
+		}
+	}
+
+	for _, entry := range script {
+		if entry.Direction == PeerToChaincode {
+			logger.Debugf("[%s]Replaying %s", shorttxid(entry.Message.Txid), entry.Message.Type)
+			if err = stream.Send(entry.Message); err != nil {
+				return nil, fmt.Errorf("Error sending %s: %s", entry.Message.Type, err)
+			}
+			result.Replayed++
+			continue
+		}
+
+		actual, err := r.await(in, pending, entry.Message.Txid)
+		if err != nil {
+			return nil, err
+		}
+		if actual == nil {
+			result.Divergence = &Divergence{Entry: entry, Reason: fmt.Sprintf("no message from chaincode within %s", r.timeout)}
+			return result, nil
+		}
+		if reason := compare(entry.Message, actual); reason != "" {
+			result.Divergence = &Divergence{Entry: entry, Actual: actual, Reason: reason}
+			return result, nil
+		}
+		result.Replayed++
+	}
+
+	// Anything else the chaincode sent was never recorded
+	r.drain(in, pending)
+	for _, msgs := range pending {
+		if len(msgs) > 0 {
+			result.Divergence = &Divergence{Actual: msgs[0], Reason: "message not in recording"}
+			break
+		}
+	}
+	return result, nil
+}
+
+func startsChaincode(entry *Entry) bool {
+	return entry.Direction == PeerToChaincode &&
+		(entry.Message.Type == pb.ChaincodeMessage_INIT || entry.Message.Type == pb.ChaincodeMessage_READY)
+}
+
+// await returns the next message the chaincode sent for txid, buffering
+// messages of other transactions. It returns nil if none arrives in time.
+func (r *Replayer) await(in chan *received, pending map[string][]*pb.ChaincodeMessage, txid string) (*pb.ChaincodeMessage, error) {
+	if msgs := pending[txid]; len(msgs) > 0 {
+		pending[txid] = msgs[1:]
+		return msgs[0], nil
+	}
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case rcv := <-in:
+			if rcv.err != nil {
+				return nil, fmt.Errorf("Error receiving from chaincode: %s", rcv.err)
+			}
+			if rcv.msg == nil || rcv.msg.Type == pb.ChaincodeMessage_KEEPALIVE {
+				continue
+			}
+			if rcv.msg.Txid == txid {
+				return rcv.msg, nil
+			}
+			pending[rcv.msg.Txid] = append(pending[rcv.msg.Txid], rcv.msg)
+		case <-timer.C:
+			return nil, nil
+		}
+	}
+}
+
+// drain buffers what the chaincode sends after the end of the recording,
+// until it stays silent for drainQuiet (or the timeout, if shorter) or the
+// stream ends
+func (r *Replayer) drain(in chan *received, pending map[string][]*pb.ChaincodeMessage) {
+	quiet := drainQuiet
+	if r.timeout < quiet {
+		quiet = r.timeout
+	}
+	timer := time.NewTimer(quiet)
+	defer timer.Stop()
+	for {
+		select {
+		case rcv := <-in:
+			if rcv.err != nil {
+				return
+			}
+			if rcv.msg != nil && rcv.msg.Type != pb.ChaincodeMessage_KEEPALIVE {
+				pending[rcv.msg.Txid] = append(pending[rcv.msg.Txid], rcv.msg)
+			}
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(quiet)
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// compare returns why actual differs from the expected message, or the empty
+// string if they match. Timestamps and security contexts are not compared,
+// they are never the same across runs.
+func compare(expected, actual *pb.ChaincodeMessage) string {
+	if expected.Type != actual.Type {
+		return fmt.Sprintf("message type differs, expected %s", expected.Type)
+	}
+	if !bytes.Equal(expected.Payload, actual.Payload) {
+		return fmt.Sprintf("payload differs, expected %q got %q", expected.Payload, actual.Payload)
+	}
+	if (expected.ChaincodeEvent == nil) != (actual.ChaincodeEvent == nil) ||
+		(expected.ChaincodeEvent != nil && !proto.Equal(expected.ChaincodeEvent, actual.ChaincodeEvent)) {
+		return "chaincode event differs"
+	}
+	return ""
+}
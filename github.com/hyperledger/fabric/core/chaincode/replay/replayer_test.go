@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// appendChaincode appends its second argument to the state of the key named
+// by its first argument
+type appendChaincode struct{}
+
+func (t *appendChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (t *appendChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	value, err := stub.GetState(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value = append(value, []byte(args[1])...)
+	if err = stub.PutState(args[0], value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (t *appendChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return stub.GetState(args[0])
+}
+
+// peerStream is the peer side of an in process chaincode stream
+type peerStream struct {
+	recv <-chan *pb.ChaincodeMessage
+	send chan<- *pb.ChaincodeMessage
+}
+
+func (s *peerStream) Send(msg *pb.ChaincodeMessage) error {
+	s.send <- msg
+	return nil
+}
+
+func (s *peerStream) Recv() (*pb.ChaincodeMessage, error) {
+	return <-s.recv, nil
+}
+
+func startChaincode(name string) *peerStream {
+	toCC := make(chan *pb.ChaincodeMessage)
+	fromCC := make(chan *pb.ChaincodeMessage)
+	go shim.StartInProc([]string{"CORE_CHAINCODE_ID_NAME=" + name}, nil, &appendChaincode{}, toCC, fromCC)
+	return &peerStream{recv: fromCC, send: toCC}
+}
+
+func mustMarshal(t *testing.T, msg proto.Message) []byte {
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal %v: %s", msg, err)
+	}
+	return b
+}
+
+func recordAppend(t *testing.T, storedValue string) []*Entry {
+	path := filepath.Join(os.TempDir(), "hyperledger", "replay_test.rec")
+	os.Remove(path)
+	defer os.Remove(path)
+
+	recorder, err := NewRecorder(path, []string{"append"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %s", err)
+	}
+	input := &pb.ChaincodeInput{Args: [][]byte{[]byte("append"), []byte("a"), []byte("x")}}
+	putState := &pb.PutStateInfo{Key: "a", Value: []byte("vx")}
+	recorder.Record("append", PeerToChaincode, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Payload: mustMarshal(t, input), Txid: "tx1", SecurityContext: &pb.ChaincodeSecurityContext{Payload: mustMarshal(t, input)}})
+	recorder.Record("append", ChaincodeToPeer, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE, Payload: []byte("a"), Txid: "tx1"})
+	recorder.Record("append", PeerToChaincode, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: []byte(storedValue), Txid: "tx1"})
+	recorder.Record("append", ChaincodeToPeer, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_PUT_STATE, Payload: mustMarshal(t, putState), Txid: "tx1"})
+	recorder.Record("append", PeerToChaincode, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: "tx1"})
+	recorder.Record("append", ChaincodeToPeer, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_KEEPALIVE})
+	recorder.Record("append", ChaincodeToPeer, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Payload: []byte("vx"), Txid: "tx1"})
+	recorder.Record("other", PeerToChaincode, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "tx2"})
+	if err = recorder.Close(); err != nil {
+		t.Fatalf("Failed to close recorder: %s", err)
+	}
+
+	entries, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("Failed to load recording: %s", err)
+	}
+	return entries
+}
+
+func TestRecorderSelection(t *testing.T) {
+	entries := recordAppend(t, "v")
+	if len(entries) != 6 {
+		t.Fatalf("Expected 6 recorded entries, got %d", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.Seq != uint64(i+1) {
+			t.Errorf("Expected entry %d to have sequence number %d, got %d", i, i+1, entry.Seq)
+		}
+		if entry.ChaincodeID != "append" {
+			t.Errorf("Recorded message of unselected chaincode %s", entry.ChaincodeID)
+		}
+	}
+	if len(Filter(entries, "append", []string{"tx2"})) != 0 {
+		t.Error("Filter returned entries of an unselected transaction")
+	}
+}
+
+func TestReadRecordingInvalid(t *testing.T) {
+	file, err := ioutil.TempFile("", "replay_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %s", err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("{\"seq\": 1}\n")
+	file.Close()
+
+	if _, err = LoadRecording(file.Name()); err == nil {
+		t.Fatal("Expected an error loading an entry without a message")
+	}
+}
+
+func TestReplayMatches(t *testing.T) {
+	replayer := NewReplayer(recordAppend(t, "v"), 5*time.Second)
+	result, err := replayer.Replay(startChaincode("append"))
+	if err != nil {
+		t.Fatalf("Replay failed: %s", err)
+	}
+	if result.Divergence != nil {
+		t.Fatalf("Unexpected divergence: %s", result.Divergence)
+	}
+	if result.Replayed != 6 {
+		t.Fatalf("Expected 6 replayed messages, got %d", result.Replayed)
+	}
+}
+
+func TestReplayDiverges(t *testing.T) {
+	// The chaincode reads "w" this time, so it no longer writes what was recorded
+	replayer := NewReplayer(recordAppend(t, "w"), 5*time.Second)
+	result, err := replayer.Replay(startChaincode("append"))
+	if err != nil {
+		t.Fatalf("Replay failed: %s", err)
+	}
+	if result.Divergence == nil {
+		t.Fatal("Expected replay to diverge")
+	}
+	if result.Divergence.Entry == nil || result.Divergence.Entry.Message.Type != pb.ChaincodeMessage_PUT_STATE {
+		t.Fatalf("Expected divergence at %s, got %s", pb.ChaincodeMessage_PUT_STATE, result.Divergence)
+	}
+	if result.Replayed != 3 {
+		t.Fatalf("Expected 3 replayed messages before divergence, got %d", result.Replayed)
+	}
+}
+
+func TestReplayLateUnrecordedMessage(t *testing.T) {
+	entries := []*Entry{
+		{Seq: 1, ChaincodeID: "late", Direction: PeerToChaincode, Message: &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Txid: "tx1"}},
+		{Seq: 2, ChaincodeID: "late", Direction: ChaincodeToPeer, Message: &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "tx1"}},
+	}
+
+	// The chaincode completes the transaction as recorded, then sends a
+	// message the recording does not contain a little later
+	toCC := make(chan *pb.ChaincodeMessage, 4)
+	fromCC := make(chan *pb.ChaincodeMessage, 4)
+	fromCC <- &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER}
+	go func() {
+		for msg := range toCC {
+			if msg.Type == pb.ChaincodeMessage_TRANSACTION {
+				fromCC <- &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "tx1"}
+				time.Sleep(100 * time.Millisecond)
+				fromCC <- &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_PUT_STATE, Txid: "tx1"}
+			}
+		}
+	}()
+	defer close(toCC)
+
+	result, err := NewReplayer(entries, 5*time.Second).Replay(&peerStream{recv: fromCC, send: toCC})
+	if err != nil {
+		t.Fatalf("Replay failed: %s", err)
+	}
+	if result.Divergence == nil || result.Divergence.Actual == nil || result.Divergence.Actual.Type != pb.ChaincodeMessage_PUT_STATE {
+		t.Fatalf("Expected the late %s to diverge, got %v", pb.ChaincodeMessage_PUT_STATE, result.Divergence)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/policy"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/mitchellh/mapstructure"
+)
+
+// RequiredPluginName is the name of the reference plugin
+const RequiredPluginName = "required"
+
+func init() {
+	Register(RequiredPluginName, newRequiredPlugin)
+}
+
+// requiredConfig is the configuration of the required plugin
+type requiredConfig struct {
+	// Signatures is the number of distinct signers which must sign the
+	// chaincode spec of the transaction
+	Signatures int `mapstructure:"signatures"`
+	// Signers are files holding the PEM encoded certificates of the signers
+	Signers []string `mapstructure:"signers"`
+	// Attributes are the attribute values the certificate of the
+	// transaction must have
+	Attributes map[string]string `mapstructure:"attributes"`
+}
+
+// SpecSignatures is the content of the metadata of a chaincode spec carrying
+// the signatures checked by the required plugin
+type SpecSignatures struct {
+	Signatures [][]byte `json:"signatures"`
+}
+
+// requiredPlugin rejects the transactions whose chaincode spec is not signed
+// by enough of the configured signers, or whose certificate does not have
+// the configured attribute values
+type requiredPlugin struct {
+	signatures int
+	signers    []*ecdsa.PublicKey
+	attributes map[string]string
+}
+
+func newRequiredPlugin(config map[string]interface{}) (Plugin, error) {
+	rc := &requiredConfig{}
+	if err := mapstructure.Decode(config, rc); err != nil {
+		return nil, err
+	}
+	p := &requiredPlugin{signatures: rc.Signatures, attributes: rc.Attributes}
+	for _, file := range rc.Signers {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading signer certificate %s: %s", file, err)
+		}
+		cert, err := primitives.PEMtoCertificate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing signer certificate %s: %s", file, err)
+		}
+		key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("Signer certificate %s does not hold an ECDSA key", file)
+		}
+		p.signers = append(p.signers, key)
+	}
+	if p.signatures < 0 || p.signatures > len(p.signers) {
+		return nil, fmt.Errorf("%d signatures required of %d signers", p.signatures, len(p.signers))
+	}
+	if p.signatures == 0 && len(p.attributes) == 0 {
+		return nil, fmt.Errorf("Neither signatures nor attributes required")
+	}
+	return p, nil
+}
+
+// getSpec returns the chaincode spec of a deploy or invoke transaction
+func getSpec(tx *pb.Transaction) (*pb.ChaincodeSpec, error) {
+	var spec *pb.ChaincodeSpec
+	if tx.Type == pb.Transaction_CHAINCODE_DEPLOY {
+		cds := &pb.ChaincodeDeploymentSpec{}
+		if err := proto.Unmarshal(tx.Payload, cds); err != nil {
+			return nil, err
+		}
+		spec = cds.ChaincodeSpec
+	} else {
+		ci := &pb.ChaincodeInvocationSpec{}
+		if err := proto.Unmarshal(tx.Payload, ci); err != nil {
+			return nil, err
+		}
+		spec = ci.ChaincodeSpec
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("Transaction has no chaincode spec")
+	}
+	return spec, nil
+}
+
+// specDigest returns the digest signed by the signers: the SHA-256 hash of
+// the chaincode spec without its metadata
+func specDigest(spec *pb.ChaincodeSpec) ([]byte, error) {
+	unsigned := *spec
+	unsigned.Metadata = nil
+	raw, err := proto.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(raw)
+	return digest[:], nil
+}
+
+// SignSpec adds the signature of key to the metadata of the chaincode spec,
+// in the form expected by the required plugin
+func SignSpec(key *ecdsa.PrivateKey, spec *pb.ChaincodeSpec) error {
+	digest, err := specDigest(spec)
+	if err != nil {
+		return err
+	}
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+	if err != nil {
+		return err
+	}
+	sig, err := asn1.Marshal(primitives.ECDSASignature{R: r, S: s})
+	if err != nil {
+		return err
+	}
+	sigs := &SpecSignatures{}
+	if len(spec.Metadata) > 0 {
+		if err = json.Unmarshal(spec.Metadata, sigs); err != nil {
+			return fmt.Errorf("Metadata does not hold signatures: %s", err)
+		}
+	}
+	sigs.Signatures = append(sigs.Signatures, sig)
+	spec.Metadata, err = json.Marshal(sigs)
+	return err
+}
+
+func (p *requiredPlugin) ValidateTransaction(tx *pb.Transaction) error {
+	if p.signatures > 0 {
+		if err := p.checkSignatures(tx); err != nil {
+			return err
+		}
+	}
+	if len(p.attributes) > 0 {
+		if len(tx.Cert) == 0 {
+			return fmt.Errorf("Transaction has no certificate to read attributes from")
+		}
+		id, err := policy.NewIdentity(tx.Cert)
+		if err != nil {
+			return err
+		}
+		for name, value := range p.attributes {
+			if actual, ok := id.Attributes[name]; !ok || actual != value {
+				return fmt.Errorf("Attribute %s of the transaction certificate must be %q", name, value)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *requiredPlugin) checkSignatures(tx *pb.Transaction) error {
+	spec, err := getSpec(tx)
+	if err != nil {
+		return err
+	}
+	digest, err := specDigest(spec)
+	if err != nil {
+		return err
+	}
+	sigs := &SpecSignatures{}
+	if len(spec.Metadata) > 0 {
+		if err = json.Unmarshal(spec.Metadata, sigs); err != nil {
+			return fmt.Errorf("Chaincode spec metadata does not hold signatures: %s", err)
+		}
+	}
+
+	signed := make(map[int]bool)
+	for _, raw := range sigs.Signatures {
+		sig := &primitives.ECDSASignature{}
+		if _, err = asn1.Unmarshal(raw, sig); err != nil {
+			continue
+		}
+		for i, signer := range p.signers {
+			if !signed[i] && ecdsa.Verify(signer, digest, sig.R, sig.S) {
+				signed[i] = true
+				break
+			}
+		}
+	}
+	if len(signed) < p.signatures {
+		return fmt.Errorf("Chaincode spec signed by %d signers, %d required", len(signed), p.signatures)
+	}
+	return nil
+}
+
+// ValidateBatch has nothing to check across transactions, every transaction
+// of the batch is checked on its own by ValidateTransaction
+func (p *requiredPlugin) ValidateBatch(txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+	return make([]error, len(txs))
+}
@@ -0,0 +1,247 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation lets validators run pluggable checks on the
+// transactions of a chaincode before they are executed and committed.
+// Plugins are registered at startup, usually from the init function of
+// their package, and bound to chaincodes in the chaincode.validation section
+// of the configuration.
+package validation
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+)
+
+var logger = logging.MustGetLogger("chaincode/validation")
+
+// Plugin validates transactions. Transactions are handed to plugins
+// decrypted. As every validator must reach the same verdict, plugins must be
+// deterministic and may only depend on the transactions and the ledger.
+type Plugin interface {
+	// ValidateTransaction is called for every deploy and invoke transaction
+	// of the chaincodes the plugin is bound to, right before it is executed
+	ValidateTransaction(tx *pb.Transaction) error
+
+	// ValidateBatch is called with the transactions of a batch bound to the
+	// plugin which executed successfully and the results of their execution,
+	// right before the batch is committed. It returns one error per
+	// transaction, nil for the transactions it accepts. The batch is
+	// executed again without the rejected transactions before it is
+	// committed, and ValidateBatch is called again on the new results.
+	ValidateBatch(txs []*pb.Transaction, results []*pb.TransactionResult) []error
+}
+
+// Factory creates a plugin from its configuration
+type Factory func(config map[string]interface{}) (Plugin, error)
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory
+}{factories: make(map[string]Factory)}
+
+// Register makes a plugin available under name. It is meant to be called at
+// startup, before the plugins are loaded from the configuration.
+func Register(name string, factory Factory) error {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.factories[name]; ok {
+		return fmt.Errorf("Validation plugin %s already registered", name)
+	}
+	registry.factories[name] = factory
+	logger.Debugf("Registered validation plugin %s", name)
+	return nil
+}
+
+// Registered returns the names of the registered plugins
+func Registered() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	var names []string
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PluginConfig configures an instance of a registered plugin
+type PluginConfig struct {
+	Name   string                 `mapstructure:"name"`
+	Config map[string]interface{} `mapstructure:"config"`
+}
+
+// BindingConfig binds plugins to the chaincodes whose name or path match one
+// of the patterns, as in path.Match. No pattern matches all chaincodes.
+type BindingConfig struct {
+	Chaincodes []string        `mapstructure:"chaincodes"`
+	Plugins    []*PluginConfig `mapstructure:"plugins"`
+}
+
+type instance struct {
+	name   string
+	plugin Plugin
+}
+
+type binding struct {
+	chaincodes []string
+	plugins    []*instance
+}
+
+// Manager runs the plugins bound to the chaincode of each transaction
+type Manager struct {
+	bindings []*binding
+}
+
+// NewManager instantiates the plugins of the bindings
+func NewManager(bindings []*BindingConfig) (*Manager, error) {
+	m := &Manager{}
+	for i, bc := range bindings {
+		if bc == nil {
+			return nil, fmt.Errorf("Validation binding %d is empty", i)
+		}
+		for _, pattern := range bc.Chaincodes {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("Invalid chaincode pattern '%s' in validation binding %d: %s", pattern, i, err)
+			}
+		}
+		b := &binding{chaincodes: bc.Chaincodes}
+		for _, pc := range bc.Plugins {
+			plugin, err := newPlugin(pc)
+			if err != nil {
+				return nil, fmt.Errorf("Error in validation binding %d: %s", i, err)
+			}
+			b.plugins = append(b.plugins, &instance{name: pc.Name, plugin: plugin})
+		}
+		m.bindings = append(m.bindings, b)
+	}
+	return m, nil
+}
+
+func newPlugin(pc *PluginConfig) (Plugin, error) {
+	if pc == nil {
+		return nil, fmt.Errorf("Empty validation plugin configuration")
+	}
+	registry.RLock()
+	factory, ok := registry.factories[pc.Name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown validation plugin '%s', registered plugins are %v", pc.Name, Registered())
+	}
+	plugin, err := factory(pc.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating validation plugin %s: %s", pc.Name, err)
+	}
+	return plugin, nil
+}
+
+// NewManagerFromConfig creates a manager for the chaincode.validation section
+// of the configuration, it returns nil if validation plugins are disabled
+func NewManagerFromConfig() (*Manager, error) {
+	if !viper.GetBool("chaincode.validation.enabled") {
+		return nil, nil
+	}
+	var bindings []*BindingConfig
+	if err := viper.UnmarshalKey("chaincode.validation.bindings", &bindings); err != nil {
+		return nil, fmt.Errorf("Error reading validation bindings: %s", err)
+	}
+	return NewManager(bindings)
+}
+
+func (m *Manager) pluginsFor(chaincodeID *pb.ChaincodeID) []*instance {
+	var plugins []*instance
+	for _, b := range m.bindings {
+		if b.matches(chaincodeID) {
+			plugins = append(plugins, b.plugins...)
+		}
+	}
+	return plugins
+}
+
+func (b *binding) matches(chaincodeID *pb.ChaincodeID) bool {
+	if len(b.chaincodes) == 0 {
+		return true
+	}
+	for _, pattern := range b.chaincodes {
+		if match(pattern, chaincodeID.Name) || match(pattern, chaincodeID.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, name string) bool {
+	if name == "" {
+		return false
+	}
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+// ValidateTransaction runs the plugins bound to the chaincode on tx
+func (m *Manager) ValidateTransaction(chaincodeID *pb.ChaincodeID, tx *pb.Transaction) error {
+	for _, inst := range m.pluginsFor(chaincodeID) {
+		if err := inst.plugin.ValidateTransaction(tx); err != nil {
+			return fmt.Errorf("Transaction rejected by validation plugin %s: %s", inst.name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateBatch runs every plugin on the transactions of the batch bound to
+// it and their results. chaincodeIDs holds the chaincode of each
+// transaction, transactions with a nil chaincode are not validated. It
+// returns one error per transaction, the first reported for it by a plugin.
+func (m *Manager) ValidateBatch(chaincodeIDs []*pb.ChaincodeID, txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+	errs := make([]error, len(txs))
+
+	// Hand each plugin instance all of its transactions at once
+	var order []*instance
+	bound := make(map[*instance][]int)
+	for i, chaincodeID := range chaincodeIDs {
+		if chaincodeID == nil {
+			continue
+		}
+		for _, inst := range m.pluginsFor(chaincodeID) {
+			if _, ok := bound[inst]; !ok {
+				order = append(order, inst)
+			}
+			bound[inst] = append(bound[inst], i)
+		}
+	}
+
+	for _, inst := range order {
+		indexes := bound[inst]
+		batch := make([]*pb.Transaction, len(indexes))
+		batchResults := make([]*pb.TransactionResult, len(indexes))
+		for j, i := range indexes {
+			batch[j], batchResults[j] = txs[i], results[i]
+		}
+		verdicts := inst.plugin.ValidateBatch(batch, batchResults)
+		for j, err := range verdicts {
+			if j < len(indexes) && err != nil && errs[indexes[j]] == nil {
+				errs[indexes[j]] = fmt.Errorf("Transaction rejected by validation plugin %s: %s", inst.name, err)
+			}
+		}
+	}
+	return errs
+}
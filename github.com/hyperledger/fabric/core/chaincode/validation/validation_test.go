@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// limitPlugin accepts at most limit transactions per batch, and rejects
+// transactions without payload or handed with the result of another one
+type limitPlugin struct {
+	limit int
+}
+
+func (p *limitPlugin) ValidateTransaction(tx *pb.Transaction) error {
+	if len(tx.Payload) == 0 {
+		return fmt.Errorf("empty payload")
+	}
+	return nil
+}
+
+func (p *limitPlugin) ValidateBatch(txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+	errs := make([]error, len(txs))
+	for i := range txs {
+		if results[i].Txid != txs[i].Txid {
+			errs[i] = fmt.Errorf("result of transaction %s", results[i].Txid)
+		} else if i >= p.limit {
+			errs[i] = fmt.Errorf("batch limit of %d reached", p.limit)
+		}
+	}
+	return errs
+}
+
+func init() {
+	Register("limit", func(config map[string]interface{}) (Plugin, error) {
+		limit, ok := config["limit"].(int)
+		if !ok {
+			return nil, fmt.Errorf("limit required")
+		}
+		return &limitPlugin{limit: limit}, nil
+	})
+}
+
+func TestRegister(t *testing.T) {
+	if err := Register(RequiredPluginName, newRequiredPlugin); err == nil {
+		t.Fatal("Expected registering a plugin twice to fail")
+	}
+	registered := strings.Join(Registered(), ",")
+	if !strings.Contains(registered, "limit") || !strings.Contains(registered, RequiredPluginName) {
+		t.Fatalf("Unexpected registered plugins %s", registered)
+	}
+
+	_, err := NewManager([]*BindingConfig{{Plugins: []*PluginConfig{{Name: "unknown"}}}})
+	if err == nil || !strings.Contains(err.Error(), RequiredPluginName) {
+		t.Fatalf("Expected an error listing the registered plugins, got %v", err)
+	}
+}
+
+func TestBindings(t *testing.T) {
+	m, err := NewManager([]*BindingConfig{
+		{Chaincodes: []string{"limited*"}, Plugins: []*PluginConfig{{Name: "limit", Config: map[string]interface{}{"limit": 1}}}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create manager: %s", err)
+	}
+
+	limited := &pb.ChaincodeID{Name: "limitedcc"}
+	free := &pb.ChaincodeID{Name: "freecc", Path: "github.com/example/free"}
+	empty := &pb.Transaction{}
+	if err = m.ValidateTransaction(limited, empty); err == nil {
+		t.Error("Expected the plugin to reject a transaction without payload")
+	}
+	if err = m.ValidateTransaction(free, empty); err != nil {
+		t.Errorf("Expected the transaction of an unbound chaincode to be accepted: %s", err)
+	}
+
+	txs := []*pb.Transaction{{Txid: "1"}, {Txid: "2"}, {Txid: "3"}, {Txid: "4"}}
+	results := []*pb.TransactionResult{{Txid: "1"}, {Txid: "2"}, {Txid: "3"}, {Txid: "4"}}
+	errs := m.ValidateBatch([]*pb.ChaincodeID{free, limited, nil, limited}, txs, results)
+	if len(errs) != len(txs) {
+		t.Fatalf("Expected %d results, got %d", len(txs), len(errs))
+	}
+	for i, expectErr := range []bool{false, false, false, true} {
+		if (errs[i] != nil) != expectErr {
+			t.Errorf("Unexpected result for transaction %d: %v", i, errs[i])
+		}
+	}
+}
+
+func writeSigner(t *testing.T, dir string, n int) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(n)),
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("signer%d", n)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %s", err)
+	}
+	file := filepath.Join(dir, fmt.Sprintf("signer%d.pem", n))
+	if err = ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %s", err)
+	}
+	return key, file
+}
+
+func invokeTx(t *testing.T, spec *pb.ChaincodeSpec) *pb.Transaction {
+	tx, err := pb.NewChaincodeExecute(&pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}, "tx", pb.Transaction_CHAINCODE_INVOKE)
+	if err != nil {
+		t.Fatalf("Failed to create transaction: %s", err)
+	}
+	return tx
+}
+
+func TestRequiredSignatures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "validation_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var keys []*ecdsa.PrivateKey
+	var signers []interface{}
+	for i := 0; i < 3; i++ {
+		key, file := writeSigner(t, dir, i)
+		keys = append(keys, key)
+		signers = append(signers, file)
+	}
+	plugin, err := newRequiredPlugin(map[string]interface{}{"signatures": 2, "signers": signers})
+	if err != nil {
+		t.Fatalf("Failed to create plugin: %s", err)
+	}
+
+	spec := &pb.ChaincodeSpec{ChaincodeID: &pb.ChaincodeID{Name: "mycc"}, CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("transfer"), []byte("10")}}}
+	if err = plugin.ValidateTransaction(invokeTx(t, spec)); err == nil {
+		t.Fatal("Expected an unsigned transaction to be rejected")
+	}
+	if err = SignSpec(keys[0], spec); err != nil {
+		t.Fatalf("Failed to sign spec: %s", err)
+	}
+	if err = SignSpec(keys[0], spec); err != nil {
+		t.Fatalf("Failed to sign spec: %s", err)
+	}
+	if err = plugin.ValidateTransaction(invokeTx(t, spec)); err == nil {
+		t.Fatal("Expected a transaction signed twice by the same signer to be rejected")
+	}
+	if err = SignSpec(keys[2], spec); err != nil {
+		t.Fatalf("Failed to sign spec: %s", err)
+	}
+	if err = plugin.ValidateTransaction(invokeTx(t, spec)); err != nil {
+		t.Fatalf("Expected a transaction signed by two signers to be accepted: %s", err)
+	}
+
+	// The signatures do not cover a different invocation
+	spec.CtorMsg.Args[1] = []byte("1000")
+	if err = plugin.ValidateTransaction(invokeTx(t, spec)); err == nil {
+		t.Fatal("Expected a transaction with a modified spec to be rejected")
+	}
+
+	if _, err = newRequiredPlugin(map[string]interface{}{"signatures": 4, "signers": signers}); err == nil {
+		t.Fatal("Expected requiring more signatures than signers to fail")
+	}
+}
+
+func TestRequiredAttributes(t *testing.T) {
+	plugin, err := newRequiredPlugin(map[string]interface{}{"attributes": map[string]string{"role": "operator"}})
+	if err != nil {
+		t.Fatalf("Failed to create plugin: %s", err)
+	}
+	spec := &pb.ChaincodeSpec{ChaincodeID: &pb.ChaincodeID{Name: "mycc"}, CtorMsg: &pb.ChaincodeInput{}}
+	if err = plugin.ValidateTransaction(invokeTx(t, spec)); err == nil {
+		t.Fatal("Expected a transaction without certificate to be rejected")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/validation"
+	"github.com/hyperledger/fabric/core/util"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/mitchellh/mapstructure"
+	"golang.org/x/net/context"
+)
+
+// ChaincodeValidationPlugin is the name of the validation plugin delegating
+// to a chaincode, usually a system chaincode
+const ChaincodeValidationPlugin = "chaincode"
+
+func init() {
+	validation.Register(ChaincodeValidationPlugin, newChaincodeValidator)
+}
+
+// chaincodeValidator queries a chaincode to validate transactions. The
+// chaincode is queried with the validate function and the marshaled
+// transaction, an error rejects the transaction. Batches are queried with
+// the validateBatch function, all the marshaled transactions and then the
+// marshaled results of their execution in the same order, the chaincode
+// returns a JSON array holding why each transaction is rejected, or an
+// empty string for the accepted ones.
+type chaincodeValidator struct {
+	Name string `mapstructure:"name"`
+}
+
+func newChaincodeValidator(config map[string]interface{}) (validation.Plugin, error) {
+	v := &chaincodeValidator{}
+	if err := mapstructure.Decode(config, v); err != nil {
+		return nil, err
+	}
+	if v.Name == "" {
+		return nil, fmt.Errorf("The name of the validating chaincode is required")
+	}
+	return v, nil
+}
+
+func (v *chaincodeValidator) query(function string, msgs ...proto.Message) ([]byte, error) {
+	args := [][]byte{[]byte(function)}
+	for _, msg := range msgs {
+		raw, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, raw)
+	}
+
+	spec := &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Name: v.Name}, CtorMsg: &pb.ChaincodeInput{Args: args}}
+	query, err := pb.NewChaincodeExecute(&pb.ChaincodeInvocationSpec{ChaincodeSpec: spec}, util.GenerateUUID(), pb.Transaction_CHAINCODE_QUERY)
+	if err != nil {
+		return nil, err
+	}
+
+	// Not through Execute: the query is the peer's own, and not subject to
+	// the policy nor to validation
+	chain := GetChain(DefaultChain)
+	ctxt := context.Background()
+	cID, cMsg, err := chain.Launch(ctxt, query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to launch validating chaincode %s: %s", v.Name, err)
+	}
+	ccMsg, err := createQueryMessage(query.Txid, cMsg)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := chain.Execute(ctxt, cID.Name, ccMsg, chain.GetExecuteTimeout(query.ChainID, cID.Name, 0), query)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query validating chaincode %s: %s", v.Name, err)
+	}
+	if resp.Type != pb.ChaincodeMessage_QUERY_COMPLETED {
+		return nil, fmt.Errorf("%s", resp.Payload)
+	}
+	return resp.Payload, nil
+}
+
+func (v *chaincodeValidator) ValidateTransaction(tx *pb.Transaction) error {
+	_, err := v.query("validate", tx)
+	return err
+}
+
+func (v *chaincodeValidator) ValidateBatch(txs []*pb.Transaction, results []*pb.TransactionResult) []error {
+	errs := make([]error, len(txs))
+	msgs := make([]proto.Message, 0, len(txs)+len(results))
+	for _, tx := range txs {
+		msgs = append(msgs, tx)
+	}
+	for _, result := range results {
+		msgs = append(msgs, result)
+	}
+	payload, err := v.query("validateBatch", msgs...)
+	if err == nil {
+		var reasons []string
+		if err = json.Unmarshal(payload, &reasons); err == nil && len(reasons) != len(txs) {
+			err = fmt.Errorf("expected %d verdicts, got %d", len(txs), len(reasons))
+		}
+		if err == nil {
+			for i, reason := range reasons {
+				if reason != "" {
+					errs[i] = fmt.Errorf("%s", reason)
+				}
+			}
+			return errs
+		}
+		err = fmt.Errorf("Invalid answer of validating chaincode %s: %s", v.Name, err)
+	}
+	// Without a verdict nothing of the batch is accepted
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
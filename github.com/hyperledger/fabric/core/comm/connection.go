@@ -1,6 +1,12 @@
 package comm
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
@@ -53,3 +59,97 @@ func InitTLSForPeer() credentials.TransportCredentials {
 	}
 	return creds
 }
+
+// InitMutualTLSForPeer returns TLS credentials for a peer chatting with
+// another peer, which also present the TLS certificate of this peer, so that
+// the remote peer can bind the connection to the identity of this peer
+func InitMutualTLSForPeer() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(viper.GetString("peer.tls.cert.file"), viper.GetString("peer.tls.key.file"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the TLS key pair of the peer: %s", err)
+	}
+	pool, err := peerTLSRootCAs()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   viper.GetString("peer.tls.serverhostoverride"),
+	}), nil
+}
+
+// InitTLSForServer returns TLS credentials for the peer server. The clients
+// may present a certificate, which is verified against the root certificate
+// of the peer TLS certificates. Whether a certificate is required is up to
+// the services, as the CLI and applications do not present one.
+func InitTLSForServer() (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(viper.GetString("peer.tls.cert.file"), viper.GetString("peer.tls.key.file"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the TLS key pair of the peer: %s", err)
+	}
+	pool, err := peerTLSRootCAs()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    pool,
+	}), nil
+}
+
+// peerTLSRootCAs returns the root certificates the TLS certificates of the
+// peers are verified against, peer.tls.rootcert.file, or the certificate of
+// this peer if not set
+func peerTLSRootCAs() (*x509.CertPool, error) {
+	file := viper.GetString("peer.tls.rootcert.file")
+	if file == "" {
+		file = viper.GetString("peer.tls.cert.file")
+	}
+	pem, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the TLS root certificates: %s", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("No TLS root certificate in %s", file)
+	}
+	return pool, nil
+}
+
+// CertCapture wraps client credentials to record the certificate the server
+// authenticated with, which gRPC does not expose to clients
+type CertCapture struct {
+	credentials.TransportCredentials
+	mutex sync.Mutex
+	cert  *x509.Certificate
+}
+
+// NewCertCapture wraps the client credentials
+func NewCertCapture(creds credentials.TransportCredentials) *CertCapture {
+	return &CertCapture{TransportCredentials: creds}
+}
+
+// ClientHandshake performs the handshake of the wrapped credentials and records the server certificate
+func (c *CertCapture) ClientHandshake(addr string, rawConn net.Conn, timeout time.Duration) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ClientHandshake(addr, rawConn, timeout)
+	if err != nil {
+		return conn, info, err
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			c.mutex.Lock()
+			c.cert = certs[0]
+			c.mutex.Unlock()
+		}
+	}
+	return conn, info, nil
+}
+
+// PeerCertificate returns the certificate of the latest server handshaked with, or nil
+func (c *CertCapture) PeerCertificate() *x509.Certificate {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cert
+}
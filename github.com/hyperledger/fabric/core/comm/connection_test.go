@@ -1,13 +1,26 @@
 package comm
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric/core/config"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 func TestConnection_Correct(t *testing.T) {
@@ -42,3 +55,100 @@ func TestConnection_WrongAddress(t *testing.T) {
 		tmpConn.Close()
 	}
 }
+
+func writeTestKeyPair(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate a key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "vp0"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Could not create a certificate: %s", err)
+	}
+	keyRaw, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Could not marshal the key: %s", err)
+	}
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}), 0600)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyRaw}), 0600)
+	return certFile, keyFile
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "comm")
+	defer os.RemoveAll(dir)
+	certFile, keyFile := writeTestKeyPair(t, dir)
+	viper.Set("peer.tls.cert.file", certFile)
+	viper.Set("peer.tls.key.file", keyFile)
+	viper.Set("peer.tls.rootcert.file", "")
+	viper.Set("peer.tls.serverhostoverride", "")
+
+	creds, err := InitTLSForServer()
+	if err != nil {
+		t.Fatalf("Could not create the server credentials: %s", err)
+	}
+	serverCreds := &clientCertCapture{TransportCredentials: creds, certs: make(chan *x509.Certificate, 1)}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %s", err)
+	}
+	server := grpc.NewServer(grpc.Creds(serverCreds))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	clientCreds, err := InitMutualTLSForPeer()
+	if err != nil {
+		t.Fatalf("Could not create the client credentials: %s", err)
+	}
+	capture := NewCertCapture(clientCreds)
+	conn, err := NewClientConnectionWithAddress(lis.Addr().String(), true, true, capture)
+	if err != nil {
+		t.Fatalf("Could not connect with mutual TLS: %s", err)
+	}
+	defer conn.Close()
+
+	if cert := capture.PeerCertificate(); cert == nil || cert.Subject.CommonName != "vp0" {
+		t.Errorf("Expected the server certificate to be captured, got %v", cert)
+	}
+	select {
+	case cert := <-serverCreds.certs:
+		if cert == nil || cert.Subject.CommonName != "vp0" {
+			t.Errorf("Expected the server to see the client certificate, got %v", cert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Expected the server to complete the handshake")
+	}
+}
+
+// clientCertCapture wraps server credentials to record the certificate each
+// client authenticated with, nil if it presented none
+type clientCertCapture struct {
+	credentials.TransportCredentials
+	certs chan *x509.Certificate
+}
+
+func (c *clientCertCapture) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	conn, info, err := c.TransportCredentials.ServerHandshake(rawConn)
+	if err != nil {
+		return conn, info, err
+	}
+	var cert *x509.Certificate
+	if tlsInfo, ok := info.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+		cert = tlsInfo.State.PeerCertificates[0]
+	}
+	c.certs <- cert
+	return conn, info, nil
+}
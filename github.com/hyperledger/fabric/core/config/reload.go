@@ -0,0 +1,363 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+// Reloadable is a subsystem which applies changes of its configuration
+// while the peer is running
+type Reloadable struct {
+	// Name identifies the subsystem in the logs
+	Name string
+	// Keys are the configuration keys the subsystem applies, a key ending
+	// with '.' stands for all the keys under it
+	Keys []string
+	// Validate checks the new configuration before anything is applied. It
+	// may be nil
+	Validate func(v *viper.Viper) error
+	// Apply applies the new configuration, which it reads through viper. It
+	// may be nil for subsystems which read the keys each time they use them
+	Apply func() error
+}
+
+// ReloadResult lists the configuration keys a reload found changed
+type ReloadResult struct {
+	// Applied are the keys applied while the peer is running
+	Applied []string
+	// RestartRequired are the keys which only take effect when the peer
+	// is restarted
+	RestartRequired []string
+}
+
+var reloader struct {
+	sync.Mutex
+	reloadables []Reloadable
+	loaded      *viper.Viper // The configuration as last loaded
+	loadedData  []byte       // The configuration as last loaded, in the format of the file
+}
+
+// RegisterReloadable registers a subsystem which applies changes of its
+// configuration while the peer is running
+func RegisterReloadable(r Reloadable) {
+	reloader.Lock()
+	defer reloader.Unlock()
+	reloader.reloadables = append(reloader.reloadables, r)
+}
+
+// readConfigFile reads the configuration file viper was loaded from, and
+// returns its data and format
+func readConfigFile() ([]byte, string, error) {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil, "", fmt.Errorf("No configuration file was loaded")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("Error reading configuration file %s: %s", path, err)
+	}
+	return data, strings.TrimPrefix(filepath.Ext(path), "."), nil
+}
+
+// parseConfig parses configuration data in the format
+func parseConfig(data []byte, configType string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType(configType)
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("Error parsing configuration file %s: %s", viper.ConfigFileUsed(), err)
+	}
+	return v, nil
+}
+
+// InitReload records the configuration file as loaded, the next reload
+// applies the changes made to it since
+func InitReload() error {
+	data, configType, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	loaded, err := parseConfig(data, configType)
+	if err != nil {
+		return err
+	}
+	reloader.Lock()
+	defer reloader.Unlock()
+	reloader.loaded, reloader.loadedData = loaded, data
+	return nil
+}
+
+// flatten adds the value under key to the settings, a map is added as the
+// keys under it
+func flatten(settings map[string]interface{}, key string, value interface{}) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			flatten(settings, key+"."+strings.ToLower(k), v)
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			flatten(settings, key+"."+strings.ToLower(fmt.Sprint(k)), v)
+		}
+	default:
+		settings[key] = value
+	}
+}
+
+// settings returns the values of the configuration by full key
+func settings(v *viper.Viper) map[string]interface{} {
+	s := make(map[string]interface{})
+	for _, k := range v.AllKeys() {
+		flatten(s, strings.ToLower(k), v.Get(k))
+	}
+	return s
+}
+
+// changedKeys returns the keys which differ between the configurations
+func changedKeys(old, new *viper.Viper) []string {
+	oldSettings, newSettings := settings(old), settings(new)
+	keys := make(map[string]bool)
+	for k := range oldSettings {
+		keys[k] = true
+	}
+	for k := range newSettings {
+		keys[k] = true
+	}
+	var changed []string
+	for k := range keys {
+		if !reflect.DeepEqual(oldSettings[k], newSettings[k]) {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// entry returns the key of a map of the configuration which matches the
+// name regardless of case, as viper does, and its value
+func entry(m interface{}, name string) (interface{}, interface{}, bool) {
+	switch m := m.(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			if strings.ToLower(k) == name {
+				return k, v, true
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			if strings.ToLower(fmt.Sprint(k)) == name {
+				return k, v, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// setEntry sets or, for a nil value, removes a key of a map of the configuration
+func setEntry(m interface{}, key, value interface{}) {
+	switch m := m.(type) {
+	case map[string]interface{}:
+		if value == nil {
+			delete(m, fmt.Sprint(key))
+		} else {
+			m[fmt.Sprint(key)] = value
+		}
+	case map[interface{}]interface{}:
+		if value == nil {
+			delete(m, key)
+		} else {
+			m[key] = value
+		}
+	}
+}
+
+// keepValue sets the value under the path of a key in the configuration to
+// its value in the old configuration, or removes it if the old one has none
+func keepValue(config, old interface{}, path []string) {
+	key, value, found := entry(config, path[0])
+	oldKey, oldValue, oldFound := entry(old, path[0])
+	if len(path) == 1 {
+		if found {
+			setEntry(config, key, nil)
+		}
+		if oldFound {
+			setEntry(config, oldKey, oldValue)
+		}
+		return
+	}
+	if !found {
+		if !oldFound {
+			return
+		}
+		if _, ok := config.(map[string]interface{}); ok {
+			value = make(map[string]interface{})
+		} else {
+			value = make(map[interface{}]interface{})
+		}
+		setEntry(config, oldKey, value)
+	}
+	keepValue(value, oldValue, path[1:])
+}
+
+// keepKeys returns the new configuration data with the keys set to their
+// values in the old one
+func keepKeys(old, new []byte, configType string, keys []string) ([]byte, error) {
+	var unmarshal func([]byte, interface{}) error
+	var marshal func(interface{}) ([]byte, error)
+	switch strings.ToLower(configType) {
+	case "yaml", "yml":
+		unmarshal, marshal = yaml.Unmarshal, yaml.Marshal
+	case "json":
+		unmarshal, marshal = json.Unmarshal, json.Marshal
+	default:
+		return nil, fmt.Errorf("Unsupported configuration file format %s", configType)
+	}
+
+	oldConfig, newConfig := make(map[string]interface{}), make(map[string]interface{})
+	if err := unmarshal(old, &oldConfig); err != nil {
+		return nil, fmt.Errorf("Error parsing configuration: %s", err)
+	}
+	if err := unmarshal(new, &newConfig); err != nil {
+		return nil, fmt.Errorf("Error parsing configuration: %s", err)
+	}
+	for _, key := range keys {
+		keepValue(newConfig, oldConfig, strings.Split(key, "."))
+	}
+	return marshal(newConfig)
+}
+
+// appliesTo returns whether the subsystem applies the key
+func (r *Reloadable) appliesTo(key string) bool {
+	for _, k := range r.Keys {
+		k = strings.ToLower(k)
+		if key == k || (strings.HasSuffix(k, ".") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload reads the configuration file again and applies the changed keys
+// to the subsystems which can change while the peer is running. The changes
+// are validated first, nothing is applied if any of them is invalid. The
+// changed keys no subsystem applies are reported, they keep their values
+// until the peer is restarted.
+func Reload() (*ReloadResult, error) {
+	reloader.Lock()
+	defer reloader.Unlock()
+	if reloader.loaded == nil {
+		return nil, fmt.Errorf("Configuration reload is not initialized")
+	}
+
+	data, configType, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := parseConfig(data, configType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReloadResult{}
+	var affected []*Reloadable
+	for _, key := range changedKeys(reloader.loaded, newConfig) {
+		applied := false
+		for i := range reloader.reloadables {
+			r := &reloader.reloadables[i]
+			if !r.appliesTo(key) {
+				continue
+			}
+			applied = true
+			found := false
+			for _, a := range affected {
+				found = found || a == r
+			}
+			if !found {
+				affected = append(affected, r)
+			}
+		}
+		if applied {
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	// The subsystems which read the keys requiring a restart keep reading
+	// the values they started with
+	if len(result.RestartRequired) > 0 {
+		if data, err = keepKeys(reloader.loadedData, data, configType, result.RestartRequired); err != nil {
+			return nil, err
+		}
+		if newConfig, err = parseConfig(data, configType); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range affected {
+		if r.Validate == nil {
+			continue
+		}
+		if err := r.Validate(newConfig); err != nil {
+			return nil, fmt.Errorf("Invalid %s configuration: %s", r.Name, err)
+		}
+	}
+
+	// Environment variables and command line flags keep precedence over
+	// the configuration file
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("Error loading configuration: %s", err)
+	}
+	reloader.loaded, reloader.loadedData = newConfig, data
+
+	// The configuration is loaded, so a subsystem failing to apply it does
+	// not keep the others from doing so
+	var failures []string
+	for _, r := range affected {
+		if r.Apply == nil {
+			continue
+		}
+		configLogger.Infof("Applying new %s configuration", r.Name)
+		if err := r.Apply(); err != nil {
+			configLogger.Errorf("Error applying new %s configuration: %s", r.Name, err)
+			failures = append(failures, fmt.Sprintf("%s: %s", r.Name, err))
+		}
+	}
+	if len(result.RestartRequired) > 0 {
+		configLogger.Warningf("Configuration changes which require a restart of the peer: %s", strings.Join(result.RestartRequired, ", "))
+	}
+	if len(failures) > 0 {
+		return result, fmt.Errorf("Error applying new configuration of %s", strings.Join(failures, "; "))
+	}
+	if len(result.Applied) > 0 {
+		configLogger.Infof("Reloaded configuration, applied: %s", strings.Join(result.Applied, ", "))
+	} else if len(result.RestartRequired) == 0 {
+		configLogger.Info("Reloaded configuration, no change found")
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testConfig = `
+logging:
+    node: %s
+rest:
+    address: 0.0.0.0:7050
+peer:
+    listenAddress: %s
+`
+
+// setupReload loads a configuration file and registers a reloadable for
+// the logging keys, it returns the file and the levels applied
+func setupReload(t *testing.T) (string, *[]string) {
+	dir, err := ioutil.TempDir("", "reload")
+	if err != nil {
+		t.Fatalf("Error creating directory: %s", err)
+	}
+	path := filepath.Join(dir, "core.yaml")
+	writeTestConfig(t, path, "info", "0.0.0.0:7051")
+
+	viper.Reset()
+	viper.SetConfigFile(path)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("Error reading configuration: %s", err)
+	}
+
+	applied := &[]string{}
+	reloader.reloadables = nil
+	RegisterReloadable(Reloadable{
+		Name: "logging",
+		Keys: []string{"logging."},
+		Validate: func(v *viper.Viper) error {
+			if v.GetString("logging.node") == "invalid" {
+				return fmt.Errorf("Invalid level")
+			}
+			return nil
+		},
+		Apply: func() error {
+			*applied = append(*applied, viper.GetString("logging.node"))
+			return nil
+		},
+	})
+	if err := InitReload(); err != nil {
+		t.Fatalf("Error initializing reload: %s", err)
+	}
+	return path, applied
+}
+
+func writeTestConfig(t *testing.T, path, level, listenAddress string) {
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf(testConfig, level, listenAddress)), 0644); err != nil {
+		t.Fatalf("Error writing configuration: %s", err)
+	}
+}
+
+func TestReloadAppliesChanges(t *testing.T) {
+	path, applied := setupReload(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	writeTestConfig(t, path, "debug", "0.0.0.0:8051")
+	result, err := Reload()
+	if err != nil {
+		t.Fatalf("Error reloading configuration: %s", err)
+	}
+	if !reflect.DeepEqual(result.Applied, []string{"logging.node"}) || !reflect.DeepEqual(*applied, []string{"debug"}) {
+		t.Errorf("Expected the logging level to be applied, got %v and %v", result.Applied, *applied)
+	}
+	if !reflect.DeepEqual(result.RestartRequired, []string{"peer.listenaddress"}) {
+		t.Errorf("Expected the listen address to require a restart, got %v", result.RestartRequired)
+	}
+	if viper.GetString("logging.node") != "debug" || viper.GetString("rest.address") != "0.0.0.0:7050" {
+		t.Errorf("Expected the new configuration to be loaded")
+	}
+	if viper.GetString("peer.listenAddress") != "0.0.0.0:7051" {
+		t.Errorf("Expected the listen address to keep its value until the peer is restarted, got %s", viper.GetString("peer.listenAddress"))
+	}
+
+	// The listen address still requires a restart
+	result, err = Reload()
+	if err != nil || len(result.Applied) != 0 || !reflect.DeepEqual(result.RestartRequired, []string{"peer.listenaddress"}) || len(*applied) != 1 {
+		t.Errorf("Expected only the listen address to be found changed, got %v, %v", result, err)
+	}
+
+	// Nothing changed since the peer started
+	writeTestConfig(t, path, "debug", "0.0.0.0:7051")
+	result, err = Reload()
+	if err != nil || len(result.Applied) != 0 || len(result.RestartRequired) != 0 || len(*applied) != 1 {
+		t.Errorf("Expected no change to be found, got %v, %v", result, err)
+	}
+}
+
+func TestReloadRejectsInvalidChanges(t *testing.T) {
+	path, applied := setupReload(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	writeTestConfig(t, path, "invalid", "0.0.0.0:8051")
+	if _, err := Reload(); err == nil {
+		t.Fatalf("Expected the invalid configuration to be rejected")
+	}
+	if len(*applied) != 0 || viper.GetString("logging.node") != "info" || viper.GetString("peer.listenAddress") != "0.0.0.0:7051" {
+		t.Errorf("Expected nothing of the invalid configuration to be loaded")
+	}
+
+	// The changes are found once they are fixed
+	writeTestConfig(t, path, "warning", "0.0.0.0:7051")
+	if result, err := Reload(); err != nil || !reflect.DeepEqual(result.Applied, []string{"logging.node"}) {
+		t.Errorf("Expected the logging level to be applied, got %v, %v", result, err)
+	}
+}
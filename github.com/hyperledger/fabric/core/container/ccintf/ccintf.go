@@ -47,4 +47,5 @@ type CCID struct {
 	ChaincodeSpec *pb.ChaincodeSpec
 	NetworkID     string
 	PeerID        string
+	ChainID       string // The chain the instance serves, empty for the default chain
 }
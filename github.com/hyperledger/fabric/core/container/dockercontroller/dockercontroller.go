@@ -265,13 +265,18 @@ func (vm *DockerVM) Destroy(ctxt context.Context, ccid ccintf.CCID, force bool,
 }
 
 //GetVMName generates the docker image from peer information given the hashcode. This is needed to
-//keep image name's unique in a single host, multi-peer environment (such as a development environment)
+//keep image name's unique in a single host, multi-peer environment (such as a development environment).
+//The chaincodes of chains other than the default one are suffixed with the chain
 func (vm *DockerVM) GetVMName(ccid ccintf.CCID) (string, error) {
+	name := ccid.ChaincodeSpec.ChaincodeID.Name
+	if ccid.ChainID != "" {
+		name = fmt.Sprintf("%s-%s", name, ccid.ChainID)
+	}
 	if ccid.NetworkID != "" {
-		return fmt.Sprintf("%s-%s-%s", ccid.NetworkID, ccid.PeerID, ccid.ChaincodeSpec.ChaincodeID.Name), nil
+		return fmt.Sprintf("%s-%s-%s", ccid.NetworkID, ccid.PeerID, name), nil
 	} else if ccid.PeerID != "" {
-		return fmt.Sprintf("%s-%s", ccid.PeerID, ccid.ChaincodeSpec.ChaincodeID.Name), nil
+		return fmt.Sprintf("%s-%s", ccid.PeerID, name), nil
 	} else {
-		return ccid.ChaincodeSpec.ChaincodeID.Name, nil
+		return name, nil
 	}
 }
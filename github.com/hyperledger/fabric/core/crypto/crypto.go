@@ -111,6 +111,10 @@ type Peer interface {
 	// If vkID is nil, then the signature is verified against this validator's verification key.
 	Verify(vkID, signature, message []byte) error
 
+	// GetEnrollmentCertificate returns the enrollment certificate, in DER
+	// format, of the peer with the given identifier.
+	GetEnrollmentCertificate(id []byte) ([]byte, error)
+
 	// GetStateEncryptor returns a StateEncryptor linked to pair defined by
 	// the deploy transaction and the execute transaction. Notice that,
 	// executeTx can also correspond to a deploy transaction.
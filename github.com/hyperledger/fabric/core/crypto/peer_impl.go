@@ -47,6 +47,15 @@ func (peer *peerImpl) GetEnrollmentID() string {
 	return peer.enrollID
 }
 
+// GetEnrollmentCertificate returns the enrollment certificate, in DER format, of the peer with the given identifier
+func (peer *peerImpl) GetEnrollmentCertificate(id []byte) ([]byte, error) {
+	cert, err := peer.getEnrollmentCert(id)
+	if err != nil {
+		return nil, err
+	}
+	return cert.Raw, nil
+}
+
 // TransactionPreValidation verifies that the transaction is
 // well formed with the respect to the security layer
 // prescriptions (i.e. signature verification).
@@ -21,7 +21,9 @@ import (
 	"io"
 	"os"
 	"path"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
@@ -50,6 +52,12 @@ var columnfamilies = []string{
 	persistCF,    // 每一个块的持久状态 (共识)
 }
 
+// DefaultChainID is the chain a peer hosts when no chain is selected, its DB
+// is the one at peer.fileSystemPath/db a peer hosting a single chain uses
+const DefaultChainID = "default"
+
+var chainIDPattern = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
 // OpenchainDB 封装rocksdb的结构的结构体
 type OpenchainDB struct {
 	DB           *gorocksdb.DB
@@ -62,6 +70,12 @@ type OpenchainDB struct {
 
 var openchainDB = create()
 
+// chainDBs are the DBs of the chains other than the default one, by chain ID
+var chainDBs = struct {
+	sync.Mutex
+	m map[string]*OpenchainDB
+}{m: make(map[string]*OpenchainDB)}
+
 // Create创建一个openchainDB实例
 func create() *OpenchainDB {
 	return &OpenchainDB{}
@@ -72,13 +86,58 @@ func GetDBHandle() *OpenchainDB {
 	return openchainDB
 }
 
+// ValidateChainID checks that a chain ID can name the DB directory of the chain
+func ValidateChainID(chainID string) error {
+	if !chainIDPattern.MatchString(chainID) {
+		return fmt.Errorf("Invalid chain ID '%s', it may only contain letters, digits, '_' and '-'", chainID)
+	}
+	return nil
+}
+
+// NormalizeChainID returns the ID of the default chain for an empty chain ID,
+// transactions, messages and events which do not select a chain are for it
+func NormalizeChainID(chainID string) string {
+	if chainID == "" {
+		return DefaultChainID
+	}
+	return chainID
+}
+
+// GetChainDBHandle returns the DB of a chain, which is opened in its own
+// directory peer.fileSystemPath/chains/<chainID>/db the first time. The DB of
+// the default chain is the one returned by GetDBHandle, which Start opens.
+func GetChainDBHandle(chainID string) (*OpenchainDB, error) {
+	if NormalizeChainID(chainID) == DefaultChainID {
+		return openchainDB, nil
+	}
+	if err := ValidateChainID(chainID); err != nil {
+		return nil, err
+	}
+
+	chainDBs.Lock()
+	defer chainDBs.Unlock()
+	if chainDB, ok := chainDBs.m[chainID]; ok {
+		return chainDB, nil
+	}
+	chainDB := create()
+	chainDB.open(getChainDBPath(chainID))
+	chainDBs.m[chainID] = chainDB
+	return chainDB, nil
+}
+
 // 启动数据库, 初始化openchainDB实例并打开数据库.注意该方法不能保证正确行为的并发调用
 func Start() {
-	openchainDB.open()
+	openchainDB.open(getDBPath())
 }
 
-// 停止数据库服务.注意该方法不能保证正确行为的并发调用
+// 停止数据库服务, 包括各个链的数据库.注意该方法不能保证正确行为的并发调用
 func Stop() {
+	chainDBs.Lock()
+	for chainID, chainDB := range chainDBs.m {
+		chainDB.close()
+		delete(chainDBs.m, chainID)
+	}
+	chainDBs.Unlock()
 	openchainDB.close()
 }
 
@@ -137,6 +196,15 @@ func (openchainDB *OpenchainDB) GetSnapshot() *gorocksdb.Snapshot {
 
 //获取rockdb数据库路径
 func getDBPath() string {
+	return getFileSystemPath() + "db"
+}
+
+// getChainDBPath returns the path of the DB of a chain other than the default one
+func getChainDBPath(chainID string) string {
+	return getFileSystemPath() + "chains/" + chainID + "/db"
+}
+
+func getFileSystemPath() string {
 	dbPath := viper.GetString("peer.fileSystemPath")
 	if dbPath == "" {
 		panic("DB path not specified in configuration file. Please check that property 'peer.fileSystemPath' is set")
@@ -144,12 +212,11 @@ func getDBPath() string {
 	if !strings.HasSuffix(dbPath, "/") {
 		dbPath = dbPath + "/"
 	}
-	return dbPath + "db"
+	return dbPath
 }
 
 // Open 打开已经存在于hyperledger中的数据库
-func (openchainDB *OpenchainDB) open() {
-	dbPath := getDBPath()
+func (openchainDB *OpenchainDB) open(dbPath string) {
 	missing, err := dirMissingOrEmpty(dbPath)
 	if err != nil {
 		panic(fmt.Sprintf("Error while trying to open DB: %s", err))
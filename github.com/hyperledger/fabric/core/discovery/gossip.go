@@ -0,0 +1,433 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/op/go-logging"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+var logger = logging.MustGetLogger("discovery")
+
+// Reputation bounds and adjustments of the members of a GossipDiscovery
+const (
+	MinReputation     = 0
+	MaxReputation     = 100
+	InitialReputation = 50
+
+	aliveReward       = 1  // A newer heartbeat was received from the member
+	suspectPenalty    = 10 // The member was not heard from for the suspect timeout
+	failurePenalty    = 25 // The member was not heard from for the failure timeout
+	misbehavedPenalty = 50 // The member sent an alive message which does not verify
+)
+
+// MemberStatus is the liveness of a member as seen by this peer
+type MemberStatus int
+
+// The liveness of a member
+const (
+	Alive   MemberStatus = iota // A heartbeat of the member was received recently
+	Suspect                     // The member was not heard from for the suspect timeout
+	Dead                        // The member was not heard from for the failure timeout
+)
+
+func (s MemberStatus) String() string {
+	switch s {
+	case Alive:
+		return "alive"
+	case Suspect:
+		return "suspect"
+	case Dead:
+		return "dead"
+	}
+	return fmt.Sprintf("MemberStatus(%d)", int(s))
+}
+
+// Member is a snapshot of what this peer knows about another member
+type Member struct {
+	Address    string
+	Endpoint   *pb.PeerEndpoint // Nil until an alive message of the member was received
+	Status     MemberStatus
+	Reputation int
+	LastSeen   time.Time
+}
+
+// SignFunc signs a message with the enrollment key of this peer
+type SignFunc func(msg []byte) ([]byte, error)
+
+// VerifyFunc verifies the signature of the alive message of a member with the
+// enrollment certificate of its PKI ID, which must be issued to the enrollment
+// ID and role the endpoint announces
+type VerifyFunc func(endpoint *pb.PeerEndpoint, signature, msg []byte) error
+
+// Gossiper is implemented by discovery helpers which take part in membership gossip
+type Gossiper interface {
+	// Heartbeat advances the heartbeat of this peer, detects failed members, and
+	// returns the membership to gossip to other peers
+	Heartbeat() (*pb.GossipMembership, error)
+	// HandleGossip merges the membership gossiped by the connected peer with
+	// the authenticated endpoint
+	HandleGossip(from *pb.PeerEndpoint, msg *pb.GossipMembership) error
+}
+
+type member struct {
+	endpoint    *pb.PeerEndpoint
+	incarnation uint64
+	heartbeat   uint64
+	alive       *pb.SignedAliveMessage // The latest alive message of the member, gossiped on to others
+	status      MemberStatus
+	reputation  int
+	lastSeen    time.Time
+}
+
+// GossipDiscovery is an implementation of Discovery which learns about the
+// members of the network from the alive messages they periodically gossip.
+// A member is suspected when no newer heartbeat was received for the suspect
+// timeout, and considered failed after the failure timeout. Alive messages are
+// signed with the enrollment key of the member they announce, the address of a
+// connected peer is bound to the identity it authenticated the connection with,
+// and the members are picked for connections according to their reputation.
+type GossipDiscovery struct {
+	sync.RWMutex
+	self           *pb.PeerEndpoint
+	sign           SignFunc
+	verify         VerifyFunc
+	suspectTimeout time.Duration
+	failTimeout    time.Duration
+	incarnation    uint64
+	heartbeat      uint64
+	members        map[string]*member // By address
+	random         *rand.Rand
+	now            func() time.Time
+}
+
+// NewGossipDiscovery is a constructor of a gossip based Discovery implementation
+// for the peer at the self endpoint. When security is disabled, sign and verify
+// are nil and alive messages are neither signed nor verified.
+func NewGossipDiscovery(self *pb.PeerEndpoint, sign SignFunc, verify VerifyFunc, suspectTimeout, failTimeout time.Duration) *GossipDiscovery {
+	gd := &GossipDiscovery{
+		self:           self,
+		sign:           sign,
+		verify:         verify,
+		suspectTimeout: suspectTimeout,
+		failTimeout:    failTimeout,
+		members:        make(map[string]*member),
+		random:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:            time.Now,
+	}
+	gd.incarnation = uint64(gd.now().UnixNano())
+	return gd
+}
+
+func (gd *GossipDiscovery) getMember(address string) *member {
+	m, ok := gd.members[address]
+	if !ok {
+		m = &member{reputation: InitialReputation, lastSeen: gd.now()}
+		gd.members[address] = m
+	}
+	return m
+}
+
+func (m *member) adjustReputation(delta int) {
+	m.reputation += delta
+	if m.reputation > MaxReputation {
+		m.reputation = MaxReputation
+	} else if m.reputation < MinReputation {
+		m.reputation = MinReputation
+	}
+}
+
+// AddNode adds an address to the discovery list, the member is considered
+// alive, as it was just connected to or configured as a root node
+func (gd *GossipDiscovery) AddNode(address string) bool {
+	if address == gd.self.Address {
+		return false
+	}
+	gd.Lock()
+	defer gd.Unlock()
+	m := gd.getMember(address)
+	m.status = Alive
+	m.lastSeen = gd.now()
+	return true
+}
+
+// RemoveNode marks a member of the discovery list as failed
+func (gd *GossipDiscovery) RemoveNode(address string) bool {
+	gd.Lock()
+	defer gd.Unlock()
+	m, ok := gd.members[address]
+	if !ok {
+		return false
+	}
+	m.status = Dead
+	return true
+}
+
+// GetAllNodes returns the addresses of the members which are not considered failed
+func (gd *GossipDiscovery) GetAllNodes() []string {
+	gd.RLock()
+	defer gd.RUnlock()
+	var addresses []string
+	for address, m := range gd.members {
+		if m.status != Dead {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// GetRandomNodes returns up to n random members, alive ones before suspected
+// ones, each picked with a probability proportional to its reputation
+func (gd *GossipDiscovery) GetRandomNodes(n int) []string {
+	gd.Lock() // The random source is not safe for concurrent use
+	defer gd.Unlock()
+
+	var picked []string
+	for _, status := range []MemberStatus{Alive, Suspect} {
+		var candidates []string
+		var weights []int
+		total := 0
+		for address, m := range gd.members {
+			if m.status == status {
+				candidates = append(candidates, address)
+				weights = append(weights, m.reputation+1) // Members without reputation are picked as a last resort
+				total += m.reputation + 1
+			}
+		}
+		for len(picked) < n && len(candidates) > 0 {
+			r := gd.random.Intn(total)
+			i := 0
+			for ; r >= weights[i]; i++ {
+				r -= weights[i]
+			}
+			picked = append(picked, candidates[i])
+			total -= weights[i]
+			candidates = append(candidates[:i], candidates[i+1:]...)
+			weights = append(weights[:i], weights[i+1:]...)
+		}
+	}
+	return picked
+}
+
+// FindNode returns true if its address is stored in the discovery list
+func (gd *GossipDiscovery) FindNode(address string) bool {
+	gd.RLock()
+	defer gd.RUnlock()
+	_, ok := gd.members[address]
+	return ok
+}
+
+// AdjustReputation changes the reputation of a member by delta, within the reputation bounds
+func (gd *GossipDiscovery) AdjustReputation(address string, delta int) {
+	gd.Lock()
+	defer gd.Unlock()
+	if m, ok := gd.members[address]; ok {
+		m.adjustReputation(delta)
+	}
+}
+
+// Members returns what this peer knows about the other members
+func (gd *GossipDiscovery) Members() []*Member {
+	gd.RLock()
+	defer gd.RUnlock()
+	var members []*Member
+	for address, m := range gd.members {
+		members = append(members, &Member{
+			Address:    address,
+			Endpoint:   m.endpoint,
+			Status:     m.status,
+			Reputation: m.reputation,
+			LastSeen:   m.lastSeen,
+		})
+	}
+	return members
+}
+
+// Heartbeat advances the heartbeat of this peer, updates the liveness of the
+// members, and returns the alive messages of this peer and of the members not
+// considered failed
+func (gd *GossipDiscovery) Heartbeat() (*pb.GossipMembership, error) {
+	gd.Lock()
+	defer gd.Unlock()
+
+	gd.detectFailures()
+
+	gd.heartbeat++
+	raw, err := proto.Marshal(&pb.AliveMessage{PeerEndpoint: gd.self, Incarnation: gd.incarnation, Heartbeat: gd.heartbeat})
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling alive message: %s", err)
+	}
+	alive := &pb.SignedAliveMessage{Alive: raw}
+	if gd.sign != nil {
+		if alive.Signature, err = gd.sign(raw); err != nil {
+			return nil, fmt.Errorf("Error signing alive message: %s", err)
+		}
+	}
+
+	msg := &pb.GossipMembership{Members: []*pb.SignedAliveMessage{alive}}
+	for _, m := range gd.members {
+		if m.status != Dead && m.alive != nil {
+			msg.Members = append(msg.Members, m.alive)
+		}
+	}
+	return msg, nil
+}
+
+// detectFailures suspects the members not heard from for the suspect timeout,
+// and fails those not heard from for the failure timeout, the lock must be held
+func (gd *GossipDiscovery) detectFailures() {
+	now := gd.now()
+	for address, m := range gd.members {
+		silence := now.Sub(m.lastSeen)
+		switch {
+		case m.status != Dead && silence >= gd.failTimeout:
+			logger.Warningf("No heartbeat from %s for %v, considering it failed", address, silence)
+			m.status = Dead
+			m.adjustReputation(-failurePenalty)
+		case m.status == Alive && silence >= gd.suspectTimeout:
+			logger.Infof("No heartbeat from %s for %v, suspecting it", address, silence)
+			m.status = Suspect
+			m.adjustReputation(-suspectPenalty)
+		}
+	}
+}
+
+// HandleGossip merges the alive messages gossiped by the connected peer with
+// the authenticated endpoint. Alive messages which do not verify are discarded,
+// the others are merged, and the last error is returned. Discarded alive
+// messages lower the reputation of the sender.
+func (gd *GossipDiscovery) HandleGossip(from *pb.PeerEndpoint, msg *pb.GossipMembership) error {
+	gd.Lock()
+	defer gd.Unlock()
+
+	var lastErr error
+	for _, signed := range msg.Members {
+		if err := gd.mergeAlive(from, signed); err != nil {
+			logger.Warningf("Discarding alive message gossiped by %s: %s", from.Address, err)
+			if sender, ok := gd.members[from.Address]; ok {
+				sender.adjustReputation(-misbehavedPenalty)
+			}
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// mergeAlive records an alive message gossiped by the connected peer with the
+// authenticated endpoint if it is newer than the one known, the lock must be held
+func (gd *GossipDiscovery) mergeAlive(from *pb.PeerEndpoint, signed *pb.SignedAliveMessage) error {
+	alive := &pb.AliveMessage{}
+	if err := proto.Unmarshal(signed.Alive, alive); err != nil {
+		return fmt.Errorf("Error unmarshalling alive message: %s", err)
+	}
+	endpoint := alive.PeerEndpoint
+	if endpoint == nil || endpoint.ID == nil || endpoint.Address == "" {
+		return fmt.Errorf("Alive message has no endpoint")
+	}
+	if endpoint.Address == gd.self.Address {
+		return nil
+	}
+	// The address of the sender is bound to the identity it authenticated the connection with
+	authenticated := endpoint.Address == from.Address
+	if authenticated && !sameIdentity(endpoint, from) {
+		return fmt.Errorf("Alive message announces %s as %s, which is connected as %s", endpoint.Address, endpoint.ID.Name, from.ID)
+	}
+
+	m, known := gd.members[endpoint.Address]
+	if known && m.endpoint != nil && sameIdentity(m.endpoint, endpoint) && (alive.Incarnation < m.incarnation || (alive.Incarnation == m.incarnation && alive.Heartbeat <= m.heartbeat)) {
+		return nil // Not newer than what we know
+	}
+
+	if gd.verify != nil {
+		if err := gd.verify(endpoint, signed.Signature, signed.Alive); err != nil {
+			return fmt.Errorf("Alive message of %s does not verify: %s", endpoint.Address, err)
+		}
+	}
+	// A member is bound to the identity it was first heard from with, until it
+	// is considered failed, or the identity is authenticated by a connection
+	if known && m.endpoint != nil && !sameIdentity(m.endpoint, endpoint) {
+		if authenticated {
+			logger.Warningf("Member %s was announced as %s, but is connected as %s", endpoint.Address, m.endpoint.ID.Name, endpoint.ID.Name)
+		} else if m.status != Dead {
+			return fmt.Errorf("Alive message announces %s as %s, which is already member %s", endpoint.Address, endpoint.ID.Name, m.endpoint.ID.Name)
+		}
+	}
+
+	m = gd.getMember(endpoint.Address)
+	if m.status != Alive {
+		logger.Infof("Received a heartbeat from %s (%s), considering it alive", endpoint.Address, endpoint.ID.Name)
+	}
+	m.endpoint = endpoint
+	m.incarnation = alive.Incarnation
+	m.heartbeat = alive.Heartbeat
+	m.alive = signed
+	m.status = Alive
+	m.lastSeen = gd.now()
+	m.adjustReputation(aliveReward)
+	return nil
+}
+
+func sameIdentity(a, b *pb.PeerEndpoint) bool {
+	return a.ID != nil && b.ID != nil && a.ID.Name == b.ID.Name && bytes.Equal(a.PkiID, b.PkiID)
+}
+
+// Snapshot returns the membership to persist
+func (gd *GossipDiscovery) Snapshot() *pb.MembershipSnapshot {
+	gd.RLock()
+	defer gd.RUnlock()
+	snapshot := &pb.MembershipSnapshot{}
+	for address, m := range gd.members {
+		endpoint := m.endpoint
+		if endpoint == nil {
+			endpoint = &pb.PeerEndpoint{Address: address}
+		}
+		snapshot.Members = append(snapshot.Members, &pb.MembershipRecord{
+			PeerEndpoint: endpoint,
+			Reputation:   int32(m.reputation),
+			LastSeen:     &timestamp.Timestamp{Seconds: m.lastSeen.Unix(), Nanos: int32(m.lastSeen.Nanosecond())},
+		})
+	}
+	return snapshot
+}
+
+// Restore adds the persisted membership. The members are suspected until a
+// heartbeat is received from them, and keep their reputation.
+func (gd *GossipDiscovery) Restore(snapshot *pb.MembershipSnapshot) {
+	gd.Lock()
+	defer gd.Unlock()
+	for _, record := range snapshot.Members {
+		if record.PeerEndpoint == nil || record.PeerEndpoint.Address == "" || record.PeerEndpoint.Address == gd.self.Address {
+			continue
+		}
+		m := gd.getMember(record.PeerEndpoint.Address)
+		if record.PeerEndpoint.ID != nil {
+			m.endpoint = record.PeerEndpoint
+		}
+		m.status = Suspect
+		m.reputation = int(record.Reputation)
+		m.adjustReputation(0)
+	}
+}
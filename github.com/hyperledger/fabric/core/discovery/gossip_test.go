@@ -0,0 +1,310 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package discovery
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// The signature of a message is the PKI ID of the signer followed by the message
+func mockSigner(pkiID []byte) SignFunc {
+	return func(msg []byte) ([]byte, error) {
+		return append(append([]byte{}, pkiID...), msg...), nil
+	}
+}
+
+// The enrollment certificate of a PKI ID is issued to the enrollment ID with the same name
+func mockVerify(endpoint *pb.PeerEndpoint, signature, msg []byte) error {
+	if string(endpoint.PkiID) != endpoint.ID.Name {
+		return fmt.Errorf("Enrolled as %s", endpoint.PkiID)
+	}
+	if !bytes.Equal(signature, append(append([]byte{}, endpoint.PkiID...), msg...)) {
+		return fmt.Errorf("Signature does not match")
+	}
+	return nil
+}
+
+func testEndpoint(name string) *pb.PeerEndpoint {
+	return &pb.PeerEndpoint{ID: &pb.PeerID{Name: name}, Address: name + ":7051", PkiID: []byte(name)}
+}
+
+func signedAlive(endpoint *pb.PeerEndpoint, signer []byte, incarnation uint64) *pb.GossipMembership {
+	raw, _ := proto.Marshal(&pb.AliveMessage{PeerEndpoint: endpoint, Incarnation: incarnation, Heartbeat: 1})
+	sig, _ := mockSigner(signer)(raw)
+	return &pb.GossipMembership{Members: []*pb.SignedAliveMessage{{Alive: raw, Signature: sig}}}
+}
+
+type mockClock struct {
+	now time.Time
+}
+
+func (c *mockClock) Now() time.Time {
+	return c.now
+}
+
+func newTestGossip(name string, clock *mockClock) *GossipDiscovery {
+	self := testEndpoint(name)
+	gd := NewGossipDiscovery(self, mockSigner(self.PkiID), mockVerify, 10*time.Second, 30*time.Second)
+	gd.now = clock.Now
+	return gd
+}
+
+func gossip(t *testing.T, from, to *GossipDiscovery) {
+	msg, err := from.Heartbeat()
+	if err != nil {
+		t.Fatalf("Heartbeat failed: %s", err)
+	}
+	if err := to.HandleGossip(from.self, msg); err != nil {
+		t.Fatalf("Gossip from %s to %s failed: %s", from.self.Address, to.self.Address, err)
+	}
+}
+
+func getTestMember(gd *GossipDiscovery, address string) *Member {
+	for _, m := range gd.Members() {
+		if m.Address == address {
+			return m
+		}
+	}
+	return nil
+}
+
+func TestGossipSpreadsMembership(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b, c := newTestGossip("a", clock), newTestGossip("b", clock), newTestGossip("c", clock)
+
+	gossip(t, a, b)
+	gossip(t, b, c)
+
+	if !c.FindNode("a:7051") || !c.FindNode("b:7051") {
+		t.Fatalf("Expected c to learn about a through b, knows %v", c.GetAllNodes())
+	}
+	if m := getTestMember(c, "a:7051"); m.Status != Alive || m.Endpoint.ID.Name != "a" {
+		t.Errorf("Expected a to be an alive member, got %+v", m)
+	}
+	if c.FindNode("c:7051") {
+		t.Errorf("A peer should not be a member of its own discovery list")
+	}
+}
+
+func TestGossipFailureDetection(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b := newTestGossip("a", clock), newTestGossip("b", clock)
+	gossip(t, a, b)
+
+	clock.now = clock.now.Add(15 * time.Second)
+	b.Heartbeat()
+	if m := getTestMember(b, "a:7051"); m.Status != Suspect {
+		t.Fatalf("Expected a to be suspected, got %s", m.Status)
+	}
+	if len(b.GetAllNodes()) != 1 {
+		t.Errorf("Suspected members should still be reconnected to")
+	}
+
+	clock.now = clock.now.Add(20 * time.Second)
+	msg, _ := b.Heartbeat()
+	if m := getTestMember(b, "a:7051"); m.Status != Dead {
+		t.Fatalf("Expected a to be considered failed, got %s", m.Status)
+	}
+	if len(b.GetAllNodes()) != 0 || len(b.GetRandomNodes(1)) != 0 {
+		t.Errorf("Failed members should not be connected to")
+	}
+	if len(msg.Members) != 1 {
+		t.Errorf("Failed members should not be gossiped, got %d alive messages", len(msg.Members))
+	}
+
+	// A new heartbeat from the member brings it back
+	gossip(t, a, b)
+	if m := getTestMember(b, "a:7051"); m.Status != Alive {
+		t.Errorf("Expected a to be alive again, got %s", m.Status)
+	}
+	if m := getTestMember(b, "a:7051"); m.Reputation >= InitialReputation {
+		t.Errorf("Expected the failures to lower the reputation of a, got %d", m.Reputation)
+	}
+}
+
+func TestGossipIgnoresStaleHeartbeats(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b := newTestGossip("a", clock), newTestGossip("b", clock)
+	stale, _ := a.Heartbeat()
+	gossip(t, a, b)
+
+	clock.now = clock.now.Add(35 * time.Second)
+	b.Heartbeat()
+	if err := b.HandleGossip(testEndpoint("x"), stale); err != nil {
+		t.Fatalf("A stale heartbeat is not an error: %s", err)
+	}
+	if m := getTestMember(b, "a:7051"); m.Status != Dead {
+		t.Errorf("A stale heartbeat should not bring a failed member back, got %s", m.Status)
+	}
+}
+
+func TestGossipRejectsForgedAliveMessages(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b := newTestGossip("a", clock), newTestGossip("b", clock)
+	gossip(t, a, b)
+	b.AddNode("mallory:7051")
+
+	mallory := testEndpoint("mallory")
+
+	// An alive message for a, signed by mallory
+	if err := b.HandleGossip(mallory, signedAlive(a.self, []byte("mallory"), a.incarnation+1)); err == nil {
+		t.Errorf("An alive message with a bad signature should be rejected")
+	}
+
+	// An alive message announcing mallory at the address of a
+	impostor := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "mallory"}, Address: "a:7051", PkiID: []byte("mallory")}
+	if err := b.HandleGossip(mallory, signedAlive(impostor, []byte("mallory"), a.incarnation+1)); err == nil {
+		t.Errorf("An alive message for another member's address should be rejected")
+	}
+
+	// An alive message signed by mallory, announcing the enrollment ID of c
+	impostor = &pb.PeerEndpoint{ID: &pb.PeerID{Name: "c"}, Address: "c:7051", PkiID: []byte("mallory")}
+	if err := b.HandleGossip(mallory, signedAlive(impostor, []byte("mallory"), 1)); err == nil {
+		t.Errorf("An alive message announcing an enrollment ID other than the signer's should be rejected")
+	}
+	if b.FindNode("c:7051") {
+		t.Errorf("Expected the alive message announcing another enrollment ID to be discarded")
+	}
+
+	// An alive message of the connected peer, announcing another identity
+	impostor = &pb.PeerEndpoint{ID: &pb.PeerID{Name: "c"}, Address: "mallory:7051", PkiID: []byte("c")}
+	c := newTestGossip("c", clock)
+	if err := b.HandleGossip(mallory, signedAlive(impostor, c.self.PkiID, 1)); err == nil {
+		t.Errorf("An alive message announcing the address of the sender as another identity should be rejected")
+	}
+
+	if m := getTestMember(b, "a:7051"); m.Endpoint.ID.Name != "a" {
+		t.Errorf("Expected a to remain bound to its identity, got %s", m.Endpoint.ID.Name)
+	}
+	if m := getTestMember(b, "mallory:7051"); m.Reputation != MinReputation {
+		t.Errorf("Expected the reputation of the sender of forged messages to be lowered, got %d", m.Reputation)
+	}
+}
+
+func TestGossipDropsOnlyBadAliveMessages(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b, c := newTestGossip("a", clock), newTestGossip("b", clock), newTestGossip("c", clock)
+	gossip(t, c, a)
+
+	msg, _ := a.Heartbeat()
+	msg.Members = append(msg.Members, &pb.SignedAliveMessage{Alive: msg.Members[0].Alive, Signature: []byte("bad")})
+	msg.Members[0], msg.Members[len(msg.Members)-1] = msg.Members[len(msg.Members)-1], msg.Members[0]
+	if err := b.HandleGossip(a.self, msg); err == nil {
+		t.Errorf("Expected the alive message which does not verify to be reported")
+	}
+	if !b.FindNode("a:7051") || !b.FindNode("c:7051") {
+		t.Errorf("Expected the alive messages which verify to be merged, knows %v", b.GetAllNodes())
+	}
+}
+
+func TestGossipConnectionRebindsAddress(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b := newTestGossip("a", clock), newTestGossip("b", clock)
+	b.AddNode("mallory:7051")
+
+	// mallory relays its own alive message announcing the address of a, with a
+	// high incarnation, before a is heard from
+	impostor := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "mallory"}, Address: "a:7051", PkiID: []byte("mallory")}
+	if err := b.HandleGossip(testEndpoint("mallory"), signedAlive(impostor, []byte("mallory"), a.incarnation+100)); err != nil {
+		t.Fatalf("Expected the first alive message for the address to be accepted: %s", err)
+	}
+
+	// The alive message a sends over its own connection binds the address to a
+	gossip(t, a, b)
+	if m := getTestMember(b, "a:7051"); m.Endpoint.ID.Name != "a" {
+		t.Errorf("Expected the connected peer to be bound to its address, got %s", m.Endpoint.ID.Name)
+	}
+	if err := b.HandleGossip(testEndpoint("mallory"), signedAlive(impostor, []byte("mallory"), a.incarnation+200)); err == nil {
+		t.Errorf("Expected the relayed alive message to no longer rebind the address")
+	}
+}
+
+func TestGossipRandomNodesByReputation(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	gd := newTestGossip("self", clock)
+	gd.AddNode("good")
+	gd.AddNode("bad")
+	gd.AdjustReputation("good", MaxReputation)
+	gd.AdjustReputation("bad", -MaxReputation)
+
+	if nodes := gd.GetRandomNodes(5); len(nodes) != 2 {
+		t.Fatalf("Expected only the 2 members to be returned, got %v", nodes)
+	}
+
+	picks := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		picks[gd.GetRandomNodes(1)[0]]++
+	}
+	if picks["good"] < 900 || picks["bad"] == 0 {
+		t.Errorf("Expected members to be picked according to their reputation, got %v", picks)
+	}
+
+	// Alive members are picked before suspected ones, whatever their reputation
+	clock.now = clock.now.Add(15 * time.Second)
+	gd.Heartbeat()
+	gd.AddNode("bad")
+	for i := 0; i < 10; i++ {
+		if nodes := gd.GetRandomNodes(1); nodes[0] != "bad" {
+			t.Fatalf("Expected the alive member to be picked, got %v", nodes)
+		}
+	}
+}
+
+func TestGossipSnapshotRestore(t *testing.T) {
+	clock := &mockClock{now: time.Now()}
+	a, b := newTestGossip("a", clock), newTestGossip("b", clock)
+	gossip(t, a, b)
+	b.AddNode("c:7051")
+	b.AdjustReputation("c:7051", 20)
+
+	raw, err := proto.Marshal(b.Snapshot())
+	if err != nil {
+		t.Fatalf("Could not marshal the snapshot: %s", err)
+	}
+	snapshot := &pb.MembershipSnapshot{}
+	if err := proto.Unmarshal(raw, snapshot); err != nil {
+		t.Fatalf("Could not unmarshal the snapshot: %s", err)
+	}
+
+	restored := newTestGossip("b", clock)
+	restored.Restore(snapshot)
+	if m := getTestMember(restored, "c:7051"); m == nil || m.Status != Suspect || m.Reputation != InitialReputation+20 {
+		t.Errorf("Expected c to be restored as suspect with its reputation, got %+v", m)
+	}
+	if m := getTestMember(restored, "a:7051"); m == nil || !bytes.Equal(m.Endpoint.PkiID, []byte("a")) {
+		t.Errorf("Expected a to be restored with its identity, got %+v", m)
+	}
+
+	// The restored identity binding holds
+	impostor := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "mallory"}, Address: "a:7051", PkiID: []byte("mallory")}
+	if err := restored.HandleGossip(testEndpoint("c"), signedAlive(impostor, []byte("mallory"), 1)); err == nil {
+		t.Errorf("Expected the restored member to stay bound to its identity")
+	}
+
+	// A heartbeat confirms a restored member
+	gossip(t, a, restored)
+	if m := getTestMember(restored, "a:7051"); m.Status != Alive {
+		t.Errorf("Expected a to be alive after its heartbeat, got %s", m.Status)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultConsensusStallTimeout = 5 * time.Minute
+	defaultHealthDialTimeout     = 2 * time.Second
+)
+
+// HealthCheck checks a subsystem of the peer
+type HealthCheck struct {
+	// Name identifies the subsystem in the results
+	Name string
+	// Check returns the status of the subsystem and a message describing it
+	Check func() (pb.HealthCheck_Status, string)
+}
+
+var healthChecks struct {
+	sync.Mutex
+	checks []HealthCheck
+}
+
+// RegisterHealthCheck registers a check run each time the health of the
+// peer is asked for
+func RegisterHealthCheck(c HealthCheck) {
+	healthChecks.Lock()
+	defer healthChecks.Unlock()
+	healthChecks.checks = append(healthChecks.checks, c)
+}
+
+// CheckHealth runs the health checks in the order they were registered. The
+// peer is healthy when none of them failed, and ready when it is healthy and
+// none of them is NOT_READY.
+func CheckHealth() *pb.Health {
+	healthChecks.Lock()
+	checks := append([]HealthCheck(nil), healthChecks.checks...)
+	healthChecks.Unlock()
+
+	health := &pb.Health{Healthy: true, Ready: true}
+	for _, c := range checks {
+		status, message := c.Check()
+		switch status {
+		case pb.HealthCheck_FAILED:
+			health.Healthy = false
+			health.Ready = false
+		case pb.HealthCheck_NOT_READY:
+			health.Ready = false
+		}
+		if status != pb.HealthCheck_OK {
+			log.Debugf("Health check %s: %s, %s", c.Name, status, message)
+		}
+		health.Checks = append(health.Checks, &pb.HealthCheck{Name: c.Name, Status: status, Message: message})
+	}
+	return health
+}
+
+// LedgerHealthCheck reads the last block of the ledger from the database
+func LedgerHealthCheck() HealthCheck {
+	return ChainLedgerHealthCheck(db.DefaultChainID)
+}
+
+// chainCheckName returns the name of the check of a subsystem of a chain
+// hosted by the peer besides the default one
+func chainCheckName(name, chainID string) string {
+	if db.NormalizeChainID(chainID) == db.DefaultChainID {
+		return name
+	}
+	return fmt.Sprintf("%s of chain %s", name, chainID)
+}
+
+// ChainLedgerHealthCheck reads the last block of the ledger of a chain from
+// its database
+func ChainLedgerHealthCheck(chainID string) HealthCheck {
+	return HealthCheck{Name: chainCheckName("ledger", chainID), Check: func() (pb.HealthCheck_Status, string) {
+		l, err := ledger.GetChainLedger(chainID)
+		if err != nil {
+			return pb.HealthCheck_FAILED, fmt.Sprintf("Error opening ledger: %s", err)
+		}
+		size := l.GetBlockchainSize()
+		if size == 0 {
+			return pb.HealthCheck_OK, "Blockchain is empty"
+		}
+		if _, err := l.GetBlockByNumber(size - 1); err != nil {
+			return pb.HealthCheck_FAILED, fmt.Sprintf("Error reading block %d: %s", size-1, err)
+		}
+		return pb.HealthCheck_OK, fmt.Sprintf("Blockchain height %d", size)
+	}}
+}
+
+// consensusHealth follows the progress of the consensus plugin between
+// health checks
+type consensusHealth struct {
+	reporter peer.ConsensusStatusReporter
+
+	lock       sync.Mutex
+	lastExec   uint64
+	progressed time.Time // When a request was last executed, or none was outstanding
+}
+
+// ConsensusHealthChecks returns the checks of the consensus progress and of
+// state transfer of a validating peer
+func ConsensusHealthChecks(reporter peer.ConsensusStatusReporter) []HealthCheck {
+	return ChainConsensusHealthChecks(reporter, db.DefaultChainID)
+}
+
+// ChainConsensusHealthChecks returns the checks of the consensus progress
+// and of state transfer of a chain hosted by a validating peer
+func ChainConsensusHealthChecks(reporter peer.ConsensusStatusReporter, chainID string) []HealthCheck {
+	c := &consensusHealth{reporter: reporter}
+	return []HealthCheck{
+		{Name: chainCheckName("consensus", chainID), Check: c.progress},
+		{Name: chainCheckName("state transfer", chainID), Check: c.stateTransfer},
+	}
+}
+
+// progress reports the peer as not ready once PBFT executed no request
+// within peer.health.consensusStallTimeout while requests are outstanding,
+// and during view changes. A stall depends on the other validating peers, so
+// unlike the local faults it does not fail the check
+func (c *consensusHealth) progress() (pb.HealthCheck_Status, string) {
+	status, err := c.reporter.GetConsensusStatus()
+	if err != nil {
+		return pb.HealthCheck_FAILED, fmt.Sprintf("Error getting consensus status: %s", err)
+	}
+	pbft := status.Pbft
+	if pbft == nil {
+		return pb.HealthCheck_OK, fmt.Sprintf("Consensus plugin %s", status.Plugin)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	outstanding := pbft.OutstandingRequests + pbft.OutstandingRequestBatches
+	// Nothing is executed while the state is transferred, which is
+	// reported on its own
+	if c.progressed.IsZero() || pbft.LastExec != c.lastExec || outstanding == 0 || pbft.StateTransferInProgress {
+		c.lastExec = pbft.LastExec
+		c.progressed = now
+	}
+
+	timeout := viper.GetDuration("peer.health.consensusStallTimeout")
+	if timeout <= 0 {
+		timeout = defaultConsensusStallTimeout
+	}
+	if stalled := now.Sub(c.progressed); stalled >= timeout {
+		return pb.HealthCheck_NOT_READY, fmt.Sprintf("No request executed for %s with %d outstanding, last executed sequence number %d",
+			stalled, outstanding, pbft.LastExec)
+	}
+	if !pbft.ActiveView {
+		return pb.HealthCheck_NOT_READY, fmt.Sprintf("View change to view %d in progress", pbft.View)
+	}
+	return pb.HealthCheck_OK, fmt.Sprintf("Last executed sequence number %d in view %d", pbft.LastExec, pbft.View)
+}
+
+// stateTransfer reports the peer as not ready while it catches up with the
+// network
+func (c *consensusHealth) stateTransfer() (pb.HealthCheck_Status, string) {
+	status, err := c.reporter.GetConsensusStatus()
+	if err != nil {
+		return pb.HealthCheck_FAILED, fmt.Sprintf("Error getting consensus status: %s", err)
+	}
+	if status.Pbft != nil && status.Pbft.StateTransferInProgress {
+		return pb.HealthCheck_NOT_READY, fmt.Sprintf("State transfer in progress from sequence number %d", status.Pbft.LastExec)
+	}
+	return pb.HealthCheck_OK, "No state transfer in progress"
+}
+
+// PeersHealthCheck reports the peer as not ready while it is connected to
+// fewer than peer.health.minPeers peers, or to none when root nodes are
+// configured
+func PeersHealthCheck(getPeers func() (*pb.PeersMessage, error)) HealthCheck {
+	return HealthCheck{Name: "peers", Check: func() (pb.HealthCheck_Status, string) {
+		peers, err := getPeers()
+		if err != nil {
+			return pb.HealthCheck_FAILED, fmt.Sprintf("Error getting peers: %s", err)
+		}
+		connected := len(peers.Peers)
+		min := viper.GetInt("peer.health.minPeers")
+		if min <= 0 && viper.GetString("peer.discovery.rootnode") != "" {
+			min = 1
+		}
+		if connected < min {
+			return pb.HealthCheck_NOT_READY, fmt.Sprintf("%d peers connected, %d required", connected, min)
+		}
+		return pb.HealthCheck_OK, fmt.Sprintf("%d peers connected", connected)
+	}}
+}
+
+// ChaincodeHealthCheck warns of the chaincode containers launched by the
+// peer which are no longer registered with it
+func ChaincodeHealthCheck() HealthCheck {
+	return HealthCheck{Name: "chaincodes", Check: func() (pb.HealthCheck_Status, string) {
+		if names := chaincode.ChaincodesNotRegistered(); len(names) > 0 {
+			return pb.HealthCheck_WARNING, fmt.Sprintf("Chaincode containers not registered, relaunched on their next transaction: %s", strings.Join(names, ", "))
+		}
+		return pb.HealthCheck_OK, "Chaincode containers registered"
+	}}
+}
+
+// MembersrvcHealthCheck reports the peer as not ready while the membership
+// services it enrolls with and gets certificates from cannot be reached
+func MembersrvcHealthCheck() HealthCheck {
+	return HealthCheck{Name: "membersrvc", Check: func() (pb.HealthCheck_Status, string) {
+		timeout := viper.GetDuration("peer.health.dialTimeout")
+		if timeout <= 0 {
+			timeout = defaultHealthDialTimeout
+		}
+		var addrs, failures []string
+		for _, key := range []string{"peer.pki.eca.paddr", "peer.pki.tca.paddr", "peer.pki.tlsca.paddr"} {
+			addr := viper.GetString(key)
+			found := addr == ""
+			for _, a := range addrs {
+				found = found || a == addr
+			}
+			if found {
+				continue
+			}
+			addrs = append(addrs, addr)
+			conn, err := net.DialTimeout("tcp", addr, timeout)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			conn.Close()
+		}
+		if len(failures) > 0 {
+			return pb.HealthCheck_NOT_READY, fmt.Sprintf("Membership services not reachable: %s", strings.Join(failures, "; "))
+		}
+		return pb.HealthCheck_OK, fmt.Sprintf("Membership services reachable at %s", strings.Join(addrs, ", "))
+	}}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+type testConsensusReporter struct {
+	status *pb.ConsensusStatus
+}
+
+func (r *testConsensusReporter) GetConsensusStatus() (*pb.ConsensusStatus, error) {
+	return r.status, nil
+}
+
+func staticCheck(name string, status pb.HealthCheck_Status) HealthCheck {
+	return HealthCheck{Name: name, Check: func() (pb.HealthCheck_Status, string) {
+		return status, ""
+	}}
+}
+
+func TestCheckHealth(t *testing.T) {
+	defer func() { healthChecks.checks = nil }()
+
+	healthChecks.checks = nil
+	RegisterHealthCheck(staticCheck("a", pb.HealthCheck_OK))
+	RegisterHealthCheck(staticCheck("b", pb.HealthCheck_WARNING))
+	if health := CheckHealth(); !health.Healthy || !health.Ready || len(health.Checks) != 2 {
+		t.Errorf("Expected a warning to keep the peer healthy and ready, got %s", health)
+	}
+
+	RegisterHealthCheck(staticCheck("c", pb.HealthCheck_NOT_READY))
+	if health := CheckHealth(); !health.Healthy || health.Ready {
+		t.Errorf("Expected the peer to be healthy but not ready, got %s", health)
+	}
+
+	RegisterHealthCheck(staticCheck("d", pb.HealthCheck_FAILED))
+	if health := CheckHealth(); health.Healthy || health.Ready || health.Checks[3].Name != "d" {
+		t.Errorf("Expected the peer to be unhealthy, got %s", health)
+	}
+}
+
+func TestConsensusHealthStall(t *testing.T) {
+	viper.Set("peer.health.consensusStallTimeout", "50ms")
+	defer viper.Set("peer.health.consensusStallTimeout", "")
+
+	pbft := &pb.PbftStatus{ActiveView: true, LastExec: 3, OutstandingRequests: 1}
+	reporter := &testConsensusReporter{status: &pb.ConsensusStatus{Plugin: "pbft", Pbft: pbft}}
+	checks := ConsensusHealthChecks(reporter)
+	progress, stateTransfer := checks[0].Check, checks[1].Check
+
+	if status, _ := progress(); status != pb.HealthCheck_OK {
+		t.Fatalf("Expected consensus to be healthy, got %s", status)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if status, _ := progress(); status != pb.HealthCheck_NOT_READY {
+		t.Fatalf("Expected a consensus stall to make the peer not ready, got %s", status)
+	}
+
+	// Executing a request is progress
+	pbft.LastExec = 4
+	if status, _ := progress(); status != pb.HealthCheck_OK {
+		t.Fatalf("Expected consensus to be healthy once a request is executed, got %s", status)
+	}
+
+	pbft.ActiveView = false
+	if status, _ := progress(); status != pb.HealthCheck_NOT_READY {
+		t.Errorf("Expected a view change to make the peer not ready, got %s", status)
+	}
+
+	pbft.StateTransferInProgress = true
+	time.Sleep(60 * time.Millisecond)
+	if status, _ := stateTransfer(); status != pb.HealthCheck_NOT_READY {
+		t.Errorf("Expected state transfer to make the peer not ready, got %s", status)
+	}
+	if status, msg := progress(); strings.HasPrefix(msg, "No request executed") {
+		t.Errorf("Expected state transfer not to count as a stall, got %s: %s", status, msg)
+	}
+}
+
+func TestPeersHealth(t *testing.T) {
+	defer viper.Set("peer.discovery.rootnode", "")
+	defer viper.Set("peer.health.minPeers", 0)
+
+	peers := &pb.PeersMessage{}
+	check := PeersHealthCheck(func() (*pb.PeersMessage, error) { return peers, nil }).Check
+
+	viper.Set("peer.discovery.rootnode", "")
+	if status, _ := check(); status != pb.HealthCheck_OK {
+		t.Errorf("Expected a peer without root nodes to be ready alone, got %s", status)
+	}
+
+	viper.Set("peer.discovery.rootnode", "localhost:7051")
+	if status, _ := check(); status != pb.HealthCheck_NOT_READY {
+		t.Errorf("Expected the peer not to be ready until it connects to a peer, got %s", status)
+	}
+
+	viper.Set("peer.health.minPeers", 2)
+	peers.Peers = []*pb.PeerEndpoint{{}}
+	if status, _ := check(); status != pb.HealthCheck_NOT_READY {
+		t.Errorf("Expected the peer not to be ready with fewer than the minimum peers, got %s", status)
+	}
+	peers.Peers = append(peers.Peers, &pb.PeerEndpoint{})
+	if status, _ := check(); status != pb.HealthCheck_OK {
+		t.Errorf("Expected the peer to be ready with the minimum peers, got %s", status)
+	}
+}
+
+func TestChainHealthChecks(t *testing.T) {
+	reporter := &testConsensusReporter{status: &pb.ConsensusStatus{Plugin: "noops"}}
+	checks := ChainConsensusHealthChecks(reporter, "chain1")
+	if checks[0].Name != "consensus of chain chain1" || checks[1].Name != "state transfer of chain chain1" {
+		t.Errorf("Expected the checks to name the chain, got %q and %q", checks[0].Name, checks[1].Name)
+	}
+	if checks := ConsensusHealthChecks(reporter); checks[0].Name != "consensus" {
+		t.Errorf("Expected the checks of the default chain not to name it, got %q", checks[0].Name)
+	}
+	if check := ChainLedgerHealthCheck("chain1"); check.Name != "ledger of chain chain1" {
+		t.Errorf("Expected the ledger check to name the chain, got %q", check.Name)
+	}
+}
@@ -31,6 +31,7 @@ import (
 // Blockchain holds basic information in memory. Operations on Blockchain are not thread-safe
 // TODO synchronize access to in-memory variables
 type blockchain struct {
+	openchainDB        *db.OpenchainDB
 	size               uint64
 	previousBlockHash  []byte
 	indexer            blockchainIndexer
@@ -45,15 +46,15 @@ type lastProcessedBlock struct {
 
 var indexBlockDataSynchronously = true
 
-func newBlockchain() (*blockchain, error) {
-	size, err := fetchBlockchainSizeFromDB()
+func newBlockchain(openchainDB *db.OpenchainDB) (*blockchain, error) {
+	size, err := fetchBlockchainSizeFromDB(openchainDB)
 	if err != nil {
 		return nil, err
 	}
-	blockchain := &blockchain{0, nil, nil, nil}
+	blockchain := &blockchain{openchainDB, 0, nil, nil, nil}
 	blockchain.size = size
 	if size > 0 {
-		previousBlock, err := fetchBlockFromDB(size - 1)
+		previousBlock, err := fetchBlockFromDB(openchainDB, size-1)
 		if err != nil {
 			return nil, err
 		}
@@ -73,9 +74,9 @@ func newBlockchain() (*blockchain, error) {
 
 func (blockchain *blockchain) startIndexer() (err error) {
 	if indexBlockDataSynchronously {
-		blockchain.indexer = newBlockchainIndexerSync()
+		blockchain.indexer = newBlockchainIndexerSync(blockchain.openchainDB)
 	} else {
-		blockchain.indexer = newBlockchainIndexerAsync()
+		blockchain.indexer = newBlockchainIndexerAsync(blockchain.openchainDB)
 	}
 	err = blockchain.indexer.start(blockchain)
 	return
@@ -96,7 +97,7 @@ func (blockchain *blockchain) getSize() uint64 {
 
 // getBlock get block at arbitrary height in block chain
 func (blockchain *blockchain) getBlock(blockNumber uint64) (*protos.Block, error) {
-	return fetchBlockFromDB(blockNumber)
+	return fetchBlockFromDB(blockchain.openchainDB, blockNumber)
 }
 
 // getBlockByHash get block by block hash
@@ -204,8 +205,8 @@ func (blockchain *blockchain) addPersistenceChangesForNewBlock(ctx context.Conte
 	if blockBytesErr != nil {
 		return 0, blockBytesErr
 	}
-	writeBatch.PutCF(db.GetDBHandle().BlockchainCF, encodeBlockNumberDBKey(blockNumber), blockBytes)
-	writeBatch.PutCF(db.GetDBHandle().BlockchainCF, blockCountKey, encodeUint64(blockNumber+1))
+	writeBatch.PutCF(blockchain.openchainDB.BlockchainCF, encodeBlockNumberDBKey(blockNumber), blockBytes)
+	writeBatch.PutCF(blockchain.openchainDB.BlockchainCF, blockCountKey, encodeUint64(blockNumber+1))
 	if blockchain.indexer.isSynchronous() {
 		blockchain.indexer.createIndexes(block, blockNumber, blockHash, writeBatch)
 	}
@@ -234,7 +235,7 @@ func (blockchain *blockchain) persistRawBlock(block *protos.Block, blockNumber u
 	}
 	writeBatch := gorocksdb.NewWriteBatch()
 	defer writeBatch.Destroy()
-	writeBatch.PutCF(db.GetDBHandle().BlockchainCF, encodeBlockNumberDBKey(blockNumber), blockBytes)
+	writeBatch.PutCF(blockchain.openchainDB.BlockchainCF, encodeBlockNumberDBKey(blockNumber), blockBytes)
 
 	blockHash, err := block.GetHash()
 	if err != nil {
@@ -245,7 +246,7 @@ func (blockchain *blockchain) persistRawBlock(block *protos.Block, blockNumber u
 	// real blockchain height, not size.
 	if blockchain.getSize() < blockNumber+1 {
 		sizeBytes := encodeUint64(blockNumber + 1)
-		writeBatch.PutCF(db.GetDBHandle().BlockchainCF, blockCountKey, sizeBytes)
+		writeBatch.PutCF(blockchain.openchainDB.BlockchainCF, blockCountKey, sizeBytes)
 		blockchain.size = blockNumber + 1
 		blockchain.previousBlockHash = blockHash
 	}
@@ -256,15 +257,15 @@ func (blockchain *blockchain) persistRawBlock(block *protos.Block, blockNumber u
 
 	opt := gorocksdb.NewDefaultWriteOptions()
 	defer opt.Destroy()
-	err = db.GetDBHandle().DB.Write(opt, writeBatch)
+	err = blockchain.openchainDB.DB.Write(opt, writeBatch)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func fetchBlockFromDB(blockNumber uint64) (*protos.Block, error) {
-	blockBytes, err := db.GetDBHandle().GetFromBlockchainCF(encodeBlockNumberDBKey(blockNumber))
+func fetchBlockFromDB(openchainDB *db.OpenchainDB, blockNumber uint64) (*protos.Block, error) {
+	blockBytes, err := openchainDB.GetFromBlockchainCF(encodeBlockNumberDBKey(blockNumber))
 	if err != nil {
 		return nil, err
 	}
@@ -274,8 +275,8 @@ func fetchBlockFromDB(blockNumber uint64) (*protos.Block, error) {
 	return protos.UnmarshallBlock(blockBytes)
 }
 
-func fetchBlockchainSizeFromDB() (uint64, error) {
-	bytes, err := db.GetDBHandle().GetFromBlockchainCF(blockCountKey)
+func fetchBlockchainSizeFromDB(openchainDB *db.OpenchainDB) (uint64, error) {
+	bytes, err := openchainDB.GetFromBlockchainCF(blockCountKey)
 	if err != nil {
 		return 0, err
 	}
@@ -285,8 +286,8 @@ func fetchBlockchainSizeFromDB() (uint64, error) {
 	return decodeToUint64(bytes), nil
 }
 
-func fetchBlockchainSizeFromSnapshot(snapshot *gorocksdb.Snapshot) (uint64, error) {
-	blockNumberBytes, err := db.GetDBHandle().GetFromBlockchainCFSnapshot(snapshot, blockCountKey)
+func fetchBlockchainSizeFromSnapshot(openchainDB *db.OpenchainDB, snapshot *gorocksdb.Snapshot) (uint64, error) {
+	blockNumberBytes, err := openchainDB.GetFromBlockchainCFSnapshot(snapshot, blockCountKey)
 	if err != nil {
 		return 0, err
 	}
@@ -42,10 +42,11 @@ type blockchainIndexer interface {
 
 // Implementation for sync indexer
 type blockchainIndexerSync struct {
+	openchainDB *db.OpenchainDB
 }
 
-func newBlockchainIndexerSync() *blockchainIndexerSync {
-	return &blockchainIndexerSync{}
+func newBlockchainIndexerSync(openchainDB *db.OpenchainDB) *blockchainIndexerSync {
+	return &blockchainIndexerSync{openchainDB}
 }
 
 func (indexer *blockchainIndexerSync) isSynchronous() bool {
@@ -58,15 +59,15 @@ func (indexer *blockchainIndexerSync) start(blockchain *blockchain) error {
 
 func (indexer *blockchainIndexerSync) createIndexes(
 	block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error {
-	return addIndexDataForPersistence(block, blockNumber, blockHash, writeBatch)
+	return addIndexDataForPersistence(indexer.openchainDB, block, blockNumber, blockHash, writeBatch)
 }
 
 func (indexer *blockchainIndexerSync) fetchBlockNumberByBlockHash(blockHash []byte) (uint64, error) {
-	return fetchBlockNumberByBlockHashFromDB(blockHash)
+	return fetchBlockNumberByBlockHashFromDB(indexer.openchainDB, blockHash)
 }
 
 func (indexer *blockchainIndexerSync) fetchTransactionIndexByID(txID string) (uint64, uint64, error) {
-	return fetchTransactionIndexByIDFromDB(txID)
+	return fetchTransactionIndexByIDFromDB(indexer.openchainDB, txID)
 }
 
 func (indexer *blockchainIndexerSync) stop() {
@@ -74,8 +75,7 @@ func (indexer *blockchainIndexerSync) stop() {
 }
 
 // Functions for persisting and retrieving index data
-func addIndexDataForPersistence(block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error {
-	openchainDB := db.GetDBHandle()
+func addIndexDataForPersistence(openchainDB *db.OpenchainDB, block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error {
 	cf := openchainDB.IndexesCF
 
 	// add blockhash -> blockNumber
@@ -107,9 +107,9 @@ func addIndexDataForPersistence(block *protos.Block, blockNumber uint64, blockHa
 	return nil
 }
 
-func fetchBlockNumberByBlockHashFromDB(blockHash []byte) (uint64, error) {
+func fetchBlockNumberByBlockHashFromDB(openchainDB *db.OpenchainDB, blockHash []byte) (uint64, error) {
 	indexLogger.Debugf("fetchBlockNumberByBlockHashFromDB() for blockhash [%x]", blockHash)
-	blockNumberBytes, err := db.GetDBHandle().GetFromIndexesCF(encodeBlockHashKey(blockHash))
+	blockNumberBytes, err := openchainDB.GetFromIndexesCF(encodeBlockHashKey(blockHash))
 	if err != nil {
 		return 0, err
 	}
@@ -121,8 +121,8 @@ func fetchBlockNumberByBlockHashFromDB(blockHash []byte) (uint64, error) {
 	return blockNumber, nil
 }
 
-func fetchTransactionIndexByIDFromDB(txID string) (uint64, uint64, error) {
-	blockNumTxIndexBytes, err := db.GetDBHandle().GetFromIndexesCF(encodeTxIDKey(txID))
+func fetchTransactionIndexByIDFromDB(openchainDB *db.OpenchainDB, txID string) (uint64, uint64, error) {
+	blockNumTxIndexBytes, err := openchainDB.GetFromIndexesCF(encodeTxIDKey(txID))
 	if err != nil {
 		return 0, 0, err
 	}
@@ -35,14 +35,18 @@ type blockWrapper struct {
 }
 
 type blockchainIndexerAsync struct {
-	blockchain *blockchain
+	openchainDB *db.OpenchainDB
+	blockchain  *blockchain
 	// Channel for transferring block from block chain for indexing
 	blockChan    chan blockWrapper
 	indexerState *blockchainIndexerState
+	// Once stopped, the blocks committed are indexed at the next start
+	stopLock sync.RWMutex
+	stopped  bool
 }
 
-func newBlockchainIndexerAsync() *blockchainIndexerAsync {
-	return new(blockchainIndexerAsync)
+func newBlockchainIndexerAsync(openchainDB *db.OpenchainDB) *blockchainIndexerAsync {
+	return &blockchainIndexerAsync{openchainDB: openchainDB}
 }
 
 func (indexer *blockchainIndexerAsync) isSynchronous() bool {
@@ -100,16 +104,22 @@ func (indexer *blockchainIndexerAsync) start(blockchain *blockchain) error {
 }
 
 func (indexer *blockchainIndexerAsync) createIndexes(block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error {
+	indexer.stopLock.RLock()
+	defer indexer.stopLock.RUnlock()
+	if indexer.stopped {
+		indexLogger.Debugf("Indexer stopped, block number [%d] will be indexed at the next start", blockNumber)
+		return nil
+	}
 	indexer.blockChan <- blockWrapper{block, blockNumber, blockHash, false}
 	return nil
 }
 
 // createIndexes adds entries into db for creating indexes on various attributes
 func (indexer *blockchainIndexerAsync) createIndexesInternal(block *protos.Block, blockNumber uint64, blockHash []byte) error {
-	openchainDB := db.GetDBHandle()
+	openchainDB := indexer.openchainDB
 	writeBatch := gorocksdb.NewWriteBatch()
 	defer writeBatch.Destroy()
-	addIndexDataForPersistence(block, blockNumber, blockHash, writeBatch)
+	addIndexDataForPersistence(openchainDB, block, blockNumber, blockHash, writeBatch)
 	writeBatch.PutCF(openchainDB.IndexesCF, lastIndexedBlockKey, encodeBlockNumber(blockNumber))
 	opt := gorocksdb.NewDefaultWriteOptions()
 	defer opt.Destroy()
@@ -128,7 +138,7 @@ func (indexer *blockchainIndexerAsync) fetchBlockNumberByBlockHash(blockHash []b
 		return 0, err
 	}
 	indexer.indexerState.waitForLastCommittedBlock()
-	return fetchBlockNumberByBlockHashFromDB(blockHash)
+	return fetchBlockNumberByBlockHashFromDB(indexer.openchainDB, blockHash)
 }
 
 func (indexer *blockchainIndexerAsync) fetchTransactionIndexByID(txID string) (uint64, uint64, error) {
@@ -137,7 +147,7 @@ func (indexer *blockchainIndexerAsync) fetchTransactionIndexByID(txID string) (u
 		return 0, 0, err
 	}
 	indexer.indexerState.waitForLastCommittedBlock()
-	return fetchTransactionIndexByIDFromDB(txID)
+	return fetchTransactionIndexByIDFromDB(indexer.openchainDB, txID)
 }
 
 func (indexer *blockchainIndexerAsync) indexPendingBlocks() error {
@@ -195,6 +205,12 @@ func (indexer *blockchainIndexerAsync) fetchBlockFromDBAndCreateIndexes(blockNum
 
 func (indexer *blockchainIndexerAsync) stop() {
 	indexer.indexerState.waitForLastCommittedBlock()
+	indexer.stopLock.Lock()
+	defer indexer.stopLock.Unlock()
+	if indexer.stopped {
+		return
+	}
+	indexer.stopped = true
 	indexer.blockChan <- blockWrapper{nil, 0, nil, true}
 	<-indexer.blockChan
 	close(indexer.blockChan)
@@ -221,7 +237,7 @@ type blockchainIndexerState struct {
 
 func newBlockchainIndexerState(indexer *blockchainIndexerAsync) (*blockchainIndexerState, error) {
 	var lock sync.RWMutex
-	zerothBlockIndexed, lastIndexedBlockNum, err := fetchLastIndexedBlockNumFromDB()
+	zerothBlockIndexed, lastIndexedBlockNum, err := fetchLastIndexedBlockNumFromDB(indexer.openchainDB)
 	if err != nil {
 		return nil, err
 	}
@@ -310,8 +326,8 @@ func (indexerState *blockchainIndexerState) checkError() error {
 	return indexerState.err
 }
 
-func fetchLastIndexedBlockNumFromDB() (zerothBlockIndexed bool, lastIndexedBlockNum uint64, err error) {
-	lastIndexedBlockNumberBytes, err := db.GetDBHandle().GetFromIndexesCF(lastIndexedBlockKey)
+func fetchLastIndexedBlockNumFromDB(openchainDB *db.OpenchainDB) (zerothBlockIndexed bool, lastIndexedBlockNum uint64, err error) {
+	lastIndexedBlockNumberBytes, err := openchainDB.GetFromIndexesCF(lastIndexedBlockKey)
 	if err != nil {
 		return
 	}
@@ -79,16 +79,21 @@ var (
 
 // Ledger - the struct for openchain ledger
 type Ledger struct {
-	blockchain *blockchain
-	state      *state.State
-	currentID  interface{}
+	chainID     string
+	openchainDB *db.OpenchainDB
+	blockchain  *blockchain
+	state       *state.State
+	currentID   interface{}
 }
 
 var ledger *Ledger
 var ledgerError error
 var once sync.Once
 
-// GetLedger - gives a reference to a 'singleton' ledger
+var chainLedgers = make(map[string]*Ledger)
+var chainLedgersLock sync.Mutex
+
+// GetLedger - gives a reference to a 'singleton' ledger of the default chain
 func GetLedger() (*Ledger, error) {
 	once.Do(func() {
 		ledger, ledgerError = GetNewLedger()
@@ -96,15 +101,57 @@ func GetLedger() (*Ledger, error) {
 	return ledger, ledgerError
 }
 
+// GetChainLedger - gives a reference to the 'singleton' ledger of a chain,
+// the ledgers of different chains share no blocks and no state
+func GetChainLedger(chainID string) (*Ledger, error) {
+	if db.NormalizeChainID(chainID) == db.DefaultChainID {
+		return GetLedger()
+	}
+	chainLedgersLock.Lock()
+	defer chainLedgersLock.Unlock()
+	if chainLedger, ok := chainLedgers[chainID]; ok {
+		return chainLedger, nil
+	}
+	openchainDB, err := db.GetChainDBHandle(chainID)
+	if err != nil {
+		return nil, err
+	}
+	chainLedger, err := newLedger(chainID, openchainDB)
+	if err != nil {
+		return nil, err
+	}
+	chainLedgers[chainID] = chainLedger
+	return chainLedger, nil
+}
+
+// StopIndexers waits for the blocks committed to the ledgers of the chains to
+// be indexed and stops indexing. The blocks committed afterwards are indexed
+// when the peer starts again.
+func StopIndexers() {
+	chainLedgersLock.Lock()
+	defer chainLedgersLock.Unlock()
+	if ledger != nil {
+		ledger.blockchain.indexer.stop()
+	}
+	for _, chainLedger := range chainLedgers {
+		chainLedger.blockchain.indexer.stop()
+	}
+	ledgerLogger.Info("Stopped block indexers")
+}
+
 // GetNewLedger - gives a reference to a new ledger TODO need better approach
 func GetNewLedger() (*Ledger, error) {
-	blockchain, err := newBlockchain()
+	return newLedger(db.DefaultChainID, db.GetDBHandle())
+}
+
+func newLedger(chainID string, openchainDB *db.OpenchainDB) (*Ledger, error) {
+	blockchain, err := newBlockchain(openchainDB)
 	if err != nil {
 		return nil, err
 	}
 
-	state := state.NewState()
-	return &Ledger{blockchain, state, nil}, nil
+	state := state.NewState(openchainDB)
+	return &Ledger{chainID, openchainDB, blockchain, state, nil}, nil
 }
 
 /////////////////// Transaction-batch related methods ///////////////////////////////
@@ -193,7 +240,7 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.Trans
 	ledger.state.AddChangesForPersistence(newBlockNumber, writeBatch)
 	opt := gorocksdb.NewDefaultWriteOptions()
 	defer opt.Destroy()
-	dbErr := db.GetDBHandle().DB.Write(opt, writeBatch)
+	dbErr := ledger.openchainDB.DB.Write(opt, writeBatch)
 	if dbErr != nil {
 		ledger.resetForNextTxGroup(false)
 		ledger.blockchain.blockPersistenceStatus(false)
@@ -203,10 +250,10 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.Trans
 	ledger.resetForNextTxGroup(true)
 	ledger.blockchain.blockPersistenceStatus(true)
 
-	sendProducerBlockEvent(block)
+	sendProducerBlockEvent(ledger.chainID, block)
 
 	//send chaincode events from transaction results
-	sendChaincodeEvents(transactionResults)
+	sendChaincodeEvents(ledger.chainID, transactionResults)
 
 	if len(transactionResults) != 0 {
 		ledgerLogger.Debug("There were some erroneous transactions. We need to send a 'TX rejected' message here.")
@@ -304,8 +351,8 @@ func (ledger *Ledger) SetStateMultipleKeys(chaincodeID string, kvs map[string][]
 // should be used when transferring the state from one peer to another peer. You must call
 // stateSnapshot.Release() once you are done with the snapshot to free up resources.
 func (ledger *Ledger) GetStateSnapshot() (*state.StateSnapshot, error) {
-	dbSnapshot := db.GetDBHandle().GetSnapshot()
-	blockHeight, err := fetchBlockchainSizeFromSnapshot(dbSnapshot)
+	dbSnapshot := ledger.openchainDB.GetSnapshot()
+	blockHeight, err := fetchBlockchainSizeFromSnapshot(ledger.openchainDB, dbSnapshot)
 	if err != nil {
 		dbSnapshot.Release()
 		return nil, err
@@ -317,6 +364,12 @@ func (ledger *Ledger) GetStateSnapshot() (*state.StateSnapshot, error) {
 	return ledger.state.GetSnapshot(blockHeight-1, dbSnapshot)
 }
 
+// GetStatePartitioning returns how the state is split into partitions, which are retrieved from several
+// peers at once, and verified one at a time, when transferring the state. See StateSnapshot.GetPartitionIterator.
+func (ledger *Ledger) GetStatePartitioning() (statemgmt.PartitionedState, error) {
+	return ledger.state.GetPartitionedState()
+}
+
 // GetStateDelta will return the state delta for the specified block if
 // available.  If not available because it has been discarded, returns nil,nil.
 func (ledger *Ledger) GetStateDelta(blockNumber uint64) (*statemgmt.StateDelta, error) {
@@ -411,6 +464,13 @@ func (ledger *Ledger) GetTransactionByID(txID string) (*protos.Transaction, erro
 	return ledger.blockchain.getTransactionByID(txID)
 }
 
+// GetTransactionBlockNumber returns the number of the block which contains
+// the transaction, or ErrResourceNotFound if it is not committed
+func (ledger *Ledger) GetTransactionBlockNumber(txID string) (uint64, error) {
+	blockNumber, _, err := ledger.blockchain.indexer.fetchTransactionIndexByID(txID)
+	return blockNumber, err
+}
+
 // PutRawBlock puts a raw block on the chain. This function should only be
 // used for synchronization between peers.
 func (ledger *Ledger) PutRawBlock(block *protos.Block, blockNumber uint64) error {
@@ -418,7 +478,7 @@ func (ledger *Ledger) PutRawBlock(block *protos.Block, blockNumber uint64) error
 	if err != nil {
 		return err
 	}
-	sendProducerBlockEvent(block)
+	sendProducerBlockEvent(ledger.chainID, block)
 	return nil
 }
 
@@ -493,7 +553,7 @@ func (ledger *Ledger) resetForNextTxGroup(txCommited bool) {
 	ledger.state.ClearInMemoryChanges(txCommited)
 }
 
-func sendProducerBlockEvent(block *protos.Block) {
+func sendProducerBlockEvent(chainID string, block *protos.Block) {
 
 	// Remove payload from deploy transactions. This is done to make block
 	// events more lightweight as the payload for these types of transactions
@@ -517,17 +577,21 @@ func sendProducerBlockEvent(block *protos.Block) {
 		}
 	}
 
-	producer.Send(producer.CreateBlockEvent(block))
+	event := producer.CreateBlockEvent(block)
+	event.ChainID = chainID
+	producer.Send(event)
 }
 
 //send chaincode events created by transactions
-func sendChaincodeEvents(trs []*protos.TransactionResult) {
+func sendChaincodeEvents(chainID string, trs []*protos.TransactionResult) {
 	if trs != nil {
 		for _, tr := range trs {
 			//we store empty chaincode events in the protobuf repeated array to make protobuf happy.
 			//when we replay off a block ignore empty events
 			if tr.ChaincodeEvent != nil && tr.ChaincodeEvent.ChaincodeID != "" {
-				producer.Send(producer.CreateChaincodeEvent(tr.ChaincodeEvent))
+				event := producer.CreateChaincodeEvent(tr.ChaincodeEvent)
+				event.ChainID = chainID
+				producer.Send(event)
 			}
 		}
 	}
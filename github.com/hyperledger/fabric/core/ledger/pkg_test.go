@@ -20,6 +20,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/core/util"
@@ -42,7 +43,7 @@ type blockchainTestWrapper struct {
 }
 
 func newTestBlockchainWrapper(t *testing.T) *blockchainTestWrapper {
-	blockchain, err := newBlockchain()
+	blockchain, err := newBlockchain(db.GetDBHandle())
 	testutil.AssertNoError(t, err, "Error while getting handle to chain")
 	return &blockchainTestWrapper{t, blockchain}
 }
@@ -58,7 +59,7 @@ func (testWrapper *blockchainTestWrapper) addNewBlock(block *protos.Block, state
 }
 
 func (testWrapper *blockchainTestWrapper) fetchBlockchainSizeFromDB() uint64 {
-	size, err := fetchBlockchainSizeFromDB()
+	size, err := fetchBlockchainSizeFromDB(testWrapper.blockchain.openchainDB)
 	testutil.AssertNoError(testWrapper.t, err, "Error while fetching blockchain size from db")
 	return size
 }
@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buckettree
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/tecbot/gorocksdb"
+)
+
+// The partitions of the state are the buckets of a level of the tree, partition i being bucket i+1. The data nodes
+// of a bucket are stored contiguously, so a partition is iterated over without scanning the rest of the state, and
+// the crypto-hash of a partition is the crypto-hash of its bucket.
+
+// GetNumPartitions - method implementation for interface 'statemgmt.PartitionedState'
+func (stateImpl *StateImpl) GetNumPartitions(max uint32) uint32 {
+	level := 0
+	for level < conf.getLowestLevel() && uint32(conf.getNumBuckets(level+1)) <= max {
+		level++
+	}
+	return uint32(conf.getNumBuckets(level))
+}
+
+// GetPartition - method implementation for interface 'statemgmt.PartitionedState'
+func (stateImpl *StateImpl) GetPartition(compositeKey []byte, partitions uint32) (uint32, error) {
+	level, err := partitionLevel(partitions)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(ancestorBucketNumber(lowestBucketNumber(compositeKey), level) - 1), nil
+}
+
+// GetPartitionSnapshotIterator - method implementation for interface 'statemgmt.PartitionedState'
+func (stateImpl *StateImpl) GetPartitionSnapshotIterator(snapshot *gorocksdb.Snapshot, partition, partitions uint32) (statemgmt.StateSnapshotIterator, error) {
+	level, err := partitionLevel(partitions)
+	if err != nil {
+		return nil, err
+	}
+	if partition >= partitions {
+		return nil, fmt.Errorf("Invalid partition %d of %d", partition, partitions)
+	}
+	first, last := descendantBucketNumbers(int(partition)+1, level)
+	dbItr := stateImpl.openchainDB.GetStateCFSnapshotIterator(snapshot)
+	dbItr.Seek(minimumPossibleDataKeyBytesFor(newBucketKeyAtLowestLevel(first)))
+	return &partitionSnapshotIterator{StateSnapshotIterator{dbItr}, encodeBucketNumber(last + 1), false}, nil
+}
+
+// ComputePartitionCryptoHash - method implementation for interface 'statemgmt.PartitionedState'
+func (stateImpl *StateImpl) ComputePartitionCryptoHash(partition, partitions uint32, kvs map[string][]byte) ([]byte, error) {
+	level, err := partitionLevel(partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[int]dataNodes)
+	for k, v := range kvs {
+		compositeKey := []byte(k)
+		bucketNumber := lowestBucketNumber(compositeKey)
+		if ancestorBucketNumber(bucketNumber, level) != int(partition)+1 {
+			return nil, fmt.Errorf("Key %x does not belong to partition %d of %d", compositeKey, partition, partitions)
+		}
+		if v == nil {
+			continue
+		}
+		buckets[bucketNumber] = append(buckets[bucketNumber], newDataNode(&dataKey{newBucketKeyAtLowestLevel(bucketNumber), compositeKey}, v))
+	}
+
+	hashes := make(map[int][]byte)
+	for bucketNumber, nodes := range buckets {
+		sort.Sort(nodes)
+		calculator := newBucketHashCalculator(newBucketKeyAtLowestLevel(bucketNumber))
+		for _, node := range nodes {
+			calculator.addNextNode(node)
+		}
+		hashes[bucketNumber] = calculator.computeCryptoHash()
+	}
+	return computeAncestorCryptoHashes(conf.getLowestLevel(), hashes, level)[int(partition)+1], nil
+}
+
+// ComputeCryptoHashOfPartitions - method implementation for interface 'statemgmt.PartitionedState'
+func (stateImpl *StateImpl) ComputeCryptoHashOfPartitions(hashes [][]byte) ([]byte, error) {
+	level, err := partitionLevel(uint32(len(hashes)))
+	if err != nil {
+		return nil, err
+	}
+	byBucketNumber := make(map[int][]byte)
+	for i, hash := range hashes {
+		if len(hash) != 0 {
+			byBucketNumber[i+1] = hash
+		}
+	}
+	return computeAncestorCryptoHashes(level, byBucketNumber, 0)[1], nil
+}
+
+// partitionLevel returns the level of the tree whose buckets are the partitions
+func partitionLevel(partitions uint32) (int, error) {
+	for level := 0; level <= conf.getLowestLevel(); level++ {
+		if uint32(conf.getNumBuckets(level)) == partitions {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("The state can not be split into %d partitions", partitions)
+}
+
+func lowestBucketNumber(compositeKey []byte) int {
+	return int(conf.computeBucketHash(compositeKey))%conf.getNumBucketsAtLowestLevel() + 1
+}
+
+// ancestorBucketNumber returns the bucket at a level a bucket of the lowest level descends from
+func ancestorBucketNumber(bucketNumber int, level int) int {
+	for l := conf.getLowestLevel(); l > level; l-- {
+		bucketNumber = conf.computeParentBucketNumber(bucketNumber)
+	}
+	return bucketNumber
+}
+
+// descendantBucketNumbers returns the range of buckets of the lowest level which descend from a bucket at a level
+func descendantBucketNumbers(bucketNumber int, level int) (int, int) {
+	first, last := bucketNumber, bucketNumber
+	for l := level; l < conf.getLowestLevel(); l++ {
+		first = (first-1)*conf.getMaxGroupingAtEachLevel() + 1
+		last = last * conf.getMaxGroupingAtEachLevel()
+	}
+	if last > conf.getNumBucketsAtLowestLevel() {
+		last = conf.getNumBucketsAtLowestLevel()
+	}
+	return first, last
+}
+
+// computeAncestorCryptoHashes computes the crypto-hashes of the buckets at a level from the crypto-hashes of the
+// buckets of a lower level, the way the tree does
+func computeAncestorCryptoHashes(level int, hashes map[int][]byte, ancestorLevel int) map[int][]byte {
+	for ; level > ancestorLevel; level-- {
+		parents := make(map[int]*bucketNode)
+		for bucketNumber, hash := range hashes {
+			bucketKey := newBucketKey(level, bucketNumber)
+			parentKey := bucketKey.getParentKey()
+			parent, ok := parents[parentKey.bucketNumber]
+			if !ok {
+				parent = newBucketNode(parentKey)
+				parents[parentKey.bucketNumber] = parent
+			}
+			parent.setChildCryptoHash(bucketKey, hash)
+		}
+		hashes = make(map[int][]byte)
+		for bucketNumber, parent := range parents {
+			hashes[bucketNumber] = parent.computeCryptoHash()
+		}
+	}
+	return hashes
+}
+
+// partitionSnapshotIterator iterates over the data nodes of a partition of a snapshot
+type partitionSnapshotIterator struct {
+	StateSnapshotIterator
+	end     []byte // The first data key after the partition
+	started bool
+}
+
+// Next - see interface 'statemgmt.StateSnapshotIterator' for details
+func (itr *partitionSnapshotIterator) Next() bool {
+	if itr.started {
+		itr.dbItr.Next()
+	}
+	itr.started = true
+	return itr.dbItr.Valid() && bytes.Compare(itr.dbItr.Key().Data(), itr.end) < 0
+}
@@ -0,0 +1,153 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buckettree
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestPartitions(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	// Levels of 1, 3, 9 and 26 buckets
+	stateImplTestWrapper := newStateImplTestWrapperWithCustomConfig(t, 26, 3)
+	stateImpl := stateImplTestWrapper.stateImpl
+
+	testutil.AssertEquals(t, stateImpl.GetNumPartitions(0), uint32(1))
+	testutil.AssertEquals(t, stateImpl.GetNumPartitions(8), uint32(3))
+	testutil.AssertEquals(t, stateImpl.GetNumPartitions(9), uint32(9))
+	testutil.AssertEquals(t, stateImpl.GetNumPartitions(100), uint32(26))
+	_, err := stateImpl.GetPartition([]byte("key"), 4)
+	testutil.AssertError(t, err, "The state should not be split into a number of partitions which is not a level of the tree")
+
+	stateDelta := statemgmt.NewStateDelta()
+	for i := 0; i < 60; i++ {
+		stateDelta.Set(fmt.Sprintf("chaincodeID%d", i%4), fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)), nil)
+	}
+	stateHash := stateImplTestWrapper.prepareWorkingSetAndComputeCryptoHash(stateDelta)
+	stateImplTestWrapper.persistChangesAndResetInMemoryChanges()
+
+	dbSnapshot := db.GetDBHandle().GetSnapshot()
+	defer dbSnapshot.Release()
+
+	for _, partitions := range []uint32{1, 3, 9, 26} {
+		numKeys := 0
+		var hashes [][]byte
+		for partition := uint32(0); partition < partitions; partition++ {
+			itr, err := stateImpl.GetPartitionSnapshotIterator(dbSnapshot, partition, partitions)
+			testutil.AssertNoError(t, err, "Error while getting partition iterator")
+			kvs := make(map[string][]byte)
+			for itr.Next() {
+				k, v := itr.GetRawKeyValue()
+				p, _ := stateImpl.GetPartition(k, partitions)
+				testutil.AssertEquals(t, p, partition)
+				kvs[string(k)] = v
+			}
+			itr.Close()
+			numKeys += len(kvs)
+
+			hash, err := stateImpl.ComputePartitionCryptoHash(partition, partitions, kvs)
+			testutil.AssertNoError(t, err, "Error while computing partition crypto-hash")
+			hashes = append(hashes, hash)
+
+			for k := range kvs {
+				kvs[k] = []byte("tampered")
+				tampered, _ := stateImpl.ComputePartitionCryptoHash(partition, partitions, kvs)
+				testutil.AssertNotEquals(t, tampered, hash)
+				if partitions > 1 {
+					_, err = stateImpl.ComputePartitionCryptoHash((partition+1)%partitions, partitions, kvs)
+					testutil.AssertError(t, err, "A key of another partition should be rejected")
+				}
+				break
+			}
+		}
+		testutil.AssertEquals(t, numKeys, 60)
+
+		computed, err := stateImpl.ComputeCryptoHashOfPartitions(hashes)
+		testutil.AssertNoError(t, err, "Error while computing crypto-hash of partitions")
+		testutil.AssertEquals(t, computed, stateHash)
+	}
+}
+
+func TestPartitionCryptoHashes(t *testing.T) {
+	// number of buckets at each level 26,13,7,4,2,1
+	testHasher := newTestHasher()
+	initConfig(map[string]interface{}{
+		ConfigNumBuckets:             26,
+		ConfigMaxGroupingAtEachLevel: 2,
+		ConfigHashFunction:           testHasher.getHashFunction(),
+	})
+	defer initConfig(nil)
+	stateImpl := NewStateImpl(nil)
+
+	testHasher.populate("chaincodeID1", "key1", 0)
+	testHasher.populate("chaincodeID2", "key2", 1)
+	testHasher.populate("chaincodeID3", "key3", 5)
+	testHasher.populate("chaincodeID4", "key4", 9)
+	testHasher.populate("chaincodeID5", "key5", 24)
+	testHasher.populate("chaincodeID6", "key6", 25)
+
+	expectedHashBucket5_1 := expectedBucketHashForTest([]string{"chaincodeID1", "key1", "value1"})
+	expectedHashBucket5_2 := expectedBucketHashForTest([]string{"chaincodeID2", "key2", "value2"})
+	expectedHashBucket5_6 := expectedBucketHashForTest([]string{"chaincodeID3", "key3", "value3"})
+	expectedHashBucket5_10 := expectedBucketHashForTest([]string{"chaincodeID4", "key4", "value4"})
+	expectedHashBucket5_25 := expectedBucketHashForTest([]string{"chaincodeID5", "key5", "value5"})
+	expectedHashBucket5_26 := expectedBucketHashForTest([]string{"chaincodeID6", "key6", "value6"})
+	expectedHashBucket4_1 := testutil.ComputeCryptoHash(expectedHashBucket5_1, expectedHashBucket5_2)
+	expectedHashBucket4_13 := testutil.ComputeCryptoHash(expectedHashBucket5_25, expectedHashBucket5_26)
+	expectedHashBucket2_1 := testutil.ComputeCryptoHash(expectedHashBucket4_1, expectedHashBucket5_6)
+	expectedHashBucket1_1 := testutil.ComputeCryptoHash(expectedHashBucket2_1, expectedHashBucket5_10)
+	expectedHash := testutil.ComputeCryptoHash(expectedHashBucket1_1, expectedHashBucket4_13)
+
+	// The 4 partitions are the buckets of level 2
+	partitions := stateImpl.GetNumPartitions(5)
+	testutil.AssertEquals(t, partitions, uint32(4))
+	byPartition := make([]map[string][]byte, partitions)
+	for i := range byPartition {
+		byPartition[i] = make(map[string][]byte)
+	}
+	for i := 1; i <= 6; i++ {
+		k := statemgmt.ConstructCompositeKey(fmt.Sprintf("chaincodeID%d", i), fmt.Sprintf("key%d", i))
+		partition, err := stateImpl.GetPartition(k, partitions)
+		testutil.AssertNoError(t, err, "Error while getting partition")
+		byPartition[partition][string(k)] = []byte(fmt.Sprintf("value%d", i))
+	}
+	testutil.AssertEquals(t, len(byPartition[0]), 3)
+	testutil.AssertEquals(t, len(byPartition[2]), 0)
+
+	var hashes [][]byte
+	for partition, kvs := range byPartition {
+		hash, err := stateImpl.ComputePartitionCryptoHash(uint32(partition), partitions, kvs)
+		testutil.AssertNoError(t, err, "Error while computing partition crypto-hash")
+		hashes = append(hashes, hash)
+	}
+	testutil.AssertEquals(t, hashes[0], expectedHashBucket2_1)
+	testutil.AssertEquals(t, hashes[1], expectedHashBucket5_10)
+	testutil.AssertNil(t, hashes[2])
+	testutil.AssertEquals(t, hashes[3], expectedHashBucket4_13)
+
+	rootHash, err := stateImpl.ComputeCryptoHashOfPartitions(hashes)
+	testutil.AssertNoError(t, err, "Error while computing crypto-hash of partitions")
+	testutil.AssertEquals(t, rootHash, expectedHash)
+
+	_, err = stateImpl.ComputePartitionCryptoHash(2, partitions, byPartition[3])
+	testutil.AssertError(t, err, "A key of another partition should be rejected")
+}
@@ -70,7 +70,7 @@ type stateImplTestWrapper struct {
 
 func newStateImplTestWrapper(t testing.TB) *stateImplTestWrapper {
 	var configMap map[string]interface{}
-	stateImpl := NewStateImpl()
+	stateImpl := NewStateImpl(db.GetDBHandle())
 	err := stateImpl.Initialize(configMap)
 	testutil.AssertNoError(t, err, "Error while constrcuting stateImpl")
 	return &stateImplTestWrapper{configMap, stateImpl, t}
@@ -78,7 +78,7 @@ func newStateImplTestWrapper(t testing.TB) *stateImplTestWrapper {
 
 func newStateImplTestWrapperWithCustomConfig(t testing.TB, numBuckets int, maxGroupingAtEachLevel int) *stateImplTestWrapper {
 	configMap := map[string]interface{}{ConfigNumBuckets: numBuckets, ConfigMaxGroupingAtEachLevel: maxGroupingAtEachLevel}
-	stateImpl := NewStateImpl()
+	stateImpl := NewStateImpl(db.GetDBHandle())
 	err := stateImpl.Initialize(configMap)
 	testutil.AssertNoError(t, err, "Error while constrcuting stateImpl")
 	return &stateImplTestWrapper{configMap, stateImpl, t}
@@ -93,7 +93,7 @@ func createFreshDBAndInitTestStateImplWithCustomHasher(t testing.TB, numBuckets
 	}
 
 	testDBWrapper.CleanDB(t)
-	stateImpl := NewStateImpl()
+	stateImpl := NewStateImpl(db.GetDBHandle())
 	stateImpl.Initialize(configMap)
 	stateImplTestWrapper := &stateImplTestWrapper{configMap, stateImpl, t}
 	stateDelta := statemgmt.NewStateDelta()
@@ -101,7 +101,7 @@ func createFreshDBAndInitTestStateImplWithCustomHasher(t testing.TB, numBuckets
 }
 
 func (testWrapper *stateImplTestWrapper) constructNewStateImpl() {
-	stateImpl := NewStateImpl()
+	stateImpl := NewStateImpl(db.GetDBHandle())
 	err := stateImpl.Initialize(testWrapper.configMap)
 	testutil.AssertNoError(testWrapper.t, err, "Error while constructing new state tree")
 	testWrapper.stateImpl = stateImpl
@@ -29,6 +29,7 @@ var logger = logging.MustGetLogger("buckettree")
 
 // StateImpl - implements the interface - 'statemgmt.HashableState'
 type StateImpl struct {
+	openchainDB            *db.OpenchainDB
 	dataNodesDelta         *dataNodesDelta
 	bucketTreeDelta        *bucketTreeDelta
 	persistedStateHash     []byte
@@ -37,15 +38,15 @@ type StateImpl struct {
 	bucketCache            *bucketCache
 }
 
-// NewStateImpl constructs a new StateImpl
-func NewStateImpl() *StateImpl {
-	return &StateImpl{}
+// NewStateImpl constructs a new StateImpl stored in the given db
+func NewStateImpl(openchainDB *db.OpenchainDB) *StateImpl {
+	return &StateImpl{openchainDB: openchainDB}
 }
 
 // Initialize - method implementation for interface 'statemgmt.HashableState'
 func (stateImpl *StateImpl) Initialize(configs map[string]interface{}) error {
 	initConfig(configs)
-	rootBucketNode, err := fetchBucketNodeFromDB(constructRootBucketKey())
+	rootBucketNode, err := fetchBucketNodeFromDB(stateImpl.openchainDB, constructRootBucketKey())
 	if err != nil {
 		return err
 	}
@@ -58,7 +59,7 @@ func (stateImpl *StateImpl) Initialize(configs map[string]interface{}) error {
 	if !ok {
 		bucketCacheMaxSize = defaultBucketCacheMaxSize
 	}
-	stateImpl.bucketCache = newBucketCache(bucketCacheMaxSize)
+	stateImpl.bucketCache = newBucketCache(stateImpl.openchainDB, bucketCacheMaxSize)
 	stateImpl.bucketCache.loadAllBucketNodesFromDB()
 	return nil
 }
@@ -66,7 +67,7 @@ func (stateImpl *StateImpl) Initialize(configs map[string]interface{}) error {
 // Get - method implementation for interface 'statemgmt.HashableState'
 func (stateImpl *StateImpl) Get(chaincodeID string, key string) ([]byte, error) {
 	dataKey := newDataKey(chaincodeID, key)
-	dataNode, err := fetchDataNodeFromDB(dataKey)
+	dataNode, err := fetchDataNodeFromDB(stateImpl.openchainDB, dataKey)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +129,7 @@ func (stateImpl *StateImpl) processDataNodeDelta() error {
 	afftectedBuckets := stateImpl.dataNodesDelta.getAffectedBuckets()
 	for _, bucketKey := range afftectedBuckets {
 		updatedDataNodes := stateImpl.dataNodesDelta.getSortedDataNodesFor(bucketKey)
-		existingDataNodes, err := fetchDataNodesFromDBFor(bucketKey)
+		existingDataNodes, err := fetchDataNodesFromDBFor(stateImpl.openchainDB, bucketKey)
 		if err != nil {
 			return err
 		}
@@ -234,7 +235,7 @@ func (stateImpl *StateImpl) AddChangesForPersistence(writeBatch *gorocksdb.Write
 }
 
 func (stateImpl *StateImpl) addDataNodeChangesForPersistence(writeBatch *gorocksdb.WriteBatch) {
-	openchainDB := db.GetDBHandle()
+	openchainDB := stateImpl.openchainDB
 	affectedBuckets := stateImpl.dataNodesDelta.getAffectedBuckets()
 	for _, affectedBucket := range affectedBuckets {
 		dataNodes := stateImpl.dataNodesDelta.getSortedDataNodesFor(affectedBucket)
@@ -251,7 +252,7 @@ func (stateImpl *StateImpl) addDataNodeChangesForPersistence(writeBatch *gorocks
 }
 
 func (stateImpl *StateImpl) addBucketNodeChangesForPersistence(writeBatch *gorocksdb.WriteBatch) {
-	openchainDB := db.GetDBHandle()
+	openchainDB := stateImpl.openchainDB
 	secondLastLevel := conf.getLowestLevel() - 1
 	for level := secondLastLevel; level >= 0; level-- {
 		bucketNodes := stateImpl.bucketTreeDelta.getBucketNodesAt(level)
@@ -293,10 +294,10 @@ func (stateImpl *StateImpl) PerfHintKeyChanged(chaincodeID string, key string) {
 
 // GetStateSnapshotIterator - method implementation for interface 'statemgmt.HashableState'
 func (stateImpl *StateImpl) GetStateSnapshotIterator(snapshot *gorocksdb.Snapshot) (statemgmt.StateSnapshotIterator, error) {
-	return newStateSnapshotIterator(snapshot)
+	return newStateSnapshotIterator(stateImpl.openchainDB, snapshot)
 }
 
 // GetRangeScanIterator - method implementation for interface 'statemgmt.HashableState'
 func (stateImpl *StateImpl) GetRangeScanIterator(chaincodeID string, startKey string, endKey string) (statemgmt.RangeScanIterator, error) {
-	return newRangeScanIterator(chaincodeID, startKey, endKey)
+	return newRangeScanIterator(stateImpl.openchainDB, chaincodeID, startKey, endKey)
 }
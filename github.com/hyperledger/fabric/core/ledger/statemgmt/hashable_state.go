@@ -68,6 +68,30 @@ type HashableState interface {
 	PerfHintKeyChanged(chaincodeID string, key string)
 }
 
+// PartitionedState - Interface that may be implemented by a state management implementation whose
+// crypto-hash can be computed from the crypto-hashes of partitions of the state. This allows retrieving
+// the state from several peers at once, and verifying each partition on its own.
+type PartitionedState interface {
+
+	// GetNumPartitions returns the largest number of partitions, not above max, the state can be split into
+	GetNumPartitions(max uint32) uint32
+
+	// GetPartition returns the partition a composite key belongs to
+	GetPartition(compositeKey []byte, partitions uint32) (uint32, error)
+
+	// GetPartitionSnapshotIterator provides an iterator over the key-values of a partition of the global
+	// state, as returned by GetStateSnapshotIterator, without iterating over the other partitions
+	GetPartitionSnapshotIterator(snapshot *gorocksdb.Snapshot, partition, partitions uint32) (StateSnapshotIterator, error)
+
+	// ComputePartitionCryptoHash computes the crypto-hash of a partition from all its key-values, by
+	// composite key. It returns an error if a key does not belong to the partition
+	ComputePartitionCryptoHash(partition, partitions uint32, kvs map[string][]byte) ([]byte, error)
+
+	// ComputeCryptoHashOfPartitions computes the crypto-hash of the state from the crypto-hashes of all
+	// its partitions
+	ComputeCryptoHashOfPartitions(hashes [][]byte) ([]byte, error)
+}
+
 // StateSnapshotIterator An interface that is to be implemented by the return value of
 // GetStateSnapshotIterator method in the implementation of HashableState interface
 type StateSnapshotIterator interface {
@@ -25,12 +25,13 @@ import (
 // StateImpl implements raw state management. This implementation does not support computation of crypto-hash of the state.
 // It simply stores the compositeKey and value in the db
 type StateImpl struct {
-	stateDelta *statemgmt.StateDelta
+	openchainDB *db.OpenchainDB
+	stateDelta  *statemgmt.StateDelta
 }
 
-// NewStateImpl constructs new instance of raw state
-func NewStateImpl() *StateImpl {
-	return &StateImpl{}
+// NewStateImpl constructs new instance of raw state stored in the given db
+func NewStateImpl(openchainDB *db.OpenchainDB) *StateImpl {
+	return &StateImpl{openchainDB: openchainDB}
 }
 
 // Initialize - method implementation for interface 'statemgmt.HashableState'
@@ -41,8 +42,7 @@ func (impl *StateImpl) Initialize(configs map[string]interface{}) error {
 // Get - method implementation for interface 'statemgmt.HashableState'
 func (impl *StateImpl) Get(chaincodeID string, key string) ([]byte, error) {
 	compositeKey := statemgmt.ConstructCompositeKey(chaincodeID, key)
-	openchainDB := db.GetDBHandle()
-	return openchainDB.GetFromStateCF(compositeKey)
+	return impl.openchainDB.GetFromStateCF(compositeKey)
 }
 
 // PrepareWorkingSet - method implementation for interface 'statemgmt.HashableState'
@@ -67,7 +67,7 @@ func (impl *StateImpl) AddChangesForPersistence(writeBatch *gorocksdb.WriteBatch
 	if delta == nil {
 		return nil
 	}
-	openchainDB := db.GetDBHandle()
+	openchainDB := impl.openchainDB
 	updatedChaincodeIds := delta.GetUpdatedChaincodeIds(false)
 	for _, updatedChaincodeID := range updatedChaincodeIds {
 		updates := delta.GetUpdates(updatedChaincodeID)
@@ -45,7 +45,7 @@ type stateTestWrapper struct {
 }
 
 func newStateTestWrapper(t *testing.T) *stateTestWrapper {
-	return &stateTestWrapper{t, NewState()}
+	return &stateTestWrapper{t, NewState(db.GetDBHandle())}
 }
 
 func (testWrapper *stateTestWrapper) get(chaincodeID string, key string, committed bool) []byte {
@@ -33,8 +33,6 @@ var logger = logging.MustGetLogger("state")
 
 const defaultStateImpl = "buckettree"
 
-var stateImpl statemgmt.HashableState
-
 type stateImplType string
 
 const (
@@ -47,6 +45,7 @@ const (
 // This encapsulates a particular implementation for managing the state persistence
 // This is not thread safe
 type State struct {
+	openchainDB           *db.OpenchainDB
 	stateImpl             statemgmt.HashableState
 	stateDelta            *statemgmt.StateDelta
 	currentTxStateDelta   *statemgmt.StateDelta
@@ -56,17 +55,18 @@ type State struct {
 	historyStateDeltaSize uint64
 }
 
-// NewState constructs a new State. This Initializes encapsulated state implementation
-func NewState() *State {
+// NewState constructs a new State stored in the given db. This Initializes encapsulated state implementation
+func NewState(openchainDB *db.OpenchainDB) *State {
 	initConfig()
 	logger.Infof("Initializing state implementation [%s]", stateImplName)
+	var stateImpl statemgmt.HashableState
 	switch stateImplName {
 	case buckettreeType:
-		stateImpl = buckettree.NewStateImpl()
+		stateImpl = buckettree.NewStateImpl(openchainDB)
 	case trieType:
-		stateImpl = trie.NewStateImpl()
+		stateImpl = trie.NewStateImpl(openchainDB)
 	case rawType:
-		stateImpl = raw.NewStateImpl()
+		stateImpl = raw.NewStateImpl(openchainDB)
 	default:
 		panic("Should not reach here. Configs should have checked for the stateImplName being a valid names ")
 	}
@@ -74,7 +74,7 @@ func NewState() *State {
 	if err != nil {
 		panic(fmt.Errorf("Error during initialization of state implementation: %s", err))
 	}
-	return &State{stateImpl, statemgmt.NewStateDelta(), statemgmt.NewStateDelta(), "", make(map[string][]byte),
+	return &State{openchainDB, stateImpl, statemgmt.NewStateDelta(), statemgmt.NewStateDelta(), "", make(map[string][]byte),
 		false, uint64(deltaHistorySize)}
 }
 
@@ -270,12 +270,21 @@ func (state *State) getStateDelta() *statemgmt.StateDelta {
 // GetSnapshot returns a snapshot of the global state for the current block. stateSnapshot.Release()
 // must be called once you are done.
 func (state *State) GetSnapshot(blockNumber uint64, dbSnapshot *gorocksdb.Snapshot) (*StateSnapshot, error) {
-	return newStateSnapshot(blockNumber, dbSnapshot)
+	return newStateSnapshot(state.stateImpl, blockNumber, dbSnapshot)
+}
+
+// GetPartitionedState returns the state implementation, when it can be split into partitions
+func (state *State) GetPartitionedState() (statemgmt.PartitionedState, error) {
+	partitioned, ok := state.stateImpl.(statemgmt.PartitionedState)
+	if !ok {
+		return nil, fmt.Errorf("State implementation [%s] can not be split into partitions", stateImplName)
+	}
+	return partitioned, nil
 }
 
 // FetchStateDeltaFromDB fetches the StateDelta corrsponding to given blockNumber
 func (state *State) FetchStateDeltaFromDB(blockNumber uint64) (*statemgmt.StateDelta, error) {
-	stateDeltaBytes, err := db.GetDBHandle().GetFromStateDeltaCF(encodeStateDeltaKey(blockNumber))
+	stateDeltaBytes, err := state.openchainDB.GetFromStateDeltaCF(encodeStateDeltaKey(blockNumber))
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +306,7 @@ func (state *State) AddChangesForPersistence(blockNumber uint64, writeBatch *gor
 	state.stateImpl.AddChangesForPersistence(writeBatch)
 
 	serializedStateDelta := state.stateDelta.Marshal()
-	cf := db.GetDBHandle().StateDeltaCF
+	cf := state.openchainDB.StateDeltaCF
 	logger.Debugf("Adding state-delta corresponding to block number[%d]", blockNumber)
 	writeBatch.PutCF(cf, encodeStateDeltaKey(blockNumber), serializedStateDelta)
 	if blockNumber >= state.historyStateDeltaSize {
@@ -332,7 +341,7 @@ func (state *State) CommitStateDelta() error {
 	state.stateImpl.AddChangesForPersistence(writeBatch)
 	opt := gorocksdb.NewDefaultWriteOptions()
 	defer opt.Destroy()
-	return db.GetDBHandle().DB.Write(opt, writeBatch)
+	return state.openchainDB.DB.Write(opt, writeBatch)
 }
 
 // DeleteState deletes ALL state keys/values from the DB. This is generally
@@ -340,7 +349,7 @@ func (state *State) CommitStateDelta() error {
 // a snapshot.
 func (state *State) DeleteState() error {
 	state.ClearInMemoryChanges(false)
-	err := db.GetDBHandle().DeleteState()
+	err := state.openchainDB.DeleteState()
 	if err != nil {
 		logger.Errorf("Error deleting state: %s", err)
 	}
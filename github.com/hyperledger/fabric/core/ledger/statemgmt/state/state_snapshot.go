@@ -17,6 +17,8 @@ limitations under the License.
 package state
 
 import (
+	"fmt"
+
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
 	"github.com/tecbot/gorocksdb"
 )
@@ -24,17 +26,18 @@ import (
 // StateSnapshot encapsulates StateSnapshotIterator given by actual state implementation and the db snapshot
 type StateSnapshot struct {
 	blockNumber  uint64
+	stateImpl    statemgmt.HashableState
 	stateImplItr statemgmt.StateSnapshotIterator
 	dbSnapshot   *gorocksdb.Snapshot
 }
 
 // newStateSnapshot creates a new snapshot of the global state for the current block.
-func newStateSnapshot(blockNumber uint64, dbSnapshot *gorocksdb.Snapshot) (*StateSnapshot, error) {
+func newStateSnapshot(stateImpl statemgmt.HashableState, blockNumber uint64, dbSnapshot *gorocksdb.Snapshot) (*StateSnapshot, error) {
 	itr, err := stateImpl.GetStateSnapshotIterator(dbSnapshot)
 	if err != nil {
 		return nil, err
 	}
-	snapshot := &StateSnapshot{blockNumber, itr, dbSnapshot}
+	snapshot := &StateSnapshot{blockNumber, stateImpl, itr, dbSnapshot}
 	return snapshot, nil
 }
 
@@ -58,3 +61,13 @@ func (ss *StateSnapshot) GetRawKeyValue() ([]byte, []byte) {
 func (ss *StateSnapshot) GetBlockNumber() uint64 {
 	return ss.blockNumber
 }
+
+// GetPartitionIterator returns an iterator over a partition of the snapshot, when the state implementation
+// can be split into partitions. The iterator must be closed before the snapshot is released.
+func (ss *StateSnapshot) GetPartitionIterator(partition, partitions uint32) (statemgmt.StateSnapshotIterator, error) {
+	partitioned, ok := ss.stateImpl.(statemgmt.PartitionedState)
+	if !ok {
+		return nil, fmt.Errorf("State implementation [%s] can not be split into partitions", stateImplName)
+	}
+	return partitioned.GetPartitionSnapshotIterator(ss.dbSnapshot, partition, partitions)
+}
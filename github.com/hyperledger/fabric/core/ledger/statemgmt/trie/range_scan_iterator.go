@@ -32,8 +32,8 @@ type RangeScanIterator struct {
 	done         bool
 }
 
-func newRangeScanIterator(chaincodeID string, startKey string, endKey string) (*RangeScanIterator, error) {
-	dbItr := db.GetDBHandle().GetStateCFIterator()
+func newRangeScanIterator(openchainDB *db.OpenchainDB, chaincodeID string, startKey string, endKey string) (*RangeScanIterator, error) {
+	dbItr := openchainDB.GetStateCFIterator()
 	encodedStartKey := newTrieKey(chaincodeID, startKey).getEncodedBytes()
 	dbItr.Seek(encodedStartKey)
 	return &RangeScanIterator{dbItr, chaincodeID, endKey, "", nil, false}, nil
@@ -0,0 +1,298 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt/state"
+	"github.com/hyperledger/fabric/core/util"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// ChainProvider is implemented by coordinators which host chains besides the
+// default one, each with its own ledger and consensus engine
+type ChainProvider interface {
+	GetChainCoordinator(chainID string) (MessageHandlerCoordinator, error)
+}
+
+// ChainScoped is implemented by coordinators which are bound to a single chain
+type ChainScoped interface {
+	ChainID() string
+}
+
+// ChainRemoteLedgers is implemented by message handlers which can retrieve
+// the ledger of any chain from the remote peer
+type ChainRemoteLedgers interface {
+	GetChainRemoteLedger(chainID string) RemoteLedger
+}
+
+// chainRemoteLedger retrieves the ledger of one chain from the remote peer
+type chainRemoteLedger struct {
+	handler *Handler
+	chainID string
+}
+
+func (rl *chainRemoteLedger) RequestBlocks(syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncBlocks, error) {
+	return rl.handler.requestBlocks(rl.chainID, syncBlockRange)
+}
+
+func (rl *chainRemoteLedger) RequestStateSnapshot() (<-chan *pb.SyncStateSnapshot, error) {
+	return rl.handler.requestStateSnapshot(rl.chainID)
+}
+
+func (rl *chainRemoteLedger) RequestStateSnapshotChunk(chunk *pb.SyncStateSnapshotChunk) (<-chan *pb.SyncStateSnapshot, error) {
+	return rl.handler.requestStateSnapshotChunk(rl.chainID, chunk)
+}
+
+func (rl *chainRemoteLedger) RequestStateDeltas(syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncStateDeltas, error) {
+	return rl.handler.requestStateDeltas(rl.chainID, syncBlockRange)
+}
+
+// chain is the coordinator of a chain hosted by the peer besides the default
+// one. It shares the network connections of the peer, but has its own ledger
+// and consensus engine, and tags the messages it sends with its chain ID so
+// that the remote peers dispatch them to the engine of the same chain
+type chain struct {
+	*Impl
+	chainID       string
+	ledgerWrapper *ledgerWrapper
+	engine        Engine
+}
+
+// GetChains returns the IDs of the chains hosted by the peer besides the default one
+func GetChains() []string {
+	return viper.GetStringSlice("peer.chains")
+}
+
+// initChains opens the ledgers of the configured chains, and creates their
+// consensus engines when the peer is a validator. All the validators of a
+// network must host the same chains
+func (p *Impl) initChains(engFactory EngineFactory) error {
+	p.chains = make(map[string]*chain)
+	for _, chainID := range GetChains() {
+		if err := db.ValidateChainID(chainID); err != nil {
+			return err
+		}
+		if db.NormalizeChainID(chainID) == db.DefaultChainID {
+			continue
+		}
+		if _, ok := p.chains[chainID]; ok {
+			return fmt.Errorf("Chain %s is configured more than once", chainID)
+		}
+		ledgerPtr, err := ledger.GetChainLedger(chainID)
+		if err != nil {
+			return fmt.Errorf("Error opening the ledger of chain %s: %s", chainID, err)
+		}
+		c := &chain{Impl: p, chainID: chainID, ledgerWrapper: &ledgerWrapper{ledger: ledgerPtr}}
+		p.chains[chainID] = c
+		if engFactory != nil {
+			if c.engine, err = engFactory(c); err != nil {
+				return fmt.Errorf("Error creating the engine of chain %s: %s", chainID, err)
+			}
+		}
+		peerLogger.Infof("Hosting chain %s", chainID)
+	}
+	return nil
+}
+
+// GetChainCoordinator returns the coordinator of a chain hosted by the peer
+func (p *Impl) GetChainCoordinator(chainID string) (MessageHandlerCoordinator, error) {
+	if db.NormalizeChainID(chainID) == db.DefaultChainID {
+		return p, nil
+	}
+	c, ok := p.chains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("Chain %s is not hosted by this peer", chainID)
+	}
+	return c, nil
+}
+
+// ChainID returns the ID of the chain of the coordinator
+func (p *Impl) ChainID() string {
+	return db.DefaultChainID
+}
+
+// ChainID returns the ID of the chain of the coordinator
+func (c *chain) ChainID() string {
+	return c.chainID
+}
+
+// GetChainCoordinator returns the coordinator of a chain hosted by the peer
+func (c *chain) GetChainCoordinator(chainID string) (MessageHandlerCoordinator, error) {
+	return c.Impl.GetChainCoordinator(chainID)
+}
+
+// Broadcast broadcasts a message of the chain to each of the currently registered PeerEndpoints of given type
+func (c *chain) Broadcast(msg *pb.Message, typ pb.PeerEndpoint_Type) []error {
+	return c.Impl.Broadcast(c.tag(msg), typ)
+}
+
+// Unicast sends a message of the chain to a specific peer
+func (c *chain) Unicast(msg *pb.Message, receiverHandle *pb.PeerID) error {
+	return c.Impl.Unicast(c.tag(msg), receiverHandle)
+}
+
+// tag returns a copy of the message carrying the chain ID
+func (c *chain) tag(msg *pb.Message) *pb.Message {
+	tagged := *msg
+	tagged.ChainID = c.chainID
+	return &tagged
+}
+
+// GetRemoteLedger returns the RemoteLedger of the chain for the remote Peer Endpoint
+func (c *chain) GetRemoteLedger(receiverHandle *pb.PeerID) (RemoteLedger, error) {
+	msgHandler, err := c.getMessageHandler(receiverHandle)
+	if err != nil {
+		return nil, fmt.Errorf("Remote ledger not found for receiver %s", receiverHandle.Name)
+	}
+	remoteLedgers, ok := msgHandler.(ChainRemoteLedgers)
+	if !ok {
+		return nil, fmt.Errorf("Remote ledger of chain %s not available for receiver %s", c.chainID, receiverHandle.Name)
+	}
+	return remoteLedgers.GetChainRemoteLedger(c.chainID), nil
+}
+
+// ExecuteTransaction executes a transaction of the chain
+func (c *chain) ExecuteTransaction(transaction *pb.Transaction) *pb.Response {
+	if db.NormalizeChainID(transaction.ChainID) != c.chainID {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(fmt.Sprintf("Transaction %s is not for chain %s", transaction.Txid, c.chainID))}
+	}
+	return c.Impl.ExecuteTransaction(transaction)
+}
+
+// GetConsensusStatusOf returns a snapshot of the state of the consensus
+// plugin of a chain, reporters which do not host chains only report the
+// default chain
+func GetConsensusStatusOf(reporter ConsensusStatusReporter, chainID string) (*pb.ConsensusStatus, error) {
+	if chains, ok := reporter.(ChainConsensusStatusReporter); ok {
+		return chains.GetChainConsensusStatus(chainID)
+	}
+	if db.NormalizeChainID(chainID) != db.DefaultChainID {
+		return nil, fmt.Errorf("Chain %s is not hosted by this peer", chainID)
+	}
+	return reporter.GetConsensusStatus()
+}
+
+// GetConsensusStatus returns a snapshot of the state of the consensus plugin of the chain
+func (c *chain) GetConsensusStatus() (*pb.ConsensusStatus, error) {
+	reporter, ok := c.engine.(ConsensusStatusReporter)
+	if !ok {
+		return nil, fmt.Errorf("Consensus status is only available on validating peers")
+	}
+	return reporter.GetConsensusStatus()
+}
+
+// sendTransactionsToLocalEngine sends a transaction of the chain to its engine
+func (c *chain) sendTransactionsToLocalEngine(transaction *pb.Transaction) *pb.Response {
+	data, err := proto.Marshal(transaction)
+	if err != nil {
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(fmt.Sprintf("Error sending transaction to local engine: %s", err))}
+	}
+	msg := &pb.Message{Type: pb.Message_CHAIN_TRANSACTION, Payload: data, Timestamp: util.CreateUtcTimestamp(), ChainID: c.chainID}
+	return c.engine.ProcessTransactionMsg(msg, transaction)
+}
+
+// GetBlockByNumber return a block of the chain by block number
+func (c *chain) GetBlockByNumber(blockNumber uint64) (*pb.Block, error) {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.GetBlockByNumber(blockNumber)
+}
+
+// GetBlockchainSize returns the height/length of the blockchain of the chain
+func (c *chain) GetBlockchainSize() uint64 {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.GetBlockchainSize()
+}
+
+// GetCurrentStateHash returns the current non-committed hash of the in memory state of the chain
+func (c *chain) GetCurrentStateHash() (stateHash []byte, err error) {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.GetTempStateHash()
+}
+
+// VerifyBlockchain checks the integrity of the blockchain of the chain between indices start and finish
+func (c *chain) VerifyBlockchain(start, finish uint64) (uint64, error) {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.VerifyChain(start, finish)
+}
+
+// GetStatePartitioning returns how the state of the chain is split into partitions when it is transferred
+func (c *chain) GetStatePartitioning() (statemgmt.PartitionedState, error) {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.GetStatePartitioning()
+}
+
+// ApplyStateDelta applies a state delta to the current state of the chain
+func (c *chain) ApplyStateDelta(id interface{}, delta *statemgmt.StateDelta) error {
+	c.ledgerWrapper.Lock()
+	defer c.ledgerWrapper.Unlock()
+	return c.ledgerWrapper.ledger.ApplyStateDelta(id, delta)
+}
+
+// CommitStateDelta makes the result of ApplyStateDelta permanent
+func (c *chain) CommitStateDelta(id interface{}) error {
+	c.ledgerWrapper.Lock()
+	defer c.ledgerWrapper.Unlock()
+	return c.ledgerWrapper.ledger.CommitStateDelta(id)
+}
+
+// RollbackStateDelta undoes the results of ApplyStateDelta
+func (c *chain) RollbackStateDelta(id interface{}) error {
+	c.ledgerWrapper.Lock()
+	defer c.ledgerWrapper.Unlock()
+	return c.ledgerWrapper.ledger.RollbackStateDelta(id)
+}
+
+// EmptyState completely empties the state of the chain and prepares it to restore a snapshot
+func (c *chain) EmptyState() error {
+	c.ledgerWrapper.Lock()
+	defer c.ledgerWrapper.Unlock()
+	return c.ledgerWrapper.ledger.DeleteALLStateKeysAndValues()
+}
+
+// GetStateSnapshot return the state snapshot of the chain
+func (c *chain) GetStateSnapshot() (*state.StateSnapshot, error) {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.GetStateSnapshot()
+}
+
+// GetStateDelta return the state delta of the chain for the requested block number
+func (c *chain) GetStateDelta(blockNumber uint64) (*statemgmt.StateDelta, error) {
+	c.ledgerWrapper.RLock()
+	defer c.ledgerWrapper.RUnlock()
+	return c.ledgerWrapper.ledger.GetStateDelta(blockNumber)
+}
+
+// PutBlock inserts a raw block into the blockchain of the chain at the specified index
+func (c *chain) PutBlock(blockNumber uint64, block *pb.Block) error {
+	c.ledgerWrapper.Lock()
+	defer c.ledgerWrapper.Unlock()
+	return c.ledgerWrapper.ledger.PutRawBlock(block, blockNumber)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"testing"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+type mockMessageHandler struct {
+	MessageHandler
+	endpoint pb.PeerEndpoint
+	sent     []*pb.Message
+}
+
+func (h *mockMessageHandler) SendMessage(msg *pb.Message) error {
+	h.sent = append(h.sent, msg)
+	return nil
+}
+
+func (h *mockMessageHandler) To() (pb.PeerEndpoint, error) {
+	return h.endpoint, nil
+}
+
+func newTestChain(chainID string) (*chain, *mockMessageHandler) {
+	h := &mockMessageHandler{endpoint: pb.PeerEndpoint{ID: &pb.PeerID{Name: "vp1"}, Type: pb.PeerEndpoint_VALIDATOR}}
+	p := &Impl{handlerMap: &handlerMap{m: map[pb.PeerID]MessageHandler{*h.endpoint.ID: h}}}
+	c := &chain{Impl: p, chainID: chainID}
+	p.chains = map[string]*chain{chainID: c}
+	return c, h
+}
+
+func TestChainTagsMessages(t *testing.T) {
+	c, h := newTestChain("chain1")
+
+	msg := &pb.Message{Type: pb.Message_CONSENSUS}
+	if errs := c.Broadcast(msg, pb.PeerEndpoint_VALIDATOR); len(errs) != 0 {
+		t.Fatalf("Unexpected broadcast errors: %v", errs)
+	}
+	if err := c.Unicast(msg, &pb.PeerID{Name: "vp1"}); err != nil {
+		t.Fatalf("Unexpected unicast error: %s", err)
+	}
+	if len(h.sent) != 2 {
+		t.Fatalf("Expected 2 messages to be sent, got %d", len(h.sent))
+	}
+	for _, sent := range h.sent {
+		if sent.ChainID != "chain1" {
+			t.Errorf("Expected the message to carry the chain ID, got %q", sent.ChainID)
+		}
+	}
+	if msg.ChainID != "" {
+		t.Errorf("Expected the message of the caller not to be modified")
+	}
+
+	if errs := c.Impl.Broadcast(msg, pb.PeerEndpoint_VALIDATOR); len(errs) != 0 || h.sent[2].ChainID != "" {
+		t.Errorf("Expected the messages of the default chain not to carry a chain ID")
+	}
+}
+
+func TestChainCoordinators(t *testing.T) {
+	c, _ := newTestChain("chain1")
+	p := c.Impl
+
+	if coord, err := p.GetChainCoordinator(""); err != nil || coord != p {
+		t.Errorf("Expected the peer to coordinate the default chain, got %v, %v", coord, err)
+	}
+	if coord, err := p.GetChainCoordinator("chain1"); err != nil || coord != c {
+		t.Errorf("Expected the chain to coordinate itself, got %v, %v", coord, err)
+	}
+	if _, err := p.GetChainCoordinator("chain2"); err == nil {
+		t.Errorf("Expected an error for a chain which is not hosted")
+	}
+	if p.ChainID() != "default" || c.ChainID() != "chain1" {
+		t.Errorf("Unexpected chain IDs %q and %q", p.ChainID(), c.ChainID())
+	}
+}
+
+func TestExecuteTransactionOfUnknownChain(t *testing.T) {
+	c, _ := newTestChain("chain1")
+
+	resp := c.Impl.ExecuteTransaction(&pb.Transaction{Txid: "tx1", ChainID: "chain2"})
+	if resp.Status != pb.Response_FAILURE {
+		t.Errorf("Expected the transaction of a chain which is not hosted to fail")
+	}
+	resp = c.ExecuteTransaction(&pb.Transaction{Txid: "tx2"})
+	if resp.Status != pb.Response_FAILURE {
+		t.Errorf("Expected the transaction of another chain to be rejected by the chain")
+	}
+}
+
+// mockEngine reports the consensus status of a chain and records the
+// validator reconfigurations ordered on it
+type mockEngine struct {
+	Engine
+	plugin   string
+	reconfig []*pb.ValidatorReconfiguration
+}
+
+func (e *mockEngine) GetConsensusStatus() (*pb.ConsensusStatus, error) {
+	return &pb.ConsensusStatus{Plugin: e.plugin}, nil
+}
+
+func (e *mockEngine) ReconfigureValidators(req *pb.ValidatorReconfiguration) error {
+	e.reconfig = append(e.reconfig, req)
+	return nil
+}
+
+func TestChainConsensus(t *testing.T) {
+	c, _ := newTestChain("chain1")
+	p := c.Impl
+	defaultEngine, chainEngine := &mockEngine{plugin: "default"}, &mockEngine{plugin: "chain1"}
+	p.engine, c.engine = defaultEngine, chainEngine
+
+	if status, err := p.GetChainConsensusStatus(""); err != nil || status.Plugin != "default" {
+		t.Errorf("Expected the status of the default chain, got %v, %v", status, err)
+	}
+	if status, err := GetConsensusStatusOf(p, "chain1"); err != nil || status.Plugin != "chain1" {
+		t.Errorf("Expected the status of the chain, got %v, %v", status, err)
+	}
+	if _, err := p.GetChainConsensusStatus("chain2"); err == nil {
+		t.Errorf("Expected an error for a chain which is not hosted")
+	}
+	if _, err := GetConsensusStatusOf(defaultEngine, "chain1"); err == nil {
+		t.Errorf("Expected a reporter which does not host chains to only report the default chain")
+	}
+
+	if err := p.ReconfigureValidators(&pb.ValidatorReconfiguration{ChainID: "chain1"}); err != nil || len(chainEngine.reconfig) != 1 || len(defaultEngine.reconfig) != 0 {
+		t.Errorf("Expected the validators of the chain to change, got %v", err)
+	}
+	if err := p.ReconfigureValidators(&pb.ValidatorReconfiguration{}); err != nil || len(defaultEngine.reconfig) != 1 {
+		t.Errorf("Expected the validators of the default chain to change, got %v", err)
+	}
+	if err := p.ReconfigureValidators(&pb.ValidatorReconfiguration{ChainID: "chain2"}); err == nil {
+		t.Errorf("Expected an error for a chain which is not hosted")
+	}
+}
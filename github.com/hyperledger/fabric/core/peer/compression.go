@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/spf13/viper"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// maxDecompressedSize bounds the payload a compressed message may expand to,
+// so that a peer cannot exhaust the memory of another with a small message.
+// The largest messages the peers exchange are the blocks of state transfer,
+// state snapshots are sent a key at a time. Larger payloads are sent
+// uncompressed.
+const maxDecompressedSize = 16 * 1024 * 1024
+
+// DefaultCompressionMinSize is the size of the smallest payload compressed
+const DefaultCompressionMinSize = 1024
+
+// supportedCompressions returns the compressions this peer announces in its
+// HelloMessage, in order of preference
+func supportedCompressions() []pb.Compression {
+	if !viper.GetBool("peer.compression.enabled") {
+		return nil
+	}
+	return []pb.Compression{pb.Compression_GZIP}
+}
+
+// compressionMinSize returns the size of the smallest payload compressed
+func compressionMinSize() int {
+	if size := viper.GetInt("peer.compression.minSize"); size > 0 {
+		return size
+	}
+	return DefaultCompressionMinSize
+}
+
+// negotiateCompression returns the compression of the messages to a peer,
+// the first of the local compressions the peer announced it accepts
+func negotiateCompression(local, remote []pb.Compression) pb.Compression {
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r {
+				return l
+			}
+		}
+	}
+	return pb.Compression_NONE
+}
+
+// compressMessage returns a copy of the message with its payload compressed,
+// or the message itself if its payload is smaller than minSize, larger than
+// the peers decompress, or compressing it does not make it smaller
+func compressMessage(msg *pb.Message, compression pb.Compression, minSize int) (*pb.Message, error) {
+	if compression == pb.Compression_NONE || msg.Compression != pb.Compression_NONE || len(msg.Payload) < minSize || len(msg.Payload) > maxDecompressedSize {
+		return msg, nil
+	}
+	var buf bytes.Buffer
+	switch compression {
+	case pb.Compression_GZIP:
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(msg.Payload); err != nil {
+			return nil, fmt.Errorf("Error compressing %s message: %s", msg.Type, err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("Error compressing %s message: %s", msg.Type, err)
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported compression %s", compression)
+	}
+	if buf.Len() >= len(msg.Payload) {
+		return msg, nil
+	}
+	compressed := *msg
+	compressed.Payload = buf.Bytes()
+	compressed.Compression = compression
+	return &compressed, nil
+}
+
+// decompressMessage returns the message with its payload decompressed
+func decompressMessage(msg *pb.Message) (*pb.Message, error) {
+	var r io.Reader
+	switch msg.Compression {
+	case pb.Compression_NONE:
+		return msg, nil
+	case pb.Compression_GZIP:
+		gr, err := gzip.NewReader(bytes.NewReader(msg.Payload))
+		if err != nil {
+			return nil, fmt.Errorf("Error decompressing %s message: %s", msg.Type, err)
+		}
+		defer gr.Close()
+		r = gr
+	default:
+		return nil, fmt.Errorf("Unsupported compression %s of %s message", msg.Compression, msg.Type)
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("Error decompressing %s message: %s", msg.Type, err)
+	}
+	if len(payload) > maxDecompressedSize {
+		return nil, fmt.Errorf("Decompressed %s message exceeds %d bytes", msg.Type, maxDecompressedSize)
+	}
+	msg.Payload = payload
+	msg.Compression = pb.Compression_NONE
+	return msg, nil
+}
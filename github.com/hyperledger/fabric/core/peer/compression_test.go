@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	gzip := []pb.Compression{pb.Compression_GZIP}
+	if c := negotiateCompression(gzip, gzip); c != pb.Compression_GZIP {
+		t.Errorf("Expected GZIP to be negotiated, got %s", c)
+	}
+	if c := negotiateCompression(gzip, nil); c != pb.Compression_NONE {
+		t.Errorf("Expected no compression with a peer which does not accept any, got %s", c)
+	}
+	if c := negotiateCompression(nil, gzip); c != pb.Compression_NONE {
+		t.Errorf("Expected no compression when compression is disabled, got %s", c)
+	}
+}
+
+func TestCompressMessage(t *testing.T) {
+	payload := bytes.Repeat([]byte("block"), 1000)
+	msg := &pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: payload, Signature: []byte("signature")}
+
+	compressed, err := compressMessage(msg, pb.Compression_GZIP, 1024)
+	if err != nil {
+		t.Fatalf("Error compressing message: %s", err)
+	}
+	if compressed.Compression != pb.Compression_GZIP || len(compressed.Payload) >= len(payload) {
+		t.Fatalf("Expected the payload to be compressed, got %d bytes", len(compressed.Payload))
+	}
+	if msg.Compression != pb.Compression_NONE || !bytes.Equal(msg.Payload, payload) {
+		t.Fatalf("Expected the message of the caller not to be modified")
+	}
+
+	decompressed, err := decompressMessage(compressed)
+	if err != nil {
+		t.Fatalf("Error decompressing message: %s", err)
+	}
+	if decompressed.Compression != pb.Compression_NONE || !bytes.Equal(decompressed.Payload, payload) || !bytes.Equal(decompressed.Signature, msg.Signature) {
+		t.Errorf("Expected the decompressed message to be the original one")
+	}
+}
+
+func TestCompressMessageSkipsSmallPayloads(t *testing.T) {
+	msg := &pb.Message{Type: pb.Message_CONSENSUS, Payload: bytes.Repeat([]byte("a"), 100)}
+	if compressed, _ := compressMessage(msg, pb.Compression_GZIP, 1024); compressed != msg {
+		t.Errorf("Expected a payload smaller than the minimum size not to be compressed")
+	}
+
+	// The peers do not decompress payloads this large
+	msg = &pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: make([]byte, maxDecompressedSize+1)}
+	if compressed, _ := compressMessage(msg, pb.Compression_GZIP, 1024); compressed != msg {
+		t.Errorf("Expected a payload larger than the maximum decompressed size not to be compressed")
+	}
+
+	// Random looking data does not get smaller
+	msg = &pb.Message{Type: pb.Message_CONSENSUS, Payload: []byte("x7#kQ9!zL2@mP4$")}
+	if compressed, _ := compressMessage(msg, pb.Compression_GZIP, 0); compressed != msg {
+		t.Errorf("Expected a payload which does not get smaller not to be compressed")
+	}
+}
+
+func TestDecompressInvalidMessage(t *testing.T) {
+	msg := &pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: []byte("not gzip"), Compression: pb.Compression_GZIP}
+	if _, err := decompressMessage(msg); err == nil {
+		t.Errorf("Expected an error decompressing an invalid payload")
+	}
+	msg = &pb.Message{Type: pb.Message_SYNC_BLOCKS, Compression: pb.Compression(42)}
+	if _, err := decompressMessage(msg); err == nil {
+		t.Errorf("Expected an error decompressing with an unsupported compression")
+	}
+}
+
+func TestDecompressBomb(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(make([]byte, maxDecompressedSize+1))
+	w.Close()
+
+	msg := &pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: buf.Bytes(), Compression: pb.Compression_GZIP}
+	if _, err := decompressMessage(msg); err == nil {
+		t.Errorf("Expected an error decompressing a payload larger than %d bytes", maxDecompressedSize)
+	}
+}
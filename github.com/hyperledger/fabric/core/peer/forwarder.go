@@ -0,0 +1,545 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/core/db"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// validatorHealth is the record of the failures of a validator the
+// transactions are forwarded to
+type validatorHealth struct {
+	failures     uint
+	backoffUntil time.Time
+}
+
+// submission is a transaction submitted to the peer
+type submission struct {
+	tx        *pb.Transaction
+	state     *pb.TransactionSubmission
+	submitted time.Time
+	finished  time.Time
+}
+
+// txForwarder forwards the transactions submitted to a non-validating peer
+// to the validators. It selects the healthiest validator, fails over to
+// another one with backoff when a validator is down or loses the
+// transaction, and tracks the submissions by transaction ID, so that a
+// transaction submitted several times is forwarded once. On a validator it
+// only tracks the submissions, which are passed to the local engine.
+type txForwarder struct {
+	sync.Mutex
+	attempts     int
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	pollInterval time.Duration
+	timeout      time.Duration
+	retention    time.Duration
+	health       map[string]*validatorHealth
+	submissions  map[string]*submission
+	lastPrune    time.Time
+
+	now        func() time.Time
+	sleep      func(time.Duration)
+	validators func() []string
+	send       func(address string, tx *pb.Transaction) (*pb.Response, error)
+	status     func(address string, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error)
+}
+
+func newTxForwarder(attempts int, backoff, maxBackoff, pollInterval, timeout, retention time.Duration) *txForwarder {
+	return &txForwarder{
+		attempts:     attempts,
+		backoff:      backoff,
+		maxBackoff:   maxBackoff,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+		retention:    retention,
+		health:       make(map[string]*validatorHealth),
+		submissions:  make(map[string]*submission),
+		now:          time.Now,
+		sleep:        time.Sleep,
+	}
+}
+
+// isFinished returns whether a submission in the state can no longer change
+func isFinished(state pb.TransactionSubmission_State) bool {
+	return state == pb.TransactionSubmission_COMMITTED || state == pb.TransactionSubmission_FAILED || state == pb.TransactionSubmission_TIMED_OUT
+}
+
+func newTimestamp(t time.Time) *timestamp.Timestamp {
+	return &timestamp.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}
+
+// backoffFor returns the delay after the given number of consecutive failures
+func (f *txForwarder) backoffFor(failures uint) time.Duration {
+	d := f.backoff
+	for i := uint(1); i < failures && d < f.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > f.maxBackoff {
+		d = f.maxBackoff
+	}
+	return d
+}
+
+// selectValidator returns the healthiest validator, preferring the ones
+// which are not excluded. Validators which failed recently are only
+// selected when all the others did too.
+func (f *txForwarder) selectValidator(exclude map[string]bool) string {
+	candidates := f.validators()
+
+	f.Lock()
+	defer f.Unlock()
+	now := f.now()
+	var best string
+	var bestExcluded, bestBackingOff bool
+	var bestHealth validatorHealth
+	// Visit the candidates in random order to spread the load among the equally healthy ones
+	for _, i := range rand.Perm(len(candidates)) {
+		address := candidates[i]
+		var health validatorHealth
+		if h, ok := f.health[address]; ok {
+			health = *h
+		}
+		excluded := exclude[address]
+		backingOff := now.Before(health.backoffUntil)
+		better := best == ""
+		if !better && excluded != bestExcluded {
+			better = !excluded
+		} else if !better && backingOff != bestBackingOff {
+			better = !backingOff
+		} else if !better && backingOff {
+			better = health.backoffUntil.Before(bestHealth.backoffUntil)
+		} else if !better {
+			better = health.failures < bestHealth.failures
+		}
+		if better {
+			best, bestExcluded, bestBackingOff, bestHealth = address, excluded, backingOff, health
+		}
+	}
+	return best
+}
+
+// failed records a failure of a validator, which is then avoided for a
+// backoff growing with its consecutive failures
+func (f *txForwarder) failed(address string) {
+	f.Lock()
+	defer f.Unlock()
+	h, ok := f.health[address]
+	if !ok {
+		h = &validatorHealth{}
+		f.health[address] = h
+	}
+	h.failures++
+	h.backoffUntil = f.now().Add(f.backoffFor(h.failures))
+}
+
+// succeeded records that a validator is healthy again
+func (f *txForwarder) succeeded(address string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.health, address)
+}
+
+// deliver forwards the transaction to the validators in turn until one
+// accepts it, and returns the validator which accepted it, the number of
+// validators tried and the response
+func (f *txForwarder) deliver(tx *pb.Transaction, exclude map[string]bool) (string, uint32, *pb.Response) {
+	var attempts uint32
+	lastErr := "No validator known to send the transaction to"
+	for i := 0; i < f.attempts; i++ {
+		if i > 0 {
+			f.sleep(f.backoffFor(uint(i)))
+		}
+		address := f.selectValidator(exclude)
+		if address == "" {
+			continue
+		}
+		exclude[address] = true
+		attempts++
+		resp, err := f.send(address, tx)
+		if err == nil && resp.Status == pb.Response_SUCCESS {
+			f.succeeded(address)
+			return address, attempts, resp
+		}
+		if err != nil {
+			// The validator could not be reached
+			lastErr = err.Error()
+			f.failed(address)
+		} else {
+			lastErr = string(resp.Msg)
+		}
+		peerLogger.Warningf("Forwarding transaction %s to validator %s failed: %s", tx.Txid, address, lastErr)
+	}
+	return "", attempts, &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(fmt.Sprintf("Error forwarding transaction %s: %s", tx.Txid, lastErr))}
+}
+
+// update changes the state of a submission
+func (f *txForwarder) update(s *submission, change func(state *pb.TransactionSubmission)) {
+	f.Lock()
+	defer f.Unlock()
+	change(s.state)
+	now := f.now()
+	s.state.Updated = newTimestamp(now)
+	if isFinished(s.state.State) && s.finished.IsZero() {
+		s.finished = now
+	}
+}
+
+// prune drops the submissions which finished, or were submitted, long
+// enough ago. It is called with the lock held
+func (f *txForwarder) prune() {
+	now := f.now()
+	if now.Sub(f.lastPrune) < f.retention/10 {
+		return
+	}
+	f.lastPrune = now
+	for txID, s := range f.submissions {
+		if (!s.finished.IsZero() && now.Sub(s.finished) > f.retention) || now.Sub(s.submitted) > f.timeout+f.retention {
+			delete(f.submissions, txID)
+		}
+	}
+}
+
+// track adds a submission for the transaction, unless one is in progress or
+// committed, and returns whether it was added
+func (f *txForwarder) track(tx *pb.Transaction, state pb.TransactionSubmission_State) (*submission, bool) {
+	f.Lock()
+	defer f.Unlock()
+	f.prune()
+	if s, ok := f.submissions[tx.Txid]; ok && s.state.State != pb.TransactionSubmission_FAILED && s.state.State != pb.TransactionSubmission_TIMED_OUT {
+		return s, false
+	}
+	now := f.now()
+	s := &submission{
+		tx:        tx,
+		submitted: now,
+		state: &pb.TransactionSubmission{
+			Txid:      tx.Txid,
+			ChainID:   tx.ChainID,
+			State:     state,
+			Submitted: newTimestamp(now),
+			Updated:   newTimestamp(now),
+		},
+	}
+	f.submissions[tx.Txid] = s
+	return s, true
+}
+
+// forget drops the submission of a transaction
+func (f *txForwarder) forget(txID string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.submissions, txID)
+}
+
+// validator returns the validator a submission was forwarded to. The
+// submission is watched even once it is no longer tracked, e.g. pruned.
+func (f *txForwarder) validator(s *submission) string {
+	f.Lock()
+	defer f.Unlock()
+	return s.state.Validator
+}
+
+// get returns a copy of the state of the submission of a transaction, or
+// nil if it is not tracked
+func (f *txForwarder) get(txID string) *pb.TransactionSubmission {
+	f.Lock()
+	defer f.Unlock()
+	s, ok := f.submissions[txID]
+	if !ok {
+		return nil
+	}
+	state := *s.state
+	return &state
+}
+
+// submit forwards a transaction to the validators. Queries are answered
+// synchronously, other transactions are tracked until they commit.
+func (f *txForwarder) submit(tx *pb.Transaction) *pb.Response {
+	if tx.Type == pb.Transaction_CHAINCODE_QUERY {
+		_, _, resp := f.deliver(tx, make(map[string]bool))
+		return resp
+	}
+	s, exclude, resp := f.forward(tx)
+	if s != nil {
+		go f.watch(s, exclude)
+	}
+	return resp
+}
+
+// forward tracks a transaction and forwards it to a validator, unless it was
+// already submitted. It returns the submission to watch if it was forwarded,
+// and the validators it was forwarded to.
+func (f *txForwarder) forward(tx *pb.Transaction) (*submission, map[string]bool, *pb.Response) {
+	s, added := f.track(tx, pb.TransactionSubmission_PENDING)
+	if !added {
+		peerLogger.Debugf("Transaction %s was already submitted", tx.Txid)
+		return nil, nil, &pb.Response{Status: pb.Response_SUCCESS, Msg: []byte(tx.Txid)}
+	}
+
+	exclude := make(map[string]bool)
+	address, attempts, resp := f.deliver(tx, exclude)
+	if address == "" {
+		f.update(s, func(state *pb.TransactionSubmission) {
+			state.State = pb.TransactionSubmission_FAILED
+			state.Attempts += attempts
+			state.Error = string(resp.Msg)
+		})
+		return nil, nil, resp
+	}
+	f.update(s, func(state *pb.TransactionSubmission) {
+		state.State = pb.TransactionSubmission_FORWARDED
+		state.Validator = address
+		state.Attempts += attempts
+	})
+	return s, exclude, resp
+}
+
+// watch polls the validator a transaction was forwarded to until the
+// transaction commits. The transaction is forwarded to another validator
+// only when the validator reports it does not know the transaction, e.g.
+// because it restarted: a validator which is slow or cannot be reached may
+// still commit it, and forwarding it again would execute it twice.
+func (f *txForwarder) watch(s *submission, exclude map[string]bool) {
+	req := &pb.TransactionSubmissionRequest{Txid: s.tx.Txid, ChainID: s.tx.ChainID}
+	for {
+		f.sleep(f.pollInterval)
+		address := f.validator(s)
+
+		status, err := f.status(address, req)
+		if err == nil && status.State == pb.TransactionSubmission_COMMITTED {
+			f.succeeded(address)
+			f.update(s, func(state *pb.TransactionSubmission) {
+				state.State = pb.TransactionSubmission_COMMITTED
+				state.BlockNumber = status.BlockNumber
+			})
+			peerLogger.Debugf("Transaction %s committed in block %d", s.tx.Txid, status.BlockNumber)
+			return
+		}
+
+		if f.now().Sub(s.submitted) >= f.timeout {
+			f.update(s, func(state *pb.TransactionSubmission) {
+				state.State = pb.TransactionSubmission_TIMED_OUT
+				state.Error = fmt.Sprintf("Transaction did not commit within %v", f.timeout)
+			})
+			peerLogger.Warningf("Transaction %s did not commit within %v", s.tx.Txid, f.timeout)
+			return
+		}
+		if err != nil {
+			peerLogger.Debugf("Validator %s could not report the state of transaction %s: %s", address, s.tx.Txid, err)
+			continue
+		}
+		if status.State != pb.TransactionSubmission_UNKNOWN {
+			continue
+		}
+
+		peerLogger.Warningf("Validator %s does not know transaction %s, failing over", address, s.tx.Txid)
+		f.failed(address)
+		address, attempts, resp := f.deliver(s.tx, exclude)
+		if address == "" {
+			f.update(s, func(state *pb.TransactionSubmission) {
+				state.State = pb.TransactionSubmission_FAILED
+				state.Attempts += attempts
+				state.Error = string(resp.Msg)
+			})
+			return
+		}
+		f.update(s, func(state *pb.TransactionSubmission) {
+			state.Validator = address
+			state.Attempts += attempts
+		})
+	}
+}
+
+// initForwarder configures the forwarding of the transactions submitted to the peer
+func (p *Impl) initForwarder() {
+	attempts := viper.GetInt("peer.forwarding.attempts")
+	backoff := viper.GetDuration("peer.forwarding.backoff")
+	maxBackoff := viper.GetDuration("peer.forwarding.maxBackoff")
+	pollInterval := viper.GetDuration("peer.forwarding.pollInterval")
+	requestTimeout := viper.GetDuration("peer.forwarding.requestTimeout")
+	timeout := viper.GetDuration("peer.forwarding.timeout")
+	retention := viper.GetDuration("peer.forwarding.retention")
+	if attempts <= 0 || backoff <= 0 || maxBackoff < backoff || pollInterval <= 0 || requestTimeout <= 0 || timeout <= pollInterval || retention <= 0 {
+		panic(fmt.Errorf("Invalid peer.forwarding configuration: attempts %d, backoff %v, maxBackoff %v, pollInterval %v, requestTimeout %v, timeout %v, retention %v",
+			attempts, backoff, maxBackoff, pollInterval, requestTimeout, timeout, retention))
+	}
+	p.forwarder = newTxForwarder(attempts, backoff, maxBackoff, pollInterval, timeout, retention)
+	p.forwarder.validators = p.validatorAddresses
+	p.forwarder.send = func(address string, tx *pb.Transaction) (*pb.Response, error) {
+		return p.sendTransactionToPeer(address, tx, requestTimeout)
+	}
+	p.forwarder.status = func(address string, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error) {
+		return p.getRemoteTransactionSubmission(address, req, requestTimeout)
+	}
+}
+
+// validatorAddresses returns the addresses of the validators the
+// transactions may be forwarded to: the connected validators, or all the
+// known peers if none is connected
+func (p *Impl) validatorAddresses() []string {
+	var addresses []string
+	for _, msgHandler := range p.cloneHandlerMap(pb.PeerEndpoint_VALIDATOR) {
+		if to, err := msgHandler.To(); err == nil && !p.isBanned(to.Address) {
+			addresses = append(addresses, to.Address)
+		}
+	}
+	if len(addresses) > 0 {
+		return addresses
+	}
+	for _, address := range p.discHelper.GetAllNodes() {
+		if !p.isBanned(address) {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// sendTransactionToPeer sends a transaction to the peer at the address, an
+// error is returned if the peer could not be reached
+func (p *Impl) sendTransactionToPeer(address string, transaction *pb.Transaction, timeout time.Duration) (*pb.Response, error) {
+	conn, err := NewPeerClientConnectionWithAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating client to peer address=%s:  %s", address, err)
+	}
+	defer conn.Close()
+	serverClient := pb.NewPeerClient(conn)
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	peerLogger.Debugf("Sending TX to Peer: %s", address)
+	response, err := serverClient.ProcessTransaction(ctx, transaction)
+	if err != nil {
+		return nil, fmt.Errorf("Error calling ProcessTransaction on remote peer at address=%s:  %s", address, err)
+	}
+	return response, nil
+}
+
+// getRemoteTransactionSubmission asks the peer at the address for the state of a transaction
+func (p *Impl) getRemoteTransactionSubmission(address string, req *pb.TransactionSubmissionRequest, timeout time.Duration) (*pb.TransactionSubmission, error) {
+	conn, err := NewPeerClientConnectionWithAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating client to peer address=%s:  %s", address, err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return pb.NewPeerClient(conn).GetTransactionSubmission(ctx, req)
+}
+
+// acceptTransaction records a transaction a validator passes to its engine,
+// and returns false if it was already accepted or committed
+func (p *Impl) acceptTransaction(transaction *pb.Transaction) bool {
+	if _, committed := p.committedBlock(transaction.ChainID, transaction.Txid); committed {
+		return false
+	}
+	s, added := p.forwarder.track(transaction, pb.TransactionSubmission_FORWARDED)
+	if added {
+		p.forwarder.update(s, func(state *pb.TransactionSubmission) {
+			state.Attempts = 1
+			if ep, err := GetPeerEndpoint(); err == nil {
+				state.Validator = ep.Address
+			}
+		})
+	}
+	return added
+}
+
+// committedBlock returns the block in which a transaction committed on the
+// ledger of its chain
+func (p *Impl) committedBlock(chainID, txID string) (uint64, bool) {
+	lw := p.ledgerWrapper
+	if db.NormalizeChainID(chainID) != db.DefaultChainID {
+		c, ok := p.chains[chainID]
+		if !ok {
+			return 0, false
+		}
+		lw = c.ledgerWrapper
+	}
+	lw.RLock()
+	defer lw.RUnlock()
+	blockNumber, err := lw.ledger.GetTransactionBlockNumber(txID)
+	return blockNumber, err == nil
+}
+
+// transactionSubmission returns the state of a transaction submitted to the
+// peer, a validator also reports the transactions committed on its ledger
+func (p *Impl) transactionSubmission(req *pb.TransactionSubmissionRequest) *pb.TransactionSubmission {
+	state := p.forwarder.get(req.Txid)
+	if state != nil && isFinished(state.State) {
+		return state
+	}
+	if p.isValidator {
+		if blockNumber, committed := p.committedBlock(req.ChainID, req.Txid); committed {
+			if state == nil {
+				return &pb.TransactionSubmission{Txid: req.Txid, ChainID: req.ChainID, State: pb.TransactionSubmission_COMMITTED, BlockNumber: blockNumber}
+			}
+			state.State = pb.TransactionSubmission_COMMITTED
+			state.BlockNumber = blockNumber
+			return state
+		}
+	}
+	if state == nil {
+		return &pb.TransactionSubmission{Txid: req.Txid, ChainID: req.ChainID, State: pb.TransactionSubmission_UNKNOWN}
+	}
+	return state
+}
+
+// GetTransactionSubmission returns the state of a transaction submitted to the peer
+func (p *Impl) GetTransactionSubmission(ctx context.Context, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error) {
+	return p.transactionSubmission(req), nil
+}
+
+// WatchTransactionSubmission streams the state of a transaction submitted to
+// the peer each time it changes, until the transaction commits or the
+// submission fails
+func (p *Impl) WatchTransactionSubmission(req *pb.TransactionSubmissionRequest, stream pb.Peer_WatchTransactionSubmissionServer) error {
+	ticker := time.NewTicker(p.forwarder.pollInterval)
+	defer ticker.Stop()
+	var last *pb.TransactionSubmission
+	for {
+		state := p.transactionSubmission(req)
+		if last == nil || state.State != last.State || state.Validator != last.Validator || state.Attempts != last.Attempts {
+			if err := stream.Send(state); err != nil {
+				return err
+			}
+			last = state
+		}
+		if state.State == pb.TransactionSubmission_UNKNOWN || isFinished(state.State) {
+			return nil
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
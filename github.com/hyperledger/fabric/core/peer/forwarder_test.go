@@ -0,0 +1,201 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// testValidators are the validators a test forwarder forwards to
+type testValidators struct {
+	sync.Mutex
+	now       time.Time
+	down      map[string]bool
+	lost      map[string]bool
+	committed map[string]bool
+	sent      []string
+}
+
+func newTestForwarder(v *testValidators, addresses ...string) *txForwarder {
+	v.now = time.Now()
+	f := newTxForwarder(3, time.Second, 4*time.Second, time.Second, time.Minute, time.Hour)
+	f.now = func() time.Time {
+		v.Lock()
+		defer v.Unlock()
+		return v.now
+	}
+	f.sleep = func(d time.Duration) {
+		v.Lock()
+		defer v.Unlock()
+		v.now = v.now.Add(d)
+	}
+	f.validators = func() []string { return addresses }
+	f.send = func(address string, tx *pb.Transaction) (*pb.Response, error) {
+		v.Lock()
+		defer v.Unlock()
+		v.sent = append(v.sent, address)
+		if v.down[address] {
+			return nil, fmt.Errorf("%s is down", address)
+		}
+		return &pb.Response{Status: pb.Response_SUCCESS, Msg: []byte(tx.Txid)}, nil
+	}
+	f.status = func(address string, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error) {
+		v.Lock()
+		defer v.Unlock()
+		if v.down[address] {
+			return nil, fmt.Errorf("%s is down", address)
+		}
+		if v.committed[address] {
+			return &pb.TransactionSubmission{Txid: req.Txid, State: pb.TransactionSubmission_COMMITTED, BlockNumber: 7}, nil
+		}
+		if v.lost[address] {
+			return &pb.TransactionSubmission{Txid: req.Txid, State: pb.TransactionSubmission_UNKNOWN}, nil
+		}
+		return &pb.TransactionSubmission{Txid: req.Txid, State: pb.TransactionSubmission_FORWARDED}, nil
+	}
+	return f
+}
+
+func TestForwarderFailsOver(t *testing.T) {
+	v := &testValidators{down: map[string]bool{"vp1:7051": true}}
+	f := newTestForwarder(v, "vp1:7051", "vp2:7051")
+
+	// Make the validator which is down the healthiest
+	f.failed("vp2:7051")
+	v.now = v.now.Add(time.Hour)
+
+	tx := &pb.Transaction{Txid: "tx1", Type: pb.Transaction_CHAINCODE_INVOKE}
+	s, exclude, resp := f.forward(tx)
+	if s == nil || resp.Status != pb.Response_SUCCESS {
+		t.Fatalf("Expected the transaction to be forwarded, got %s", resp.Msg)
+	}
+	if len(v.sent) != 2 || v.sent[0] != "vp1:7051" || !exclude["vp1:7051"] || !exclude["vp2:7051"] {
+		t.Fatalf("Expected the transaction to be forwarded to both validators in turn, got %v", v.sent)
+	}
+
+	state := f.get("tx1")
+	if state.State != pb.TransactionSubmission_FORWARDED || state.Validator != "vp2:7051" || state.Attempts != 2 {
+		t.Fatalf("Expected the transaction to be forwarded to the second validator, got %v", state)
+	}
+	if address := f.selectValidator(map[string]bool{}); address != "vp2:7051" {
+		t.Errorf("Expected the validator which is down to be avoided, got %s", address)
+	}
+}
+
+func TestForwarderDeduplicates(t *testing.T) {
+	v := &testValidators{}
+	f := newTestForwarder(v, "vp1:7051")
+
+	tx := &pb.Transaction{Txid: "tx1", Type: pb.Transaction_CHAINCODE_INVOKE}
+	f.forward(tx)
+	s, _, resp := f.forward(tx)
+	if s != nil || resp.Status != pb.Response_SUCCESS || string(resp.Msg) != "tx1" {
+		t.Fatalf("Expected the duplicate submission to succeed, got %v", resp)
+	}
+	if len(v.sent) != 1 {
+		t.Errorf("Expected the transaction to be forwarded once, forwarded %d times", len(v.sent))
+	}
+}
+
+func TestForwarderFailsWithoutValidators(t *testing.T) {
+	v := &testValidators{down: map[string]bool{"vp1:7051": true}}
+	f := newTestForwarder(v, "vp1:7051")
+
+	tx := &pb.Transaction{Txid: "tx1", Type: pb.Transaction_CHAINCODE_INVOKE}
+	if _, _, resp := f.forward(tx); resp.Status != pb.Response_FAILURE {
+		t.Fatalf("Expected the submission to fail")
+	}
+	if state := f.get("tx1"); state.State != pb.TransactionSubmission_FAILED || state.Attempts != 3 {
+		t.Fatalf("Expected the submission to fail after 3 attempts, got %v", state)
+	}
+
+	// A failed submission may be submitted again
+	v.down = nil
+	if _, _, resp := f.forward(tx); resp.Status != pb.Response_SUCCESS {
+		t.Fatalf("Expected the transaction to be submitted again, got %s", resp.Msg)
+	}
+}
+
+func TestForwarderReforwardsLostTransaction(t *testing.T) {
+	v := &testValidators{lost: map[string]bool{"vp1:7051": true}, committed: map[string]bool{"vp2:7051": true}}
+	f := newTestForwarder(v, "vp1:7051", "vp2:7051")
+
+	tx := &pb.Transaction{Txid: "tx1", Type: pb.Transaction_CHAINCODE_INVOKE}
+	s, _ := f.track(tx, pb.TransactionSubmission_FORWARDED)
+	f.update(s, func(state *pb.TransactionSubmission) { state.Validator = "vp1:7051" })
+	f.watch(s, map[string]bool{"vp1:7051": true})
+
+	state := f.get("tx1")
+	if state.State != pb.TransactionSubmission_COMMITTED || state.Validator != "vp2:7051" || state.BlockNumber != 7 {
+		t.Fatalf("Expected the transaction to commit on the second validator, got %v", state)
+	}
+	if len(v.sent) != 1 || v.sent[0] != "vp2:7051" {
+		t.Errorf("Expected the transaction to be forwarded to the second validator, got %v", v.sent)
+	}
+}
+
+func TestForwarderWatchesPrunedSubmission(t *testing.T) {
+	v := &testValidators{}
+	f := newTestForwarder(v, "vp1:7051")
+
+	tx := &pb.Transaction{Txid: "tx1", Type: pb.Transaction_CHAINCODE_INVOKE}
+	s, _ := f.track(tx, pb.TransactionSubmission_FORWARDED)
+	f.update(s, func(state *pb.TransactionSubmission) { state.Validator = "vp1:7051" })
+	// The submission is pruned while it is watched, after the first poll
+	f.status = func(address string, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error) {
+		f.forget(req.Txid)
+		return &pb.TransactionSubmission{Txid: req.Txid, State: pb.TransactionSubmission_FORWARDED}, nil
+	}
+	f.watch(s, map[string]bool{"vp1:7051": true})
+
+	if s.state.State != pb.TransactionSubmission_TIMED_OUT || s.state.Validator != "vp1:7051" {
+		t.Fatalf("Expected the pruned submission to time out on the first validator, got %v", s.state)
+	}
+}
+
+func TestForwarderTimesOut(t *testing.T) {
+	for _, v := range []*testValidators{{}, {down: map[string]bool{"vp1:7051": true}}} {
+		f := newTestForwarder(v, "vp1:7051", "vp2:7051")
+
+		tx := &pb.Transaction{Txid: "tx1", Type: pb.Transaction_CHAINCODE_INVOKE}
+		s, _ := f.track(tx, pb.TransactionSubmission_FORWARDED)
+		f.update(s, func(state *pb.TransactionSubmission) { state.Validator = "vp1:7051" })
+		f.watch(s, map[string]bool{"vp1:7051": true})
+
+		if state := f.get("tx1"); state.State != pb.TransactionSubmission_TIMED_OUT || state.Validator != "vp1:7051" {
+			t.Fatalf("Expected the submission to time out on the first validator, got %v", state)
+		}
+		// The validator may still commit the transaction, it is not executed twice
+		if len(v.sent) != 0 {
+			t.Fatalf("Expected the transaction not to be forwarded to another validator, got %v", v.sent)
+		}
+
+		// The finished submissions are dropped after the retention
+		v.now = v.now.Add(2 * time.Hour)
+		f.Lock()
+		f.prune()
+		f.Unlock()
+		if f.get("tx1") != nil {
+			t.Errorf("Expected the submission to be dropped")
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"crypto/x509"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+
+	"github.com/hyperledger/fabric/core/discovery"
+	"github.com/hyperledger/fabric/core/util"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+const membershipKey = "membership"
+
+// initGossipDiscovery installs the gossip membership as the discovery helper,
+// restores the persisted membership, and starts gossiping
+func (p *Impl) initGossipDiscovery() []string {
+	interval := viper.GetDuration("peer.discovery.gossip.interval")
+	fanout := viper.GetInt("peer.discovery.gossip.fanout")
+	suspectTimeout := viper.GetDuration("peer.discovery.gossip.suspectTimeout")
+	failTimeout := viper.GetDuration("peer.discovery.gossip.failTimeout")
+	if interval <= 0 || fanout <= 0 || suspectTimeout <= 0 || failTimeout < suspectTimeout {
+		panic(fmt.Errorf("Invalid peer.discovery.gossip configuration: interval %v, fanout %d, suspectTimeout %v, failTimeout %v", interval, fanout, suspectTimeout, failTimeout))
+	}
+
+	self, err := p.GetPeerEndpoint()
+	if err != nil {
+		panic(fmt.Errorf("Could not get the endpoint of this peer for gossip: %s", err))
+	}
+
+	var sign discovery.SignFunc
+	var verify discovery.VerifyFunc
+	if SecurityEnabled() {
+		sign = p.secHelper.Sign
+		verify = p.verifyAlive
+	}
+	gd := discovery.NewGossipDiscovery(self, sign, verify, suspectTimeout, failTimeout)
+	p.discHelper = gd
+
+	if err := p.loadMembership(gd); err != nil {
+		peerLogger.Errorf("%s", err)
+	}
+
+	p.gossipOnce.Do(func() {
+		go p.gossipMembership(gd, interval, fanout)
+	})
+	return gd.GetAllNodes()
+}
+
+// verifyAlive verifies the signature of the alive message of a member with its
+// enrollment certificate, which must be issued to the enrollment ID and role
+// the member announces
+func (p *Impl) verifyAlive(endpoint *pb.PeerEndpoint, signature, msg []byte) error {
+	raw, err := p.secHelper.GetEnrollmentCertificate(endpoint.PkiID)
+	if err != nil {
+		return fmt.Errorf("Could not get the enrollment certificate of %s: %s", endpoint.ID, err)
+	}
+	eCert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return fmt.Errorf("Could not parse the enrollment certificate of %s: %s", endpoint.ID, err)
+	}
+	if err := verifyEnrollment(eCert, endpoint); err != nil {
+		return err
+	}
+	return p.secHelper.Verify(endpoint.PkiID, signature, msg)
+}
+
+// gossipMembership sends the membership to fanout random connected peers every interval
+func (p *Impl) gossipMembership(gossiper discovery.Gossiper, interval time.Duration, fanout int) {
+	peerLogger.Debugf("Starting membership gossip, with interval = %s and fanout = %d", interval, fanout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.stopped:
+			return
+		}
+		membership, err := gossiper.Heartbeat()
+		if err != nil {
+			peerLogger.Errorf("Error in membership gossip: %s", err)
+			continue
+		}
+		data, err := proto.Marshal(membership)
+		if err != nil {
+			peerLogger.Errorf("Error marshalling membership gossip: %s", err)
+			continue
+		}
+		msg := &pb.Message{Type: pb.Message_DISC_GOSSIP, Payload: data, Timestamp: util.CreateUtcTimestamp()}
+
+		var handlers []MessageHandler
+		for _, handler := range p.cloneHandlerMap(pb.PeerEndpoint_UNDEFINED) {
+			handlers = append(handlers, handler)
+		}
+		for i, j := range rand.Perm(len(handlers)) {
+			if i == fanout {
+				break
+			}
+			if err := handlers[j].SendMessage(msg); err != nil {
+				to, _ := handlers[j].To()
+				peerLogger.Warningf("Error gossiping membership to %s: %s", to.Address, err)
+			}
+		}
+	}
+}
+
+// storeMembership persists the members with their reputation
+func (p *Impl) storeMembership(gd *discovery.GossipDiscovery) error {
+	raw, err := proto.Marshal(gd.Snapshot())
+	if err != nil {
+		err = fmt.Errorf("Could not marshal membership: %s", err)
+		peerLogger.Error(err)
+		return err
+	}
+	return p.Store(membershipKey, raw)
+}
+
+// loadMembership restores the persisted membership, or the discovery list
+// persisted before gossip was enabled
+func (p *Impl) loadMembership(gd *discovery.GossipDiscovery) error {
+	raw, err := p.Load(membershipKey)
+	if err != nil {
+		return fmt.Errorf("Unable to load membership from DB: %s", err)
+	}
+	if raw == nil {
+		addresses, err := p.LoadDiscoveryList()
+		for _, address := range addresses {
+			gd.AddNode(address)
+		}
+		return err
+	}
+	snapshot := &pb.MembershipSnapshot{}
+	if err := proto.Unmarshal(raw, snapshot); err != nil {
+		return fmt.Errorf("Could not unmarshal membership: %s", err)
+	}
+	gd.Restore(snapshot)
+	return nil
+}
@@ -25,7 +25,10 @@ import (
 	"github.com/looplab/fsm"
 	"github.com/spf13/viper"
 
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/discovery"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt/state"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -33,20 +36,19 @@ const DefaultSyncSnapshotTimeout time.Duration = 60 * time.Second
 
 // Handler peer handler implementation.
 type Handler struct {
-	chatMutex                     sync.Mutex
-	ToPeerEndpoint                *pb.PeerEndpoint
-	Coordinator                   MessageHandlerCoordinator
-	ChatStream                    ChatStream
-	doneChan                      chan struct{}
-	FSM                           *fsm.FSM
-	initiatedStream               bool // Was the stream initiated within this Peer
-	registered                    bool
-	syncBlocks                    chan *pb.SyncBlocks
-	snapshotRequestHandler        *syncStateSnapshotRequestHandler
-	syncStateDeltasRequestHandler *syncStateDeltasHandler
-	syncBlocksRequestHandler      *syncBlocksRequestHandler
-	syncSnapshotTimeout           time.Duration
-	lastIgnoredSnapshotCID        *uint64
+	chatMutex           sync.Mutex
+	ToPeerEndpoint      *pb.PeerEndpoint
+	Coordinator         MessageHandlerCoordinator
+	ChatStream          ChatStream
+	doneChan            chan struct{}
+	FSM                 *fsm.FSM
+	initiatedStream     bool // Was the stream initiated within this Peer
+	registered          bool
+	sendQueue           *sendQueue
+	syncBlocks          chan *pb.SyncBlocks
+	syncHandlersLock    sync.Mutex
+	syncHandlers        map[string]*chainSyncHandlers // The state sync requests to the remote peer by chain
+	syncSnapshotTimeout time.Duration
 }
 
 // NewPeerHandler returns a new Peer handler
@@ -60,21 +62,26 @@ func NewPeerHandler(coord MessageHandlerCoordinator, stream ChatStream, initiate
 	}
 	d.doneChan = make(chan struct{})
 
+	sendQueue, err := newSendQueueFromConfig(d.sendToStream)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating new Peer Handler: %s", err)
+	}
+	d.sendQueue = sendQueue
+
 	if dur := viper.GetDuration("peer.sync.state.snapshot.writeTimeout"); dur == 0 {
 		d.syncSnapshotTimeout = DefaultSyncSnapshotTimeout
 	} else {
 		d.syncSnapshotTimeout = dur
 	}
 
-	d.snapshotRequestHandler = newSyncStateSnapshotRequestHandler()
-	d.syncStateDeltasRequestHandler = newSyncStateDeltasHandler()
-	d.syncBlocksRequestHandler = newSyncBlocksRequestHandler()
+	d.syncHandlers = make(map[string]*chainSyncHandlers)
 	d.FSM = fsm.NewFSM(
 		"created",
 		fsm.Events{
 			{Name: pb.Message_DISC_HELLO.String(), Src: []string{"created"}, Dst: "established"},
 			{Name: pb.Message_DISC_GET_PEERS.String(), Src: []string{"established"}, Dst: "established"},
 			{Name: pb.Message_DISC_PEERS.String(), Src: []string{"established"}, Dst: "established"},
+			{Name: pb.Message_DISC_GOSSIP.String(), Src: []string{"established"}, Dst: "established"},
 			{Name: pb.Message_SYNC_BLOCK_ADDED.String(), Src: []string{"established"}, Dst: "established"},
 			{Name: pb.Message_SYNC_GET_BLOCKS.String(), Src: []string{"established"}, Dst: "established"},
 			{Name: pb.Message_SYNC_BLOCKS.String(), Src: []string{"established"}, Dst: "established"},
@@ -88,6 +95,7 @@ func NewPeerHandler(coord MessageHandlerCoordinator, stream ChatStream, initiate
 			"before_" + pb.Message_DISC_HELLO.String():              func(e *fsm.Event) { d.beforeHello(e) },
 			"before_" + pb.Message_DISC_GET_PEERS.String():          func(e *fsm.Event) { d.beforeGetPeers(e) },
 			"before_" + pb.Message_DISC_PEERS.String():              func(e *fsm.Event) { d.beforePeers(e) },
+			"before_" + pb.Message_DISC_GOSSIP.String():             func(e *fsm.Event) { d.beforeGossip(e) },
 			"before_" + pb.Message_SYNC_BLOCK_ADDED.String():        func(e *fsm.Event) { d.beforeBlockAdded(e) },
 			"before_" + pb.Message_SYNC_GET_BLOCKS.String():         func(e *fsm.Event) { d.beforeSyncGetBlocks(e) },
 			"before_" + pb.Message_SYNC_BLOCKS.String():             func(e *fsm.Event) { d.beforeSyncBlocks(e) },
@@ -103,9 +111,11 @@ func NewPeerHandler(coord MessageHandlerCoordinator, stream ChatStream, initiate
 		// Send intiial Hello
 		helloMessage, err := d.Coordinator.NewOpenchainDiscoveryHello()
 		if err != nil {
+			d.sendQueue.close(err)
 			return nil, fmt.Errorf("Error getting new HelloMessage: %s", err)
 		}
 		if err := d.SendMessage(helloMessage); err != nil {
+			d.sendQueue.close(err)
 			return nil, fmt.Errorf("Error creating new Peer Handler, error returned sending %s: %s", pb.Message_DISC_HELLO, err)
 		}
 	}
@@ -138,6 +148,7 @@ func (d *Handler) To() (pb.PeerEndpoint, error) {
 
 // Stop stops this handler, which will trigger the Deregister from the MessageHandlerCoordinator.
 func (d *Handler) Stop() error {
+	d.sendQueue.close(fmt.Errorf("Handler is stopped"))
 	// Deregister the handler
 	err := d.deregister()
 	if err != nil {
@@ -172,8 +183,20 @@ func (d *Handler) beforeHello(e *fsm.Event) {
 			return
 		}
 		peerLogger.Debugf("Verified signature for %s", e.Event)
+
+		if peerBindingRequired() {
+			if err := d.verifyPeerIdentity(helloMessage.PeerEndpoint); err != nil {
+				e.Cancel(identityError{fmt.Errorf("Rejecting peer: %s", err)})
+				return
+			}
+			peerLogger.Debugf("Verified the TLS certificate of %s is bound to its identity", helloMessage.PeerEndpoint.ID)
+		}
 	}
 
+	compression := negotiateCompression(supportedCompressions(), helloMessage.Compressions)
+	peerLogger.Debugf("Compressing messages to %s with %s", helloMessage.PeerEndpoint.ID, compression)
+	d.sendQueue.setCompression(compression)
+
 	if d.initiatedStream == false {
 		// Did NOT intitiate the stream, need to send back HELLO
 		peerLogger.Debugf("Received %s, sending back %s", e.Event, pb.Message_DISC_HELLO.String())
@@ -191,6 +214,10 @@ func (d *Handler) beforeHello(e *fsm.Event) {
 	// Register
 	err = d.Coordinator.RegisterHandler(d)
 	if err != nil {
+		if _, ok := err.(bannedError); ok {
+			e.Cancel(err)
+			return
+		}
 		e.Cancel(fmt.Errorf("Error registering Handler: %s", err))
 	} else {
 		// Registered successfully
@@ -252,6 +279,29 @@ func (d *Handler) beforePeers(e *fsm.Event) {
 
 }
 
+func (d *Handler) beforeGossip(e *fsm.Event) {
+	msg, ok := e.Args[0].(*pb.Message)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	gossiper, ok := d.Coordinator.GetDiscHelper().(discovery.Gossiper)
+	if !ok {
+		peerLogger.Debugf("Ignoring %s, membership gossip is not enabled", e.Event)
+		return
+	}
+
+	membership := &pb.GossipMembership{}
+	if err := proto.Unmarshal(msg.Payload, membership); err != nil {
+		e.Cancel(fmt.Errorf("Error unmarshalling GossipMembership: %s", err))
+		return
+	}
+	// The alive messages which do not verify are dropped, the others are merged
+	if err := gossiper.HandleGossip(d.ToPeerEndpoint, membership); err != nil {
+		peerLogger.Warningf("Error handling membership gossip from %s: %s", d.ToPeerEndpoint.Address, err)
+	}
+}
+
 func (d *Handler) beforeBlockAdded(e *fsm.Event) {
 	peerLogger.Debugf("Received message: %s", e.Event)
 	msg, ok := e.Args[0].(*pb.Message)
@@ -275,6 +325,13 @@ func (d *Handler) HandleMessage(msg *pb.Message) error {
 	}
 	err := d.FSM.Event(msg.Type.String(), msg)
 	if err != nil {
+		if canceled, ok := err.(*fsm.CanceledError); ok {
+			switch canceled.Err.(type) {
+			case identityError, bannedError:
+				// The Chat stream is ended, so pass the error on as is
+				return canceled.Err
+			}
+		}
 		if _, ok := err.(*fsm.NoTransitionError); !ok {
 			// Only allow NoTransitionError's, all others are considered true error.
 			return fmt.Errorf("Peer FSM failed while handling message (%s): current state: %s, error: %s", msg.Type.String(), d.FSM.Current(), err)
@@ -284,18 +341,38 @@ func (d *Handler) HandleMessage(msg *pb.Message) error {
 	return nil
 }
 
-// SendMessage sends a message to the remote PEER through the stream
+// SendMessage queues a message to send to the remote PEER through the stream
 func (d *Handler) SendMessage(msg *pb.Message) error {
+	peerLogger.Debugf("Queueing message to stream of type: %s ", msg.Type)
+	if err := d.sendQueue.enqueue(msg); err != nil {
+		return fmt.Errorf("Error Sending message through ChatStream: %s", err)
+	}
+	return nil
+}
+
+// sendToStream sends a message through the stream, it is called by the send queue
+func (d *Handler) sendToStream(msg *pb.Message) error {
 	//make sure Sends are serialized. Also make sure everyone uses SendMessage
 	//instead of calling Send directly on the grpc stream
 	d.chatMutex.Lock()
 	defer d.chatMutex.Unlock()
 	peerLogger.Debugf("Sending message to stream of type: %s ", msg.Type)
-	err := d.ChatStream.Send(msg)
-	if err != nil {
-		return fmt.Errorf("Error Sending message through ChatStream: %s", err)
-	}
-	return nil
+	return d.ChatStream.Send(msg)
+}
+
+// Closed returns a channel closed when the handler stops sending to the
+// remote PEER, because it is stopped, the stream failed or the send queue
+// overflowed
+func (d *Handler) Closed() <-chan struct{} {
+	return d.sendQueue.closed
+}
+
+// Traffic returns the state of the send queue to the remote PEER and what
+// was sent to it
+func (d *Handler) Traffic() *pb.PeerTraffic {
+	traffic := d.sendQueue.traffic()
+	traffic.PeerEndpoint = d.ToPeerEndpoint
+	return traffic
 }
 
 // start starts the Peer server function
@@ -316,14 +393,48 @@ func (d *Handler) start() error {
 	}
 }
 
+// getSyncHandlers returns the handlers of the state sync requests to the remote peer for the ledger of a chain
+func (d *Handler) getSyncHandlers(chainID string) *chainSyncHandlers {
+	d.syncHandlersLock.Lock()
+	defer d.syncHandlersLock.Unlock()
+	sh, ok := d.syncHandlers[db.NormalizeChainID(chainID)]
+	if !ok {
+		sh = newChainSyncHandlers()
+		d.syncHandlers[db.NormalizeChainID(chainID)] = sh
+	}
+	return sh
+}
+
+// getChainCoordinator returns the coordinator of the ledger of a chain of this peer, which the remote peer syncs from
+func (d *Handler) getChainCoordinator(chainID string) (MessageHandlerCoordinator, error) {
+	if db.NormalizeChainID(chainID) == db.DefaultChainID {
+		return d.Coordinator, nil
+	}
+	chains, ok := d.Coordinator.(ChainProvider)
+	if !ok {
+		return nil, fmt.Errorf("Chain %s is not hosted by this peer", chainID)
+	}
+	return chains.GetChainCoordinator(chainID)
+}
+
+// GetChainRemoteLedger returns the RemoteLedger of a chain of the other PeerEndpoint
+func (d *Handler) GetChainRemoteLedger(chainID string) RemoteLedger {
+	return &chainRemoteLedger{handler: d, chainID: chainID}
+}
+
 // RequestBlocks get the blocks from the other PeerEndpoint based upon supplied SyncBlockRange, will provide them through the returned channel.
 // this will also stop writing any received blocks to channels created from Prior calls to RequestBlocks(..)
 func (d *Handler) RequestBlocks(syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncBlocks, error) {
-	d.syncBlocksRequestHandler.Lock()
-	defer d.syncBlocksRequestHandler.Unlock()
+	return d.requestBlocks("", syncBlockRange)
+}
 
-	d.syncBlocksRequestHandler.reset()
-	syncBlockRange.CorrelationId = d.syncBlocksRequestHandler.correlationID
+func (d *Handler) requestBlocks(chainID string, syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncBlocks, error) {
+	sh := d.getSyncHandlers(chainID)
+	sh.syncBlocksRequestHandler.Lock()
+	defer sh.syncBlocksRequestHandler.Unlock()
+
+	sh.syncBlocksRequestHandler.reset()
+	syncBlockRange.CorrelationId = sh.syncBlocksRequestHandler.correlationID
 
 	// Marshal the SyncBlockRange as the payload
 	syncBlockRangeBytes, err := proto.Marshal(syncBlockRange)
@@ -331,10 +442,10 @@ func (d *Handler) RequestBlocks(syncBlockRange *pb.SyncBlockRange) (<-chan *pb.S
 		return nil, fmt.Errorf("Error marshaling syncBlockRange during GetBlocks: %s", err)
 	}
 	peerLogger.Debugf("Sending %s with Range %s", pb.Message_SYNC_GET_BLOCKS.String(), syncBlockRange)
-	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_GET_BLOCKS, Payload: syncBlockRangeBytes}); err != nil {
+	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_GET_BLOCKS, Payload: syncBlockRangeBytes, ChainID: chainID}); err != nil {
 		return nil, fmt.Errorf("Error sending %s during GetBlocks: %s", pb.Message_SYNC_GET_BLOCKS, err)
 	}
-	return d.syncBlocksRequestHandler.channel, nil
+	return sh.syncBlocksRequestHandler.channel, nil
 }
 
 func (d *Handler) beforeSyncGetBlocks(e *fsm.Event) {
@@ -352,7 +463,7 @@ func (d *Handler) beforeSyncGetBlocks(e *fsm.Event) {
 		return
 	}
 
-	go d.sendBlocks(syncBlockRange)
+	go d.sendBlocks(msg.ChainID, syncBlockRange)
 }
 
 func (d *Handler) beforeSyncBlocks(e *fsm.Event) {
@@ -379,25 +490,31 @@ func (d *Handler) beforeSyncBlocks(e *fsm.Event) {
 		}
 	}()
 
-	d.syncBlocksRequestHandler.Lock()
-	defer d.syncBlocksRequestHandler.Unlock()
+	sh := d.getSyncHandlers(msg.ChainID)
+	sh.syncBlocksRequestHandler.Lock()
+	defer sh.syncBlocksRequestHandler.Unlock()
 	// Use non-blocking send, will WARN if missed message.
-	if d.syncBlocksRequestHandler.shouldHandle(syncBlocks.Range.CorrelationId) {
+	if sh.syncBlocksRequestHandler.shouldHandle(syncBlocks.Range.CorrelationId) {
 		select {
-		case d.syncBlocksRequestHandler.channel <- syncBlocks:
+		case sh.syncBlocksRequestHandler.channel <- syncBlocks:
 		default:
 			peerLogger.Warningf("Did NOT send SyncBlocks message to channel for range: %d - %d", syncBlocks.Range.Start, syncBlocks.Range.End)
-			d.syncBlocksRequestHandler.reset()
+			sh.syncBlocksRequestHandler.reset()
 		}
 	} else {
 		//Ignore the message, does not match the current correlationId
-		peerLogger.Warningf("Ignoring SyncBlocks message with correlationId = %d, blocks %d to %d, as current correlationId = %d", syncBlocks.Range.CorrelationId, syncBlocks.Range.Start, syncBlocks.Range.End, d.syncBlocksRequestHandler.correlationID)
+		peerLogger.Warningf("Ignoring SyncBlocks message with correlationId = %d, blocks %d to %d, as current correlationId = %d", syncBlocks.Range.CorrelationId, syncBlocks.Range.Start, syncBlocks.Range.End, sh.syncBlocksRequestHandler.correlationID)
 	}
 }
 
-// sendBlocks sends the blocks based upon the supplied SyncBlockRange over the stream.
-func (d *Handler) sendBlocks(syncBlockRange *pb.SyncBlockRange) {
+// sendBlocks sends the blocks of the ledger of a chain based upon the supplied SyncBlockRange over the stream.
+func (d *Handler) sendBlocks(chainID string, syncBlockRange *pb.SyncBlockRange) {
 	peerLogger.Debugf("Sending blocks %d-%d", syncBlockRange.Start, syncBlockRange.End)
+	coord, err := d.getChainCoordinator(chainID)
+	if err != nil {
+		peerLogger.Errorf("Error sending blocks: %s", err)
+		return
+	}
 	var blockNums []uint64
 	if syncBlockRange.Start > syncBlockRange.End {
 		// Send in reverse order
@@ -414,7 +531,7 @@ func (d *Handler) sendBlocks(syncBlockRange *pb.SyncBlockRange) {
 	}
 	for _, currBlockNum := range blockNums {
 		// Get the Block from
-		block, err := d.Coordinator.GetBlockByNumber(currBlockNum)
+		block, err := coord.GetBlockByNumber(currBlockNum)
 		if err != nil {
 			peerLogger.Errorf("Error sending blockNum %d: %s", currBlockNum, err)
 			break
@@ -426,7 +543,7 @@ func (d *Handler) sendBlocks(syncBlockRange *pb.SyncBlockRange) {
 			peerLogger.Errorf("Error marshalling syncBlocks for BlockNum = %d: %s", currBlockNum, err)
 			break
 		}
-		if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: syncBlocksBytes}); err != nil {
+		if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: syncBlocksBytes, ChainID: chainID}); err != nil {
 			peerLogger.Errorf("Error sending blockNum %d: %s", currBlockNum, err)
 			break
 		}
@@ -443,23 +560,56 @@ func (d *Handler) sendBlocks(syncBlockRange *pb.SyncBlockRange) {
 // RequestStateSnapshot request the state snapshot deltas from the other PeerEndpoint, will provide them through the returned channel.
 // this will also stop writing any received syncStateSnapshot(s) to channels created from Prior calls to RequestStateSnapshot()
 func (d *Handler) RequestStateSnapshot() (<-chan *pb.SyncStateSnapshot, error) {
-	d.snapshotRequestHandler.Lock()
-	defer d.snapshotRequestHandler.Unlock()
+	return d.requestStateSnapshot("")
+}
+
+func (d *Handler) requestStateSnapshot(chainID string) (<-chan *pb.SyncStateSnapshot, error) {
+	sh := d.getSyncHandlers(chainID)
+	sh.snapshotRequestHandler.Lock()
+	defer sh.snapshotRequestHandler.Unlock()
 	// Reset the handler
-	d.snapshotRequestHandler.reset()
+	sh.snapshotRequestHandler.reset()
 
 	// Create the syncStateSnapshotRequest
-	syncStateSnapshotRequest := d.snapshotRequestHandler.createRequest()
+	syncStateSnapshotRequest := sh.snapshotRequestHandler.createRequest()
 	syncStateSnapshotRequestBytes, err := proto.Marshal(syncStateSnapshotRequest)
 	if err != nil {
 		return nil, fmt.Errorf("Error marshaling syncStateSnapshotRequest during GetStateSnapshot: %s", err)
 	}
 	peerLogger.Debugf("Sending %s with syncStateSnapshotRequest = %s", pb.Message_SYNC_STATE_GET_SNAPSHOT.String(), syncStateSnapshotRequest)
-	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_GET_SNAPSHOT, Payload: syncStateSnapshotRequestBytes}); err != nil {
+	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_GET_SNAPSHOT, Payload: syncStateSnapshotRequestBytes, ChainID: chainID}); err != nil {
 		return nil, fmt.Errorf("Error sending %s during GetStateSnapshot: %s", pb.Message_SYNC_STATE_GET_SNAPSHOT, err)
 	}
 
-	return d.snapshotRequestHandler.channel, nil
+	return sh.snapshotRequestHandler.channel, nil
+}
+
+// RequestStateSnapshotChunk request a partition of the state as of a past block from the other PeerEndpoint, will provide its deltas through the returned channel.
+// this will also stop writing any received syncStateSnapshot(s) to channels created from Prior calls to RequestStateSnapshot() or RequestStateSnapshotChunk()
+func (d *Handler) RequestStateSnapshotChunk(chunk *pb.SyncStateSnapshotChunk) (<-chan *pb.SyncStateSnapshot, error) {
+	return d.requestStateSnapshotChunk("", chunk)
+}
+
+func (d *Handler) requestStateSnapshotChunk(chainID string, chunk *pb.SyncStateSnapshotChunk) (<-chan *pb.SyncStateSnapshot, error) {
+	sh := d.getSyncHandlers(chainID)
+	sh.snapshotRequestHandler.Lock()
+	defer sh.snapshotRequestHandler.Unlock()
+	// Reset the handler
+	sh.snapshotRequestHandler.reset()
+
+	// Create the syncStateSnapshotRequest
+	syncStateSnapshotRequest := sh.snapshotRequestHandler.createRequest()
+	syncStateSnapshotRequest.Chunk = chunk
+	syncStateSnapshotRequestBytes, err := proto.Marshal(syncStateSnapshotRequest)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshaling syncStateSnapshotRequest during RequestStateSnapshotChunk: %s", err)
+	}
+	peerLogger.Debugf("Sending %s with syncStateSnapshotRequest = %s", pb.Message_SYNC_STATE_GET_SNAPSHOT.String(), syncStateSnapshotRequest)
+	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_GET_SNAPSHOT, Payload: syncStateSnapshotRequestBytes, ChainID: chainID}); err != nil {
+		return nil, fmt.Errorf("Error sending %s during RequestStateSnapshotChunk: %s", pb.Message_SYNC_STATE_GET_SNAPSHOT, err)
+	}
+
+	return sh.snapshotRequestHandler.channel, nil
 }
 
 // beforeSyncStateGetSnapshot triggers the sending of State Snapshot deltas to remote Peer.
@@ -479,7 +629,11 @@ func (d *Handler) beforeSyncStateGetSnapshot(e *fsm.Event) {
 	}
 
 	// Start a separate go FUNC to send the State snapshot
-	go d.sendStateSnapshot(syncStateSnapshotRequest)
+	if syncStateSnapshotRequest.Chunk != nil {
+		go d.sendStateSnapshotChunk(msg.ChainID, syncStateSnapshotRequest)
+	} else {
+		go d.sendStateSnapshot(msg.ChainID, syncStateSnapshotRequest)
+	}
 }
 
 // beforeSyncStateSnapshot will write the State Snapshot deltas to the respective channel.
@@ -505,33 +659,39 @@ func (d *Handler) beforeSyncStateSnapshot(e *fsm.Event) {
 		}
 	}()
 	// Use blocking send and timeout, will WARN and close channel if write times out
-	d.snapshotRequestHandler.Lock()
-	defer d.snapshotRequestHandler.Unlock()
+	sh := d.getSyncHandlers(msg.ChainID)
+	sh.snapshotRequestHandler.Lock()
+	defer sh.snapshotRequestHandler.Unlock()
 	timer := time.NewTimer(d.syncSnapshotTimeout)
 	// Make sure the correlationID matches
-	if d.snapshotRequestHandler.shouldHandle(syncStateSnapshot.Request.CorrelationId) {
+	if sh.snapshotRequestHandler.shouldHandle(syncStateSnapshot.Request.CorrelationId) {
 		select {
-		case d.snapshotRequestHandler.channel <- syncStateSnapshot:
+		case sh.snapshotRequestHandler.channel <- syncStateSnapshot:
 		case <-timer.C:
 			// Was not able to write to the channel, in which case the Snapshot stream is incomplete, and must be discarded, closing the channel
 			// without sending the terminating message which would have had an empty byte slice.
 			peerLogger.Warningf("Did NOT send SyncStateSnapshot message to channel for correlationId = %d, sequence = %d because we timed out reading, closing channel as the message has been discarded", syncStateSnapshot.Request.CorrelationId, syncStateSnapshot.Sequence)
-			d.snapshotRequestHandler.reset()
+			sh.snapshotRequestHandler.reset()
 		}
 	} else {
-		if d.lastIgnoredSnapshotCID == nil || *d.lastIgnoredSnapshotCID < syncStateSnapshot.Request.CorrelationId {
-			peerLogger.Warningf("Ignoring SyncStateSnapshot message with correlationId = %d, sequence = %d, as current correlationId = %d, future messages for this (and older ids) will be suppressed", syncStateSnapshot.Request.CorrelationId, syncStateSnapshot.Sequence, d.snapshotRequestHandler.correlationID)
-			d.lastIgnoredSnapshotCID = &syncStateSnapshot.Request.CorrelationId
+		if sh.lastIgnoredSnapshotCID == nil || *sh.lastIgnoredSnapshotCID < syncStateSnapshot.Request.CorrelationId {
+			peerLogger.Warningf("Ignoring SyncStateSnapshot message with correlationId = %d, sequence = %d, as current correlationId = %d, future messages for this (and older ids) will be suppressed", syncStateSnapshot.Request.CorrelationId, syncStateSnapshot.Sequence, sh.snapshotRequestHandler.correlationID)
+			sh.lastIgnoredSnapshotCID = &syncStateSnapshot.Request.CorrelationId
 			//Ignore the message, does not match the current correlationId
 		}
 	}
 }
 
 // sendBlocks sends the blocks based upon the supplied SyncBlockRange over the stream.
-func (d *Handler) sendStateSnapshot(syncStateSnapshotRequest *pb.SyncStateSnapshotRequest) {
+func (d *Handler) sendStateSnapshot(chainID string, syncStateSnapshotRequest *pb.SyncStateSnapshotRequest) {
 	peerLogger.Debugf("Sending state snapshot with correlationId = %d", syncStateSnapshotRequest.CorrelationId)
 
-	snapshot, err := d.Coordinator.GetStateSnapshot()
+	coord, err := d.getChainCoordinator(chainID)
+	if err != nil {
+		peerLogger.Errorf("Error getting snapshot: %s", err)
+		return
+	}
+	snapshot, err := coord.GetStateSnapshot()
 	if err != nil {
 		peerLogger.Errorf("Error getting snapshot: %s", err)
 		return
@@ -543,39 +703,200 @@ func (d *Handler) sendStateSnapshot(syncStateSnapshotRequest *pb.SyncStateSnapsh
 	var sequence uint64
 	// Loop through and send the Deltas
 	for i := 0; snapshot.Next(); i++ {
-		delta := statemgmt.NewStateDelta()
 		k, v := snapshot.GetRawKeyValue()
-		cID, keyID := statemgmt.DecodeCompositeKey(k)
-		delta.Set(cID, keyID, v, nil)
-
-		deltaAsBytes := delta.Marshal()
-		// Encode a SyncStateSnapsot into the payload
 		sequence = uint64(i)
-		syncStateSnapshot := &pb.SyncStateSnapshot{Delta: deltaAsBytes, Sequence: sequence, BlockNumber: currBlockNumber, Request: syncStateSnapshotRequest}
-
-		syncStateSnapshotBytes, err := proto.Marshal(syncStateSnapshot)
-		if err != nil {
-			peerLogger.Errorf("Error marshalling syncStateSnapsot for BlockNum = %d: %s", currBlockNumber, err)
-			break
-		}
-		if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_SNAPSHOT, Payload: syncStateSnapshotBytes}); err != nil {
+		if err := d.sendStateSnapshotKey(chainID, syncStateSnapshotRequest, currBlockNumber, sequence, k, v); err != nil {
 			peerLogger.Errorf("Error sending syncStateSnapsot for BlockNum = %d: %s", currBlockNumber, err)
 			break
 		}
 	}
 
-	// Now send the terminating message
-	syncStateSnapshot := &pb.SyncStateSnapshot{Delta: []byte{}, Sequence: sequence + 1, BlockNumber: currBlockNumber, Request: syncStateSnapshotRequest}
+	d.sendStateSnapshotEnd(chainID, syncStateSnapshotRequest, currBlockNumber, sequence+1, nil)
+}
+
+// sendStateSnapshotKey sends a key of the state and its value as a state delta
+func (d *Handler) sendStateSnapshotKey(chainID string, syncStateSnapshotRequest *pb.SyncStateSnapshotRequest, blockNumber, sequence uint64, compositeKey, value []byte) error {
+	delta := statemgmt.NewStateDelta()
+	cID, keyID := statemgmt.DecodeCompositeKey(compositeKey)
+	delta.Set(cID, keyID, value, nil)
+
+	// Encode a SyncStateSnapsot into the payload
+	syncStateSnapshot := &pb.SyncStateSnapshot{Delta: delta.Marshal(), Sequence: sequence, BlockNumber: blockNumber, Request: syncStateSnapshotRequest}
+
+	syncStateSnapshotBytes, err := proto.Marshal(syncStateSnapshot)
+	if err != nil {
+		return fmt.Errorf("Error marshalling syncStateSnapsot: %s", err)
+	}
+	return d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_SNAPSHOT, Payload: syncStateSnapshotBytes, ChainID: chainID})
+}
+
+// sendStateSnapshotEnd sends the terminating message of a state snapshot, which has an empty delta, and the hashes
+// of the partitions of the state when they were requested
+func (d *Handler) sendStateSnapshotEnd(chainID string, syncStateSnapshotRequest *pb.SyncStateSnapshotRequest, currBlockNumber, sequence uint64, partitionHashes [][]byte) {
+	syncStateSnapshot := &pb.SyncStateSnapshot{Delta: []byte{}, Sequence: sequence, BlockNumber: currBlockNumber, Request: syncStateSnapshotRequest, PartitionHashes: partitionHashes}
 	syncStateSnapshotBytes, err := proto.Marshal(syncStateSnapshot)
 	if err != nil {
 		peerLogger.Errorf("Error marshalling terminating syncStateSnapsot message for correlationId = %d, BlockNum = %d: %s", syncStateSnapshotRequest.CorrelationId, currBlockNumber, err)
 		return
 	}
-	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_SNAPSHOT, Payload: syncStateSnapshotBytes}); err != nil {
+	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_SNAPSHOT, Payload: syncStateSnapshotBytes, ChainID: chainID}); err != nil {
 		peerLogger.Errorf("Error sending terminating syncStateSnapsot for correlationId = %d, BlockNum = %d: %s", syncStateSnapshotRequest.CorrelationId, currBlockNumber, err)
 		return
 	}
+}
+
+// sendStateSnapshotChunk sends the keys of a partition of the state as of a past block, or the hashes of all the
+// partitions
+func (d *Handler) sendStateSnapshotChunk(chainID string, syncStateSnapshotRequest *pb.SyncStateSnapshotRequest) {
+	chunk := syncStateSnapshotRequest.Chunk
+	peerLogger.Debugf("Sending partition %d of %d of the state snapshot at block %d with correlationId = %d", chunk.Partition, chunk.Partitions, chunk.BlockNumber, syncStateSnapshotRequest.CorrelationId)
+
+	if chunk.Partition >= chunk.Partitions {
+		peerLogger.Errorf("Invalid state snapshot partition %d of %d", chunk.Partition, chunk.Partitions)
+		return
+	}
+
+	coord, err := d.getChainCoordinator(chainID)
+	if err != nil {
+		peerLogger.Errorf("Error getting snapshot: %s", err)
+		return
+	}
+	partitioning, err := coord.GetStatePartitioning()
+	if err != nil {
+		peerLogger.Errorf("Cannot send the state snapshot in partitions: %s", err)
+		return
+	}
+	snapshot, err := coord.GetStateSnapshot()
+	if err != nil {
+		peerLogger.Errorf("Error getting snapshot: %s", err)
+		return
+	}
+	defer snapshot.Release()
+
+	if snapshot.GetBlockNumber() < chunk.BlockNumber {
+		peerLogger.Errorf("Cannot send the state snapshot at block %d, the state is only at block %d", chunk.BlockNumber, snapshot.GetBlockNumber())
+		return
+	}
+
+	var deltas []*statemgmt.StateDelta
+	for blockNumber := snapshot.GetBlockNumber(); blockNumber > chunk.BlockNumber; blockNumber-- {
+		delta, err := coord.GetStateDelta(blockNumber)
+		if err != nil || delta == nil {
+			peerLogger.Errorf("Cannot send the state snapshot at block %d, the state delta of block %d is not available: %v", chunk.BlockNumber, blockNumber, err)
+			return
+		}
+		deltas = append(deltas, delta)
+	}
+	rollback, err := rollbackKeys(deltas, func(k []byte) (uint32, error) {
+		return partitioning.GetPartition(k, chunk.Partitions)
+	})
+	if err != nil {
+		peerLogger.Errorf("Cannot send the state snapshot at block %d: %s", chunk.BlockNumber, err)
+		return
+	}
 
+	if chunk.Hashes {
+		hashes := make([][]byte, chunk.Partitions)
+		for partition := range hashes {
+			kvs := make(map[string][]byte)
+			err = forEachKeyOfPartitionAsOf(snapshot, uint32(partition), chunk.Partitions, rollback[uint32(partition)], func(k, v []byte) error {
+				kvs[string(k)] = v
+				return nil
+			})
+			if err == nil {
+				hashes[partition], err = partitioning.ComputePartitionCryptoHash(uint32(partition), chunk.Partitions, kvs)
+			}
+			if err != nil {
+				peerLogger.Errorf("Cannot compute the hash of partition %d of the state snapshot at block %d: %s", partition, chunk.BlockNumber, err)
+				return
+			}
+		}
+		d.sendStateSnapshotEnd(chainID, syncStateSnapshotRequest, chunk.BlockNumber, 0, hashes)
+		return
+	}
+
+	var sequence uint64
+	err = forEachKeyOfPartitionAsOf(snapshot, chunk.Partition, chunk.Partitions, rollback[chunk.Partition], func(k, v []byte) error {
+		err := d.sendStateSnapshotKey(chainID, syncStateSnapshotRequest, chunk.BlockNumber, sequence, k, v)
+		sequence++
+		return err
+	})
+	if err != nil {
+		peerLogger.Errorf("Error sending syncStateSnapsot for BlockNum = %d: %s", chunk.BlockNumber, err)
+		return
+	}
+
+	d.sendStateSnapshotEnd(chainID, syncStateSnapshotRequest, chunk.BlockNumber, sequence, nil)
+}
+
+// stateIterator iterates over the keys of the state and their values
+type stateIterator interface {
+	Next() bool
+	GetRawKeyValue() ([]byte, []byte)
+}
+
+// rollbackKeys returns, by partition, the keys changed since a past block with their values as of the block, nil
+// for the keys which did not exist, from the state deltas of the blocks since, the newest first
+func rollbackKeys(deltas []*statemgmt.StateDelta, partitionOf func(k []byte) (uint32, error)) (map[uint32]map[string][]byte, error) {
+	rollback := make(map[uint32]map[string][]byte)
+	for _, delta := range deltas {
+		for _, cID := range delta.GetUpdatedChaincodeIds(false) {
+			for keyID, value := range delta.GetUpdates(cID) {
+				k := statemgmt.ConstructCompositeKey(cID, keyID)
+				partition, err := partitionOf(k)
+				if err != nil {
+					return nil, err
+				}
+				if rollback[partition] == nil {
+					rollback[partition] = make(map[string][]byte)
+				}
+				rollback[partition][string(k)] = value.GetPreviousValue()
+			}
+		}
+	}
+	return rollback, nil
+}
+
+// forEachKeyOfPartitionAsOf calls send with each key of a partition of the state as of a past block, and its value,
+// iterating over the partition only
+func forEachKeyOfPartitionAsOf(snapshot *state.StateSnapshot, partition, partitions uint32, rollback map[string][]byte, send func(k, v []byte) error) error {
+	itr, err := snapshot.GetPartitionIterator(partition, partitions)
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+	return forEachKeyAsOf(itr, rollback, send)
+}
+
+// forEachKeyAsOf calls send with each key of the state as of a past block, and its value. The keys are iterated
+// from the current state, and the keys changed since the block are rolled back to their values as of the block.
+func forEachKeyAsOf(itr stateIterator, rollback map[string][]byte, send func(k, v []byte) error) error {
+	rolledBack := make(map[string]bool)
+	for itr.Next() {
+		k, v := itr.GetRawKeyValue()
+		if previous, ok := rollback[string(k)]; ok {
+			rolledBack[string(k)] = true
+			if previous == nil {
+				continue
+			}
+			v = previous
+		}
+		if err := send(k, v); err != nil {
+			return err
+		}
+	}
+
+	// The keys deleted since the block
+	for k, v := range rollback {
+		if v == nil || rolledBack[k] {
+			continue
+		}
+		if err := send([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // ----------------------------------------------------------------------------
@@ -588,24 +909,29 @@ func (d *Handler) sendStateSnapshot(syncStateSnapshotRequest *pb.SyncStateSnapsh
 // RequestStateDeltas get the state snapshot deltas from the other PeerEndpoint, will provide them through the returned channel.
 // this will also stop writing any received syncStateSnapshot(s) to channels created from Prior calls to GetStateSnapshot()
 func (d *Handler) RequestStateDeltas(syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncStateDeltas, error) {
-	d.syncStateDeltasRequestHandler.Lock()
-	defer d.syncStateDeltasRequestHandler.Unlock()
+	return d.requestStateDeltas("", syncBlockRange)
+}
+
+func (d *Handler) requestStateDeltas(chainID string, syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncStateDeltas, error) {
+	sh := d.getSyncHandlers(chainID)
+	sh.syncStateDeltasRequestHandler.Lock()
+	defer sh.syncStateDeltasRequestHandler.Unlock()
 	// Reset the handler
-	d.syncStateDeltasRequestHandler.reset()
-	syncBlockRange.CorrelationId = d.syncStateDeltasRequestHandler.correlationID
+	sh.syncStateDeltasRequestHandler.reset()
+	syncBlockRange.CorrelationId = sh.syncStateDeltasRequestHandler.correlationID
 
 	// Create the syncStateSnapshotRequest
-	syncStateDeltasRequest := d.syncStateDeltasRequestHandler.createRequest(syncBlockRange)
+	syncStateDeltasRequest := sh.syncStateDeltasRequestHandler.createRequest(syncBlockRange)
 	syncStateDeltasRequestBytes, err := proto.Marshal(syncStateDeltasRequest)
 	if err != nil {
 		return nil, fmt.Errorf("Error marshaling syncStateDeltasRequest during RequestStateDeltas: %s", err)
 	}
 	peerLogger.Debugf("Sending %s with syncStateDeltasRequest = %s", pb.Message_SYNC_STATE_GET_DELTAS.String(), syncStateDeltasRequest)
-	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_GET_DELTAS, Payload: syncStateDeltasRequestBytes}); err != nil {
+	if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_GET_DELTAS, Payload: syncStateDeltasRequestBytes, ChainID: chainID}); err != nil {
 		return nil, fmt.Errorf("Error sending %s during RequestStateDeltas: %s", pb.Message_SYNC_STATE_GET_DELTAS, err)
 	}
 
-	return d.syncStateDeltasRequestHandler.channel, nil
+	return sh.syncStateDeltasRequestHandler.channel, nil
 }
 
 // beforeSyncStateGetDeltas triggers the sending of Get SyncStateDeltas to remote Peer.
@@ -625,12 +951,17 @@ func (d *Handler) beforeSyncStateGetDeltas(e *fsm.Event) {
 	}
 
 	// Start a separate go FUNC to send the State Deltas
-	go d.sendStateDeltas(syncStateDeltasRequest)
+	go d.sendStateDeltas(msg.ChainID, syncStateDeltasRequest)
 }
 
 // sendBlocks sends the blocks based upon the supplied SyncBlockRange over the stream.
-func (d *Handler) sendStateDeltas(syncStateDeltasRequest *pb.SyncStateDeltasRequest) {
+func (d *Handler) sendStateDeltas(chainID string, syncStateDeltasRequest *pb.SyncStateDeltasRequest) {
 	peerLogger.Debugf("Sending state deltas for block range %d-%d", syncStateDeltasRequest.Range.Start, syncStateDeltasRequest.Range.End)
+	coord, err := d.getChainCoordinator(chainID)
+	if err != nil {
+		peerLogger.Errorf("Error sending state deltas: %s", err)
+		return
+	}
 	var blockNums []uint64
 	syncBlockRange := syncStateDeltasRequest.Range
 	if syncBlockRange.Start > syncBlockRange.End {
@@ -647,7 +978,7 @@ func (d *Handler) sendStateDeltas(syncStateDeltasRequest *pb.SyncStateDeltasRequ
 	}
 	for _, currBlockNum := range blockNums {
 		// Get the state deltas for Block from coordinator
-		stateDelta, err := d.Coordinator.GetStateDelta(currBlockNum)
+		stateDelta, err := coord.GetStateDelta(currBlockNum)
 		if err != nil {
 			peerLogger.Errorf("Error sending stateDelta for blockNum %d: %s", currBlockNum, err)
 			break
@@ -664,7 +995,7 @@ func (d *Handler) sendStateDeltas(syncStateDeltasRequest *pb.SyncStateDeltasRequ
 			peerLogger.Errorf("Error marshalling syncStateDeltas for BlockNum = %d: %s", currBlockNum, err)
 			break
 		}
-		if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_DELTAS, Payload: syncStateDeltasBytes}); err != nil {
+		if err := d.SendMessage(&pb.Message{Type: pb.Message_SYNC_STATE_DELTAS, Payload: syncStateDeltasBytes, ChainID: chainID}); err != nil {
 			peerLogger.Errorf("Error sending stateDeltas for blockNum %d: %s", currBlockNum, err)
 			break
 		}
@@ -695,19 +1026,20 @@ func (d *Handler) beforeSyncStateDeltas(e *fsm.Event) {
 	}()
 
 	// Use non-blocking send, will WARN and close channel if missed message.
-	d.syncStateDeltasRequestHandler.Lock()
-	defer d.syncStateDeltasRequestHandler.Unlock()
-	if d.syncStateDeltasRequestHandler.shouldHandle(syncStateDeltas.Range.CorrelationId) {
+	sh := d.getSyncHandlers(msg.ChainID)
+	sh.syncStateDeltasRequestHandler.Lock()
+	defer sh.syncStateDeltasRequestHandler.Unlock()
+	if sh.syncStateDeltasRequestHandler.shouldHandle(syncStateDeltas.Range.CorrelationId) {
 		select {
-		case d.syncStateDeltasRequestHandler.channel <- syncStateDeltas:
+		case sh.syncStateDeltasRequestHandler.channel <- syncStateDeltas:
 		default:
 			// Was not able to write to the channel, in which case the SyncStateDeltasRequest stream is incomplete, and must be discarded, closing the channel
 			peerLogger.Warningf("Did NOT send SyncStateDeltas message to channel for block range %d-%d, closing channel as the message has been discarded", syncStateDeltas.Range.Start, syncStateDeltas.Range.End)
-			d.syncStateDeltasRequestHandler.reset()
+			sh.syncStateDeltasRequestHandler.reset()
 		}
 	} else {
 		//Ignore the message, does not match the current correlationId
-		peerLogger.Warningf("Ignoring SyncStateDeltas message with correlationId = %d, blocks %d to %d, as current correlationId = %d", syncStateDeltas.Range.CorrelationId, syncStateDeltas.Range.Start, syncStateDeltas.Range.End, d.syncStateDeltasRequestHandler.correlationID)
+		peerLogger.Warningf("Ignoring SyncStateDeltas message with correlationId = %d, blocks %d to %d, as current correlationId = %d", syncStateDeltas.Range.CorrelationId, syncStateDeltas.Range.Start, syncStateDeltas.Range.End, sh.syncStateDeltasRequestHandler.correlationID)
 	}
 
 }
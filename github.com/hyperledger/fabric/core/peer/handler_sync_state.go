@@ -119,3 +119,25 @@ func newSyncStateDeltasHandler() *syncStateDeltasHandler {
 	ssdh.reset()
 	return ssdh
 }
+
+//-----------------------------------------------------------------------------
+//
+// Chain Sync Handlers
+//
+//-----------------------------------------------------------------------------
+
+// chainSyncHandlers holds the state sync requests to a remote peer for the ledger of one chain
+type chainSyncHandlers struct {
+	snapshotRequestHandler        *syncStateSnapshotRequestHandler
+	syncStateDeltasRequestHandler *syncStateDeltasHandler
+	syncBlocksRequestHandler      *syncBlocksRequestHandler
+	lastIgnoredSnapshotCID        *uint64
+}
+
+func newChainSyncHandlers() *chainSyncHandlers {
+	return &chainSyncHandlers{
+		snapshotRequestHandler:        newSyncStateSnapshotRequestHandler(),
+		syncStateDeltasRequestHandler: newSyncStateDeltasHandler(),
+		syncBlocksRequestHandler:      newSyncBlocksRequestHandler(),
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+)
+
+type mockStateIterator struct {
+	keys   []string
+	values map[string][]byte
+	next   int
+}
+
+func (itr *mockStateIterator) Next() bool {
+	itr.next++
+	return itr.next <= len(itr.keys)
+}
+
+func (itr *mockStateIterator) GetRawKeyValue() ([]byte, []byte) {
+	k := itr.keys[itr.next-1]
+	return []byte(k), itr.values[k]
+}
+
+func TestForEachKeyAsOf(t *testing.T) {
+	key := func(k string) string {
+		return string(statemgmt.ConstructCompositeKey("cc", k))
+	}
+	current := map[string][]byte{key("a"): []byte("3"), key("b"): []byte("2"), key("d"): []byte("9")}
+
+	// Block 3 sets a and deletes c, block 2 sets a and creates d
+	block3 := statemgmt.NewStateDelta()
+	block3.Set("cc", "a", []byte("3"), []byte("2"))
+	block3.Delete("cc", "c", []byte("7"))
+	block2 := statemgmt.NewStateDelta()
+	block2.Set("cc", "a", []byte("2"), []byte("1"))
+	block2.Set("cc", "d", []byte("9"), nil)
+
+	expected := map[string]string{key("a"): "1", key("b"): "2", key("c"): "7"}
+
+	partitions := uint32(3)
+	partitionOf := func(k []byte) (uint32, error) {
+		h := fnv.New32a()
+		h.Write(k)
+		return h.Sum32() % partitions, nil
+	}
+	rollback, err := rollbackKeys([]*statemgmt.StateDelta{block3, block2}, partitionOf)
+	if err != nil {
+		t.Fatalf("Could not roll the keys back: %s", err)
+	}
+
+	state := make(map[string]string)
+	for partition := uint32(0); partition < partitions; partition++ {
+		// The iterator only covers the keys of the partition
+		itr := &mockStateIterator{values: current}
+		for _, k := range []string{key("a"), key("b"), key("d")} {
+			if p, _ := partitionOf([]byte(k)); p == partition {
+				itr.keys = append(itr.keys, k)
+			}
+		}
+		err := forEachKeyAsOf(itr, rollback[partition], func(k, v []byte) error {
+			if p, _ := partitionOf(k); p != partition {
+				t.Errorf("Key %s does not belong to partition %d", k, partition)
+			}
+			if _, ok := state[string(k)]; ok {
+				t.Errorf("Key %s was sent twice", k)
+			}
+			state[string(k)] = string(v)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Could not iterate over partition %d: %s", partition, err)
+		}
+	}
+
+	if len(state) != len(expected) {
+		t.Fatalf("Expected the state as of block 1 to be %v, got %v", expected, state)
+	}
+	for k, v := range expected {
+		if state[k] != v {
+			t.Errorf("Expected the state as of block 1 to be %v, got %v", expected, state)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+	grpcpeer "google.golang.org/grpc/peer"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/crypto"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	membersrvc "github.com/hyperledger/fabric/membersrvc/protos"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// identityError is returned when a peer announces an identity its TLS
+// certificate does not match, the Chat stream with it is ended
+type identityError struct {
+	error
+}
+
+// certifiedChatStream is a ChatStream whose transport authenticated the remote peer with a TLS certificate
+type certifiedChatStream struct {
+	ChatStream
+	cert *x509.Certificate
+}
+
+// remoteCertificate returns the TLS certificate the remote peer of a Chat stream authenticated with, or nil
+func remoteCertificate(stream ChatStream) *x509.Certificate {
+	if s, ok := stream.(*certifiedChatStream); ok {
+		return s.cert
+	}
+	return nil
+}
+
+// clientCertificate returns the TLS certificate the client of a server side stream authenticated with, or nil
+func clientCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return nil
+	}
+	return info.State.PeerCertificates[0]
+}
+
+// peerBindingRequired returns whether the peers must authenticate with a TLS
+// certificate bound to the identity they announce
+func peerBindingRequired() bool {
+	return SecurityEnabled() && comm.TLSEnabled()
+}
+
+// CheckPeerBindingConfig returns an error when the peers must bind their TLS
+// certificate to their identity but peer.tls.rootcert.file is not set. The
+// TLS certificate of each peer is issued to its own enrollment ID, trusting
+// only the certificate of this peer would refuse all the other peers.
+func CheckPeerBindingConfig() error {
+	return checkPeerBindingConfig(peerBindingRequired(), viper.GetString("peer.tls.rootcert.file"))
+}
+
+func checkPeerBindingConfig(required bool, rootCertFile string) error {
+	if required && rootCertFile == "" {
+		return fmt.Errorf("peer.tls.rootcert.file must be set when security and TLS are enabled, it holds the root certificate the TLS certificates of the peers are issued by")
+	}
+	return nil
+}
+
+// verifyPeerBinding checks that the TLS certificate of a remote peer belongs
+// to the enrollment identity it announced: the certificates must be issued to
+// the announced enrollment ID, the role of the enrollment certificate must
+// match the announced type, and the TLS certificate must be valid for the
+// announced address
+func verifyPeerBinding(tlsCert, eCert *x509.Certificate, endpoint *pb.PeerEndpoint) error {
+	if tlsCert == nil {
+		return fmt.Errorf("%s did not authenticate with a TLS certificate", endpoint.ID)
+	}
+	if err := verifyEnrollment(eCert, endpoint); err != nil {
+		return err
+	}
+	if tlsCert.Subject.CommonName != eCert.Subject.CommonName {
+		return fmt.Errorf("%s authenticated with a TLS certificate issued to %s, but is enrolled as %s", endpoint.ID, tlsCert.Subject.CommonName, eCert.Subject.CommonName)
+	}
+
+	host, _, err := net.SplitHostPort(endpoint.Address)
+	if err != nil {
+		return fmt.Errorf("%s announced an invalid address %s: %s", endpoint.ID, endpoint.Address, err)
+	}
+	if err := tlsCert.VerifyHostname(host); err != nil {
+		return fmt.Errorf("%s announced the address %s, which its TLS certificate is not valid for: %s", endpoint.ID, endpoint.Address, err)
+	}
+	return nil
+}
+
+// verifyEnrollment checks that the enrollment certificate of a peer is issued
+// to the enrollment ID it announced, with the role of the type it announced
+func verifyEnrollment(eCert *x509.Certificate, endpoint *pb.PeerEndpoint) error {
+	if eCert.Subject.CommonName != endpoint.ID.Name {
+		return fmt.Errorf("%s announced itself as %s, but is enrolled as %s", endpoint.Address, endpoint.ID.Name, eCert.Subject.CommonName)
+	}
+	roleRaw, err := primitives.GetCriticalExtension(eCert, crypto.ECertSubjectRole)
+	if err != nil {
+		return fmt.Errorf("The enrollment certificate of %s has no role: %s", endpoint.ID, err)
+	}
+	role, err := strconv.Atoi(string(roleRaw))
+	if err != nil {
+		return fmt.Errorf("The enrollment certificate of %s has an invalid role: %s", endpoint.ID, err)
+	}
+	switch {
+	case membersrvc.Role(role) == membersrvc.Role_VALIDATOR && endpoint.Type == pb.PeerEndpoint_VALIDATOR:
+	case membersrvc.Role(role) == membersrvc.Role_PEER && endpoint.Type == pb.PeerEndpoint_NON_VALIDATOR:
+	default:
+		return fmt.Errorf("%s announced itself as %s, but is enrolled with role %s", endpoint.ID, endpoint.Type, membersrvc.Role(role))
+	}
+	return nil
+}
+
+// verifyPeerIdentity checks that the remote peer of a Chat stream authenticated
+// with a TLS certificate bound to the identity it announced in its hello
+func (d *Handler) verifyPeerIdentity(endpoint *pb.PeerEndpoint) error {
+	raw, err := d.Coordinator.GetSecHelper().GetEnrollmentCertificate(endpoint.PkiID)
+	if err != nil {
+		return fmt.Errorf("Could not get the enrollment certificate of %s: %s", endpoint.ID, err)
+	}
+	eCert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return fmt.Errorf("Could not parse the enrollment certificate of %s: %s", endpoint.ID, err)
+	}
+	return verifyPeerBinding(remoteCertificate(d.ChatStream), eCert, endpoint)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/crypto"
+	membersrvc "github.com/hyperledger/fabric/membersrvc/protos"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func newTestCertificate(t *testing.T, commonName string, extensions []pkix.Extension, dnsNames []string, ips []net.IP) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate a key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: commonName},
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: extensions,
+		DNSNames:        dnsNames,
+		IPAddresses:     ips,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Could not create a certificate: %s", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("Could not parse the certificate: %s", err)
+	}
+	return cert
+}
+
+func newTestECert(t *testing.T, enrollID string, role membersrvc.Role) *x509.Certificate {
+	ext := pkix.Extension{Id: crypto.ECertSubjectRole, Critical: true, Value: []byte(strconv.Itoa(int(role)))}
+	return newTestCertificate(t, enrollID, []pkix.Extension{ext}, nil, nil)
+}
+
+func TestVerifyPeerBinding(t *testing.T) {
+	tlsCert := newTestCertificate(t, "vp0", nil, []string{"vp0.example.com"}, []net.IP{net.ParseIP("10.0.0.1")})
+	validator := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "vp0"}, Address: "vp0.example.com:7051", Type: pb.PeerEndpoint_VALIDATOR}
+
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_VALIDATOR), validator); err != nil {
+		t.Errorf("Expected a matching validator to be accepted: %s", err)
+	}
+	byIP := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "vp0"}, Address: "10.0.0.1:7051", Type: pb.PeerEndpoint_VALIDATOR}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_VALIDATOR), byIP); err != nil {
+		t.Errorf("Expected a validator announcing an IP address of its certificate to be accepted: %s", err)
+	}
+	nvp := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "vp0"}, Address: "vp0.example.com:7051", Type: pb.PeerEndpoint_NON_VALIDATOR}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_PEER), nvp); err != nil {
+		t.Errorf("Expected a matching non validator to be accepted: %s", err)
+	}
+
+	if err := verifyPeerBinding(nil, newTestECert(t, "vp0", membersrvc.Role_VALIDATOR), validator); err == nil {
+		t.Errorf("Expected a peer without TLS certificate to be rejected")
+	}
+	impostor := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "vp1"}, Address: "vp0.example.com:7051", Type: pb.PeerEndpoint_VALIDATOR}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp1", membersrvc.Role_VALIDATOR), impostor); err == nil {
+		t.Errorf("Expected a peer whose TLS certificate is issued to another enrollment ID to be rejected")
+	}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_VALIDATOR), impostor); err == nil {
+		t.Errorf("Expected a peer announcing an ID other than its enrollment ID to be rejected")
+	}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_PEER), validator); err == nil {
+		t.Errorf("Expected a non validator announcing itself as a validator to be rejected")
+	}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_VALIDATOR), nvp); err == nil {
+		t.Errorf("Expected a validator announcing itself as a non validator to be rejected")
+	}
+	elsewhere := &pb.PeerEndpoint{ID: &pb.PeerID{Name: "vp0"}, Address: "vp1.example.com:7051", Type: pb.PeerEndpoint_VALIDATOR}
+	if err := verifyPeerBinding(tlsCert, newTestECert(t, "vp0", membersrvc.Role_VALIDATOR), elsewhere); err == nil {
+		t.Errorf("Expected a peer announcing an address its TLS certificate is not valid for to be rejected")
+	}
+	if err := verifyPeerBinding(tlsCert, newTestCertificate(t, "vp0", nil, nil, nil), validator); err == nil {
+		t.Errorf("Expected a peer whose enrollment certificate has no role to be rejected")
+	}
+}
+
+func TestCheckPeerBindingConfig(t *testing.T) {
+	if err := checkPeerBindingConfig(true, ""); err == nil {
+		t.Errorf("Expected the root certificate of the peers to be required when they bind their TLS certificate to their identity")
+	}
+	if err := checkPeerBindingConfig(true, "tlsca.cert"); err != nil {
+		t.Errorf("Error checking configuration with a root certificate: %s", err)
+	}
+	if err := checkPeerBindingConfig(false, ""); err != nil {
+		t.Errorf("Error checking configuration without peer binding: %s", err)
+	}
+}
+
+// newTestKeyPair returns a self-signed TLS certificate issued to commonName,
+// valid for 127.0.0.1 as a server and as a client
+func newTestKeyPair(t *testing.T, commonName string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Could not generate a key: %s", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Could not create a certificate: %s", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key}
+}
+
+func TestChatRequiresClientCertificate(t *testing.T) {
+	savedSecurity, savedTLS := viper.GetBool("security.enabled"), viper.GetBool("peer.tls.enabled")
+	viper.Set("security.enabled", true)
+	viper.Set("peer.tls.enabled", true)
+	CacheConfiguration()
+	comm.CacheConfiguration()
+	defer func() {
+		viper.Set("security.enabled", savedSecurity)
+		viper.Set("peer.tls.enabled", savedTLS)
+		CacheConfiguration()
+		comm.CacheConfiguration()
+	}()
+
+	cert := newTestKeyPair(t, "vp0")
+	pool := x509.NewCertPool()
+	parsed, _ := x509.ParseCertificate(cert.Certificate[0])
+	pool.AddCert(parsed)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %s", err)
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    pool,
+	})))
+	// A stopped peer, whose Chat fails right after the client certificate is checked
+	p := &Impl{handlerMap: &handlerMap{m: make(map[pb.PeerID]MessageHandler)}, stopped: make(chan struct{})}
+	p.Stop(time.Now())
+	pb.RegisterPeerServer(server, p)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	chat := func(clientCerts []tls.Certificate) error {
+		creds := credentials.NewTLS(&tls.Config{Certificates: clientCerts, RootCAs: pool})
+		conn, err := comm.NewClientConnectionWithAddress(lis.Addr().String(), true, true, creds)
+		if err != nil {
+			t.Fatalf("Could not connect: %s", err)
+		}
+		defer conn.Close()
+		stream, err := pb.NewPeerClient(conn).Chat(context.Background())
+		if err != nil {
+			t.Fatalf("Could not start Chat: %s", err)
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	if err := chat(nil); err == nil || !strings.Contains(err.Error(), "requires a TLS client certificate") {
+		t.Errorf("Expected a client without a certificate to be refused, got %v", err)
+	}
+	if err := chat([]tls.Certificate{cert}); err == nil || !strings.Contains(err.Error(), "Peer is stopping") {
+		t.Errorf("Expected the certificate of the client to be accepted, got %v", err)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"golang.org/x/net/context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/op/go-logging"
@@ -58,6 +59,7 @@ type BlocksRetriever interface {
 // StateRetriever interface for retrieving state deltas, etc.
 type StateRetriever interface {
 	RequestStateSnapshot() (<-chan *pb.SyncStateSnapshot, error)
+	RequestStateSnapshotChunk(chunk *pb.SyncStateSnapshotChunk) (<-chan *pb.SyncStateSnapshot, error)
 	RequestStateDeltas(syncBlockRange *pb.SyncBlockRange) (<-chan *pb.SyncStateDeltas, error)
 }
 
@@ -87,6 +89,7 @@ type BlockChainModifier interface {
 type BlockChainUtil interface {
 	HashBlock(block *pb.Block) ([]byte, error)
 	VerifyBlockchain(start, finish uint64) (uint64, error)
+	GetStatePartitioning() (statemgmt.PartitionedState, error)
 }
 
 // StateAccessor interface for retreiving blocks by block number
@@ -134,6 +137,24 @@ type SecurityAccessor interface {
 	GetSecHelper() crypto.Peer
 }
 
+// ConsensusStatusReporter is implemented by engines which can report the
+// state of their consensus plugin
+type ConsensusStatusReporter interface {
+	GetConsensusStatus() (*pb.ConsensusStatus, error)
+}
+
+// ChainConsensusStatusReporter is implemented by peers which can report the
+// state of the consensus plugin of each chain they host
+type ChainConsensusStatusReporter interface {
+	GetChainConsensusStatus(chainID string) (*pb.ConsensusStatus, error)
+}
+
+// ValidatorReconfigurer is implemented by engines whose set of validators
+// can change while the network is running
+type ValidatorReconfigurer interface {
+	ReconfigureValidators(req *pb.ValidatorReconfiguration) error
+}
+
 var peerLogger = logging.MustGetLogger("peer")
 
 // NewPeerClientConnection Returns a new grpc.ClientConn to the configured local PEER.
@@ -193,6 +214,16 @@ type Impl struct {
 	reconnectOnce  sync.Once
 	discHelper     discovery.Discovery
 	discPersist    bool
+	gossipOnce     sync.Once
+	reputations    *reputations
+	redials        *redials
+	chains         map[string]*chain
+	forwarder      *txForwarder
+
+	stopLock sync.Mutex
+	stopping bool
+	stopped  chan struct{}  // Closed when the peer stops, its chats and background services end then
+	running  sync.WaitGroup // The chats and the background writes to the database in progress
 }
 
 type TransactionProccesor interface {
@@ -210,13 +241,13 @@ type Engine interface {
 // NewPeerWithHandler returns a Peer which uses the supplied handler factory function for creating new handlers on new Chat service invocations.
 func NewPeerWithHandler(secHelperFunc func() crypto.Peer, handlerFact HandlerFactory) (*Impl, error) {
 	peer := new(Impl)
-	peerNodes := peer.initDiscovery()
 
 	if handlerFact == nil {
 		return nil, errors.New("Cannot supply nil handler factory")
 	}
 	peer.handlerFactory = handlerFact
 	peer.handlerMap = &handlerMap{m: make(map[pb.PeerID]MessageHandler)}
+	peer.stopped = make(chan struct{})
 
 	peer.secHelper = secHelperFunc()
 
@@ -227,12 +258,23 @@ func NewPeerWithHandler(secHelperFunc func() crypto.Peer, handlerFact HandlerFac
 		}
 	}
 
+	peer.initReputations()
+	peer.initRedials()
+	peer.initForwarder()
+
+	// The membership gossip signs with the security object
+	peerNodes := peer.initDiscovery()
+
 	ledgerPtr, err := ledger.GetLedger()
 	if err != nil {
 		return nil, fmt.Errorf("Error constructing NewPeerWithHandler: %s", err)
 	}
 	peer.ledgerWrapper = &ledgerWrapper{ledger: ledgerPtr}
 
+	if err = peer.initChains(nil); err != nil {
+		return nil, fmt.Errorf("Error constructing NewPeerWithHandler: %s", err)
+	}
+
 	peer.chatWithSomePeers(peerNodes)
 	return peer, nil
 }
@@ -240,9 +282,9 @@ func NewPeerWithHandler(secHelperFunc func() crypto.Peer, handlerFact HandlerFac
 // NewPeerWithEngine returns a Peer which uses the supplied handler factory function for creating new handlers on new Chat service invocations.
 func NewPeerWithEngine(secHelperFunc func() crypto.Peer, engFactory EngineFactory) (peer *Impl, err error) {
 	peer = new(Impl)
-	peerNodes := peer.initDiscovery()
 
 	peer.handlerMap = &handlerMap{m: make(map[pb.PeerID]MessageHandler)}
+	peer.stopped = make(chan struct{})
 
 	peer.isValidator = ValidatorEnabled()
 	peer.secHelper = secHelperFunc()
@@ -254,6 +296,13 @@ func NewPeerWithEngine(secHelperFunc func() crypto.Peer, engFactory EngineFactor
 		}
 	}
 
+	peer.initReputations()
+	peer.initRedials()
+	peer.initForwarder()
+
+	// The membership gossip signs with the security object
+	peerNodes := peer.initDiscovery()
+
 	// Initialize the ledger before the engine, as consensus may want to begin interrogating the ledger immediately
 	ledgerPtr, err := ledger.GetLedger()
 	if err != nil {
@@ -270,6 +319,10 @@ func NewPeerWithEngine(secHelperFunc func() crypto.Peer, engFactory EngineFactor
 		return nil, errors.New("Cannot supply nil handler factory")
 	}
 
+	if err = peer.initChains(engFactory); err != nil {
+		return nil, err
+	}
+
 	peer.chatWithSomePeers(peerNodes)
 	return peer, nil
 
@@ -277,7 +330,14 @@ func NewPeerWithEngine(secHelperFunc func() crypto.Peer, engFactory EngineFactor
 
 // Chat implementation of the the Chat bidi streaming RPC function
 func (p *Impl) Chat(stream pb.Peer_ChatServer) error {
-	return p.handleChat(stream.Context(), stream, false)
+	if !peerBindingRequired() {
+		return p.handleChat(stream.Context(), stream, "")
+	}
+	cert := clientCertificate(stream.Context())
+	if cert == nil {
+		return fmt.Errorf("Chat requires a TLS client certificate")
+	}
+	return p.handleChat(stream.Context(), &certifiedChatStream{ChatStream: stream, cert: cert}, "")
 }
 
 // ProcessTransaction implementation of the ProcessTransaction RPC function
@@ -373,6 +433,9 @@ func (p *Impl) RegisterHandler(messageHandler MessageHandler) error {
 	if err != nil {
 		return fmt.Errorf("Error registering handler: %s", err)
 	}
+	if to, _ := messageHandler.To(); p.isBanned(to.Address) {
+		return bannedError{fmt.Errorf("Peer %s (%s) is banned", to.Address, key.Name)}
+	}
 	p.handlerMap.Lock()
 	defer p.handlerMap.Unlock()
 	if _, ok := p.handlerMap.m[*key]; ok == true {
@@ -484,16 +547,9 @@ func (p *Impl) Unicast(msg *pb.Message, receiverHandle *pb.PeerID) error {
 
 // SendTransactionsToPeer forwards transactions to the specified peer address.
 func (p *Impl) SendTransactionsToPeer(peerAddress string, transaction *pb.Transaction) (response *pb.Response) {
-	conn, err := NewPeerClientConnectionWithAddress(peerAddress)
+	response, err := p.sendTransactionToPeer(peerAddress, transaction, 0)
 	if err != nil {
-		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(fmt.Sprintf("Error creating client to peer address=%s:  %s", peerAddress, err))}
-	}
-	defer conn.Close()
-	serverClient := pb.NewPeerClient(conn)
-	peerLogger.Debugf("Sending TX to Peer: %s", peerAddress)
-	response, err = serverClient.ProcessTransaction(context.Background(), transaction)
-	if err != nil {
-		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(fmt.Sprintf("Error calling ProcessTransaction on remote peer at address=%s:  %s", peerAddress, err))}
+		return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(err.Error())}
 	}
 	return response
 }
@@ -525,7 +581,11 @@ func (p *Impl) ensureConnected() {
 	peerLogger.Debugf("Starting Peer reconnect service (touch service), with period = %s", touchPeriod)
 	for {
 		// Simply loop and check if need to reconnect
-		<-tickChan
+		select {
+		case <-tickChan:
+		case <-p.stopped:
+			return
+		}
 		peersMsg, err := p.GetPeers()
 		if err != nil {
 			peerLogger.Errorf("Error in touch service: %s", err.Error())
@@ -543,6 +603,14 @@ func (p *Impl) ensureConnected() {
 		}
 		peerLogger.Debugf("Connected to: %v", getPeerAddresses(peersMsg))
 		peerLogger.Debugf("Discovery knows about: %v", allNodes)
+
+		// Persist the liveness and reputations learned since the last touch
+		if _, ok := p.discHelper.(*discovery.GossipDiscovery); ok && p.begin() {
+			if err := p.StoreDiscoveryList(); err != nil {
+				peerLogger.Errorf("Error in touch service: %s", err)
+			}
+			p.running.Done()
+		}
 	}
 
 }
@@ -567,26 +635,62 @@ func (p *Impl) chatWithSomePeers(addresses []string) {
 			peerLogger.Errorf("Failed to obtain peer endpoint, %v", err)
 			return
 		}
+		if p.isBanned(address) {
+			peerLogger.Debugf("Skipping banned address: %v", address)
+			continue
+		}
+		if !p.redials.due(address) {
+			peerLogger.Debugf("Skipping address failed recently: %v", address)
+			continue
+		}
 		go p.chatWithPeer(address)
 	}
 }
 
 func (p *Impl) chatWithPeer(address string) error {
 	peerLogger.Debugf("Initiating Chat with peer address: %s", address)
-	conn, err := NewPeerClientConnectionWithAddress(address)
+	var conn *grpc.ClientConn
+	var capture *comm.CertCapture
+	var err error
+	if peerBindingRequired() {
+		// Authenticate to the remote peer, and find out who it authenticated as
+		var creds credentials.TransportCredentials
+		if creds, err = comm.InitMutualTLSForPeer(); err == nil {
+			capture = comm.NewCertCapture(creds)
+			conn, err = comm.NewClientConnectionWithAddress(address, true, true, capture)
+		}
+	} else {
+		conn, err = NewPeerClientConnectionWithAddress(address)
+	}
 	if err != nil {
 		peerLogger.Errorf("Error creating connection to peer address %s: %s", address, err)
+		p.chatFailed(address)
 		return err
 	}
+	defer conn.Close()
 	serverClient := pb.NewPeerClient(conn)
-	ctx := context.Background()
+	// The chat ends when the peer stops
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stopped:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 	stream, err := serverClient.Chat(ctx)
 	if err != nil {
 		peerLogger.Errorf("Error establishing chat with peer address %s: %s", address, err)
+		p.chatFailed(address)
 		return err
 	}
 	peerLogger.Debugf("Established Chat with peer address: %s", address)
-	err = p.handleChat(ctx, stream, true)
+	var chatStream ChatStream = stream
+	if capture != nil {
+		chatStream = &certifiedChatStream{ChatStream: stream, cert: capture.PeerCertificate()}
+	}
+	err = p.handleChat(ctx, chatStream, address)
 	stream.CloseSend()
 	if err != nil {
 		peerLogger.Errorf("Ending Chat with peer address %s due to error: %s", address, err)
@@ -598,41 +702,163 @@ func (p *Impl) chatWithPeer(address string) error {
 // Chat implementation of the the Chat bidi streaming RPC function
 //handleChat执行过程中，建立消息循环，而这里的handler.HandleMessage。
 // 这个handler是Engine的消息响应句柄，该消息响应处理来自于Consensus模块
-func (p *Impl) handleChat(ctx context.Context, stream ChatStream, initiatedStream bool) error {
+// The address is the one dialed when this peer initiated the stream, and empty otherwise
+func (p *Impl) handleChat(ctx context.Context, stream ChatStream, address string) error {
+	if !p.begin() {
+		return fmt.Errorf("Peer is stopping, not starting Chat")
+	}
+	defer p.running.Done()
+
 	deadline, ok := ctx.Deadline()
 	peerLogger.Debugf("Current context deadline = %s, ok = %v", deadline, ok)
-	handler, err := p.handlerFactory(p, stream, initiatedStream)
+	handler, err := p.handlerFactory(p, stream, address != "")
 	if err != nil {
 		return fmt.Errorf("Error creating handler during handleChat initiation: %s", err)
 	}
 	defer handler.Stop()
-	for {
-		in, err := stream.Recv()
-		if err == io.EOF {
-			peerLogger.Debug("Received EOF, ending Chat")
-			return nil
+	// A peer dialed which ends the Chat before completing the hello
+	// handshake is dialed again after a backoff
+	handshaked := false
+	if address != "" {
+		defer func() {
+			if !handshaked {
+				p.chatFailed(address)
+			}
+		}()
+	}
+	// Receive in the background, so that the Chat also ends when the handler
+	// stops sending, e.g. when its send queue overflows
+	received := make(chan *pb.Message)
+	recvErr := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case received <- in:
+			case <-stop:
+				return
+			}
 		}
-		if err != nil {
+	}()
+	var closed <-chan struct{}
+	if closer, ok := handler.(ChatCloser); ok {
+		closed = closer.Closed()
+	}
+	for {
+		var in *pb.Message
+		select {
+		case in = <-received:
+		case err := <-recvErr:
+			if err == io.EOF {
+				peerLogger.Debug("Received EOF, ending Chat")
+				return nil
+			}
 			e := fmt.Errorf("Error during Chat, stopping handler: %s", err)
 			peerLogger.Error(e.Error())
 			return e
+		case <-closed:
+			e := fmt.Errorf("Handler stopped sending, ending Chat")
+			peerLogger.Warning(e.Error())
+			return e
+		case <-p.stopped:
+			peerLogger.Debug("Peer is stopping, ending Chat")
+			return nil
+		}
+		in, err = decompressMessage(in)
+		if err == nil {
+			err = handler.HandleMessage(in)
+		}
+		if address != "" && !handshaked {
+			if to, toErr := handler.To(); toErr == nil && p.isRegistered(handler, &to) {
+				handshaked = true
+				p.redials.succeeded(address)
+			}
 		}
-		err = handler.HandleMessage(in)
 		if err != nil {
 			peerLogger.Errorf("Error handling message: %s", err)
+			p.reportChatError(handler, err)
+			switch err.(type) {
+			case identityError, bannedError:
+				return err
+			}
 			//return err
 		}
+		if to, err := handler.To(); err == nil && p.isBanned(to.Address) {
+			return fmt.Errorf("Ending Chat with banned peer %s", to.Address)
+		}
+	}
+}
+
+// begin records the start of a chat or of a background write to the
+// database, unless the peer is stopping. Done is called on p.running once it
+// ends.
+func (p *Impl) begin() bool {
+	p.stopLock.Lock()
+	defer p.stopLock.Unlock()
+	if p.stopping {
+		return false
+	}
+	p.running.Add(1)
+	return true
+}
+
+// Stop ends the chats with the other peers and the background services of
+// the peer, which use the database, and waits until the chats end or the
+// deadline passes. It is called once the peer is drained, before the
+// database is closed.
+func (p *Impl) Stop(deadline time.Time) error {
+	p.stopLock.Lock()
+	if !p.stopping {
+		p.stopping = true
+		close(p.stopped)
+	}
+	p.stopLock.Unlock()
+
+	ended := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(ended)
+	}()
+	select {
+	case <-ended:
+		return nil
+	case <-time.After(deadline.Sub(time.Now())):
+		return fmt.Errorf("Chats with other peers still in progress")
 	}
 }
 
 //ExecuteTransaction executes transactions decides to do execute in dev or prod mode
 // 本次请求被封装成交易Struct，该处理是在PeerServer中
 func (p *Impl) ExecuteTransaction(transaction *pb.Transaction) (response *pb.Response) {
-	if p.isValidator {
-		response = p.sendTransactionsToLocalEngine(transaction)
+	var c *chain
+	if db.NormalizeChainID(transaction.ChainID) != db.DefaultChainID {
+		var ok bool
+		if c, ok = p.chains[transaction.ChainID]; !ok {
+			return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(fmt.Sprintf("Chain %s is not hosted by this peer", transaction.ChainID))}
+		}
+	}
+	if !p.isValidator {
+		// Forward to the validators, failing over until the transaction commits
+		return p.forwarder.submit(transaction)
+	}
+	if transaction.Type != pb.Transaction_CHAINCODE_QUERY && !p.acceptTransaction(transaction) {
+		peerLogger.Debugf("Transaction %s was already accepted", transaction.Txid)
+		return &pb.Response{Status: pb.Response_SUCCESS, Msg: []byte(transaction.Txid)}
+	}
+	if c != nil {
+		response = c.sendTransactionsToLocalEngine(transaction)
 	} else {
-		peerAddresses := p.discHelper.GetRandomNodes(1)
-		response = p.SendTransactionsToPeer(peerAddresses[0], transaction)
+		response = p.sendTransactionsToLocalEngine(transaction)
+	}
+	if transaction.Type != pb.Transaction_CHAINCODE_QUERY && response.Status != pb.Response_SUCCESS {
+		// Let the transaction be submitted again
+		p.forwarder.forget(transaction.Txid)
 	}
 	return response
 }
@@ -647,6 +873,48 @@ func (p *Impl) GetPeerEndpoint() (*pb.PeerEndpoint, error) {
 	return ep, err
 }
 
+// GetConsensusStatus returns a snapshot of the state of the consensus
+// plugin, it is only available on validating peers
+func (p *Impl) GetConsensusStatus() (*pb.ConsensusStatus, error) {
+	reporter, ok := p.engine.(ConsensusStatusReporter)
+	if !ok {
+		return nil, fmt.Errorf("Consensus status is only available on validating peers")
+	}
+	return reporter.GetConsensusStatus()
+}
+
+// GetChainConsensusStatus returns a snapshot of the state of the consensus
+// plugin of a chain hosted by the peer
+func (p *Impl) GetChainConsensusStatus(chainID string) (*pb.ConsensusStatus, error) {
+	coordinator, err := p.GetChainCoordinator(chainID)
+	if err != nil {
+		return nil, err
+	}
+	reporter, ok := coordinator.(ConsensusStatusReporter)
+	if !ok {
+		return nil, fmt.Errorf("Consensus status is not available for chain %s", chainID)
+	}
+	return reporter.GetConsensusStatus()
+}
+
+// ReconfigureValidators orders a change of the set of validators of the
+// chain of the request, it is only available on validating peers
+func (p *Impl) ReconfigureValidators(req *pb.ValidatorReconfiguration) error {
+	engine := p.engine
+	if db.NormalizeChainID(req.ChainID) != db.DefaultChainID {
+		c, ok := p.chains[req.ChainID]
+		if !ok {
+			return fmt.Errorf("Chain %s is not hosted by this peer", req.ChainID)
+		}
+		engine = c.engine
+	}
+	reconfigurer, ok := engine.(ValidatorReconfigurer)
+	if !ok {
+		return fmt.Errorf("Changing the set of validators is only available on validating peers")
+	}
+	return reconfigurer.ReconfigureValidators(req)
+}
+
 func (p *Impl) newHelloMessage() (*pb.HelloMessage, error) {
 	endpoint, err := p.GetPeerEndpoint()
 	if err != nil {
@@ -659,7 +927,7 @@ func (p *Impl) newHelloMessage() (*pb.HelloMessage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Error creating hello message, error getting block chain info: %s", err)
 	}
-	return &pb.HelloMessage{PeerEndpoint: endpoint, BlockchainInfo: blockChainInfo}, nil
+	return &pb.HelloMessage{PeerEndpoint: endpoint, BlockchainInfo: blockChainInfo, Compressions: supportedCompressions()}, nil
 }
 
 // GetBlockByNumber return a block by block number
@@ -696,6 +964,13 @@ func (p *Impl) VerifyBlockchain(start, finish uint64) (uint64, error) {
 	return p.ledgerWrapper.ledger.VerifyChain(start, finish)
 }
 
+// GetStatePartitioning returns how the state is split into partitions when it is transferred
+func (p *Impl) GetStatePartitioning() (statemgmt.PartitionedState, error) {
+	p.ledgerWrapper.RLock()
+	defer p.ledgerWrapper.RUnlock()
+	return p.ledgerWrapper.ledger.GetStatePartitioning()
+}
+
 // ApplyStateDelta applies a state delta to the current state
 // The result of this function can be retrieved using GetCurrentStateDelta
 // To commit the result, call CommitStateDelta, or to roll it back
@@ -789,27 +1064,80 @@ func (p *Impl) signMessageMutating(msg *pb.Message) error {
 
 // initDiscovery load the addresses from the discovery list previously saved to disk and adds them to the current discovery list
 func (p *Impl) initDiscovery() []string {
-	p.discHelper = discovery.NewDiscoveryImpl()
 	p.discPersist = viper.GetBool("peer.discovery.persist")
 	if !p.discPersist {
 		peerLogger.Warning("Discovery list will not be persisted to disk")
 	}
-	addresses, err := p.LoadDiscoveryList() // load any previously saved addresses
-	if err != nil {
-		peerLogger.Errorf("%s", err)
-	}
-	for _, address := range addresses { // add them to the current discovery list
-		_ = p.discHelper.AddNode(address)
+
+	var addresses []string
+	if viper.GetBool("peer.discovery.gossip.enabled") {
+		addresses = p.initGossipDiscovery()
+	} else {
+		p.discHelper = discovery.NewDiscoveryImpl()
+		var err error
+		addresses, err = p.LoadDiscoveryList() // load any previously saved addresses
+		if err != nil {
+			peerLogger.Errorf("%s", err)
+		}
+		for _, address := range addresses { // add them to the current discovery list
+			_ = p.discHelper.AddNode(address)
+		}
 	}
 	peerLogger.Debugf("Retrieved discovery list from disk: %v", addresses)
 	// parse the config file, ENV flags, etc.
-	rootNodes := strings.Split(viper.GetString("peer.discovery.rootnode"), ",")
-	if !(len(rootNodes) == 1 && strings.Compare(rootNodes[0], "") == 0) {
+	rootNodes := getRootNodes(viper.GetString("peer.discovery.rootnode"))
+	if len(rootNodes) > 0 {
 		addresses = append(rootNodes, p.discHelper.GetAllNodes()...)
 	}
 	return addresses
 }
 
+// getRootNodes parses the comma separated list of peer.discovery.rootnode
+func getRootNodes(rootNodes string) []string {
+	var addresses []string
+	for _, address := range strings.Split(rootNodes, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// ValidateRootNodes checks the peer.discovery.rootnode of a new configuration
+func ValidateRootNodes(v *viper.Viper) error {
+	for _, address := range getRootNodes(v.GetString("peer.discovery.rootnode")) {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return fmt.Errorf("Invalid root node %s: %s", address, err)
+		}
+	}
+	return nil
+}
+
+// ReloadRootNodes starts chatting with the root nodes of the configuration
+// which are not in the discovery list yet. The peers connected through the
+// root nodes removed from the configuration stay connected.
+func (p *Impl) ReloadRootNodes() error {
+	var added []string
+	for _, address := range getRootNodes(viper.GetString("peer.discovery.rootnode")) {
+		if p.discHelper.FindNode(address) {
+			continue
+		}
+		if !p.discHelper.AddNode(address) {
+			return fmt.Errorf("Unable to add root node %s to discovery list", address)
+		}
+		added = append(added, address)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	peerLogger.Infof("Connecting to new root nodes %v", added)
+	if err := p.StoreDiscoveryList(); err != nil {
+		peerLogger.Error(err)
+	}
+	p.chatWithSomePeers(added)
+	return nil
+}
+
 // =============================================================================
 // Persistor
 // =============================================================================
@@ -864,6 +1192,9 @@ func (p *Impl) StoreDiscoveryList() error {
 	if !p.discPersist {
 		return nil
 	}
+	if gd, ok := p.discHelper.(*discovery.GossipDiscovery); ok {
+		return p.storeMembership(gd)
+	}
 	var err error
 	addresses := p.discHelper.GetAllNodes()
 	raw, err := proto.Marshal(&pb.PeersAddresses{Addresses: addresses})
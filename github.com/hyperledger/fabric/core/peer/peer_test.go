@@ -55,6 +55,27 @@ func TestMissingMessageHandlerUnicast(t *testing.T) {
 	}
 }
 
+func TestStopWaitsForChats(t *testing.T) {
+	p := &Impl{handlerMap: &handlerMap{m: make(map[pb.PeerID]MessageHandler)}, stopped: make(chan struct{})}
+	if !p.begin() {
+		t.Fatalf("Expected a chat to start while the peer runs")
+	}
+	if err := p.Stop(time.Now().Add(10 * time.Millisecond)); err == nil {
+		t.Fatalf("Expected an error when a chat does not end before the deadline")
+	}
+	if p.begin() {
+		t.Fatalf("Expected no chat to start once the peer stops")
+	}
+	if err := p.handleChat(context.Background(), nil, ""); err == nil {
+		t.Fatalf("Expected no chat to be handled once the peer stops")
+	}
+
+	p.running.Done()
+	if err := p.Stop(time.Now().Add(time.Minute)); err != nil {
+		t.Errorf("Error stopping peer: %s", err)
+	}
+}
+
 func performChat(t testing.TB, conn *grpc.ClientConn) error {
 	serverClient := pb.NewPeerClient(conn)
 	stream, err := serverClient.Chat(context.Background())
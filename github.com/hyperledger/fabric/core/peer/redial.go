@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// redialRecord is the failures to chat with a remote peer this peer dialed
+type redialRecord struct {
+	failures uint32    // The number of consecutive failures
+	next     time.Time // When the peer is dialed again at the earliest
+}
+
+// redials delays dialing again the peers which could not be connected to,
+// timed out or failed the hello handshake, with an exponential backoff. The
+// failures are not scored as offenses: the peers are not banned, and their
+// connections are still accepted.
+type redials struct {
+	sync.Mutex
+	backoff    time.Duration
+	maxBackoff time.Duration
+	records    map[string]*redialRecord
+	now        func() time.Time
+}
+
+func newRedials(backoff, maxBackoff time.Duration) *redials {
+	return &redials{
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		records:    make(map[string]*redialRecord),
+		now:        time.Now,
+	}
+}
+
+// failed records a failure to chat with the peer at address, and returns
+// how long until it is dialed again
+func (r *redials) failed(address string) time.Duration {
+	if r == nil {
+		return 0
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	rec, ok := r.records[address]
+	if !ok {
+		rec = &redialRecord{}
+		r.records[address] = rec
+	}
+	backoff := r.backoff
+	for i := uint32(0); i < rec.failures && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.maxBackoff {
+		backoff = r.maxBackoff
+	}
+	rec.failures++
+	rec.next = r.now().Add(backoff)
+	return backoff
+}
+
+// succeeded forgets the failures of the peer at address, once it completed
+// the hello handshake
+func (r *redials) succeeded(address string) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	delete(r.records, address)
+}
+
+// due returns whether the peer at address may be dialed
+func (r *redials) due(address string) bool {
+	if r == nil {
+		return true
+	}
+	r.Lock()
+	defer r.Unlock()
+
+	rec, ok := r.records[address]
+	return !ok || !r.now().Before(rec.next)
+}
+
+// initRedials enables the backoff of the peers this peer fails to chat with
+func (p *Impl) initRedials() {
+	backoff := viper.GetDuration("peer.discovery.redialBackoff")
+	maxBackoff := viper.GetDuration("peer.discovery.maxRedialBackoff")
+	if backoff <= 0 {
+		return
+	}
+	if maxBackoff < backoff {
+		panic(fmt.Errorf("Invalid peer.discovery configuration: redialBackoff %v, maxRedialBackoff %v", backoff, maxBackoff))
+	}
+	p.redials = newRedials(backoff, maxBackoff)
+}
+
+// chatFailed delays dialing again the peer at address
+func (p *Impl) chatFailed(address string) {
+	if backoff := p.redials.failed(address); backoff > 0 {
+		peerLogger.Debugf("Dialing peer %s again in %s at the earliest", address, backoff)
+	}
+}
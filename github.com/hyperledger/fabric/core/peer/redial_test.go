@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedialBackoff(t *testing.T) {
+	now := time.Now()
+	r := newRedials(time.Second, 5*time.Second)
+	r.now = func() time.Time { return now }
+
+	if !r.due("vp1:7051") {
+		t.Fatalf("Expected a peer which never failed to be dialed")
+	}
+	for i, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if backoff := r.failed("vp1:7051"); backoff != expected {
+			t.Fatalf("Expected a backoff of %v after %d failures, got %v", expected, i+1, backoff)
+		}
+		now = now.Add(expected - time.Millisecond)
+		if r.due("vp1:7051") {
+			t.Fatalf("Expected the peer not to be dialed during the backoff after %d failures", i+1)
+		}
+		if !r.due("vp2:7051") {
+			t.Fatalf("Expected only the failing peer to be backed off")
+		}
+		now = now.Add(time.Millisecond)
+		if !r.due("vp1:7051") {
+			t.Fatalf("Expected the peer to be dialed after the backoff after %d failures", i+1)
+		}
+	}
+
+	r.succeeded("vp1:7051")
+	if backoff := r.failed("vp1:7051"); backoff != time.Second {
+		t.Errorf("Expected the backoff to restart once the peer completed the handshake, got %v", backoff)
+	}
+}
+
+func TestRedialBackoffDisabled(t *testing.T) {
+	var r *redials
+	if r.failed("vp1:7051") != 0 || !r.due("vp1:7051") {
+		t.Errorf("Expected peers to be dialed again right away without backoff")
+	}
+}
@@ -0,0 +1,352 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/spf13/viper"
+
+	"github.com/hyperledger/fabric/core/discovery"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+const bansKey = "bans"
+
+// Offense is a kind of misbehavior of a remote peer. Only the misbehavior of
+// authenticated peers is scored: failures to connect, timeouts and failed
+// handshakes may as well be caused by the network, or by a peer impersonating
+// another, and scoring them would let anyone get honest peers banned. The
+// peers which fail that way are dialed again after a backoff instead, see
+// redials.
+type Offense int
+
+const (
+	OffenseProtocolViolation Offense = iota // The peer sent a malformed or unexpected message
+	OffenseInvalidData                      // The peer served data or signatures which did not verify
+)
+
+var offenseNames = map[Offense]string{
+	OffenseProtocolViolation: "protocol violation",
+	OffenseInvalidData:       "invalid data",
+}
+
+func (o Offense) String() string {
+	if name, ok := offenseNames[o]; ok {
+		return name
+	}
+	return fmt.Sprintf("offense %d", int(o))
+}
+
+// score is what an offense adds to the score of a peer, a peer whose score
+// reaches peer.reputation.threshold is banned
+func (o Offense) score() int {
+	switch o {
+	case OffenseProtocolViolation:
+		return 20
+	default:
+		return 50
+	}
+}
+
+// MisbehaviorReporter is implemented by coordinators which keep track of
+// the misbehavior of remote peers, and ban the peers which misbehave repeatedly
+type MisbehaviorReporter interface {
+	ReportMisbehavior(peerID *pb.PeerID, offense Offense, reason string)
+}
+
+// BanListReporter is implemented by peers which can report the remote peers
+// they banned
+type BanListReporter interface {
+	GetBannedPeers() (*pb.BannedPeers, error)
+}
+
+// LocalError is returned by message handlers for errors which are not the
+// fault of the remote peer, such as a full queue, they are not scored against it
+type LocalError struct {
+	Err error
+}
+
+func (e LocalError) Error() string {
+	return e.Err.Error()
+}
+
+// bannedError is returned when a banned peer says hello, the Chat stream with it is ended
+type bannedError struct {
+	error
+}
+
+// peerRecord is the misbehavior of a remote peer
+type peerRecord struct {
+	name        string    // The PeerID name of the peer, if known
+	score       int       // The score of the recent offenses, halved every decay period
+	scored      time.Time // When the score was last raised
+	bans        uint32    // The number of consecutive bans, each lasts twice as long as the previous one
+	bannedUntil time.Time // The end of the last ban
+	reason      string    // The offense which caused the last ban
+}
+
+// reputations scores the offenses of the remote peers by address, and bans
+// the peers whose score reaches a threshold with an exponential backoff
+type reputations struct {
+	sync.Mutex
+	threshold      int
+	decay          time.Duration
+	banDuration    time.Duration
+	maxBanDuration time.Duration
+	records        map[string]*peerRecord
+	now            func() time.Time
+}
+
+func newReputations(threshold int, decay, banDuration, maxBanDuration time.Duration) *reputations {
+	return &reputations{
+		threshold:      threshold,
+		decay:          decay,
+		banDuration:    banDuration,
+		maxBanDuration: maxBanDuration,
+		records:        make(map[string]*peerRecord),
+		now:            time.Now,
+	}
+}
+
+// report scores an offense of the peer at address, and returns whether it
+// got the peer banned
+func (r *reputations) report(address, name string, offense Offense) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now()
+	rec, ok := r.records[address]
+	if !ok {
+		rec = &peerRecord{}
+		r.records[address] = rec
+	}
+	if name != "" {
+		rec.name = name
+	}
+	if now.Before(rec.bannedUntil) {
+		return false
+	}
+	// A peer which behaved for as long as the longest ban since its last ban starts over
+	if rec.bans > 0 && now.Sub(rec.bannedUntil) > r.maxBanDuration {
+		rec.bans = 0
+	}
+	if periods := now.Sub(rec.scored) / r.decay; periods > 0 {
+		rec.score >>= uint(periods)
+	}
+	rec.score += offense.score()
+	rec.scored = now
+	if rec.score < r.threshold {
+		return false
+	}
+
+	duration := r.banDuration
+	for i := uint32(0); i < rec.bans && duration < r.maxBanDuration; i++ {
+		duration *= 2
+	}
+	if duration > r.maxBanDuration {
+		duration = r.maxBanDuration
+	}
+	rec.bans++
+	rec.bannedUntil = now.Add(duration)
+	rec.score = 0
+	rec.reason = offense.String()
+	return true
+}
+
+// isBanned returns whether the peer at address is banned
+func (r *reputations) isBanned(address string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	rec, ok := r.records[address]
+	return ok && r.now().Before(rec.bannedUntil)
+}
+
+// addressOf returns the address of a peer by its PeerID name, if it ever misbehaved
+func (r *reputations) addressOf(name string) (string, bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	for address, rec := range r.records {
+		if rec.name == name {
+			return address, true
+		}
+	}
+	return "", false
+}
+
+// banned returns the peers which are banned, or also the peers whose bans
+// expired but still count towards the duration of their next ban
+func (r *reputations) banned(expired bool) *pb.BannedPeers {
+	r.Lock()
+	defer r.Unlock()
+
+	now := r.now()
+	bans := &pb.BannedPeers{}
+	for address, rec := range r.records {
+		if rec.bans == 0 || (!expired && !now.Before(rec.bannedUntil)) {
+			continue
+		}
+		bans.Peers = append(bans.Peers, &pb.BannedPeer{
+			Address: address,
+			Name:    rec.name,
+			Bans:    rec.bans,
+			Until:   &timestamp.Timestamp{Seconds: rec.bannedUntil.Unix(), Nanos: int32(rec.bannedUntil.Nanosecond())},
+			Reason:  rec.reason,
+		})
+	}
+	sort.Sort(bannedPeersByAddress(bans.Peers))
+	return bans
+}
+
+// restore reinstates the bans persisted by a previous run
+func (r *reputations) restore(bans *pb.BannedPeers) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, ban := range bans.Peers {
+		rec := &peerRecord{name: ban.Name, bans: ban.Bans, reason: ban.Reason}
+		if ban.Until != nil {
+			rec.bannedUntil = time.Unix(ban.Until.Seconds, int64(ban.Until.Nanos))
+		}
+		r.records[ban.Address] = rec
+	}
+}
+
+type bannedPeersByAddress []*pb.BannedPeer
+
+func (a bannedPeersByAddress) Len() int           { return len(a) }
+func (a bannedPeersByAddress) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a bannedPeersByAddress) Less(i, j int) bool { return a[i].Address < a[j].Address }
+
+// initReputations enables the banning of misbehaving peers, and restores
+// the bans persisted by a previous run
+func (p *Impl) initReputations() {
+	if !viper.GetBool("peer.reputation.enabled") {
+		return
+	}
+	threshold := viper.GetInt("peer.reputation.threshold")
+	decay := viper.GetDuration("peer.reputation.decay")
+	banDuration := viper.GetDuration("peer.reputation.banDuration")
+	maxBanDuration := viper.GetDuration("peer.reputation.maxBanDuration")
+	if threshold <= 0 || decay <= 0 || banDuration <= 0 || maxBanDuration < banDuration {
+		panic(fmt.Errorf("Invalid peer.reputation configuration: threshold %d, decay %v, banDuration %v, maxBanDuration %v", threshold, decay, banDuration, maxBanDuration))
+	}
+	p.reputations = newReputations(threshold, decay, banDuration, maxBanDuration)
+
+	raw, err := p.Load(bansKey)
+	if err != nil {
+		peerLogger.Errorf("Unable to load bans from DB: %s", err)
+		return
+	}
+	if raw == nil {
+		return
+	}
+	bans := &pb.BannedPeers{}
+	if err := proto.Unmarshal(raw, bans); err != nil {
+		peerLogger.Errorf("Could not unmarshal bans: %s", err)
+		return
+	}
+	p.reputations.restore(bans)
+}
+
+// ReportMisbehavior scores an offense of a remote peer, and bans the peer
+// if it misbehaved repeatedly
+func (p *Impl) ReportMisbehavior(peerID *pb.PeerID, offense Offense, reason string) {
+	if p.reputations == nil || peerID == nil {
+		return
+	}
+	if handler, err := p.getMessageHandler(peerID); err == nil {
+		if to, err := handler.To(); err == nil {
+			p.reportMisbehavior(to.Address, peerID.Name, offense, reason)
+			return
+		}
+	}
+	if address, ok := p.reputations.addressOf(peerID.Name); ok {
+		p.reportMisbehavior(address, peerID.Name, offense, reason)
+		return
+	}
+	peerLogger.Debugf("Ignoring %s of %v, which is no longer connected: %s", offense, peerID, reason)
+}
+
+func (p *Impl) reportMisbehavior(address, name string, offense Offense, reason string) {
+	if p.reputations == nil {
+		return
+	}
+	peerLogger.Debugf("Peer %s (%s) misbehaved, %s: %s", address, name, offense, reason)
+	if gd, ok := p.discHelper.(*discovery.GossipDiscovery); ok {
+		gd.AdjustReputation(address, -offense.score())
+	}
+	if !p.reputations.report(address, name, offense) {
+		return
+	}
+	peerLogger.Warningf("Banning peer %s (%s) after its %s: %s", address, name, offense, reason)
+	if err := p.storeBans(); err != nil {
+		peerLogger.Errorf("%s", err)
+	}
+}
+
+// reportChatError scores an error handling a message of a Chat stream
+// against the remote peer. Until the peer completed the hello handshake, the
+// identity it announced is not authenticated, and the error is not scored.
+func (p *Impl) reportChatError(handler MessageHandler, err error) {
+	switch err.(type) {
+	case bannedError, LocalError:
+		return
+	}
+	if to, toErr := handler.To(); toErr == nil && p.isRegistered(handler, &to) {
+		p.reportMisbehavior(to.Address, to.ID.Name, OffenseProtocolViolation, err.Error())
+	}
+}
+
+// isRegistered returns whether the handler completed the hello handshake
+func (p *Impl) isRegistered(handler MessageHandler, to *pb.PeerEndpoint) bool {
+	if to.ID == nil {
+		return false
+	}
+	registered, err := p.getMessageHandler(to.ID)
+	return err == nil && registered == handler
+}
+
+// isBanned returns whether the peer at address is banned
+func (p *Impl) isBanned(address string) bool {
+	return p.reputations != nil && p.reputations.isBanned(address)
+}
+
+// GetBannedPeers returns the remote peers which are banned for misbehaving
+func (p *Impl) GetBannedPeers() (*pb.BannedPeers, error) {
+	if p.reputations == nil {
+		return &pb.BannedPeers{}, nil
+	}
+	return p.reputations.banned(false), nil
+}
+
+// storeBans persists the bans, with the expired ones which still count
+// towards the duration of the next ban of a peer
+func (p *Impl) storeBans() error {
+	raw, err := proto.Marshal(p.reputations.banned(true))
+	if err != nil {
+		return fmt.Errorf("Could not marshal bans: %s", err)
+	}
+	return p.Store(bansKey, raw)
+}
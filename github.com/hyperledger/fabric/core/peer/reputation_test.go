@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func newTestReputations(now *time.Time) *reputations {
+	r := newReputations(100, time.Minute, time.Minute, 10*time.Minute)
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestReputationBansAtThreshold(t *testing.T) {
+	now := time.Now()
+	r := newTestReputations(&now)
+
+	for i := 0; i < 4; i++ {
+		if r.report("vp1:7051", "vp1", OffenseProtocolViolation) {
+			t.Fatalf("Expected the peer not to be banned after %d violations", i+1)
+		}
+	}
+	if r.isBanned("vp1:7051") {
+		t.Fatalf("Expected the peer not to be banned below the threshold")
+	}
+	if !r.report("vp1:7051", "vp1", OffenseProtocolViolation) || !r.isBanned("vp1:7051") {
+		t.Fatalf("Expected the peer to be banned at the threshold")
+	}
+	if r.isBanned("vp2:7051") {
+		t.Errorf("Expected only the misbehaving peer to be banned")
+	}
+	if r.report("vp1:7051", "vp1", OffenseInvalidData) {
+		t.Errorf("Expected the offenses of a banned peer not to ban it again")
+	}
+
+	bans := r.banned(false)
+	if len(bans.Peers) != 1 || bans.Peers[0].Address != "vp1:7051" || bans.Peers[0].Name != "vp1" || bans.Peers[0].Bans != 1 {
+		t.Fatalf("Expected the peer to be listed as banned, got %v", bans)
+	}
+
+	now = now.Add(time.Minute)
+	if r.isBanned("vp1:7051") || len(r.banned(false).Peers) != 0 {
+		t.Errorf("Expected the ban to expire")
+	}
+}
+
+func TestReputationScoreDecays(t *testing.T) {
+	now := time.Now()
+	r := newTestReputations(&now)
+
+	r.report("vp1:7051", "", OffenseInvalidData)
+	now = now.Add(time.Minute)
+	if r.report("vp1:7051", "", OffenseInvalidData) {
+		t.Fatalf("Expected the score to halve after the decay period")
+	}
+	if !r.report("vp1:7051", "", OffenseInvalidData) {
+		t.Fatalf("Expected the peer to be banned once its score reaches the threshold again")
+	}
+}
+
+func TestReputationBanBackoff(t *testing.T) {
+	now := time.Now()
+	r := newTestReputations(&now)
+
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute} {
+		r.report("vp1:7051", "", OffenseInvalidData)
+		r.report("vp1:7051", "", OffenseInvalidData)
+		if until := r.records["vp1:7051"].bannedUntil; until.Sub(now) != expected {
+			t.Fatalf("Expected a ban of %v, got %v", expected, until.Sub(now))
+		}
+		now = r.records["vp1:7051"].bannedUntil
+	}
+
+	// A peer which behaved long enough starts over
+	now = now.Add(11 * time.Minute)
+	r.report("vp1:7051", "", OffenseInvalidData)
+	r.report("vp1:7051", "", OffenseInvalidData)
+	if rec := r.records["vp1:7051"]; rec.bans != 1 || rec.bannedUntil.Sub(now) != time.Minute {
+		t.Errorf("Expected the backoff to be reset, got %d bans until %v", rec.bans, rec.bannedUntil.Sub(now))
+	}
+}
+
+func TestReputationRestore(t *testing.T) {
+	now := time.Now()
+	r := newTestReputations(&now)
+	r.report("vp1:7051", "vp1", OffenseInvalidData)
+	r.report("vp1:7051", "vp1", OffenseInvalidData)
+	r.report("vp2:7051", "vp2", OffenseInvalidData)
+	r.report("vp2:7051", "vp2", OffenseInvalidData)
+	now = now.Add(time.Minute)
+	r.report("vp1:7051", "vp1", OffenseInvalidData)
+	r.report("vp1:7051", "vp1", OffenseInvalidData)
+
+	raw, err := proto.Marshal(r.banned(true))
+	if err != nil {
+		t.Fatalf("Could not marshal the bans: %s", err)
+	}
+	bans := &pb.BannedPeers{}
+	if err := proto.Unmarshal(raw, bans); err != nil {
+		t.Fatalf("Could not unmarshal the bans: %s", err)
+	}
+	if len(bans.Peers) != 2 {
+		t.Fatalf("Expected the expired ban to be persisted for the backoff, got %v", bans)
+	}
+
+	restored := newTestReputations(&now)
+	restored.restore(bans)
+	if !restored.isBanned("vp1:7051") || restored.isBanned("vp2:7051") {
+		t.Errorf("Expected only the ban of vp1 to be in force after the restore")
+	}
+	if address, ok := restored.addressOf("vp2"); !ok || address != "vp2:7051" {
+		t.Errorf("Expected the address of vp2 to be restored, got %s", address)
+	}
+	restored.report("vp2:7051", "vp2", OffenseInvalidData)
+	restored.report("vp2:7051", "vp2", OffenseInvalidData)
+	if rec := restored.records["vp2:7051"]; rec.bans != 2 || rec.bannedUntil.Sub(now) != 2*time.Minute {
+		t.Errorf("Expected the restored bans to count towards the backoff, got %d bans until %v", rec.bans, rec.bannedUntil.Sub(now))
+	}
+}
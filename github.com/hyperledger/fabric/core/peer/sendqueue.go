@@ -0,0 +1,226 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// DefaultSendQueueSize is the number of messages queued to a peer by default
+const DefaultSendQueueSize = 1000
+
+// overflowPolicy is what a handler does with a message when the send queue
+// to its peer is full
+type overflowPolicy int
+
+const (
+	// overflowBlock waits until the queue has room for the message
+	overflowBlock overflowPolicy = iota
+	// overflowDrop drops the message
+	overflowDrop
+	// overflowDisconnect drops the message and disconnects from the peer
+	overflowDisconnect
+)
+
+func parseOverflowPolicy(policy string) (overflowPolicy, error) {
+	switch policy {
+	case "", "block":
+		return overflowBlock, nil
+	case "drop":
+		return overflowDrop, nil
+	case "disconnect":
+		return overflowDisconnect, nil
+	}
+	return 0, fmt.Errorf("Invalid send queue overflow policy %q, must be block, drop or disconnect", policy)
+}
+
+// sendQueue is the bounded queue of the messages to a peer. The messages are
+// compressed and sent to the stream by a single goroutine, so that a slow
+// peer only holds up as many messages as the queue holds.
+type sendQueue struct {
+	// The counters are first to be 64-bit aligned for atomic access
+	messagesSent    uint64
+	bytesSent       uint64
+	messagesDropped uint64
+
+	messages    chan *pb.Message
+	policy      overflowPolicy
+	send        func(*pb.Message) error
+	minSize     int
+	compression int32 // pb.Compression, set once the peers said hello
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	closeErr  error
+}
+
+func newSendQueue(size int, policy overflowPolicy, minSize int, send func(*pb.Message) error) *sendQueue {
+	q := &sendQueue{
+		messages: make(chan *pb.Message, size),
+		policy:   policy,
+		send:     send,
+		minSize:  minSize,
+		closed:   make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// newSendQueueFromConfig returns a send queue configured by peer.sendQueue
+func newSendQueueFromConfig(send func(*pb.Message) error) (*sendQueue, error) {
+	size := viper.GetInt("peer.sendQueue.size")
+	if size <= 0 {
+		size = DefaultSendQueueSize
+	}
+	policy, err := parseOverflowPolicy(viper.GetString("peer.sendQueue.overflow"))
+	if err != nil {
+		return nil, err
+	}
+	return newSendQueue(size, policy, compressionMinSize(), send), nil
+}
+
+// ValidateSendQueue checks the peer.sendQueue of a new configuration. The
+// send queue and compression settings apply to the connections established
+// after they change.
+func ValidateSendQueue(v *viper.Viper) error {
+	_, err := parseOverflowPolicy(v.GetString("peer.sendQueue.overflow"))
+	return err
+}
+
+// setCompression sets the compression of the messages sent from now on
+func (q *sendQueue) setCompression(compression pb.Compression) {
+	atomic.StoreInt32(&q.compression, int32(compression))
+}
+
+func (q *sendQueue) getCompression() pb.Compression {
+	return pb.Compression(atomic.LoadInt32(&q.compression))
+}
+
+// enqueue queues a message to send, applying the overflow policy if the queue
+// is full. It returns an error if the message will not be sent.
+func (q *sendQueue) enqueue(msg *pb.Message) error {
+	select {
+	case <-q.closed:
+		return q.closeErr
+	default:
+	}
+	select {
+	case q.messages <- msg:
+		return nil
+	default:
+	}
+	switch q.policy {
+	case overflowBlock:
+		select {
+		case q.messages <- msg:
+			return nil
+		case <-q.closed:
+			return q.closeErr
+		}
+	case overflowDrop:
+		atomic.AddUint64(&q.messagesDropped, 1)
+		return fmt.Errorf("Send queue is full, dropped %s message", msg.Type)
+	default:
+		atomic.AddUint64(&q.messagesDropped, 1)
+		err := fmt.Errorf("Send queue is full, disconnecting")
+		q.close(err)
+		return err
+	}
+}
+
+// close stops sending, the messages still queued are dropped
+func (q *sendQueue) close(err error) {
+	q.closeOnce.Do(func() {
+		q.closeErr = err
+		close(q.closed)
+	})
+}
+
+// run sends the queued messages until the queue is closed or sending fails
+func (q *sendQueue) run() {
+	for {
+		select {
+		case msg := <-q.messages:
+			compressed, err := compressMessage(msg, q.getCompression(), q.minSize)
+			if err == nil {
+				err = q.send(compressed)
+			}
+			if err != nil {
+				peerLogger.Errorf("Error sending %s message, closing send queue: %s", msg.Type, err)
+				q.close(err)
+				return
+			}
+			atomic.AddUint64(&q.messagesSent, 1)
+			atomic.AddUint64(&q.bytesSent, uint64(proto.Size(compressed)))
+		case <-q.closed:
+			return
+		}
+	}
+}
+
+// traffic returns the state of the queue and what was sent through it
+func (q *sendQueue) traffic() *pb.PeerTraffic {
+	return &pb.PeerTraffic{
+		Compression:     q.getCompression(),
+		QueueDepth:      uint32(len(q.messages)),
+		QueueCapacity:   uint32(cap(q.messages)),
+		MessagesSent:    atomic.LoadUint64(&q.messagesSent),
+		BytesSent:       atomic.LoadUint64(&q.bytesSent),
+		MessagesDropped: atomic.LoadUint64(&q.messagesDropped),
+	}
+}
+
+// ChatCloser is implemented by handlers which may end the Chat with their
+// peer, for instance when the send queue to the peer overflows
+type ChatCloser interface {
+	// Closed returns a channel closed when the Chat should end
+	Closed() <-chan struct{}
+}
+
+// TrafficReporter is implemented by handlers which can report the traffic
+// to their peer
+type TrafficReporter interface {
+	Traffic() *pb.PeerTraffic
+}
+
+// PeersTrafficReporter is implemented by peers which can report the traffic
+// to the peers they are connected to
+type PeersTrafficReporter interface {
+	GetPeersTraffic() (*pb.PeersTraffic, error)
+}
+
+// GetPeersTraffic returns the traffic to the connected peers
+func (p *Impl) GetPeersTraffic() (*pb.PeersTraffic, error) {
+	p.handlerMap.RLock()
+	defer p.handlerMap.RUnlock()
+	traffic := &pb.PeersTraffic{}
+	for _, msgHandler := range p.handlerMap.m {
+		reporter, ok := msgHandler.(TrafficReporter)
+		if !ok {
+			continue
+		}
+		traffic.Peers = append(traffic.Peers, reporter.Traffic())
+	}
+	return traffic, nil
+}
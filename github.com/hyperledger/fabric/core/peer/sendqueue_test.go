@@ -0,0 +1,154 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peer
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// blockedStream is a stream which sends once it is released
+type blockedStream struct {
+	release chan struct{}
+	sent    chan *pb.Message
+}
+
+func newBlockedStream() *blockedStream {
+	return &blockedStream{release: make(chan struct{}), sent: make(chan *pb.Message, 100)}
+}
+
+func (s *blockedStream) send(msg *pb.Message) error {
+	<-s.release
+	s.sent <- msg
+	return nil
+}
+
+// fill queues messages until the queue of the given size is full, while the
+// first one is being sent
+func fill(t *testing.T, q *sendQueue, size int) {
+	for i := 0; i <= size; i++ {
+		if err := q.enqueue(&pb.Message{Type: pb.Message_CONSENSUS}); err != nil {
+			t.Fatalf("Error queueing message %d: %s", i, err)
+		}
+		// Wait for the first message to be picked up by the sender
+		for i == 0 && len(q.messages) != 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestSendQueueDrops(t *testing.T) {
+	s := newBlockedStream()
+	q := newSendQueue(2, overflowDrop, 1024, s.send)
+	defer q.close(fmt.Errorf("Test is done"))
+	fill(t, q, 2)
+
+	if err := q.enqueue(&pb.Message{Type: pb.Message_CONSENSUS}); err == nil {
+		t.Fatalf("Expected the message to be dropped")
+	}
+	traffic := q.traffic()
+	if traffic.MessagesDropped != 1 || traffic.QueueDepth != 2 || traffic.QueueCapacity != 2 {
+		t.Errorf("Unexpected traffic %v", traffic)
+	}
+
+	close(s.release)
+	for i := 0; i < 3; i++ {
+		<-s.sent
+	}
+	select {
+	case <-q.closed:
+		t.Errorf("Expected the queue to stay open when dropping messages")
+	default:
+	}
+}
+
+func TestSendQueueDisconnects(t *testing.T) {
+	s := newBlockedStream()
+	q := newSendQueue(2, overflowDisconnect, 1024, s.send)
+	fill(t, q, 2)
+
+	if err := q.enqueue(&pb.Message{Type: pb.Message_CONSENSUS}); err == nil {
+		t.Fatalf("Expected the message to be dropped")
+	}
+	select {
+	case <-q.closed:
+	default:
+		t.Fatalf("Expected the queue to be closed when it overflows")
+	}
+	if err := q.enqueue(&pb.Message{Type: pb.Message_CONSENSUS}); err == nil {
+		t.Errorf("Expected no message to be queued once the queue is closed")
+	}
+	close(s.release)
+}
+
+func TestSendQueueBlocks(t *testing.T) {
+	s := newBlockedStream()
+	q := newSendQueue(2, overflowBlock, 1024, s.send)
+	defer q.close(fmt.Errorf("Test is done"))
+	fill(t, q, 2)
+
+	queued := make(chan error)
+	go func() {
+		queued <- q.enqueue(&pb.Message{Type: pb.Message_CONSENSUS})
+	}()
+	select {
+	case <-queued:
+		t.Fatalf("Expected queueing to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(s.release)
+	if err := <-queued; err != nil {
+		t.Fatalf("Error queueing message: %s", err)
+	}
+	for i := 0; i < 4; i++ {
+		<-s.sent
+	}
+}
+
+func TestSendQueueCompresses(t *testing.T) {
+	s := newBlockedStream()
+	close(s.release)
+	q := newSendQueue(2, overflowBlock, 1024, s.send)
+	defer q.close(fmt.Errorf("Test is done"))
+
+	payload := bytes.Repeat([]byte("block"), 1000)
+	q.enqueue(&pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: payload})
+	if sent := <-s.sent; sent.Compression != pb.Compression_NONE {
+		t.Fatalf("Expected no compression before it is negotiated")
+	}
+
+	q.setCompression(pb.Compression_GZIP)
+	q.enqueue(&pb.Message{Type: pb.Message_SYNC_BLOCKS, Payload: payload})
+	if sent := <-s.sent; sent.Compression != pb.Compression_GZIP {
+		t.Fatalf("Expected the message to be compressed once it is negotiated")
+	}
+
+	// The message is counted once it is sent
+	traffic := q.traffic()
+	for traffic.MessagesSent != 2 {
+		time.Sleep(time.Millisecond)
+		traffic = q.traffic()
+	}
+	if traffic.BytesSent >= uint64(2*len(payload)) || traffic.Compression != pb.Compression_GZIP {
+		t.Errorf("Unexpected traffic %v", traffic)
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statetransfer
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// snapshotProgress is the fast sync in progress, the partitions already retrieved are not retrieved again when
+// a failed attempt is retried for the same block
+type snapshotProgress struct {
+	blockNumber uint64          // The block the state is retrieved as of
+	stateHash   []byte          // The state hash of this block, which the retrieved state must have
+	partitions  uint32          // The number of partitions the state is retrieved in
+	hashes      [][]byte        // The hashes of the partitions, which were checked against the state hash
+	done        map[uint32]bool // The partitions already applied to the state
+}
+
+// syncStatePiece is a piece of a state snapshot partition, as received from a peer
+type syncStatePiece struct {
+	msg   *pb.SyncStateSnapshot
+	delta *statemgmt.StateDelta
+}
+
+// fastSyncState retrieves the state as of the block, whose hash is known to be valid, typically from a checkpoint.
+// The hashes of the partitions of the state are retrieved first, and checked against the state hash of the block.
+// The partitions are then retrieved from several peers in parallel, and each is checked against its hash before it
+// is applied, so that a peer serving a bad partition is banned, and the partition retrieved from another peer.
+// Returns the block number the state corresponds to.
+func (sts *coordinatorImpl) fastSyncState(blockNumber uint64, blockHash []byte, peerIDs []*pb.PeerID) (uint64, error) {
+	partitioning, err := sts.stack.GetStatePartitioning()
+	if err != nil {
+		return sts.currentStateBlockNumber, fmt.Errorf("Could not fast sync the state: %s", err)
+	}
+
+	progress := sts.snapshotProgress
+	if progress == nil || progress.blockNumber != blockNumber {
+		block, err := sts.fetchCheckpointBlock(blockNumber, blockHash, peerIDs)
+		if err != nil {
+			return sts.currentStateBlockNumber, err
+		}
+
+		partitions := partitioning.GetNumPartitions(sts.snapshotPartitions)
+		hashes, err := sts.fetchPartitionHashes(blockNumber, block.StateHash, partitions, partitioning, peerIDs)
+		if err != nil {
+			return sts.currentStateBlockNumber, err
+		}
+
+		if err := sts.stack.EmptyState(); nil != err {
+			return sts.currentStateBlockNumber, fmt.Errorf("Could not empty the current state: %s", err)
+		}
+
+		progress = &snapshotProgress{
+			blockNumber: blockNumber,
+			stateHash:   block.StateHash,
+			partitions:  partitions,
+			hashes:      hashes,
+			done:        make(map[uint32]bool),
+		}
+		sts.snapshotProgress = progress
+		logger.Infof("Fast syncing the state as of block %d in %d partitions", blockNumber, partitions)
+	} else {
+		logger.Infof("Resuming fast sync of the state as of block %d, %d of %d partitions already retrieved", blockNumber, len(progress.done), progress.partitions)
+	}
+
+	var chunks []*syncChunk
+	for _, chunk := range splitRange(0, uint64(progress.partitions-1), 0) {
+		if !progress.done[uint32(chunk.start)] {
+			chunks = append(chunks, chunk)
+		}
+	}
+
+	err = sts.fetchChunks(peerIDs, chunks, func(peerID *pb.PeerID, chunk *syncChunk) (interface{}, int, error) {
+		return sts.fetchStatePartition(peerID, progress, partitioning, uint32(chunk.start))
+	}, func(peerID *pb.PeerID, chunk *syncChunk, data interface{}) error {
+		for _, piece := range data.([]*syncStatePiece) {
+			if err := sts.stack.ApplyStateDelta(piece.msg, piece.delta); nil != err {
+				// The partition is partially applied, so the state can not be resumed from
+				sts.snapshotProgress = nil
+				return fmt.Errorf("Could not apply partition %d from %v: %s", chunk.start, peerID, err)
+			}
+			if err := sts.stack.CommitStateDelta(piece.msg); nil != err {
+				sts.snapshotProgress = nil
+				return fmt.Errorf("Could not commit partition %d from %v: %s", chunk.start, peerID, err)
+			}
+		}
+		progress.done[uint32(chunk.start)] = true
+		logger.Debugf("Retrieved partition %d of the state as of block %d from %v", chunk.start, blockNumber, peerID)
+		return nil
+	})
+	if err != nil {
+		return sts.currentStateBlockNumber, err
+	}
+
+	// All partitions are applied, whatever the outcome the next attempt starts over
+	sts.snapshotProgress = nil
+
+	stateHash, err := sts.stack.GetCurrentStateHash()
+	if nil != err {
+		return sts.currentStateBlockNumber, fmt.Errorf("Could not compute the current state hash: %s", err)
+	}
+	if !bytes.Equal(stateHash, progress.stateHash) {
+		return sts.currentStateBlockNumber, fmt.Errorf("Fast synced the state as of block %d to hash %x, but the block has state hash %x", blockNumber, stateHash, progress.stateHash)
+	}
+
+	logger.Infof("Fast synced the state as of block %d with hash %x", blockNumber, stateHash)
+	return blockNumber, nil
+}
+
+// fetchCheckpointBlock returns the block with the given number and hash, from the local blockchain if it is there
+func (sts *coordinatorImpl) fetchCheckpointBlock(blockNumber uint64, blockHash []byte, peerIDs []*pb.PeerID) (*pb.Block, error) {
+	if block, err := sts.stack.GetBlockByNumber(blockNumber); err == nil && block != nil {
+		if testHash, err := sts.stack.HashBlock(block); err == nil && bytes.Equal(testHash, blockHash) {
+			return block, nil
+		}
+	}
+
+	var block *pb.Block
+	err := sts.fetchChunks(peerIDs, splitRange(blockNumber, blockNumber, 0), sts.fetchBlocks, func(peerID *pb.PeerID, chunk *syncChunk, data interface{}) error {
+		blocks := data.([]*pb.Block)
+		if err := sts.verifyBlockHash(peerID, blockNumber, blocks[0], blockHash); err != nil {
+			return err
+		}
+		block = blocks[0]
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Could not retrieve block %d to fast sync to: %s", blockNumber, err)
+	}
+	return block, nil
+}
+
+// fetchPartitionHashes retrieves the hashes of the partitions of the state as of a block, and checks them against
+// the state hash of the block
+func (sts *coordinatorImpl) fetchPartitionHashes(blockNumber uint64, stateHash []byte, partitions uint32, partitioning statemgmt.PartitionedState, peerIDs []*pb.PeerID) ([][]byte, error) {
+	var hashes [][]byte
+	err := sts.fetchChunks(peerIDs, splitRange(0, 0, 0), func(peerID *pb.PeerID, chunk *syncChunk) (interface{}, int, error) {
+		logger.Debugf("Requesting the hashes of the %d partitions of the state as of block %d from %v", partitions, blockNumber, peerID)
+		msg, err := sts.receiveStatePartition(peerID, &pb.SyncStateSnapshotChunk{
+			BlockNumber: blockNumber,
+			Partitions:  partitions,
+			Hashes:      true,
+		}, func(msg *pb.SyncStateSnapshot) error {
+			return badData("Received a delta instead of the hashes of the partitions from %v", peerID)
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if uint32(len(msg.PartitionHashes)) != partitions {
+			return nil, 0, badData("Received %d partition hashes from %v, expected %d", len(msg.PartitionHashes), peerID, partitions)
+		}
+		computed, err := partitioning.ComputeCryptoHashOfPartitions(msg.PartitionHashes)
+		if err != nil {
+			return nil, 0, badData("Received invalid partition hashes from %v: %s", peerID, err)
+		}
+		if !bytes.Equal(computed, stateHash) {
+			return nil, 0, badData("Received partition hashes from %v which hash to %x, but block %d has state hash %x", peerID, computed, blockNumber, stateHash)
+		}
+		return msg.PartitionHashes, len(msg.PartitionHashes), nil
+	}, func(peerID *pb.PeerID, chunk *syncChunk, data interface{}) error {
+		hashes = data.([][]byte)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Could not retrieve the hashes of the partitions of the state as of block %d: %s", blockNumber, err)
+	}
+	return hashes, nil
+}
+
+// fetchStatePartition retrieves a partition of the state as of a block from a peer, and checks it against its hash
+func (sts *coordinatorImpl) fetchStatePartition(peerID *pb.PeerID, progress *snapshotProgress, partitioning statemgmt.PartitionedState, partition uint32) (interface{}, int, error) {
+	logger.Debugf("Requesting partition %d of the state as of block %d from %v", partition, progress.blockNumber, peerID)
+	var pieces []*syncStatePiece
+	kvs := make(map[string][]byte)
+	_, err := sts.receiveStatePartition(peerID, &pb.SyncStateSnapshotChunk{
+		BlockNumber: progress.blockNumber,
+		Partition:   partition,
+		Partitions:  progress.partitions,
+	}, func(msg *pb.SyncStateSnapshot) error {
+		umDelta := &statemgmt.StateDelta{}
+		if err := umDelta.Unmarshal(msg.Delta); nil != err {
+			return badData("Received a corrupt delta of partition %d from %v after %d deltas: %s", partition, peerID, len(pieces), err)
+		}
+		for _, cID := range umDelta.GetUpdatedChaincodeIds(false) {
+			for keyID, value := range umDelta.GetUpdates(cID) {
+				kvs[string(statemgmt.ConstructCompositeKey(cID, keyID))] = value.GetValue()
+			}
+		}
+		pieces = append(pieces, &syncStatePiece{msg: msg, delta: umDelta})
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hash, err := partitioning.ComputePartitionCryptoHash(partition, progress.partitions, kvs)
+	if err != nil {
+		return nil, 0, badData("Received an invalid partition %d from %v: %s", partition, peerID, err)
+	}
+	if !bytes.Equal(hash, progress.hashes[partition]) {
+		return nil, 0, badData("Received partition %d from %v with hash %x, expected hash %x", partition, peerID, hash, progress.hashes[partition])
+	}
+	return pieces, len(pieces), nil
+}
+
+// receiveStatePartition requests a partition of the state as of a block from a peer, passes each delta received to
+// process, and returns the terminating message
+func (sts *coordinatorImpl) receiveStatePartition(peerID *pb.PeerID, chunk *pb.SyncStateSnapshotChunk, process func(msg *pb.SyncStateSnapshot) error) (*pb.SyncStateSnapshot, error) {
+	stateChan, err := sts.GetRemoteStateSnapshotChunk(peerID, chunk)
+	if nil != err {
+		return nil, fmt.Errorf("Failed to get partition %d from %v: %s", chunk.Partition, peerID, err)
+	}
+
+	timer := time.NewTimer(sts.StateSnapshotRequestTimeout)
+	defer timer.Stop()
+
+	for deltas := 0; ; deltas++ {
+		select {
+		case msg, ok := <-stateChan:
+			if !ok {
+				return nil, fmt.Errorf("Had partition %d channel close prematurely after %d deltas", chunk.Partition, deltas)
+			}
+			if msg.BlockNumber != chunk.BlockNumber {
+				return nil, badData("Received a delta of partition %d as of block %d from %v, requested block %d", chunk.Partition, msg.BlockNumber, peerID, chunk.BlockNumber)
+			}
+			if 0 == len(msg.Delta) {
+				return msg, nil
+			}
+			if err := process(msg); err != nil {
+				return nil, err
+			}
+		case <-timer.C:
+			return nil, timedOut("Timed out retrieving partition %d from %v", chunk.Partition, peerID)
+		}
+	}
+}
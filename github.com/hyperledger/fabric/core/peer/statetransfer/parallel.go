@@ -0,0 +1,373 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statetransfer
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// throughputWeight is the weight of the latest measure in the moving average of the throughput of a peer
+const throughputWeight = 0.5
+
+// peerStats is what state transfer learned about a peer it synced from
+type peerStats struct {
+	measured    bool      // Whether the throughput was measured yet, unmeasured peers are tried first
+	throughput  float64   // Moving average of the blocks or state deltas per second the peer served
+	bannedUntil time.Time // The peer served data which did not verify, and is not used before this time
+}
+
+// badDataError is returned when a peer served data which did not verify, the peer is banned
+type badDataError struct {
+	error
+}
+
+func badData(format string, a ...interface{}) error {
+	return badDataError{fmt.Errorf(format, a...)}
+}
+
+// timeoutError is returned when a peer did not serve a request in time
+type timeoutError struct {
+	error
+}
+
+func timedOut(format string, a ...interface{}) error {
+	return timeoutError{fmt.Errorf(format, a...)}
+}
+
+// syncChunk is a range of blocks or state deltas which is retrieved with a single request to a peer
+type syncChunk struct {
+	start, end uint64             // The range as requested, backwards for blocks, forwards for state deltas
+	failed     map[pb.PeerID]bool // The peers which could not serve this chunk
+}
+
+type fetchResult struct {
+	chunk   int
+	peerID  *pb.PeerID
+	data    interface{}
+	items   int
+	elapsed time.Duration
+	err     error
+}
+
+// splitRange splits the range from start to end into chunks of at most size+1 items, in the order of the range
+func splitRange(start, end, size uint64) []*syncChunk {
+	var chunks []*syncChunk
+	cursor := start
+	for {
+		chunk := &syncChunk{start: cursor, failed: make(map[pb.PeerID]bool)}
+		if start >= end {
+			if cursor-end > size {
+				chunk.end = cursor - size
+			} else {
+				chunk.end = end
+			}
+		} else {
+			if end-cursor > size {
+				chunk.end = cursor + size
+			} else {
+				chunk.end = end
+			}
+		}
+		chunks = append(chunks, chunk)
+		if chunk.end == end {
+			return chunks
+		}
+		if start >= end {
+			cursor = chunk.end - 1
+		} else {
+			cursor = chunk.end + 1
+		}
+	}
+}
+
+// fetchChunks retrieves the chunks from up to sts.parallelism peers at once, one request per peer at a time,
+// and passes them in order to process. A chunk which could not be retrieved, or which process reports as bad
+// data, is retrieved again from another peer. It fails once a chunk could not be retrieved from any peer.
+func (sts *coordinatorImpl) fetchChunks(passedPeerIDs []*pb.PeerID, chunks []*syncChunk,
+	fetch func(peerID *pb.PeerID, chunk *syncChunk) (data interface{}, items int, err error),
+	process func(peerID *pb.PeerID, chunk *syncChunk, data interface{}) error) error {
+
+	peerIDs, err := sts.getPeers(passedPeerIDs)
+	if err != nil {
+		return err
+	}
+
+	results := make(chan *fetchResult, len(peerIDs)) // Allows the fetching threads to exit if we return early
+	fetched := make(map[int]*fetchResult)
+	busy := make(map[pb.PeerID]bool)
+	queue := make([]int, len(chunks)) // The chunks left to retrieve, in order
+	for i := range queue {
+		queue[i] = i
+	}
+	window := 2 * sts.parallelism // The chunks retrieved ahead of the one processed next are bounded
+	inFlight := 0
+	next := 0
+	var lastErr error
+
+	for next < len(chunks) {
+		for inFlight < sts.parallelism && len(queue) > 0 && queue[0] < next+window {
+			index := queue[0]
+			peerID := sts.pickPeer(peerIDs, busy, chunks[index])
+			if peerID == nil {
+				break
+			}
+			queue = queue[1:]
+			busy[*peerID] = true
+			inFlight++
+			go func(index int, peerID *pb.PeerID) {
+				start := time.Now()
+				data, items, err := fetch(peerID, chunks[index])
+				results <- &fetchResult{chunk: index, peerID: peerID, data: data, items: items, elapsed: time.Since(start), err: err}
+			}(index, peerID)
+		}
+
+		if inFlight == 0 {
+			if lastErr == nil {
+				lastErr = fmt.Errorf("No peers available to retrieve %d through %d", chunks[queue[0]].start, chunks[queue[0]].end)
+			}
+			return lastErr
+		}
+
+		res := <-results
+		inFlight--
+		delete(busy, *res.peerID)
+
+		if res.err != nil {
+			logger.Warningf("Could not retrieve %d through %d from %v: %s", chunks[res.chunk].start, chunks[res.chunk].end, res.peerID, res.err)
+			sts.peerFailed(res.peerID, res.err)
+			chunks[res.chunk].failed[*res.peerID] = true
+			queue = requeueChunk(queue, res.chunk)
+			lastErr = res.err
+			continue
+		}
+
+		sts.recordThroughput(res.peerID, res.items, res.elapsed)
+		fetched[res.chunk] = res
+
+		for res, ok := fetched[next]; ok; res, ok = fetched[next] {
+			delete(fetched, next)
+			if err := process(res.peerID, chunks[next], res.data); err != nil {
+				if _, ok := err.(badDataError); !ok {
+					return err
+				}
+				sts.peerFailed(res.peerID, err)
+				chunks[next].failed[*res.peerID] = true
+				queue = requeueChunk(queue, next)
+				lastErr = err
+				break
+			}
+			next++
+		}
+	}
+
+	return nil
+}
+
+// requeueChunk inserts the chunk index in the queue, keeping it ordered
+func requeueChunk(queue []int, index int) []int {
+	i := sort.SearchInts(queue, index)
+	queue = append(queue, 0)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = index
+	return queue
+}
+
+// getPeers returns the peers to sync from, the passed peers, or all other validating peers if nil, without the
+// banned peers, the fastest first
+func (sts *coordinatorImpl) getPeers(passedPeerIDs []*pb.PeerID) ([]*pb.PeerID, error) {
+	peerIDs := passedPeerIDs
+
+	ep, err := sts.stack.GetPeerEndpoint()
+
+	if err != nil {
+		// Unless we throttle here, this condition will likely cause a tight loop which will adversely affect the rest of the system
+		time.Sleep(sts.DiscoveryThrottleTime)
+		return nil, fmt.Errorf("Error resolving our own PeerID, this shouldn't happen")
+	}
+
+	if nil == passedPeerIDs {
+		logger.Debugf("getPeers: no peerIDs given, discovering")
+
+		peersMsg, err := sts.stack.GetPeers()
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't retrieve list of peers: %v", err)
+		}
+		peers := peersMsg.GetPeers()
+		for _, endpoint := range peers {
+			if endpoint.Type == pb.PeerEndpoint_VALIDATOR {
+				if endpoint.ID.Name == ep.ID.Name {
+					continue
+				}
+				peerIDs = append(peerIDs, endpoint.ID)
+			}
+		}
+
+		logger.Debugf("Discovered %d peerIDs", len(peerIDs))
+	}
+
+	if 0 == len(peerIDs) {
+		logger.Errorf("Invoked state transfer with no peers specified, throttling thread")
+		// Unless we throttle here, this condition will likely cause a tight loop which will adversely affect the rest of the system
+		time.Sleep(sts.DiscoveryThrottleTime)
+		return nil, fmt.Errorf("No peers available to try over")
+	}
+
+	return sts.preferPeers(peerIDs), nil
+}
+
+// preferPeers orders the peers by decreasing throughput, peers not measured yet first, in random order otherwise,
+// and drops the banned peers, unless all of them are
+func (sts *coordinatorImpl) preferPeers(peerIDs []*pb.PeerID) []*pb.PeerID {
+	sts.peerMutex.Lock()
+	defer sts.peerMutex.Unlock()
+
+	now := time.Now()
+	candidates := peersByThroughput{sts: sts}
+	for _, i := range rand.Perm(len(peerIDs)) {
+		if stats, ok := sts.peerStats[*peerIDs[i]]; ok && now.Before(stats.bannedUntil) {
+			continue
+		}
+		candidates.peerIDs = append(candidates.peerIDs, peerIDs[i])
+	}
+
+	if 0 == len(candidates.peerIDs) {
+		// The hash chain still protects us from bad data, giving up on state transfer would not
+		logger.Warningf("All %d peers to sync from are banned, lifting their bans", len(peerIDs))
+		for _, peerID := range peerIDs {
+			sts.peerStats[*peerID].bannedUntil = time.Time{}
+		}
+		candidates.peerIDs = append(candidates.peerIDs, peerIDs...)
+	}
+
+	sort.Stable(candidates)
+	logger.Debugf("Syncing from peers %v", candidates.peerIDs)
+	return candidates.peerIDs
+}
+
+// pickPeer returns the fastest peer which is neither busy, banned, nor failed to serve the chunk, or nil
+func (sts *coordinatorImpl) pickPeer(peerIDs []*pb.PeerID, busy map[pb.PeerID]bool, chunk *syncChunk) *pb.PeerID {
+	sts.peerMutex.Lock()
+	defer sts.peerMutex.Unlock()
+
+	now := time.Now()
+	var best *pb.PeerID
+	bestThroughput := -1.0
+	for _, peerID := range peerIDs {
+		if busy[*peerID] || chunk.failed[*peerID] {
+			continue
+		}
+		if stats, ok := sts.peerStats[*peerID]; ok && now.Before(stats.bannedUntil) {
+			continue
+		}
+		if throughput := sts.throughput(peerID); throughput > bestThroughput {
+			best = peerID
+			bestThroughput = throughput
+		}
+	}
+	return best
+}
+
+// throughput returns the measured throughput of a peer, or +Inf if not measured yet, peerMutex must be held
+func (sts *coordinatorImpl) throughput(peerID *pb.PeerID) float64 {
+	stats, ok := sts.peerStats[*peerID]
+	if !ok || !stats.measured {
+		return math.Inf(1)
+	}
+	return stats.throughput
+}
+
+func (sts *coordinatorImpl) getPeerStats(peerID *pb.PeerID) *peerStats {
+	stats, ok := sts.peerStats[*peerID]
+	if !ok {
+		stats = &peerStats{}
+		sts.peerStats[*peerID] = stats
+	}
+	return stats
+}
+
+// recordThroughput updates the throughput of a peer which served items in elapsed time
+func (sts *coordinatorImpl) recordThroughput(peerID *pb.PeerID, items int, elapsed time.Duration) {
+	sts.peerMutex.Lock()
+	defer sts.peerMutex.Unlock()
+
+	if elapsed <= 0 {
+		elapsed = time.Nanosecond
+	}
+	rate := float64(items) / elapsed.Seconds()
+	stats := sts.getPeerStats(peerID)
+	if stats.measured {
+		rate = throughputWeight*rate + (1-throughputWeight)*stats.throughput
+	}
+	stats.measured = true
+	stats.throughput = rate
+}
+
+// peerFailed bans a peer which served bad data, and halves the throughput of a peer which could not serve,
+// only bad data is reported to the peer, as a timeout may not be the fault of the remote peer
+func (sts *coordinatorImpl) peerFailed(peerID *pb.PeerID, err error) {
+	sts.peerMutex.Lock()
+	defer sts.peerMutex.Unlock()
+
+	stats := sts.getPeerStats(peerID)
+	if _, ok := err.(badDataError); ok {
+		logger.Warningf("Banning %v for %v, as it served data which did not verify: %s", peerID, sts.banDuration, err)
+		stats.bannedUntil = time.Now().Add(sts.banDuration)
+		sts.reportMisbehavior(peerID, peer.OffenseInvalidData, err)
+		return
+	}
+	stats.measured = true
+	stats.throughput /= 2
+}
+
+// reportMisbehavior scores the failure of a peer against it on the peer, so
+// that a peer which fails repeatedly is banned from the network as well
+func (sts *coordinatorImpl) reportMisbehavior(peerID *pb.PeerID, offense peer.Offense, err error) {
+	if reporter, ok := sts.stack.(peer.MisbehaviorReporter); ok {
+		reporter.ReportMisbehavior(peerID, offense, err.Error())
+	}
+}
+
+// isBanned returns whether a peer is currently banned
+func (sts *coordinatorImpl) isBanned(peerID *pb.PeerID) bool {
+	sts.peerMutex.Lock()
+	defer sts.peerMutex.Unlock()
+
+	stats, ok := sts.peerStats[*peerID]
+	return ok && time.Now().Before(stats.bannedUntil)
+}
+
+type peersByThroughput struct {
+	sts     *coordinatorImpl
+	peerIDs []*pb.PeerID
+}
+
+func (a peersByThroughput) Len() int {
+	return len(a.peerIDs)
+}
+func (a peersByThroughput) Swap(i, j int) {
+	a.peerIDs[i], a.peerIDs[j] = a.peerIDs[j], a.peerIDs[i]
+}
+func (a peersByThroughput) Less(i, j int) bool {
+	return a.sts.throughput(a.peerIDs[i]) > a.sts.throughput(a.peerIDs[j])
+}
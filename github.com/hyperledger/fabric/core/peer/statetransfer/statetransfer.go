@@ -19,8 +19,8 @@ package statetransfer
 import (
 	"bytes"
 	"fmt"
-	"math/rand"
 	"sort"
+	"sync"
 	"time"
 
 	_ "github.com/hyperledger/fabric/core" // Logging format init
@@ -90,6 +90,15 @@ type coordinatorImpl struct {
 	maxBlockRange      uint64 // The maximum number blocks to attempt to retrieve at once, to prevent from overflowing the peer's buffer
 	maxStateDeltaRange uint64 // The maximum number of state deltas to attempt to retrieve at once, to prevent from overflowing the peer's buffer
 
+	parallelism int                      // The number of peers to retrieve blocks and state deltas from at once
+	banDuration time.Duration            // How long a peer which served data which did not verify is not synced from
+	peerMutex   sync.Mutex               // Protects peerStats, which is used by both the state and the block threads
+	peerStats   map[pb.PeerID]*peerStats // What was learned about the peers synced from
+
+	fastSync           bool              // Whether an invalid state is retrieved as of the sync target in partitions, rather than from a snapshot of a single peer
+	snapshotPartitions uint32            // The number of partitions the state is retrieved in during fast sync
+	snapshotProgress   *snapshotProgress // The partitions of the fast sync in progress already retrieved, so that a failed attempt resumes
+
 	currentStateBlockNumber uint64 // When state transfer does not complete successfully, the current state does not always correspond to the block height
 }
 
@@ -184,6 +193,27 @@ func NewCoordinatorImpl(stack PartialStack) Coordinator {
 	}
 	sts.maxStateDeltaRange = uint64(tmp)
 
+	sts.parallelism = viper.GetInt("statetransfer.parallelism")
+	if sts.parallelism <= 0 {
+		panic(fmt.Errorf("statetransfer.parallelism must be greater than 0"))
+	}
+
+	sts.banDuration, err = time.ParseDuration(viper.GetString("statetransfer.banduration"))
+	if err != nil {
+		panic(fmt.Errorf("Cannot parse statetransfer.banduration: %s", err))
+	}
+
+	sts.peerStats = make(map[pb.PeerID]*peerStats)
+
+	sts.fastSync = viper.GetBool("statetransfer.fastsync.enabled")
+	if sts.fastSync {
+		partitions := viper.GetInt("statetransfer.fastsync.partitions")
+		if partitions <= 0 {
+			panic(fmt.Errorf("statetransfer.fastsync.partitions must be greater than 0"))
+		}
+		sts.snapshotPartitions = uint32(partitions)
+	}
+
 	return sts
 }
 
@@ -225,59 +255,24 @@ func (a blockRangeSlice) Less(i, j int) bool {
 // helper functions for state transfer
 // =============================================================================
 
-// Executes a func trying each peer included in peerIDs until successful
+// Executes a func trying each peer included in peerIDs until successful, the fastest first
 // Attempts to execute over all peers if peerIDs is nil
 func (sts *coordinatorImpl) tryOverPeers(passedPeerIDs []*pb.PeerID, do func(peerID *pb.PeerID) error) (err error) {
 
-	peerIDs := passedPeerIDs
-
-	ep, err := sts.stack.GetPeerEndpoint()
-
+	peerIDs, err := sts.getPeers(passedPeerIDs)
 	if err != nil {
-		// Unless we throttle here, this condition will likely cause a tight loop which will adversely affect the rest of the system
-		time.Sleep(sts.DiscoveryThrottleTime)
-		return fmt.Errorf("Error resolving our own PeerID, this shouldn't happen")
-	}
-
-	if nil == passedPeerIDs {
-		logger.Debugf("tryOverPeers: no peerIDs given, discovering")
-
-		peersMsg, err := sts.stack.GetPeers()
-		if err != nil {
-			return fmt.Errorf("Couldn't retrieve list of peers: %v", err)
-		}
-		peers := peersMsg.GetPeers()
-		for _, endpoint := range peers {
-			if endpoint.Type == pb.PeerEndpoint_VALIDATOR {
-				if endpoint.ID.Name == ep.ID.Name {
-					continue
-				}
-				peerIDs = append(peerIDs, endpoint.ID)
-			}
-		}
-
-		logger.Debugf("Discovered %d peerIDs", len(peerIDs))
+		return err
 	}
 
 	logger.Debugf("tryOverPeers: using peerIDs: %v", peerIDs)
 
-	if 0 == len(peerIDs) {
-		logger.Errorf("Invoked tryOverPeers with no peers specified, throttling thread")
-		// Unless we throttle here, this condition will likely cause a tight loop which will adversely affect the rest of the system
-		time.Sleep(sts.DiscoveryThrottleTime)
-		return fmt.Errorf("No peers available to try over")
-	}
-
-	numReplicas := len(peerIDs)
-	startIndex := rand.Int() % numReplicas
-
-	for i := 0; i < numReplicas; i++ {
-		index := (i + startIndex) % numReplicas
-		err = do(peerIDs[index])
+	for _, peerID := range peerIDs {
+		err = do(peerID)
 		if err == nil {
 			break
 		} else {
-			logger.Warningf("tryOverPeers: loop error from %v : %s", peerIDs[index], err)
+			logger.Warningf("tryOverPeers: loop error from %v : %s", peerID, err)
+			sts.peerFailed(peerID, err)
 		}
 	}
 
@@ -288,6 +283,7 @@ func (sts *coordinatorImpl) tryOverPeers(passedPeerIDs []*pb.PeerID, do func(pee
 // Attempts to complete a blockSyncReq using the supplied peers
 // Will return the last block number attempted to sync, and the last block successfully synced (or nil) and error on failure
 // This means on failure, the returned block corresponds to 1 higher than the returned block number
+// Large ranges are retrieved from several peers in parallel, each chunk is verified against the hash chain before it is put
 func (sts *coordinatorImpl) syncBlocks(highBlock, lowBlock uint64, highHash []byte, peerIDs []*pb.PeerID) (uint64, *pb.Block, error) {
 	logger.Debugf("Syncing blocks from %d to %d with head hash of %x", highBlock, lowBlock, highHash)
 	validBlockHash := highHash
@@ -295,102 +291,34 @@ func (sts *coordinatorImpl) syncBlocks(highBlock, lowBlock uint64, highHash []by
 	var block *pb.Block
 	var goodRange *blockRange
 
-	err := sts.tryOverPeers(peerIDs, func(peerID *pb.PeerID) error {
-		for {
-			intermediateBlock := blockCursor + 1
-			var blockChan <-chan *pb.SyncBlocks
-			var err error
-			for {
-
-				if intermediateBlock == blockCursor+1 {
-					if sts.maxBlockRange > blockCursor {
-						// Don't underflow
-						intermediateBlock = 0
-					} else {
-						intermediateBlock = blockCursor - sts.maxBlockRange
-					}
-					if intermediateBlock < lowBlock {
-						intermediateBlock = lowBlock
-					}
-					logger.Debugf("Requesting block range from %d to %d", blockCursor, intermediateBlock)
-					blockChan, err = sts.GetRemoteBlocks(peerID, blockCursor, intermediateBlock)
-				}
-
-				if nil != err {
-					logger.Warningf("Failed to get blocks from %d to %d from %v: %s", blockCursor, lowBlock, peerID, err)
-					return err
-				}
-
-				select {
-				case syncBlockMessage, ok := <-blockChan:
-
-					if !ok {
-						return fmt.Errorf("Channel closed before we could finish reading")
-					}
+	err := sts.fetchChunks(peerIDs, splitRange(highBlock, lowBlock, sts.maxBlockRange), sts.fetchBlocks, func(peerID *pb.PeerID, chunk *syncChunk, data interface{}) error {
+		blocks := data.([]*pb.Block)
 
-					if syncBlockMessage.Range.Start < syncBlockMessage.Range.End {
-						// If the message is not replying with blocks backwards, we did not ask for it
-						return fmt.Errorf("Received a block with wrong (increasing) order from %v, aborting", peerID)
-					}
-
-					var i int
-					for i, block = range syncBlockMessage.Blocks {
-						// It no longer correct to get duplication or out of range blocks, so we treat this as an error
-						if syncBlockMessage.Range.Start-uint64(i) != blockCursor {
-							return fmt.Errorf("Received a block out of order, indicating a buffer overflow or other corruption: start=%d, end=%d, wanted %d", syncBlockMessage.Range.Start, syncBlockMessage.Range.End, blockCursor)
-						}
-
-						testHash, err := sts.stack.HashBlock(block)
-						if nil != err {
-							return fmt.Errorf("Got a block %d which could not hash from %v: %s", blockCursor, peerID, err)
-						}
-
-						if !bytes.Equal(testHash, validBlockHash) {
-							return fmt.Errorf("Got block %d from %v with hash %x, was expecting hash %x", blockCursor, peerID, testHash, validBlockHash)
-						}
-
-						logger.Debugf("Putting block %d to with PreviousBlockHash %x and StateHash %x", blockCursor, block.PreviousBlockHash, block.StateHash)
-						if !sts.RecoverDamage {
-
-							// If we are not supposed to be destructive in our recovery, check to make sure this block doesn't already exist
-							if oldBlock, err := sts.stack.GetBlockByNumber(blockCursor); err == nil && oldBlock != nil {
-								oldBlockHash, err := sts.stack.HashBlock(oldBlock)
-								if nil == err {
-									if !bytes.Equal(oldBlockHash, validBlockHash) {
-										panic("The blockchain is corrupt and the configuration has specified that bad blocks should not be deleted/overridden")
-									}
-								} else {
-									logger.Errorf("Could not compute the hash of block %d", blockCursor)
-									panic("The blockchain is corrupt and the configuration has specified that bad blocks should not be deleted/overridden")
-								}
-								logger.Debugf("Not actually putting block %d to with PreviousBlockHash %x and StateHash %x, as it already exists", blockCursor, block.PreviousBlockHash, block.StateHash)
-							} else {
-								sts.stack.PutBlock(blockCursor, block)
-							}
-						} else {
-							sts.stack.PutBlock(blockCursor, block)
-						}
+		// The blocks of the chunk chain, so checking the first links them all to the blocks already synced
+		if err := sts.verifyBlockHash(peerID, chunk.start, blocks[0], validBlockHash); err != nil {
+			return err
+		}
 
-						goodRange = &blockRange{
-							highBlock:   highBlock,
-							lowBlock:    blockCursor,
-							lowNextHash: block.PreviousBlockHash,
-						}
+		for i := range blocks {
+			block = blocks[i]
+			sts.putBlock(blockCursor, block, validBlockHash)
 
-						validBlockHash = block.PreviousBlockHash
+			goodRange = &blockRange{
+				highBlock:   highBlock,
+				lowBlock:    blockCursor,
+				lowNextHash: block.PreviousBlockHash,
+			}
 
-						if blockCursor == lowBlock {
-							logger.Debugf("Successfully synced from block %d to block %d", highBlock, lowBlock)
-							return nil
-						}
-						blockCursor--
+			validBlockHash = block.PreviousBlockHash
 
-					}
-				case <-time.After(sts.BlockRequestTimeout):
-					return fmt.Errorf("Had block sync request to %v time out", peerID)
-				}
+			if blockCursor == lowBlock {
+				logger.Debugf("Successfully synced from block %d to block %d", highBlock, lowBlock)
+				return nil
 			}
+			blockCursor--
 		}
+
+		return nil
 	})
 
 	if nil != block {
@@ -408,6 +336,96 @@ func (sts *coordinatorImpl) syncBlocks(highBlock, lowBlock uint64, highHash []by
 
 }
 
+// Retrieves the blocks of a chunk from a peer, checking that they are in order and chain to one another
+func (sts *coordinatorImpl) fetchBlocks(peerID *pb.PeerID, chunk *syncChunk) (interface{}, int, error) {
+	logger.Debugf("Requesting block range from %d to %d from %v", chunk.start, chunk.end, peerID)
+	blockChan, err := sts.GetRemoteBlocks(peerID, chunk.start, chunk.end)
+	if nil != err {
+		return nil, 0, fmt.Errorf("Failed to get blocks from %d to %d from %v: %s", chunk.start, chunk.end, peerID, err)
+	}
+
+	count := int(chunk.start-chunk.end) + 1
+	blocks := make([]*pb.Block, 0, count)
+
+	for len(blocks) < count {
+		select {
+		case syncBlockMessage, ok := <-blockChan:
+
+			if !ok {
+				return nil, 0, fmt.Errorf("Channel closed before we could finish reading")
+			}
+
+			if syncBlockMessage.Range.Start < syncBlockMessage.Range.End {
+				// If the message is not replying with blocks backwards, we did not ask for it
+				return nil, 0, badData("Received a block with wrong (increasing) order from %v, aborting", peerID)
+			}
+
+			for i, block := range syncBlockMessage.Blocks {
+				blockCursor := chunk.start - uint64(len(blocks))
+				// It no longer correct to get duplication or out of range blocks, so we treat this as an error
+				if len(blocks) == count || syncBlockMessage.Range.Start-uint64(i) != blockCursor {
+					return nil, 0, badData("Received a block out of order, indicating a buffer overflow or other corruption: start=%d, end=%d, wanted %d", syncBlockMessage.Range.Start, syncBlockMessage.Range.End, blockCursor)
+				}
+
+				if len(blocks) > 0 {
+					testHash, err := sts.stack.HashBlock(block)
+					if nil != err {
+						return nil, 0, badData("Got a block %d which could not hash from %v: %s", blockCursor, peerID, err)
+					}
+
+					if previousHash := blocks[len(blocks)-1].PreviousBlockHash; !bytes.Equal(testHash, previousHash) {
+						return nil, 0, badData("Got block %d from %v with hash %x, was expecting hash %x", blockCursor, peerID, testHash, previousHash)
+					}
+				}
+
+				blocks = append(blocks, block)
+			}
+		case <-time.After(sts.BlockRequestTimeout):
+			return nil, 0, timedOut("Had block sync request to %v time out", peerID)
+		}
+	}
+
+	return blocks, count, nil
+}
+
+// Checks that a block served by a peer has the expected hash
+func (sts *coordinatorImpl) verifyBlockHash(peerID *pb.PeerID, blockNumber uint64, block *pb.Block, blockHash []byte) error {
+	testHash, err := sts.stack.HashBlock(block)
+	if nil != err {
+		return badData("Got a block %d which could not hash from %v: %s", blockNumber, peerID, err)
+	}
+
+	if !bytes.Equal(testHash, blockHash) {
+		return badData("Got block %d from %v with hash %x, was expecting hash %x", blockNumber, peerID, testHash, blockHash)
+	}
+	return nil
+}
+
+// Puts a block whose hash was verified, unless configured not to override existing blocks
+func (sts *coordinatorImpl) putBlock(blockNumber uint64, block *pb.Block, blockHash []byte) {
+	logger.Debugf("Putting block %d to with PreviousBlockHash %x and StateHash %x", blockNumber, block.PreviousBlockHash, block.StateHash)
+	if !sts.RecoverDamage {
+
+		// If we are not supposed to be destructive in our recovery, check to make sure this block doesn't already exist
+		if oldBlock, err := sts.stack.GetBlockByNumber(blockNumber); err == nil && oldBlock != nil {
+			oldBlockHash, err := sts.stack.HashBlock(oldBlock)
+			if nil == err {
+				if !bytes.Equal(oldBlockHash, blockHash) {
+					panic("The blockchain is corrupt and the configuration has specified that bad blocks should not be deleted/overridden")
+				}
+			} else {
+				logger.Errorf("Could not compute the hash of block %d", blockNumber)
+				panic("The blockchain is corrupt and the configuration has specified that bad blocks should not be deleted/overridden")
+			}
+			logger.Debugf("Not actually putting block %d to with PreviousBlockHash %x and StateHash %x, as it already exists", blockNumber, block.PreviousBlockHash, block.StateHash)
+		} else {
+			sts.stack.PutBlock(blockNumber, block)
+		}
+	} else {
+		sts.stack.PutBlock(blockNumber, block)
+	}
+}
+
 func (sts *coordinatorImpl) syncBlockchainToTarget(blockSyncReq *blockSyncReq) {
 
 	logger.Debugf("Processing a blockSyncReq to block %d through %d", blockSyncReq.blockNumber, blockSyncReq.reportOnBlock)
@@ -614,7 +632,11 @@ func (sts *coordinatorImpl) attemptStateTransfer(blockNumber uint64, peerIDs []*
 
 	if !sts.stateValid {
 		// Our state is currently bad, so get a new one
-		sts.currentStateBlockNumber, err = sts.syncStateSnapshot(blockNumber, peerIDs)
+		if sts.fastSync {
+			sts.currentStateBlockNumber, err = sts.fastSyncState(blockNumber, blockHash, peerIDs)
+		} else {
+			sts.currentStateBlockNumber, err = sts.syncStateSnapshot(blockNumber, peerIDs)
+		}
 
 		if nil != err {
 			return fmt.Errorf("Could not retrieve state as recent as %d from any of specified peers", blockNumber), true
@@ -696,92 +718,104 @@ func (sts *coordinatorImpl) attemptStateTransfer(blockNumber uint64, peerIDs []*
 func (sts *coordinatorImpl) playStateUpToBlockNumber(toBlockNumber uint64, peerIDs []*pb.PeerID) error {
 	logger.Debugf("Attempting to play state forward from %v to block %d", peerIDs, toBlockNumber)
 	var stateHash []byte
-	err := sts.tryOverPeers(peerIDs, func(peerID *pb.PeerID) error {
-
-		var deltaMessages <-chan *pb.SyncStateDeltas
-		for {
+	err := sts.fetchChunks(peerIDs, splitRange(sts.currentStateBlockNumber+1, toBlockNumber, sts.maxStateDeltaRange), sts.fetchStateDeltas, func(peerID *pb.PeerID, chunk *syncChunk, data interface{}) error {
 
-			intermediateBlock := sts.currentStateBlockNumber + 1 + sts.maxStateDeltaRange
-			if intermediateBlock > toBlockNumber {
-				intermediateBlock = toBlockNumber
+		for _, deltas := range data.([]*syncStateDeltas) {
+			if deltas.msg.Range.Start <= sts.currentStateBlockNumber {
+				// Already played, before a delta of this chunk did not verify
+				continue
 			}
-			logger.Debugf("Requesting state delta range from %d to %d", sts.currentStateBlockNumber+1, intermediateBlock)
-			var err error
-			deltaMessages, err = sts.GetRemoteStateDeltas(peerID, sts.currentStateBlockNumber+1, intermediateBlock)
 
-			if err != nil {
-				return fmt.Errorf("Received an error while trying to get the state deltas for blocks %d through %d from %v", sts.currentStateBlockNumber+1, intermediateBlock, peerID)
-			}
+			for _, umDelta := range deltas.deltas {
+				sts.stack.ApplyStateDelta(deltas.msg, umDelta)
 
-			for sts.currentStateBlockNumber < intermediateBlock {
-				select {
-				case deltaMessage, ok := <-deltaMessages:
-					if !ok {
-						return fmt.Errorf("Was only able to recover to block number %d when desired to recover to %d", sts.currentStateBlockNumber, toBlockNumber)
-					}
+				success := false
+
+				testBlock, err := sts.stack.GetBlockByNumber(sts.currentStateBlockNumber + 1)
 
-					if deltaMessage.Range.Start != sts.currentStateBlockNumber+1 || deltaMessage.Range.End < deltaMessage.Range.Start || deltaMessage.Range.End > toBlockNumber {
-						return fmt.Errorf("Received a state delta from %v either in the wrong order (backwards) or not next in sequence, aborting, start=%d, end=%d", peerID, deltaMessage.Range.Start, deltaMessage.Range.End)
+				if err != nil {
+					logger.Warningf("Could not retrieve block %d, though it should be present", deltas.msg.Range.End)
+				} else {
+
+					stateHash, err = sts.stack.GetCurrentStateHash()
+					if err != nil {
+						logger.Warningf("Could not compute state hash for some reason: %s", err)
 					}
+					logger.Debugf("Played state forward from %v to block %d with StateHash (%x), block has StateHash (%x)", peerID, deltas.msg.Range.End, stateHash, testBlock.StateHash)
+					if bytes.Equal(testBlock.StateHash, stateHash) {
+						success = true
+					}
+				}
 
-					for _, delta := range deltaMessage.Deltas {
-						umDelta := &statemgmt.StateDelta{}
-						if err := umDelta.Unmarshal(delta); nil != err {
-							return fmt.Errorf("Received a corrupt state delta from %v : %s", peerID, err)
-						}
-						sts.stack.ApplyStateDelta(deltaMessage, umDelta)
+				if !success {
+					if sts.stack.RollbackStateDelta(deltas.msg) != nil {
+						sts.stateValid = false
+						return fmt.Errorf("played state forward according to %v, but the state hash did not match, failed to roll back, invalidated state", peerID)
+					}
+					return badData("Played state forward according to %v, but the state hash did not match, rolled back", peerID)
 
-						success := false
+				}
 
-						testBlock, err := sts.stack.GetBlockByNumber(sts.currentStateBlockNumber + 1)
+				if sts.stack.CommitStateDelta(deltas.msg) != nil {
+					sts.stateValid = false
+					return fmt.Errorf("Played state forward according to %v, hashes matched, but failed to commit, invalidated state", peerID)
+				}
 
-						if err != nil {
-							logger.Warningf("Could not retrieve block %d, though it should be present", deltaMessage.Range.End)
-						} else {
+				logger.Debugf("Moved state from %d to %d", sts.currentStateBlockNumber, sts.currentStateBlockNumber+1)
+				sts.currentStateBlockNumber++
+			}
+		}
 
-							stateHash, err = sts.stack.GetCurrentStateHash()
-							if err != nil {
-								logger.Warningf("Could not compute state hash for some reason: %s", err)
-							}
-							logger.Debugf("Played state forward from %v to block %d with StateHash (%x), block has StateHash (%x)", peerID, deltaMessage.Range.End, stateHash, testBlock.StateHash)
-							if bytes.Equal(testBlock.StateHash, stateHash) {
-								success = true
-							}
-						}
+		return nil
+	})
+	logger.Debugf("State is now valid at block %d and hash %x", sts.currentStateBlockNumber, stateHash)
+	return err
+}
 
-						if !success {
-							if sts.stack.RollbackStateDelta(deltaMessage) != nil {
-								sts.stateValid = false
-								return fmt.Errorf("played state forward according to %v, but the state hash did not match, failed to roll back, invalidated state", peerID)
-							}
-							return fmt.Errorf("Played state forward according to %v, but the state hash did not match, rolled back", peerID)
+type syncStateDeltas struct {
+	msg    *pb.SyncStateDeltas
+	deltas []*statemgmt.StateDelta
+}
 
-						}
+// Retrieves the state deltas of a chunk from a peer, checking that they are in sequence and unmarshal
+func (sts *coordinatorImpl) fetchStateDeltas(peerID *pb.PeerID, chunk *syncChunk) (interface{}, int, error) {
+	logger.Debugf("Requesting state delta range from %d to %d from %v", chunk.start, chunk.end, peerID)
+	deltaMessages, err := sts.GetRemoteStateDeltas(peerID, chunk.start, chunk.end)
 
-						if sts.stack.CommitStateDelta(deltaMessage) != nil {
-							sts.stateValid = false
-							return fmt.Errorf("Played state forward according to %v, hashes matched, but failed to commit, invalidated state", peerID)
-						}
+	if err != nil {
+		return nil, 0, fmt.Errorf("Received an error while trying to get the state deltas for blocks %d through %d from %v", chunk.start, chunk.end, peerID)
+	}
 
-						logger.Debugf("Moved state from %d to %d", sts.currentStateBlockNumber, sts.currentStateBlockNumber+1)
-						sts.currentStateBlockNumber++
+	var result []*syncStateDeltas
+	next := chunk.start
+	for next <= chunk.end {
+		select {
+		case deltaMessage, ok := <-deltaMessages:
+			if !ok {
+				return nil, 0, fmt.Errorf("Was only able to retrieve the state deltas up to block number %d when desired to retrieve up to %d", next-1, chunk.end)
+			}
 
-						if sts.currentStateBlockNumber == toBlockNumber {
-							logger.Debugf("Caught up to block %d", sts.currentStateBlockNumber)
-							return nil
-						}
-					}
+			if deltaMessage.Range.Start != next || deltaMessage.Range.End < deltaMessage.Range.Start || deltaMessage.Range.End > chunk.end {
+				return nil, 0, badData("Received a state delta from %v either in the wrong order (backwards) or not next in sequence, aborting, start=%d, end=%d", peerID, deltaMessage.Range.Start, deltaMessage.Range.End)
+			}
 
-				case <-time.After(sts.StateDeltaRequestTimeout):
-					logger.Warningf("Timed out during state delta recovery from %v", peerID)
-					return fmt.Errorf("timed out during state delta recovery from %v", peerID)
+			deltas := &syncStateDeltas{msg: deltaMessage}
+			for _, delta := range deltaMessage.Deltas {
+				umDelta := &statemgmt.StateDelta{}
+				if err := umDelta.Unmarshal(delta); nil != err {
+					return nil, 0, badData("Received a corrupt state delta from %v : %s", peerID, err)
 				}
+				deltas.deltas = append(deltas.deltas, umDelta)
 			}
+			result = append(result, deltas)
+			next = deltaMessage.Range.End + 1
+
+		case <-time.After(sts.StateDeltaRequestTimeout):
+			return nil, 0, timedOut("timed out during state delta recovery from %v", peerID)
 		}
+	}
 
-	})
-	logger.Debugf("State is now valid at block %d and hash %x", sts.currentStateBlockNumber, stateHash)
-	return err
+	return result, int(chunk.end-chunk.start) + 1, nil
 }
 
 // This function will retrieve the current state from a peer.
@@ -806,6 +840,7 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 			return err
 		}
 
+		start := time.Now()
 		timer := time.NewTimer(sts.StateSnapshotRequestTimeout)
 		counter := 0
 
@@ -824,11 +859,12 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 					}
 
 					logger.Debugf("Received final piece of state snapshot from %v after %d deltas, now has hash %x", peerID, counter, stateHash)
+					sts.recordThroughput(peerID, counter, time.Since(start))
 					return nil
 				}
 				umDelta := &statemgmt.StateDelta{}
 				if err := umDelta.Unmarshal(piece.Delta); nil != err {
-					return fmt.Errorf("received a corrupt delta from %v after %d deltas : %s", peerID, counter, err)
+					return badData("received a corrupt delta from %v after %d deltas : %s", peerID, counter, err)
 				}
 				sts.stack.ApplyStateDelta(piece, umDelta)
 				currentStateBlock = piece.BlockNumber
@@ -837,7 +873,7 @@ func (sts *coordinatorImpl) syncStateSnapshot(minBlockNumber uint64, peerIDs []*
 				}
 				counter++
 			case <-timer.C:
-				return fmt.Errorf("Timed out during state recovery from %v", peerID)
+				return timedOut("Timed out during state recovery from %v", peerID)
 			}
 		}
 
@@ -869,6 +905,15 @@ func (sts *coordinatorImpl) GetRemoteStateSnapshot(replicaID *pb.PeerID) (<-chan
 	return remoteLedger.RequestStateSnapshot()
 }
 
+// GetRemoteStateSnapshotChunk will return a channel to stream a partition of a state snapshot as of a past block from the desired replicaID
+func (sts *coordinatorImpl) GetRemoteStateSnapshotChunk(replicaID *pb.PeerID, chunk *pb.SyncStateSnapshotChunk) (<-chan *pb.SyncStateSnapshot, error) {
+	remoteLedger, err := sts.stack.GetRemoteLedger(replicaID)
+	if nil != err {
+		return nil, err
+	}
+	return remoteLedger.RequestStateSnapshotChunk(chunk)
+}
+
 // GetRemoteStateDeltas will return a channel to stream a state snapshot deltas from the desired replicaID
 func (sts *coordinatorImpl) GetRemoteStateDeltas(replicaID *pb.PeerID, start, finish uint64) (<-chan *pb.SyncStateDeltas, error) {
 	remoteLedger, err := sts.stack.GetRemoteLedger(replicaID)
@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/protos"
+	"github.com/tecbot/gorocksdb"
 )
 
 type mockRequest int
@@ -35,6 +36,7 @@ const (
 	SyncDeltas mockRequest = iota
 	SyncBlocks
 	SyncSnapshot
+	SyncPartitionHashes
 )
 
 type mockResponse int
@@ -154,7 +156,13 @@ func NewMockLedger(remoteLedgers LedgerDirectory, filter func(request mockReques
 			return Normal
 		}
 	} else {
-		mock.filter = filter
+		// Blocks and state deltas are requested from several peers at once, but the filters need not be thread safe
+		filterMutex := &sync.Mutex{}
+		mock.filter = func(request mockRequest, peerID *protos.PeerID) mockResponse {
+			filterMutex.Lock()
+			defer filterMutex.Unlock()
+			return filter(request, peerID)
+		}
 	}
 
 	mock.remoteLedgers = remoteLedgers
@@ -201,6 +209,9 @@ func (rl *remoteLedger) RequestBlocks(rng *protos.SyncBlockRange) (<-chan *proto
 func (rl *remoteLedger) RequestStateSnapshot() (<-chan *protos.SyncStateSnapshot, error) {
 	return rl.mockLedger.GetRemoteStateSnapshot(rl.peerID)
 }
+func (rl *remoteLedger) RequestStateSnapshotChunk(chunk *protos.SyncStateSnapshotChunk) (<-chan *protos.SyncStateSnapshot, error) {
+	return rl.mockLedger.GetRemoteStateSnapshotChunk(rl.peerID, chunk)
+}
 func (rl *remoteLedger) RequestStateDeltas(rng *protos.SyncBlockRange) (<-chan *protos.SyncStateDeltas, error) {
 	return rl.mockLedger.GetRemoteStateDeltas(rl.peerID, rng.Start, rng.End)
 }
@@ -372,6 +383,120 @@ func (mock *MockLedger) GetRemoteStateSnapshot(peerID *protos.PeerID) (<-chan *p
 	return res, nil
 }
 
+// GetRemoteStateSnapshotChunk sends the sum of the deltas of the blocks up to chunk.BlockNumber whose number falls in
+// the partition, as the simple state is the sum of the deltas of all blocks, or the sums of all the partitions as
+// their hashes
+func (mock *MockLedger) GetRemoteStateSnapshotChunk(peerID *protos.PeerID, chunk *protos.SyncStateSnapshotChunk) (<-chan *protos.SyncStateSnapshot, error) {
+	rl, ok := mock.remoteLedgers.GetLedgerByPeerID(peerID)
+	if !ok {
+		return nil, fmt.Errorf("Bad peer ID %v", peerID)
+	}
+
+	res := make(chan *protos.SyncStateSnapshot, 2) // Allows the thread to exit even if the consumer doesn't finish
+	request := SyncSnapshot
+	if chunk.Hashes {
+		request = SyncPartitionHashes
+	}
+	ft := mock.filter(request, peerID)
+
+	if ft == Timeout {
+		return res, nil
+	}
+
+	partitionSum := func(partition uint32) uint64 {
+		var sum uint64
+		for b := uint64(partition); b <= chunk.BlockNumber; b += uint64(chunk.Partitions) {
+			remoteBlock, err := rl.GetBlockByNumber(b)
+			if err != nil {
+				break
+			}
+			for _, transaction := range remoteBlock.Transactions {
+				d, _ := binary.Uvarint(transaction.Payload)
+				sum += d
+			}
+		}
+		return sum
+	}
+
+	var skew uint64
+	switch ft {
+	case OutOfOrder:
+		fallthrough // This is an equivalent case to corruption, as we cannot detect out of order
+	case Corrupt:
+		// Well formed, but the partition or the hashes do not verify
+		skew = 1
+	case Normal:
+	default:
+		mock.t.Fatalf("Unsupported filter result %d", ft)
+	}
+
+	go func() {
+		if chunk.Hashes {
+			var hashes [][]byte
+			for partition := uint32(0); partition < chunk.Partitions; partition++ {
+				hashes = append(hashes, []byte(fmt.Sprintf("%d", partitionSum(partition)+skew)))
+			}
+			res <- &protos.SyncStateSnapshot{Delta: []byte{}, BlockNumber: chunk.BlockNumber, PartitionHashes: hashes}
+			return
+		}
+		res <- &protos.SyncStateSnapshot{
+			Delta:       SimpleBytesToStateDelta(SimpleEncodeUint64(partitionSum(chunk.Partition) + skew)).Marshal(),
+			Sequence:    0,
+			BlockNumber: chunk.BlockNumber,
+		}
+		res <- &protos.SyncStateSnapshot{
+			Delta:       []byte{},
+			Sequence:    1,
+			BlockNumber: chunk.BlockNumber,
+		}
+	}()
+	return res, nil
+}
+
+// mockPartitioning splits the simple state by block number, the hash of a partition being the sum of the deltas of
+// its blocks
+type mockPartitioning struct{}
+
+func (mock *MockLedger) GetStatePartitioning() (statemgmt.PartitionedState, error) {
+	return mockPartitioning{}, nil
+}
+
+func (mockPartitioning) GetNumPartitions(max uint32) uint32 {
+	return max
+}
+
+func (mockPartitioning) GetPartition(compositeKey []byte, partitions uint32) (uint32, error) {
+	return 0, fmt.Errorf("The simple state has no keys")
+}
+
+func (mockPartitioning) GetPartitionSnapshotIterator(snapshot *gorocksdb.Snapshot, partition, partitions uint32) (statemgmt.StateSnapshotIterator, error) {
+	return nil, fmt.Errorf("The simple state has no snapshots")
+}
+
+func (mockPartitioning) ComputePartitionCryptoHash(partition, partitions uint32, kvs map[string][]byte) ([]byte, error) {
+	var sum uint64
+	for _, v := range kvs {
+		d, r := binary.Uvarint(v)
+		if r <= 0 {
+			return nil, fmt.Errorf("Value was not a uint64, %x", v)
+		}
+		sum += d
+	}
+	return []byte(fmt.Sprintf("%d", sum)), nil
+}
+
+func (mockPartitioning) ComputeCryptoHashOfPartitions(hashes [][]byte) ([]byte, error) {
+	var sum uint64
+	for _, hash := range hashes {
+		var d uint64
+		if _, err := fmt.Sscanf(string(hash), "%d", &d); err != nil {
+			return nil, err
+		}
+		sum += d
+	}
+	return []byte(fmt.Sprintf("%d", sum)), nil
+}
+
 func (mock *MockLedger) GetRemoteStateDeltas(peerID *protos.PeerID, start, finish uint64) (<-chan *protos.SyncStateDeltas, error) {
 	return mock.getRemoteStateDeltas(peerID, start, finish, SyncDeltas)
 }
@@ -687,3 +687,347 @@ func TestBlockRangeOrdering(t *testing.T) {
 		t.Fatalf("Low range should come third")
 	}
 }
+
+func TestSplitRange(t *testing.T) {
+	check := func(chunks []*syncChunk, expected ...uint64) {
+		if len(chunks)*2 != len(expected) {
+			t.Fatalf("Expected %d chunks, got %d", len(expected)/2, len(chunks))
+		}
+		for i, chunk := range chunks {
+			if chunk.start != expected[2*i] || chunk.end != expected[2*i+1] {
+				t.Errorf("Expected chunk %d to be %d through %d, got %d through %d", i, expected[2*i], expected[2*i+1], chunk.start, chunk.end)
+			}
+		}
+	}
+
+	check(splitRange(10, 0, 3), 10, 7, 6, 3, 2, 0)
+	check(splitRange(1, 7, 3), 1, 4, 5, 7)
+	check(splitRange(5, 5, 3), 5, 5)
+}
+
+func TestCatchupParallel(t *testing.T) {
+	mrls := createRemoteLedgers(1, 4)
+
+	mutex := &sync.Mutex{}
+	blockRequests := make(map[protos.PeerID]int)
+	deltaRequests := 0
+	ml := NewMockLedger(mrls, func(request mockRequest, peerID *protos.PeerID) mockResponse {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch request {
+		case SyncBlocks:
+			blockRequests[*peerID]++
+		case SyncDeltas:
+			deltaRequests++
+		}
+		return Normal
+	}, t)
+	ml.PutBlock(0, SimpleGetBlock(0))
+
+	sts := newTestStateTransfer(ml, mrls)
+	defer sts.Stop()
+	maxRange := uint64(3)
+	sts.maxStateDeltaRange = maxRange
+	sts.maxBlockRange = maxRange
+
+	targetBlock := uint64(40)
+	if err := executeStateTransfer(sts, ml, targetBlock, 10, mrls); nil != err {
+		t.Fatalf("Parallel case: %s", err)
+	}
+
+	if n, err := ml.VerifyBlockchain(targetBlock, 0); 0 != n || nil != err {
+		t.Fatalf("Blockchain claims to be up to date, but does not verify")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	total := 0
+	for _, count := range blockRequests {
+		total += count
+	}
+	if total != 11 {
+		t.Errorf("Expected the 41 blocks to be retrieved in 11 requests, got %d", total)
+	}
+	if len(blockRequests) < 2 {
+		t.Errorf("Expected the blocks to be retrieved from several peers, got %v", blockRequests)
+	}
+	if deltaRequests != 10 {
+		t.Errorf("Expected the 40 state deltas to be retrieved in 10 requests, got %d", deltaRequests)
+	}
+}
+
+func TestCatchupBansBadPeer(t *testing.T) {
+	mrls := createRemoteLedgers(1, 3)
+	badPeer := &protos.PeerID{Name: "Peer 1"}
+
+	mutex := &sync.Mutex{}
+	badRequests := 0
+	ml := NewMockLedger(mrls, func(request mockRequest, peerID *protos.PeerID) mockResponse {
+		if request != SyncBlocks || *peerID != *badPeer {
+			return Normal
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		badRequests++
+		return OutOfOrder
+	}, t)
+	ml.PutBlock(0, SimpleGetBlock(0))
+
+	sts := newTestStateTransfer(ml, mrls)
+	defer sts.Stop()
+	sts.maxStateDeltaRange = 3
+	sts.maxBlockRange = 3
+
+	if err := executeStateTransfer(sts, ml, 40, 10, mrls); nil != err {
+		t.Fatalf("Bad peer case: %s", err)
+	}
+
+	if !sts.isBanned(badPeer) {
+		t.Errorf("The peer which served blocks out of order should be banned")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if badRequests != 1 {
+		t.Errorf("Expected no more requests to the peer once banned, got %d requests", badRequests)
+	}
+}
+
+type reportingPartialStack struct {
+	PartialStack
+	offenses map[protos.PeerID][]peer.Offense
+}
+
+func (s *reportingPartialStack) ReportMisbehavior(peerID *protos.PeerID, offense peer.Offense, reason string) {
+	s.offenses[*peerID] = append(s.offenses[*peerID], offense)
+}
+
+func TestPeerFailuresReported(t *testing.T) {
+	mrls := createRemoteLedgers(1, 3)
+	stack := &reportingPartialStack{
+		PartialStack: newPartialStack(NewMockLedger(mrls, nil, t), mrls),
+		offenses:     make(map[protos.PeerID][]peer.Offense),
+	}
+	sts := NewCoordinatorImpl(stack).(*coordinatorImpl)
+
+	badPeer, slowPeer, failedPeer := &protos.PeerID{Name: "Peer 1"}, &protos.PeerID{Name: "Peer 2"}, &protos.PeerID{Name: "Peer 3"}
+	sts.peerFailed(badPeer, badData("Got a corrupt block"))
+	sts.peerFailed(slowPeer, timedOut("Had block sync request time out"))
+	sts.peerFailed(failedPeer, fmt.Errorf("Could not get the remote ledger"))
+
+	if offenses := stack.offenses[*badPeer]; len(offenses) != 1 || offenses[0] != peer.OffenseInvalidData {
+		t.Errorf("Expected the peer which served bad data to be reported, got %v", offenses)
+	}
+	if offenses := stack.offenses[*slowPeer]; len(offenses) != 0 {
+		t.Errorf("Expected the peer which timed out not to be reported, got %v", offenses)
+	}
+	if offenses := stack.offenses[*failedPeer]; len(offenses) != 0 {
+		t.Errorf("Expected other failures not to be reported, got %v", offenses)
+	}
+}
+
+func TestPeerPreference(t *testing.T) {
+	mrls := createRemoteLedgers(1, 3)
+	ml := NewMockLedger(mrls, nil, t)
+	sts := newTestThreadlessStateTransfer(ml, mrls)
+
+	slow := &protos.PeerID{Name: "Peer 1"}
+	fast := &protos.PeerID{Name: "Peer 2"}
+	unknown := &protos.PeerID{Name: "Peer 3"}
+	peerIDs := []*protos.PeerID{slow, fast, unknown}
+
+	check := func(peers []*protos.PeerID, expected ...*protos.PeerID) {
+		if len(peers) != len(expected) {
+			t.Fatalf("Expected peers %v, got %v", expected, peers)
+		}
+		for i := range peers {
+			if *peers[i] != *expected[i] {
+				t.Fatalf("Expected peers %v, got %v", expected, peers)
+			}
+		}
+	}
+
+	sts.recordThroughput(slow, 10, time.Second)
+	sts.recordThroughput(fast, 100, time.Second)
+	check(sts.preferPeers(peerIDs), unknown, fast, slow)
+
+	sts.peerFailed(fast, fmt.Errorf("timed out"))
+	check(sts.preferPeers(peerIDs), unknown, fast, slow)
+	for i := 0; i < 3; i++ {
+		sts.peerFailed(fast, fmt.Errorf("timed out"))
+	}
+	check(sts.preferPeers(peerIDs), unknown, slow, fast)
+
+	sts.peerFailed(unknown, badData("corrupt"))
+	check(sts.preferPeers(peerIDs), slow, fast)
+
+	chunk := splitRange(0, 0, 0)[0]
+	chunk.failed[*fast] = true
+	if peerID := sts.pickPeer(peerIDs, map[protos.PeerID]bool{*slow: true}, chunk); peerID != nil {
+		t.Errorf("No peer should serve a chunk when the others are busy, banned, or failed it, got %v", peerID)
+	}
+
+	sts.peerFailed(slow, badData("corrupt"))
+	sts.peerFailed(fast, badData("corrupt"))
+	if peers := sts.preferPeers(peerIDs); len(peers) != 3 {
+		t.Errorf("The bans should be lifted when all peers are banned, got %v", peers)
+	}
+}
+
+func TestFastSync(t *testing.T) {
+	mrls := createRemoteLedgers(1, 3)
+
+	mutex := &sync.Mutex{}
+	requests := make(map[mockRequest]int)
+	ml := NewMockLedger(mrls, func(request mockRequest, peerID *protos.PeerID) mockResponse {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests[request]++
+		return Normal
+	}, t)
+	ml.PutBlock(0, SimpleGetBlock(0))
+
+	sts := newTestStateTransfer(ml, mrls)
+	defer sts.Stop()
+	sts.fastSync = true
+	sts.snapshotPartitions = 4
+	sts.maxStateDeltas = 5
+
+	if err := executeStateTransfer(sts, ml, 20, 10, mrls); nil != err {
+		t.Fatalf("Fast sync case: %s", err)
+	}
+
+	mutex.Lock()
+	if requests[SyncSnapshot] != 4 || requests[SyncDeltas] != 0 {
+		t.Errorf("Expected the state to be retrieved in 4 partitions without deltas, got %d partition and %d delta requests", requests[SyncSnapshot], requests[SyncDeltas])
+	}
+	mutex.Unlock()
+
+	// Once the state is valid, the later blocks are played forward
+	if err := executeStateTransfer(sts, ml, 25, 10, mrls); nil != err {
+		t.Fatalf("Fast sync case, playing forward: %s", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if requests[SyncSnapshot] != 4 || requests[SyncDeltas] == 0 {
+		t.Errorf("Expected the later blocks to be played forward with deltas, got %d partition and %d delta requests", requests[SyncSnapshot], requests[SyncDeltas])
+	}
+}
+
+func TestFastSyncResume(t *testing.T) {
+	blockNumber := uint64(20)
+	mrls := createRemoteLedgers(1, 3)
+
+	mutex := &sync.Mutex{}
+	snapshotRequests := 0
+	disconnected := true
+	ml := NewMockLedger(mrls, func(request mockRequest, peerID *protos.PeerID) mockResponse {
+		if request != SyncSnapshot {
+			return Normal
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		snapshotRequests++
+		if disconnected && snapshotRequests > 2 {
+			return Timeout
+		}
+		return Normal
+	}, t)
+	ml.PutBlock(0, SimpleGetBlock(0))
+
+	sts := newTestStateTransfer(ml, mrls)
+	defer sts.Stop()
+	sts.fastSync = true
+	sts.snapshotPartitions = 4
+	sts.maxStateDeltas = 5
+	sts.parallelism = 1
+	sts.StateSnapshotRequestTimeout = 100 * time.Millisecond
+
+	for peerID := range mrls.remoteLedgers {
+		mrls.GetMockRemoteLedgerByPeerID(&peerID).blockHeight = blockNumber + 1
+	}
+
+	blockHash := SimpleGetBlockHash(blockNumber)
+	if err, _ := sts.SyncToTarget(blockNumber, blockHash, nil); err == nil {
+		t.Fatalf("State transfer should not have completed while the peers time out")
+	}
+
+	mutex.Lock()
+	disconnected = false
+	retrieved := snapshotRequests
+	mutex.Unlock()
+	sts.StateSnapshotRequestTimeout = time.Second
+
+	if err, _ := sts.SyncToTarget(blockNumber, blockHash, nil); err != nil {
+		t.Fatalf("Error resuming state transfer: %s", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if snapshotRequests-retrieved != 2 {
+		t.Errorf("Expected only the 2 partitions not retrieved yet to be requested again, got %d requests", snapshotRequests-retrieved)
+	}
+
+	if stateHash, _ := ml.GetCurrentStateHash(); !bytes.Equal(stateHash, SimpleGetStateHash(blockNumber)) {
+		t.Errorf("Current state does not validate against block %d", blockNumber)
+	}
+}
+
+func TestFastSyncBadPartition(t *testing.T) {
+	blockNumber := uint64(20)
+	mrls := createRemoteLedgers(1, 3)
+
+	// The first peer asked for the hashes of the partitions, and for a partition, serves well formed data which does
+	// not verify
+	mutex := &sync.Mutex{}
+	bad := make(map[mockRequest]protos.PeerID)
+	requests := make(map[mockRequest]int)
+	ml := NewMockLedger(mrls, func(request mockRequest, peerID *protos.PeerID) mockResponse {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests[request]++
+		if request != SyncPartitionHashes && request != SyncSnapshot {
+			return Normal
+		}
+		if _, ok := bad[request]; !ok {
+			bad[request] = *peerID
+		}
+		if bad[request] == *peerID {
+			return Corrupt
+		}
+		return Normal
+	}, t)
+	ml.PutBlock(0, SimpleGetBlock(0))
+
+	sts := newTestStateTransfer(ml, mrls)
+	defer sts.Stop()
+	sts.fastSync = true
+	sts.snapshotPartitions = 4
+	sts.maxStateDeltas = 5
+
+	for peerID := range mrls.remoteLedgers {
+		mrls.GetMockRemoteLedgerByPeerID(&peerID).blockHeight = blockNumber + 1
+	}
+
+	if err, _ := sts.SyncToTarget(blockNumber, SimpleGetBlockHash(blockNumber), nil); err != nil {
+		t.Fatalf("Fast sync should have retrieved the bad data from other peers: %s", err)
+	}
+
+	if stateHash, _ := ml.GetCurrentStateHash(); !bytes.Equal(stateHash, SimpleGetStateHash(blockNumber)) {
+		t.Errorf("Current state does not validate against block %d", blockNumber)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, request := range []mockRequest{SyncPartitionHashes, SyncSnapshot} {
+		peerID := bad[request]
+		if !sts.isBanned(&peerID) {
+			t.Errorf("Peer %v served bad data for request %d and should be banned", peerID, request)
+		}
+	}
+	if requests[SyncPartitionHashes] != 2 || requests[SyncSnapshot] < 5 {
+		t.Errorf("Expected the bad data to be retrieved again, got %d hashes and %d partition requests", requests[SyncPartitionHashes], requests[SyncSnapshot])
+	}
+}
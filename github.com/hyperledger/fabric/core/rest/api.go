@@ -24,6 +24,7 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger"
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/spf13/viper"
@@ -38,6 +39,19 @@ var (
 type PeerInfo interface {
 	GetPeers() (*pb.PeersMessage, error)
 	GetPeerEndpoint() (*pb.PeerEndpoint, error)
+	GetConsensusStatus() (*pb.ConsensusStatus, error)
+}
+
+// ChainConsensusStatus is implemented by peers which report the state of
+// the consensus plugin of each chain they host
+type ChainConsensusStatus interface {
+	GetChainConsensusStatus(chainID string) (*pb.ConsensusStatus, error)
+}
+
+// TransactionSubmissions is implemented by peers which track the state of
+// the transactions submitted to them
+type TransactionSubmissions interface {
+	GetTransactionSubmission(ctx context.Context, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error)
 }
 
 // ServerOpenchain defines the Openchain server object, which holds the
@@ -45,6 +59,7 @@ type PeerInfo interface {
 type ServerOpenchain struct {
 	ledger   *ledger.Ledger
 	peerInfo PeerInfo
+	chainID  string
 }
 
 // NewOpenchainServer creates a new instance of the ServerOpenchain.
@@ -73,6 +88,31 @@ func NewOpenchainServerWithPeerInfo(peerServer PeerInfo) (*ServerOpenchain, erro
 	return s, nil
 }
 
+// ForChain returns a copy of the ServerOpenchain which serves the ledger of
+// one of the chains hosted by the peer.
+func (s *ServerOpenchain) ForChain(chainID string) (*ServerOpenchain, error) {
+	if err := db.ValidateChainID(chainID); err != nil {
+		return nil, err
+	}
+	if db.NormalizeChainID(chainID) != db.DefaultChainID && !isHostedChain(chainID) {
+		return nil, fmt.Errorf("Chain %s is not hosted by this peer", chainID)
+	}
+	ledger, err := ledger.GetChainLedger(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerOpenchain{ledger: ledger, peerInfo: s.peerInfo, chainID: chainID}, nil
+}
+
+func isHostedChain(chainID string) bool {
+	for _, hosted := range viper.GetStringSlice("peer.chains") {
+		if hosted == chainID {
+			return true
+		}
+	}
+	return false
+}
+
 // GetBlockchainInfo returns information about the blockchain ledger such as
 // height, current block hash, and previous block hash.
 func (s *ServerOpenchain) GetBlockchainInfo(ctx context.Context, e *empty.Empty) (*pb.BlockchainInfo, error) {
@@ -162,6 +202,15 @@ func (s *ServerOpenchain) GetTransactionByID(ctx context.Context, txID string) (
 	return transaction, nil
 }
 
+// GetTransactionSubmission returns the state of a transaction submitted to the target peer
+func (s *ServerOpenchain) GetTransactionSubmission(ctx context.Context, req *pb.TransactionSubmissionRequest) (*pb.TransactionSubmission, error) {
+	submissions, ok := s.peerInfo.(TransactionSubmissions)
+	if !ok {
+		return nil, fmt.Errorf("Transaction submissions are not tracked by this peer")
+	}
+	return submissions.GetTransactionSubmission(ctx, req)
+}
+
 // GetPeers returns a list of all peer nodes currently connected to the target peer.
 func (s *ServerOpenchain) GetPeers(ctx context.Context, e *empty.Empty) (*pb.PeersMessage, error) {
 	return s.peerInfo.GetPeers()
@@ -178,3 +227,15 @@ func (s *ServerOpenchain) GetPeerEndpoint(ctx context.Context, e *empty.Empty) (
 	peersMessage := &pb.PeersMessage{Peers: peers}
 	return peersMessage, nil
 }
+
+// GetConsensusStatus returns a snapshot of the state of the consensus plugin of target peer,
+// for the chain of the server.
+func (s *ServerOpenchain) GetConsensusStatus(ctx context.Context, e *empty.Empty) (*pb.ConsensusStatus, error) {
+	if chains, ok := s.peerInfo.(ChainConsensusStatus); ok {
+		return chains.GetChainConsensusStatus(s.chainID)
+	}
+	if db.NormalizeChainID(s.chainID) != db.DefaultChainID {
+		return nil, fmt.Errorf("Consensus status of chain %s is not available on this peer", s.chainID)
+	}
+	return s.peerInfo.GetConsensusStatus()
+}
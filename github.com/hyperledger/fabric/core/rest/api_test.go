@@ -70,6 +70,10 @@ func (p *peerInfo) GetPeerEndpoint() (*protos.PeerEndpoint, error) {
 	return pe, nil
 }
 
+func (p *peerInfo) GetConsensusStatus() (*protos.ConsensusStatus, error) {
+	return &protos.ConsensusStatus{Plugin: "noops"}, nil
+}
+
 func TestServerOpenchain_API_GetBlockchainInfo(t *testing.T) {
 	// Construct a ledger with 0 blocks.
 	ledger := ledger.InitTestLedger(t)
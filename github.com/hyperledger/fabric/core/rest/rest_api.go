@@ -17,17 +17,20 @@ limitations under the License.
 package rest
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"golang.org/x/net/context"
 
@@ -58,8 +61,9 @@ var serverDevops pb.DevopsServer
 // the methods available on the ServerOpenchain service and the Devops service
 // through a REST API.
 type ServerOpenchainREST struct {
-	server *ServerOpenchain
-	devops pb.DevopsServer
+	server  *ServerOpenchain
+	devops  pb.DevopsServer
+	chainID string // The chain selected by the chain query parameter
 }
 
 // restResult defines the response payload for a general REST interface request.
@@ -158,13 +162,35 @@ var (
 
 // SetOpenchainServer is a middleware function that sets the pointer to the
 // underlying ServerOpenchain object and the undeflying Devops object.
+// The chain query parameter selects the chain of the request among the
+// chains hosted by the peer, the default chain is used when it is absent.
 func (s *ServerOpenchainREST) SetOpenchainServer(rw web.ResponseWriter, req *web.Request, next web.NextMiddlewareFunc) {
 	s.server = serverOpenchain
 	s.devops = serverDevops
 
+	if chainID := req.URL.Query().Get("chain"); chainID != "" {
+		server, err := serverOpenchain.ForChain(chainID)
+		if err != nil {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(rw).Encode(restResult{Error: err.Error()})
+			return
+		}
+		s.server = server
+		s.chainID = chainID
+	}
+
 	next(rw, req)
 }
 
+// setChain sets the chain selected by the request on a chaincode
+// specification which does not name its chain
+func (s *ServerOpenchainREST) setChain(spec *pb.ChaincodeSpec) {
+	if spec != nil && spec.ChainID == "" {
+		spec.ChainID = s.chainID
+	}
+}
+
 // SetResponseType is a middleware function that sets the appropriate response
 // headers. Currently, it is setting the "Content-Type" to "application/json" as
 // well as the necessary headers in order to enable CORS for Swagger usage.
@@ -718,6 +744,33 @@ func (s *ServerOpenchainREST) GetTransactionByID(rw web.ResponseWriter, req *web
 	}
 }
 
+// GetTransactionSubmission returns the state of a transaction submitted to
+// the peer, which clients poll until the transaction commits or the
+// submission fails
+func (s *ServerOpenchainREST) GetTransactionSubmission(rw web.ResponseWriter, req *web.Request) {
+	// Parse out the transaction ID
+	txID := req.PathParams["id"]
+
+	submission, err := s.server.GetTransactionSubmission(context.Background(), &pb.TransactionSubmissionRequest{Txid: txID, ChainID: s.chainID})
+
+	encoder := json.NewEncoder(rw)
+
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Error retrieving the submission of transaction %s: %s.", txID, err)})
+		restLogger.Errorf("Error retrieving the submission of transaction %s: %s", txID, err)
+		return
+	}
+	if submission.State == pb.TransactionSubmission_UNKNOWN {
+		rw.WriteHeader(http.StatusNotFound)
+		encoder.Encode(restResult{Error: fmt.Sprintf("Transaction %s is not found.", txID)})
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	encoder.Encode(submission)
+}
+
 // Deploy first builds the chaincode package and subsequently deploys it to the
 // blockchain.
 //
@@ -847,6 +900,7 @@ func (s *ServerOpenchainREST) Deploy(rw web.ResponseWriter, req *web.Request) {
 	}
 
 	// Deploy the ChaincodeSpec
+	s.setChain(&spec)
 	chaincodeDeploymentSpec, err := s.devops.Deploy(context.Background(), &spec)
 	if err != nil {
 		// Replace " characters with '
@@ -988,6 +1042,7 @@ func (s *ServerOpenchainREST) Invoke(rw web.ResponseWriter, req *web.Request) {
 	}
 
 	// Invoke the chainCode
+	s.setChain(spec.ChaincodeSpec)
 	resp, err := s.devops.Invoke(context.Background(), &spec)
 	if err != nil {
 		// Replace " characters with '
@@ -1130,6 +1185,7 @@ func (s *ServerOpenchainREST) Query(rw web.ResponseWriter, req *web.Request) {
 	}
 
 	// Query the chainCode
+	s.setChain(spec.ChaincodeSpec)
 	resp, err := s.devops.Query(context.Background(), &spec)
 	if err != nil {
 		// Replace " characters with '
@@ -1409,6 +1465,15 @@ func (s *ServerOpenchainREST) processChaincodeDeploy(spec *pb.ChaincodeSpec) rpc
 		return error
 	}
 
+	// Check that the timeout, if any, is not negative.
+	if spec.Timeout < 0 {
+		// Format the error appropriately for further processing
+		error := formatRPCError(InvalidParams.Code, InvalidParams.Message, "Chaincode timeout may not be negative.")
+		restLogger.Error("Chaincode timeout may not be negative.")
+
+		return error
+	}
+
 	//
 	// Check if security is enabled
 	//
@@ -1471,6 +1536,7 @@ func (s *ServerOpenchainREST) processChaincodeDeploy(spec *pb.ChaincodeSpec) rpc
 	//
 	// Trigger the chaincode deployment through the devops service
 	//
+	s.setChain(spec)
 	chaincodeDeploymentSpec, err := s.devops.Deploy(context.Background(), spec)
 
 	//
@@ -1533,6 +1599,15 @@ func (s *ServerOpenchainREST) processChaincodeInvokeOrQuery(method string, spec
 		return error
 	}
 
+	// Check that the timeout, if any, is not negative.
+	if spec.ChaincodeSpec.Timeout < 0 {
+		// Format the error appropriately for further processing
+		error := formatRPCError(InvalidParams.Code, InvalidParams.Message, "Chaincode timeout may not be negative.")
+		restLogger.Error("Chaincode timeout may not be negative.")
+
+		return error
+	}
+
 	//
 	// Check if security is enabled
 	//
@@ -1597,6 +1672,8 @@ func (s *ServerOpenchainREST) processChaincodeInvokeOrQuery(method string, spec
 	//
 	var result rpcResult
 
+	s.setChain(spec.ChaincodeSpec)
+
 	// Check the method that is being requested and execute either an invoke or a query
 	if method == "invoke" {
 
@@ -1710,6 +1787,58 @@ func (s *ServerOpenchainREST) GetPeers(rw web.ResponseWriter, req *web.Request)
 	}
 }
 
+// GetConsensusStatus returns a snapshot of the state of the consensus plugin of the target peer
+func (s *ServerOpenchainREST) GetConsensusStatus(rw web.ResponseWriter, req *web.Request) {
+	status, err := s.server.GetConsensusStatus(context.Background(), &empty.Empty{})
+
+	encoder := json.NewEncoder(rw)
+
+	// Check for error
+	if err != nil {
+		// Failure
+		rw.WriteHeader(http.StatusBadRequest)
+		encoder.Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: Querying consensus status -- %s", err)
+	} else {
+		// Success
+		rw.WriteHeader(http.StatusOK)
+		encoder.Encode(status)
+	}
+}
+
+// GetHealth returns the result of the health checks of the peer, with status
+// 503 when the peer is unhealthy.
+func (s *ServerOpenchainREST) GetHealth(rw web.ResponseWriter, req *web.Request) {
+	health := core.CheckHealth()
+	writeHealth(rw, health, health.Healthy)
+}
+
+// GetReadiness returns the result of the health checks of the peer, with
+// status 503 when the peer is not ready to serve requests.
+func (s *ServerOpenchainREST) GetReadiness(rw web.ResponseWriter, req *web.Request) {
+	health := core.CheckHealth()
+	writeHealth(rw, health, health.Ready)
+}
+
+// writeHealth writes the result of the health checks with all their fields,
+// so that a failed check is not mistaken for a missing one
+func writeHealth(rw web.ResponseWriter, health *pb.Health, ok bool) {
+	marshaler := &jsonpb.Marshaler{EmitDefaults: true}
+	out, err := marshaler.MarshalToString(health)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(rw).Encode(restResult{Error: err.Error()})
+		restLogger.Errorf("Error: Marshaling health -- %s", err)
+		return
+	}
+	if ok {
+		rw.WriteHeader(http.StatusOK)
+	} else {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+	fmt.Fprintln(rw, out)
+}
+
 // NotFound returns a custom landing page when a given hyperledger end point
 // had not been defined.
 func (s *ServerOpenchainREST) NotFound(rw web.ResponseWriter, r *web.Request) {
@@ -1738,8 +1867,13 @@ func buildOpenchainRESTRouter() *web.Router {
 	router.Post("/chaincode", (*ServerOpenchainREST).ProcessChaincode)
 
 	router.Get("/transactions/:id", (*ServerOpenchainREST).GetTransactionByID)
+	router.Get("/transactions/:id/submission", (*ServerOpenchainREST).GetTransactionSubmission)
 
 	router.Get("/network/peers", (*ServerOpenchainREST).GetPeers)
+	router.Get("/network/consensus", (*ServerOpenchainREST).GetConsensusStatus)
+
+	router.Get("/health", (*ServerOpenchainREST).GetHealth)
+	router.Get("/health/ready", (*ServerOpenchainREST).GetReadiness)
 
 	// Add not found page
 	router.NotFound((*ServerOpenchainREST).NotFound)
@@ -1747,28 +1881,118 @@ func buildOpenchainRESTRouter() *web.Router {
 	return router
 }
 
+// restListener is the listener of the running REST service, closed to stop it
+var restListener struct {
+	sync.Mutex
+	lis net.Listener
+}
+
+// listenREST listens on rest.address, with TLS if it is enabled
+func listenREST() (net.Listener, error) {
+	lis, err := net.Listen("tcp", viper.GetString("rest.address"))
+	if err != nil {
+		return nil, err
+	}
+	if !comm.TLSEnabled() {
+		return lis, nil
+	}
+	cert, err := tls.LoadX509KeyPair(viper.GetString("peer.tls.cert.file"), viper.GetString("peer.tls.key.file"))
+	if err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return tls.NewListener(lis, &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"http/1.1"}}), nil
+}
+
 // StartOpenchainRESTServer initializes the REST service and adds the required
-// middleware and routes.
+// middleware and routes. It returns when the REST service stops.
 func StartOpenchainRESTServer(server *ServerOpenchain, devops *core.Devops) {
 	// Initialize the REST service object
 	restLogger.Infof("Initializing the REST service on %s, TLS is %s.", viper.GetString("rest.address"), (map[bool]string{true: "enabled", false: "disabled"})[comm.TLSEnabled()])
 
+	// Start server
+	lis, err := listenREST()
+	if err != nil {
+		restLogger.Errorf("Error starting the REST service: %s", err)
+		return
+	}
+	serveREST(lis, useRESTListener(server, devops, lis))
+}
+
+// RestartOpenchainRESTServer applies a new REST configuration: the REST
+// service is stopped if it is disabled, and otherwise moved to rest.address.
+// The new address is bound before the service on the old one is stopped, so
+// the service keeps running on the old address if the new one is not
+// available, and the error is returned.
+func RestartOpenchainRESTServer(server *ServerOpenchain, devops *core.Devops) error {
+	if !viper.GetBool("rest.enabled") {
+		StopOpenchainRESTServer()
+		return nil
+	}
+	restLogger.Infof("Initializing the REST service on %s, TLS is %s.", viper.GetString("rest.address"), (map[bool]string{true: "enabled", false: "disabled"})[comm.TLSEnabled()])
+	lis, err := listenREST()
+	if err != nil {
+		return fmt.Errorf("Error starting the REST service on %s: %s", viper.GetString("rest.address"), err)
+	}
+	go serveREST(lis, useRESTListener(server, devops, lis))
+	return nil
+}
+
+// useRESTListener records the listener as the one of the REST service, and
+// stops the service on the previous one. It returns the router to serve.
+func useRESTListener(server *ServerOpenchain, devops *core.Devops, lis net.Listener) *web.Router {
+	restListener.Lock()
+	defer restListener.Unlock()
 	// Record the pointer to the underlying ServerOpenchain and Devops objects.
 	serverOpenchain = server
 	serverDevops = devops
+	if restListener.lis != nil {
+		restListener.lis.Close()
+	}
+	restListener.lis = lis
+	return buildOpenchainRESTRouter()
+}
 
-	router := buildOpenchainRESTRouter()
+// serveREST serves the REST service on the listener until it is stopped
+func serveREST(lis net.Listener, router *web.Router) {
+	err := http.Serve(lis, router)
 
-	// Start server
-	if comm.TLSEnabled() {
-		err := http.ListenAndServeTLS(viper.GetString("rest.address"), viper.GetString("peer.tls.cert.file"), viper.GetString("peer.tls.key.file"), router)
-		if err != nil {
-			restLogger.Errorf("ListenAndServeTLS: %s", err)
-		}
+	restListener.Lock()
+	stopped := restListener.lis != lis
+	if !stopped {
+		restListener.lis = nil
+	}
+	restListener.Unlock()
+	if stopped {
+		restLogger.Infof("REST service on %s stopped", lis.Addr())
 	} else {
-		err := http.ListenAndServe(viper.GetString("rest.address"), router)
-		if err != nil {
-			restLogger.Errorf("ListenAndServe: %s", err)
+		restLogger.Errorf("Serve: %s", err)
+	}
+}
+
+// StopOpenchainRESTServer stops the REST service, if it is running
+func StopOpenchainRESTServer() {
+	restListener.Lock()
+	defer restListener.Unlock()
+	if restListener.lis != nil {
+		restListener.lis.Close()
+		restListener.lis = nil
+	}
+}
+
+// ValidateRESTConfig checks the REST settings of a new configuration
+func ValidateRESTConfig(v *viper.Viper) error {
+	if v.GetBool("rest.enabled") {
+		if _, _, err := net.SplitHostPort(v.GetString("rest.address")); err != nil {
+			return fmt.Errorf("Invalid rest.address: %s", err)
 		}
 	}
+	pattern := v.GetString("rest.validPatterns.enrollmentID")
+	if pattern == "" {
+		return errors.New("Missing configuration key rest.validPatterns.enrollmentID")
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("Invalid rest.validPatterns.enrollmentID: %s", err)
+	}
+	return nil
 }
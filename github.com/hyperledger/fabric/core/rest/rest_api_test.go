@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -29,6 +30,10 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/spf13/viper"
+
+	"github.com/hyperledger/fabric/core"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/protos"
 )
@@ -780,6 +785,35 @@ func TestServerOpenchainREST_API_Chaincode_Query(t *testing.T) {
 	}
 }
 
+func TestServerOpenchainREST_API_GetHealth(t *testing.T) {
+	core.RegisterHealthCheck(core.HealthCheck{Name: "state transfer", Check: func() (protos.HealthCheck_Status, string) {
+		return protos.HealthCheck_NOT_READY, "State transfer in progress"
+	}})
+
+	// Start the HTTP REST test server
+	httpServer := httptest.NewServer(buildOpenchainRESTRouter())
+	defer httpServer.Close()
+
+	for path, code := range map[string]int{"/health": http.StatusOK, "/health/ready": http.StatusServiceUnavailable} {
+		response, err := http.Get(httpServer.URL + path)
+		if err != nil {
+			t.Fatalf("Error attempt to GET %s: %v", path, err)
+		}
+		var health protos.Health
+		err = jsonpb.Unmarshal(response.Body, &health)
+		response.Body.Close()
+		if err != nil {
+			t.Fatalf("Invalid JSON response: %v", err)
+		}
+		if response.StatusCode != code {
+			t.Errorf("Expected status %d from %s but got %d", code, path, response.StatusCode)
+		}
+		if !health.Healthy || health.Ready || len(health.Checks) == 0 {
+			t.Errorf("Expected the peer to be healthy but not ready, got %s", &health)
+		}
+	}
+}
+
 func TestServerOpenchainREST_API_NotFound(t *testing.T) {
 	httpServer := httptest.NewServer(buildOpenchainRESTRouter())
 	defer httpServer.Close()
@@ -789,3 +823,46 @@ func TestServerOpenchainREST_API_NotFound(t *testing.T) {
 		t.Errorf("Expected an error when accessing non-existing endpoint, but got %#v", res.Error)
 	}
 }
+
+func TestRestartOpenchainRESTServer(t *testing.T) {
+	enabled, address := viper.GetBool("rest.enabled"), viper.GetString("rest.address")
+	defer func() {
+		viper.Set("rest.enabled", enabled)
+		viper.Set("rest.address", address)
+		StopOpenchainRESTServer()
+	}()
+	listener := func() net.Listener {
+		restListener.Lock()
+		defer restListener.Unlock()
+		return restListener.lis
+	}
+
+	viper.Set("rest.enabled", true)
+	viper.Set("rest.address", "127.0.0.1:0")
+	if err := RestartOpenchainRESTServer(nil, nil); err != nil {
+		t.Fatalf("Error starting the REST service: %s", err)
+	}
+	lis := listener()
+	if lis == nil {
+		t.Fatalf("Expected the REST service to be listening")
+	}
+
+	// The REST service keeps running when the new address is not available
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err)
+	}
+	defer taken.Close()
+	viper.Set("rest.address", taken.Addr().String())
+	if err := RestartOpenchainRESTServer(nil, nil); err == nil {
+		t.Fatalf("Expected an error moving the REST service to an address in use")
+	}
+	if listener() != lis {
+		t.Fatalf("Expected the REST service to keep running on its address")
+	}
+
+	viper.Set("rest.enabled", false)
+	if err := RestartOpenchainRESTServer(nil, nil); err != nil || listener() != nil {
+		t.Errorf("Expected the REST service to stop, got %v", err)
+	}
+}
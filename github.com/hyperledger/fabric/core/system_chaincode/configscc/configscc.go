@@ -0,0 +1,334 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configscc implements the configuration system chaincode. Its state
+// holds the network-wide parameters all validators must agree on. They are
+// changed by updates signed by the network administrators, and take effect
+// at the block named by the update, so that every validator switches to the
+// new values at the same point of the chain.
+package configscc
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/spf13/viper"
+)
+
+var logger = shim.NewLogger("configscc")
+
+const (
+	// ChaincodeName is the name the configuration system chaincode is
+	// deployed with, its state is read under this name
+	ChaincodeName = "configscc"
+
+	// ChaincodePath is the path of the configuration system chaincode
+	ChaincodePath = "github.com/hyperledger/fabric/core/system_chaincode/configscc"
+
+	sequenceKey    = "sequence"
+	parameterKey   = "parameter."
+	historyEntries = 16
+)
+
+// Parameter validators, by parameter name. Only these parameters can be set.
+var parameters = map[string]func(value string) error{
+	"pbft.general.batchsize": func(value string) error {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return errors.New("must be a positive integer")
+		}
+		return nil
+	},
+	"pbft.general.timeout.batch": func(value string) error {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return errors.New("must be a positive duration")
+		}
+		return nil
+	},
+}
+
+// Update is a change of parameters signed by the administrators
+type Update struct {
+	// Sequence must be one more than the sequence of the last update
+	// applied, so that an update is never applied twice
+	Sequence uint64 `json:"sequence"`
+	// EffectiveBlock is the number of the first block the new values apply
+	// to. Values of an update naming a block already on the chain apply from
+	// the block after the one holding the update.
+	EffectiveBlock uint64            `json:"effectiveBlock"`
+	Parameters     map[string]string `json:"parameters"`
+}
+
+// Value is a value a parameter has from a block on
+type Value struct {
+	Value          string `json:"value"`
+	EffectiveBlock uint64 `json:"effectiveBlock"`
+	Sequence       uint64 `json:"sequence"`
+}
+
+// SystemChaincode is the configuration system chaincode. The administrators
+// and the number of their signatures an update needs are read from the
+// chaincode.configscc section of the configuration, which must be the same
+// on all validators.
+type SystemChaincode struct {
+	admins    []*ecdsa.PublicKey
+	threshold int
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// SignUpdate returns the base64 encoded signature of an administrator over
+// a marshaled update, as expected by the update function
+func SignUpdate(key *ecdsa.PrivateKey, update []byte) (string, error) {
+	digest := sha256.Sum256(update)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	raw, err := asn1.Marshal(ecdsaSignature{r, s})
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func (t *SystemChaincode) getAdmins() ([]*ecdsa.PublicKey, int, error) {
+	if t.admins != nil {
+		return t.admins, t.threshold, nil
+	}
+	var admins []*ecdsa.PublicKey
+	for _, file := range viper.GetStringSlice("chaincode.configscc.admins") {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error reading administrator certificate %s: %s", file, err)
+		}
+		cert, err := primitives.PEMtoCertificate(raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error parsing administrator certificate %s: %s", file, err)
+		}
+		key, ok := cert.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, 0, fmt.Errorf("Administrator certificate %s does not hold an ECDSA key", file)
+		}
+		admins = append(admins, key)
+	}
+	threshold := viper.GetInt("chaincode.configscc.threshold")
+	if threshold <= 0 {
+		threshold = len(admins)
+	}
+	if len(admins) == 0 || threshold > len(admins) {
+		return nil, 0, fmt.Errorf("Invalid configuration, %d signatures required of %d administrators", threshold, len(admins))
+	}
+	t.admins, t.threshold = admins, threshold
+	return admins, threshold, nil
+}
+
+// Init does not touch the state: system chaincodes are initialized by every
+// peer on its own, outside of consensus
+func (t *SystemChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+// Invoke applies an update. The first argument of the update function is
+// the JSON encoded Update, the following ones the base64 encoded signatures
+// of the administrators over it.
+func (t *SystemChaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	if function != "update" {
+		return nil, fmt.Errorf("Unsupported function %s", function)
+	}
+	if len(args) < 2 {
+		return nil, errors.New("An update and at least one signature are required")
+	}
+
+	if err := t.verify([]byte(args[0]), args[1:]); err != nil {
+		return nil, err
+	}
+	update := &Update{}
+	if err := json.Unmarshal([]byte(args[0]), update); err != nil {
+		return nil, fmt.Errorf("Error decoding update: %s", err)
+	}
+	if len(update.Parameters) == 0 {
+		return nil, errors.New("Update does not change any parameter")
+	}
+	for name, value := range update.Parameters {
+		validate, ok := parameters[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown parameter %s", name)
+		}
+		if err := validate(value); err != nil {
+			return nil, fmt.Errorf("Invalid value %q for %s: %s", value, name, err)
+		}
+	}
+
+	sequence, err := getSequence(stub)
+	if err != nil {
+		return nil, err
+	}
+	if update.Sequence != sequence+1 {
+		return nil, fmt.Errorf("Update sequence %d out of order, expected %d", update.Sequence, sequence+1)
+	}
+
+	for name, value := range update.Parameters {
+		history, err := getHistory(stub, name)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, &Value{Value: value, EffectiveBlock: update.EffectiveBlock, Sequence: update.Sequence})
+		if len(history) > historyEntries {
+			history = history[len(history)-historyEntries:]
+		}
+		raw, err := json.Marshal(history)
+		if err != nil {
+			return nil, err
+		}
+		if err = stub.PutState(parameterKey+name, raw); err != nil {
+			return nil, err
+		}
+	}
+	if err = stub.PutState(sequenceKey, []byte(strconv.FormatUint(update.Sequence, 10))); err != nil {
+		return nil, err
+	}
+	logger.Infof("Applied configuration update %d effective at block %d", update.Sequence, update.EffectiveBlock)
+	return nil, nil
+}
+
+// verify checks that update is signed by enough distinct administrators
+func (t *SystemChaincode) verify(update []byte, signatures []string) error {
+	admins, threshold, err := t.getAdmins()
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(update)
+	signed := make(map[int]bool)
+	for _, encoded := range signatures {
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("Error decoding signature: %s", err)
+		}
+		sig := &ecdsaSignature{}
+		if _, err = asn1.Unmarshal(raw, sig); err != nil {
+			return fmt.Errorf("Error decoding signature: %s", err)
+		}
+		for i, admin := range admins {
+			if !signed[i] && ecdsa.Verify(admin, digest[:], sig.R, sig.S) {
+				signed[i] = true
+				break
+			}
+		}
+	}
+	if len(signed) < threshold {
+		return fmt.Errorf("Update signed by %d administrators, %d required", len(signed), threshold)
+	}
+	return nil
+}
+
+// Query returns, for the get function, the JSON encoded values a parameter
+// had and will have, and for the sequence function the sequence of the last
+// update applied
+func (t *SystemChaincode) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	switch function {
+	case "get":
+		if len(args) != 1 {
+			return nil, errors.New("The get function takes the name of a parameter")
+		}
+		return stub.GetState(parameterKey + args[0])
+	case "sequence":
+		sequence, err := getSequence(stub)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.FormatUint(sequence, 10)), nil
+	}
+	return nil, fmt.Errorf("Unsupported function %s", function)
+}
+
+func getSequence(stub shim.ChaincodeStubInterface) (uint64, error) {
+	raw, err := stub.GetState(sequenceKey)
+	if err != nil || raw == nil {
+		return 0, err
+	}
+	return strconv.ParseUint(string(raw), 10, 64)
+}
+
+func getHistory(stub shim.ChaincodeStubInterface, name string) ([]*Value, error) {
+	raw, err := stub.GetState(parameterKey + name)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	return decodeHistory(name, raw)
+}
+
+func decodeHistory(name string, raw []byte) ([]*Value, error) {
+	var history []*Value
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return nil, fmt.Errorf("Error decoding values of %s: %s", name, err)
+	}
+	return history, nil
+}
+
+// StateReader reads the committed state of a chaincode, as the ledger does
+type StateReader interface {
+	GetState(chaincodeID string, key string, committed bool) ([]byte, error)
+}
+
+// Read returns the value a parameter has for the block with the given
+// number, according to the committed state. found is false if the parameter
+// was never set, in which case the local configuration applies.
+func Read(state StateReader, name string, block uint64) (value string, found bool, err error) {
+	raw, err := state.GetState(ChaincodeName, parameterKey+name, true)
+	if err != nil || raw == nil {
+		return "", false, err
+	}
+	history, err := decodeHistory(name, raw)
+	if err != nil {
+		return "", false, err
+	}
+	// The latest update effective at block wins
+	sort.Sort(byEffectiveBlock(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].EffectiveBlock <= block {
+			return history[i].Value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+type byEffectiveBlock []*Value
+
+func (v byEffectiveBlock) Len() int      { return len(v) }
+func (v byEffectiveBlock) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v byEffectiveBlock) Less(i, j int) bool {
+	if v[i].EffectiveBlock == v[j].EffectiveBlock {
+		return v[i].Sequence < v[j].Sequence
+	}
+	return v[i].EffectiveBlock < v[j].EffectiveBlock
+}
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configscc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// mockState reads the state of a MockStub as the ledger would
+type mockState struct {
+	stub *shim.MockStub
+}
+
+func (s *mockState) GetState(chaincodeID string, key string, committed bool) ([]byte, error) {
+	return s.stub.GetState(key)
+}
+
+func newAdmins(t *testing.T, n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %s", err)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+func newConfigStub(admins []*ecdsa.PrivateKey, threshold int) *shim.MockStub {
+	cc := &SystemChaincode{threshold: threshold}
+	for _, key := range admins {
+		cc.admins = append(cc.admins, &key.PublicKey)
+	}
+	return shim.NewMockStub(ChaincodeName, cc)
+}
+
+func signedUpdate(t *testing.T, update *Update, signers ...*ecdsa.PrivateKey) []string {
+	raw, err := json.Marshal(update)
+	if err != nil {
+		t.Fatalf("Failed to marshal update: %s", err)
+	}
+	args := []string{string(raw)}
+	for _, key := range signers {
+		sig, err := SignUpdate(key, raw)
+		if err != nil {
+			t.Fatalf("Failed to sign update: %s", err)
+		}
+		args = append(args, sig)
+	}
+	return args
+}
+
+func TestUpdateRequiresThreshold(t *testing.T) {
+	admins := newAdmins(t, 3)
+	stub := newConfigStub(admins, 2)
+	update := &Update{Sequence: 1, EffectiveBlock: 10, Parameters: map[string]string{"pbft.general.batchsize": "100"}}
+
+	if _, err := stub.MockInvoke("1", "update", signedUpdate(t, update, admins[0])); err == nil {
+		t.Fatal("Expected an update signed by a single administrator to be refused")
+	}
+	if _, err := stub.MockInvoke("2", "update", signedUpdate(t, update, admins[0], admins[0])); err == nil {
+		t.Fatal("Expected an update signed twice by the same administrator to be refused")
+	}
+	outsider := newAdmins(t, 1)[0]
+	if _, err := stub.MockInvoke("3", "update", signedUpdate(t, update, admins[0], outsider)); err == nil {
+		t.Fatal("Expected an update signed by a non administrator to be refused")
+	}
+
+	args := signedUpdate(t, update, admins[0], admins[2])
+	if _, err := stub.MockInvoke("4", "update", args); err != nil {
+		t.Fatalf("Update signed by two administrators failed: %s", err)
+	}
+	if _, err := stub.MockInvoke("5", "update", args); err == nil {
+		t.Fatal("Expected a replayed update to be refused")
+	}
+
+	sequence, err := stub.MockQuery("sequence", nil)
+	if err != nil || string(sequence) != "1" {
+		t.Fatalf("Expected sequence 1, got %s (%v)", sequence, err)
+	}
+}
+
+func TestUpdateValidatesParameters(t *testing.T) {
+	admins := newAdmins(t, 1)
+	stub := newConfigStub(admins, 1)
+
+	invalid := []map[string]string{
+		{"pbft.general.batchsize": "-1"},
+		{"pbft.general.timeout.batch": "soon"},
+		{"pbft.general.N": "7"},
+		{},
+	}
+	for i, params := range invalid {
+		update := &Update{Sequence: 1, Parameters: params}
+		if _, err := stub.MockInvoke("1", "update", signedUpdate(t, update, admins[0])); err == nil {
+			t.Errorf("Expected update %d to be refused", i)
+		}
+	}
+}
+
+func TestReadAtBlock(t *testing.T) {
+	admins := newAdmins(t, 1)
+	stub := newConfigStub(admins, 1)
+	state := &mockState{stub}
+
+	if _, found, err := Read(state, "pbft.general.batchsize", 5); found || err != nil {
+		t.Fatalf("Expected the parameter not to be set, got found=%v err=%v", found, err)
+	}
+
+	updates := []*Update{
+		{Sequence: 1, EffectiveBlock: 10, Parameters: map[string]string{"pbft.general.batchsize": "100", "pbft.general.timeout.batch": "2s"}},
+		{Sequence: 2, EffectiveBlock: 20, Parameters: map[string]string{"pbft.general.batchsize": "200"}},
+		// takes effect before the previous update
+		{Sequence: 3, EffectiveBlock: 15, Parameters: map[string]string{"pbft.general.batchsize": "150"}},
+	}
+	for i, update := range updates {
+		if _, err := stub.MockInvoke("tx", "update", signedUpdate(t, update, admins[0])); err != nil {
+			t.Fatalf("Update %d failed: %s", i, err)
+		}
+	}
+
+	expected := map[uint64]string{9: "", 10: "100", 14: "100", 15: "150", 19: "150", 20: "200", 100: "200"}
+	for block, value := range expected {
+		actual, found, err := Read(state, "pbft.general.batchsize", block)
+		if err != nil {
+			t.Fatalf("Failed to read batch size at block %d: %s", block, err)
+		}
+		if found != (value != "") || actual != value {
+			t.Errorf("Expected batch size %q at block %d, got %q (found=%v)", value, block, actual, found)
+		}
+	}
+	if value, _, _ := Read(state, "pbft.general.timeout.batch", 30); value != "2s" {
+		t.Errorf("Expected batch timeout 2s, got %q", value)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/hyperledger/fabric/core/system_chaincode/api"
 	//import system chain codes here
 	"github.com/hyperledger/fabric/bddtests/syschaincode/noop"
+	"github.com/hyperledger/fabric/core/system_chaincode/configscc"
 )
 
 //see systemchaincode_test.go for an example using "sample_syscc"
@@ -30,12 +31,22 @@ var systemChaincodes = []*api.SystemChaincode{
 		Path:      "github.com/hyperledger/fabric/bddtests/syschaincode/noop",
 		InitArgs:  [][]byte{},
 		Chaincode: &noop.SystemChaincode{},
+	},
+	{
+		Enabled:   true,
+		Name:      configscc.ChaincodeName,
+		Path:      configscc.ChaincodePath,
+		InitArgs:  [][]byte{},
+		Chaincode: &configscc.SystemChaincode{},
 	}}
 
 //RegisterSysCCs is the hook for system chaincodes where system chaincodes are registered with the fabric
 //note the chaincode must still be deployed and launched like a user chaincode will be
-func RegisterSysCCs() {
+func RegisterSysCCs() error {
 	for _, sysCC := range systemChaincodes {
-		api.RegisterSysCC(sysCC)
+		if err := api.RegisterSysCC(sysCC); err != nil {
+			return err
+		}
 	}
+	return nil
 }
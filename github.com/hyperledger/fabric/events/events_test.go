@@ -65,6 +65,9 @@ func (a *Adapter) Recv(msg *ehpb.Event) (bool, error) {
 	switch x := msg.Event.(type) {
 	case *ehpb.Event_Block, *ehpb.Event_ChaincodeEvent, *ehpb.Event_Register, *ehpb.Event_Unregister:
 		a.updateCountNotify()
+	case *ehpb.Event_Shutdown:
+		a.updateCountNotify()
+		return false, nil
 	case nil:
 		// The field is not set.
 		return false, fmt.Errorf("event not set")
@@ -195,6 +198,25 @@ func TestFailReceive(t *testing.T) {
 	}
 }
 
+func TestFailReceiveOtherChain(t *testing.T) {
+	var err error
+
+	adapter.count = 1
+	emsg := createTestBlock()
+	emsg.ChainID = "chain1"
+	if err = producer.Send(emsg); err != nil {
+		t.Fail()
+		t.Logf("Error sending message %s", err)
+	}
+
+	select {
+	case <-adapter.notfy:
+		t.Fail()
+		t.Logf("should NOT have received the block of another chain")
+	case <-time.After(2 * time.Second):
+	}
+}
+
 func TestUnregister(t *testing.T) {
 	var err error
 	obcEHClient.RegisterAsync([]*ehpb.Interest{&ehpb.Interest{EventType: ehpb.EventType_CHAINCODE, RegInfo: &ehpb.Interest_ChaincodeRegInfo{ChaincodeRegInfo: &ehpb.ChaincodeReg{ChaincodeID: "0xffffffff", EventName: "event10"}}}})
@@ -245,6 +267,32 @@ func TestUnregister(t *testing.T) {
 
 }
 
+// Test the shutdown of the event hub, which ends the stream the other tests use
+func TestShutdown(t *testing.T) {
+	adapter.count = 1
+	producer.Shutdown("Peer is shutting down")
+	select {
+	case <-adapter.notfy:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out on shutdown event")
+	}
+
+	// Once shut down, events are not sent anymore and streams are refused
+	if err := producer.Send(createTestChaincodeEvent("0xffffffff", "event1")); err != nil {
+		t.Fatalf("Error sending message %s", err)
+	}
+	select {
+	case <-adapter.notfy:
+		t.Fatalf("should NOT have received event after shutdown")
+	case <-time.After(time.Second):
+	}
+	client, _ := consumer.NewEventsClient(peerAddress, time.Second, &Adapter{notfy: make(chan struct{}, 1)})
+	if err := client.Start(); err == nil {
+		client.Stop()
+		t.Errorf("Expected the stream to be refused after shutdown")
+	}
+}
+
 func BenchmarkMessages(b *testing.B) {
 	numMessages := 10000
 
@@ -30,7 +30,8 @@ func CreateChaincodeEvent(te *ehpb.ChaincodeEvent) *ehpb.Event {
 	return &ehpb.Event{Event: &ehpb.Event_ChaincodeEvent{ChaincodeEvent: te}}
 }
 
-//CreateRejectionEvent creates an Event from TxResults
+//CreateRejectionEvent creates an Event from TxResults, on the chain of the
+//transaction
 func CreateRejectionEvent(tx *ehpb.Transaction, errorMsg string) *ehpb.Event {
-	return &ehpb.Event{Event: &ehpb.Event_Rejection{Rejection: &ehpb.Rejection{Tx: tx, ErrorMsg: errorMsg}}}
+	return &ehpb.Event{Event: &ehpb.Event_Rejection{Rejection: &ehpb.Rejection{Tx: tx, ErrorMsg: errorMsg}}, ChainID: tx.ChainID}
 }
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/fabric/core/db"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -193,7 +194,9 @@ func (hl *genericHandlerList) foreach(e *pb.Event, action func(h *handler)) {
 //
 type eventProcessor struct {
 	sync.RWMutex
-	eventConsumers map[pb.EventType]handlerList
+	//the handlers interested in each event type, each chain has its own
+	//event stream and so its own handlers
+	eventConsumers map[pb.EventType]map[string]handlerList
 
 	//we could generalize this with mutiple channels each with its own size
 	// 产生多个大小限定的channels
@@ -220,16 +223,20 @@ func (ep *eventProcessor) start() {
 		//等待事件
 		e := <-ep.eventChannel
 
-		var hl handlerList
 		eType := getMessageType(e)
 		ep.Lock()
-		if hl, _ = ep.eventConsumers[eType]; hl == nil {
+		chainConsumers, ok := ep.eventConsumers[eType]
+		if !ok {
 			producerLogger.Errorf("Event of type %s does not exist", eType)
 			ep.Unlock()
 			continue
 		}
+		hl := chainConsumers[db.NormalizeChainID(e.ChainID)]
 		//lock the handler map lock
 		ep.Unlock()
+		if hl == nil {
+			continue
+		}
 
 		hl.foreach(e, func(h *handler) {
 			if e.Event != nil {
@@ -246,7 +253,7 @@ func initializeEvents(bufferSize uint, tout int) {
 		panic("should not be called twice")
 	}
 
-	gEventProcessor = &eventProcessor{eventConsumers: make(map[pb.EventType]handlerList), eventChannel: make(chan *pb.Event, bufferSize), timeout: tout}
+	gEventProcessor = &eventProcessor{eventConsumers: make(map[pb.EventType]map[string]handlerList), eventChannel: make(chan *pb.Event, bufferSize), timeout: tout}
 
 	addInternalEventTypes()
 
@@ -263,27 +270,43 @@ func AddEventType(eventType pb.EventType) error {
 		return fmt.Errorf("event type exists %s", pb.EventType_name[int32(eventType)])
 	}
 
+	gEventProcessor.eventConsumers[eventType] = make(map[string]handlerList)
+	gEventProcessor.Unlock()
+
+	return nil
+}
+
+//newHandlerList creates the list of the handlers interested in an event type
+//on a chain
+func newHandlerList(eventType pb.EventType) handlerList {
 	switch eventType {
 	case pb.EventType_BLOCK:
-		gEventProcessor.eventConsumers[eventType] = &genericHandlerList{handlers: make(map[*handler]bool)}
+		return &genericHandlerList{handlers: make(map[*handler]bool)}
 	case pb.EventType_CHAINCODE:
-		gEventProcessor.eventConsumers[eventType] = &chaincodeHandlerList{handlers: make(map[string]map[string]map[*handler]bool)}
+		return &chaincodeHandlerList{handlers: make(map[string]map[string]map[*handler]bool)}
 	case pb.EventType_REJECTION:
-		gEventProcessor.eventConsumers[eventType] = &genericHandlerList{handlers: make(map[*handler]bool)}
+		return &genericHandlerList{handlers: make(map[*handler]bool)}
 	}
-	gEventProcessor.Unlock()
-
 	return nil
 }
 
 func registerHandler(ie *pb.Interest, h *handler) error {
-	producerLogger.Debugf("registerHandler %s", ie.EventType)
+	producerLogger.Debugf("registerHandler %s on chain %s", ie.EventType, db.NormalizeChainID(ie.ChainID))
 
 	gEventProcessor.Lock()
 	defer gEventProcessor.Unlock()
-	if hl, ok := gEventProcessor.eventConsumers[ie.EventType]; !ok {
+	chainConsumers, ok := gEventProcessor.eventConsumers[ie.EventType]
+	if !ok {
 		return fmt.Errorf("event type %s does not exist", ie.EventType)
-	} else if _, err := hl.add(ie, h); err != nil {
+	}
+	hl := chainConsumers[db.NormalizeChainID(ie.ChainID)]
+	if hl == nil {
+		if hl = newHandlerList(ie.EventType); hl == nil {
+			return fmt.Errorf("event type %s cannot be registered for", ie.EventType)
+		}
+		chainConsumers[db.NormalizeChainID(ie.ChainID)] = hl
+	}
+	if _, err := hl.add(ie, h); err != nil {
 		return fmt.Errorf("error registering handler for  %s: %s", ie.EventType, err)
 	}
 
@@ -291,12 +314,16 @@ func registerHandler(ie *pb.Interest, h *handler) error {
 }
 
 func deRegisterHandler(ie *pb.Interest, h *handler) error {
-	producerLogger.Debugf("deRegisterHandler %s", ie.EventType)
+	producerLogger.Debugf("deRegisterHandler %s on chain %s", ie.EventType, db.NormalizeChainID(ie.ChainID))
 
 	gEventProcessor.Lock()
 	defer gEventProcessor.Unlock()
-	if hl, ok := gEventProcessor.eventConsumers[ie.EventType]; !ok {
+	chainConsumers, ok := gEventProcessor.eventConsumers[ie.EventType]
+	if !ok {
 		return fmt.Errorf("event type %s does not exist", ie.EventType)
+	}
+	if hl := chainConsumers[db.NormalizeChainID(ie.ChainID)]; hl == nil {
+		return fmt.Errorf("no handler registered for %s on chain %s", ie.EventType, db.NormalizeChainID(ie.ChainID))
 	} else if _, err := hl.del(ie, h); err != nil {
 		return fmt.Errorf("error deregistering handler for %s: %s", ie.EventType, err)
 	}
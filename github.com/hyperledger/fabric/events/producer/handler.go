@@ -19,18 +19,24 @@ package producer
 import (
 	"fmt"
 	"strconv"
+	"sync"
 
+	"github.com/hyperledger/fabric/core/db"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
 type handler struct {
 	ChatStream       pb.Events_ChatServer
 	interestedEvents map[string]*pb.Interest
+	sendLock         sync.Mutex    // Serializes the sends of the processor and of the chat
+	ended            chan struct{} // Closed once the Shutdown event is sent
+	endOnce          sync.Once
 }
 
 func newEventHandler(stream pb.Events_ChatServer) (*handler, error) {
 	d := &handler{
 		ChatStream: stream,
+		ended:      make(chan struct{}),
 	}
 	d.interestedEvents = make(map[string]*pb.Interest)
 	return d, nil
@@ -55,7 +61,7 @@ func getInterestKey(interest pb.Interest) string {
 	default:
 		producerLogger.Errorf("unknown interest type %s", interest.EventType)
 	}
-	return key
+	return db.NormalizeChainID(interest.ChainID) + key
 }
 
 func (d *handler) register(iMsg []*pb.Interest) error {
@@ -112,7 +118,7 @@ func (d *handler) HandleMessage(msg *pb.Event) error {
 		return fmt.Errorf("Invalide type from client %T", msg.Event)
 	}
 	//TODO return supported events.. for now just return the received msg
-	if err := d.ChatStream.Send(msg); err != nil {
+	if err := d.SendMessage(msg); err != nil {
 		return fmt.Errorf("Error sending response to %v:  %s", msg, err)
 	}
 
@@ -121,9 +127,29 @@ func (d *handler) HandleMessage(msg *pb.Event) error {
 
 // SendMessage 通过流发送一条消息给远程的peer
 func (d *handler) SendMessage(msg *pb.Event) error {
+	d.sendLock.Lock()
+	defer d.sendLock.Unlock()
+	select {
+	case <-d.ended:
+		return fmt.Errorf("ChatStream ended")
+	default:
+	}
 	err := d.ChatStream.Send(msg)
 	if err != nil {
 		return fmt.Errorf("Error Sending message through ChatStream: %s", err)
 	}
 	return nil
 }
+
+// end sends the Shutdown event, after which no event is sent, and ends the
+// chat stream
+func (d *handler) end(reason string) {
+	d.endOnce.Do(func() {
+		if err := d.SendMessage(&pb.Event{Event: &pb.Event_Shutdown{Shutdown: &pb.Shutdown{Reason: reason}}}); err != nil {
+			producerLogger.Errorf("Error sending shutdown event: %s", err)
+		}
+		d.sendLock.Lock()
+		close(d.ended)
+		d.sendLock.Unlock()
+	})
+}
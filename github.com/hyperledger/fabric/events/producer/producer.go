@@ -19,6 +19,7 @@ package producer
 import (
 	"fmt"
 	"io"
+	"sync"
 	"time"
 
 	pb "github.com/hyperledger/fabric/protos"
@@ -31,6 +32,9 @@ var producerLogger = logging.MustGetLogger("eventhub_producer")
 
 // EventsServer implementation of the Peer service
 type EventsServer struct {
+	sync.Mutex
+	handlers map[*handler]bool // The handlers of the open chat streams
+	stopped  bool
 }
 
 //singleton - if we want to create multiple servers, we need to subsume events.gEventConsumers into EventsServer
@@ -54,23 +58,90 @@ func (p *EventsServer) Chat(stream pb.Events_ChatServer) error {
 	if err != nil {
 		return fmt.Errorf("Error creating handler during handleChat initiation: %s", err)
 	}
+	if !p.addHandler(handler) {
+		return fmt.Errorf("Event hub is shutting down")
+	}
+	defer p.removeHandler(handler)
 	defer handler.Stop()
-	for {
-		in, err := stream.Recv()
-		if err == io.EOF {
-			producerLogger.Debug("Received EOF, ending Chat")
-			return nil
+
+	// Receive in the background so that the stream can be ended when the
+	// event hub shuts down
+	received := make(chan *pb.Event)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case received <- in:
+			case <-handler.ended:
+				return
+			}
 		}
-		if err != nil {
+	}()
+
+	for {
+		select {
+		case in := <-received:
+			err = handler.HandleMessage(in)
+			if err != nil {
+				producerLogger.Errorf("Error handling message: %s", err)
+				return err
+			}
+		case err = <-recvErr:
+			if err == io.EOF {
+				producerLogger.Debug("Received EOF, ending Chat")
+				return nil
+			}
 			e := fmt.Errorf("Error during Chat, stopping handler: %s", err)
 			producerLogger.Error(e.Error())
 			return e
+		case <-handler.ended:
+			producerLogger.Debug("Event hub shut down, ending Chat")
+			return nil
 		}
-		err = handler.HandleMessage(in)
-		if err != nil {
-			producerLogger.Errorf("Error handling message: %s", err)
-			return err
-		}
+	}
+}
+
+func (p *EventsServer) addHandler(h *handler) bool {
+	p.Lock()
+	defer p.Unlock()
+	if p.stopped {
+		return false
+	}
+	if p.handlers == nil {
+		p.handlers = make(map[*handler]bool)
+	}
+	p.handlers[h] = true
+	return true
+}
+
+func (p *EventsServer) removeHandler(h *handler) {
+	p.Lock()
+	defer p.Unlock()
+	delete(p.handlers, h)
+}
+
+// Shutdown sends a Shutdown event with the reason as the last event of each
+// open chat stream and ends them. No chat stream is accepted afterwards.
+func Shutdown(reason string) {
+	if globalEventsServer == nil {
+		return
+	}
+	p := globalEventsServer
+	p.Lock()
+	p.stopped = true
+	handlers := make([]*handler, 0, len(p.handlers))
+	for h := range p.handlers {
+		handlers = append(handlers, h)
+	}
+	p.Unlock()
 
+	producerLogger.Infof("Ending %d event streams: %s", len(handlers), reason)
+	for _, h := range handlers {
+		h.end(reason)
 	}
 }
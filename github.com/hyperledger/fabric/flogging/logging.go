@@ -17,8 +17,10 @@ limitations under the License.
 package flogging
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
@@ -31,6 +33,10 @@ var loggingLogger = logging.MustGetLogger("logging")
 // case of configuration errors.
 var loggingDefaultLevel = logging.INFO
 
+// The modules whose logging level was ever overridden by LoggingInit
+var overriddenModules = make(map[string]bool)
+var overriddenLock sync.Mutex
+
 // LoggingInit is a 'hook' called at the beginning of command processing to
 // parse logging-related options specified either on the command-line or in
 // config files.  Command-line options take precedence over config file
@@ -47,6 +53,7 @@ func LoggingInit(command string) {
 	if spec == "" {
 		spec = viper.GetString("logging." + command)
 	}
+	overridden := make(map[string]bool)
 	if spec != "" {
 		fields := strings.Split(spec, ":")
 		for _, field := range fields {
@@ -69,6 +76,7 @@ func LoggingInit(command string) {
 					modules := strings.Split(split[0], ",")
 					for _, module := range modules {
 						logging.SetLevel(level, module)
+						overridden[module] = true
 						loggingLogger.Debugf("Setting logging level for module '%s' to %s", module, level)
 					}
 				}
@@ -80,6 +88,46 @@ func LoggingInit(command string) {
 	// Set the default logging level for all modules
 	logging.SetLevel(defaultLevel, "")
 	loggingLogger.Debugf("Setting default logging level to %s for command '%s'", defaultLevel, command)
+
+	// The modules overridden before and not anymore, when the configuration
+	// is reloaded, go back to the default level
+	overriddenLock.Lock()
+	defer overriddenLock.Unlock()
+	for module := range overriddenModules {
+		if !overridden[module] {
+			logging.SetLevel(defaultLevel, module)
+		}
+	}
+	for module := range overridden {
+		overriddenModules[module] = true
+	}
+}
+
+// ValidateLoggingSpec returns an error if a logging specification is not
+// valid, LoggingInit ignores the invalid parts of a specification
+func ValidateLoggingSpec(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	for _, field := range strings.Split(spec, ":") {
+		split := strings.Split(field, "=")
+		switch len(split) {
+		case 1:
+			if _, err := logging.LogLevel(field); err != nil {
+				return fmt.Errorf("Logging level '%s' not recognized", field)
+			}
+		case 2:
+			if _, err := logging.LogLevel(split[1]); err != nil {
+				return fmt.Errorf("Invalid logging level in '%s'", field)
+			}
+			if split[0] == "" {
+				return fmt.Errorf("Invalid logging override specification '%s' - no module specified", field)
+			}
+		default:
+			return fmt.Errorf("Invalid logging override '%s'; Missing ':' ?", field)
+		}
+	}
+	return nil
 }
 
 // DefaultLoggingLevel returns the fallback value for loggers to use if parsing fails
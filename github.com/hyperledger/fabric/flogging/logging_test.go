@@ -156,3 +156,27 @@ func assertEquals(t *testing.T, expected interface{}, actual interface{}) {
 		t.Errorf("Expected: %v, Got: %v", expected, actual)
 	}
 }
+
+func TestLoggingLevelOverrideRemoved(t *testing.T) {
+	viper.Reset()
+	viper.Set("logging_level", "warning:reloaded=debug")
+	flogging.LoggingInit("")
+	assertModuleLoggingLevel(t, "reloaded", logging.DEBUG)
+
+	viper.Set("logging_level", "error")
+	flogging.LoggingInit("")
+	assertModuleLoggingLevel(t, "reloaded", logging.ERROR)
+}
+
+func TestValidateLoggingSpec(t *testing.T) {
+	for _, spec := range []string{"", "info", "core,test=warning", "info:test=debug"} {
+		if err := flogging.ValidateLoggingSpec(spec); err != nil {
+			t.Errorf("Expected '%s' to be valid: %s", spec, err)
+		}
+	}
+	for _, spec := range []string{"foo", "=info", "core=foo", "core=info=debug"} {
+		if err := flogging.ValidateLoggingSpec(spec); err == nil {
+			t.Errorf("Expected '%s' to be invalid", spec)
+		}
+	}
+}
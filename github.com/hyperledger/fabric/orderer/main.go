@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/op/go-logging"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+
+	"github.com/hyperledger/fabric/flogging"
+	"github.com/hyperledger/fabric/metadata"
+	"github.com/hyperledger/fabric/orderer/solo"
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+const envPrefix = "ORDERER"
+
+var logger = logging.MustGetLogger("orderer")
+
+func main() {
+	viper.SetEnvPrefix(envPrefix)
+	viper.AutomaticEnv()
+	replacer := strings.NewReplacer(".", "_")
+	viper.SetEnvKeyReplacer(replacer)
+	viper.SetConfigName("orderer")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("./")
+	// Path to look for the config file based on GOPATH
+	gopath := os.Getenv("GOPATH")
+	for _, p := range filepath.SplitList(gopath) {
+		cfgpath := filepath.Join(p, "src/github.com/hyperledger/fabric/orderer")
+		viper.AddConfigPath(cfgpath)
+	}
+	err := viper.ReadInConfig()
+	if err != nil {
+		logger.Panicf("Fatal error when reading %s config file: %s", "orderer", err)
+	}
+
+	flogging.LoggingInit("orderer")
+	logger.Infof("Orderer (%s)", metadata.Version)
+
+	if n := viper.GetInt("server.gomaxprocs"); n > 0 {
+		runtime.GOMAXPROCS(n)
+	}
+
+	orderer, err := solo.New(viper.GetString("ledger.fileSystemPath"), viper.GetInt("batch.size"), viper.GetDuration("batch.timeout"))
+	if err != nil {
+		logger.Errorf("Failed to start the orderer: %s", err)
+		os.Exit(1)
+	}
+
+	srv := grpc.NewServer()
+	pb.RegisterAtomicBroadcastServer(srv, orderer)
+
+	sock, err := net.Listen("tcp", viper.GetString("server.address"))
+	if err != nil {
+		logger.Errorf("Failed to listen on %s: %s", viper.GetString("server.address"), err)
+		os.Exit(1)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		logger.Infof("Received %s, stopping", sig)
+		sock.Close()
+	}()
+
+	logger.Infof("Ordering service listening on %s, %d batches in the log", sock.Addr(), orderer.Height())
+	srv.Serve(sock)
+	srv.Stop()
+	if err := orderer.Close(); err != nil {
+		logger.Errorf("Failed to close the log: %s", err)
+	}
+}
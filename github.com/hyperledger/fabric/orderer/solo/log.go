@@ -0,0 +1,120 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+const logFileName = "batches.log"
+
+// diskLog appends the ordered batches to a file. Each record is the length
+// of the marshalled batch, its CRC32 checksum, and the marshalled batch.
+// A record which was only partially written when the orderer stopped is
+// discarded when the log is opened again.
+type diskLog struct {
+	file *os.File
+}
+
+// openLog opens the log in dir, creating it if needed, and returns the
+// batches it holds
+func openLog(dir string) (*diskLog, []*pb.OrderedBatch, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	batches, end, err := readBatches(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	size, err := file.Seek(0, os.SEEK_END)
+	if err == nil && size > end {
+		logger.Warningf("Discarding the last %d bytes of the log, a partially written batch", size-end)
+		err = file.Truncate(end)
+	}
+	if err == nil {
+		_, err = file.Seek(end, os.SEEK_SET)
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return &diskLog{file: file}, batches, nil
+}
+
+// readBatches reads the records of the log, it stops at the first record
+// which is incomplete or damaged and returns where it ends
+func readBatches(file *os.File) (batches []*pb.OrderedBatch, end int64, err error) {
+	reader := bufio.NewReader(file)
+	header := make([]byte, 8)
+	for {
+		if _, err = io.ReadFull(reader, header); err != nil {
+			return batches, end, nil
+		}
+		raw := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err = io.ReadFull(reader, raw); err != nil {
+			return batches, end, nil
+		}
+		if crc32.ChecksumIEEE(raw) != binary.BigEndian.Uint32(header[4:]) {
+			return batches, end, nil
+		}
+		batch := &pb.OrderedBatch{}
+		if err = proto.Unmarshal(raw, batch); err != nil {
+			return nil, 0, fmt.Errorf("Could not unmarshal batch at offset %d: %s", end, err)
+		}
+		if batch.SequenceNumber != uint64(len(batches))+1 {
+			return nil, 0, fmt.Errorf("Batch at offset %d has sequence number %d, expected %d", end, batch.SequenceNumber, len(batches)+1)
+		}
+		batches = append(batches, batch)
+		end += int64(len(header) + len(raw))
+	}
+}
+
+// append writes a batch to the log, it returns once the batch is on disk
+func (l *diskLog) append(batch *pb.OrderedBatch) error {
+	raw, err := proto.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, 8, 8+len(raw))
+	binary.BigEndian.PutUint32(record, uint32(len(raw)))
+	binary.BigEndian.PutUint32(record[4:], crc32.ChecksumIEEE(raw))
+	record = append(record, raw...)
+	if _, err = l.file.Write(record); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+func (l *diskLog) close() error {
+	return l.file.Close()
+}
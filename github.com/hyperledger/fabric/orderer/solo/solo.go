@@ -0,0 +1,215 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package solo is a single node ordering service. It orders the
+// transactions in the order it receives them, cuts them into batches, and
+// persists the batches in a log on local disk, from which it delivers them.
+// It does not tolerate any fault, and is meant as a reference
+// implementation of the AtomicBroadcast service, for development and test.
+package solo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+var logger = logging.MustGetLogger("orderer/solo")
+
+// Server implements pb.AtomicBroadcastServer
+type Server struct {
+	batchSize    int
+	batchTimeout time.Duration
+
+	mutex      sync.Mutex
+	log        *diskLog
+	batches    []*pb.OrderedBatch
+	txIDs      map[string]bool   // transactions of the log and of the pending batch
+	pending    []*pb.Transaction // transactions of the next batch
+	timer      *time.Timer
+	timerEpoch uint64        // incremented each time a batch is cut, to ignore the expiry of stale timers
+	signal     chan struct{} // closed when a batch is appended to the log
+	failed     error         // set when the log can not be written anymore
+	done       chan struct{}
+}
+
+// New creates an orderer whose log is in dir, restoring the batches the log
+// already holds. A batch is cut when it holds batchSize transactions, or
+// batchTimeout after its first transaction was received.
+func New(dir string, batchSize int, batchTimeout time.Duration) (*Server, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("Batch size must be positive")
+	}
+	log, batches, err := openLog(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open the log in %s: %s", dir, err)
+	}
+	s := &Server{
+		batchSize:    batchSize,
+		batchTimeout: batchTimeout,
+		log:          log,
+		batches:      batches,
+		txIDs:        make(map[string]bool),
+		signal:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	for _, batch := range batches {
+		for _, tx := range batch.Transactions {
+			s.txIDs[tx.Txid] = true
+		}
+	}
+	logger.Infof("Restored %d batches from the log in %s", len(batches), dir)
+	return s, nil
+}
+
+// Broadcast accepts a transaction for ordering. The transaction is only
+// durable once its batch is cut, if the orderer stops before, the
+// transaction must be broadcast again.
+func (s *Server) Broadcast(ctx context.Context, tx *pb.Transaction) (*pb.BroadcastResponse, error) {
+	if tx.Txid == "" {
+		return &pb.BroadcastResponse{Status: pb.BroadcastResponse_BAD_REQUEST, Message: "Transaction has no ID"}, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failed != nil {
+		return &pb.BroadcastResponse{Status: pb.BroadcastResponse_SERVICE_UNAVAILABLE, Message: s.failed.Error()}, nil
+	}
+	if s.txIDs[tx.Txid] {
+		return &pb.BroadcastResponse{Status: pb.BroadcastResponse_BAD_REQUEST, Message: fmt.Sprintf("Transaction %s was already ordered", tx.Txid)}, nil
+	}
+	s.txIDs[tx.Txid] = true
+	s.pending = append(s.pending, tx)
+	logger.Debugf("Received transaction %s", tx.Txid)
+
+	if len(s.pending) >= s.batchSize {
+		s.cut()
+	} else if len(s.pending) == 1 {
+		epoch := s.timerEpoch
+		s.timer = time.AfterFunc(s.batchTimeout, func() {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			if s.timerEpoch == epoch && len(s.pending) > 0 {
+				s.cut()
+			}
+		})
+	}
+
+	if s.failed != nil {
+		return &pb.BroadcastResponse{Status: pb.BroadcastResponse_SERVICE_UNAVAILABLE, Message: s.failed.Error()}, nil
+	}
+	return &pb.BroadcastResponse{Status: pb.BroadcastResponse_SUCCESS}, nil
+}
+
+// cut appends the pending transactions to the log as a new batch, it must
+// be called with the mutex held
+func (s *Server) cut() {
+	s.timerEpoch++
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.failed != nil {
+		return
+	}
+
+	batch := &pb.OrderedBatch{
+		SequenceNumber: uint64(len(s.batches)) + 1,
+		Transactions:   s.pending,
+	}
+	s.pending = nil
+	if err := s.log.append(batch); err != nil {
+		logger.Errorf("Could not append batch %d to the log, not ordering anymore: %s", batch.SequenceNumber, err)
+		s.failed = fmt.Errorf("Could not persist batch %d: %s", batch.SequenceNumber, err)
+		return
+	}
+	s.batches = append(s.batches, batch)
+	logger.Debugf("Cut batch %d with %d transactions", batch.SequenceNumber, len(batch.Transactions))
+
+	close(s.signal)
+	s.signal = make(chan struct{})
+}
+
+// Deliver sends the batches of the log from the requested sequence number,
+// and then each new batch, until the client goes away or the orderer stops
+func (s *Server) Deliver(req *pb.DeliverRequest, stream pb.AtomicBroadcast_DeliverServer) error {
+	next := req.Start
+	if next == 0 {
+		next = 1
+	}
+	logger.Debugf("Delivering batches from %d", next)
+
+	for {
+		s.mutex.Lock()
+		var batches []*pb.OrderedBatch
+		if next <= uint64(len(s.batches)) {
+			batches = s.batches[next-1:]
+		}
+		signal := s.signal
+		s.mutex.Unlock()
+
+		for _, batch := range batches {
+			if err := stream.Send(batch); err != nil {
+				return err
+			}
+			next++
+		}
+		if len(batches) > 0 {
+			continue
+		}
+
+		select {
+		case <-signal:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.done:
+			return fmt.Errorf("Orderer is stopping")
+		}
+	}
+}
+
+// Height returns the number of batches in the log
+func (s *Server) Height() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return uint64(len(s.batches))
+}
+
+// Close stops the deliveries and closes the log, the pending transactions
+// are discarded
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.timerEpoch++
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	close(s.done)
+	if s.failed == nil {
+		s.failed = fmt.Errorf("Orderer is stopping")
+	}
+	return s.log.close()
+}
@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package solo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+func newTestServer(t *testing.T, dir string, batchSize int) *Server {
+	s, err := New(dir, batchSize, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Could not create orderer: %s", err)
+	}
+	return s
+}
+
+func broadcast(t *testing.T, s *Server, txID string) *pb.BroadcastResponse {
+	resp, err := s.Broadcast(context.Background(), &pb.Transaction{Txid: txID})
+	if err != nil {
+		t.Fatalf("Broadcast of %s failed: %s", txID, err)
+	}
+	return resp
+}
+
+func waitHeight(t *testing.T, s *Server, height uint64) {
+	for i := 0; i < 100 && s.Height() < height; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.Height() != height {
+		t.Fatalf("Expected %d batches, got %d", height, s.Height())
+	}
+}
+
+func TestBatchCutting(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "orderer")
+	defer os.RemoveAll(dir)
+	s := newTestServer(t, dir, 2)
+	defer s.Close()
+
+	broadcast(t, s, "tx1")
+	broadcast(t, s, "tx2")
+	if s.Height() != 1 {
+		t.Errorf("A full batch should be cut at once")
+	}
+	broadcast(t, s, "tx3")
+	if s.Height() != 1 {
+		t.Errorf("A partial batch should wait for the batch timeout")
+	}
+	waitHeight(t, s, 2)
+
+	if resp := broadcast(t, s, "tx1"); resp.Status != pb.BroadcastResponse_BAD_REQUEST {
+		t.Errorf("A transaction should only be ordered once, got %s", resp.Status)
+	}
+	if resp := broadcast(t, s, ""); resp.Status != pb.BroadcastResponse_BAD_REQUEST {
+		t.Errorf("A transaction without ID should be rejected, got %s", resp.Status)
+	}
+}
+
+func TestLogRestore(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "orderer")
+	defer os.RemoveAll(dir)
+	s := newTestServer(t, dir, 1)
+	for i := 1; i <= 3; i++ {
+		broadcast(t, s, fmt.Sprintf("tx%d", i))
+	}
+	s.Close()
+
+	// A crash while appending the fourth batch leaves part of it in the log
+	file, err := os.OpenFile(filepath.Join(dir, logFileName), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Could not open the log: %s", err)
+	}
+	file.Write([]byte{0, 0, 0, 42, 1, 2})
+	file.Close()
+
+	s = newTestServer(t, dir, 1)
+	defer s.Close()
+	if s.Height() != 3 {
+		t.Fatalf("Expected the 3 batches of the log to be restored, got %d", s.Height())
+	}
+	if resp := broadcast(t, s, "tx2"); resp.Status != pb.BroadcastResponse_BAD_REQUEST {
+		t.Errorf("A transaction of the restored log should not be ordered again, got %s", resp.Status)
+	}
+	broadcast(t, s, "tx4")
+	if s.Height() != 4 || s.batches[3].SequenceNumber != 4 {
+		t.Errorf("The log should go on after the restored batches")
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "orderer")
+	defer os.RemoveAll(dir)
+	s := newTestServer(t, dir, 1)
+	defer s.Close()
+
+	srv := grpc.NewServer()
+	pb.RegisterAtomicBroadcastServer(srv, s)
+	sock, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %s", err)
+	}
+	go srv.Serve(sock)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(sock.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Could not dial the orderer: %s", err)
+	}
+	defer conn.Close()
+	client := pb.NewAtomicBroadcastClient(conn)
+
+	for i := 1; i <= 3; i++ {
+		if resp, err := client.Broadcast(context.Background(), &pb.Transaction{Txid: fmt.Sprintf("tx%d", i)}); err != nil || resp.Status != pb.BroadcastResponse_SUCCESS {
+			t.Fatalf("Broadcast failed: %v %v", resp, err)
+		}
+	}
+
+	stream, err := client.Deliver(context.Background(), &pb.DeliverRequest{Start: 2})
+	if err != nil {
+		t.Fatalf("Deliver failed: %s", err)
+	}
+	go s.Broadcast(context.Background(), &pb.Transaction{Txid: "tx4"})
+	for seqNo := uint64(2); seqNo <= 4; seqNo++ {
+		batch, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Delivery failed: %s", err)
+		}
+		if batch.SequenceNumber != seqNo || batch.Transactions[0].Txid != fmt.Sprintf("tx%d", seqNo) {
+			t.Errorf("Expected batch %d, got %v", seqNo, batch)
+		}
+	}
+}
@@ -48,6 +48,10 @@ func Cmd() *cobra.Command {
 		fmt.Sprint("Username for chaincode operations when security is enabled"))
 	flags.StringVarP(&customIDGenAlg, "tid", "t", common.UndefinedParamValue,
 		fmt.Sprint("Name of a custom ID generation algorithm (hashing and decoding) e.g. sha256base64"))
+	flags.Int32Var(&chaincodeTimeout, "timeout", 0,
+		fmt.Sprintf("Timeout in milliseconds for executing the %s, on deploy the default for all its invocations (0 uses the peer default)", chainFuncName))
+	flags.StringVar(&chainID, "chain", "",
+		fmt.Sprintf("Chain of the %s, among the chains hosted by the peer (empty for the default chain)", chainFuncName))
 
 	chaincodeCmd.AddCommand(deployCmd())
 	chaincodeCmd.AddCommand(invokeCmd())
@@ -67,6 +71,8 @@ var (
 	chaincodeQueryHex       bool
 	chaincodeAttributesJSON string
 	customIDGenAlg          string
+	chaincodeTimeout        int32
+	chainID                 string
 )
 
 var chaincodeCmd = &cobra.Command{
@@ -55,7 +55,9 @@ func getChaincodeSpecification(cmd *cobra.Command) (*pb.ChaincodeSpec, error) {
 		Type:        pb.ChaincodeSpec_Type(pb.ChaincodeSpec_Type_value[chaincodeLang]),
 		ChaincodeID: &pb.ChaincodeID{Path: chaincodePath, Name: chaincodeName},
 		CtorMsg:     input,
+		Timeout:     chaincodeTimeout,
 		Attributes:  attributes,
+		ChainID:     chainID,
 	}
 	// If security is enabled, add client login token
 	if core.SecurityEnabled() {
@@ -208,5 +210,9 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 		}
 	}
 
+	if chaincodeTimeout < 0 {
+		return fmt.Errorf("Chaincode timeout may not be negative")
+	}
+
 	return nil
 }
@@ -61,3 +61,15 @@ func TestCheckChaincodeCmdParamsEmptyCtor(t *testing.T) {
 
 	require.Error(result)
 }
+
+func TestCheckChaincodeCmdParamsNegativeTimeout(t *testing.T) {
+	chaincodeAttributesJSON = "[]"
+	chaincodeCtorJSON = `{ "Args":["func", "param"] }`
+	chaincodePath = "some/path"
+	chaincodeTimeout = -1
+	defer func() { chaincodeTimeout = 0 }()
+	require := require.New(t)
+	result := checkChaincodeCmdParams(nil)
+
+	require.Error(result)
+}
@@ -27,7 +27,14 @@ import (
 	"golang.org/x/net/context"
 )
 
+var listBanned, listTraffic bool
+
 func listCmd() *cobra.Command {
+	networkListCmd.Flags().BoolVarP(&listBanned, "banned", "", false,
+		"Also list the peers the target peer node banned for misbehaving")
+	networkListCmd.Flags().BoolVarP(&listTraffic, "traffic", "", false,
+		"Also list the send queues and traffic of the target peer node to the connected peers")
+
 	return networkListCmd
 }
 
@@ -60,7 +67,30 @@ func networkList() (err error) {
 
 	// The generated pb.PeersMessage struct will be added "omitempty" tag automatically.
 	// But we still want to print it when pb.PeersMessage is empty.
-	jsonOutput, _ := json.Marshal(struct{ Peers []*pb.PeerEndpoint }{append([]*pb.PeerEndpoint{}, peers.GetPeers()...)})
+	output := struct {
+		Peers   []*pb.PeerEndpoint
+		Banned  *[]*pb.BannedPeer  `json:",omitempty"`
+		Traffic *[]*pb.PeerTraffic `json:",omitempty"`
+	}{Peers: append([]*pb.PeerEndpoint{}, peers.GetPeers()...)}
+
+	adminClient := pb.NewAdminClient(clientConn)
+	if listBanned {
+		bans, err := adminClient.GetBannedPeers(context.Background(), &empty.Empty{})
+		if err != nil {
+			return fmt.Errorf("Error trying to get banned peers: %s", err)
+		}
+		banned := append([]*pb.BannedPeer{}, bans.GetPeers()...)
+		output.Banned = &banned
+	}
+	if listTraffic {
+		peersTraffic, err := adminClient.GetPeersTraffic(context.Background(), &empty.Empty{})
+		if err != nil {
+			return fmt.Errorf("Error trying to get peers traffic: %s", err)
+		}
+		traffic := append([]*pb.PeerTraffic{}, peersTraffic.GetPeers()...)
+		output.Traffic = &traffic
+	}
+	jsonOutput, _ := json.Marshal(output)
 	fmt.Println(string(jsonOutput))
 	return nil
 }
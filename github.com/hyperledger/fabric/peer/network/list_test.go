@@ -29,4 +29,6 @@ func TestListCmd(t *testing.T) {
 	require.NotNil(cmd)
 	require.Equal("list", cmd.Name())
 	require.NotNil(cmd.RunE)
+	require.NotNil(cmd.Flags().Lookup("banned"))
+	require.NotNil(cmd.Flags().Lookup("traffic"))
 }
@@ -38,5 +38,6 @@ func Cmd() *cobra.Command {
 var networkCmd = &cobra.Command{
 	Use:   networkFuncName,
 	Short: fmt.Sprintf("%s specific commands.", networkFuncName),
-	Long:  fmt.Sprintf("%s specific commands.", networkFuncName),
+	Long: fmt.Sprintf("%s specific commands. The chains hosted by a peer share its connections, "+
+		"so the peers, bans and traffic they report do not depend on a chain.", networkFuncName),
 }
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var (
+	healthReady   bool
+	healthTimeout time.Duration
+)
+
+func healthCmd() *cobra.Command {
+	nodeHealthCmd.Flags().BoolVarP(&healthReady, "ready", "", false,
+		"Also fail when the node is not ready to serve requests")
+	nodeHealthCmd.Flags().DurationVarP(&healthTimeout, "timeout", "", 10*time.Second,
+		"Time to wait for the checks of the node")
+
+	return nodeHealthCmd
+}
+
+var nodeHealthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Checks the health of the node.",
+	Long: `Runs the health checks of the running node and prints their results. Exits with a ` +
+		`non-zero status when the node cannot be reached or is unhealthy, or with --ready when ` +
+		`it is not ready to serve requests, so that it can be used as a container or systemd probe.`,
+	// The usage would bury the results of the checks
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return health()
+	},
+}
+
+func health() error {
+	clientConn, err := peer.NewPeerClientConnection()
+	if err != nil {
+		return fmt.Errorf("Error trying to connect to local peer: %s", err)
+	}
+	defer clientConn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+	serverClient := pb.NewAdminClient(clientConn)
+	result, err := serverClient.GetHealth(ctx, &empty.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error checking the health of the local peer: %s", err)
+	}
+
+	marshaler := &jsonpb.Marshaler{EmitDefaults: true, Indent: "  "}
+	out, err := marshaler.MarshalToString(result)
+	if err != nil {
+		return fmt.Errorf("Error marshaling health: %s", err)
+	}
+	fmt.Println(out)
+
+	if !result.Healthy {
+		return fmt.Errorf("Peer is unhealthy")
+	}
+	if healthReady && !result.Ready {
+		return fmt.Errorf("Peer is not ready")
+	}
+	return nil
+}
@@ -35,6 +35,9 @@ func Cmd() *cobra.Command {
 	nodeCmd.AddCommand(startCmd())
 	nodeCmd.AddCommand(statusCmd())
 	nodeCmd.AddCommand(stopCmd())
+	nodeCmd.AddCommand(reloadCmd())
+	nodeCmd.AddCommand(healthCmd())
+	nodeCmd.AddCommand(validatorCmd())
 
 	return nodeCmd
 }
@@ -42,5 +45,6 @@ func Cmd() *cobra.Command {
 var nodeCmd = &cobra.Command{
 	Use:   nodeFuncName,
 	Short: fmt.Sprintf("%s specific commands.", nodeFuncName),
-	Long:  fmt.Sprintf("%s specific commands.", nodeFuncName),
+	Long: fmt.Sprintf("%s specific commands. The commands apply to the node and all the chains it hosts, "+
+		"except status --consensus and validator, which select a chain with --chain.", nodeFuncName),
 }
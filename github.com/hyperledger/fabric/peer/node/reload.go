@@ -0,0 +1,64 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func reloadCmd() *cobra.Command {
+	return nodeReloadCmd
+}
+
+var nodeReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reloads the configuration of the node.",
+	Long: `Makes the running node read its configuration file again and apply the changes ` +
+		`which do not require a restart, as sending it SIGHUP does. Lists the changes applied ` +
+		`and those which require a restart.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reload()
+	},
+}
+
+func reload() error {
+	clientConn, err := peer.NewPeerClientConnection()
+	if err != nil {
+		return fmt.Errorf("Error trying to connect to local peer: %s", err)
+	}
+
+	serverClient := pb.NewAdminClient(clientConn)
+	result, err := serverClient.ReloadConfig(context.Background(), &empty.Empty{})
+	if err != nil {
+		return fmt.Errorf("Error reloading the configuration of the local peer: %s", err)
+	}
+
+	marshaler := &jsonpb.Marshaler{EmitDefaults: true, Indent: "  "}
+	out, err := marshaler.MarshalToString(result)
+	if err != nil {
+		return fmt.Errorf("Error marshaling configuration reload: %s", err)
+	}
+	fmt.Println(out)
+	return nil
+}
@@ -0,0 +1,224 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/consensus/helper"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/rest"
+	"github.com/hyperledger/fabric/events/producer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdown stops the peer in order when it is asked to, through a signal or
+// the admin service. The steps left are skipped once its deadline passes.
+type shutdown struct {
+	timeout        time.Duration
+	deadline       time.Time
+	lis            net.Listener
+	grpcServer     *grpc.Server
+	ehubLis        net.Listener
+	ehubGrpcServer *grpc.Server
+	peerServer     *peer.Impl
+
+	lock     sync.Mutex
+	draining bool           // Only admin requests are accepted once set
+	closing  bool           // No request is accepted once set
+	calls    sync.WaitGroup // The requests in progress
+
+	requested   chan struct{} // Closed when the shutdown is requested
+	requestOnce sync.Once
+	drained     chan struct{} // Closed when the peer is drained
+	drainErr    error
+	indexing    bool // Whether blocks were still being indexed at the deadline
+}
+
+func newShutdown() *shutdown {
+	timeout := viper.GetDuration("peer.shutdown.timeout")
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	return &shutdown{
+		timeout:   timeout,
+		requested: make(chan struct{}),
+		drained:   make(chan struct{}),
+	}
+}
+
+// accepted returns whether a request to the method is accepted while the
+// peer is drained, the admin service reports on the peer and stops it
+func accepted(method string) bool {
+	return strings.HasPrefix(method, "/protos.Admin/") && method != "/protos.Admin/ReloadConfig"
+}
+
+// unaryInterceptor refuses the requests received while the peer shuts down,
+// but those of the admin service until the peer is drained
+func (s *shutdown) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	s.lock.Lock()
+	if s.closing || (s.draining && !accepted(info.FullMethod)) {
+		s.lock.Unlock()
+		return nil, grpc.Errorf(codes.Unavailable, "Peer is shutting down")
+	}
+	s.calls.Add(1)
+	s.lock.Unlock()
+	defer s.calls.Done()
+	return handler(ctx, req)
+}
+
+// request asks for the peer to shut down
+func (s *shutdown) request() {
+	s.requestOnce.Do(func() {
+		close(s.requested)
+	})
+}
+
+// Stop asks for the peer to shut down and waits until it is drained
+func (s *shutdown) Stop() error {
+	s.request()
+	<-s.drained
+	return s.drainErr
+}
+
+// health reports the peer as not ready once it is asked to shut down
+func (s *shutdown) health() (pb.HealthCheck_Status, string) {
+	select {
+	case <-s.requested:
+		return pb.HealthCheck_NOT_READY, "Peer is shutting down"
+	default:
+		return pb.HealthCheck_OK, "Peer is running"
+	}
+}
+
+// drain stops the peer from accepting requests, lets consensus finish the
+// batches in progress, flushes the block indexes, stops the chaincode
+// containers and ends the event streams
+func (s *shutdown) drain() {
+	s.deadline = time.Now().Add(s.timeout)
+	logger.Infof("Shutting down peer within %s", s.timeout)
+	var errs []string
+
+	s.lock.Lock()
+	s.draining = true
+	s.lock.Unlock()
+	if s.lis != nil {
+		s.lis.Close()
+	}
+	if s.ehubLis != nil {
+		s.ehubLis.Close()
+	}
+	rest.StopOpenchainRESTServer()
+
+	if err := helper.DrainEngines(s.deadline); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	indexed := make(chan struct{})
+	go func() {
+		ledger.StopIndexers()
+		close(indexed)
+	}()
+	select {
+	case <-indexed:
+	case <-time.After(s.deadline.Sub(time.Now())):
+		s.indexing = true
+		errs = append(errs, "Blocks still being indexed")
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), s.deadline)
+	if err := chaincode.StopChaincodes(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	cancel()
+
+	producer.Shutdown("Peer is shutting down")
+
+	if len(errs) > 0 {
+		s.drainErr = fmt.Errorf("%s", strings.Join(errs, "; "))
+		logger.Errorf("Peer not drained within %s: %s", s.timeout, s.drainErr)
+	} else {
+		logger.Info("Peer drained")
+	}
+	close(s.drained)
+}
+
+// close waits for the requests in progress, stops the gRPC servers, the
+// consensus engines and the chats with the other peers, and closes the
+// database, unless blocks are still being indexed or chats did not end
+func (s *shutdown) close() {
+	s.lock.Lock()
+	s.closing = true
+	s.lock.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.calls.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(s.deadline.Sub(time.Now())):
+		logger.Warning("Requests still in progress at the shutdown deadline")
+	}
+
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	if s.ehubGrpcServer != nil {
+		s.ehubGrpcServer.Stop()
+	}
+
+	// Nothing may use the database once it is closed: the consenters and
+	// executors stop processing events, and the chats and background services
+	// of the peer end
+	helper.StopEngines()
+	chatting := false
+	if s.peerServer != nil {
+		if err := s.peerServer.Stop(s.deadline); err != nil {
+			logger.Warningf("Error stopping peer: %s", err)
+			chatting = true
+		}
+	}
+
+	if s.indexing {
+		// The blocks not indexed are indexed at the next start
+		logger.Warning("Not closing the database while blocks are being indexed")
+	} else if chatting {
+		logger.Warning("Not closing the database while chats with other peers are in progress")
+	} else {
+		db.Stop()
+	}
+
+	pidFile := viper.GetString("peer.fileSystemPath") + "/peer.pid"
+	logger.Debugf("Remove pid file  %s", pidFile)
+	os.Remove(pidFile)
+	logger.Info("Peer stopped")
+}
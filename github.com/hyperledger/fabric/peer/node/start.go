@@ -33,6 +33,7 @@ import (
 	"github.com/hyperledger/fabric/core"
 	"github.com/hyperledger/fabric/core/chaincode"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/config"
 	"github.com/hyperledger/fabric/core/crypto"
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/genesis"
@@ -40,6 +41,7 @@ import (
 	"github.com/hyperledger/fabric/core/rest"
 	"github.com/hyperledger/fabric/core/system_chaincode"
 	"github.com/hyperledger/fabric/events/producer"
+	"github.com/hyperledger/fabric/flogging"
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -96,6 +98,9 @@ func serve(args []string) error {
 		err = fmt.Errorf("Failed to get Peer Endpoint: %s", err)
 		return err
 	}
+	if err = peer.CheckPeerBindingConfig(); err != nil {
+		return err
+	}
 
 	// 启动grpc服务，监听7051端口
 	// 设置服务器地址，创建服务器实例，后续代码会使用lis
@@ -130,19 +135,24 @@ func serve(args []string) error {
 	//启动rockdb数据库
 	db.Start()
 
+	stopper := newShutdown()
+	stopper.lis, stopper.ehubLis, stopper.ehubGrpcServer = lis, ehubLis, ehubGrpcServer
+
 	var opts []grpc.ServerOption
 	if comm.TLSEnabled() {
-		creds, err := credentials.NewServerTLSFromFile(viper.GetString("peer.tls.cert.file"),
-			viper.GetString("peer.tls.key.file"))
+		// Peers chatting with this peer authenticate with their TLS certificate
+		creds, err := comm.InitTLSForServer()
 
 		if err != nil {
 			grpclog.Fatalf("Failed to generate credentials %v", err)
 		}
 		opts = []grpc.ServerOption{grpc.Creds(creds)}
 	}
+	opts = append(opts, grpc.UnaryInterceptor(stopper.unaryInterceptor))
 
 	//创建一个grpc服务
 	grpcServer := grpc.NewServer(opts...)
+	stopper.grpcServer = grpcServer
 
 	//注册Chaincode支持服务器
 	secHelper, err := getSecHelper()
@@ -154,7 +164,9 @@ func serve(args []string) error {
 		return secHelper
 	}
 
-	registerChaincodeSupport(chaincode.DefaultChain, grpcServer, secHelper)
+	if err = registerChaincodeSupport(chaincode.DefaultChain, grpcServer, secHelper); err != nil {
+		return err
+	}
 
 	var peerServer *peer.Impl
 
@@ -184,9 +196,10 @@ func serve(args []string) error {
 
 	// 注册peer服务
 	pb.RegisterPeerServer(grpcServer, peerServer)
+	stopper.peerServer = peerServer
 
 	// 注册管理服务器
-	pb.RegisterAdminServer(grpcServer, core.NewAdminServer())
+	pb.RegisterAdminServer(grpcServer, core.NewAdminServer(peerServer, peerServer, peerServer, peerServer, stopper))
 
 	// 注册Devops服务器
 	// 在Peer节点初始化的时候 创建DevopsServer
@@ -207,12 +220,18 @@ func serve(args []string) error {
 		go rest.StartOpenchainRESTServer(serverOpenchain, serverDevops)
 	}
 
+	registerReloadables(peerServer, serverOpenchain, serverDevops)
+	registerHealthChecks(peerServer, stopper)
+	if err := config.InitReload(); err != nil {
+		logger.Errorf("Configuration cannot be reloaded: %s", err)
+	}
+
 	logger.Infof("Starting peer with ID=%s, network ID=%s, address=%s, rootnodes=%v, validator=%v",
 		peerEndpoint.ID, viper.GetString("peer.networkId"), peerEndpoint.Address,
 		viper.GetString("peer.discovery.rootnode"), peer.ValidatorEnabled())
 
 	// 启动GRPC服务器. 如果是必须的话在一个goroutine中完成这样我们能够部署genesis
-	serve := make(chan error)
+	serve := make(chan error, 1)
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
@@ -220,7 +239,27 @@ func serve(args []string) error {
 		sig := <-sigs
 		fmt.Println()
 		fmt.Println(sig)
-		serve <- nil
+		stopper.request()
+		sig = <-sigs
+		logger.Warningf("Received %s again, exiting without waiting for the shutdown", sig)
+		os.Exit(1)
+	}()
+
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	go func() {
+		for range reloads {
+			select {
+			case <-stopper.requested:
+				logger.Warning("Received SIGHUP while shutting down, not reloading configuration")
+				continue
+			default:
+			}
+			logger.Info("Received SIGHUP, reloading configuration")
+			if _, err := config.Reload(); err != nil {
+				logger.Errorf("Error reloading configuration: %s", err)
+			}
+		}
 	}()
 
 	go func() {
@@ -252,15 +291,108 @@ func serve(args []string) error {
 		}()
 	}
 
-	// Block until grpc server exits 产生块直到grpc服务退出
-	return <-serve
+	// Block until grpc server exits or the peer is asked to stop
+	// 产生块直到grpc服务退出
+	select {
+	case err := <-serve:
+		return err
+	case <-stopper.requested:
+	}
+	stopper.drain()
+	stopper.close()
+	return nil
+}
+
+// registerReloadables registers the subsystems which apply changes of the
+// configuration while the peer is running, through SIGHUP or the admin
+// service. Changes of any other key require a restart of the peer.
+func registerReloadables(peerServer *peer.Impl, serverOpenchain *rest.ServerOpenchain, serverDevops *core.Devops) {
+	config.RegisterReloadable(config.Reloadable{
+		Name: "logging",
+		Keys: []string{"logging."},
+		Validate: func(v *viper.Viper) error {
+			return flogging.ValidateLoggingSpec(v.GetString("logging." + nodeFuncName))
+		},
+		Apply: func() error {
+			flogging.LoggingInit(nodeFuncName)
+			return nil
+		},
+	})
+	config.RegisterReloadable(config.Reloadable{
+		Name:     "chaincode timeouts",
+		Keys:     []string{"chaincode.startuptimeout", "chaincode.executetimeout", "chaincode.maxexecutetimeout"},
+		Validate: chaincode.ValidateTimeouts,
+		Apply: func() error {
+			chaincode.ReloadTimeouts()
+			return nil
+		},
+	})
+	config.RegisterReloadable(config.Reloadable{
+		Name:     "discovery root nodes",
+		Keys:     []string{"peer.discovery.rootnode"},
+		Validate: peer.ValidateRootNodes,
+		Apply:    peerServer.ReloadRootNodes,
+	})
+	// Read when connecting to a peer
+	config.RegisterReloadable(config.Reloadable{
+		Name:     "peer connections",
+		Keys:     []string{"peer.compression.", "peer.sendQueue."},
+		Validate: peer.ValidateSendQueue,
+	})
+	// Read for each request
+	config.RegisterReloadable(config.Reloadable{
+		Name:     "REST patterns",
+		Keys:     []string{"rest.validPatterns."},
+		Validate: rest.ValidateRESTConfig,
+	})
+	config.RegisterReloadable(config.Reloadable{
+		Name:     "REST service",
+		Keys:     []string{"rest.enabled", "rest.address"},
+		Validate: rest.ValidateRESTConfig,
+		Apply: func() error {
+			return rest.RestartOpenchainRESTServer(serverOpenchain, serverDevops)
+		},
+	})
+}
+
+// registerHealthChecks registers the checks of the health and readiness
+// of the peer reported by the admin service, REST and 'peer node health'
+func registerHealthChecks(peerServer *peer.Impl, stopper *shutdown) {
+	core.RegisterHealthCheck(core.LedgerHealthCheck())
+	if peer.ValidatorEnabled() {
+		for _, c := range core.ConsensusHealthChecks(peerServer) {
+			core.RegisterHealthCheck(c)
+		}
+	}
+	// The chains hosted besides the default one are checked the same way
+	for _, chainID := range peer.GetChains() {
+		if db.NormalizeChainID(chainID) == db.DefaultChainID {
+			continue
+		}
+		core.RegisterHealthCheck(core.ChainLedgerHealthCheck(chainID))
+		coordinator, err := peerServer.GetChainCoordinator(chainID)
+		if err != nil {
+			continue
+		}
+		if reporter, ok := coordinator.(peer.ConsensusStatusReporter); ok && peer.ValidatorEnabled() {
+			for _, c := range core.ChainConsensusHealthChecks(reporter, chainID) {
+				core.RegisterHealthCheck(c)
+			}
+		}
+	}
+	core.RegisterHealthCheck(core.PeersHealthCheck(peerServer.GetPeers))
+	core.RegisterHealthCheck(core.ChaincodeHealthCheck())
+	if core.SecurityEnabled() {
+		core.RegisterHealthCheck(core.MembersrvcHealthCheck())
+	}
+	core.RegisterHealthCheck(core.HealthCheck{Name: "shutdown", Check: stopper.health})
 }
 
 // 该函数主要作用是将系统chaincode部署到Docker上，同时根据第一个参数chainname创建
 // ChainCodeSupport 实例;该实例包括 chaincode路径、超时时间、chainname等数据信息。
 // 将得到的ChainCodeSupport实例注册到grpcServer
 func registerChaincodeSupport(chainname chaincode.ChainName, grpcServer *grpc.Server,
-	secHelper crypto.Peer) {
+	secHelper crypto.Peer) error {
 
 	//get user mode
 	//获取用户模式
@@ -283,10 +415,13 @@ func registerChaincodeSupport(chainname chaincode.ChainName, grpcServer *grpc.Se
 
 	//Now that chaincode is initialized, register all system chaincodes.
 	// RegisterSysCCs 该函数注册部署系统chaincode
-	system_chaincode.RegisterSysCCs()
+	if err := system_chaincode.RegisterSysCCs(); err != nil {
+		return fmt.Errorf("Error registering system chaincodes: %s", err)
+	}
 	// RegisterChaincodeSupportServer 该函数比较重要，该函数向grpcServer注册
 	// chainCodeSupport实例，同时传入chainCodeSupport的服务规范
 	pb.RegisterChaincodeSupportServer(grpcServer, ccSrv)
+	return nil
 }
 
 // 创建 createEventHubServer 该函数创建事件Hub服务器（创建线程运行），同时为该服务器创建监听实例，调用
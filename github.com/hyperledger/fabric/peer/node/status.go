@@ -19,6 +19,7 @@ package node
 import (
 	"fmt"
 
+	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/hyperledger/fabric/core/peer"
 	pb "github.com/hyperledger/fabric/protos"
@@ -26,7 +27,17 @@ import (
 	"golang.org/x/net/context"
 )
 
+var (
+	statusConsensus bool
+	statusChainID   string
+)
+
 func statusCmd() *cobra.Command {
+	nodeStatusCmd.Flags().BoolVarP(&statusConsensus, "consensus", "", false,
+		"Also return a snapshot of the state of the consensus plugin of a validating node")
+	nodeStatusCmd.Flags().StringVar(&statusChainID, "chain", "",
+		"Chain whose consensus plugin --consensus reports, among the chains hosted by the peer (empty for the default chain)")
+
 	return nodeStatusCmd
 }
 
@@ -58,5 +69,25 @@ func status() (err error) {
 		return err
 	}
 	fmt.Println(status)
+
+	if statusConsensus {
+		return consensusStatus(serverClient)
+	}
+	return nil
+}
+
+func consensusStatus(serverClient pb.AdminClient) error {
+	status, err := serverClient.GetConsensusStatus(context.Background(), &pb.ConsensusStatusRequest{ChainID: statusChainID})
+	if err != nil {
+		logger.Errorf("Error trying to get consensus status from local peer: %s", err)
+		return fmt.Errorf("Error trying to get consensus status from local peer: %s", err)
+	}
+
+	marshaler := &jsonpb.Marshaler{EmitDefaults: true, Indent: "  "}
+	out, err := marshaler.MarshalToString(status)
+	if err != nil {
+		return fmt.Errorf("Error marshaling consensus status: %s", err)
+	}
+	fmt.Println(out)
 	return nil
 }
@@ -31,6 +31,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 func stopCmd() *cobra.Command {
@@ -44,7 +46,8 @@ func stopCmd() *cobra.Command {
 var nodeStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stops the running node.",
-	Long:  `Stops the running node, disconnecting from the network.`,
+	Long: `Stops the running node, disconnecting from the network. The node stops accepting ` +
+		`requests and finishes its work in progress before it exits, within peer.shutdown.timeout.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		stop()
 	},
@@ -72,16 +75,23 @@ func stop() (err error) {
 	logger.Info("Stopping peer using grpc")
 	serverClient := pb.NewAdminClient(clientConn)
 
+	// The peer replies once it is drained, and exits after
 	status, err := serverClient.StopServer(context.Background(), &empty.Empty{})
 	db.Stop()
 	if err != nil {
+		if grpc.Code(err) == codes.Unknown {
+			// The peer stopped without finishing the shutdown sequence
+			err = fmt.Errorf("Error stopping peer: %s", grpc.ErrorDesc(err))
+			fmt.Println(err)
+			return err
+		}
+		// The peer exited before replying
 		fmt.Println(&pb.ServerStatus{Status: pb.ServerStatus_STOPPED})
 		return nil
 	}
 
-	err = fmt.Errorf("Connection remain opened, peer process doesn't exit")
 	fmt.Println(status)
-	return err
+	return nil
 }
 
 func readPid(fileName string) (int, error) {
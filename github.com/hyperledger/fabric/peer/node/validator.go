@@ -0,0 +1,131 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var (
+	validatorKeys       []string
+	validatorSignatures []string
+	validatorSignOnly   bool
+	validatorChainID    string
+)
+
+func validatorCmd() *cobra.Command {
+	nodeValidatorCmd.Flags().StringSliceVarP(&validatorKeys, "key", "k", nil,
+		"Files holding the PEM encoded private keys of administrators signing the change")
+	nodeValidatorCmd.Flags().StringSliceVarP(&validatorSignatures, "signature", "s", nil,
+		"Base64 encoded signatures of administrators over the change")
+	nodeValidatorCmd.Flags().BoolVarP(&validatorSignOnly, "sign-only", "", false,
+		"Print the signatures instead of submitting the change")
+	nodeValidatorCmd.Flags().StringVar(&validatorChainID, "chain", "",
+		"Chain whose validators change, among the chains hosted by the peer (empty for the default chain)")
+
+	return nodeValidatorCmd
+}
+
+var nodeValidatorCmd = &cobra.Command{
+	Use:   "validator <add|remove> <validator> <sequence>",
+	Short: "Adds or removes a validator.",
+	Long: `Asks the running validating peer to order the addition or removal of a validator, ` +
+		`identified by its peer.id. The change must be signed by the administrators of ` +
+		`pbft.general.reconfiguration, and sequence must be one more than the sequence of the ` +
+		`last change ordered, as reported by peer node status. Administrators who do not have ` +
+		`access to the peer sign with --sign-only and hand their signatures over. The signatures ` +
+		`cover the chain selected with --chain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return reconfigureValidators(args)
+	},
+}
+
+func reconfigureValidators(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("Expected the action, the validator and the sequence, got %d arguments", len(args))
+	}
+	action, ok := pb.ValidatorReconfiguration_Action_value[strings.ToUpper(args[0])]
+	if !ok {
+		return fmt.Errorf("Unknown action %s, expected add or remove", args[0])
+	}
+	sequence, err := strconv.ParseUint(args[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid sequence %s: %s", args[2], err)
+	}
+	req := &pb.ValidatorReconfiguration{
+		Action:    pb.ValidatorReconfiguration_Action(action),
+		Validator: &pb.PeerID{Name: args[1]},
+		Sequence:  sequence,
+		ChainID:   validatorChainID,
+	}
+
+	for _, encoded := range validatorSignatures {
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("Invalid signature %s: %s", encoded, err)
+		}
+		req.Signatures = append(req.Signatures, sig)
+	}
+	for _, file := range validatorKeys {
+		raw, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("Error reading key %s: %s", file, err)
+		}
+		key, err := primitives.PEMtoPrivateKey(raw, nil)
+		if err != nil {
+			return fmt.Errorf("Error parsing key %s: %s", file, err)
+		}
+		ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return fmt.Errorf("Key %s is not an ECDSA key", file)
+		}
+		if err = req.Sign(ecdsaKey); err != nil {
+			return fmt.Errorf("Error signing with key %s: %s", file, err)
+		}
+	}
+
+	if validatorSignOnly {
+		for _, sig := range req.Signatures[len(validatorSignatures):] {
+			fmt.Println(base64.StdEncoding.EncodeToString(sig))
+		}
+		return nil
+	}
+
+	clientConn, err := peer.NewPeerClientConnection()
+	if err != nil {
+		return fmt.Errorf("Error trying to connect to local peer: %s", err)
+	}
+	defer clientConn.Close()
+
+	serverClient := pb.NewAdminClient(clientConn)
+	if _, err = serverClient.ReconfigureValidators(context.Background(), req); err != nil {
+		return fmt.Errorf("Error reconfiguring the validators: %s", err)
+	}
+	fmt.Printf("Ordered the %s of validator %s\n", strings.ToLower(args[0]), args[1])
+	return nil
+}
@@ -13,6 +13,7 @@ It is generated from these files:
 	events.proto
 	fabric.proto
 	server_admin.proto
+	orderer.proto
 
 It has these top-level messages:
 	BlockNumber
@@ -42,6 +43,7 @@ It has these top-level messages:
 	Register
 	Rejection
 	Unregister
+	Shutdown
 	Event
 	Transaction
 	TransactionBlock
@@ -55,16 +57,37 @@ It has these top-level messages:
 	PeersMessage
 	PeersAddresses
 	HelloMessage
+	AliveMessage
+	SignedAliveMessage
+	GossipMembership
+	MembershipRecord
+	MembershipSnapshot
 	Message
 	Response
+	TransactionSubmission
+	TransactionSubmissionRequest
 	BlockState
 	SyncBlockRange
 	SyncBlocks
 	SyncStateSnapshotRequest
+	SyncStateSnapshotChunk
 	SyncStateSnapshot
 	SyncStateDeltasRequest
 	SyncStateDeltas
 	ServerStatus
+	ConsensusStatus
+	PbftStatus
+	BannedPeer
+	BannedPeers
+	PeerTraffic
+	PeersTraffic
+	ConfigReload
+	HealthCheck
+	Health
+	ValidatorReconfiguration
+	BroadcastResponse
+	DeliverRequest
+	OrderedBatch
 */
 package protos
 
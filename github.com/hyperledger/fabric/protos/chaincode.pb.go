@@ -214,6 +214,8 @@ type ChaincodeSpec struct {
 	ConfidentialityLevel ConfidentialityLevel `protobuf:"varint,6,opt,name=confidentialityLevel,enum=protos.ConfidentialityLevel" json:"confidentialityLevel,omitempty"`
 	Metadata             []byte               `protobuf:"bytes,7,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	Attributes           []string             `protobuf:"bytes,8,rep,name=attributes" json:"attributes,omitempty"`
+	// The chain to deploy, invoke or query the chaincode on, the default chain if empty
+	ChainID string `protobuf:"bytes,9,opt,name=chainID" json:"chainID,omitempty"`
 }
 
 func (m *ChaincodeSpec) Reset()                    { *m = ChaincodeSpec{} }
@@ -561,79 +563,79 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("chaincode.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 1173 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x56, 0x4b, 0x6f, 0xdb, 0x46,
-	0x10, 0x8e, 0x5e, 0x7e, 0x8c, 0x5e, 0xcc, 0x5a, 0x71, 0x04, 0xf7, 0x91, 0x80, 0x48, 0x03, 0xa3,
-	0x07, 0x25, 0x55, 0xd3, 0xa2, 0x40, 0x8b, 0xa0, 0x0c, 0xb9, 0x71, 0x19, 0xcb, 0x94, 0xb2, 0xa2,
-	0x8d, 0xe4, 0x64, 0xd0, 0xd4, 0x9a, 0x26, 0x22, 0x93, 0x04, 0xb9, 0x32, 0xec, 0x5b, 0xcf, 0x3d,
-	0xf5, 0x37, 0xf4, 0x47, 0xf4, 0xd0, 0x1f, 0xd4, 0xdf, 0xd1, 0xdd, 0xe5, 0x43, 0xcf, 0x04, 0x01,
-	0x7a, 0xd2, 0xce, 0xcc, 0x37, 0xb3, 0xb3, 0xdf, 0x7e, 0x3b, 0x14, 0xb4, 0xdd, 0x2b, 0xc7, 0x0f,
-	0xdc, 0x70, 0x42, 0x7b, 0x51, 0x1c, 0xb2, 0x10, 0x6d, 0xc9, 0x9f, 0xe4, 0xa0, 0x53, 0x04, 0xe8,
-	0x0d, 0x0d, 0x58, 0x1a, 0x3d, 0x78, 0xe4, 0x85, 0xa1, 0x37, 0xa5, 0xcf, 0xa4, 0x75, 0x31, 0xbb,
-	0x7c, 0xc6, 0xfc, 0x6b, 0x9a, 0x30, 0xe7, 0x3a, 0x4a, 0x01, 0xea, 0x0f, 0x50, 0xd7, 0xf3, 0x44,
-	0xd3, 0x40, 0x08, 0xaa, 0x91, 0xc3, 0xae, 0xba, 0xa5, 0xc7, 0xa5, 0xc3, 0x5d, 0x22, 0xd7, 0xc2,
-	0x17, 0x38, 0xd7, 0xb4, 0x5b, 0x4e, 0x7d, 0x62, 0xad, 0x3e, 0x81, 0xd6, 0x3c, 0x2d, 0x88, 0x66,
-	0x4c, 0xa0, 0x9c, 0xd8, 0x4b, 0x78, 0x66, 0xe5, 0xb0, 0x41, 0xe4, 0x5a, 0xfd, 0xbb, 0x02, 0xcd,
-	0x02, 0x36, 0x8e, 0xa8, 0x8b, 0x7a, 0x50, 0x65, 0x77, 0x11, 0x95, 0xf5, 0x5b, 0xfd, 0x83, 0xb4,
-	0x89, 0xa4, 0xb7, 0x04, 0xea, 0xd9, 0x1c, 0x41, 0x24, 0x0e, 0xf1, 0xf6, 0xdc, 0x79, 0x7b, 0xb2,
-	0x85, 0x7a, 0x7f, 0x6f, 0x2d, 0xcd, 0x34, 0xc8, 0x22, 0x0e, 0x3d, 0x87, 0x6d, 0x97, 0x85, 0xf1,
-	0x49, 0xe2, 0x75, 0x2b, 0x32, 0x65, 0x7f, 0x3d, 0x45, 0x74, 0x4d, 0x72, 0x18, 0xea, 0xc2, 0xb6,
-	0xa0, 0x26, 0x9c, 0xb1, 0x6e, 0x95, 0x67, 0xd4, 0x48, 0x6e, 0xa2, 0x27, 0xd0, 0x4c, 0xa8, 0x3b,
-	0x8b, 0xa9, 0x1e, 0x06, 0x8c, 0xde, 0xb2, 0x6e, 0x4d, 0xf2, 0xb0, 0xec, 0x44, 0x23, 0xe8, 0xb8,
-	0x61, 0x70, 0xe9, 0x4f, 0x38, 0xf7, 0xbe, 0x33, 0xf5, 0xd9, 0xdd, 0x80, 0xdf, 0xc3, 0xb4, 0xbb,
-	0x25, 0x0f, 0xfa, 0x65, 0xb1, 0xfd, 0x06, 0x0c, 0xd9, 0x98, 0x89, 0x0e, 0x60, 0xe7, 0x9a, 0x32,
-	0x67, 0xe2, 0x30, 0xa7, 0xbb, 0xcd, 0xab, 0x34, 0x48, 0x61, 0xa3, 0xaf, 0x01, 0x1c, 0xc6, 0x62,
-	0xff, 0x62, 0xc6, 0x68, 0xd2, 0xdd, 0xe1, 0x94, 0xef, 0x92, 0x05, 0x8f, 0xfa, 0x12, 0xaa, 0x82,
-	0x44, 0xd4, 0x84, 0xdd, 0x53, 0xcb, 0xc0, 0xaf, 0x4d, 0x0b, 0x1b, 0xca, 0x3d, 0x04, 0xb0, 0x75,
-	0x34, 0x1c, 0x68, 0xd6, 0x91, 0x52, 0x42, 0x3b, 0x50, 0xb5, 0x86, 0x06, 0x56, 0xca, 0x68, 0x1b,
-	0x2a, 0xba, 0x46, 0x94, 0x8a, 0x70, 0xbd, 0xd1, 0xce, 0x34, 0xa5, 0xaa, 0xfe, 0x53, 0x86, 0x87,
-	0x05, 0x53, 0x06, 0x8d, 0xa6, 0xe1, 0xdd, 0x35, 0x6f, 0x4f, 0x5e, 0xe1, 0xcf, 0xd0, 0x74, 0x17,
-	0xaf, 0x4b, 0xde, 0x65, 0xbd, 0xff, 0x60, 0xe3, 0x5d, 0x92, 0x65, 0x2c, 0xfa, 0x15, 0x9a, 0xf4,
-	0xf2, 0x92, 0xba, 0xcc, 0xbf, 0xa1, 0x86, 0xc3, 0x68, 0x76, 0xa3, 0x07, 0xbd, 0x54, 0xa7, 0xbd,
-	0x5c, 0xa7, 0x3d, 0x3b, 0xd7, 0x29, 0x59, 0x4e, 0x40, 0x8f, 0xb9, 0x22, 0x78, 0xb5, 0x91, 0xe3,
-	0x7e, 0x70, 0x3c, 0x2a, 0xaf, 0xb7, 0x41, 0x16, 0x5d, 0xc8, 0x82, 0x6d, 0x7a, 0x4b, 0x5d, 0x1c,
-	0xdc, 0xc8, 0xab, 0x6c, 0xf5, 0x5f, 0xac, 0xb5, 0xb6, 0x7c, 0xa4, 0x1e, 0xe6, 0xf0, 0x19, 0xf3,
-	0xc3, 0x80, 0xe7, 0xf8, 0x71, 0x18, 0x88, 0x00, 0xc9, 0x8b, 0xa8, 0x3d, 0xe8, 0x6c, 0x02, 0x08,
-	0x36, 0x8d, 0xa1, 0x7e, 0x8c, 0x49, 0xca, 0xec, 0xf8, 0xfd, 0xd8, 0xc6, 0x27, 0x4a, 0x49, 0xfd,
-	0xbd, 0xb4, 0x40, 0x9e, 0x19, 0xdc, 0x84, 0xae, 0x23, 0x52, 0xff, 0x3f, 0x79, 0x87, 0xd0, 0xf6,
-	0x27, 0x47, 0x34, 0xa0, 0xb1, 0x2c, 0xa8, 0x4d, 0xbd, 0xec, 0x4d, 0xae, 0xba, 0xd5, 0x3f, 0xcb,
-	0xd0, 0x9d, 0x97, 0x12, 0x42, 0xe5, 0xb2, 0xca, 0xa5, 0xca, 0xc5, 0xe3, 0x3a, 0xd3, 0x29, 0x8d,
-	0x75, 0x1a, 0x33, 0xd9, 0x40, 0x83, 0x2c, 0x78, 0xe6, 0xf1, 0xb1, 0xef, 0x05, 0x72, 0x87, 0x22,
-	0x2e, 0x3c, 0xe2, 0xa9, 0x44, 0xce, 0xdd, 0x34, 0x74, 0x26, 0x19, 0xfb, 0xb9, 0x29, 0x22, 0x17,
-	0x7e, 0x30, 0xf1, 0x03, 0x4f, 0x32, 0xcf, 0x23, 0x99, 0xb9, 0x24, 0xe6, 0xda, 0x8a, 0x98, 0x9f,
-	0x42, 0x2b, 0x72, 0x62, 0xce, 0xe8, 0x49, 0x8e, 0xd8, 0x92, 0x88, 0x15, 0x2f, 0xfa, 0x05, 0xea,
-	0xec, 0xb6, 0xd0, 0x85, 0x7c, 0x13, 0x9f, 0x56, 0xce, 0x22, 0x5c, 0xfd, 0xab, 0x06, 0x4a, 0x41,
-	0xc9, 0x09, 0x4d, 0x12, 0x21, 0x95, 0xef, 0x96, 0xc6, 0xd1, 0x57, 0x6b, 0xb7, 0x90, 0xe1, 0x16,
-	0x27, 0xd2, 0x4f, 0xb0, 0x5b, 0xcc, 0xd0, 0xcf, 0x50, 0xef, 0x1c, 0xfc, 0x09, 0xde, 0xf8, 0xec,
-	0x64, 0xb7, 0xfe, 0x44, 0x92, 0xc6, 0x27, 0xac, 0x58, 0xa3, 0x37, 0xd0, 0x4e, 0x96, 0x2f, 0x4e,
-	0x12, 0x57, 0xef, 0x3f, 0x5e, 0xd7, 0xca, 0x32, 0x8e, 0xac, 0x26, 0xa2, 0x97, 0xd0, 0x2a, 0x94,
-	0x84, 0xc5, 0xd7, 0x41, 0x32, 0xbc, 0x69, 0x2a, 0xca, 0x28, 0x59, 0x41, 0xab, 0xff, 0x96, 0x37,
-	0xcf, 0x93, 0x06, 0xec, 0x10, 0x7c, 0x64, 0x72, 0xdd, 0x13, 0x3e, 0x51, 0x5a, 0x00, 0xb9, 0xc5,
-	0xa3, 0x65, 0x31, 0x4e, 0x4c, 0xcb, 0xb4, 0xf9, 0x60, 0xd9, 0x85, 0x1a, 0xc1, 0x9a, 0xf1, 0x5e,
-	0xa9, 0xa2, 0x36, 0xd4, 0x6d, 0xa2, 0x59, 0x63, 0x4d, 0xb7, 0xcd, 0xa1, 0xa5, 0xd4, 0x44, 0x49,
-	0x7d, 0x78, 0x32, 0x1a, 0x60, 0x9b, 0x27, 0x6d, 0x09, 0x28, 0x26, 0x64, 0x48, 0x94, 0x6d, 0x11,
-	0x39, 0xc2, 0xf6, 0xf9, 0xd8, 0xd6, 0x6c, 0xac, 0xec, 0x08, 0x73, 0x74, 0x9a, 0x9b, 0xbb, 0xc2,
-	0x34, 0xf0, 0x20, 0x33, 0x01, 0x75, 0x40, 0x31, 0xad, 0xb3, 0xe1, 0x31, 0x3e, 0xd7, 0x7f, 0xd3,
-	0x4c, 0x4b, 0x17, 0xa3, 0xad, 0x8e, 0x14, 0x68, 0x64, 0xde, 0xb7, 0xa7, 0x98, 0xbc, 0x57, 0x1a,
-	0x69, 0xcb, 0xe3, 0xd1, 0xd0, 0x1a, 0x63, 0xa5, 0x29, 0x76, 0x4b, 0x03, 0x2d, 0xb4, 0x07, 0x6d,
-	0xb9, 0x3c, 0x9f, 0x77, 0xd3, 0x16, 0xdd, 0xa6, 0xce, 0xb4, 0x27, 0x05, 0x3d, 0x80, 0xfb, 0xbc,
-	0xfb, 0xa3, 0xac, 0x5e, 0xb6, 0xfb, 0x7d, 0x2e, 0xef, 0xfd, 0x35, 0xf7, 0xb9, 0x85, 0xdf, 0xd9,
-	0x0a, 0x42, 0x5f, 0xc0, 0xc3, 0xf5, 0x98, 0x3e, 0x18, 0xf2, 0x06, 0xf6, 0xc4, 0x29, 0x8e, 0x31,
-	0x1e, 0x69, 0x03, 0xf3, 0x0c, 0x2b, 0x1d, 0xf5, 0x47, 0x68, 0x8c, 0x66, 0x6c, 0xcc, 0xf8, 0xa0,
-	0x33, 0x83, 0xcb, 0x90, 0xf7, 0x5f, 0xf9, 0x40, 0xef, 0xb2, 0xaf, 0xb1, 0x58, 0xf2, 0x73, 0xd6,
-	0x6e, 0x9c, 0xe9, 0x8c, 0x66, 0xef, 0x32, 0x35, 0x54, 0x0c, 0x6d, 0xe2, 0x04, 0x1e, 0x7d, 0x3b,
-	0xa3, 0xf1, 0x9d, 0x4c, 0x17, 0x2f, 0x8e, 0xcb, 0x2e, 0x66, 0xc7, 0x45, 0x7e, 0x61, 0xa3, 0x7d,
-	0xd8, 0xa2, 0xc1, 0x44, 0x44, 0xd2, 0xf9, 0x91, 0x59, 0xea, 0x37, 0xb0, 0xb7, 0x52, 0xc6, 0x12,
-	0xf2, 0x69, 0x41, 0x99, 0x7f, 0x7b, 0xd3, 0x22, 0x65, 0xdf, 0x50, 0x9f, 0x42, 0x67, 0x05, 0xa6,
-	0x4f, 0xc3, 0x84, 0xae, 0xe1, 0x34, 0x7e, 0xf2, 0x65, 0x1c, 0xdf, 0xe4, 0x4c, 0x34, 0xfc, 0xd9,
-	0x07, 0xfb, 0xa3, 0xb4, 0x56, 0x83, 0xd0, 0x24, 0x0a, 0x03, 0xbe, 0x1d, 0x86, 0x26, 0x4f, 0x4c,
-	0xb4, 0x60, 0x22, 0x6b, 0xa6, 0x7f, 0x3d, 0xea, 0xfd, 0x47, 0xb9, 0xa8, 0x3f, 0xb2, 0x37, 0x59,
-	0xce, 0x12, 0xcf, 0xf2, 0xca, 0x49, 0x4e, 0xc2, 0x38, 0xdd, 0x7a, 0x87, 0xe4, 0x66, 0x76, 0x9e,
-	0x4a, 0x7e, 0x9e, 0x6f, 0x5f, 0x40, 0x67, 0xd3, 0xf7, 0x5b, 0x0c, 0xff, 0xd1, 0xe9, 0xab, 0x81,
-	0xa9, 0xf3, 0x27, 0xc1, 0x15, 0xa7, 0x0f, 0xad, 0xd7, 0xa6, 0x81, 0x2d, 0xdb, 0xd4, 0x06, 0x4a,
-	0xa9, 0xff, 0x6e, 0x61, 0xee, 0x8c, 0x67, 0x51, 0x14, 0xf2, 0x11, 0x6b, 0x70, 0x15, 0x52, 0xcf,
-	0x4f, 0x18, 0x8d, 0x51, 0xf7, 0x63, 0x53, 0xe7, 0xe0, 0xa3, 0x11, 0xf5, 0xde, 0x61, 0xe9, 0x79,
-	0xe9, 0x55, 0x17, 0xf6, 0xc3, 0xd8, 0xeb, 0x5d, 0xf1, 0x97, 0x19, 0x4f, 0xe9, 0xc4, 0xa3, 0x71,
-	0x96, 0x70, 0x91, 0xfe, 0x29, 0xfc, 0xfe, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x6b, 0x55, 0xfc,
-	0xa4, 0x2e, 0x0a, 0x00, 0x00,
+	// 1181 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xdb, 0x6e, 0xdb, 0x46,
+	0x13, 0x8e, 0xce, 0xd2, 0xe8, 0xb4, 0x59, 0x2b, 0x0e, 0xa1, 0xff, 0x6f, 0x22, 0x10, 0x69, 0x20,
+	0xf4, 0x42, 0x49, 0xd5, 0xa4, 0x28, 0xd0, 0x22, 0x28, 0x23, 0x6e, 0x5c, 0xc6, 0x32, 0xa5, 0xac,
+	0x68, 0xa3, 0xb9, 0x32, 0x68, 0x6a, 0x2d, 0x13, 0x91, 0x49, 0x82, 0x5c, 0x09, 0xd6, 0x5d, 0xaf,
+	0x7b, 0xd5, 0x67, 0xe8, 0x63, 0xf4, 0x29, 0xfa, 0x14, 0x7d, 0x8e, 0x62, 0x97, 0xa4, 0xac, 0x83,
+	0xdd, 0x06, 0xe8, 0x95, 0x76, 0x66, 0xbe, 0x99, 0x9d, 0xc3, 0xb7, 0x43, 0x41, 0xd3, 0xb9, 0xb2,
+	0x5d, 0xcf, 0xf1, 0xa7, 0xac, 0x17, 0x84, 0x3e, 0xf7, 0x71, 0x51, 0xfe, 0x44, 0xed, 0xd6, 0xda,
+	0xc0, 0x96, 0xcc, 0xe3, 0xb1, 0xb5, 0xfd, 0x74, 0xe6, 0xfb, 0xb3, 0x39, 0x7b, 0x21, 0xa5, 0x8b,
+	0xc5, 0xe5, 0x0b, 0xee, 0x5e, 0xb3, 0x88, 0xdb, 0xd7, 0x41, 0x0c, 0x50, 0x5f, 0x43, 0x75, 0x90,
+	0x3a, 0x1a, 0x3a, 0xc6, 0x90, 0x0f, 0x6c, 0x7e, 0xa5, 0x64, 0x3a, 0x99, 0x6e, 0x85, 0xca, 0xb3,
+	0xd0, 0x79, 0xf6, 0x35, 0x53, 0xb2, 0xb1, 0x4e, 0x9c, 0xd5, 0x67, 0xd0, 0xb8, 0x75, 0xf3, 0x82,
+	0x05, 0x17, 0x28, 0x3b, 0x9c, 0x45, 0x4a, 0xa6, 0x93, 0xeb, 0xd6, 0xa8, 0x3c, 0xab, 0x7f, 0xe6,
+	0xa0, 0xbe, 0x86, 0x4d, 0x02, 0xe6, 0xe0, 0x1e, 0xe4, 0xf9, 0x2a, 0x60, 0x32, 0x7e, 0xa3, 0xdf,
+	0x8e, 0x93, 0x88, 0x7a, 0x5b, 0xa0, 0x9e, 0xb5, 0x0a, 0x18, 0x95, 0x38, 0xfc, 0x1a, 0xaa, 0xce,
+	0x6d, 0x7a, 0x32, 0x85, 0x6a, 0xff, 0x60, 0xcf, 0xcd, 0xd0, 0xe9, 0x26, 0x0e, 0xbf, 0x84, 0x92,
+	0xc3, 0xfd, 0xf0, 0x24, 0x9a, 0x29, 0x39, 0xe9, 0x72, 0xb8, 0xef, 0x22, 0xb2, 0xa6, 0x29, 0x0c,
+	0x2b, 0x50, 0x12, 0xad, 0xf1, 0x17, 0x5c, 0xc9, 0x77, 0x32, 0xdd, 0x02, 0x4d, 0x45, 0xfc, 0x0c,
+	0xea, 0x11, 0x73, 0x16, 0x21, 0x1b, 0xf8, 0x1e, 0x67, 0x37, 0x5c, 0x29, 0xc8, 0x3e, 0x6c, 0x2b,
+	0xf1, 0x18, 0x5a, 0x8e, 0xef, 0x5d, 0xba, 0x53, 0xe6, 0x71, 0xd7, 0x9e, 0xbb, 0x7c, 0x35, 0x64,
+	0x4b, 0x36, 0x57, 0x8a, 0xb2, 0xd0, 0xff, 0xaf, 0xaf, 0xbf, 0x03, 0x43, 0xef, 0xf4, 0xc4, 0x6d,
+	0x28, 0x5f, 0x33, 0x6e, 0x4f, 0x6d, 0x6e, 0x2b, 0xa5, 0x4e, 0xa6, 0x5b, 0xa3, 0x6b, 0x19, 0x3f,
+	0x01, 0xb0, 0x39, 0x0f, 0xdd, 0x8b, 0x05, 0x67, 0x91, 0x52, 0xee, 0xe4, 0xba, 0x15, 0xba, 0xa1,
+	0x11, 0xd5, 0xc8, 0x76, 0x18, 0xba, 0x52, 0x91, 0xd9, 0xa6, 0xa2, 0xfa, 0x06, 0xf2, 0xa2, 0xbd,
+	0xb8, 0x0e, 0x95, 0x53, 0x53, 0x27, 0xef, 0x0c, 0x93, 0xe8, 0xe8, 0x01, 0x06, 0x28, 0x1e, 0x8d,
+	0x86, 0x9a, 0x79, 0x84, 0x32, 0xb8, 0x0c, 0x79, 0x73, 0xa4, 0x13, 0x94, 0xc5, 0x25, 0xc8, 0x0d,
+	0x34, 0x8a, 0x72, 0x42, 0xf5, 0x5e, 0x3b, 0xd3, 0x50, 0x5e, 0xfd, 0x23, 0x0b, 0x8f, 0xd7, 0x3d,
+	0xd4, 0x59, 0x30, 0xf7, 0x57, 0xd7, 0xcc, 0xe3, 0x72, 0xb8, 0xdf, 0x43, 0xdd, 0xd9, 0x1c, 0xa4,
+	0x9c, 0x72, 0xb5, 0xff, 0xe8, 0xce, 0x29, 0xd3, 0x6d, 0x2c, 0xfe, 0x11, 0xea, 0xec, 0xf2, 0x92,
+	0x39, 0xdc, 0x5d, 0x32, 0xdd, 0xe6, 0x2c, 0x99, 0x75, 0xbb, 0x17, 0x33, 0xb8, 0x97, 0x32, 0xb8,
+	0x67, 0xa5, 0x0c, 0xa6, 0xdb, 0x0e, 0xb8, 0x03, 0x55, 0x11, 0x6d, 0x6c, 0x3b, 0x9f, 0xec, 0x19,
+	0x93, 0x83, 0xaf, 0xd1, 0x4d, 0x15, 0x36, 0xa1, 0xc4, 0x6e, 0x98, 0x43, 0xbc, 0xa5, 0x1c, 0x72,
+	0xa3, 0xff, 0x6a, 0x2f, 0xb5, 0xed, 0x92, 0x7a, 0xe4, 0x86, 0x39, 0x0b, 0xee, 0xfa, 0x1e, 0xf1,
+	0x96, 0x6e, 0xe8, 0x7b, 0xc2, 0x40, 0xd3, 0x20, 0x6a, 0x0f, 0x5a, 0x77, 0x01, 0x44, 0x37, 0xf5,
+	0xd1, 0xe0, 0x98, 0xd0, 0xb8, 0xb3, 0x93, 0x8f, 0x13, 0x8b, 0x9c, 0xa0, 0x8c, 0xfa, 0x4b, 0x66,
+	0xa3, 0x79, 0x86, 0xb7, 0xf4, 0x1d, 0x5b, 0xb8, 0xfe, 0xf7, 0xe6, 0x75, 0xa1, 0xe9, 0x4e, 0x8f,
+	0x98, 0xc7, 0x42, 0x19, 0x50, 0x9b, 0xcf, 0x92, 0xd7, 0xba, 0xab, 0x56, 0x7f, 0xcb, 0x82, 0x72,
+	0x1b, 0x4a, 0x50, 0xd8, 0xe5, 0xab, 0x94, 0xc4, 0x4f, 0x00, 0x1c, 0x7b, 0x3e, 0x67, 0xe1, 0x80,
+	0x85, 0x5c, 0x26, 0x50, 0xa3, 0x1b, 0x9a, 0x5b, 0xfb, 0xc4, 0x9d, 0x79, 0x4a, 0x76, 0xd3, 0x2e,
+	0x34, 0x82, 0x76, 0x81, 0xbd, 0x9a, 0xfb, 0xf6, 0x34, 0xe9, 0x7e, 0x2a, 0x0a, 0xcb, 0x85, 0xeb,
+	0x4d, 0x5d, 0x6f, 0x26, 0x3b, 0x5f, 0xa3, 0xa9, 0xb8, 0x45, 0xf3, 0xc2, 0x0e, 0xcd, 0x9f, 0x43,
+	0x23, 0xb0, 0x43, 0xe6, 0xf1, 0x93, 0x14, 0x51, 0x94, 0x88, 0x1d, 0x2d, 0xfe, 0x01, 0xaa, 0xfc,
+	0x66, 0xcd, 0x0b, 0xa5, 0xf4, 0xaf, 0xcc, 0xd9, 0x84, 0xab, 0xbf, 0x17, 0x00, 0xad, 0x5b, 0x72,
+	0xc2, 0xa2, 0x48, 0x50, 0xe5, 0xeb, 0xad, 0x45, 0xf5, 0xc5, 0xde, 0x14, 0x12, 0xdc, 0xe6, 0xae,
+	0xfa, 0x0e, 0x2a, 0xeb, 0xed, 0xfa, 0x19, 0xec, 0xbd, 0x05, 0xff, 0x43, 0xdf, 0x30, 0xe4, 0xf9,
+	0x8d, 0x3b, 0x95, 0x4d, 0xab, 0x50, 0x79, 0xc6, 0xef, 0xa1, 0x19, 0x6d, 0x0f, 0x4e, 0x36, 0xae,
+	0xda, 0xef, 0xec, 0x73, 0x65, 0x1b, 0x47, 0x77, 0x1d, 0xf1, 0x1b, 0x68, 0xac, 0x99, 0x44, 0xc4,
+	0x77, 0x43, 0x29, 0xde, 0xb3, 0x2f, 0xa5, 0x95, 0xee, 0xa0, 0xd5, 0xbf, 0xb2, 0x77, 0xef, 0x93,
+	0x1a, 0x94, 0x29, 0x39, 0x32, 0x26, 0x16, 0xa1, 0x28, 0x83, 0x1b, 0x00, 0xa9, 0x44, 0x74, 0x94,
+	0x15, 0xeb, 0xc4, 0x30, 0x0d, 0x0b, 0xe5, 0x70, 0x05, 0x0a, 0x94, 0x68, 0xfa, 0x47, 0x94, 0xc7,
+	0x4d, 0xa8, 0x5a, 0x54, 0x33, 0x27, 0xda, 0xc0, 0x32, 0x46, 0x26, 0x2a, 0x88, 0x90, 0x83, 0xd1,
+	0xc9, 0x78, 0x48, 0x2c, 0xa2, 0xa3, 0xa2, 0x80, 0x12, 0x4a, 0x47, 0x14, 0x95, 0x84, 0xe5, 0x88,
+	0x58, 0xe7, 0x13, 0x4b, 0xb3, 0x08, 0x2a, 0x0b, 0x71, 0x7c, 0x9a, 0x8a, 0x15, 0x21, 0xea, 0x64,
+	0x98, 0x88, 0x80, 0x5b, 0x80, 0x0c, 0xf3, 0x6c, 0x74, 0x4c, 0xce, 0x07, 0x3f, 0x69, 0x86, 0x39,
+	0x10, 0xab, 0xad, 0x8a, 0x11, 0xd4, 0x12, 0xed, 0x87, 0x53, 0x42, 0x3f, 0xa2, 0x5a, 0x9c, 0xf2,
+	0x64, 0x3c, 0x32, 0x27, 0x04, 0xd5, 0xc5, 0x6d, 0xb1, 0xa1, 0x81, 0x0f, 0xa0, 0x29, 0x8f, 0xe7,
+	0xb7, 0xd9, 0x34, 0x45, 0xb6, 0xb1, 0x32, 0xce, 0x09, 0xe1, 0x47, 0xf0, 0x90, 0x6a, 0xe6, 0x51,
+	0x12, 0x2f, 0xb9, 0xfd, 0x21, 0x6e, 0xc3, 0xe1, 0x9e, 0xfa, 0xdc, 0x24, 0x3f, 0x5b, 0x08, 0xe3,
+	0xff, 0xc1, 0xe3, 0x7d, 0xdb, 0x60, 0x38, 0x9a, 0x10, 0x74, 0x20, 0xaa, 0x38, 0x26, 0x64, 0xac,
+	0x0d, 0x8d, 0x33, 0x82, 0x5a, 0xea, 0xb7, 0x50, 0x1b, 0x2f, 0xf8, 0x84, 0xdb, 0x9c, 0x19, 0xde,
+	0xa5, 0x8f, 0x11, 0xe4, 0x3e, 0xb1, 0x55, 0xf2, 0x9d, 0x16, 0x47, 0xdc, 0x82, 0xc2, 0xd2, 0x9e,
+	0x2f, 0x58, 0xf2, 0x2e, 0x63, 0x41, 0x25, 0xd0, 0xa4, 0xb6, 0x37, 0x63, 0x1f, 0x16, 0x2c, 0x5c,
+	0x49, 0x77, 0xf1, 0xe2, 0x22, 0x6e, 0x87, 0xfc, 0x78, 0xed, 0xbf, 0x96, 0xf1, 0x21, 0x14, 0x99,
+	0x37, 0x15, 0x96, 0x78, 0x7f, 0x24, 0x92, 0xfa, 0x25, 0x1c, 0xec, 0x84, 0x31, 0x05, 0x7d, 0x1a,
+	0x90, 0x35, 0xf4, 0x24, 0x48, 0xd6, 0xd5, 0xd5, 0xe7, 0xd0, 0xda, 0x81, 0x0d, 0xe6, 0x7e, 0xc4,
+	0xf6, 0x70, 0x1a, 0x3c, 0xde, 0xc1, 0x1d, 0xb3, 0xd5, 0x99, 0x48, 0xf8, 0xb3, 0x0b, 0xfb, 0x35,
+	0xb3, 0x17, 0x83, 0xb2, 0x28, 0xf0, 0xbd, 0x88, 0x61, 0x02, 0xf5, 0x4f, 0x6c, 0x15, 0x69, 0xde,
+	0x54, 0xc6, 0x8c, 0xff, 0x94, 0x54, 0xfb, 0x4f, 0x53, 0x52, 0xdf, 0x73, 0x37, 0xdd, 0xf6, 0x12,
+	0xcf, 0xf2, 0xca, 0x8e, 0x4e, 0xfc, 0x30, 0xbe, 0xba, 0x4c, 0x53, 0x31, 0xa9, 0x27, 0x97, 0xd6,
+	0xf3, 0xd5, 0x2b, 0x68, 0xdd, 0xf5, 0x65, 0x17, 0xcb, 0x7f, 0x7c, 0xfa, 0x76, 0x68, 0x0c, 0xd0,
+	0x03, 0xc1, 0xb8, 0xc1, 0xc8, 0x7c, 0x67, 0xe8, 0xc4, 0xb4, 0x0c, 0x6d, 0x88, 0x32, 0xfd, 0xb3,
+	0x8d, 0xbd, 0x33, 0x59, 0x04, 0x81, 0x1f, 0x72, 0xfc, 0x16, 0xca, 0x94, 0xcd, 0xdc, 0x88, 0xb3,
+	0x10, 0x2b, 0xf7, 0x6d, 0x9d, 0xf6, 0xbd, 0x96, 0x6e, 0xe6, 0x65, 0xe6, 0xad, 0x02, 0x87, 0x7e,
+	0x38, 0xeb, 0x5d, 0xad, 0x02, 0x16, 0xce, 0xd9, 0x74, 0xc6, 0xc2, 0x04, 0x7e, 0x11, 0xff, 0x59,
+	0xfc, 0xe6, 0xef, 0x01, 0x00, 0xf6, 0xf7, 0x5d, 0x89, 0x46, 0x0a, 0x00, 0x00,
 }
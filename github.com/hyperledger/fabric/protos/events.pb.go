@@ -67,6 +67,8 @@ type Interest struct {
 	// Types that are valid to be assigned to RegInfo:
 	//	*Interest_ChaincodeRegInfo
 	RegInfo isInterest_RegInfo `protobuf_oneof:"RegInfo"`
+	// The chain whose events are of interest, the default chain if empty
+	ChainID string `protobuf:"bytes,3,opt,name=chainID" json:"chainID,omitempty"`
 }
 
 func (m *Interest) Reset()                    { *m = Interest{} }
@@ -208,6 +210,17 @@ func (m *Unregister) GetEvents() []*Interest {
 	return nil
 }
 
+// Shutdown is the last event the producer sends on a stream, when the peer
+// shuts down
+type Shutdown struct {
+	Reason string `protobuf:"bytes,1,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *Shutdown) Reset()                    { *m = Shutdown{} }
+func (m *Shutdown) String() string            { return proto.CompactTextString(m) }
+func (*Shutdown) ProtoMessage()               {}
+func (*Shutdown) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{5} }
+
 // Event is used by
 //  - consumers (adapters) to send Register
 //  - producer to advertise supported types and events
@@ -218,13 +231,16 @@ type Event struct {
 	//	*Event_ChaincodeEvent
 	//	*Event_Rejection
 	//	*Event_Unregister
+	//	*Event_Shutdown
 	Event isEvent_Event `protobuf_oneof:"Event"`
+	// The chain of a producer event, the default chain if empty
+	ChainID string `protobuf:"bytes,6,opt,name=chainID" json:"chainID,omitempty"`
 }
 
 func (m *Event) Reset()                    { *m = Event{} }
 func (m *Event) String() string            { return proto.CompactTextString(m) }
 func (*Event) ProtoMessage()               {}
-func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{5} }
+func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor4, []int{6} }
 
 type isEvent_Event interface {
 	isEvent_Event()
@@ -245,12 +261,16 @@ type Event_Rejection struct {
 type Event_Unregister struct {
 	Unregister *Unregister `protobuf:"bytes,5,opt,name=unregister,oneof"`
 }
+type Event_Shutdown struct {
+	Shutdown *Shutdown `protobuf:"bytes,7,opt,name=shutdown,oneof"`
+}
 
 func (*Event_Register) isEvent_Event()       {}
 func (*Event_Block) isEvent_Event()          {}
 func (*Event_ChaincodeEvent) isEvent_Event() {}
 func (*Event_Rejection) isEvent_Event()      {}
 func (*Event_Unregister) isEvent_Event()     {}
+func (*Event_Shutdown) isEvent_Event()       {}
 
 func (m *Event) GetEvent() isEvent_Event {
 	if m != nil {
@@ -294,6 +314,13 @@ func (m *Event) GetUnregister() *Unregister {
 	return nil
 }
 
+func (m *Event) GetShutdown() *Shutdown {
+	if x, ok := m.GetEvent().(*Event_Shutdown); ok {
+		return x.Shutdown
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Event_OneofMarshaler, _Event_OneofUnmarshaler, _Event_OneofSizer, []interface{}{
@@ -302,6 +329,7 @@ func (*Event) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error,
 		(*Event_ChaincodeEvent)(nil),
 		(*Event_Rejection)(nil),
 		(*Event_Unregister)(nil),
+		(*Event_Shutdown)(nil),
 	}
 }
 
@@ -334,6 +362,11 @@ func _Event_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Unregister); err != nil {
 			return err
 		}
+	case *Event_Shutdown:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Shutdown); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Event.Event has unexpected type %T", x)
@@ -384,6 +417,14 @@ func _Event_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer)
 		err := b.DecodeMessage(msg)
 		m.Event = &Event_Unregister{msg}
 		return true, err
+	case 7: // Event.shutdown
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Shutdown)
+		err := b.DecodeMessage(msg)
+		m.Event = &Event_Shutdown{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -418,6 +459,11 @@ func _Event_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(5<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *Event_Shutdown:
+		s := proto.Size(x.Shutdown)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -431,6 +477,7 @@ func init() {
 	proto.RegisterType((*Register)(nil), "protos.Register")
 	proto.RegisterType((*Rejection)(nil), "protos.Rejection")
 	proto.RegisterType((*Unregister)(nil), "protos.Unregister")
+	proto.RegisterType((*Shutdown)(nil), "protos.Shutdown")
 	proto.RegisterType((*Event)(nil), "protos.Event")
 	proto.RegisterEnum("protos.EventType", EventType_name, EventType_value)
 }
@@ -544,33 +591,37 @@ var _Events_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("events.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 445 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x53, 0x5d, 0x6f, 0xd3, 0x30,
-	0x14, 0x6d, 0xda, 0xb5, 0xab, 0x6f, 0xd3, 0x29, 0xbb, 0x4c, 0x28, 0x8a, 0x78, 0x98, 0x82, 0x90,
-	0xaa, 0x3d, 0x14, 0x08, 0x15, 0xcf, 0x90, 0x2c, 0x62, 0x81, 0x91, 0x4a, 0x26, 0xfc, 0x80, 0x34,
-	0x78, 0x23, 0x7c, 0x24, 0x93, 0x1d, 0x10, 0xfc, 0x05, 0x1e, 0xf9, 0xc5, 0x78, 0x8e, 0x9d, 0x64,
-	0xf4, 0x69, 0x4f, 0xf5, 0xbd, 0xe7, 0x9c, 0x7b, 0x8f, 0x4f, 0x1d, 0xb0, 0xd9, 0x4f, 0x56, 0x35,
-	0x62, 0x7d, 0xc3, 0xeb, 0xa6, 0xc6, 0x99, 0xfa, 0x11, 0xde, 0x49, 0xf1, 0x39, 0x2f, 0xab, 0xa2,
-	0xfe, 0xc4, 0x14, 0xdc, 0xa2, 0x9e, 0x7d, 0x95, 0xef, 0x78, 0x59, 0xb4, 0x95, 0x9f, 0x82, 0x1d,
-	0x19, 0x16, 0x65, 0xd7, 0x78, 0x0a, 0x8b, 0x4e, 0x95, 0x9c, 0xbb, 0xd6, 0xa9, 0xb5, 0x22, 0x74,
-	0xd8, 0xc2, 0x47, 0x40, 0xd4, 0xb8, 0x34, 0xff, 0xce, 0xdc, 0xb1, 0xc2, 0xfb, 0x86, 0xff, 0xc7,
-	0x82, 0x79, 0x52, 0x35, 0x8c, 0x33, 0xd1, 0xe0, 0x53, 0x4d, 0xcd, 0x7e, 0xdf, 0x30, 0x35, 0xea,
-	0x28, 0x38, 0x6e, 0xf7, 0x8a, 0x75, 0x6c, 0x00, 0xda, 0x73, 0x30, 0x04, 0xa7, 0x18, 0xb8, 0x49,
-	0xaa, 0xab, 0x5a, 0xad, 0x58, 0x04, 0x27, 0x46, 0x37, 0x74, 0x7b, 0x31, 0xa2, 0x7b, 0xfc, 0x90,
-	0xc0, 0xa1, 0x3e, 0xfa, 0x1b, 0x98, 0xcb, 0x63, 0x29, 0xa4, 0x1d, 0x5c, 0xc1, 0xac, 0x0d, 0x49,
-	0x1a, 0x99, 0xc8, 0x81, 0x8e, 0x19, 0x68, 0xdc, 0x52, 0x8d, 0xfb, 0x97, 0x40, 0x28, 0xfb, 0xc2,
-	0x8a, 0xa6, 0xac, 0x2b, 0x7c, 0x0c, 0xe3, 0xe6, 0x97, 0xf2, 0xbe, 0x08, 0x1e, 0x18, 0x49, 0xc6,
-	0xf3, 0x4a, 0xe4, 0x8a, 0x40, 0x25, 0x8c, 0x1e, 0xcc, 0x19, 0xe7, 0x35, 0x7f, 0x2f, 0xae, 0x75,
-	0x22, 0x5d, 0xed, 0xbf, 0x04, 0xf8, 0x58, 0xf1, 0xfb, 0xbb, 0xf8, 0x3b, 0x86, 0xa9, 0xca, 0x08,
-	0xd7, 0x30, 0x37, 0x7a, 0x6d, 0xa4, 0x53, 0x99, 0xdb, 0xc9, 0x20, 0x3a, 0x0e, 0x3e, 0x81, 0xe9,
-	0xee, 0x5b, 0x5d, 0x7c, 0xd5, 0xc9, 0x2d, 0x0d, 0x39, 0xbc, 0x6d, 0x4a, 0x66, 0x8b, 0xe2, 0x2b,
-	0x38, 0xea, 0xb2, 0x53, 0x8b, 0xdc, 0x89, 0xe2, 0x3f, 0xdc, 0x4b, 0x5a, 0xa1, 0x52, 0xf8, 0x1f,
-	0x1f, 0x9f, 0x03, 0xe1, 0x26, 0x28, 0xf7, 0x40, 0x89, 0x8f, 0x7b, 0x67, 0x1a, 0x90, 0xba, 0x9e,
-	0x85, 0x1b, 0x80, 0x1f, 0x5d, 0x1a, 0xee, 0x54, 0x69, 0xd0, 0x68, 0xfa, 0x9c, 0xa4, 0x68, 0xc0,
-	0x0b, 0x0f, 0x75, 0x14, 0x67, 0x21, 0x90, 0xee, 0xdd, 0xa0, 0x2d, 0xff, 0xdd, 0xf8, 0x4d, 0xf2,
-	0x21, 0x8b, 0xa9, 0x33, 0x42, 0x02, 0xd3, 0xf0, 0x72, 0x1b, 0xbd, 0x73, 0x2c, 0x5c, 0x02, 0x89,
-	0x2e, 0x5e, 0x27, 0x69, 0xb4, 0x3d, 0x8f, 0x9d, 0xf1, 0x6d, 0x49, 0xe3, 0xb7, 0x71, 0x94, 0x25,
-	0xdb, 0xd4, 0x99, 0x04, 0x1b, 0x98, 0xa9, 0x19, 0x02, 0xcf, 0xe0, 0x40, 0xde, 0xb1, 0xc1, 0xe5,
-	0x9d, 0x37, 0xe9, 0xdd, 0x2d, 0xfd, 0xd1, 0xca, 0x7a, 0x66, 0xed, 0xda, 0x6f, 0xea, 0xc5, 0xbf,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x15, 0xb7, 0x5f, 0x65, 0x6a, 0x03, 0x00, 0x00,
+	// 498 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x93, 0xcd, 0x6e, 0x9b, 0x4c,
+	0x14, 0x86, 0xc1, 0x8e, 0x6d, 0x38, 0xd8, 0x11, 0x39, 0x5f, 0x14, 0x21, 0xeb, 0x5b, 0x58, 0x54,
+	0x95, 0x50, 0x17, 0x6e, 0x4b, 0xa3, 0xae, 0x5b, 0x30, 0x2a, 0xb4, 0xa9, 0x2d, 0x4d, 0xdc, 0x0b,
+	0xc0, 0x64, 0x62, 0xbb, 0x3f, 0x10, 0xcd, 0x4c, 0xda, 0xf4, 0x7e, 0x7a, 0x59, 0xbd, 0x98, 0xca,
+	0xc3, 0x0c, 0xe0, 0x66, 0xd5, 0x15, 0x3a, 0xe7, 0x7d, 0xcf, 0x0f, 0xcf, 0xcc, 0xc0, 0x98, 0x7e,
+	0xa7, 0xa5, 0xe0, 0xf3, 0x3b, 0x56, 0x89, 0x0a, 0x87, 0xf2, 0xc3, 0xa7, 0xe7, 0xc5, 0x2e, 0xdf,
+	0x97, 0x45, 0x75, 0x43, 0xa5, 0x5c, 0xab, 0xd3, 0xf1, 0x6d, 0xbe, 0x61, 0xfb, 0xa2, 0x8e, 0xfc,
+	0x25, 0x8c, 0x63, 0xed, 0x22, 0x74, 0x8b, 0x33, 0x70, 0x9a, 0xaa, 0x6c, 0xe1, 0x99, 0x33, 0x33,
+	0xb0, 0x49, 0x37, 0x85, 0xff, 0x83, 0x2d, 0xdb, 0x2d, 0xf3, 0x6f, 0xd4, 0xeb, 0x49, 0xbd, 0x4d,
+	0xf8, 0xbf, 0x4c, 0xb0, 0xb2, 0x52, 0x50, 0x46, 0xb9, 0xc0, 0xe7, 0xca, 0xba, 0xfe, 0x79, 0x47,
+	0x65, 0xab, 0xd3, 0xf0, 0xac, 0x9e, 0xcb, 0xe7, 0x89, 0x16, 0x48, 0xeb, 0xc1, 0x08, 0xdc, 0xa2,
+	0xb3, 0x4d, 0x56, 0xde, 0x56, 0x72, 0x84, 0x13, 0x9e, 0xeb, 0xba, 0xee, 0xb6, 0xa9, 0x41, 0x1e,
+	0xf9, 0xd1, 0x83, 0x91, 0xcc, 0x65, 0x0b, 0xaf, 0x2f, 0xb7, 0xd3, 0x61, 0x64, 0xc3, 0x48, 0x99,
+	0xfc, 0x4b, 0xb0, 0x08, 0xdd, 0xee, 0xb9, 0xa0, 0x0c, 0x03, 0x18, 0xd6, 0xf8, 0x3c, 0x73, 0xd6,
+	0x0f, 0x9c, 0xd0, 0xd5, 0xa3, 0xf4, 0x7f, 0x10, 0xa5, 0xfb, 0x57, 0x60, 0x13, 0xfa, 0x99, 0x16,
+	0x62, 0x5f, 0x95, 0xf8, 0x04, 0x7a, 0xe2, 0x41, 0xfe, 0x95, 0x13, 0xfe, 0xa7, 0x4b, 0xd6, 0x2c,
+	0x2f, 0x79, 0x2e, 0x0d, 0xa4, 0x27, 0x1e, 0x70, 0x0a, 0x16, 0x65, 0xac, 0x62, 0x1f, 0xf9, 0x56,
+	0xb1, 0x6a, 0x62, 0xff, 0x35, 0xc0, 0xa7, 0x92, 0xfd, 0xfb, 0x16, 0x3e, 0x58, 0xd7, 0xbb, 0x7b,
+	0x71, 0x53, 0xfd, 0x28, 0xf1, 0x02, 0x86, 0x8c, 0xe6, 0xbc, 0x2a, 0xd5, 0x49, 0xa9, 0xc8, 0xff,
+	0xdd, 0x83, 0x81, 0x24, 0x8c, 0x73, 0xb0, 0xf4, 0x0c, 0xb5, 0x6c, 0xd3, 0x59, 0x13, 0x48, 0x0d,
+	0xd2, 0x78, 0xf0, 0x29, 0x0c, 0x36, 0x5f, 0xab, 0xe2, 0x8b, 0xe2, 0x3e, 0xd1, 0xe6, 0xe8, 0x90,
+	0x4c, 0x0d, 0x52, 0xab, 0xf8, 0x06, 0x4e, 0x1b, 0xf2, 0x72, 0x90, 0x84, 0xed, 0x84, 0x17, 0x8f,
+	0xce, 0x49, 0xaa, 0xa9, 0x41, 0xfe, 0xf2, 0xe3, 0x4b, 0xb0, 0x99, 0x86, 0xe9, 0x9d, 0xc8, 0xe2,
+	0xb3, 0x76, 0x33, 0x25, 0xa4, 0x06, 0x69, 0x5d, 0x78, 0x09, 0x70, 0xdf, 0x10, 0xf3, 0x06, 0xb2,
+	0x06, 0x75, 0x4d, 0xcb, 0x32, 0x35, 0x48, 0xc7, 0x77, 0x20, 0xc0, 0x15, 0x2f, 0x6f, 0x74, 0x4c,
+	0x40, 0x73, 0x3c, 0x10, 0xd0, 0x9e, 0xee, 0x05, 0x1a, 0x1e, 0x5f, 0xa0, 0x91, 0x82, 0xfa, 0x2c,
+	0x02, 0xbb, 0xb9, 0xbf, 0x38, 0x06, 0x8b, 0x24, 0xef, 0xb2, 0xeb, 0x75, 0x42, 0x5c, 0x03, 0x6d,
+	0x18, 0x44, 0x57, 0xab, 0xf8, 0x83, 0x6b, 0xe2, 0x04, 0xec, 0x38, 0x7d, 0x9b, 0x2d, 0xe3, 0xd5,
+	0x22, 0x71, 0x7b, 0x87, 0x90, 0x24, 0xef, 0x93, 0x78, 0x9d, 0xad, 0x96, 0x6e, 0x3f, 0x0c, 0x61,
+	0x28, 0x7b, 0x70, 0x0c, 0xe0, 0x24, 0xde, 0xe5, 0x02, 0x27, 0x47, 0x6f, 0x63, 0x7a, 0x1c, 0x06,
+	0xe6, 0x0b, 0x73, 0x53, 0xbf, 0xec, 0x57, 0x7f, 0x06, 0x00, 0xbf, 0x2f, 0xba, 0x52, 0xf0, 0x03,
+	0x00, 0x00,
 }
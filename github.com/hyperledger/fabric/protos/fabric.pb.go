@@ -19,6 +19,30 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// Compression is the algorithm the payload of a Message is compressed with.
+// A peer only compresses the messages to another peer with a compression
+// the other peer announced in its HelloMessage.
+type Compression int32
+
+const (
+	Compression_NONE Compression = 0
+	Compression_GZIP Compression = 1
+)
+
+var Compression_name = map[int32]string{
+	0: "NONE",
+	1: "GZIP",
+}
+var Compression_value = map[string]int32{
+	"NONE": 0,
+	"GZIP": 1,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+func (Compression) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{0} }
+
 type Transaction_Type int32
 
 const (
@@ -86,6 +110,7 @@ const (
 	Message_DISC_GET_PEERS          Message_Type = 3
 	Message_DISC_PEERS              Message_Type = 4
 	Message_DISC_NEWMSG             Message_Type = 5
+	Message_DISC_GOSSIP             Message_Type = 7
 	Message_CHAIN_TRANSACTION       Message_Type = 6
 	Message_SYNC_GET_BLOCKS         Message_Type = 11
 	Message_SYNC_BLOCKS             Message_Type = 12
@@ -105,6 +130,7 @@ var Message_Type_name = map[int32]string{
 	3:  "DISC_GET_PEERS",
 	4:  "DISC_PEERS",
 	5:  "DISC_NEWMSG",
+	7:  "DISC_GOSSIP",
 	6:  "CHAIN_TRANSACTION",
 	11: "SYNC_GET_BLOCKS",
 	12: "SYNC_BLOCKS",
@@ -123,6 +149,7 @@ var Message_Type_value = map[string]int32{
 	"DISC_GET_PEERS":          3,
 	"DISC_PEERS":              4,
 	"DISC_NEWMSG":             5,
+	"DISC_GOSSIP":             7,
 	"CHAIN_TRANSACTION":       6,
 	"SYNC_GET_BLOCKS":         11,
 	"SYNC_BLOCKS":             12,
@@ -138,7 +165,7 @@ var Message_Type_value = map[string]int32{
 func (x Message_Type) String() string {
 	return proto.EnumName(Message_Type_name, int32(x))
 }
-func (Message_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{12, 0} }
+func (Message_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{17, 0} }
 
 type Response_StatusCode int32
 
@@ -162,7 +189,46 @@ var Response_StatusCode_value = map[string]int32{
 func (x Response_StatusCode) String() string {
 	return proto.EnumName(Response_StatusCode_name, int32(x))
 }
-func (Response_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{13, 0} }
+func (Response_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{18, 0} }
+
+type TransactionSubmission_State int32
+
+const (
+	TransactionSubmission_UNKNOWN TransactionSubmission_State = 0
+	// accepted, not forwarded to a validator yet
+	TransactionSubmission_PENDING TransactionSubmission_State = 1
+	// accepted by a validator, not committed yet
+	TransactionSubmission_FORWARDED TransactionSubmission_State = 2
+	TransactionSubmission_COMMITTED TransactionSubmission_State = 3
+	// rejected by the validators, or no validator could be reached
+	TransactionSubmission_FAILED TransactionSubmission_State = 4
+	// not committed before the submission timeout
+	TransactionSubmission_TIMED_OUT TransactionSubmission_State = 5
+)
+
+var TransactionSubmission_State_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "PENDING",
+	2: "FORWARDED",
+	3: "COMMITTED",
+	4: "FAILED",
+	5: "TIMED_OUT",
+}
+var TransactionSubmission_State_value = map[string]int32{
+	"UNKNOWN":   0,
+	"PENDING":   1,
+	"FORWARDED": 2,
+	"COMMITTED": 3,
+	"FAILED":    4,
+	"TIMED_OUT": 5,
+}
+
+func (x TransactionSubmission_State) String() string {
+	return proto.EnumName(TransactionSubmission_State_name, int32(x))
+}
+func (TransactionSubmission_State) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor5, []int{19, 0}
+}
 
 // Transaction defines a function call to a contract.
 // `args` is an array of type string so that the chaincode writer can choose
@@ -183,6 +249,8 @@ type Transaction struct {
 	ToValidators                   []byte                     `protobuf:"bytes,10,opt,name=toValidators,proto3" json:"toValidators,omitempty"`
 	Cert                           []byte                     `protobuf:"bytes,11,opt,name=cert,proto3" json:"cert,omitempty"`
 	Signature                      []byte                     `protobuf:"bytes,12,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The chain the transaction is executed on, the default chain if empty
+	ChainID string `protobuf:"bytes,13,opt,name=chainID" json:"chainID,omitempty"`
 }
 
 func (m *Transaction) Reset()                    { *m = Transaction{} }
@@ -398,6 +466,8 @@ func (*PeersAddresses) Descriptor() ([]byte, []int) { return fileDescriptor5, []
 type HelloMessage struct {
 	PeerEndpoint   *PeerEndpoint   `protobuf:"bytes,1,opt,name=peerEndpoint" json:"peerEndpoint,omitempty"`
 	BlockchainInfo *BlockchainInfo `protobuf:"bytes,2,opt,name=blockchainInfo" json:"blockchainInfo,omitempty"`
+	// The compressions the peer accepts, in order of preference
+	Compressions []Compression `protobuf:"varint,3,rep,packed,name=compressions,enum=protos.Compression" json:"compressions,omitempty"`
 }
 
 func (m *HelloMessage) Reset()                    { *m = HelloMessage{} }
@@ -419,17 +489,115 @@ func (m *HelloMessage) GetBlockchainInfo() *BlockchainInfo {
 	return nil
 }
 
+// AliveMessage is gossiped by a peer to announce that it is alive. The
+// incarnation is set when the peer starts, and the heartbeat increases within
+// an incarnation, so that a more recent message supersedes an older one.
+type AliveMessage struct {
+	PeerEndpoint *PeerEndpoint `protobuf:"bytes,1,opt,name=peerEndpoint" json:"peerEndpoint,omitempty"`
+	Incarnation  uint64        `protobuf:"varint,2,opt,name=incarnation" json:"incarnation,omitempty"`
+	Heartbeat    uint64        `protobuf:"varint,3,opt,name=heartbeat" json:"heartbeat,omitempty"`
+}
+
+func (m *AliveMessage) Reset()                    { *m = AliveMessage{} }
+func (m *AliveMessage) String() string            { return proto.CompactTextString(m) }
+func (*AliveMessage) ProtoMessage()               {}
+func (*AliveMessage) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{12} }
+
+func (m *AliveMessage) GetPeerEndpoint() *PeerEndpoint {
+	if m != nil {
+		return m.PeerEndpoint
+	}
+	return nil
+}
+
+// SignedAliveMessage is a marshalled AliveMessage, signed with the enrollment
+// key of the peer it announces when security is enabled
+type SignedAliveMessage struct {
+	Alive     []byte `protobuf:"bytes,1,opt,name=alive,proto3" json:"alive,omitempty"`
+	Signature []byte `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *SignedAliveMessage) Reset()                    { *m = SignedAliveMessage{} }
+func (m *SignedAliveMessage) String() string            { return proto.CompactTextString(m) }
+func (*SignedAliveMessage) ProtoMessage()               {}
+func (*SignedAliveMessage) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{13} }
+
+// GossipMembership is the payload of Message.DISC_GOSSIP, the latest alive
+// messages of the peers the sender considers alive, including its own
+type GossipMembership struct {
+	Members []*SignedAliveMessage `protobuf:"bytes,1,rep,name=members" json:"members,omitempty"`
+}
+
+func (m *GossipMembership) Reset()                    { *m = GossipMembership{} }
+func (m *GossipMembership) String() string            { return proto.CompactTextString(m) }
+func (*GossipMembership) ProtoMessage()               {}
+func (*GossipMembership) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{14} }
+
+func (m *GossipMembership) GetMembers() []*SignedAliveMessage {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+// MembershipRecord is what a peer persists about another member
+type MembershipRecord struct {
+	PeerEndpoint *PeerEndpoint              `protobuf:"bytes,1,opt,name=peerEndpoint" json:"peerEndpoint,omitempty"`
+	Reputation   int32                      `protobuf:"varint,2,opt,name=reputation" json:"reputation,omitempty"`
+	LastSeen     *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=lastSeen" json:"lastSeen,omitempty"`
+}
+
+func (m *MembershipRecord) Reset()                    { *m = MembershipRecord{} }
+func (m *MembershipRecord) String() string            { return proto.CompactTextString(m) }
+func (*MembershipRecord) ProtoMessage()               {}
+func (*MembershipRecord) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{15} }
+
+func (m *MembershipRecord) GetPeerEndpoint() *PeerEndpoint {
+	if m != nil {
+		return m.PeerEndpoint
+	}
+	return nil
+}
+
+func (m *MembershipRecord) GetLastSeen() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.LastSeen
+	}
+	return nil
+}
+
+// MembershipSnapshot is the membership a peer persists between restarts
+type MembershipSnapshot struct {
+	Members []*MembershipRecord `protobuf:"bytes,1,rep,name=members" json:"members,omitempty"`
+}
+
+func (m *MembershipSnapshot) Reset()                    { *m = MembershipSnapshot{} }
+func (m *MembershipSnapshot) String() string            { return proto.CompactTextString(m) }
+func (*MembershipSnapshot) ProtoMessage()               {}
+func (*MembershipSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{16} }
+
+func (m *MembershipSnapshot) GetMembers() []*MembershipRecord {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
 type Message struct {
 	Type      Message_Type               `protobuf:"varint,1,opt,name=type,enum=protos.Message_Type" json:"type,omitempty"`
 	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=timestamp" json:"timestamp,omitempty"`
 	Payload   []byte                     `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
 	Signature []byte                     `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The chain a consensus or state sync message is for, the default chain if empty
+	ChainID string `protobuf:"bytes,5,opt,name=chainID" json:"chainID,omitempty"`
+	// The compression of the payload, the signature is of the uncompressed payload
+	Compression Compression `protobuf:"varint,6,opt,name=compression,enum=protos.Compression" json:"compression,omitempty"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
 func (m *Message) String() string            { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()               {}
-func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{12} }
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{17} }
 
 func (m *Message) GetTimestamp() *google_protobuf.Timestamp {
 	if m != nil {
@@ -446,7 +614,55 @@ type Response struct {
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{13} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{18} }
+
+// TransactionSubmission is the state of a transaction submitted to a peer.
+// A non-validating peer forwards the transaction to the validators, failing
+// over to another one until the transaction commits or the submission times
+// out. A validator reports the transactions committed on its ledger.
+type TransactionSubmission struct {
+	Txid    string                      `protobuf:"bytes,1,opt,name=txid" json:"txid,omitempty"`
+	ChainID string                      `protobuf:"bytes,2,opt,name=chainID" json:"chainID,omitempty"`
+	State   TransactionSubmission_State `protobuf:"varint,3,opt,name=state,enum=protos.TransactionSubmission_State" json:"state,omitempty"`
+	// The validator the transaction was last forwarded to
+	Validator string `protobuf:"bytes,4,opt,name=validator" json:"validator,omitempty"`
+	// The number of times the transaction was forwarded
+	Attempts uint32 `protobuf:"varint,5,opt,name=attempts" json:"attempts,omitempty"`
+	// The block the transaction committed in
+	BlockNumber uint64                     `protobuf:"varint,6,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Error       string                     `protobuf:"bytes,7,opt,name=error" json:"error,omitempty"`
+	Submitted   *google_protobuf.Timestamp `protobuf:"bytes,8,opt,name=submitted" json:"submitted,omitempty"`
+	Updated     *google_protobuf.Timestamp `protobuf:"bytes,9,opt,name=updated" json:"updated,omitempty"`
+}
+
+func (m *TransactionSubmission) Reset()                    { *m = TransactionSubmission{} }
+func (m *TransactionSubmission) String() string            { return proto.CompactTextString(m) }
+func (*TransactionSubmission) ProtoMessage()               {}
+func (*TransactionSubmission) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{19} }
+
+func (m *TransactionSubmission) GetSubmitted() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Submitted
+	}
+	return nil
+}
+
+func (m *TransactionSubmission) GetUpdated() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Updated
+	}
+	return nil
+}
+
+type TransactionSubmissionRequest struct {
+	Txid    string `protobuf:"bytes,1,opt,name=txid" json:"txid,omitempty"`
+	ChainID string `protobuf:"bytes,2,opt,name=chainID" json:"chainID,omitempty"`
+}
+
+func (m *TransactionSubmissionRequest) Reset()                    { *m = TransactionSubmissionRequest{} }
+func (m *TransactionSubmissionRequest) String() string            { return proto.CompactTextString(m) }
+func (*TransactionSubmissionRequest) ProtoMessage()               {}
+func (*TransactionSubmissionRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{20} }
 
 // BlockState is the payload of Message.SYNC_BLOCK_ADDED. When a VP
 // commits a new block to the ledger, it will notify its connected NVPs of the
@@ -461,7 +677,7 @@ type BlockState struct {
 func (m *BlockState) Reset()                    { *m = BlockState{} }
 func (m *BlockState) String() string            { return proto.CompactTextString(m) }
 func (*BlockState) ProtoMessage()               {}
-func (*BlockState) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{14} }
+func (*BlockState) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{21} }
 
 func (m *BlockState) GetBlock() *Block {
 	if m != nil {
@@ -484,7 +700,7 @@ type SyncBlockRange struct {
 func (m *SyncBlockRange) Reset()                    { *m = SyncBlockRange{} }
 func (m *SyncBlockRange) String() string            { return proto.CompactTextString(m) }
 func (*SyncBlockRange) ProtoMessage()               {}
-func (*SyncBlockRange) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{15} }
+func (*SyncBlockRange) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{22} }
 
 // SyncBlocks is the payload of Message.SYNC_BLOCKS, where the range
 // indicates the blocks responded to the request SYNC_GET_BLOCKS
@@ -496,7 +712,7 @@ type SyncBlocks struct {
 func (m *SyncBlocks) Reset()                    { *m = SyncBlocks{} }
 func (m *SyncBlocks) String() string            { return proto.CompactTextString(m) }
 func (*SyncBlocks) ProtoMessage()               {}
-func (*SyncBlocks) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{16} }
+func (*SyncBlocks) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{23} }
 
 func (m *SyncBlocks) GetRange() *SyncBlockRange {
 	if m != nil {
@@ -513,30 +729,61 @@ func (m *SyncBlocks) GetBlocks() []*Block {
 }
 
 // SyncSnapshotRequest Payload for the penchainMessage.SYNC_GET_SNAPSHOT message.
+// Without a chunk, the snapshot is of the whole current state.
 type SyncStateSnapshotRequest struct {
-	CorrelationId uint64 `protobuf:"varint,1,opt,name=correlationId" json:"correlationId,omitempty"`
+	CorrelationId uint64                  `protobuf:"varint,1,opt,name=correlationId" json:"correlationId,omitempty"`
+	Chunk         *SyncStateSnapshotChunk `protobuf:"bytes,2,opt,name=chunk" json:"chunk,omitempty"`
 }
 
 func (m *SyncStateSnapshotRequest) Reset()                    { *m = SyncStateSnapshotRequest{} }
 func (m *SyncStateSnapshotRequest) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateSnapshotRequest) ProtoMessage()               {}
-func (*SyncStateSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{17} }
+func (*SyncStateSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{24} }
+
+func (m *SyncStateSnapshotRequest) GetChunk() *SyncStateSnapshotChunk {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+// SyncStateSnapshotChunk restricts a SyncStateSnapshotRequest to a partition
+// of the state as of a past block, so that a snapshot may be retrieved from
+// several peers at once, and a disconnection only loses a partition. The
+// state is split into partitions by the state implementation, whose hash is
+// computed from the hashes of the partitions, and the serving peer rolls its
+// current state back to the block with the state deltas it keeps. When hashes
+// is set, the terminating message carries the hashes of all the partitions
+// instead, which are checked against the state hash of the block, so that
+// each partition can be checked against them.
+type SyncStateSnapshotChunk struct {
+	BlockNumber uint64 `protobuf:"varint,1,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Partition   uint32 `protobuf:"varint,2,opt,name=partition" json:"partition,omitempty"`
+	Partitions  uint32 `protobuf:"varint,3,opt,name=partitions" json:"partitions,omitempty"`
+	Hashes      bool   `protobuf:"varint,4,opt,name=hashes" json:"hashes,omitempty"`
+}
+
+func (m *SyncStateSnapshotChunk) Reset()                    { *m = SyncStateSnapshotChunk{} }
+func (m *SyncStateSnapshotChunk) String() string            { return proto.CompactTextString(m) }
+func (*SyncStateSnapshotChunk) ProtoMessage()               {}
+func (*SyncStateSnapshotChunk) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{25} }
 
 // SyncStateSnapshot is the payload of Message.SYNC_SNAPSHOT, which is a response
 // to penchainMessage.SYNC_GET_SNAPSHOT. It contains the snapshot or a chunk of the
 // snapshot on stream, and in which case, the sequence indicate the order
 // starting at 0.  The terminating message will have len(delta) == 0.
 type SyncStateSnapshot struct {
-	Delta       []byte                    `protobuf:"bytes,1,opt,name=delta,proto3" json:"delta,omitempty"`
-	Sequence    uint64                    `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"`
-	BlockNumber uint64                    `protobuf:"varint,3,opt,name=blockNumber" json:"blockNumber,omitempty"`
-	Request     *SyncStateSnapshotRequest `protobuf:"bytes,4,opt,name=request" json:"request,omitempty"`
+	Delta           []byte                    `protobuf:"bytes,1,opt,name=delta,proto3" json:"delta,omitempty"`
+	Sequence        uint64                    `protobuf:"varint,2,opt,name=sequence" json:"sequence,omitempty"`
+	BlockNumber     uint64                    `protobuf:"varint,3,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Request         *SyncStateSnapshotRequest `protobuf:"bytes,4,opt,name=request" json:"request,omitempty"`
+	PartitionHashes [][]byte                  `protobuf:"bytes,5,rep,name=partitionHashes,proto3" json:"partitionHashes,omitempty"`
 }
 
 func (m *SyncStateSnapshot) Reset()                    { *m = SyncStateSnapshot{} }
 func (m *SyncStateSnapshot) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateSnapshot) ProtoMessage()               {}
-func (*SyncStateSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{18} }
+func (*SyncStateSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{26} }
 
 func (m *SyncStateSnapshot) GetRequest() *SyncStateSnapshotRequest {
 	if m != nil {
@@ -556,7 +803,7 @@ type SyncStateDeltasRequest struct {
 func (m *SyncStateDeltasRequest) Reset()                    { *m = SyncStateDeltasRequest{} }
 func (m *SyncStateDeltasRequest) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateDeltasRequest) ProtoMessage()               {}
-func (*SyncStateDeltasRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{19} }
+func (*SyncStateDeltasRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{27} }
 
 func (m *SyncStateDeltasRequest) GetRange() *SyncBlockRange {
 	if m != nil {
@@ -575,7 +822,7 @@ type SyncStateDeltas struct {
 func (m *SyncStateDeltas) Reset()                    { *m = SyncStateDeltas{} }
 func (m *SyncStateDeltas) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateDeltas) ProtoMessage()               {}
-func (*SyncStateDeltas) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{20} }
+func (*SyncStateDeltas) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{28} }
 
 func (m *SyncStateDeltas) GetRange() *SyncBlockRange {
 	if m != nil {
@@ -597,19 +844,29 @@ func init() {
 	proto.RegisterType((*PeersMessage)(nil), "protos.PeersMessage")
 	proto.RegisterType((*PeersAddresses)(nil), "protos.PeersAddresses")
 	proto.RegisterType((*HelloMessage)(nil), "protos.HelloMessage")
+	proto.RegisterType((*AliveMessage)(nil), "protos.AliveMessage")
+	proto.RegisterType((*SignedAliveMessage)(nil), "protos.SignedAliveMessage")
+	proto.RegisterType((*GossipMembership)(nil), "protos.GossipMembership")
+	proto.RegisterType((*MembershipRecord)(nil), "protos.MembershipRecord")
+	proto.RegisterType((*MembershipSnapshot)(nil), "protos.MembershipSnapshot")
 	proto.RegisterType((*Message)(nil), "protos.Message")
 	proto.RegisterType((*Response)(nil), "protos.Response")
+	proto.RegisterType((*TransactionSubmission)(nil), "protos.TransactionSubmission")
+	proto.RegisterType((*TransactionSubmissionRequest)(nil), "protos.TransactionSubmissionRequest")
 	proto.RegisterType((*BlockState)(nil), "protos.BlockState")
 	proto.RegisterType((*SyncBlockRange)(nil), "protos.SyncBlockRange")
 	proto.RegisterType((*SyncBlocks)(nil), "protos.SyncBlocks")
 	proto.RegisterType((*SyncStateSnapshotRequest)(nil), "protos.SyncStateSnapshotRequest")
+	proto.RegisterType((*SyncStateSnapshotChunk)(nil), "protos.SyncStateSnapshotChunk")
 	proto.RegisterType((*SyncStateSnapshot)(nil), "protos.SyncStateSnapshot")
 	proto.RegisterType((*SyncStateDeltasRequest)(nil), "protos.SyncStateDeltasRequest")
 	proto.RegisterType((*SyncStateDeltas)(nil), "protos.SyncStateDeltas")
+	proto.RegisterEnum("protos.Compression", Compression_name, Compression_value)
 	proto.RegisterEnum("protos.Transaction_Type", Transaction_Type_name, Transaction_Type_value)
 	proto.RegisterEnum("protos.PeerEndpoint_Type", PeerEndpoint_Type_name, PeerEndpoint_Type_value)
 	proto.RegisterEnum("protos.Message_Type", Message_Type_name, Message_Type_value)
 	proto.RegisterEnum("protos.Response_StatusCode", Response_StatusCode_name, Response_StatusCode_value)
+	proto.RegisterEnum("protos.TransactionSubmission_State", TransactionSubmission_State_name, TransactionSubmission_State_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -628,6 +885,11 @@ type PeerClient interface {
 	Chat(ctx context.Context, opts ...grpc.CallOption) (Peer_ChatClient, error)
 	// Process a transaction from a remote source.
 	ProcessTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Response, error)
+	// Get the state of a transaction submitted to this peer.
+	GetTransactionSubmission(ctx context.Context, in *TransactionSubmissionRequest, opts ...grpc.CallOption) (*TransactionSubmission, error)
+	// Stream the state of a transaction submitted to this peer each time it
+	// changes, until the transaction commits or the submission fails.
+	WatchTransactionSubmission(ctx context.Context, in *TransactionSubmissionRequest, opts ...grpc.CallOption) (Peer_WatchTransactionSubmissionClient, error)
 }
 
 type peerClient struct {
@@ -678,6 +940,47 @@ func (c *peerClient) ProcessTransaction(ctx context.Context, in *Transaction, op
 	return out, nil
 }
 
+func (c *peerClient) GetTransactionSubmission(ctx context.Context, in *TransactionSubmissionRequest, opts ...grpc.CallOption) (*TransactionSubmission, error) {
+	out := new(TransactionSubmission)
+	err := grpc.Invoke(ctx, "/protos.Peer/GetTransactionSubmission", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) WatchTransactionSubmission(ctx context.Context, in *TransactionSubmissionRequest, opts ...grpc.CallOption) (Peer_WatchTransactionSubmissionClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Peer_serviceDesc.Streams[1], c.cc, "/protos.Peer/WatchTransactionSubmission", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &peerWatchTransactionSubmissionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Peer_WatchTransactionSubmissionClient interface {
+	Recv() (*TransactionSubmission, error)
+	grpc.ClientStream
+}
+
+type peerWatchTransactionSubmissionClient struct {
+	grpc.ClientStream
+}
+
+func (x *peerWatchTransactionSubmissionClient) Recv() (*TransactionSubmission, error) {
+	m := new(TransactionSubmission)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for Peer service
 
 type PeerServer interface {
@@ -686,6 +989,11 @@ type PeerServer interface {
 	Chat(Peer_ChatServer) error
 	// Process a transaction from a remote source.
 	ProcessTransaction(context.Context, *Transaction) (*Response, error)
+	// Get the state of a transaction submitted to this peer.
+	GetTransactionSubmission(context.Context, *TransactionSubmissionRequest) (*TransactionSubmission, error)
+	// Stream the state of a transaction submitted to this peer each time it
+	// changes, until the transaction commits or the submission fails.
+	WatchTransactionSubmission(*TransactionSubmissionRequest, Peer_WatchTransactionSubmissionServer) error
 }
 
 func RegisterPeerServer(s *grpc.Server, srv PeerServer) {
@@ -736,6 +1044,45 @@ func _Peer_ProcessTransaction_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Peer_GetTransactionSubmission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionSubmissionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).GetTransactionSubmission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Peer/GetTransactionSubmission",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).GetTransactionSubmission(ctx, req.(*TransactionSubmissionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Peer_WatchTransactionSubmission_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TransactionSubmissionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PeerServer).WatchTransactionSubmission(m, &peerWatchTransactionSubmissionServer{stream})
+}
+
+type Peer_WatchTransactionSubmissionServer interface {
+	Send(*TransactionSubmission) error
+	grpc.ServerStream
+}
+
+type peerWatchTransactionSubmissionServer struct {
+	grpc.ServerStream
+}
+
+func (x *peerWatchTransactionSubmissionServer) Send(m *TransactionSubmission) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Peer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Peer",
 	HandlerType: (*PeerServer)(nil),
@@ -744,6 +1091,10 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessTransaction",
 			Handler:    _Peer_ProcessTransaction_Handler,
 		},
+		{
+			MethodName: "GetTransactionSubmission",
+			Handler:    _Peer_GetTransactionSubmission_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -752,6 +1103,11 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchTransactionSubmission",
+			Handler:       _Peer_WatchTransactionSubmission_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: fileDescriptor5,
 }
@@ -759,97 +1115,130 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("fabric.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 1463 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x57, 0xcd, 0x6e, 0xdb, 0xc6,
-	0x13, 0x8f, 0x3e, 0x1d, 0x8d, 0x64, 0x99, 0xde, 0x38, 0x09, 0xe3, 0x04, 0xf9, 0x07, 0xfc, 0xb7,
-	0x40, 0x50, 0xb4, 0x4a, 0xe1, 0xa0, 0x48, 0x10, 0xa0, 0x45, 0x14, 0x89, 0x8e, 0x85, 0x28, 0x94,
-	0xb3, 0x94, 0x1d, 0xa4, 0x87, 0x1a, 0xb4, 0xb4, 0xb6, 0x89, 0x48, 0xa4, 0x4a, 0x52, 0x41, 0x7d,
-	0xed, 0xa9, 0xe8, 0x6b, 0xf4, 0xd8, 0x63, 0x9f, 0xa1, 0x1f, 0x8f, 0xd0, 0xb7, 0xe8, 0xa5, 0x0f,
-	0xd0, 0xd9, 0x59, 0x92, 0x22, 0x65, 0x25, 0x4d, 0x7a, 0xb1, 0x39, 0x5f, 0xbb, 0xf3, 0xf1, 0x9b,
-	0x99, 0x15, 0x34, 0x4e, 0x9c, 0xe3, 0xc0, 0x1d, 0xb5, 0x66, 0x81, 0x1f, 0xf9, 0xac, 0x4a, 0xff,
-	0xc2, 0xed, 0x8d, 0xd1, 0x99, 0xe3, 0x7a, 0x23, 0x7f, 0x2c, 0x94, 0x60, 0x7b, 0x2b, 0x65, 0x88,
-	0x37, 0xc2, 0x8b, 0x62, 0xee, 0xff, 0x4e, 0x7d, 0xff, 0x74, 0x22, 0xee, 0x11, 0x75, 0x3c, 0x3f,
-	0xb9, 0x17, 0xb9, 0x53, 0x11, 0x46, 0xce, 0x74, 0xa6, 0x14, 0x8c, 0x3f, 0xcb, 0x50, 0x1f, 0x06,
-	0x8e, 0x17, 0x3a, 0xa3, 0xc8, 0xf5, 0x3d, 0xf6, 0x29, 0x94, 0xa3, 0xf3, 0x99, 0xd0, 0x0b, 0x77,
-	0x0a, 0x77, 0x9b, 0x3b, 0xba, 0xd2, 0x0a, 0x5b, 0x19, 0x95, 0xd6, 0x10, 0xe5, 0x9c, 0xb4, 0xd8,
-	0x1d, 0xa8, 0xa7, 0xd7, 0xf6, 0xba, 0x7a, 0x11, 0x8d, 0x1a, 0x3c, 0xcb, 0x62, 0x3a, 0xac, 0xcd,
-	0x9c, 0xf3, 0x89, 0xef, 0x8c, 0xf5, 0x12, 0x49, 0x13, 0x92, 0x6d, 0xc3, 0xe5, 0xa9, 0x88, 0x9c,
-	0xb1, 0x13, 0x39, 0x7a, 0x99, 0x44, 0x29, 0xcd, 0x18, 0x7a, 0xf1, 0x9d, 0x3b, 0xd6, 0x2b, 0xc8,
-	0xaf, 0x71, 0xfa, 0x66, 0x0f, 0xa1, 0x96, 0x3a, 0xaf, 0x57, 0x51, 0x50, 0xdf, 0xd9, 0x6e, 0xa9,
-	0xf0, 0x5a, 0x49, 0x78, 0xad, 0x61, 0xa2, 0xc1, 0x17, 0xca, 0x6c, 0x1f, 0xb6, 0x46, 0xbe, 0x77,
-	0xe2, 0x8e, 0x31, 0x2f, 0xae, 0x33, 0x71, 0xa3, 0xf3, 0x3e, 0xe6, 0x68, 0xa2, 0xaf, 0x51, 0x8c,
-	0xb7, 0x92, 0x18, 0x3b, 0x2b, 0x74, 0xf8, 0x4a, 0x4b, 0xb6, 0x0b, 0xb7, 0x97, 0xf8, 0xfb, 0xf2,
-	0x8c, 0x91, 0x3f, 0x39, 0x14, 0x41, 0x88, 0x49, 0xd2, 0x2f, 0x93, 0xe7, 0xff, 0xa2, 0xc5, 0xb6,
-	0xa0, 0xe2, 0xf9, 0xde, 0x48, 0xe8, 0x35, 0x4a, 0x80, 0x22, 0x98, 0x01, 0x8d, 0xc8, 0x3f, 0x44,
-	0x03, 0xcc, 0x85, 0x1f, 0x84, 0x3a, 0x90, 0x30, 0xc7, 0x93, 0x19, 0x1a, 0x89, 0x20, 0xd2, 0xeb,
-	0x24, 0xa3, 0x6f, 0x76, 0x0b, 0x6a, 0xa1, 0x7b, 0xea, 0x39, 0xd1, 0x3c, 0x10, 0x7a, 0x83, 0x04,
-	0x0b, 0x86, 0xe1, 0x43, 0x59, 0x56, 0x8e, 0xad, 0x43, 0xed, 0xc0, 0xea, 0x9a, 0xbb, 0x3d, 0xcb,
-	0xec, 0x6a, 0x97, 0xd0, 0x05, 0xad, 0xb3, 0xd7, 0xee, 0x59, 0x9d, 0x41, 0xd7, 0x3c, 0xea, 0x9a,
-	0xfb, 0xfd, 0xc1, 0x2b, 0xad, 0x90, 0xe7, 0xf6, 0xac, 0xc3, 0xc1, 0x33, 0x53, 0x2b, 0xb2, 0x2b,
-	0xb0, 0xb1, 0xe0, 0xbe, 0x38, 0x30, 0xf9, 0x2b, 0xad, 0xc4, 0xae, 0xc3, 0x95, 0x05, 0x73, 0x68,
-	0xf2, 0xe7, 0x3d, 0xab, 0x3d, 0x34, 0xb5, 0xb2, 0xf1, 0x0c, 0xb4, 0x0c, 0x6c, 0x9e, 0x4c, 0xfc,
-	0xd1, 0x6b, 0xf6, 0x00, 0x43, 0x5b, 0xf0, 0x42, 0x84, 0x59, 0x09, 0xeb, 0x78, 0x65, 0x05, 0xcc,
-	0x78, 0x4e, 0xd1, 0xf8, 0xa5, 0x00, 0x9b, 0x59, 0xa9, 0x08, 0xe7, 0x93, 0x28, 0xc5, 0x49, 0x21,
-	0x83, 0x93, 0x6b, 0x50, 0x0d, 0x48, 0x1a, 0xc3, 0x31, 0xa6, 0x64, 0x76, 0x44, 0x10, 0xf8, 0x41,
-	0x07, 0x81, 0x49, 0x58, 0x5c, 0xe7, 0x0b, 0x86, 0xac, 0x04, 0x11, 0x04, 0xc5, 0x1a, 0x57, 0x04,
-	0xfb, 0x0a, 0x9a, 0x29, 0x98, 0x4d, 0xd9, 0x56, 0x84, 0xc8, 0xfa, 0xce, 0xb5, 0x14, 0x33, 0x39,
-	0x29, 0x5f, 0xd2, 0x36, 0x7e, 0x2d, 0x42, 0x45, 0x05, 0x8e, 0x7d, 0xf0, 0x26, 0x86, 0x46, 0x81,
-	0xee, 0x4e, 0xc8, 0x3c, 0xae, 0x8b, 0x1f, 0x82, 0xeb, 0xe5, 0x64, 0x96, 0xde, 0x33, 0x99, 0x04,
-	0x94, 0xc8, 0x89, 0xc4, 0x9e, 0x13, 0x9e, 0xc5, 0xbd, 0xb7, 0x60, 0xe0, 0x08, 0xd8, 0x9c, 0x05,
-	0xe2, 0x8d, 0xeb, 0xcf, 0x43, 0xf2, 0x9d, 0xb4, 0x2a, 0xa4, 0x75, 0x51, 0x20, 0xb5, 0x11, 0xe4,
-	0xa1, 0xf0, 0xc2, 0x79, 0xf8, 0x3c, 0xe9, 0xe7, 0xaa, 0xd2, 0xbe, 0x20, 0x60, 0x5f, 0x40, 0x1d,
-	0x31, 0x2e, 0x0d, 0xbb, 0x52, 0x6f, 0x8d, 0xc2, 0x4d, 0x3d, 0xb6, 0x16, 0x22, 0x9e, 0xd5, 0x33,
-	0xbe, 0x2f, 0x40, 0x93, 0xae, 0xa4, 0xfc, 0xf6, 0xbc, 0x13, 0x5f, 0x96, 0xf9, 0x4c, 0xb8, 0xa7,
-	0x67, 0x11, 0xe5, 0xb3, 0xcc, 0x63, 0x8a, 0x7d, 0x02, 0xda, 0x68, 0x1e, 0x04, 0x98, 0xfd, 0x85,
-	0xf3, 0x0a, 0x08, 0x17, 0xf8, 0xab, 0x23, 0x2d, 0xbd, 0x25, 0x52, 0xe3, 0xe7, 0x02, 0xd4, 0x33,
-	0x1e, 0xb2, 0xaf, 0x61, 0x1b, 0x65, 0xce, 0xa4, 0x2f, 0xc6, 0xa7, 0x02, 0x51, 0x34, 0x9d, 0xba,
-	0x51, 0x5a, 0x27, 0xf2, 0xea, 0xdd, 0x95, 0x7c, 0x87, 0x35, 0x7b, 0x0c, 0x1b, 0x79, 0x28, 0x85,
-	0x18, 0x44, 0xe9, 0x1d, 0xc8, 0x5b, 0x56, 0x37, 0x30, 0xd3, 0xfb, 0x42, 0x04, 0xed, 0xf1, 0x18,
-	0xf1, 0x4f, 0xf3, 0xe2, 0xcc, 0x0f, 0xa3, 0xa4, 0x53, 0xe4, 0xb7, 0xe4, 0xcd, 0xfc, 0x40, 0xf5,
-	0x49, 0x85, 0xd3, 0xb7, 0x71, 0x0b, 0xaa, 0xd2, 0x0c, 0x27, 0x37, 0x4a, 0x3d, 0x67, 0x2a, 0x12,
-	0x0b, 0xf9, 0x6d, 0xfc, 0x56, 0x80, 0x86, 0x14, 0x9b, 0xde, 0x78, 0xe6, 0xbb, 0x5e, 0xc4, 0x6e,
-	0x43, 0x11, 0xe7, 0xbe, 0x8a, 0xb5, 0x99, 0xb8, 0xa6, 0x0e, 0xe0, 0x45, 0x97, 0xc6, 0xbf, 0xa3,
-	0x3c, 0xa0, 0x5b, 0x6a, 0x3c, 0x21, 0xd9, 0x67, 0xf1, 0xa2, 0x29, 0xd1, 0x10, 0xbe, 0x91, 0xb5,
-	0x4d, 0x4e, 0xcf, 0x6e, 0x1a, 0xec, 0xcf, 0xd9, 0x6b, 0x17, 0xef, 0x52, 0x70, 0x55, 0x84, 0xf1,
-	0x60, 0xf5, 0x4c, 0x43, 0xf2, 0xb0, 0xdd, 0xef, 0x75, 0xdb, 0xc3, 0x01, 0xc7, 0x61, 0xb6, 0x09,
-	0xeb, 0xd6, 0xc0, 0x3a, 0x5a, 0xb0, 0x8a, 0xc6, 0x23, 0x15, 0x07, 0x02, 0x33, 0x0c, 0x9d, 0x53,
-	0x81, 0xa8, 0xa9, 0xcc, 0x24, 0x1d, 0x0f, 0xa4, 0xad, 0x55, 0xee, 0x70, 0xa5, 0x62, 0xb4, 0xa0,
-	0x49, 0xb6, 0x71, 0x6a, 0x05, 0xf5, 0x93, 0x93, 0x10, 0x74, 0x42, 0x8d, 0x2f, 0x18, 0xc6, 0x0f,
-	0x98, 0xb4, 0x3d, 0x31, 0x99, 0xf8, 0xc9, 0x65, 0x0f, 0xa1, 0x31, 0xcb, 0x9c, 0x1b, 0xa7, 0x6f,
-	0xf5, 0x9d, 0x39, 0x4d, 0x39, 0x8f, 0x8e, 0x73, 0x6d, 0x10, 0x0f, 0x8c, 0x14, 0x15, 0xf9, 0x26,
-	0xe1, 0x4b, 0xda, 0xc6, 0x5f, 0x25, 0x58, 0x4b, 0xbc, 0xb8, 0x9b, 0xdb, 0xf4, 0xe9, 0xed, 0xb1,
-	0x38, 0x9b, 0xfb, 0xff, 0x3e, 0xa1, 0xde, 0xbe, 0xfd, 0x73, 0xbb, 0xaa, 0xbc, 0xbc, 0xab, 0x7e,
-	0x2f, 0xae, 0x2e, 0x6c, 0x13, 0xa0, 0xdb, 0xb3, 0x3b, 0x47, 0x7b, 0x66, 0xbf, 0x3f, 0xc0, 0xca,
-	0xe2, 0x42, 0x22, 0x5a, 0xfe, 0x19, 0x58, 0x96, 0xd9, 0x19, 0xe2, 0x96, 0x62, 0xd0, 0x24, 0xe6,
-	0x53, 0x73, 0x78, 0xb4, 0x6f, 0x9a, 0xdc, 0xc6, 0x25, 0x95, 0x18, 0x2a, 0xba, 0xcc, 0x36, 0xa0,
-	0x4e, 0xb4, 0x65, 0xbe, 0x7c, 0x6e, 0x3f, 0xd5, 0x2a, 0xec, 0x2a, 0x6c, 0xd2, 0x16, 0x3b, 0x1a,
-	0xf2, 0xb6, 0x65, 0xb7, 0x3b, 0xc3, 0xde, 0xc0, 0xd2, 0xaa, 0xf2, 0x02, 0xfb, 0x95, 0xa5, 0xce,
-	0x7a, 0xd2, 0x1f, 0x74, 0x9e, 0xd9, 0x5a, 0x5d, 0x1a, 0x13, 0x33, 0x66, 0x34, 0xe4, 0xb6, 0x5c,
-	0x30, 0x8e, 0xda, 0xdd, 0x2e, 0x3a, 0xbb, 0xce, 0x6e, 0xc2, 0x75, 0xe2, 0xda, 0x43, 0xdc, 0x87,
-	0x74, 0x82, 0x6d, 0xb5, 0xf7, 0xed, 0xbd, 0xc1, 0x50, 0x6b, 0xca, 0xad, 0x99, 0x11, 0xa6, 0x82,
-	0x0d, 0x76, 0x03, 0xae, 0x2e, 0x59, 0x75, 0xcd, 0xfe, 0xb0, 0x6d, 0x6b, 0x9a, 0xf4, 0x31, 0x23,
-	0x8a, 0xd9, 0x9b, 0xac, 0x01, 0x97, 0xb9, 0x69, 0xef, 0x0f, 0x2c, 0xdb, 0xd4, 0xb6, 0x64, 0xc6,
-	0x3a, 0xf2, 0xd3, 0xb2, 0x0f, 0x6c, 0xed, 0xaa, 0xf1, 0x63, 0x01, 0xa5, 0x22, 0x9c, 0xc9, 0x49,
-	0xcc, 0xee, 0x43, 0x55, 0x8e, 0xf9, 0x79, 0x18, 0x17, 0xfd, 0x66, 0x52, 0xf4, 0x44, 0xa3, 0x65,
-	0x93, 0x58, 0x6e, 0x44, 0x1e, 0xab, 0x32, 0x0d, 0x4a, 0xd3, 0xf0, 0x34, 0x9e, 0xa1, 0xf2, 0x13,
-	0xbb, 0x0e, 0x16, 0x7a, 0xcb, 0x25, 0x6a, 0xc0, 0x9a, 0x7d, 0xd0, 0xe9, 0x98, 0xb6, 0xad, 0xfd,
-	0x51, 0x90, 0xd4, 0x6e, 0xbb, 0xd7, 0x3f, 0xe0, 0xa6, 0xf6, 0x77, 0xc9, 0x78, 0x01, 0x40, 0x00,
-	0x95, 0xd6, 0x82, 0xfd, 0x1f, 0x2a, 0x04, 0xcf, 0x18, 0xff, 0xeb, 0x39, 0x0c, 0x73, 0x25, 0xc3,
-	0x01, 0x03, 0xb4, 0x99, 0xba, 0x62, 0x82, 0xfb, 0x42, 0x39, 0x91, 0xe1, 0x18, 0xdf, 0x40, 0xd3,
-	0x3e, 0xf7, 0x46, 0xca, 0xc6, 0xf1, 0x10, 0xd7, 0x1f, 0xc1, 0xfa, 0xc8, 0xc7, 0x41, 0x3f, 0x71,
-	0xe4, 0xb2, 0xeb, 0x8d, 0xe3, 0xfd, 0x90, 0x67, 0xca, 0x79, 0x82, 0xa7, 0xc4, 0xc3, 0xaf, 0xcc,
-	0x15, 0x21, 0x63, 0x15, 0x9e, 0xc2, 0x6a, 0x99, 0xcb, 0x4f, 0xc3, 0xc1, 0x58, 0x93, 0xf3, 0x43,
-	0x5c, 0x18, 0x95, 0x40, 0x5e, 0x12, 0xbb, 0x9c, 0xb6, 0x5d, 0xde, 0x05, 0xae, 0x94, 0xd8, 0xc7,
-	0x50, 0xa5, 0x20, 0x92, 0xd9, 0xbd, 0x14, 0x61, 0x2c, 0x34, 0x1e, 0x83, 0x2e, 0xed, 0x29, 0x29,
-	0xb6, 0xe7, 0xcc, 0xc2, 0x33, 0x3f, 0xe2, 0xe2, 0xdb, 0x39, 0xf6, 0xd0, 0xfb, 0x05, 0x63, 0xfc,
-	0x84, 0x8f, 0xa3, 0x0b, 0x47, 0xc8, 0x10, 0xc7, 0x94, 0xb5, 0x82, 0x1a, 0x99, 0x44, 0xc8, 0x67,
-	0x77, 0x28, 0x0f, 0x97, 0xaf, 0x4e, 0x15, 0x7b, 0x4a, 0xcb, 0xe7, 0x3c, 0xf9, 0x64, 0xcd, 0xa7,
-	0xc7, 0x22, 0x88, 0xd3, 0x90, 0x65, 0xb1, 0x47, 0xb0, 0x16, 0x28, 0xd7, 0xa8, 0x69, 0xeb, 0x3b,
-	0x77, 0xb2, 0x29, 0x58, 0x15, 0x02, 0x4f, 0x0c, 0x8c, 0x5d, 0xb8, 0x96, 0x2a, 0x51, 0xf1, 0xc2,
-	0x24, 0xca, 0x0f, 0x4a, 0xab, 0xf1, 0x12, 0x7b, 0x32, 0x7f, 0xce, 0x07, 0xd6, 0x05, 0x9f, 0x0e,
-	0x94, 0x0b, 0x55, 0x17, 0x7c, 0x21, 0x2a, 0x6a, 0x67, 0x0e, 0x65, 0x39, 0x7b, 0x59, 0x0b, 0xca,
-	0xb8, 0x5d, 0x23, 0xb6, 0xb1, 0x34, 0x13, 0xb7, 0x97, 0x19, 0xc6, 0xa5, 0xbb, 0x85, 0xcf, 0x0b,
-	0xec, 0x4b, 0x60, 0xf8, 0xb0, 0x1f, 0x21, 0x2f, 0xfb, 0x4b, 0x6a, 0xd5, 0x3b, 0x6c, 0x5b, 0x5b,
-	0xee, 0x38, 0xe3, 0xd2, 0xb1, 0xfa, 0x49, 0x77, 0xff, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc8,
-	0xc4, 0xd0, 0x63, 0xe9, 0x0d, 0x00, 0x00,
+	// 1985 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0xcd, 0x6f, 0x23, 0x49,
+	0x15, 0x9f, 0xf6, 0x67, 0xfc, 0xfc, 0x91, 0x4e, 0x4d, 0x66, 0xb6, 0xd7, 0x1b, 0x06, 0xd3, 0xbb,
+	0x48, 0xd1, 0x68, 0xc8, 0xae, 0xb2, 0xbb, 0xcc, 0xb0, 0x48, 0x08, 0xaf, 0xbb, 0x93, 0x58, 0xe3,
+	0xb4, 0xbd, 0xd5, 0xce, 0x44, 0x33, 0x07, 0xa2, 0x4a, 0xbb, 0x26, 0x6e, 0x8d, 0xdd, 0xdd, 0x74,
+	0x95, 0x23, 0x72, 0xe5, 0x84, 0x38, 0xf1, 0x47, 0x70, 0xe3, 0xc8, 0x81, 0x1b, 0x12, 0x07, 0x10,
+	0x17, 0x0e, 0xdc, 0xf8, 0x3b, 0x10, 0x7f, 0x00, 0xaa, 0xea, 0x6f, 0xc7, 0x93, 0xd9, 0x61, 0xc5,
+	0x25, 0xa9, 0xf7, 0x51, 0x55, 0xef, 0xe3, 0x57, 0xef, 0xbd, 0x36, 0xb4, 0x5e, 0x93, 0xcb, 0xd0,
+	0x75, 0x0e, 0x82, 0xd0, 0xe7, 0x3e, 0xaa, 0xc9, 0x7f, 0xac, 0xbb, 0xed, 0xcc, 0x89, 0xeb, 0x39,
+	0xfe, 0x8c, 0x46, 0x82, 0xee, 0x6e, 0xca, 0xa0, 0xd7, 0xd4, 0xe3, 0x31, 0xf7, 0xfb, 0x57, 0xbe,
+	0x7f, 0xb5, 0xa0, 0x9f, 0x4a, 0xea, 0x72, 0xf5, 0xfa, 0x53, 0xee, 0x2e, 0x29, 0xe3, 0x64, 0x19,
+	0x44, 0x0a, 0xfa, 0xbf, 0x2b, 0xd0, 0x9c, 0x86, 0xc4, 0x63, 0xc4, 0xe1, 0xae, 0xef, 0xa1, 0x27,
+	0x50, 0xe1, 0x37, 0x01, 0xd5, 0x94, 0x9e, 0xb2, 0xdf, 0x39, 0xd4, 0x22, 0x2d, 0x76, 0x90, 0x53,
+	0x39, 0x98, 0xde, 0x04, 0x14, 0x4b, 0x2d, 0xd4, 0x83, 0x66, 0x7a, 0xed, 0xd0, 0xd0, 0x4a, 0x3d,
+	0x65, 0xbf, 0x85, 0xf3, 0x2c, 0xa4, 0x41, 0x3d, 0x20, 0x37, 0x0b, 0x9f, 0xcc, 0xb4, 0xb2, 0x94,
+	0x26, 0x24, 0xea, 0xc2, 0xd6, 0x92, 0x72, 0x32, 0x23, 0x9c, 0x68, 0x15, 0x29, 0x4a, 0x69, 0x84,
+	0xa0, 0xc2, 0x7f, 0xe5, 0xce, 0xb4, 0x6a, 0x4f, 0xd9, 0x6f, 0x60, 0xb9, 0x46, 0xcf, 0xa0, 0x91,
+	0x1a, 0xaf, 0xd5, 0x7a, 0xca, 0x7e, 0xf3, 0xb0, 0x7b, 0x10, 0xb9, 0x77, 0x90, 0xb8, 0x77, 0x30,
+	0x4d, 0x34, 0x70, 0xa6, 0x8c, 0x26, 0xb0, 0xeb, 0xf8, 0xde, 0x6b, 0x77, 0x46, 0x3d, 0xee, 0x92,
+	0x85, 0xcb, 0x6f, 0x46, 0xf4, 0x9a, 0x2e, 0xb4, 0xba, 0xf4, 0x71, 0x2f, 0xf1, 0x71, 0xb0, 0x41,
+	0x07, 0x6f, 0xdc, 0x89, 0x8e, 0xe0, 0xd1, 0x1a, 0x7f, 0x22, 0xce, 0x70, 0xfc, 0xc5, 0x0b, 0x1a,
+	0x32, 0xd7, 0xf7, 0xb4, 0x2d, 0x69, 0xf9, 0x3b, 0xb4, 0xd0, 0x2e, 0x54, 0x3d, 0xdf, 0x73, 0xa8,
+	0xd6, 0x90, 0x01, 0x88, 0x08, 0xa4, 0x43, 0x8b, 0xfb, 0x2f, 0xc8, 0xc2, 0x9d, 0x11, 0xee, 0x87,
+	0x4c, 0x03, 0x29, 0x2c, 0xf0, 0x44, 0x84, 0x1c, 0x1a, 0x72, 0xad, 0x29, 0x65, 0x72, 0x8d, 0xf6,
+	0xa0, 0xc1, 0xdc, 0x2b, 0x8f, 0xf0, 0x55, 0x48, 0xb5, 0x96, 0x14, 0x64, 0x0c, 0x91, 0x09, 0x99,
+	0x98, 0xa1, 0xa1, 0xb5, 0xa5, 0x71, 0x09, 0xa9, 0xfb, 0x50, 0x11, 0x39, 0x45, 0x6d, 0x68, 0x9c,
+	0x59, 0x86, 0x79, 0x34, 0xb4, 0x4c, 0x43, 0xbd, 0x87, 0x76, 0x41, 0x1d, 0x9c, 0xf4, 0x87, 0xd6,
+	0x60, 0x6c, 0x98, 0x17, 0x86, 0x39, 0x19, 0x8d, 0x5f, 0xaa, 0x4a, 0x91, 0x3b, 0xb4, 0x5e, 0x8c,
+	0x9f, 0x9b, 0x6a, 0x09, 0xdd, 0x87, 0xed, 0x8c, 0xfb, 0xcd, 0x99, 0x89, 0x5f, 0xaa, 0x65, 0xf4,
+	0x01, 0xdc, 0xcf, 0x98, 0x53, 0x13, 0x9f, 0x0e, 0xad, 0xfe, 0xd4, 0x54, 0x2b, 0xfa, 0x73, 0x50,
+	0x73, 0x80, 0xfa, 0x7a, 0xe1, 0x3b, 0x6f, 0xd0, 0x53, 0x68, 0xf1, 0x8c, 0xc7, 0x34, 0xa5, 0x57,
+	0xde, 0x6f, 0x1e, 0xde, 0xdf, 0x00, 0x40, 0x5c, 0x50, 0xd4, 0xff, 0xa8, 0xc0, 0x4e, 0x5e, 0x4a,
+	0xd9, 0x6a, 0xc1, 0x53, 0x04, 0x29, 0x39, 0x04, 0x3d, 0x84, 0x5a, 0x28, 0xa5, 0x31, 0x50, 0x63,
+	0x4a, 0xc4, 0x8d, 0x86, 0xa1, 0x1f, 0x0e, 0xfc, 0x19, 0x95, 0x28, 0x6d, 0xe3, 0x8c, 0x21, 0x72,
+	0x24, 0x09, 0x09, 0xd2, 0x06, 0x8e, 0x08, 0xf4, 0x33, 0xe8, 0xa4, 0x30, 0x37, 0xc5, 0x83, 0x93,
+	0x58, 0x6d, 0x1e, 0x3e, 0x4c, 0xd1, 0x54, 0x90, 0xe2, 0x35, 0x6d, 0xfd, 0xaf, 0x25, 0xa8, 0x46,
+	0x8e, 0x6b, 0x50, 0xbf, 0x8e, 0x41, 0xa3, 0xc8, 0xbb, 0x13, 0xb2, 0x88, 0xf8, 0xd2, 0xfb, 0x20,
+	0x7e, 0x3d, 0x98, 0xe5, 0x6f, 0x19, 0x4c, 0x09, 0x21, 0x4e, 0x38, 0x3d, 0x21, 0x6c, 0x1e, 0xbf,
+	0xca, 0x8c, 0x81, 0x9e, 0xc0, 0x4e, 0x10, 0xd2, 0x6b, 0xd7, 0x5f, 0x31, 0x69, 0xbb, 0xd4, 0xaa,
+	0x4a, 0xad, 0xdb, 0x02, 0xa1, 0xed, 0xf8, 0x1e, 0xa3, 0x1e, 0x5b, 0xb1, 0xd3, 0xe4, 0xa5, 0xd7,
+	0x22, 0xed, 0x5b, 0x02, 0xf4, 0x25, 0x34, 0x3d, 0xdf, 0x13, 0x1b, 0x0d, 0xa1, 0x57, 0xef, 0x29,
+	0x79, 0x8b, 0xad, 0x4c, 0x84, 0xf3, 0x7a, 0xfa, 0xaf, 0x15, 0xe8, 0xc8, 0x2b, 0x23, 0x30, 0x7b,
+	0xaf, 0x7d, 0x91, 0xe6, 0x39, 0x75, 0xaf, 0xe6, 0x5c, 0xc6, 0xb3, 0x82, 0x63, 0x0a, 0x3d, 0x06,
+	0xd5, 0x59, 0x85, 0x21, 0xf5, 0x78, 0x66, 0x7c, 0x04, 0x84, 0x5b, 0xfc, 0xcd, 0x9e, 0x96, 0xdf,
+	0xe2, 0xa9, 0xfe, 0x07, 0x05, 0x9a, 0x39, 0x0b, 0xd1, 0x2b, 0xe8, 0x2e, 0x7c, 0x87, 0x2c, 0x46,
+	0x74, 0x76, 0x45, 0xc3, 0x81, 0xbf, 0x5c, 0xba, 0x3c, 0xcd, 0x93, 0xa6, 0xbc, 0x33, 0x93, 0x77,
+	0xec, 0x46, 0x3f, 0x87, 0xed, 0x22, 0x94, 0x98, 0x56, 0xea, 0x95, 0xef, 0x40, 0xde, 0xba, 0xba,
+	0xfe, 0x25, 0x34, 0x27, 0x94, 0x86, 0xfd, 0xd9, 0x2c, 0xa4, 0x4c, 0x56, 0x92, 0xb9, 0xcf, 0x78,
+	0xf2, 0x52, 0xc4, 0x5a, 0xf0, 0x02, 0x3f, 0x8c, 0xde, 0x49, 0x15, 0xcb, 0xb5, 0xbe, 0x07, 0x35,
+	0xb1, 0x6d, 0x68, 0x08, 0xa9, 0x47, 0x96, 0x34, 0xd9, 0x21, 0xd6, 0xfa, 0xdf, 0x14, 0x68, 0x09,
+	0xb1, 0xe9, 0xcd, 0x02, 0xdf, 0xf5, 0x38, 0x7a, 0x04, 0xa5, 0xa1, 0x11, 0xfb, 0xda, 0x49, 0x4c,
+	0x8b, 0x0e, 0xc0, 0x25, 0x57, 0x36, 0x06, 0x12, 0x59, 0x20, 0x6f, 0x69, 0xe0, 0x84, 0x44, 0x3f,
+	0x8a, 0x5b, 0x50, 0x59, 0x96, 0xe7, 0x0f, 0xf3, 0x7b, 0x93, 0xd3, 0xf3, 0x3d, 0x68, 0x17, 0xaa,
+	0xc1, 0x1b, 0x77, 0x68, 0xc4, 0x70, 0x8d, 0x08, 0xfd, 0xe9, 0xe6, 0x9a, 0xd6, 0x86, 0xc6, 0x8b,
+	0xfe, 0x68, 0x68, 0xf4, 0xa7, 0x63, 0xac, 0x2a, 0x68, 0x07, 0xda, 0xd6, 0xd8, 0xba, 0xc8, 0x58,
+	0x25, 0xfd, 0xab, 0xc8, 0x0f, 0x76, 0x4a, 0x19, 0x23, 0x57, 0x14, 0x3d, 0x86, 0x6a, 0x20, 0xe8,
+	0xb8, 0x20, 0xed, 0x6e, 0x32, 0x07, 0x47, 0x2a, 0xfa, 0x01, 0x74, 0xe4, 0xde, 0x38, 0xb4, 0x54,
+	0xbe, 0x27, 0x92, 0x10, 0xf2, 0x84, 0x06, 0xce, 0x18, 0xfa, 0x5f, 0x14, 0x68, 0x9d, 0xd0, 0xc5,
+	0xc2, 0x4f, 0x2e, 0x7b, 0x06, 0xad, 0x20, 0x77, 0x6e, 0x1c, 0xbe, 0xcd, 0x77, 0x16, 0x34, 0x45,
+	0x3d, 0xba, 0x2c, 0x3c, 0x83, 0xb8, 0x60, 0xa4, 0xa8, 0x28, 0x3e, 0x12, 0xbc, 0xa6, 0x2d, 0x2a,
+	0x86, 0xe3, 0x2f, 0x03, 0x61, 0x58, 0x5a, 0x31, 0x3a, 0xd9, 0xfb, 0x1b, 0x64, 0x32, 0x5c, 0x50,
+	0xd4, 0x7f, 0xa3, 0x40, 0xab, 0xbf, 0x70, 0xaf, 0xe9, 0x77, 0xf7, 0xa1, 0x07, 0x4d, 0xd7, 0x73,
+	0x48, 0xe8, 0x11, 0x51, 0x8c, 0xa4, 0x03, 0x15, 0x9c, 0x67, 0x89, 0x70, 0xce, 0x29, 0x09, 0xf9,
+	0x25, 0x25, 0x5c, 0xe2, 0xa3, 0x82, 0x33, 0x86, 0x7e, 0x02, 0xc8, 0x76, 0xaf, 0x3c, 0x3a, 0x2b,
+	0xd8, 0xb3, 0x0b, 0x55, 0x22, 0x68, 0x69, 0x48, 0x0b, 0x47, 0x44, 0xb1, 0x57, 0x96, 0xd6, 0x7a,
+	0xa5, 0x7e, 0x02, 0xea, 0xb1, 0xcf, 0x98, 0x1b, 0x9c, 0xd2, 0xe5, 0x25, 0x0d, 0xd9, 0xdc, 0x0d,
+	0xd0, 0x17, 0x50, 0x5f, 0x46, 0x54, 0x0c, 0x85, 0x6e, 0xe2, 0xd2, 0xed, 0x4b, 0x71, 0xa2, 0xaa,
+	0xff, 0x5e, 0x01, 0x35, 0x3b, 0x04, 0x53, 0xc7, 0x0f, 0x67, 0xdf, 0x21, 0x44, 0x8f, 0x00, 0x42,
+	0x1a, 0xac, 0x78, 0x16, 0xa1, 0x2a, 0xce, 0x71, 0xd0, 0x8f, 0x61, 0x6b, 0x41, 0x18, 0xb7, 0x29,
+	0xf5, 0x64, 0x7c, 0xee, 0xae, 0x33, 0xa9, 0xae, 0x08, 0x5d, 0x66, 0xa5, 0xed, 0x91, 0x80, 0xcd,
+	0x7d, 0x8e, 0x0e, 0xd7, 0x5d, 0x4e, 0xe7, 0xc1, 0x75, 0x97, 0x32, 0x87, 0xff, 0x59, 0x81, 0x7a,
+	0x12, 0xfa, 0xfd, 0xc2, 0x30, 0xb9, 0x9b, 0x6d, 0x96, 0xe2, 0xfc, 0x23, 0xfe, 0xdf, 0x5b, 0xdd,
+	0xdb, 0x07, 0xcc, 0x42, 0x8a, 0x2b, 0x77, 0x8c, 0x43, 0xd5, 0xc2, 0x38, 0x24, 0x3a, 0x51, 0x0e,
+	0xe1, 0xb2, 0x63, 0xbd, 0xe5, 0x25, 0xe4, 0xf5, 0xf4, 0x7f, 0x95, 0x36, 0x97, 0x9c, 0x0e, 0x80,
+	0x31, 0xb4, 0x07, 0x17, 0x27, 0xe6, 0x68, 0x34, 0x56, 0x15, 0x31, 0x2a, 0x49, 0x5a, 0xfc, 0x19,
+	0x5b, 0x96, 0x39, 0x98, 0xaa, 0x25, 0x84, 0xa0, 0x23, 0x99, 0xc7, 0xe6, 0xf4, 0x62, 0x62, 0x9a,
+	0xd8, 0x56, 0xcb, 0xe9, 0xc6, 0x88, 0xae, 0xa0, 0x6d, 0x68, 0x4a, 0xda, 0x32, 0xcf, 0x4f, 0xed,
+	0x63, 0xb5, 0x9a, 0x32, 0x8e, 0xc7, 0xb6, 0x3d, 0x9c, 0xa8, 0x75, 0xf4, 0x00, 0x76, 0xe4, 0xc0,
+	0x75, 0x31, 0xc5, 0x7d, 0xcb, 0xee, 0x0f, 0xa6, 0xc3, 0xb1, 0xa5, 0xd6, 0xc4, 0x8d, 0xf6, 0x4b,
+	0x2b, 0x3a, 0xfc, 0xeb, 0xd1, 0x78, 0xf0, 0xdc, 0x56, 0x9b, 0x62, 0xb3, 0x64, 0xc6, 0x8c, 0x96,
+	0x18, 0xec, 0x32, 0xc6, 0x45, 0xdf, 0x30, 0x4c, 0x43, 0x6d, 0xa3, 0x8f, 0xe0, 0x03, 0xc9, 0xb5,
+	0xa7, 0xfd, 0xa9, 0x29, 0x4f, 0xb0, 0xad, 0xfe, 0xc4, 0x3e, 0x19, 0x4f, 0xd5, 0x8e, 0x18, 0xf0,
+	0x72, 0xc2, 0x54, 0xb0, 0x8d, 0x3e, 0x84, 0x07, 0x6b, 0xbb, 0x0c, 0x73, 0x34, 0xed, 0xdb, 0xaa,
+	0x2a, 0x6c, 0xcc, 0x89, 0x62, 0xf6, 0x0e, 0x6a, 0xc1, 0x16, 0x36, 0xed, 0xc9, 0xd8, 0xb2, 0x4d,
+	0x75, 0x57, 0x84, 0x70, 0x20, 0x96, 0x96, 0x7d, 0x66, 0xab, 0x0f, 0xf4, 0xdf, 0x2a, 0xb0, 0x85,
+	0x29, 0x0b, 0x7c, 0x8f, 0x51, 0xf4, 0x39, 0xd4, 0x18, 0x27, 0x7c, 0xc5, 0x62, 0x58, 0x7d, 0x94,
+	0x64, 0x26, 0xd1, 0x38, 0xb0, 0xa5, 0x58, 0x0c, 0x6f, 0x38, 0x56, 0x45, 0x2a, 0x94, 0x97, 0xec,
+	0x2a, 0x7e, 0xe8, 0x62, 0xa9, 0x3f, 0x05, 0xc8, 0xf4, 0xd6, 0x73, 0xd6, 0x82, 0xba, 0x7d, 0x36,
+	0x18, 0x98, 0xb6, 0xad, 0xfe, 0x5d, 0x11, 0xd4, 0x51, 0x7f, 0x38, 0x3a, 0xc3, 0xa6, 0xfa, 0x9f,
+	0xb2, 0xfe, 0xe7, 0x32, 0x3c, 0xc8, 0x0d, 0x50, 0xf6, 0xea, 0x72, 0xe9, 0x4a, 0x04, 0x6c, 0x9c,
+	0x39, 0x73, 0x30, 0x2b, 0x15, 0x61, 0xf6, 0x13, 0xa8, 0x0a, 0xe3, 0x92, 0x3e, 0xf7, 0xf1, 0x86,
+	0xe1, 0x2c, 0x3b, 0x5b, 0xfa, 0x44, 0x71, 0xb4, 0x43, 0x20, 0xfb, 0x3a, 0xf9, 0x14, 0x88, 0xc7,
+	0xd2, 0x8c, 0x21, 0x3e, 0xac, 0x08, 0xe7, 0x74, 0x19, 0x70, 0x26, 0xa1, 0xdd, 0xc6, 0x29, 0x2d,
+	0x4a, 0xac, 0x2c, 0xfc, 0xd6, 0x4a, 0xbc, 0x56, 0x89, 0xed, 0x0a, 0xce, 0xb3, 0xb2, 0x71, 0xb7,
+	0x9e, 0x1f, 0x77, 0x9f, 0x41, 0x83, 0x09, 0x63, 0x38, 0xa7, 0x33, 0x6d, 0xeb, 0xdd, 0xef, 0x33,
+	0x55, 0x16, 0x65, 0x73, 0x15, 0xcc, 0x88, 0xd8, 0xd7, 0x78, 0xe7, 0xbe, 0x44, 0x55, 0x7f, 0x05,
+	0x55, 0xe9, 0x31, 0x6a, 0x42, 0xfd, 0xcc, 0x7a, 0x6e, 0x8d, 0xcf, 0x2d, 0xf5, 0x9e, 0x20, 0x26,
+	0xa6, 0x65, 0x0c, 0xad, 0x63, 0x55, 0x11, 0x29, 0x3b, 0x1a, 0xe3, 0xf3, 0x3e, 0x16, 0x40, 0x2d,
+	0x45, 0x90, 0x39, 0x3d, 0x1d, 0x4e, 0xa7, 0xa6, 0xa1, 0x96, 0x11, 0x40, 0x4d, 0xe4, 0xcc, 0x34,
+	0xd4, 0x8a, 0x10, 0x4d, 0x87, 0xa7, 0xa6, 0x71, 0x31, 0x3e, 0x9b, 0xaa, 0x55, 0x7d, 0x04, 0x7b,
+	0x1b, 0x63, 0x8c, 0xe9, 0x2f, 0x57, 0x94, 0xf1, 0xf7, 0x4b, 0xa3, 0xfe, 0x0d, 0x80, 0x6c, 0xad,
+	0x91, 0xb9, 0x1f, 0x43, 0x55, 0x06, 0x33, 0x2e, 0xe9, 0xed, 0x42, 0xf7, 0xc5, 0x91, 0x4c, 0x14,
+	0x71, 0x99, 0x47, 0x83, 0x2e, 0x38, 0x89, 0x31, 0x99, 0xe3, 0xe8, 0xbf, 0x80, 0x8e, 0x7d, 0xe3,
+	0x39, 0xd1, 0x1e, 0xe2, 0x5d, 0x51, 0xf4, 0x09, 0xb4, 0x1d, 0x3f, 0x0c, 0xe9, 0x42, 0x56, 0xf9,
+	0xe1, 0x2c, 0x9e, 0x6c, 0x8b, 0x4c, 0x91, 0x3a, 0xc6, 0x49, 0x3c, 0xb6, 0x55, 0x70, 0x44, 0x08,
+	0xe8, 0x53, 0x6f, 0x16, 0x77, 0x4b, 0xb1, 0xd4, 0x09, 0x40, 0x7a, 0x3e, 0x43, 0x4f, 0xa0, 0x1a,
+	0x8a, 0x4b, 0x34, 0xa5, 0x38, 0x30, 0x14, 0x4d, 0xc0, 0x91, 0x12, 0xfa, 0x21, 0xd4, 0xa4, 0x13,
+	0xc9, 0xd4, 0xb9, 0xe6, 0x61, 0x2c, 0xd4, 0xaf, 0x41, 0x13, 0xfb, 0x65, 0x50, 0x92, 0x76, 0x92,
+	0xc4, 0xf7, 0xdb, 0x39, 0xf3, 0x05, 0x54, 0x9d, 0xf9, 0xca, 0x7b, 0x13, 0x77, 0x83, 0x47, 0x79,
+	0xb3, 0x0a, 0xc7, 0x0e, 0x84, 0x16, 0x8e, 0x94, 0xf5, 0xdf, 0x29, 0xf0, 0x70, 0xb3, 0xc6, 0x3a,
+	0xf4, 0x95, 0xdb, 0xd0, 0xdf, 0x83, 0x46, 0x40, 0x42, 0xee, 0xa6, 0xbd, 0xb5, 0x8d, 0x33, 0x86,
+	0xc8, 0x5a, 0x4a, 0xb0, 0xf8, 0x33, 0x31, 0xc7, 0x91, 0x9f, 0x1d, 0x84, 0xcd, 0x29, 0x93, 0x2f,
+	0x72, 0x0b, 0xc7, 0x94, 0xfe, 0x0f, 0x05, 0x76, 0x6e, 0x99, 0x24, 0x72, 0x35, 0x93, 0xe9, 0x8f,
+	0xa7, 0x12, 0x49, 0x88, 0xa7, 0xcb, 0x44, 0x94, 0xc4, 0x4f, 0x02, 0x51, 0x12, 0x53, 0x7a, 0xdd,
+	0xfe, 0xf2, 0x6d, 0xfb, 0xbf, 0x82, 0x7a, 0x18, 0xc5, 0x58, 0x9a, 0xd0, 0x3c, 0xec, 0xbd, 0x35,
+	0x68, 0x71, 0x2e, 0x70, 0xb2, 0x01, 0xed, 0xc3, 0x76, 0xea, 0xcb, 0x49, 0xe4, 0x46, 0xb5, 0x57,
+	0xde, 0x6f, 0xe1, 0x75, 0xb6, 0x7e, 0x94, 0x8b, 0xb0, 0xc4, 0x2b, 0x4b, 0x12, 0xfb, 0x5e, 0x48,
+	0xd2, 0xcf, 0x61, 0x7b, 0xed, 0x9c, 0xf7, 0x84, 0xe2, 0x43, 0xa8, 0xc9, 0xa8, 0x45, 0x50, 0x6c,
+	0xe1, 0x98, 0x7a, 0xfc, 0x03, 0x68, 0xe6, 0x9a, 0x34, 0xda, 0x82, 0x8a, 0x35, 0xb6, 0x4c, 0xf5,
+	0x9e, 0x58, 0x1d, 0xbf, 0x1a, 0x4e, 0x54, 0xe5, 0xf0, 0x4f, 0x25, 0xa8, 0x88, 0x29, 0x4b, 0xfc,
+	0xdc, 0x35, 0x98, 0x13, 0x8e, 0xb6, 0xd7, 0x66, 0x93, 0xee, 0x3a, 0x63, 0x5f, 0xf9, 0x4c, 0x41,
+	0x3f, 0x05, 0x34, 0x09, 0x7d, 0x87, 0x32, 0x96, 0xff, 0xc9, 0x6c, 0xd3, 0x67, 0x75, 0x57, 0x5d,
+	0xef, 0x4a, 0xe8, 0x02, 0xb4, 0x63, 0xca, 0x37, 0x77, 0x8e, 0x4f, 0xee, 0x2c, 0xfe, 0x71, 0x7c,
+	0xbb, 0xdf, 0xbb, 0x53, 0x0b, 0x39, 0xd0, 0x3d, 0x27, 0xdc, 0x99, 0xff, 0xff, 0xae, 0xf8, 0x4c,
+	0xb9, 0x8c, 0x7e, 0x7f, 0xfc, 0xfc, 0xbf, 0x03, 0x00, 0xf0, 0xd2, 0xb9, 0x59, 0x96, 0x14, 0x00,
+	0x00,
 }
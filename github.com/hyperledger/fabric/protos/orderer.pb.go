@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go.
+// source: orderer.proto
+// DO NOT EDIT!
+
+package protos
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type BroadcastResponse_Status int32
+
+const (
+	BroadcastResponse_SUCCESS             BroadcastResponse_Status = 0
+	BroadcastResponse_BAD_REQUEST         BroadcastResponse_Status = 1
+	BroadcastResponse_SERVICE_UNAVAILABLE BroadcastResponse_Status = 2
+)
+
+var BroadcastResponse_Status_name = map[int32]string{
+	0: "SUCCESS",
+	1: "BAD_REQUEST",
+	2: "SERVICE_UNAVAILABLE",
+}
+var BroadcastResponse_Status_value = map[string]int32{
+	"SUCCESS":             0,
+	"BAD_REQUEST":         1,
+	"SERVICE_UNAVAILABLE": 2,
+}
+
+func (x BroadcastResponse_Status) String() string {
+	return proto.EnumName(BroadcastResponse_Status_name, int32(x))
+}
+func (BroadcastResponse_Status) EnumDescriptor() ([]byte, []int) { return fileDescriptor7, []int{0, 0} }
+
+type BroadcastResponse struct {
+	Status  BroadcastResponse_Status `protobuf:"varint,1,opt,name=status,enum=protos.BroadcastResponse_Status" json:"status,omitempty"`
+	Message string                   `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *BroadcastResponse) Reset()                    { *m = BroadcastResponse{} }
+func (m *BroadcastResponse) String() string            { return proto.CompactTextString(m) }
+func (*BroadcastResponse) ProtoMessage()               {}
+func (*BroadcastResponse) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{0} }
+
+type DeliverRequest struct {
+	// Sequence number of the first batch to deliver, the log starts at 1.
+	Start uint64 `protobuf:"varint,1,opt,name=start" json:"start,omitempty"`
+}
+
+func (m *DeliverRequest) Reset()                    { *m = DeliverRequest{} }
+func (m *DeliverRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeliverRequest) ProtoMessage()               {}
+func (*DeliverRequest) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{1} }
+
+// OrderedBatch is an entry of the log of the ordering service.
+type OrderedBatch struct {
+	SequenceNumber uint64         `protobuf:"varint,1,opt,name=sequenceNumber" json:"sequenceNumber,omitempty"`
+	Transactions   []*Transaction `protobuf:"bytes,2,rep,name=transactions" json:"transactions,omitempty"`
+}
+
+func (m *OrderedBatch) Reset()                    { *m = OrderedBatch{} }
+func (m *OrderedBatch) String() string            { return proto.CompactTextString(m) }
+func (*OrderedBatch) ProtoMessage()               {}
+func (*OrderedBatch) Descriptor() ([]byte, []int) { return fileDescriptor7, []int{2} }
+
+func (m *OrderedBatch) GetTransactions() []*Transaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*BroadcastResponse)(nil), "protos.BroadcastResponse")
+	proto.RegisterType((*DeliverRequest)(nil), "protos.DeliverRequest")
+	proto.RegisterType((*OrderedBatch)(nil), "protos.OrderedBatch")
+	proto.RegisterEnum("protos.BroadcastResponse_Status", BroadcastResponse_Status_name, BroadcastResponse_Status_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for AtomicBroadcast service
+
+type AtomicBroadcastClient interface {
+	// Broadcast submits a transaction for ordering.
+	Broadcast(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*BroadcastResponse, error)
+	// Deliver streams the batches of the log, starting at the requested
+	// sequence number, and the new batches as they are ordered.
+	Deliver(ctx context.Context, in *DeliverRequest, opts ...grpc.CallOption) (AtomicBroadcast_DeliverClient, error)
+}
+
+type atomicBroadcastClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAtomicBroadcastClient(cc *grpc.ClientConn) AtomicBroadcastClient {
+	return &atomicBroadcastClient{cc}
+}
+
+func (c *atomicBroadcastClient) Broadcast(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*BroadcastResponse, error) {
+	out := new(BroadcastResponse)
+	err := grpc.Invoke(ctx, "/protos.AtomicBroadcast/Broadcast", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *atomicBroadcastClient) Deliver(ctx context.Context, in *DeliverRequest, opts ...grpc.CallOption) (AtomicBroadcast_DeliverClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AtomicBroadcast_serviceDesc.Streams[0], c.cc, "/protos.AtomicBroadcast/Deliver", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &atomicBroadcastDeliverClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AtomicBroadcast_DeliverClient interface {
+	Recv() (*OrderedBatch, error)
+	grpc.ClientStream
+}
+
+type atomicBroadcastDeliverClient struct {
+	grpc.ClientStream
+}
+
+func (x *atomicBroadcastDeliverClient) Recv() (*OrderedBatch, error) {
+	m := new(OrderedBatch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for AtomicBroadcast service
+
+type AtomicBroadcastServer interface {
+	// Broadcast submits a transaction for ordering.
+	Broadcast(context.Context, *Transaction) (*BroadcastResponse, error)
+	// Deliver streams the batches of the log, starting at the requested
+	// sequence number, and the new batches as they are ordered.
+	Deliver(*DeliverRequest, AtomicBroadcast_DeliverServer) error
+}
+
+func RegisterAtomicBroadcastServer(s *grpc.Server, srv AtomicBroadcastServer) {
+	s.RegisterService(&_AtomicBroadcast_serviceDesc, srv)
+}
+
+func _AtomicBroadcast_Broadcast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Transaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AtomicBroadcastServer).Broadcast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.AtomicBroadcast/Broadcast",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AtomicBroadcastServer).Broadcast(ctx, req.(*Transaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AtomicBroadcast_Deliver_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeliverRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AtomicBroadcastServer).Deliver(m, &atomicBroadcastDeliverServer{stream})
+}
+
+type AtomicBroadcast_DeliverServer interface {
+	Send(*OrderedBatch) error
+	grpc.ServerStream
+}
+
+type atomicBroadcastDeliverServer struct {
+	grpc.ServerStream
+}
+
+func (x *atomicBroadcastDeliverServer) Send(m *OrderedBatch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AtomicBroadcast_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.AtomicBroadcast",
+	HandlerType: (*AtomicBroadcastServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Broadcast",
+			Handler:    _AtomicBroadcast_Broadcast_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Deliver",
+			Handler:       _AtomicBroadcast_Deliver_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: fileDescriptor7,
+}
+
+func init() { proto.RegisterFile("orderer.proto", fileDescriptor7) }
+
+var fileDescriptor7 = []byte{
+	// 320 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0x4f, 0x4f, 0xc2, 0x30,
+	0x18, 0xc6, 0x2d, 0x2a, 0x84, 0x17, 0x04, 0x2c, 0x44, 0x27, 0xa7, 0x65, 0x07, 0xc2, 0x89, 0x18,
+	0x3c, 0xa8, 0xf1, 0x60, 0x36, 0xd8, 0x81, 0x84, 0x60, 0xec, 0x80, 0x2b, 0x29, 0xe3, 0x55, 0x97,
+	0xc8, 0x8a, 0x7d, 0x8b, 0xdf, 0xc1, 0x6f, 0xe2, 0xc7, 0x34, 0x6e, 0x4c, 0xc4, 0x3f, 0xa7, 0xe6,
+	0x79, 0xfa, 0x6b, 0xd3, 0xdf, 0x5b, 0x38, 0x52, 0x7a, 0x81, 0x1a, 0x75, 0x67, 0xa5, 0x95, 0x51,
+	0x3c, 0x9f, 0x2c, 0xd4, 0x2c, 0x3f, 0xc8, 0xb9, 0x8e, 0xc2, 0xb4, 0x75, 0xde, 0x19, 0x1c, 0x7b,
+	0x5a, 0xc9, 0x45, 0x28, 0xc9, 0x08, 0xa4, 0x95, 0x8a, 0x09, 0xf9, 0x15, 0xe4, 0xc9, 0x48, 0xb3,
+	0x26, 0x8b, 0xd9, 0xac, 0x5d, 0xe9, 0xda, 0x29, 0x4d, 0x9d, 0x5f, 0x68, 0x27, 0x48, 0x38, 0xb1,
+	0xe1, 0xb9, 0x05, 0x85, 0x25, 0x12, 0xc9, 0x47, 0xb4, 0x72, 0x36, 0x6b, 0x17, 0x45, 0x16, 0x9d,
+	0x5b, 0xc8, 0xa7, 0x2c, 0x2f, 0x41, 0x21, 0x98, 0xf4, 0x7a, 0x7e, 0x10, 0xd4, 0xf6, 0x78, 0x15,
+	0x4a, 0x9e, 0xdb, 0x9f, 0x09, 0xff, 0x7e, 0xe2, 0x07, 0xe3, 0x1a, 0xe3, 0xa7, 0x50, 0x0f, 0x7c,
+	0x31, 0x1d, 0xf4, 0xfc, 0xd9, 0x64, 0xe4, 0x4e, 0xdd, 0xc1, 0xd0, 0xf5, 0x86, 0x7e, 0x2d, 0xe7,
+	0xb4, 0xa0, 0xd2, 0xc7, 0xe7, 0xe8, 0x15, 0xb5, 0xc0, 0x97, 0x35, 0x92, 0xe1, 0x0d, 0x38, 0x24,
+	0x23, 0xb5, 0x49, 0x5e, 0x79, 0x20, 0xd2, 0xe0, 0x28, 0x28, 0xdf, 0x25, 0xe6, 0x0b, 0x4f, 0x9a,
+	0xf0, 0x89, 0xb7, 0xa0, 0x42, 0x9f, 0x07, 0xe2, 0x10, 0x47, 0xeb, 0xe5, 0x1c, 0xf5, 0x06, 0xff,
+	0xd1, 0xf2, 0x4b, 0x28, 0x1b, 0x2d, 0x63, 0x92, 0xa1, 0x89, 0x54, 0x4c, 0x56, 0xce, 0xde, 0x6f,
+	0x97, 0xba, 0xf5, 0x4c, 0x7d, 0xbc, 0xdd, 0x13, 0x3b, 0x60, 0xf7, 0x8d, 0x41, 0xd5, 0x35, 0x6a,
+	0x19, 0x85, 0x5f, 0xe3, 0xe1, 0x37, 0x50, 0xdc, 0x86, 0xbf, 0xee, 0x68, 0x9e, 0xfd, 0x3b, 0x53,
+	0x7e, 0x0d, 0x85, 0x8d, 0x29, 0x3f, 0xc9, 0xa8, 0x5d, 0xf5, 0x66, 0x23, 0xeb, 0xbf, 0xab, 0x9e,
+	0xb3, 0x79, 0xfa, 0xcb, 0x17, 0x1f, 0x03, 0x00, 0x70, 0xc4, 0xa9, 0x48, 0xfd, 0x01, 0x00, 0x00,
+}
@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protos
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+)
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// SignedBytes returns the bytes administrators sign: the reconfiguration
+// marshaled without its signatures
+func (r *ValidatorReconfiguration) SignedBytes() ([]byte, error) {
+	return proto.Marshal(&ValidatorReconfiguration{Action: r.Action, Validator: r.Validator, Sequence: r.Sequence, ChainID: r.ChainID})
+}
+
+// Sign adds the signature of an administrator to the reconfiguration
+func (r *ValidatorReconfiguration) Sign(key *ecdsa.PrivateKey) error {
+	raw, err := r.SignedBytes()
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(raw)
+	sr, ss, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return err
+	}
+	sig, err := asn1.Marshal(ecdsaSignature{sr, ss})
+	if err != nil {
+		return err
+	}
+	r.Signatures = append(r.Signatures, sig)
+	return nil
+}
@@ -8,6 +8,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf1 "github.com/golang/protobuf/ptypes/empty"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -52,6 +53,56 @@ func (x ServerStatus_StatusCode) String() string {
 }
 func (ServerStatus_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor6, []int{0, 0} }
 
+type HealthCheck_Status int32
+
+const (
+	HealthCheck_OK        HealthCheck_Status = 0
+	HealthCheck_WARNING   HealthCheck_Status = 1
+	HealthCheck_NOT_READY HealthCheck_Status = 2
+	HealthCheck_FAILED    HealthCheck_Status = 3
+)
+
+var HealthCheck_Status_name = map[int32]string{
+	0: "OK",
+	1: "WARNING",
+	2: "NOT_READY",
+	3: "FAILED",
+}
+var HealthCheck_Status_value = map[string]int32{
+	"OK":        0,
+	"WARNING":   1,
+	"NOT_READY": 2,
+	"FAILED":    3,
+}
+
+func (x HealthCheck_Status) String() string {
+	return proto.EnumName(HealthCheck_Status_name, int32(x))
+}
+func (HealthCheck_Status) EnumDescriptor() ([]byte, []int) { return fileDescriptor6, []int{9, 0} }
+
+type ValidatorReconfiguration_Action int32
+
+const (
+	ValidatorReconfiguration_ADD    ValidatorReconfiguration_Action = 0
+	ValidatorReconfiguration_REMOVE ValidatorReconfiguration_Action = 1
+)
+
+var ValidatorReconfiguration_Action_name = map[int32]string{
+	0: "ADD",
+	1: "REMOVE",
+}
+var ValidatorReconfiguration_Action_value = map[string]int32{
+	"ADD":    0,
+	"REMOVE": 1,
+}
+
+func (x ValidatorReconfiguration_Action) String() string {
+	return proto.EnumName(ValidatorReconfiguration_Action_name, int32(x))
+}
+func (ValidatorReconfiguration_Action) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor6, []int{11, 0}
+}
+
 type ServerStatus struct {
 	Status ServerStatus_StatusCode `protobuf:"varint,1,opt,name=status,enum=protos.ServerStatus_StatusCode" json:"status,omitempty"`
 }
@@ -61,9 +112,256 @@ func (m *ServerStatus) String() string            { return proto.CompactTextStri
 func (*ServerStatus) ProtoMessage()               {}
 func (*ServerStatus) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
 
+// ConsensusStatusRequest selects the chain whose consensus status is
+// returned, the default chain when chainID is empty.
+type ConsensusStatusRequest struct {
+	ChainID string `protobuf:"bytes,1,opt,name=chainID" json:"chainID,omitempty"`
+}
+
+func (m *ConsensusStatusRequest) Reset()                    { *m = ConsensusStatusRequest{} }
+func (m *ConsensusStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*ConsensusStatusRequest) ProtoMessage()               {}
+func (*ConsensusStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{1} }
+
+// ConsensusStatus is a read-only snapshot of the consensus plugin of a
+// validating peer. Plugins other than PBFT only report their name.
+type ConsensusStatus struct {
+	Plugin string      `protobuf:"bytes,1,opt,name=plugin" json:"plugin,omitempty"`
+	Pbft   *PbftStatus `protobuf:"bytes,2,opt,name=pbft" json:"pbft,omitempty"`
+}
+
+func (m *ConsensusStatus) Reset()                    { *m = ConsensusStatus{} }
+func (m *ConsensusStatus) String() string            { return proto.CompactTextString(m) }
+func (*ConsensusStatus) ProtoMessage()               {}
+func (*ConsensusStatus) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{2} }
+
+func (m *ConsensusStatus) GetPbft() *PbftStatus {
+	if m != nil {
+		return m.Pbft
+	}
+	return nil
+}
+
+// PbftStatus describes the progress of a PBFT replica, and the requests
+// and view changes it is waiting on.
+type PbftStatus struct {
+	ReplicaId                 uint64                        `protobuf:"varint,1,opt,name=replicaId" json:"replicaId,omitempty"`
+	View                      uint64                        `protobuf:"varint,2,opt,name=view" json:"view,omitempty"`
+	Primary                   uint64                        `protobuf:"varint,3,opt,name=primary" json:"primary,omitempty"`
+	ActiveView                bool                          `protobuf:"varint,4,opt,name=activeView" json:"activeView,omitempty"`
+	N                         uint32                        `protobuf:"varint,5,opt,name=N,json=n" json:"N,omitempty"`
+	F                         uint32                        `protobuf:"varint,6,opt,name=f" json:"f,omitempty"`
+	Replicas                  []uint64                      `protobuf:"varint,7,rep,packed,name=replicas" json:"replicas,omitempty"`
+	LowWatermark              uint64                        `protobuf:"varint,8,opt,name=lowWatermark" json:"lowWatermark,omitempty"`
+	HighWatermark             uint64                        `protobuf:"varint,9,opt,name=highWatermark" json:"highWatermark,omitempty"`
+	LastExec                  uint64                        `protobuf:"varint,10,opt,name=lastExec" json:"lastExec,omitempty"`
+	SeqNo                     uint64                        `protobuf:"varint,11,opt,name=seqNo" json:"seqNo,omitempty"`
+	StateTransferInProgress   bool                          `protobuf:"varint,12,opt,name=stateTransferInProgress" json:"stateTransferInProgress,omitempty"`
+	Executing                 bool                          `protobuf:"varint,13,opt,name=executing" json:"executing,omitempty"`
+	Checkpoints               []uint64                      `protobuf:"varint,14,rep,packed,name=checkpoints" json:"checkpoints,omitempty"`
+	OutstandingRequestBatches uint32                        `protobuf:"varint,15,opt,name=outstandingRequestBatches" json:"outstandingRequestBatches,omitempty"`
+	OutstandingRequests       uint32                        `protobuf:"varint,16,opt,name=outstandingRequests" json:"outstandingRequests,omitempty"`
+	PendingRequests           uint32                        `protobuf:"varint,17,opt,name=pendingRequests" json:"pendingRequests,omitempty"`
+	BatchedRequests           uint32                        `protobuf:"varint,18,opt,name=batchedRequests" json:"batchedRequests,omitempty"`
+	ViewChanges               []*PbftStatus_ViewChangeVotes `protobuf:"bytes,19,rep,name=viewChanges" json:"viewChanges,omitempty"`
+	PendingReconfigurations   uint32                        `protobuf:"varint,20,opt,name=pendingReconfigurations" json:"pendingReconfigurations,omitempty"`
+	ReconfigurationCheckpoint uint64                        `protobuf:"varint,21,opt,name=reconfigurationCheckpoint" json:"reconfigurationCheckpoint,omitempty"`
+	ReconfigurationSequence   uint64                        `protobuf:"varint,22,opt,name=reconfigurationSequence" json:"reconfigurationSequence,omitempty"`
+}
+
+func (m *PbftStatus) Reset()                    { *m = PbftStatus{} }
+func (m *PbftStatus) String() string            { return proto.CompactTextString(m) }
+func (*PbftStatus) ProtoMessage()               {}
+func (*PbftStatus) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{3} }
+
+func (m *PbftStatus) GetViewChanges() []*PbftStatus_ViewChangeVotes {
+	if m != nil {
+		return m.ViewChanges
+	}
+	return nil
+}
+
+type PbftStatus_ViewChangeVotes struct {
+	View     uint64   `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
+	Replicas []uint64 `protobuf:"varint,2,rep,packed,name=replicas" json:"replicas,omitempty"`
+}
+
+func (m *PbftStatus_ViewChangeVotes) Reset()                    { *m = PbftStatus_ViewChangeVotes{} }
+func (m *PbftStatus_ViewChangeVotes) String() string            { return proto.CompactTextString(m) }
+func (*PbftStatus_ViewChangeVotes) ProtoMessage()               {}
+func (*PbftStatus_ViewChangeVotes) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{3, 0} }
+
+// BannedPeer is a remote peer which is not connected to, nor accepted
+// connections from, until its ban expires. Each ban of a peer lasts
+// twice as long as the previous one.
+type BannedPeer struct {
+	Address string                     `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	Name    string                     `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Bans    uint32                     `protobuf:"varint,3,opt,name=bans" json:"bans,omitempty"`
+	Until   *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=until" json:"until,omitempty"`
+	Reason  string                     `protobuf:"bytes,5,opt,name=reason" json:"reason,omitempty"`
+}
+
+func (m *BannedPeer) Reset()                    { *m = BannedPeer{} }
+func (m *BannedPeer) String() string            { return proto.CompactTextString(m) }
+func (*BannedPeer) ProtoMessage()               {}
+func (*BannedPeer) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{4} }
+
+func (m *BannedPeer) GetUntil() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Until
+	}
+	return nil
+}
+
+type BannedPeers struct {
+	Peers []*BannedPeer `protobuf:"bytes,1,rep,name=peers" json:"peers,omitempty"`
+}
+
+func (m *BannedPeers) Reset()                    { *m = BannedPeers{} }
+func (m *BannedPeers) String() string            { return proto.CompactTextString(m) }
+func (*BannedPeers) ProtoMessage()               {}
+func (*BannedPeers) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{5} }
+
+func (m *BannedPeers) GetPeers() []*BannedPeer {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+// PeerTraffic describes the queue of the messages to send to a connected
+// peer, and what was sent to it since it connected.
+type PeerTraffic struct {
+	PeerEndpoint    *PeerEndpoint `protobuf:"bytes,1,opt,name=peerEndpoint" json:"peerEndpoint,omitempty"`
+	Compression     Compression   `protobuf:"varint,2,opt,name=compression,enum=protos.Compression" json:"compression,omitempty"`
+	QueueDepth      uint32        `protobuf:"varint,3,opt,name=queueDepth" json:"queueDepth,omitempty"`
+	QueueCapacity   uint32        `protobuf:"varint,4,opt,name=queueCapacity" json:"queueCapacity,omitempty"`
+	MessagesSent    uint64        `protobuf:"varint,5,opt,name=messagesSent" json:"messagesSent,omitempty"`
+	BytesSent       uint64        `protobuf:"varint,6,opt,name=bytesSent" json:"bytesSent,omitempty"`
+	MessagesDropped uint64        `protobuf:"varint,7,opt,name=messagesDropped" json:"messagesDropped,omitempty"`
+}
+
+func (m *PeerTraffic) Reset()                    { *m = PeerTraffic{} }
+func (m *PeerTraffic) String() string            { return proto.CompactTextString(m) }
+func (*PeerTraffic) ProtoMessage()               {}
+func (*PeerTraffic) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{6} }
+
+func (m *PeerTraffic) GetPeerEndpoint() *PeerEndpoint {
+	if m != nil {
+		return m.PeerEndpoint
+	}
+	return nil
+}
+
+type PeersTraffic struct {
+	Peers []*PeerTraffic `protobuf:"bytes,1,rep,name=peers" json:"peers,omitempty"`
+}
+
+func (m *PeersTraffic) Reset()                    { *m = PeersTraffic{} }
+func (m *PeersTraffic) String() string            { return proto.CompactTextString(m) }
+func (*PeersTraffic) ProtoMessage()               {}
+func (*PeersTraffic) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{7} }
+
+func (m *PeersTraffic) GetPeers() []*PeerTraffic {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+// ConfigReload lists the configuration keys a reload found changed, those
+// applied while the peer is running, and those which take effect when the
+// peer is restarted.
+type ConfigReload struct {
+	Applied         []string `protobuf:"bytes,1,rep,name=applied" json:"applied,omitempty"`
+	RestartRequired []string `protobuf:"bytes,2,rep,name=restartRequired" json:"restartRequired,omitempty"`
+}
+
+func (m *ConfigReload) Reset()                    { *m = ConfigReload{} }
+func (m *ConfigReload) String() string            { return proto.CompactTextString(m) }
+func (*ConfigReload) ProtoMessage()               {}
+func (*ConfigReload) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{8} }
+
+// HealthCheck is the result of a check of a subsystem of the peer. A
+// WARNING reports a degraded subsystem which the peer still works with,
+// NOT_READY one the peer cannot serve requests with yet, and FAILED one
+// the peer does not recover from without being restarted.
+type HealthCheck struct {
+	Name    string             `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Status  HealthCheck_Status `protobuf:"varint,2,opt,name=status,enum=protos.HealthCheck_Status" json:"status,omitempty"`
+	Message string             `protobuf:"bytes,3,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *HealthCheck) Reset()                    { *m = HealthCheck{} }
+func (m *HealthCheck) String() string            { return proto.CompactTextString(m) }
+func (*HealthCheck) ProtoMessage()               {}
+func (*HealthCheck) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{9} }
+
+// Health is the result of the health and readiness checks of the peer. The
+// peer is healthy when none of the checks failed, and ready when it is
+// healthy and none of the checks is NOT_READY.
+type Health struct {
+	Healthy bool           `protobuf:"varint,1,opt,name=healthy" json:"healthy,omitempty"`
+	Ready   bool           `protobuf:"varint,2,opt,name=ready" json:"ready,omitempty"`
+	Checks  []*HealthCheck `protobuf:"bytes,3,rep,name=checks" json:"checks,omitempty"`
+}
+
+func (m *Health) Reset()                    { *m = Health{} }
+func (m *Health) String() string            { return proto.CompactTextString(m) }
+func (*Health) ProtoMessage()               {}
+func (*Health) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{10} }
+
+func (m *Health) GetChecks() []*HealthCheck {
+	if m != nil {
+		return m.Checks
+	}
+	return nil
+}
+
+// ValidatorReconfiguration requests that a validator joins or leaves the set
+// of validators. Administrators sign the SHA-256 digest of the request
+// marshaled without signatures, and sequence must be one more than the
+// sequence of the last reconfiguration ordered, so that a request cannot be
+// ordered twice. chainID selects the chain whose validators change, the
+// default chain when it is empty.
+type ValidatorReconfiguration struct {
+	Action     ValidatorReconfiguration_Action `protobuf:"varint,1,opt,name=action,enum=protos.ValidatorReconfiguration_Action" json:"action,omitempty"`
+	Validator  *PeerID                         `protobuf:"bytes,2,opt,name=validator" json:"validator,omitempty"`
+	Sequence   uint64                          `protobuf:"varint,3,opt,name=sequence" json:"sequence,omitempty"`
+	Signatures [][]byte                        `protobuf:"bytes,4,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	ChainID    string                          `protobuf:"bytes,5,opt,name=chainID" json:"chainID,omitempty"`
+}
+
+func (m *ValidatorReconfiguration) Reset()                    { *m = ValidatorReconfiguration{} }
+func (m *ValidatorReconfiguration) String() string            { return proto.CompactTextString(m) }
+func (*ValidatorReconfiguration) ProtoMessage()               {}
+func (*ValidatorReconfiguration) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{11} }
+
+func (m *ValidatorReconfiguration) GetValidator() *PeerID {
+	if m != nil {
+		return m.Validator
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
+	proto.RegisterType((*ConsensusStatusRequest)(nil), "protos.ConsensusStatusRequest")
+	proto.RegisterType((*ConsensusStatus)(nil), "protos.ConsensusStatus")
+	proto.RegisterType((*PbftStatus)(nil), "protos.PbftStatus")
+	proto.RegisterType((*PbftStatus_ViewChangeVotes)(nil), "protos.PbftStatus.ViewChangeVotes")
+	proto.RegisterType((*BannedPeer)(nil), "protos.BannedPeer")
+	proto.RegisterType((*BannedPeers)(nil), "protos.BannedPeers")
+	proto.RegisterType((*PeerTraffic)(nil), "protos.PeerTraffic")
+	proto.RegisterType((*PeersTraffic)(nil), "protos.PeersTraffic")
+	proto.RegisterType((*ConfigReload)(nil), "protos.ConfigReload")
+	proto.RegisterType((*HealthCheck)(nil), "protos.HealthCheck")
+	proto.RegisterType((*Health)(nil), "protos.Health")
+	proto.RegisterType((*ValidatorReconfiguration)(nil), "protos.ValidatorReconfiguration")
 	proto.RegisterEnum("protos.ServerStatus_StatusCode", ServerStatus_StatusCode_name, ServerStatus_StatusCode_value)
+	proto.RegisterEnum("protos.HealthCheck_Status", HealthCheck_Status_name, HealthCheck_Status_value)
+	proto.RegisterEnum("protos.ValidatorReconfiguration_Action", ValidatorReconfiguration_Action_name, ValidatorReconfiguration_Action_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -81,6 +379,19 @@ type AdminClient interface {
 	GetStatus(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	StartServer(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	StopServer(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
+	// Return a snapshot of the state of the consensus plugin of a chain.
+	GetConsensusStatus(ctx context.Context, in *ConsensusStatusRequest, opts ...grpc.CallOption) (*ConsensusStatus, error)
+	// Return the remote peers banned for misbehaving.
+	GetBannedPeers(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*BannedPeers, error)
+	// Return the traffic to the connected peers.
+	GetPeersTraffic(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*PeersTraffic, error)
+	// Read the configuration file again and apply the changes which do not
+	// require a restart.
+	ReloadConfig(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ConfigReload, error)
+	// Return the result of the health and readiness checks of the peer.
+	GetHealth(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*Health, error)
+	// Order the addition or removal of a validator, signed by administrators.
+	ReconfigureValidators(ctx context.Context, in *ValidatorReconfiguration, opts ...grpc.CallOption) (*google_protobuf1.Empty, error)
 }
 
 type adminClient struct {
@@ -118,6 +429,60 @@ func (c *adminClient) StopServer(ctx context.Context, in *google_protobuf1.Empty
 	return out, nil
 }
 
+func (c *adminClient) GetConsensusStatus(ctx context.Context, in *ConsensusStatusRequest, opts ...grpc.CallOption) (*ConsensusStatus, error) {
+	out := new(ConsensusStatus)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetConsensusStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetBannedPeers(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*BannedPeers, error) {
+	out := new(BannedPeers)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetBannedPeers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetPeersTraffic(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*PeersTraffic, error) {
+	out := new(PeersTraffic)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetPeersTraffic", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReloadConfig(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ConfigReload, error) {
+	out := new(ConfigReload)
+	err := grpc.Invoke(ctx, "/protos.Admin/ReloadConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetHealth(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*Health, error) {
+	out := new(Health)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetHealth", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReconfigureValidators(ctx context.Context, in *ValidatorReconfiguration, opts ...grpc.CallOption) (*google_protobuf1.Empty, error) {
+	out := new(google_protobuf1.Empty)
+	err := grpc.Invoke(ctx, "/protos.Admin/ReconfigureValidators", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -125,6 +490,19 @@ type AdminServer interface {
 	GetStatus(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
 	StartServer(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
 	StopServer(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
+	// Return a snapshot of the state of the consensus plugin of a chain.
+	GetConsensusStatus(context.Context, *ConsensusStatusRequest) (*ConsensusStatus, error)
+	// Return the remote peers banned for misbehaving.
+	GetBannedPeers(context.Context, *google_protobuf1.Empty) (*BannedPeers, error)
+	// Return the traffic to the connected peers.
+	GetPeersTraffic(context.Context, *google_protobuf1.Empty) (*PeersTraffic, error)
+	// Read the configuration file again and apply the changes which do not
+	// require a restart.
+	ReloadConfig(context.Context, *google_protobuf1.Empty) (*ConfigReload, error)
+	// Return the result of the health and readiness checks of the peer.
+	GetHealth(context.Context, *google_protobuf1.Empty) (*Health, error)
+	// Order the addition or removal of a validator, signed by administrators.
+	ReconfigureValidators(context.Context, *ValidatorReconfiguration) (*google_protobuf1.Empty, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -185,6 +563,114 @@ func _Admin_StopServer_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_GetConsensusStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsensusStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetConsensusStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetConsensusStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetConsensusStatus(ctx, req.(*ConsensusStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetBannedPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetBannedPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetBannedPeers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetBannedPeers(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetPeersTraffic_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetPeersTraffic(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetPeersTraffic",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetPeersTraffic(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/ReloadConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReloadConfig(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetHealth(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReconfigureValidators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatorReconfiguration)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReconfigureValidators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/ReconfigureValidators",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReconfigureValidators(ctx, req.(*ValidatorReconfiguration))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -201,6 +687,30 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopServer",
 			Handler:    _Admin_StopServer_Handler,
 		},
+		{
+			MethodName: "GetConsensusStatus",
+			Handler:    _Admin_GetConsensusStatus_Handler,
+		},
+		{
+			MethodName: "GetBannedPeers",
+			Handler:    _Admin_GetBannedPeers_Handler,
+		},
+		{
+			MethodName: "GetPeersTraffic",
+			Handler:    _Admin_GetPeersTraffic_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Admin_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "GetHealth",
+			Handler:    _Admin_GetHealth_Handler,
+		},
+		{
+			MethodName: "ReconfigureValidators",
+			Handler:    _Admin_ReconfigureValidators_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor6,
@@ -209,21 +719,87 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("server_admin.proto", fileDescriptor6) }
 
 var fileDescriptor6 = []byte{
-	// 248 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x2a, 0x4e, 0x2d, 0x2a,
-	0x4b, 0x2d, 0x8a, 0x4f, 0x4c, 0xc9, 0xcd, 0xcc, 0xd3, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62,
-	0x03, 0x53, 0xc5, 0x52, 0xd2, 0xe9, 0xf9, 0xf9, 0xe9, 0x39, 0xa9, 0xfa, 0x60, 0x6e, 0x52, 0x69,
-	0x9a, 0x7e, 0x6a, 0x6e, 0x41, 0x49, 0x25, 0x44, 0x91, 0xd2, 0x22, 0x46, 0x2e, 0x9e, 0x60, 0xb0,
-	0xde, 0xe0, 0x92, 0xc4, 0x92, 0xd2, 0x62, 0x21, 0x73, 0x2e, 0xb6, 0x62, 0x30, 0x4b, 0x82, 0x51,
-	0x81, 0x51, 0x83, 0xcf, 0x48, 0x1e, 0xa2, 0xb0, 0x58, 0x0f, 0x59, 0x95, 0x1e, 0x84, 0x72, 0xce,
-	0x4f, 0x49, 0x0d, 0x82, 0x2a, 0x57, 0x8a, 0xe4, 0xe2, 0x42, 0x88, 0x0a, 0xf1, 0x72, 0x71, 0x86,
-	0xfa, 0xb9, 0xb8, 0xba, 0x79, 0xfa, 0xb9, 0xba, 0x08, 0x30, 0x08, 0x71, 0x73, 0xb1, 0x07, 0x87,
-	0x38, 0x06, 0x85, 0x00, 0x39, 0x8c, 0x10, 0x8e, 0x7f, 0x40, 0x00, 0x90, 0xc3, 0x24, 0xc4, 0xc5,
-	0xc5, 0x16, 0xe0, 0x18, 0x1a, 0x0c, 0x64, 0x33, 0x0b, 0x71, 0x72, 0xb1, 0xba, 0x06, 0x05, 0xf9,
-	0x07, 0x09, 0xb0, 0x80, 0xd4, 0x84, 0xfa, 0x79, 0xfb, 0xf9, 0x87, 0xfb, 0x09, 0xb0, 0x1a, 0x1d,
-	0x64, 0xe4, 0x62, 0x75, 0x04, 0xf9, 0x4c, 0xc8, 0x9a, 0x8b, 0xd3, 0x3d, 0xb5, 0x04, 0xea, 0x54,
-	0x31, 0x3d, 0x88, 0xcf, 0xf4, 0x60, 0x3e, 0xd3, 0x73, 0x05, 0xf9, 0x4c, 0x4a, 0x04, 0x9b, 0x93,
-	0x95, 0x18, 0x84, 0x6c, 0xb9, 0xb8, 0x81, 0xec, 0xa2, 0x12, 0x88, 0x30, 0xc9, 0xda, 0x6d, 0x40,
-	0x1e, 0xcc, 0x2f, 0x20, 0x4f, 0x77, 0x12, 0x24, 0x36, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0x5b, 0x2d, 0xff, 0xc2, 0xaa, 0x01, 0x00, 0x00,
+	// 1302 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0x51, 0x6f, 0x1a, 0x47,
+	0x10, 0xee, 0x19, 0x38, 0x9b, 0x01, 0xdb, 0x74, 0xed, 0x38, 0x57, 0xda, 0x26, 0xe8, 0x54, 0xb5,
+	0x54, 0xad, 0x48, 0x44, 0x54, 0x25, 0x69, 0x53, 0x45, 0x04, 0x88, 0x8b, 0xd2, 0x60, 0x67, 0x71,
+	0x1c, 0xe5, 0x29, 0x5a, 0xee, 0x16, 0x38, 0x05, 0xf6, 0x2e, 0xbb, 0x8b, 0x13, 0xff, 0x93, 0x3e,
+	0x57, 0xea, 0x43, 0xdf, 0xfa, 0x53, 0xfa, 0x8b, 0xaa, 0x6a, 0x77, 0xef, 0x8e, 0x3b, 0x1c, 0x64,
+	0xb5, 0x4f, 0xec, 0x7c, 0xf3, 0xcd, 0xed, 0xec, 0xec, 0xcc, 0xec, 0x00, 0x48, 0x50, 0x7e, 0x41,
+	0xf9, 0x1b, 0xe2, 0x2f, 0x02, 0xd6, 0x8a, 0x78, 0x28, 0x43, 0x64, 0xeb, 0x1f, 0x51, 0xff, 0x7c,
+	0x1a, 0x86, 0xd3, 0x39, 0xbd, 0xa3, 0xc5, 0xf1, 0x72, 0x72, 0x87, 0x2e, 0x22, 0x79, 0x69, 0x48,
+	0xf5, 0xdb, 0xeb, 0x4a, 0x19, 0x2c, 0xa8, 0x90, 0x64, 0x11, 0xc5, 0x84, 0xea, 0x84, 0x8c, 0x79,
+	0xe0, 0x19, 0xc9, 0xfd, 0xdd, 0x82, 0xea, 0x48, 0x6f, 0x35, 0x92, 0x44, 0x2e, 0x05, 0xba, 0x0f,
+	0xb6, 0xd0, 0x2b, 0xc7, 0x6a, 0x58, 0xcd, 0xbd, 0xf6, 0x6d, 0x43, 0x14, 0xad, 0x2c, 0xab, 0x65,
+	0x7e, 0xba, 0xa1, 0x4f, 0x71, 0x4c, 0x77, 0x5f, 0x03, 0xac, 0x50, 0xb4, 0x0b, 0xe5, 0x97, 0xc3,
+	0x5e, 0xff, 0xe9, 0x60, 0xd8, 0xef, 0xd5, 0x3e, 0x41, 0x15, 0xd8, 0x1e, 0x9d, 0x75, 0xf0, 0x59,
+	0xbf, 0x57, 0xb3, 0x8c, 0x70, 0x72, 0x7a, 0xda, 0xef, 0xd5, 0xb6, 0x10, 0x80, 0x7d, 0xda, 0x79,
+	0x39, 0xea, 0xf7, 0x6a, 0x05, 0x54, 0x86, 0x52, 0x1f, 0xe3, 0x13, 0x5c, 0x2b, 0x2a, 0xce, 0xcb,
+	0xe1, 0xb3, 0xe1, 0xc9, 0xab, 0x61, 0xad, 0xe4, 0xb6, 0xe1, 0xa8, 0x1b, 0x32, 0x41, 0x99, 0x58,
+	0x0a, 0xb3, 0x07, 0xa6, 0xef, 0x96, 0x54, 0x48, 0xe4, 0xc0, 0xb6, 0x37, 0x23, 0x01, 0x1b, 0xf4,
+	0xb4, 0xbb, 0x65, 0x9c, 0x88, 0xee, 0x0b, 0xd8, 0x5f, 0xb3, 0x41, 0x47, 0x60, 0x47, 0xf3, 0xe5,
+	0x34, 0x60, 0x31, 0x37, 0x96, 0xd0, 0xd7, 0x50, 0x8c, 0xc6, 0x13, 0xe9, 0x6c, 0x35, 0xac, 0x66,
+	0xa5, 0x8d, 0x92, 0x03, 0x9f, 0x8e, 0x27, 0x32, 0xde, 0x4d, 0xeb, 0xdd, 0x3f, 0xb6, 0x01, 0x56,
+	0x20, 0xfa, 0x02, 0xca, 0x9c, 0x46, 0xf3, 0xc0, 0x23, 0x03, 0x5f, 0x7f, 0xb1, 0x88, 0x57, 0x00,
+	0x42, 0x50, 0xbc, 0x08, 0xe8, 0x7b, 0xfd, 0xd1, 0x22, 0xd6, 0x6b, 0xe5, 0x6d, 0xc4, 0x83, 0x05,
+	0xe1, 0x97, 0x4e, 0x41, 0xc3, 0x89, 0x88, 0x6e, 0x01, 0x10, 0x4f, 0x06, 0x17, 0xf4, 0x5c, 0xd9,
+	0x14, 0x1b, 0x56, 0x73, 0x07, 0x67, 0x10, 0x54, 0x05, 0x6b, 0xe8, 0x94, 0x1a, 0x56, 0x73, 0x17,
+	0x5b, 0x4c, 0x49, 0x13, 0xc7, 0x36, 0xd2, 0x04, 0xd5, 0x61, 0x27, 0xde, 0x56, 0x38, 0xdb, 0x8d,
+	0x42, 0xb3, 0x88, 0x53, 0x19, 0xb9, 0x50, 0x9d, 0x87, 0xef, 0x5f, 0x11, 0x49, 0xf9, 0x82, 0xf0,
+	0xb7, 0xce, 0x8e, 0xde, 0x36, 0x87, 0xa1, 0xaf, 0x60, 0x77, 0x16, 0x4c, 0x67, 0x2b, 0x52, 0x59,
+	0x93, 0xf2, 0xa0, 0xda, 0x65, 0x4e, 0x84, 0xec, 0x7f, 0xa0, 0x9e, 0x03, 0x9a, 0x90, 0xca, 0xe8,
+	0x10, 0x4a, 0x82, 0xbe, 0x1b, 0x86, 0x4e, 0x45, 0x2b, 0x8c, 0x80, 0x1e, 0xc0, 0x4d, 0x95, 0x1a,
+	0xf4, 0x8c, 0x13, 0x26, 0x26, 0x94, 0x0f, 0xd8, 0x29, 0x0f, 0xa7, 0x9c, 0x0a, 0xe1, 0x54, 0xf5,
+	0x01, 0x37, 0xa9, 0x55, 0x64, 0xe9, 0x07, 0xea, 0x2d, 0x65, 0xc0, 0xa6, 0xce, 0xae, 0xe6, 0xae,
+	0x00, 0xd4, 0x80, 0x8a, 0x37, 0xa3, 0xde, 0xdb, 0x28, 0x0c, 0x98, 0x14, 0xce, 0x9e, 0x3e, 0x72,
+	0x16, 0x42, 0x8f, 0xe0, 0xb3, 0x70, 0x29, 0x85, 0x24, 0xcc, 0x0f, 0xd8, 0x34, 0xce, 0x95, 0x27,
+	0x44, 0x7a, 0x33, 0x2a, 0x9c, 0x7d, 0x1d, 0xb7, 0xcd, 0x04, 0x74, 0x17, 0x0e, 0xae, 0x2a, 0x85,
+	0x53, 0xd3, 0x76, 0x1f, 0x53, 0xa1, 0x26, 0xec, 0x47, 0x34, 0xcf, 0xfe, 0x54, 0xb3, 0xd7, 0x61,
+	0xc5, 0x1c, 0xeb, 0x6d, 0xfc, 0x94, 0x89, 0x0c, 0x73, 0x0d, 0x46, 0x3d, 0xa8, 0xa8, 0x9c, 0xe9,
+	0xce, 0x08, 0x9b, 0x52, 0xe1, 0x1c, 0x34, 0x0a, 0xcd, 0x4a, 0xdb, 0xbd, 0x9a, 0x9b, 0xad, 0xf3,
+	0x94, 0x75, 0x1e, 0x4a, 0x2a, 0x70, 0xd6, 0x4c, 0xdd, 0x41, 0xea, 0x82, 0x17, 0xb2, 0x49, 0x30,
+	0x5d, 0x72, 0x22, 0x83, 0x90, 0x09, 0xe7, 0x50, 0xef, 0xbb, 0x49, 0xad, 0x62, 0xc8, 0xf3, 0x58,
+	0x37, 0x8d, 0xb0, 0x73, 0x43, 0xdf, 0xf3, 0x66, 0x82, 0xda, 0x77, 0x4d, 0x39, 0x52, 0x07, 0x63,
+	0x1e, 0x75, 0x8e, 0xb4, 0xed, 0x26, 0x75, 0xbd, 0x03, 0xfb, 0x6b, 0x27, 0x4a, 0x4b, 0xc9, 0xca,
+	0x94, 0x52, 0x36, 0xe9, 0xb7, 0xf2, 0x49, 0xef, 0xfe, 0x66, 0x01, 0x3c, 0x21, 0x8c, 0x51, 0xff,
+	0x94, 0x52, 0xae, 0xaa, 0x8e, 0xf8, 0xbe, 0xce, 0xbb, 0xb8, 0x47, 0xc4, 0xa2, 0xfa, 0x30, 0x23,
+	0x0b, 0xaa, 0x6b, 0xb4, 0x8c, 0xf5, 0x5a, 0x61, 0x63, 0xc2, 0x84, 0x2e, 0xd0, 0x5d, 0xac, 0xd7,
+	0xe8, 0x2e, 0x94, 0x96, 0x4c, 0x06, 0x73, 0x5d, 0x98, 0x95, 0x76, 0xbd, 0x65, 0x7a, 0x6c, 0x2b,
+	0xe9, 0xb1, 0xad, 0xb3, 0xa4, 0xc7, 0x62, 0x43, 0x54, 0xad, 0x86, 0x53, 0x22, 0x42, 0xa6, 0x8b,
+	0xb6, 0x8c, 0x63, 0xc9, 0xbd, 0x0f, 0x95, 0x95, 0x67, 0x2a, 0x1d, 0x4a, 0x91, 0x5a, 0x38, 0x56,
+	0xa3, 0x90, 0x6d, 0x3d, 0x2b, 0x0e, 0x36, 0x04, 0xf7, 0xaf, 0x2d, 0xa8, 0x28, 0xf9, 0x8c, 0x93,
+	0xc9, 0x24, 0xf0, 0xd0, 0x03, 0xa8, 0x2a, 0x45, 0x9f, 0xf9, 0xe6, 0x46, 0x2c, 0xed, 0xd9, 0x61,
+	0x9a, 0x1f, 0x19, 0x1d, 0xce, 0x31, 0xd1, 0x0f, 0x50, 0xf1, 0xc2, 0x45, 0xa4, 0x02, 0x10, 0x84,
+	0x4c, 0x9f, 0x7d, 0xaf, 0x7d, 0x90, 0x18, 0x76, 0x57, 0x2a, 0x9c, 0xe5, 0xa9, 0x0e, 0xf5, 0x6e,
+	0x49, 0x97, 0xb4, 0x47, 0x23, 0x39, 0x8b, 0xa3, 0x93, 0x41, 0x54, 0x17, 0xd1, 0x52, 0x97, 0x44,
+	0xc4, 0x0b, 0xe4, 0xa5, 0x8e, 0xd5, 0x2e, 0xce, 0x83, 0xaa, 0x1f, 0x2d, 0xa8, 0x10, 0x64, 0x4a,
+	0xc5, 0x88, 0x32, 0xa9, 0xa3, 0x53, 0xc4, 0x39, 0x4c, 0x55, 0xff, 0xf8, 0x52, 0xc6, 0x04, 0xdb,
+	0xf4, 0xd5, 0x14, 0x50, 0x15, 0x94, 0xb0, 0x7b, 0x3c, 0x8c, 0x22, 0xea, 0x3b, 0xdb, 0x9a, 0xb3,
+	0x0e, 0xbb, 0x0f, 0xa1, 0xaa, 0xa3, 0x9c, 0x84, 0xec, 0xdb, 0x7c, 0xb0, 0x0f, 0xb2, 0xb1, 0x8a,
+	0x39, 0x49, 0xb4, 0x31, 0x54, 0xbb, 0x3a, 0x3b, 0x31, 0x9d, 0x87, 0xc4, 0xd7, 0x29, 0x14, 0x45,
+	0xf3, 0x80, 0xfa, 0xda, 0xb8, 0x8c, 0x13, 0x51, 0xb9, 0xc3, 0xd5, 0xd5, 0x73, 0xa9, 0x2a, 0x37,
+	0xe0, 0xd4, 0xd7, 0xe9, 0x58, 0xc6, 0xeb, 0xb0, 0xfb, 0xa7, 0x05, 0x95, 0x5f, 0x28, 0x99, 0xcb,
+	0x99, 0xae, 0x93, 0x34, 0xf9, 0xac, 0x4c, 0xf2, 0xb5, 0xd3, 0xc7, 0xd7, 0x5c, 0x4b, 0x3d, 0xf1,
+	0x31, 0x63, 0x18, 0xbf, 0xbd, 0xc9, 0xbb, 0xab, 0x7c, 0x8b, 0x4f, 0xae, 0x6f, 0xa5, 0x8c, 0x13,
+	0xd1, 0x7d, 0x00, 0xb6, 0xe1, 0x22, 0x1b, 0xb6, 0x4e, 0x9e, 0x99, 0x67, 0xf8, 0x55, 0x07, 0x0f,
+	0x07, 0xc3, 0xe3, 0x9a, 0xa5, 0x9e, 0xe8, 0xe1, 0xc9, 0xd9, 0x1b, 0xdc, 0xef, 0xf4, 0x5e, 0x9b,
+	0x87, 0xf8, 0x69, 0x67, 0xf0, 0xab, 0x7a, 0x88, 0x5d, 0x0a, 0xb6, 0xd9, 0x51, 0x7d, 0x7d, 0xa6,
+	0x57, 0x97, 0xda, 0xd1, 0x1d, 0x9c, 0x88, 0xaa, 0xe9, 0x73, 0x4a, 0xfc, 0x4b, 0xed, 0xea, 0x0e,
+	0x36, 0x02, 0xfa, 0x0e, 0x6c, 0xdd, 0x89, 0x55, 0x01, 0xe5, 0xa2, 0x9c, 0x39, 0x01, 0x8e, 0x29,
+	0xee, 0x3f, 0x16, 0x38, 0xe7, 0x64, 0x1e, 0xf8, 0x44, 0x86, 0x7c, 0xad, 0x03, 0xa1, 0xc7, 0x60,
+	0xab, 0x07, 0x30, 0x64, 0xf1, 0x20, 0xf2, 0x4d, 0xf2, 0xa5, 0x4d, 0x16, 0xad, 0x8e, 0xa6, 0xe3,
+	0xd8, 0x0c, 0x7d, 0x0f, 0xe5, 0x8b, 0x84, 0x1a, 0xbf, 0xed, 0x7b, 0xd9, 0x3b, 0x1f, 0xf4, 0xf0,
+	0x8a, 0xa0, 0x1a, 0x8a, 0x48, 0x5a, 0x94, 0x79, 0x9c, 0x53, 0x59, 0xe5, 0xbe, 0x08, 0xa6, 0x8c,
+	0xc8, 0x25, 0xa7, 0xc2, 0x29, 0x36, 0x0a, 0xcd, 0x2a, 0xce, 0x20, 0xd9, 0x29, 0xa4, 0x94, 0x9f,
+	0x42, 0xbe, 0x04, 0xdb, 0x78, 0x85, 0xb6, 0xa1, 0xd0, 0xe9, 0xa9, 0x51, 0x08, 0xc0, 0xc6, 0xfd,
+	0xe7, 0x27, 0xe7, 0xfd, 0x9a, 0xd5, 0xfe, 0xbb, 0x08, 0xa5, 0x8e, 0x9a, 0xf0, 0xd0, 0x43, 0x28,
+	0x1f, 0xd3, 0x64, 0xb2, 0x38, 0xba, 0xd2, 0x60, 0xfa, 0x6a, 0xc2, 0xab, 0x1f, 0x7e, 0x6c, 0x16,
+	0x43, 0x3f, 0x41, 0x65, 0xa4, 0x32, 0xcd, 0x80, 0xff, 0xd1, 0xf8, 0x47, 0x35, 0xb5, 0x85, 0xd1,
+	0xff, 0xb2, 0x7d, 0x0e, 0xe8, 0x98, 0xca, 0xf5, 0x29, 0xeb, 0xd6, 0xaa, 0x95, 0x7c, 0x6c, 0x64,
+	0xab, 0xdf, 0xdc, 0xa0, 0x47, 0x3f, 0xc3, 0xde, 0x31, 0x95, 0xd9, 0xf6, 0xb8, 0xc9, 0x9d, 0x83,
+	0xab, 0x7d, 0x52, 0xa0, 0xc7, 0xb0, 0x7f, 0x4c, 0x65, 0xae, 0xe2, 0xaf, 0x3d, 0x4e, 0x8e, 0xfd,
+	0x08, 0xaa, 0xa6, 0xdc, 0x4d, 0xe9, 0x5f, 0x6f, 0x9d, 0x6b, 0x11, 0xf7, 0xf4, 0x05, 0xc6, 0x55,
+	0xb3, 0xc9, 0x74, 0x2f, 0x5f, 0x0d, 0xe8, 0x05, 0xdc, 0x58, 0x25, 0x31, 0x4d, 0x13, 0x5b, 0xa0,
+	0xc6, 0x75, 0xc9, 0x5e, 0xdf, 0xb0, 0xc5, 0xd8, 0xfc, 0x49, 0xb8, 0xf7, 0xef, 0x00, 0xac, 0x7b,
+	0x12, 0x38, 0x41, 0x0c, 0x00, 0x00,
 }
@@ -88,6 +88,9 @@ func NewChaincodeDeployTransaction(chaincodeDeploymentSpec *ChaincodeDeploymentS
 		return nil, fmt.Errorf("Could not marshal payload for chaincode deployment: %s", err)
 	}
 	transaction.Payload = data
+	if chaincodeDeploymentSpec.ChaincodeSpec != nil {
+		transaction.ChainID = chaincodeDeploymentSpec.ChaincodeSpec.ChainID
+	}
 	return transaction, nil
 }
 
@@ -110,6 +113,9 @@ func NewChaincodeExecute(chaincodeInvocationSpec *ChaincodeInvocationSpec, uuid
 		return nil, fmt.Errorf("Could not marshal payload for chaincode invocation: %s", err)
 	}
 	transaction.Payload = data
+	if chaincodeInvocationSpec.ChaincodeSpec != nil {
+		transaction.ChainID = chaincodeInvocationSpec.ChaincodeSpec.ChainID
+	}
 	return transaction, nil
 }
 
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/replay"
+	pb "github.com/hyperledger/fabric/protos"
+	"google.golang.org/grpc"
+)
+
+// replayServer accepts a single chaincode registration and replays the
+// recording to it
+type replayServer struct {
+	replayer *replay.Replayer
+	results  chan *replayOutcome
+}
+
+type replayOutcome struct {
+	result *replay.Result
+	err    error
+}
+
+func (s *replayServer) Register(stream pb.ChaincodeSupport_RegisterServer) error {
+	result, err := s.replayer.Replay(stream)
+	s.results <- &replayOutcome{result, err}
+	return err
+}
+
+func main() {
+	flagSetName := os.Args[0]
+	flagSet := flag.NewFlagSet(flagSetName, flag.ExitOnError)
+	recordingPtr := flagSet.String("recording", "", "path to the chaincode message recording")
+	namePtr := flagSet.String("name", "", "name of the chaincode to replay, as recorded")
+	txidsPtr := flagSet.String("txids", "", "comma separated IDs of the transactions to replay (default all)")
+	listenPtr := flagSet.String("listen", "127.0.0.1:7051", "address the chaincode connects to")
+	chaincodePtr := flagSet.String("chaincode", "", "chaincode binary to launch (default wait for the chaincode to be started by hand)")
+	timeoutPtr := flagSet.Duration("timeout", 10*time.Second, "time to wait for every message expected from the chaincode")
+	flagSet.Parse(os.Args[1:])
+
+	if *recordingPtr == "" || *namePtr == "" {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", flagSetName)
+		flagSet.PrintDefaults()
+		os.Exit(3)
+	}
+
+	entries, err := replay.LoadRecording(*recordingPtr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(4)
+	}
+	var txids []string
+	if *txidsPtr != "" {
+		txids = strings.Split(*txidsPtr, ",")
+	}
+	entries = replay.Filter(entries, *namePtr, txids)
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "Recording contains no messages for chaincode %s\n", *namePtr)
+		os.Exit(5)
+	}
+	fmt.Printf("Replaying %d messages of chaincode %s\n", len(entries), *namePtr)
+
+	os.Exit(replayTo(*listenPtr, *chaincodePtr, *namePtr, entries, *timeoutPtr))
+}
+
+// replayTo serves the replayer on listen, optionally launching the chaincode,
+// and returns the exit code of the tool
+func replayTo(listen, chaincode, name string, entries []*replay.Entry, timeout time.Duration) int {
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listening on %s: %s\n", listen, err)
+		return 6
+	}
+	server := &replayServer{replayer: replay.NewReplayer(entries, timeout), results: make(chan *replayOutcome, 1)}
+	grpcServer := grpc.NewServer()
+	pb.RegisterChaincodeSupportServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	if chaincode != "" {
+		cmd := exec.Command(chaincode)
+		cmd.Env = append(os.Environ(), "CORE_PEER_ADDRESS="+listen, "CORE_CHAINCODE_ID_NAME="+name, "CORE_PEER_TLS_ENABLED=false")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err = cmd.Start(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error launching chaincode %s: %s\n", chaincode, err)
+			return 7
+		}
+		defer cmd.Process.Kill()
+	} else {
+		fmt.Printf("Waiting for chaincode to connect on %s (CORE_CHAINCODE_ID_NAME=%s)\n", listen, name)
+	}
+
+	outcome := <-server.results
+	if outcome.err != nil {
+		fmt.Fprintf(os.Stderr, "Replay failed: %s\n", outcome.err)
+		return 8
+	}
+	fmt.Printf("Replayed %d of %d messages\n", outcome.result.Replayed, len(entries))
+	if outcome.result.Divergence != nil {
+		fmt.Printf("Chaincode diverged from recording: %s\n", outcome.result.Divergence)
+		return 1
+	}
+	fmt.Println("Chaincode behaved as recorded")
+	return 0
+}